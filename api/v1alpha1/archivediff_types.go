@@ -0,0 +1,180 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ArchiveDiffSpec names an archive to compare with the live cluster.
+// +kubebuilder:validation:XValidation:rule="has(self.backupName) != has(self.storagePath)",message="exactly one of backupName or storagePath must be set"
+type ArchiveDiffSpec struct {
+	// ArchiveName is the archive file to compare.
+	// +kubebuilder:validation:MinLength=1
+	ArchiveName string `json:"archiveName"`
+
+	// BackupName references a ClusterBackup in the same namespace whose
+	// storage location holds the archive.
+	// +optional
+	BackupName string `json:"backupName,omitempty"`
+
+	// StoragePath is the storage location holding the archive, used instead
+	// of backupName.
+	// +kubebuilder:validation:XValidation:rule="self.startsWith('/') || self.startsWith('host://')",message="must be an absolute path or a host:// URI"
+	// +optional
+	StoragePath string `json:"storagePath,omitempty"`
+
+	// AgeIdentitySecretRef references a Secret in the same namespace holding
+	// age identities able to decrypt the archive.
+	// +optional
+	AgeIdentitySecretRef *SecretKeyReference `json:"ageIdentitySecretRef,omitempty"`
+
+	// IgnoreExtra skips reporting live objects the archive does not hold,
+	// for archives of selected objects rather than whole namespaces.
+	// +optional
+	IgnoreExtra bool `json:"ignoreExtra,omitempty"`
+
+	// Interval repeats the comparison to audit configuration drift. When
+	// unset the archive is compared once per generation.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// DiffPhase describes where an ArchiveDiff is in its lifecycle.
+// +kubebuilder:validation:Enum=Completed;Failed
+type DiffPhase string
+
+const (
+	// DiffPhaseCompleted means the archive was compared with the cluster.
+	DiffPhaseCompleted DiffPhase = "Completed"
+	// DiffPhaseFailed means the comparison could not be carried out.
+	DiffPhaseFailed DiffPhase = "Failed"
+)
+
+// ArchiveDiffItem is one object that differs between the archive and the
+// cluster.
+type ArchiveDiffItem struct {
+	// Resource is the group-qualified resource name, e.g. "deployments.apps".
+	Resource string `json:"resource"`
+
+	// Namespace of the object, empty for cluster-scoped objects.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the object.
+	Name string `json:"name"`
+
+	// State is Missing for archived objects absent from the cluster,
+	// Changed for objects whose live content differs and Extra for live
+	// objects the archive does not hold.
+	// +kubebuilder:validation:Enum=Missing;Changed;Extra
+	State string `json:"state"`
+
+	// Diff is the JSON merge patch turning the archived object into the
+	// live one, for changed objects.
+	// +optional
+	Diff string `json:"diff,omitempty"`
+}
+
+// ArchiveDiffStatus defines the observed state of ArchiveDiff.
+type ArchiveDiffStatus struct {
+	// Phase represents the outcome of the last comparison
+	// +optional
+	Phase DiffPhase `json:"phase,omitempty"`
+
+	// ObservedGeneration is the generation the current status refers to.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// LastDiffTime is when the archive was last compared with the cluster
+	// +optional
+	LastDiffTime *metav1.Time `json:"lastDiffTime,omitempty"`
+
+	// StoragePath is the storage location the archive was read from
+	// +optional
+	StoragePath string `json:"storagePath,omitempty"`
+
+	// Missing counts archived objects absent from the cluster.
+	// +optional
+	Missing int `json:"missing,omitempty"`
+
+	// Changed counts objects whose live content differs from the archive.
+	// +optional
+	Changed int `json:"changed,omitempty"`
+
+	// Extra counts live objects the archive does not hold.
+	// +optional
+	Extra int `json:"extra,omitempty"`
+
+	// Unchanged counts objects matching the archive.
+	// +optional
+	Unchanged int `json:"unchanged,omitempty"`
+
+	// Items lists the drifted objects, capped at 100.
+	// +optional
+	Items []ArchiveDiffItem `json:"items,omitempty"`
+
+	// Message provides additional information about the comparison
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// conditions represent the current state of the ArchiveDiff resource.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Archive",type=string,JSONPath=`.spec.archiveName`
+// +kubebuilder:printcolumn:name="Missing",type=integer,JSONPath=`.status.missing`
+// +kubebuilder:printcolumn:name="Changed",type=integer,JSONPath=`.status.changed`
+// +kubebuilder:printcolumn:name="Extra",type=integer,JSONPath=`.status.extra`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ArchiveDiff is the Schema for the archivediffs API. It compares an
+// archive with the live cluster and reports the objects that drifted.
+type ArchiveDiff struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the desired state of ArchiveDiff
+	// +required
+	Spec ArchiveDiffSpec `json:"spec"`
+
+	// status defines the observed state of ArchiveDiff
+	// +optional
+	Status ArchiveDiffStatus `json:"status,omitempty,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// ArchiveDiffList contains a list of ArchiveDiff
+type ArchiveDiffList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ArchiveDiff `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ArchiveDiff{}, &ArchiveDiffList{})
+}
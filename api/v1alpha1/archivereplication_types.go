@@ -0,0 +1,163 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ArchiveReplicationSpec defines which archives are copied where.
+// +kubebuilder:validation:XValidation:rule="has(self.backupName) != has(self.sourceStoragePath)",message="exactly one of backupName or sourceStoragePath must be set"
+type ArchiveReplicationSpec struct {
+	// BackupName references a ClusterBackup in the same namespace whose
+	// storage location holds the archives to replicate.
+	// +optional
+	BackupName string `json:"backupName,omitempty"`
+
+	// SourceStoragePath is the primary storage location, used instead of
+	// backupName.
+	// +kubebuilder:validation:XValidation:rule="self.startsWith('/') || self.startsWith('host://')",message="must be an absolute path or a host:// URI"
+	// +optional
+	SourceStoragePath string `json:"sourceStoragePath,omitempty"`
+
+	// Destinations are the secondary storage locations completed archives
+	// are copied to.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=8
+	// +kubebuilder:validation:XValidation:rule="self.all(p, p.startsWith('/') || p.startsWith('host://'))",message="storage paths must be absolute paths or host:// URIs"
+	// +listType=set
+	Destinations []string `json:"destinations"`
+
+	// Interval is how often the source is checked for archives that have not
+	// been replicated yet. Completing a referenced ClusterBackup also
+	// triggers a pass.
+	// +kubebuilder:default:="15m"
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// ArchiveReplicaPhase describes the state of one archive in one destination.
+// +kubebuilder:validation:Enum=Replicated;Failed
+type ArchiveReplicaPhase string
+
+const (
+	// ArchiveReplicaReplicated means the archive is present in the destination.
+	ArchiveReplicaReplicated ArchiveReplicaPhase = "Replicated"
+	// ArchiveReplicaFailed means the last copy to the destination failed.
+	ArchiveReplicaFailed ArchiveReplicaPhase = "Failed"
+)
+
+// ArchiveReplicaStatus is the state of an archive in one destination.
+type ArchiveReplicaStatus struct {
+	// Destination is the storage location.
+	Destination string `json:"destination"`
+
+	// Phase is the replication state of the archive in the destination.
+	Phase ArchiveReplicaPhase `json:"phase"`
+
+	// ReplicatedTime is when the archive was first seen in the destination.
+	// +optional
+	ReplicatedTime *metav1.Time `json:"replicatedTime,omitempty"`
+
+	// Message explains a failure.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ReplicatedArchive is the replication state of one archive in the source.
+type ReplicatedArchive struct {
+	// Name is the archive file name.
+	Name string `json:"name"`
+
+	// Replicas holds the state of the archive in each destination.
+	// +listType=map
+	// +listMapKey=destination
+	// +optional
+	Replicas []ArchiveReplicaStatus `json:"replicas,omitempty"`
+}
+
+// ArchiveReplicationStatus defines the observed state of ArchiveReplication.
+type ArchiveReplicationStatus struct {
+	// ObservedGeneration is the generation the current status refers to.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// SourceStoragePath is the storage location archives were read from.
+	// +optional
+	SourceStoragePath string `json:"sourceStoragePath,omitempty"`
+
+	// LastSyncTime is when the source was last compared with the
+	// destinations.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// Archives catalogs the archives in the source, newest first, with their
+	// replication state.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Archives []ReplicatedArchive `json:"archives,omitempty"`
+
+	// PendingArchives counts archive copies that have not succeeded yet.
+	// +optional
+	PendingArchives int `json:"pendingArchives,omitempty"`
+
+	// conditions represent the current state of the ArchiveReplication resource.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Source",type=string,JSONPath=`.status.sourceStoragePath`
+// +kubebuilder:printcolumn:name="Pending",type=integer,JSONPath=`.status.pendingArchives`
+// +kubebuilder:printcolumn:name="Last Sync",type=date,JSONPath=`.status.lastSyncTime`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ArchiveReplication is the Schema for the archivereplications API. It copies
+// completed archives from a primary storage location to secondary ones,
+// independently of the backups writing them.
+type ArchiveReplication struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the desired state of ArchiveReplication
+	// +required
+	Spec ArchiveReplicationSpec `json:"spec"`
+
+	// status defines the observed state of ArchiveReplication
+	// +optional
+	Status ArchiveReplicationStatus `json:"status,omitempty,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// ArchiveReplicationList contains a list of ArchiveReplication
+type ArchiveReplicationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ArchiveReplication `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ArchiveReplication{}, &ArchiveReplicationList{})
+}
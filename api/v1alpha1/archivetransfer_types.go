@@ -0,0 +1,152 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TransferMode selects whether the source archive is kept.
+// +kubebuilder:validation:Enum=Copy;Move
+type TransferMode string
+
+const (
+	// TransferModeCopy keeps the archive in the source.
+	TransferModeCopy TransferMode = "Copy"
+	// TransferModeMove removes the archive from the source once copied.
+	TransferModeMove TransferMode = "Move"
+)
+
+// ArchiveTransferSpec names an archive and where to copy or move it.
+// +kubebuilder:validation:XValidation:rule="has(self.backupName) != has(self.sourceStoragePath)",message="exactly one of backupName or sourceStoragePath must be set"
+type ArchiveTransferSpec struct {
+	// ArchiveName is the archive file to transfer.
+	// +kubebuilder:validation:MinLength=1
+	ArchiveName string `json:"archiveName"`
+
+	// BackupName references a ClusterBackup in the same namespace whose
+	// storage location holds the archive.
+	// +optional
+	BackupName string `json:"backupName,omitempty"`
+
+	// SourceStoragePath is the storage location holding the archive, used
+	// instead of backupName.
+	// +kubebuilder:validation:XValidation:rule="self.startsWith('/') || self.startsWith('host://')",message="must be an absolute path or a host:// URI"
+	// +optional
+	SourceStoragePath string `json:"sourceStoragePath,omitempty"`
+
+	// DestinationStoragePath is the storage location the archive is
+	// transferred to. An archive of the same name must not exist there.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:XValidation:rule="self.startsWith('/') || self.startsWith('host://')",message="must be an absolute path or a host:// URI"
+	DestinationStoragePath string `json:"destinationStoragePath"`
+
+	// Mode is Copy to keep the source archive or Move to remove it once
+	// the copy is complete.
+	// +kubebuilder:default:=Copy
+	// +optional
+	Mode TransferMode `json:"mode,omitempty"`
+}
+
+// TransferPhase describes where an ArchiveTransfer is in its lifecycle.
+// +kubebuilder:validation:Enum=InProgress;Completed;Failed
+type TransferPhase string
+
+const (
+	// TransferPhaseInProgress means the archive is being copied.
+	TransferPhaseInProgress TransferPhase = "InProgress"
+	// TransferPhaseCompleted means the archive was transferred.
+	TransferPhaseCompleted TransferPhase = "Completed"
+	// TransferPhaseFailed means the transfer could not be carried out.
+	TransferPhaseFailed TransferPhase = "Failed"
+)
+
+// ArchiveTransferStatus defines the observed state of ArchiveTransfer.
+type ArchiveTransferStatus struct {
+	// Phase represents the current phase of the transfer
+	// +optional
+	Phase TransferPhase `json:"phase,omitempty"`
+
+	// ObservedGeneration is the generation the current status refers to.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// StartTime is the time when the transfer started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time when the transfer finished, successfully or not
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// SourceStoragePath is the storage location the archive was read from
+	// +optional
+	SourceStoragePath string `json:"sourceStoragePath,omitempty"`
+
+	// BackupLocation is the archive in the destination
+	// +optional
+	BackupLocation string `json:"backupLocation,omitempty"`
+
+	// Message provides additional information about the transfer status
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// conditions represent the current state of the ArchiveTransfer resource.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Archive",type=string,JSONPath=`.spec.archiveName`
+// +kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.spec.mode`
+// +kubebuilder:printcolumn:name="Destination",type=string,JSONPath=`.spec.destinationStoragePath`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ArchiveTransfer is the Schema for the archivetransfers API. It copies or
+// moves one archive between storage locations once per generation.
+type ArchiveTransfer struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the desired state of ArchiveTransfer
+	// +required
+	Spec ArchiveTransferSpec `json:"spec"`
+
+	// status defines the observed state of ArchiveTransfer
+	// +optional
+	Status ArchiveTransferStatus `json:"status,omitempty,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// ArchiveTransferList contains a list of ArchiveTransfer
+type ArchiveTransferList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ArchiveTransfer `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ArchiveTransfer{}, &ArchiveTransferList{})
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BackupOperatorConfigName is the name of the only BackupOperatorConfig the
+// operator reads.
+const BackupOperatorConfigName = "default"
+
+// BackupOperatorConfigSpec holds operator-wide defaults. Settings made on a
+// ClusterBackup take precedence over them.
+type BackupOperatorConfigSpec struct {
+	// DefaultStoragePath is used by ClusterBackups that do not set
+	// storagePath.
+	// +kubebuilder:validation:XValidation:rule="self.startsWith('/') || self.startsWith('host://')",message="must be an absolute path or a host:// URI"
+	// +optional
+	DefaultStoragePath string `json:"defaultStoragePath,omitempty"`
+
+	// ClusterName names this cluster in storage path templates
+	// ({{.ClusterName}}). ClusterBackups labeled backup.backup.io/cluster,
+	// such as those of a ClusterBackupSet, use the label instead.
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +optional
+	ClusterName string `json:"clusterName,omitempty"`
+
+	// ExcludeNamespaces are left out of every backup, in addition to the
+	// namespaces each ClusterBackup excludes.
+	// +optional
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+
+	// ExcludeNamespacePatterns are regular expressions, such as ^kube-,
+	// matched against namespace names. Matching namespaces are left out of
+	// every backup, even when a ClusterBackup includes or names them.
+	// +optional
+	ExcludeNamespacePatterns []string `json:"excludeNamespacePatterns,omitempty"`
+
+	// Concurrency is used by ClusterBackups that do not set their own.
+	// +optional
+	Concurrency *BackupConcurrency `json:"concurrency,omitempty"`
+
+	// Pacing is used by ClusterBackups that do not set their own.
+	// +optional
+	Pacing *BackupPacing `json:"pacing,omitempty"`
+
+	// ScratchDir is used by ClusterBackups that do not set their own.
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +optional
+	ScratchDir string `json:"scratchDir,omitempty"`
+
+	// Client limits the rate of the requests sent to the apiserver while
+	// collecting and restoring resources.
+	// +optional
+	Client *ClientRateLimits `json:"client,omitempty"`
+
+	// Metrics tunes the metrics and conditions derived from backup runs.
+	// +optional
+	Metrics *MetricsOptions `json:"metrics,omitempty"`
+
+	// Audit tunes the audit log kept in each storage location.
+	// +optional
+	Audit *AuditOptions `json:"audit,omitempty"`
+
+	// CloudEvents publishes backup lifecycle events to a sink.
+	// +optional
+	CloudEvents *CloudEventsSink `json:"cloudEvents,omitempty"`
+
+	// Notifications publish the same CloudEvents to cloud messaging
+	// services.
+	// +optional
+	Notifications []NotificationSink `json:"notifications,omitempty"`
+}
+
+// NotificationSink publishes CloudEvents to a cloud messaging service.
+// Credentials come from the cloud's default chain, as for KMS keys.
+type NotificationSink struct {
+	// Provider is the messaging service.
+	// +kubebuilder:validation:Enum=aws-sns;gcp-pubsub;azure-servicebus
+	Provider string `json:"provider"`
+
+	// Target identifies where events are published:
+	//   - aws-sns: a topic ARN
+	//   - gcp-pubsub: projects/<project>/topics/<topic>
+	//   - azure-servicebus: https://<namespace>.servicebus.windows.net/<queue or topic>
+	// +kubebuilder:validation:MinLength=1
+	Target string `json:"target"`
+
+	// Types limits the published events to these types. Empty publishes
+	// every type.
+	// +optional
+	Types []CloudEventType `json:"types,omitempty"`
+}
+
+// CloudEventType is the type of a published CloudEvent.
+// +kubebuilder:validation:Enum=backup.completed;backup.failed;restore.completed;restore.failed;archive.deleted
+type CloudEventType string
+
+// CloudEventsProtocol selects how CloudEvents are delivered.
+// +kubebuilder:validation:Enum=HTTP;Kafka
+type CloudEventsProtocol string
+
+const (
+	// CloudEventsProtocolHTTP posts each event to the URL in structured
+	// content mode.
+	CloudEventsProtocolHTTP CloudEventsProtocol = "HTTP"
+	// CloudEventsProtocolKafka produces each event to a topic through a
+	// Kafka REST Proxy at the URL.
+	CloudEventsProtocolKafka CloudEventsProtocol = "Kafka"
+)
+
+// CloudEventsSink is where backup.completed, backup.failed,
+// restore.completed, restore.failed and archive.deleted events are
+// published.
+// +kubebuilder:validation:XValidation:rule="self.protocol != 'Kafka' || has(self.topic)",message="topic is required for the Kafka protocol"
+type CloudEventsSink struct {
+	// Protocol is HTTP or Kafka.
+	// +kubebuilder:default=HTTP
+	// +optional
+	Protocol CloudEventsProtocol `json:"protocol,omitempty"`
+
+	// URL of the HTTP endpoint, or of the Kafka REST Proxy.
+	// +kubebuilder:validation:XValidation:rule="self.startsWith('http://') || self.startsWith('https://')",message="must be an http:// or https:// URL"
+	URL string `json:"url"`
+
+	// Topic the events are produced to with the Kafka protocol.
+	// +optional
+	Topic string `json:"topic,omitempty"`
+}
+
+// AuditOptions tunes the audit log of backup, restore and delete operations.
+type AuditOptions struct {
+	// Events also emits every audit record as an Audited event on the
+	// object the operation was run for.
+	// +optional
+	Events bool `json:"events,omitempty"`
+}
+
+// ClientRateLimits configures the client-side rate limiter. Unset values
+// keep the limits the operator was started with.
+type ClientRateLimits struct {
+	// QPS is the sustained number of requests per second.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	QPS *int32 `json:"qps,omitempty"`
+
+	// Burst is the number of requests that may be sent at once.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Burst *int32 `json:"burst,omitempty"`
+}
+
+// MetricsOptions tunes the metrics and conditions derived from backup runs.
+type MetricsOptions struct {
+	// StaleBackupThreshold is the number of schedule periods without a
+	// successful run after which a scheduled ClusterBackup is marked Stale,
+	// overriding the --stale-backup-threshold flag. For example "2" or "1.5".
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	// +optional
+	StaleBackupThreshold string `json:"staleBackupThreshold,omitempty"`
+
+	// DegradedFailureThreshold is the number of consecutive failed runs
+	// after which a ClusterBackup is marked Degraded, overriding the
+	// --degraded-failure-threshold flag.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	DegradedFailureThreshold *int32 `json:"degradedFailureThreshold,omitempty"`
+}
+
+// BackupOperatorConfigStatus defines the observed state of BackupOperatorConfig.
+type BackupOperatorConfigStatus struct {
+	// ObservedGeneration is the generation the current status refers to.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// conditions represent the current state of the BackupOperatorConfig resource.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:validation:XValidation:rule="self.metadata.name == 'default'",message="the BackupOperatorConfig must be named default"
+// +kubebuilder:printcolumn:name="Storage",type=string,JSONPath=`.spec.defaultStoragePath`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// BackupOperatorConfig is the Schema for the backupoperatorconfigs API. The
+// operator reads the single object named "default".
+type BackupOperatorConfig struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the operator-wide defaults
+	// +optional
+	Spec BackupOperatorConfigSpec `json:"spec,omitempty"`
+
+	// status defines the observed state of BackupOperatorConfig
+	// +optional
+	Status BackupOperatorConfigStatus `json:"status,omitempty,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// BackupOperatorConfigList contains a list of BackupOperatorConfig
+type BackupOperatorConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BackupOperatorConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BackupOperatorConfig{}, &BackupOperatorConfigList{})
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BackupPolicySpec holds settings shared by the ClusterBackups that
+// reference the policy. Settings made on a ClusterBackup take precedence.
+// +kubebuilder:validation:XValidation:rule="!has(self.rotation) || !has(self.maxArchives)",message="rotation and maxArchives are mutually exclusive"
+type BackupPolicySpec struct {
+	// IncludeNamespaces is used by ClusterBackups that do not set their own.
+	// +optional
+	IncludeNamespaces []string `json:"includeNamespaces,omitempty"`
+
+	// ExcludeNamespaces are left out in addition to the namespaces each
+	// ClusterBackup excludes.
+	// +optional
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+
+	// ResourceTypes is used by ClusterBackups that do not set their own.
+	// +optional
+	ResourceTypes []string `json:"resourceTypes,omitempty"`
+
+	// ExcludeGitOpsManaged leaves out objects tracked by Argo CD or Flux.
+	// +optional
+	ExcludeGitOpsManaged bool `json:"excludeGitOpsManaged,omitempty"`
+
+	// IncludeGeneratedResources backs up service account token Secrets and
+	// kube-root-ca.crt ConfigMaps.
+	// +optional
+	IncludeGeneratedResources bool `json:"includeGeneratedResources,omitempty"`
+
+	// IncludeFinishedWorkloads backs up Pods that succeeded or failed and
+	// Jobs that completed or failed.
+	// +optional
+	IncludeFinishedWorkloads bool `json:"includeFinishedWorkloads,omitempty"`
+
+	// SkipReissuableCertificateSecrets leaves out Secrets cert-manager can
+	// issue again.
+	// +optional
+	SkipReissuableCertificateSecrets bool `json:"skipReissuableCertificateSecrets,omitempty"`
+
+	// IncludeReferencedResources also backs up the ConfigMaps, Secrets and
+	// PersistentVolumeClaims referenced by backed-up workloads.
+	// +optional
+	IncludeReferencedResources bool `json:"includeReferencedResources,omitempty"`
+
+	// Concurrency is used by ClusterBackups that do not set their own.
+	// +optional
+	Concurrency *BackupConcurrency `json:"concurrency,omitempty"`
+
+	// Pacing is used by ClusterBackups that do not set their own.
+	// +optional
+	Pacing *BackupPacing `json:"pacing,omitempty"`
+
+	// Encryption is used by ClusterBackups that do not set their own.
+	// +optional
+	Encryption *BackupEncryption `json:"encryption,omitempty"`
+
+	// RetentionDays is used by ClusterBackups that do not set their own.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	RetentionDays *int `json:"retentionDays,omitempty"`
+
+	// MaxArchives is used by ClusterBackups that set neither maxArchives nor
+	// rotation.
+	// +optional
+	MaxArchives *int `json:"maxArchives,omitempty"`
+
+	// Rotation is used by ClusterBackups that set neither rotation nor
+	// maxArchives.
+	// +optional
+	Rotation *ArchiveRotation `json:"rotation,omitempty"`
+
+	// MaxTotalSize is used by ClusterBackups that do not set their own.
+	// +optional
+	MaxTotalSize *resource.Quantity `json:"maxTotalSize,omitempty"`
+
+	// Immutability is used by ClusterBackups that do not set their own.
+	// +optional
+	Immutability *ArchiveImmutability `json:"immutability,omitempty"`
+}
+
+// BackupPolicyStatus defines the observed state of BackupPolicy.
+type BackupPolicyStatus struct {
+	// ObservedGeneration is the generation the current status refers to.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// conditions represent the current state of the BackupPolicy resource.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// BackupPolicy is the Schema for the backuppolicies API. ClusterBackups in
+// the same namespace reference it through spec.policyName.
+type BackupPolicy struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the shared backup settings
+	// +optional
+	Spec BackupPolicySpec `json:"spec,omitempty"`
+
+	// status defines the observed state of BackupPolicy
+	// +optional
+	Status BackupPolicyStatus `json:"status,omitempty,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// BackupPolicyList contains a list of BackupPolicy
+type BackupPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BackupPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BackupPolicy{}, &BackupPolicyList{})
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BackupRunPhase describes where a BackupRun is in its lifecycle.
+// +kubebuilder:validation:Enum=Running;Succeeded;Failed
+type BackupRunPhase string
+
+const (
+	// BackupRunPhaseRunning means the run has not finished yet.
+	BackupRunPhaseRunning BackupRunPhase = "Running"
+	// BackupRunPhaseSucceeded means the run stored an archive.
+	BackupRunPhaseSucceeded BackupRunPhase = "Succeeded"
+	// BackupRunPhaseFailed means the run failed.
+	BackupRunPhaseFailed BackupRunPhase = "Failed"
+)
+
+// BackupRunSpec identifies the run a BackupRun records. The operator creates
+// one for every run of a ClusterBackup.
+type BackupRunSpec struct {
+	// BackupName is the ClusterBackup the run belongs to.
+	// +kubebuilder:validation:MinLength=1
+	BackupName string `json:"backupName"`
+
+	// RunID identifies the run in the operator's logs, events and metrics and
+	// in the manifest of its archive.
+	// +kubebuilder:validation:MinLength=1
+	RunID string `json:"runID"`
+
+	// Trigger records what started the run.
+	// +optional
+	Trigger RunTrigger `json:"trigger,omitempty"`
+}
+
+// BackupRunStatus holds the result of the run.
+type BackupRunStatus struct {
+	// Phase is Running until the run finishes, then Succeeded or Failed.
+	// +optional
+	Phase BackupRunPhase `json:"phase,omitempty"`
+
+	// StartTime is when the run started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is when the run finished.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Archive is the name of the archive the run stored.
+	// +optional
+	Archive string `json:"archive,omitempty"`
+
+	// BackupLocation is where the archive was stored.
+	// +optional
+	BackupLocation string `json:"backupLocation,omitempty"`
+
+	// Size is the size of the archive.
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// ResourceCount is the number of resources backed up.
+	// +optional
+	ResourceCount int `json:"resourceCount,omitempty"`
+
+	// Partial is set when the run was interrupted by an operator shutdown
+	// and the archive holds only the resources collected until then.
+	// +optional
+	Partial bool `json:"partial,omitempty"`
+
+	// BaseArchive is the archive an incremental run reused unchanged
+	// resources from.
+	// +optional
+	BaseArchive string `json:"baseArchive,omitempty"`
+
+	// StorageLocations reports the outcome for storagePath and each replica
+	// storage location.
+	// +listType=map
+	// +listMapKey=storagePath
+	// +optional
+	StorageLocations []StorageLocationStatus `json:"storageLocations,omitempty"`
+
+	// SkippedResources lists the resources the run left out because the
+	// operator may not list them.
+	// +optional
+	SkippedResources []string `json:"skippedResources,omitempty"`
+
+	// SlowestResources are the resource types the run spent the most time
+	// on, slowest first.
+	// +optional
+	SlowestResources []ResourceTiming `json:"slowestResources,omitempty"`
+
+	// Message describes the result, or the error of a failed run.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Logs points at the operator log lines of the run.
+	// +optional
+	Logs *BackupRunLogs `json:"logs,omitempty"`
+}
+
+// BackupRunLogs locates the log lines of a run: those of Pod carrying the
+// run ID.
+type BackupRunLogs struct {
+	// Pod is the operator pod that ran the backup.
+	// +optional
+	Pod string `json:"pod,omitempty"`
+
+	// Namespace is the namespace of Pod, when the operator knows it.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Backup",type=string,JSONPath=`.spec.backupName`
+// +kubebuilder:printcolumn:name="Trigger",type=string,JSONPath=`.spec.trigger`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Resources",type=integer,JSONPath=`.status.resourceCount`
+// +kubebuilder:printcolumn:name="Size",type=string,JSONPath=`.status.size`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// BackupRun records one run of a ClusterBackup.
+type BackupRun struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec identifies the run
+	// +required
+	Spec BackupRunSpec `json:"spec"`
+
+	// status holds the result of the run
+	// +optional
+	Status BackupRunStatus `json:"status,omitempty,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// BackupRunList contains a list of BackupRun
+type BackupRunList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BackupRun `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BackupRun{}, &BackupRunList{})
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CleanupPolicySpec defines which storage locations are cleaned up and which
+// archives are kept.
+// +kubebuilder:validation:XValidation:rule="has(self.backupName) != has(self.storagePaths)",message="exactly one of backupName or storagePaths must be set"
+// +kubebuilder:validation:XValidation:rule="has(self.retentionDays) || has(self.maxArchives) || has(self.rotation) || has(self.maxTotalSize)",message="at least one of retentionDays, maxArchives, rotation or maxTotalSize must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.rotation) || !has(self.maxArchives)",message="rotation and maxArchives are mutually exclusive"
+type CleanupPolicySpec struct {
+	// BackupName references a ClusterBackup in the same namespace whose
+	// storage location and replica locations are cleaned up.
+	// +optional
+	BackupName string `json:"backupName,omitempty"`
+
+	// StoragePaths are the storage locations cleaned up, used instead of
+	// backupName.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=8
+	// +kubebuilder:validation:XValidation:rule="self.all(p, p.startsWith('/') || p.startsWith('host://'))",message="storage paths must be absolute paths or host:// URIs"
+	// +listType=set
+	// +optional
+	StoragePaths []string `json:"storagePaths,omitempty"`
+
+	// RetentionDays removes archives older than this many days.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	RetentionDays *int `json:"retentionDays,omitempty"`
+
+	// MaxArchives keeps at most this many archives in each location.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxArchives *int `json:"maxArchives,omitempty"`
+
+	// Rotation keeps the newest daily, weekly and monthly archives, as for
+	// ClusterBackups.
+	// +optional
+	Rotation *ArchiveRotation `json:"rotation,omitempty"`
+
+	// MaxTotalSize caps the combined size of the archives in each location,
+	// removing the oldest first.
+	// +optional
+	MaxTotalSize *resource.Quantity `json:"maxTotalSize,omitempty"`
+
+	// Interval is how often retention is enforced.
+	// +kubebuilder:default:="1h"
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// CleanupPolicyStatus defines the observed state of CleanupPolicy.
+type CleanupPolicyStatus struct {
+	// ObservedGeneration is the generation the current status refers to.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// StoragePaths are the storage locations last cleaned up.
+	// +optional
+	StoragePaths []string `json:"storagePaths,omitempty"`
+
+	// LastCleanupTime is when retention was last enforced.
+	// +optional
+	LastCleanupTime *metav1.Time `json:"lastCleanupTime,omitempty"`
+
+	// RemovedArchives lists the archives the last cleanup removed.
+	// +optional
+	RemovedArchives []string `json:"removedArchives,omitempty"`
+
+	// LockedArchives lists archives retention would have removed but that
+	// are locked.
+	// +optional
+	LockedArchives []string `json:"lockedArchives,omitempty"`
+
+	// conditions represent the current state of the CleanupPolicy resource.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Backup",type=string,JSONPath=`.spec.backupName`
+// +kubebuilder:printcolumn:name="Last Cleanup",type=date,JSONPath=`.status.lastCleanupTime`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// CleanupPolicy is the Schema for the cleanuppolicies API. It enforces
+// retention on storage locations on its own schedule, so old archives are
+// removed even while the backups writing them fail or are not scheduled.
+type CleanupPolicy struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the desired state of CleanupPolicy
+	// +required
+	Spec CleanupPolicySpec `json:"spec"`
+
+	// status defines the observed state of CleanupPolicy
+	// +optional
+	Status CleanupPolicyStatus `json:"status,omitempty,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// CleanupPolicyList contains a list of CleanupPolicy
+type CleanupPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CleanupPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CleanupPolicy{}, &CleanupPolicyList{})
+}
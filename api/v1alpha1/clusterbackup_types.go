@@ -17,18 +17,59 @@ limitations under the License.
 package v1alpha1
 
 import (
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// TriggerAnnotation starts an out-of-cycle run of a ClusterBackup whenever
+// its value changes, e.g. to a timestamp or another nonce.
+const TriggerAnnotation = "backup.backup.io/trigger"
+
+// RunTrigger names what started a backup run.
+// +kubebuilder:validation:Enum=Scheduled;Manual
+type RunTrigger string
+
+const (
+	// RunTriggerScheduled is a run of spec.schedule or spec.resourceSchedules.
+	RunTriggerScheduled RunTrigger = "Scheduled"
+	// RunTriggerManual is the run of a one-shot ClusterBackup or one started
+	// through TriggerAnnotation.
+	RunTriggerManual RunTrigger = "Manual"
+)
+
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
 // ClusterBackupSpec defines the desired state of ClusterBackup
+// +kubebuilder:validation:XValidation:rule="!has(self.items) || !has(self.application)",message="items and application are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!has(self.rotation) || !has(self.maxArchives)",message="rotation and maxArchives are mutually exclusive"
 type ClusterBackupSpec struct {
 	// StoragePath defines where the backup archive will be stored
-	// This can be a local path or a cloud storage URL (e.g., s3://bucket/path)
-	// +kubebuilder:validation:Required
-	StoragePath string `json:"storagePath"`
+	// This is an absolute path or a host:// URI.
+	// Defaults to the defaultStoragePath of the BackupOperatorConfig.
+	// {{.ClusterName}}, {{.Namespace}} and {{.BackupName}} are replaced,
+	// here and in replicaStoragePaths and tiering.coldStoragePath.
+	// +kubebuilder:validation:XValidation:rule="self.startsWith('/') || self.startsWith('host://')",message="must be an absolute path or a host:// URI"
+	// +optional
+	StoragePath string `json:"storagePath,omitempty"`
+
+	// ReplicaStoragePaths are additional storage locations every archive is
+	// copied to, in parallel with the upload to storagePath. A failed copy is
+	// reported in status.storageLocations and the Replicated condition
+	// without failing the backup. Restores read from storagePath.
+	// +kubebuilder:validation:MaxItems=8
+	// +kubebuilder:validation:XValidation:rule="self.all(p, p.startsWith('/') || p.startsWith('host://'))",message="storage paths must be absolute paths or host:// URIs"
+	// +listType=set
+	// +optional
+	ReplicaStoragePaths []string `json:"replicaStoragePaths,omitempty"`
+
+	// PolicyName references a BackupPolicy in the same namespace whose
+	// settings apply where this ClusterBackup leaves them unset. The backup
+	// fails while the policy does not exist.
+	// +optional
+	PolicyName string `json:"policyName,omitempty"`
 
 	// IncludeNamespaces specifies which namespaces to include in the backup
 	// If empty, all namespaces will be backed up
@@ -45,18 +86,196 @@ type ClusterBackupSpec struct {
 	// +optional
 	IncludeClusterResources *bool `json:"includeClusterResources,omitempty"`
 
+	// ClusterResources narrows the cluster-scoped kinds backed up when
+	// includeClusterResources is set. Namespaces are still selected by
+	// resourceTypes.
+	// +optional
+	ClusterResources *ClusterResourceFilter `json:"clusterResources,omitempty"`
+
 	// ResourceTypes specifies which resource types to backup
-	// If empty, common resource types will be backed up
+	// If empty, common resource types will be backed up. Entries such as
+	// "*.monitoring.coreos.com" select every kind of a group and its
+	// subgroups.
 	// +optional
 	ResourceTypes []string `json:"resourceTypes,omitempty"`
 
+	// Items backs up only the named objects, given as "kind/namespace/name"
+	// or "kind/name" for cluster-scoped ones, instead of scanning the
+	// cluster. kind may be qualified by its group, as in "deployment.apps".
+	// Namespace and resource type filters are ignored, and the backup fails
+	// if an item cannot be read.
+	// +kubebuilder:validation:MaxItems=200
+	// +listType=set
+	// +optional
+	Items []string `json:"items,omitempty"`
+
+	// IncludeReferencedResources also backs up the ConfigMaps, Secrets and
+	// PersistentVolumeClaims referenced by the volumes, environment and
+	// imagePullSecrets of backed-up workloads, even when their types were not
+	// selected, so restored workloads find their configuration.
+	// +optional
+	IncludeReferencedResources bool `json:"includeReferencedResources,omitempty"`
+
+	// Application backs up one application: the root object it names, in
+	// the same form as items, every object the root transitively owns
+	// through ownerReferences, and the ConfigMaps, Secrets and
+	// PersistentVolumeClaims their pod templates reference. Owned objects
+	// are looked up in the root's namespace. Namespace and resource type
+	// filters are ignored.
+	// +optional
+	Application string `json:"application,omitempty"`
+
+	// Concurrency tunes how many List calls are issued in parallel. Lower
+	// values reduce apiserver load, higher values shorten the backup.
+	// +optional
+	Concurrency *BackupConcurrency `json:"concurrency,omitempty"`
+
+	// Pacing bounds the objects fetched per List call and spaces the calls
+	// out, keeping the operator within tight memory and CPU limits.
+	// +optional
+	Pacing *BackupPacing `json:"pacing,omitempty"`
+
+	// ScratchDir names the scratch directory, registered with the
+	// operator's --scratch-dir flag, that archives are staged in before
+	// they are stored, e.g. one backed by a memory emptyDir or a
+	// PersistentVolumeClaim. Defaults to the operator config's, then to
+	// --temp-dir.
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +optional
+	ScratchDir string `json:"scratchDir,omitempty"`
+
+	// ExcludeGitOpsManaged leaves objects tracked by Argo CD
+	// (argocd.argoproj.io/instance label or tracking-id annotation) or Flux
+	// (kustomize.toolkit.fluxcd.io/name or helm.toolkit.fluxcd.io/name
+	// label) out of the archive, since GitOps recreates them from git.
+	// +optional
+	ExcludeGitOpsManaged bool `json:"excludeGitOpsManaged,omitempty"`
+
+	// IncludeGeneratedResources backs up the service account token Secrets
+	// and kube-root-ca.crt ConfigMaps that Kubernetes generates for each
+	// cluster. They are left out by default because their content is only
+	// valid in the cluster they were taken from.
+	// +optional
+	IncludeGeneratedResources bool `json:"includeGeneratedResources,omitempty"`
+
+	// IncludeFinishedWorkloads backs up Pods that succeeded or failed and
+	// Jobs that completed or failed. They are left out by default because
+	// they never run again once restored.
+	// +optional
+	IncludeFinishedWorkloads bool `json:"includeFinishedWorkloads,omitempty"`
+
+	// SkipReissuableCertificateSecrets leaves out the Secrets cert-manager
+	// issued for Certificates that still exist, and the private keys of
+	// in-flight issuances, since cert-manager issues them again after a
+	// restore. Secrets that CA Issuers or ClusterIssuers sign with are always
+	// backed up.
+	// +optional
+	SkipReissuableCertificateSecrets bool `json:"skipReissuableCertificateSecrets,omitempty"`
+
+	// Compression applied to archives. Restores detect the compression of
+	// each archive, so changing it does not affect existing archives.
+	// +kubebuilder:validation:Enum=gzip;zstd;none
+	// +kubebuilder:default=gzip
+	// +optional
+	Compression string `json:"compression,omitempty"`
+
+	// Incremental skips writing objects whose resourceVersion is unchanged
+	// since the latest full archive. Their entries are referenced from that
+	// archive, which is kept while it is referenced, so steady-state runs
+	// mostly write what changed. Cannot be combined with encryption, items
+	// or application.
+	// +optional
+	Incremental *IncrementalBackup `json:"incremental,omitempty"`
+
+	// Encryption encrypts archives before they are written to storage.
+	// +optional
+	Encryption *BackupEncryption `json:"encryption,omitempty"`
+
+	// SecretEncryption writes Secrets encrypted on their own, as
+	// SealedSecrets or SOPS files, so archives and GitOps exports never
+	// hold plaintext credentials even without whole-archive encryption.
+	// +optional
+	SecretEncryption *SecretEncryption `json:"secretEncryption,omitempty"`
+
+	// GitExport also writes the backed-up manifests as plain YAML in a
+	// kustomize-friendly directory tree, optionally committed to a git
+	// repository, so every backup becomes a reviewable change that GitOps
+	// tooling can apply.
+	// +optional
+	GitExport *GitExport `json:"gitExport,omitempty"`
+
+	// Continuous also watches the selected resources and appends every
+	// change to a change log in storagePath, so changes made between
+	// scheduled backups are kept. It cannot be combined with items, an
+	// application or encryption.
+	// +optional
+	Continuous *ContinuousBackup `json:"continuous,omitempty"`
+
+	// Actions are Jobs run in the namespace of the ClusterBackup before and
+	// after every backup run, for example to export a database into a
+	// PersistentVolumeClaim that the backup then includes.
+	// +optional
+	Actions *BackupActions `json:"actions,omitempty"`
+
+	// RetryPolicy retries failed runs, e.g. after a storage outage or an API
+	// server restart, instead of waiting for the next scheduled run.
+	// +optional
+	RetryPolicy *BackupRetryPolicy `json:"retryPolicy,omitempty"`
+
 	// Schedule defines a cron schedule for automatic backups
 	// If empty, backup runs once when the resource is created
+	// Either a duration such as "24h", a five-field cron expression such as
+	// "0 2 * * *" or a macro such as "@daily". Cron expressions are evaluated
+	// in UTC.
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:XValidation:rule="self.matches('^([0-9]+([.][0-9]+)?(ns|us|ms|s|m|h))+$') || self.matches('^@(yearly|annually|monthly|weekly|daily|midnight|hourly)$') || self.matches('^(([*]|([0-5]?[0-9])(-([0-5]?[0-9]))?)(/[0-9]+)?(,([*]|([0-5]?[0-9])(-([0-5]?[0-9]))?)(/[0-9]+)?)*) +(([*]|([01]?[0-9]|2[0-3])(-([01]?[0-9]|2[0-3]))?)(/[0-9]+)?(,([*]|([01]?[0-9]|2[0-3])(-([01]?[0-9]|2[0-3]))?)(/[0-9]+)?)*) +([?]|([*]|(0?[1-9]|[12][0-9]|3[01])(-(0?[1-9]|[12][0-9]|3[01]))?)(/[0-9]+)?(,([*]|(0?[1-9]|[12][0-9]|3[01])(-(0?[1-9]|[12][0-9]|3[01]))?)(/[0-9]+)?)*) +(([*]|(0?[1-9]|1[0-2]|(?i:jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec))(-(0?[1-9]|1[0-2]|(?i:jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)))?)(/[0-9]+)?(,([*]|(0?[1-9]|1[0-2]|(?i:jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec))(-(0?[1-9]|1[0-2]|(?i:jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)))?)(/[0-9]+)?)*) +([?]|([*]|(0?[0-6]|(?i:sun|mon|tue|wed|thu|fri|sat))(-(0?[0-6]|(?i:sun|mon|tue|wed|thu|fri|sat)))?)(/[0-9]+)?(,([*]|(0?[0-6]|(?i:sun|mon|tue|wed|thu|fri|sat))(-(0?[0-6]|(?i:sun|mon|tue|wed|thu|fri|sat)))?)(/[0-9]+)?)*)$')",message="schedule must be a duration, a five-field cron expression or a cron macro"
 	// +optional
 	Schedule string `json:"schedule,omitempty"`
 
+	// ResourceSchedules back up some resource types on their own cadence,
+	// for example Secrets and ConfigMaps hourly while everything else
+	// follows schedule. Each run collects only the resource types that are
+	// due, so its archive holds a subset of the selection. Requires
+	// schedule, and cannot be combined with items or application.
+	// +kubebuilder:validation:MaxItems=20
+	// +optional
+	ResourceSchedules []ResourceSchedule `json:"resourceSchedules,omitempty"`
+
+	// Estimate only sizes the backup: the selected resources are listed and
+	// encoded as for a real run, and status.estimate reports how many there
+	// are and how large the archive would be, but nothing is written to
+	// storage. The estimate runs once per generation; unset it to take real
+	// backups.
+	// +optional
+	Estimate bool `json:"estimate,omitempty"`
+
+	// MissingPermissionPolicy decides what happens when the check run before
+	// each backup finds selected resources the operator may not list: Fail
+	// stops the backup before anything is collected, Skip leaves them out
+	// and lists them in status.skippedResources. Backups of items or an
+	// application are not checked.
+	// +kubebuilder:validation:Enum=Fail;Skip
+	// +kubebuilder:default=Skip
+	// +optional
+	MissingPermissionPolicy string `json:"missingPermissionPolicy,omitempty"`
+
+	// Impersonate runs backups, estimates and inline restores as another
+	// identity, so they are bound by its RBAC instead of the operator's.
+	// spec.restore.impersonate takes precedence for restores.
+	// +optional
+	Impersonate *Impersonation `json:"impersonate,omitempty"`
+
+	// ClusterRef backs up, estimates and restores into a remote cluster
+	// reached through a kubeconfig stored in a Secret, instead of the
+	// cluster the operator runs in. spec.restore.clusterRef takes
+	// precedence for restores.
+	// +optional
+	ClusterRef *ClusterReference `json:"clusterRef,omitempty"`
+
 	// RetentionDays defines how many days to retain backups. If set, backups
 	// older than this value (based on modification time) will be removed.
+	// +kubebuilder:validation:Minimum=1
 	// +optional
 	RetentionDays *int `json:"retentionDays,omitempty"`
 
@@ -65,6 +284,46 @@ type ClusterBackupSpec struct {
 	// +optional
 	MaxArchives *int `json:"maxArchives,omitempty"`
 
+	// HistoryLimit is how many runs status.history keeps. Defaults to 10.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=50
+	// +optional
+	HistoryLimit *int32 `json:"historyLimit,omitempty"`
+
+	// Rotation tags each run daily, weekly or monthly by when it fires and
+	// keeps a separate number of archives per tag, replacing maxArchives.
+	// +optional
+	Rotation *ArchiveRotation `json:"rotation,omitempty"`
+
+	// MaxTotalSize caps the combined size of the archives in storagePath and
+	// in each replica location, e.g. 50Gi. Once the other retention settings
+	// have run, the oldest archives are removed until the location fits.
+	// Pinned archives and the newest archive are kept but still count, as
+	// do archives of other backups sharing the location.
+	// +optional
+	MaxTotalSize *resource.Quantity `json:"maxTotalSize,omitempty"`
+
+	// PinnedArchives names archives exempt from retentionDays,
+	// maxArchives and maxTotalSize, e.g. snapshots taken before an upgrade. The operator keeps
+	// a keep marker next to each listed archive in every storage location
+	// and removes it once the archive is unlisted. Markers created by hand
+	// (<archive>.keep) pin an archive as well.
+	// +kubebuilder:validation:MaxItems=100
+	// +listType=set
+	// +optional
+	PinnedArchives []string `json:"pinnedArchives,omitempty"`
+
+	// Tiering moves archives to a cold storage location once they are old
+	// enough, instead of keeping them in storagePath. retentionDays also
+	// applies to the cold location, maxArchives only to storagePath.
+	// +optional
+	Tiering *ArchiveTiering `json:"tiering,omitempty"`
+
+	// Immutability locks every archive written by this ClusterBackup, and its
+	// replicas, against deletion by retention, deleteOnDelete and tiering.
+	// +optional
+	Immutability *ArchiveImmutability `json:"immutability,omitempty"`
+
 	// DeleteOnDelete controls whether the operator should remove archives
 	// created by this ClusterBackup when the ClusterBackup CR is deleted.
 	// +optional
@@ -74,15 +333,615 @@ type ClusterBackupSpec struct {
 	// When specified, the controller will attempt to restore the referenced
 	// archive. The restore runs once per generation and archive name pair.
 	// +optional
-	Restore *ClusterRestoreSpec `json:"restore,omitempty"`
+	Restore *RestoreOptions `json:"restore,omitempty"`
 }
 
-// ClusterRestoreSpec contains the parameters needed to restore from a backup archive.
-type ClusterRestoreSpec struct {
+// BackupEncryption configures encryption of archives. Every archive is
+// encrypted with a fresh data key, and only copies of that key wrapped by the
+// configured keys are stored with the archive.
+type BackupEncryption struct {
+	// KMS wraps the data key with a cloud KMS key. The operator uses its
+	// workload credentials for the provider, both to back up and to restore.
+	// +optional
+	KMS *KMSKey `json:"kms,omitempty"`
+
+	// AgeRecipients are age public keys ("age1...") the data key is
+	// encrypted to. Archives encrypted only to age recipients are standard
+	// age files that can also be decrypted with the age CLI.
+	// +optional
+	AgeRecipients []string `json:"ageRecipients,omitempty"`
+
+	// ReencryptArchives re-encrypts existing archives that are not encrypted
+	// with the current keys after every backup run, so rotated keys can be
+	// retired. Archives encrypted to retired age keys are decrypted with the
+	// identities of spec.restore.ageIdentitySecretRef.
+	// +optional
+	ReencryptArchives bool `json:"reencryptArchives,omitempty"`
+}
+
+// SecretEncryption configures how Secrets are written into archives.
+type SecretEncryption struct {
+	// Format is SealedSecrets, to write every Secret as a Bitnami
+	// SealedSecret the sealed-secrets controller unseals on restore, or
+	// SOPS, to encrypt their data to age recipients in the SOPS format.
+	// +kubebuilder:validation:Enum=SealedSecrets;SOPS
+	Format string `json:"format"`
+
+	// SealedSecrets configures the SealedSecrets format.
+	// +optional
+	SealedSecrets *SealedSecretsEncryption `json:"sealedSecrets,omitempty"`
+
+	// SOPS configures the SOPS format.
+	// +optional
+	SOPS *SOPSEncryption `json:"sops,omitempty"`
+}
+
+// SealedSecretsEncryption selects the certificate Secrets are sealed to.
+type SealedSecretsEncryption struct {
+	// ControllerNamespace is where the sealed-secrets controller keeps its
+	// sealing keys. Secrets are sealed to its newest active key.
+	// +kubebuilder:default=kube-system
+	// +optional
+	ControllerNamespace string `json:"controllerNamespace,omitempty"`
+
+	// Certificate is the PEM encoded sealing certificate, as printed by
+	// "kubeseal --fetch-cert". It takes precedence over the controller's
+	// keys.
+	// +optional
+	Certificate string `json:"certificate,omitempty"`
+}
+
+// SOPSEncryption lists the keys SOPS encrypted Secrets can be decrypted
+// with.
+type SOPSEncryption struct {
+	// AgeRecipients are age public keys ("age1...") the data keys are
+	// encrypted to. Restores decrypt with the identities of
+	// spec.restore.ageIdentitySecretRef.
+	// +kubebuilder:validation:MinItems=1
+	AgeRecipients []string `json:"ageRecipients"`
+}
+
+// BackupRetryPolicy sets how often and when failed runs are retried.
+type BackupRetryPolicy struct {
+	// MaxRetries is how many times a failed run is retried before it stays
+	// Failed.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10
+	// +kubebuilder:default:=3
+	// +optional
+	MaxRetries int32 `json:"maxRetries,omitempty"`
+
+	// Backoff is the delay before the first retry. Each further retry waits
+	// twice as long as the one before, up to an hour. Defaults to 1m.
+	// +optional
+	Backoff *metav1.Duration `json:"backoff,omitempty"`
+}
+
+// BackupActions lists the Jobs run around every backup run.
+type BackupActions struct {
+	// Pre Jobs run one after another before the resources are collected.
+	// +optional
+	Pre []BackupJobAction `json:"pre,omitempty"`
+
+	// Post Jobs run one after another once the archive is written, or the
+	// backup failed.
+	// +optional
+	Post []BackupJobAction `json:"post,omitempty"`
+}
+
+// BackupJobAction is a Job run as part of a backup run.
+type BackupJobAction struct {
+	// Name identifies the action and is part of the name of its Jobs.
+	// +kubebuilder:validation:MaxLength=20
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// JobSpec is the spec of the Job. The restart policy of its pods
+	// defaults to Never.
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	JobSpec batchv1.JobSpec `json:"jobSpec"`
+
+	// Timeout bounds how long the Job may take to complete.
+	// +kubebuilder:default:="10m"
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// FailurePolicy decides what a failed or timed out Job does to the
+	// run: Fail fails the run, and a failed pre action skips the backup;
+	// Ignore only reports it in an event.
+	// +kubebuilder:validation:Enum=Fail;Ignore
+	// +kubebuilder:default=Fail
+	// +optional
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+}
+
+// ContinuousBackup configures the change log of a ClusterBackup.
+type ContinuousBackup struct {
+	// SegmentInterval is how long each change log file covers before the
+	// next one is started. Files ending before the oldest archive are
+	// removed.
+	// +kubebuilder:default:="1h"
+	// +optional
+	SegmentInterval *metav1.Duration `json:"segmentInterval,omitempty"`
+}
+
+// ContinuousBackupStatus reports the change log of a ClusterBackup.
+type ContinuousBackupStatus struct {
+	// Active is set while changes are being recorded.
+	Active bool `json:"active"`
+
+	// Segment is the change log file being written.
+	// +optional
+	Segment string `json:"segment,omitempty"`
+
+	// RecordedChanges counts the changes recorded since the watch started,
+	// as of the last time Active, Segment or Message changed. The
+	// backup_continuous_recorded_changes metric carries the live count.
+	// +optional
+	RecordedChanges int64 `json:"recordedChanges,omitempty"`
+
+	// LastChangeTime is when the latest change was recorded, as of the last
+	// time Active, Segment or Message changed.
+	// +optional
+	LastChangeTime *metav1.Time `json:"lastChangeTime,omitempty"`
+
+	// Message explains why changes are not being recorded.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// IncrementalBackup configures incremental archives.
+type IncrementalBackup struct {
+	// FullInterval is how old the latest full archive may get before the
+	// next run writes a full archive again.
+	// +kubebuilder:default:="168h"
+	// +optional
+	FullInterval *metav1.Duration `json:"fullInterval,omitempty"`
+}
+
+// ResourceSchedule backs up resource types on their own cadence.
+type ResourceSchedule struct {
+	// ResourceTypes lists the kinds following this cadence, as in
+	// resourceTypes. They are left out of the runs of schedule.
+	// +kubebuilder:validation:MinItems=1
+	// +listType=set
+	ResourceTypes []string `json:"resourceTypes"`
+
+	// Interval between backups of these resource types, such as "1h".
+	Interval metav1.Duration `json:"interval"`
+}
+
+// ResourceScheduleStatus reports one cadence of a ClusterBackup.
+type ResourceScheduleStatus struct {
+	// ResourceTypes of the cadence, empty for the resource types following
+	// spec.schedule.
+	// +optional
+	ResourceTypes []string `json:"resourceTypes,omitempty"`
+
+	// LastRunTime is when a run collecting these resource types last
+	// finished, successfully or not.
+	// +optional
+	LastRunTime *metav1.Time `json:"lastRunTime,omitempty"`
+
+	// NextRunTime is when these resource types are due again.
+	// +optional
+	NextRunTime *metav1.Time `json:"nextRunTime,omitempty"`
+
+	// Running is set while the current run collects these resource types.
+	// +optional
+	Running bool `json:"running,omitempty"`
+}
+
+// GitExport configures the GitOps export of a backup.
+type GitExport struct {
+	// Path of the export directory. It is relative to storagePath, or to
+	// the repository root when a repository is set, and defaults to the name
+	// of the ClusterBackup. Its previous contents are replaced on every run.
+	// The storagePath variables and {{.Date}}, the UTC date of the run as
+	// YYYY-MM-DD, are replaced.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// IncludeSecrets exports Secrets as well. They are left out by default
+	// because the export is written unencrypted.
+	// +optional
+	IncludeSecrets bool `json:"includeSecrets,omitempty"`
+
+	// Repository commits the export and pushes it to a git remote instead of
+	// writing it next to the archives.
+	// +optional
+	Repository *GitRepository `json:"repository,omitempty"`
+}
+
+// GitRepository is the branch of a git remote that receives exports.
+type GitRepository struct {
+	// URL of the remote, over HTTPS or SSH.
+	// +kubebuilder:validation:MinLength=1
+	URL string `json:"url"`
+
+	// Branch receiving the commits. It is created if the remote is empty.
+	// +kubebuilder:default:=main
+	// +optional
+	Branch string `json:"branch,omitempty"`
+
+	// CredentialsSecretRef names a Secret in the same namespace holding
+	// "username" and "password" keys for HTTPS remotes, or an "identity" key
+	// with an SSH private key and a "known_hosts" key for SSH remotes.
+	// +optional
+	CredentialsSecretRef *corev1.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
+}
+
+// KMSKey references a key-encryption key held by a cloud KMS.
+type KMSKey struct {
+	// Provider is the KMS service holding the key.
+	// +kubebuilder:validation:Enum=aws-kms;gcp-kms;azure-keyvault
+	Provider string `json:"provider"`
+
+	// KeyID identifies the key: an AWS key or alias ARN, a GCP
+	// projects/.../cryptoKeys/... resource name, or an Azure Key Vault key URL.
+	// +kubebuilder:validation:MinLength=1
+	KeyID string `json:"keyID"`
+}
+
+// ClusterResourceFilter selects cluster-scoped kinds, named as in
+// resourceTypes.
+type ClusterResourceFilter struct {
+	// Include, when set, backs up only these cluster-scoped kinds, whether
+	// or not resourceTypes lists them.
+	// +listType=set
+	// +optional
+	Include []string `json:"include,omitempty"`
+
+	// Exclude leaves these cluster-scoped kinds out, even when include or
+	// resourceTypes lists them.
+	// +listType=set
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// BackupConcurrency bounds the parallelism used while collecting resources.
+// Unset values are derived from the number of discovered resource types and
+// namespaces.
+type BackupConcurrency struct {
+	// ResourceTypes is the number of resource types collected in parallel.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ResourceTypes *int `json:"resourceTypes,omitempty"`
+
+	// Namespaces is the number of namespaces listed in parallel for each
+	// resource type.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Namespaces *int `json:"namespaces,omitempty"`
+}
+
+// BackupPacing trades backup duration for lower peak resource use.
+type BackupPacing struct {
+	// PageSize is the number of objects requested per List call. Defaults
+	// to 500.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PageSize *int `json:"pageSize,omitempty"`
+
+	// PageInterval is waited between the List calls for each resource type
+	// and namespace.
+	// +optional
+	PageInterval *metav1.Duration `json:"pageInterval,omitempty"`
+}
+
+// RestoreThrottle limits the rate at which a restore applies resources.
+type RestoreThrottle struct {
+	// ObjectsPerSecond is the sustained number of resources applied per
+	// second.
+	// +kubebuilder:validation:Minimum=1
+	ObjectsPerSecond int32 `json:"objectsPerSecond"`
+
+	// Burst is the number of resources that may be applied at once.
+	// Defaults to ObjectsPerSecond.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Burst *int32 `json:"burst,omitempty"`
+}
+
+// ScratchUsage is the staging space a backup run used, to size the volume
+// backing its scratch directory.
+type ScratchUsage struct {
+	// Directory the archive was staged in.
+	// +optional
+	Directory string `json:"directory,omitempty"`
+
+	// Peak is the most the run held in the directory at once.
+	// +optional
+	Peak *resource.Quantity `json:"peak,omitempty"`
+
+	// Recommended is half again Peak, the free space a run needs before
+	// it is staged. Size the emptyDir sizeLimit or volume claim above it,
+	// leaving room for growth.
+	// +optional
+	Recommended *resource.Quantity `json:"recommended,omitempty"`
+}
+
+// RestoreCallout is an HTTP endpoint that receives every archived object
+// before the restore applies it, and may return a changed object or skip it.
+type RestoreCallout struct {
+	// Name identifies the call-out in logs and restore failures.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	// URL each object is posted to.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// Resources limits the call-out to these group-qualified resources,
+	// e.g. "deployments.apps" or "configmaps". Empty sends every object.
+	// +optional
+	Resources []string `json:"resources,omitempty"`
+
+	// TimeoutSeconds bounds each call. Defaults to 10.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=60
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+
+	// FailurePolicy decides what happens to an object when the call fails
+	// or its answer is invalid. Fail fails the object; Ignore applies it
+	// as if the call-out was not configured.
+	// +kubebuilder:validation:Enum=Fail;Ignore
+	// +kubebuilder:default:=Fail
+	// +optional
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+}
+
+// QuiescedWorkload is a Deployment or StatefulSet a restore scales down
+// while it applies objects.
+type QuiescedWorkload struct {
+	// Kind of the workload.
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet
+	// +kubebuilder:default:=Deployment
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Namespace of the workload.
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// Name of the workload.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// RestoreOptions contains the parameters needed to restore from a backup archive.
+// It is used inline in a ClusterBackup, whose storage location holds the
+// archive, and embedded in the spec of a ClusterRestore.
+// +kubebuilder:validation:XValidation:rule="has(self.pointInTime) ? !has(self.archiveURL) : has(self.archiveName) != has(self.archiveURL)",message="exactly one of archiveName or archiveURL must be set, or pointInTime without archiveURL"
+// +kubebuilder:validation:XValidation:rule="!has(self.forceConflicts) || self.forceConflicts || (has(self.existingResourcePolicy) && self.existingResourcePolicy == 'Patch')",message="forceConflicts can only be disabled with existingResourcePolicy Patch"
+type RestoreOptions struct {
 	// ArchiveName identifies the archive file sitting inside the configured
 	// storagePath that should be reapplied to the cluster.
 	// +kubebuilder:validation:MinLength=1
-	ArchiveName string `json:"archiveName"`
+	// +optional
+	ArchiveName string `json:"archiveName,omitempty"`
+
+	// ArchiveURL is an https:// URL, such as a pre-signed object storage
+	// link, the archive is streamed from instead of a storage location.
+	// Its query string is never written to status or logs, but anyone able
+	// to read this resource can read the URL.
+	// +kubebuilder:validation:Pattern=`^https://`
+	// +optional
+	ArchiveURL string `json:"archiveURL,omitempty"`
+
+	// ArchiveSHA256 is the expected SHA-256 digest of the whole archive, as
+	// 64 hex characters with an optional "sha256:" prefix. The restore fails
+	// without applying anything when the archive does not match.
+	// +kubebuilder:validation:Pattern=`^(sha256:)?[0-9a-fA-F]{64}$`
+	// +optional
+	ArchiveSHA256 string `json:"archiveSHA256,omitempty"`
+
+	// PointInTime restores the cluster as it was at this time by replaying
+	// the continuous backup change log of the storage location onto the
+	// archive. When archiveName is unset the newest archive written before
+	// this time is used. Objects deleted before this time are not restored;
+	// objects that exist in the cluster are never deleted.
+	// +optional
+	PointInTime *metav1.Time `json:"pointInTime,omitempty"`
+
+	// FailurePolicy controls what happens when an individual resource fails
+	// to apply. Continue keeps applying the remaining resources and reports
+	// the failures in status; FailFast aborts on the first failure.
+	// +kubebuilder:validation:Enum=Continue;FailFast
+	// +kubebuilder:default:=Continue
+	// +optional
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+
+	// ExistingResourcePolicy controls how resources that already exist are
+	// restored. Update replaces them with the archived content; Patch
+	// server-side applies the archived content, setting only the fields
+	// present in the archive so fields managed by other controllers are kept.
+	// +kubebuilder:validation:Enum=Update;Patch
+	// +kubebuilder:default:=Update
+	// +optional
+	ExistingResourcePolicy string `json:"existingResourcePolicy,omitempty"`
+
+	// FieldManager records the restored fields under this field manager
+	// name. Restores sharing it take over each other's fields, so fields an
+	// earlier restore set but the current archive lacks are removed by
+	// Patch. Defaults to backup-operator-restore.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=128
+	// +optional
+	FieldManager string `json:"fieldManager,omitempty"`
+
+	// ForceConflicts lets Patch take over the archived fields other field
+	// managers, such as GitOps controllers, own. When false, resources with
+	// conflicting fields fail and are left unchanged.
+	// +kubebuilder:default:=true
+	// +optional
+	ForceConflicts *bool `json:"forceConflicts,omitempty"`
+
+	// ExcludeGitOpsManaged skips archived objects tracked by Argo CD or Flux,
+	// so the restore does not fight the GitOps controllers that recreate
+	// them.
+	// +optional
+	ExcludeGitOpsManaged bool `json:"excludeGitOpsManaged,omitempty"`
+
+	// IncludeGeneratedResources restores service account token Secrets and
+	// kube-root-ca.crt ConfigMaps found in the archive. They are skipped by
+	// default because the target cluster generates its own.
+	// +optional
+	IncludeGeneratedResources bool `json:"includeGeneratedResources,omitempty"`
+
+	// HelmReleasePolicy controls how Helm release Secrets
+	// (sh.helm.release.v1.*) are restored. Intact restores the archived
+	// release history as it was. Rollback keeps the release Secrets already
+	// in the cluster, adds the archived revisions that are missing and lists
+	// the `helm rollback` commands returning each release to its archived
+	// revision in the restore summary.
+	// +kubebuilder:validation:Enum=Intact;Rollback
+	// +kubebuilder:default:=Intact
+	// +optional
+	HelmReleasePolicy string `json:"helmReleasePolicy,omitempty"`
+
+	// QuotaPolicy controls the check of the archive against the
+	// ResourceQuotas of its target namespaces, made before anything is
+	// applied. Warn reports expected shortfalls in the restore summary and
+	// restores anyway, FailFast fails the restore without applying anything
+	// and Ignore skips the check.
+	// +kubebuilder:validation:Enum=Warn;FailFast;Ignore
+	// +kubebuilder:default:=Warn
+	// +optional
+	QuotaPolicy string `json:"quotaPolicy,omitempty"`
+
+	// ClusterMismatchPolicy controls what happens when the archive was taken
+	// from a different cluster than the one it is restored into, as told by
+	// the UID of its kube-system namespace. Warn reports the mismatch in the
+	// restore summary and restores anyway, Fail fails the restore without
+	// applying anything and Ignore skips the check. Archives that did not
+	// record their cluster are never checked.
+	// +kubebuilder:validation:Enum=Warn;Fail;Ignore
+	// +kubebuilder:default:=Warn
+	// +optional
+	ClusterMismatchPolicy string `json:"clusterMismatchPolicy,omitempty"`
+
+	// RemovedAPIPolicy controls archived resources whose API version the
+	// target cluster no longer serves, such as extensions/v1beta1 Ingresses,
+	// checked against its discovery data before anything is applied.
+	// Convert rewrites them to the version that replaced them where the
+	// conversion is mechanical and fails the rest, Skip leaves them out and
+	// Fail fails the restore without applying anything.
+	// +kubebuilder:validation:Enum=Convert;Skip;Fail
+	// +kubebuilder:default:=Convert
+	// +optional
+	RemovedAPIPolicy string `json:"removedAPIPolicy,omitempty"`
+
+	// IgnoreWebhookFailures sets failurePolicy Ignore on every admission
+	// webhook in the cluster while the restore runs, and puts the original
+	// policies back once it finishes. Use it when webhooks whose backends
+	// are not running yet would otherwise reject restored resources.
+	// +optional
+	IgnoreWebhookFailures bool `json:"ignoreWebhookFailures,omitempty"`
+
+	// Throttle limits how fast resources are applied, so replaying a large
+	// archive does not overwhelm admission webhooks and controllers in the
+	// target cluster.
+	// +optional
+	Throttle *RestoreThrottle `json:"throttle,omitempty"`
+
+	// Callouts are called in order for every archived object before it is
+	// applied. Each may return a changed object or skip it, so objects can
+	// be transformed by logic outside the operator.
+	// +kubebuilder:validation:MaxItems=10
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Callouts []RestoreCallout `json:"callouts,omitempty"`
+
+	// ScaleWorkloadsToZero restores Deployments and StatefulSets with zero
+	// replicas and records the archived count in the
+	// backup.backup.io/original-replicas annotation, so configuration and
+	// storage can be checked before any pod starts. Existing workloads that
+	// are updated are scaled down as well.
+	// +optional
+	ScaleWorkloadsToZero bool `json:"scaleWorkloadsToZero,omitempty"`
+
+	// Quiesce lists workloads, such as GitOps controllers or operators that
+	// would fight the restore over the objects it applies, to scale to zero
+	// before the restore starts and back to their previous replicas once it
+	// ends. The recorded count is kept in the
+	// backup.backup.io/quiesced-replicas annotation until then.
+	// +kubebuilder:validation:MaxItems=20
+	// +optional
+	Quiesce []QuiescedWorkload `json:"quiesce,omitempty"`
+
+	// AgeIdentitySecretRef references a Secret in the same namespace holding
+	// age identities able to decrypt archives encrypted to age recipients.
+	// +optional
+	AgeIdentitySecretRef *SecretKeyReference `json:"ageIdentitySecretRef,omitempty"`
+
+	// Impersonate applies the restored resources as another identity, so
+	// the restore is bound by its RBAC and attributed to it in the audit
+	// log.
+	// +optional
+	Impersonate *Impersonation `json:"impersonate,omitempty"`
+
+	// ClusterRef restores into a remote cluster reached through a
+	// kubeconfig stored in a Secret, such as a standby cluster, instead of
+	// the cluster the operator runs in. Inline in a ClusterBackup it takes
+	// precedence over spec.clusterRef.
+	// +optional
+	ClusterRef *ClusterReference `json:"clusterRef,omitempty"`
+}
+
+// ClusterReference selects a remote cluster through a kubeconfig Secret in
+// the same namespace. The kubeconfig must embed its credentials: exec and
+// auth provider plugins and references to local files are rejected.
+type ClusterReference struct {
+	// Name of the Secret.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Key within the Secret. Defaults to "kubeconfig".
+	// +kubebuilder:default:=kubeconfig
+	// +optional
+	Key string `json:"key,omitempty"`
+
+	// Context selects a kubeconfig context other than its current one.
+	// +optional
+	Context string `json:"context,omitempty"`
+}
+
+// Impersonation names the identity the operator acts as, either a
+// ServiceAccount in the same namespace or a user with optional groups.
+// +kubebuilder:validation:XValidation:rule="has(self.serviceAccountName) != has(self.user)",message="exactly one of serviceAccountName or user must be set"
+// +kubebuilder:validation:XValidation:rule="!has(self.groups) || has(self.user)",message="groups can only be set with user"
+type Impersonation struct {
+	// ServiceAccountName is a ServiceAccount in the same namespace.
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// User is the user name to act as, such as a break-glass identity.
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	User string `json:"user,omitempty"`
+
+	// Groups are the groups User acts with.
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+}
+
+// SecretKeyReference selects a key of a Secret in the same namespace.
+type SecretKeyReference struct {
+	// Name of the Secret.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Key within the Secret. Defaults to "identity".
+	// +kubebuilder:default:=identity
+	// +optional
+	Key string `json:"key,omitempty"`
 }
 
 // ClusterBackupStatus defines the observed state of ClusterBackup.
@@ -111,10 +970,90 @@ type ClusterBackupStatus struct {
 	// +optional
 	Message string `json:"message,omitempty"`
 
+	// StorageLocations reports the outcome of the last run for storagePath
+	// and each replica storage location.
+	// +listType=map
+	// +listMapKey=storagePath
+	// +optional
+	StorageLocations []StorageLocationStatus `json:"storageLocations,omitempty"`
+
+	// LockedArchives lists the archives retention kept in the last run
+	// because they are still locked.
+	// +optional
+	LockedArchives []string `json:"lockedArchives,omitempty"`
+
+	// Archives catalogs the newest archives of this ClusterBackup and the
+	// tier they are stored in.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Archives []ArchiveCatalogEntry `json:"archives,omitempty"`
+
+	// LastExportCommit is the commit holding the GitOps export of the last
+	// backup, when it is pushed to a repository.
+	// +optional
+	LastExportCommit string `json:"lastExportCommit,omitempty"`
+
 	// LastBackupTime is the timestamp of the last successful backup (for scheduled backups)
 	// +optional
 	LastBackupTime *metav1.Time `json:"lastBackupTime,omitempty"`
 
+	// ArchiveSize is the size of the archive stored by the last successful
+	// backup.
+	// +optional
+	ArchiveSize *resource.Quantity `json:"archiveSize,omitempty"`
+
+	// Scratch reports the staging space used by the last successful backup.
+	// +optional
+	Scratch *ScratchUsage `json:"scratch,omitempty"`
+
+	// LastScheduleTime is when the schedule last started a run; manual runs
+	// leave it alone.
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// NextScheduleTime is when the schedule starts the next run. It is
+	// unset for one-shot backups.
+	// +optional
+	NextScheduleTime *metav1.Time `json:"nextScheduleTime,omitempty"`
+
+	// LastRunID identifies the most recent backup run in the operator's
+	// logs, events and metrics and in the manifest of its archive.
+	// +optional
+	LastRunID string `json:"lastRunID,omitempty"`
+
+	// LastRunTrigger records what started the most recent backup run.
+	// +optional
+	LastRunTrigger RunTrigger `json:"lastRunTrigger,omitempty"`
+
+	// ConsecutiveFailures counts the runs that failed since the last
+	// successful one, retries included.
+	// +optional
+	ConsecutiveFailures int32 `json:"consecutiveFailures,omitempty"`
+
+	// Attempts counts the attempts of the most recent run, starting at 1 and
+	// growing with every retry of spec.retryPolicy.
+	// +optional
+	Attempts int32 `json:"attempts,omitempty"`
+
+	// NextRetryTime is when the failed run is retried next.
+	// +optional
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+
+	// LastTrigger is the value of the backup.backup.io/trigger annotation
+	// when the most recent run started; a different value starts a new run.
+	// +optional
+	LastTrigger string `json:"lastTrigger,omitempty"`
+
+	// Continuous reports the change log when spec.continuous is set.
+	// +optional
+	Continuous *ContinuousBackupStatus `json:"continuous,omitempty"`
+
+	// ResourceSchedules reports each cadence when spec.resourceSchedules is
+	// set, starting with the one of spec.schedule.
+	// +optional
+	ResourceSchedules []ResourceScheduleStatus `json:"resourceSchedules,omitempty"`
+
 	// conditions represent the current state of the ClusterBackup resource.
 	// +listType=map
 	// +listMapKey=type
@@ -134,18 +1073,406 @@ type ClusterBackupStatus struct {
 	// +optional
 	LastRestoreResourceCount int `json:"lastRestoreResourceCount,omitempty"`
 
+	// LastRestoreSummary breaks down the outcome of the last restore per
+	// result and per resource type.
+	// +optional
+	LastRestoreSummary *RestoreSummary `json:"lastRestoreSummary,omitempty"`
+
 	// LastRestoreObservedGeneration captures which generation triggered the last
 	// successful restore.
 	// +optional
 	LastRestoreObservedGeneration int64 `json:"lastRestoreObservedGeneration,omitempty"`
 
+	// LastRestoreRunID identifies the most recent restore attempt in the
+	// operator's logs, events and metrics.
+	// +optional
+	LastRestoreRunID string `json:"lastRestoreRunID,omitempty"`
+
 	// RestoreMessage holds details about the most recent restore attempt.
 	// +optional
 	RestoreMessage string `json:"restoreMessage,omitempty"`
+
+	// Estimate is the result of the last run in estimate mode.
+	// +optional
+	Estimate *BackupEstimate `json:"estimate,omitempty"`
+
+	// SkippedResources lists the resources the last backup left out because
+	// the operator may not list them, e.g. "secrets in payments".
+	// +optional
+	SkippedResources []string `json:"skippedResources,omitempty"`
+
+	// SlowestResources are the resource types the last backup spent the
+	// most time on, slowest first.
+	// +optional
+	SlowestResources []ResourceTiming `json:"slowestResources,omitempty"`
+
+	// History lists the most recent runs, newest first, up to
+	// spec.historyLimit.
+	// +optional
+	History []BackupRunRecord `json:"history,omitempty"`
+}
+
+// BackupRunRecord summarizes one finished backup run.
+type BackupRunRecord struct {
+	// RunID identifies the run in the operator's logs, events and metrics.
+	RunID string `json:"runID"`
+
+	// Trigger records what started the run.
+	// +optional
+	Trigger RunTrigger `json:"trigger,omitempty"`
+
+	// Attempt is the attempt of the run, 1 unless it was a retry.
+	// +optional
+	Attempt int32 `json:"attempt,omitempty"`
+
+	// StartTime is when the run started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is when the run finished.
+	CompletionTime metav1.Time `json:"completionTime"`
+
+	// Outcome is Succeeded or Failed.
+	// +kubebuilder:validation:Enum=Succeeded;Failed
+	Outcome string `json:"outcome"`
+
+	// Archive is the name of the archive the run stored.
+	// +optional
+	Archive string `json:"archive,omitempty"`
+
+	// Size is the size of that archive.
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// ResourceCount is the number of resources backed up.
+	// +optional
+	ResourceCount int `json:"resourceCount,omitempty"`
+
+	// Error summarizes why a failed run failed.
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// Partial is set when the run was interrupted by an operator shutdown
+	// and Archive holds only the resources collected until then.
+	// +optional
+	Partial bool `json:"partial,omitempty"`
+}
+
+// ResourceTiming is the time a backup spent on one resource type, summed over
+// the namespaces it was listed in.
+type ResourceTiming struct {
+	// Resource is the group-qualified resource name, e.g. "deployments.apps".
+	Resource string `json:"resource"`
+
+	// ItemCount is the number of resources of this type backed up.
+	ItemCount int `json:"itemCount"`
+
+	// Duration is the time spent listing and archiving them.
+	Duration metav1.Duration `json:"duration"`
+
+	// ListDuration is the part of Duration spent waiting for List calls.
+	ListDuration metav1.Duration `json:"listDuration"`
+}
+
+// BackupEstimate is the expected size of a backup.
+type BackupEstimate struct {
+	// ObservedGeneration is the generation the estimate was made for.
+	ObservedGeneration int64 `json:"observedGeneration"`
+
+	// Time is when the estimate was made.
+	Time metav1.Time `json:"time"`
+
+	// ItemCount is the number of resources the backup would hold.
+	ItemCount int `json:"itemCount"`
+
+	// Bytes is the size of the resources as stored in the archive, before
+	// compression.
+	Bytes int64 `json:"bytes"`
+
+	// CompressedBytes is the expected size of the archive with the
+	// configured compression, before encryption.
+	CompressedBytes int64 `json:"compressedBytes"`
+
+	// ResourceTypes breaks the estimate down per resource type, largest
+	// first.
+	// +listType=map
+	// +listMapKey=resource
+	// +optional
+	ResourceTypes []ResourceEstimate `json:"resourceTypes,omitempty"`
+
+	// SkippedResources lists the resources a backup would leave out because
+	// the operator may not list them.
+	// +optional
+	SkippedResources []string `json:"skippedResources,omitempty"`
+}
+
+// ResourceEstimate is the share of one resource type in a BackupEstimate.
+type ResourceEstimate struct {
+	// Resource is the group-qualified resource name, e.g. "deployments.apps".
+	Resource string `json:"resource"`
+
+	// ItemCount is the number of resources of this type.
+	ItemCount int `json:"itemCount"`
+
+	// Bytes is their size before compression.
+	Bytes int64 `json:"bytes"`
+}
+
+// ArchiveTiering configures the transition of old archives to cold storage.
+type ArchiveTiering struct {
+	// ColdStoragePath is the storage location archives are moved to.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:XValidation:rule="self.startsWith('/') || self.startsWith('host://')",message="must be an absolute path or a host:// URI"
+	ColdStoragePath string `json:"coldStoragePath"`
+
+	// TransitionAfterDays is the age, based on modification time, at which
+	// an archive is moved to coldStoragePath.
+	// +kubebuilder:validation:Minimum=1
+	TransitionAfterDays int `json:"transitionAfterDays"`
+}
+
+// ArchiveRotation is a count-based rotation plan. The first run of a
+// calendar month (UTC) is tagged monthly, the first run of any other ISO week
+// weekly and every other run daily; archives written without a tag count as
+// daily. A tag without a count is not pruned.
+// +kubebuilder:validation:XValidation:rule="has(self.daily) || has(self.weekly) || has(self.monthly)",message="set at least one of daily, weekly or monthly"
+type ArchiveRotation struct {
+	// Daily is how many daily archives to keep.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Daily *int `json:"daily,omitempty"`
+
+	// Weekly is how many weekly archives to keep.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Weekly *int `json:"weekly,omitempty"`
+
+	// Monthly is how many monthly archives to keep.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Monthly *int `json:"monthly,omitempty"`
+}
+
+// ArchiveImmutability configures the lock placed on new archives.
+// +kubebuilder:validation:XValidation:rule="has(self.retentionDays) || (has(self.legalHold) && self.legalHold)",message="set retentionDays or legalHold"
+type ArchiveImmutability struct {
+	// RetentionDays is how long an archive is locked after it is written.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	RetentionDays *int `json:"retentionDays,omitempty"`
+
+	// LegalHold locks archives until their lock file is removed by hand,
+	// regardless of retentionDays.
+	// +optional
+	LegalHold bool `json:"legalHold,omitempty"`
+}
+
+// ArchiveTier names the storage tier holding an archive.
+// +kubebuilder:validation:Enum=Hot;Cold
+type ArchiveTier string
+
+const (
+	// ArchiveTierHot is the storage location of the ClusterBackup.
+	ArchiveTierHot ArchiveTier = "Hot"
+	// ArchiveTierCold is the cold storage location of the tiering policy.
+	ArchiveTierCold ArchiveTier = "Cold"
+)
+
+// ArchiveCatalogEntry records where an archive is stored.
+type ArchiveCatalogEntry struct {
+	// Name is the archive file name.
+	Name string `json:"name"`
+
+	// Tier is the storage tier holding the archive.
+	Tier ArchiveTier `json:"tier"`
+
+	// StoragePath is the storage location holding the archive.
+	StoragePath string `json:"storagePath"`
+
+	// Pinned is set when the archive is exempt from retention.
+	// +optional
+	Pinned bool `json:"pinned,omitempty"`
+
+	// RotationTag is the rotation tag of the archive: daily, weekly or
+	// monthly.
+	// +optional
+	RotationTag string `json:"rotationTag,omitempty"`
+
+	// EncryptionKeys lists the keys the archive is encrypted with, as
+	// "<provider>:<key ID>".
+	// +optional
+	EncryptionKeys []string `json:"encryptionKeys,omitempty"`
+
+	// Conditions holds the Verified condition, set when the archive was last
+	// read back from storage and checked against its manifest.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// StorageLocationStatus is the state of one storage location of a ClusterBackup.
+type StorageLocationStatus struct {
+	// StoragePath is the storage location.
+	StoragePath string `json:"storagePath"`
+
+	// Phase is Completed when the last archive was stored here, Failed
+	// otherwise.
+	// +kubebuilder:validation:Enum=Completed;Failed
+	Phase string `json:"phase"`
+
+	// BackupLocation is where the last archive was stored.
+	// +optional
+	BackupLocation string `json:"backupLocation,omitempty"`
+
+	// Message explains a failure.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// LastSuccessTime is when an archive was last stored here.
+	// +optional
+	LastSuccessTime *metav1.Time `json:"lastSuccessTime,omitempty"`
+
+	// ArchiveCount is the number of archives held here after retention,
+	// including those of other backups sharing the location.
+	// +optional
+	ArchiveCount int `json:"archiveCount,omitempty"`
+
+	// TotalBytes is the combined size of those archives.
+	// +optional
+	TotalBytes int64 `json:"totalBytes,omitempty"`
+}
+
+// RestoreCounts tallies the outcome of the items in a restore.
+type RestoreCounts struct {
+	// Created is the number of resources that did not exist and were created.
+	// +optional
+	Created int `json:"created,omitempty"`
+
+	// Updated is the number of existing resources that were overwritten.
+	// +optional
+	Updated int `json:"updated,omitempty"`
+
+	// Skipped is the number of archived resources that were intentionally not applied.
+	// +optional
+	Skipped int `json:"skipped,omitempty"`
+
+	// Failed is the number of resources that could not be applied.
+	// +optional
+	Failed int `json:"failed,omitempty"`
+}
+
+// ResourceRestoreCounts holds the restore counts for a single resource type.
+type ResourceRestoreCounts struct {
+	// Resource is the group-qualified resource name, e.g. "deployments.apps".
+	Resource string `json:"resource"`
+
+	RestoreCounts `json:",inline"`
+}
+
+// RestoreFailure describes an archived resource that failed to restore.
+type RestoreFailure struct {
+	// Resource is the group-qualified resource name, e.g. "deployments.apps".
+	Resource string `json:"resource"`
+
+	// Namespace of the resource, empty for cluster-scoped resources.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the resource.
+	Name string `json:"name"`
+
+	// Reason is the error returned while applying the resource.
+	Reason string `json:"reason"`
+}
+
+// APIVersionFinding reports an archived resource stored in a removed or
+// deprecated API version.
+type APIVersionFinding struct {
+	// APIVersion the resource was archived in.
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the resource.
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Namespace of the resource, empty for cluster-scoped resources.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the resource.
+	Name string `json:"name"`
+
+	// Removed is true when the target cluster does not serve APIVersion,
+	// and false when it is deprecated but still served.
+	// +optional
+	Removed bool `json:"removed,omitempty"`
+
+	// TargetAPIVersion is the version that replaces APIVersion.
+	// +optional
+	TargetAPIVersion string `json:"targetAPIVersion,omitempty"`
+
+	// Decision is what the restore did with the resource: Convert, Skip,
+	// Fail or Keep.
+	Decision string `json:"decision"`
+}
+
+// RestoreSummary breaks down the outcome of a restore.
+type RestoreSummary struct {
+	RestoreCounts `json:",inline"`
+
+	// ResourceTypes breaks the counts down per resource type.
+	// +listType=map
+	// +listMapKey=resource
+	// +optional
+	ResourceTypes []ResourceRestoreCounts `json:"resourceTypes,omitempty"`
+
+	// FailedItems lists the resources that failed to restore. The list is
+	// capped, so it may be shorter than Failed.
+	// +optional
+	FailedItems []RestoreFailure `json:"failedItems,omitempty"`
+
+	// QuotaWarnings lists the namespace quotas the archive was expected to
+	// exceed when the restore went ahead under the Warn quota policy.
+	// +optional
+	QuotaWarnings []string `json:"quotaWarnings,omitempty"`
+
+	// HelmRollbackCommands lists the `helm rollback` commands returning each
+	// archived Helm release to its archived revision, when the Rollback Helm
+	// release policy is used.
+	// +optional
+	HelmRollbackCommands []string `json:"helmRollbackCommands,omitempty"`
+
+	// ClusterWarning describes how the cluster the archive was taken from
+	// differs from the one it was restored into, when the restore went ahead
+	// under the Warn cluster mismatch policy.
+	// +optional
+	ClusterWarning string `json:"clusterWarning,omitempty"`
+
+	// APIFindings lists the archived resources stored in removed or
+	// deprecated API versions, up to 50.
+	// +optional
+	APIFindings []APIVersionFinding `json:"apiFindings,omitempty"`
+
+	// BaseArchive is the archive a point-in-time restore started from.
+	// +optional
+	BaseArchive string `json:"baseArchive,omitempty"`
+
+	// ReplayedChanges counts the change log records a point-in-time restore
+	// replayed onto its base archive.
+	// +optional
+	ReplayedChanges int `json:"replayedChanges,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.spec.schedule`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Resources",type=integer,JSONPath=`.status.resourceCount`
+// +kubebuilder:printcolumn:name="Size",type=string,JSONPath=`.status.archiveSize`
+// +kubebuilder:printcolumn:name="Last Schedule",type=date,JSONPath=`.status.lastScheduleTime`
+// +kubebuilder:printcolumn:name="Next Schedule",type=string,JSONPath=`.status.nextScheduleTime`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // ClusterBackup is the Schema for the clusterbackups API
 type ClusterBackup struct {
@@ -0,0 +1,157 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterNameLabel names the cluster a ClusterBackup backs up. The
+// ClusterBackupSet controller sets it, and storage path templates read it as
+// {{.ClusterName}}.
+const ClusterNameLabel = "backup.backup.io/cluster"
+
+// ClusterBackupSetSpec defines the clusters of a fleet and the backup
+// settings they share.
+// +kubebuilder:validation:XValidation:rule="has(self.clusters) || has(self.clusterSelector)",message="set clusters, clusterSelector or both"
+// +kubebuilder:validation:XValidation:rule="!has(self.template.clusterRef)",message="template.clusterRef is set per cluster"
+// +kubebuilder:validation:XValidation:rule="!has(self.template.impersonate)",message="template.impersonate is not supported"
+// +kubebuilder:validation:XValidation:rule="!has(self.template.restore)",message="template.restore is not supported; restore from the per-cluster ClusterBackup"
+type ClusterBackupSetSpec struct {
+	// Clusters lists remote clusters by kubeconfig Secret.
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=256
+	// +optional
+	Clusters []FleetCluster `json:"clusters,omitempty"`
+
+	// ClusterSelector adds the Cluster API Clusters (cluster.x-k8s.io) in
+	// the same namespace whose labels match. Each is reached through the
+	// "<name>-kubeconfig" Secret Cluster API maintains for it.
+	// +optional
+	ClusterSelector *metav1.LabelSelector `json:"clusterSelector,omitempty"`
+
+	// Template is the ClusterBackup spec applied to every cluster. Storage
+	// paths that do not use {{.ClusterName}} get a "/<cluster>" suffix so
+	// archives of different clusters do not mix; without storagePath the
+	// defaultStoragePath of the BackupOperatorConfig is used as the base.
+	// +required
+	Template ClusterBackupSpec `json:"template"`
+}
+
+// FleetCluster is one cluster of a ClusterBackupSet.
+type FleetCluster struct {
+	// Name identifies the cluster within the set and names its
+	// ClusterBackup "<set>-<name>".
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// ClusterRef is the kubeconfig Secret of the cluster.
+	ClusterRef ClusterReference `json:"clusterRef"`
+}
+
+// ClusterBackupSetMember is the state of the backup of one cluster.
+type ClusterBackupSetMember struct {
+	// Name is the cluster name.
+	Name string `json:"name"`
+
+	// BackupName is the ClusterBackup created for the cluster.
+	BackupName string `json:"backupName"`
+
+	// Phase mirrors the phase of the ClusterBackup.
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// LastBackupTime mirrors the last successful backup of the ClusterBackup.
+	// +optional
+	LastBackupTime *metav1.Time `json:"lastBackupTime,omitempty"`
+
+	// Message mirrors the message of the ClusterBackup.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ClusterBackupSetStatus defines the observed state of ClusterBackupSet.
+type ClusterBackupSetStatus struct {
+	// ObservedGeneration is the generation the current status refers to.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Clusters holds the backup state of every cluster in the set.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Clusters []ClusterBackupSetMember `json:"clusters,omitempty"`
+
+	// Total counts the clusters in the set.
+	// +optional
+	Total int `json:"total,omitempty"`
+
+	// Succeeded counts the clusters whose last backup completed.
+	// +optional
+	Succeeded int `json:"succeeded,omitempty"`
+
+	// Failed counts the clusters whose last backup failed.
+	// +optional
+	Failed int `json:"failed,omitempty"`
+
+	// conditions represent the current state of the ClusterBackupSet resource.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Clusters",type=integer,JSONPath=`.status.total`
+// +kubebuilder:printcolumn:name="Succeeded",type=integer,JSONPath=`.status.succeeded`
+// +kubebuilder:printcolumn:name="Failed",type=integer,JSONPath=`.status.failed`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ClusterBackupSet is the Schema for the clusterbackupsets API. It creates a
+// ClusterBackup per cluster of a fleet from a shared template and
+// aggregates their status.
+type ClusterBackupSet struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the desired state of ClusterBackupSet
+	// +required
+	Spec ClusterBackupSetSpec `json:"spec"`
+
+	// status defines the observed state of ClusterBackupSet
+	// +optional
+	Status ClusterBackupSetStatus `json:"status,omitempty,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterBackupSetList contains a list of ClusterBackupSet
+type ClusterBackupSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterBackupSet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterBackupSet{}, &ClusterBackupSetList{})
+}
@@ -0,0 +1,243 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RestorePhase describes where a ClusterRestore is in its lifecycle.
+// +kubebuilder:validation:Enum=Pending;Validating;Planned;InProgress;PartiallyFailed;Completed;Failed
+type RestorePhase string
+
+const (
+	// RestorePhasePending means the restore has been accepted but not started.
+	RestorePhasePending RestorePhase = "Pending"
+	// RestorePhaseValidating means the archive is being located and read.
+	RestorePhaseValidating RestorePhase = "Validating"
+	// RestorePhasePlanned means the restore was previewed in status.plan and
+	// nothing was applied.
+	RestorePhasePlanned RestorePhase = "Planned"
+	// RestorePhaseInProgress means resources are being applied.
+	RestorePhaseInProgress RestorePhase = "InProgress"
+	// RestorePhasePartiallyFailed means the restore finished but some resources failed.
+	RestorePhasePartiallyFailed RestorePhase = "PartiallyFailed"
+	// RestorePhaseCompleted means every archived resource was restored.
+	RestorePhaseCompleted RestorePhase = "Completed"
+	// RestorePhaseFailed means the restore could not be carried out.
+	RestorePhaseFailed RestorePhase = "Failed"
+)
+
+// RestoreProgress counts how far a running restore has got.
+type RestoreProgress struct {
+	// TotalItems is the number of resources read from the archive.
+	// +optional
+	TotalItems int `json:"totalItems,omitempty"`
+
+	// ItemsProcessed is the number of resources applied, skipped or failed so far.
+	// +optional
+	ItemsProcessed int `json:"itemsProcessed,omitempty"`
+}
+
+// RestorePlanItem is what a restore would do with one archived resource.
+type RestorePlanItem struct {
+	// Resource is the group-qualified resource name, e.g. "deployments.apps".
+	Resource string `json:"resource"`
+
+	// Namespace of the resource, empty for cluster-scoped resources.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the resource.
+	Name string `json:"name"`
+
+	// Action is Create, Update, Skip or Fail.
+	Action string `json:"action"`
+
+	// Conflict is set for updates to resources another controller manages,
+	// which may revert the change.
+	// +optional
+	Conflict bool `json:"conflict,omitempty"`
+
+	// Changes lists the fields set by the archive whose live value an
+	// update changes.
+	// +optional
+	Changes []string `json:"changes,omitempty"`
+
+	// Reason explains skipped, failed and conflicting resources.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// ClusterRestoreSpec defines the desired state of ClusterRestore: the restore
+// options shared with ClusterBackup and where to find the archive.
+// +kubebuilder:validation:XValidation:rule="!has(self.archiveURL) || (!has(self.backupName) && !has(self.storagePath))",message="archiveURL cannot be combined with backupName or storagePath"
+type ClusterRestoreSpec struct {
+	// BackupName references a ClusterBackup in the same namespace whose
+	// storagePath holds the archive.
+	// +optional
+	BackupName string `json:"backupName,omitempty"`
+
+	// StoragePath points directly at the storage location holding the archive
+	// and takes precedence over BackupName.
+	// +kubebuilder:validation:XValidation:rule="self.startsWith('/') || self.startsWith('host://')",message="must be an absolute path or a host:// URI"
+	// +optional
+	StoragePath string `json:"storagePath,omitempty"`
+
+	// Plan previews the restore instead of running it: the archive is read
+	// and checked, and what would happen to each resource is recorded in
+	// status.plan without applying anything. Unset it to run the restore.
+	// +optional
+	Plan bool `json:"plan,omitempty"`
+
+	RestoreOptions `json:",inline"`
+}
+
+// RestorePlan previews what a restore would do.
+type RestorePlan struct {
+	// Create counts the resources missing from the cluster.
+	// +optional
+	Create int `json:"create,omitempty"`
+
+	// Update counts the existing resources that differ from the archive.
+	// +optional
+	Update int `json:"update,omitempty"`
+
+	// Unchanged counts the existing resources that match the archive.
+	// +optional
+	Unchanged int `json:"unchanged,omitempty"`
+
+	// Skip counts the resources left out or kept as they are.
+	// +optional
+	Skip int `json:"skip,omitempty"`
+
+	// Fail counts the resources that cannot be restored.
+	// +optional
+	Fail int `json:"fail,omitempty"`
+
+	// Conflicts counts the updates to resources another controller manages.
+	// +optional
+	Conflicts int `json:"conflicts,omitempty"`
+
+	// Items lists every resource except unchanged ones, in the order they
+	// would be applied, up to 100.
+	// +optional
+	Items []RestorePlanItem `json:"items,omitempty"`
+
+	// QuotaWarnings lists the namespace quotas the restore is expected to
+	// exceed.
+	// +optional
+	QuotaWarnings []string `json:"quotaWarnings,omitempty"`
+
+	// ClusterWarning describes how the cluster the archive was taken from
+	// differs from the target.
+	// +optional
+	ClusterWarning string `json:"clusterWarning,omitempty"`
+
+	// APIFindings lists the resources archived in removed or deprecated API
+	// versions.
+	// +optional
+	APIFindings []APIVersionFinding `json:"apiFindings,omitempty"`
+}
+
+// ClusterRestoreStatus defines the observed state of ClusterRestore.
+type ClusterRestoreStatus struct {
+	// Phase represents the current phase of the restore
+	// +optional
+	Phase RestorePhase `json:"phase,omitempty"`
+
+	// ObservedGeneration is the generation the current status refers to.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// StartTime is the time when the restore started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time when the restore finished, successfully or not
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// StoragePath is the storage location the archive was read from
+	// +optional
+	StoragePath string `json:"storagePath,omitempty"`
+
+	// RunID identifies the restore run in the operator's logs, events and
+	// metrics.
+	// +optional
+	RunID string `json:"runID,omitempty"`
+
+	// Progress reports how many archived resources have been processed.
+	// +optional
+	Progress *RestoreProgress `json:"progress,omitempty"`
+
+	// Plan previews the restore when spec.plan is set.
+	// +optional
+	Plan *RestorePlan `json:"plan,omitempty"`
+
+	// Summary breaks down the outcome of the restore once it has finished.
+	// +optional
+	Summary *RestoreSummary `json:"summary,omitempty"`
+
+	// Message provides additional information about the restore status
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// conditions represent the current state of the ClusterRestore resource.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Archive",type=string,JSONPath=`.spec.archiveName`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Processed",type=integer,JSONPath=`.status.progress.itemsProcessed`
+// +kubebuilder:printcolumn:name="Total",type=integer,JSONPath=`.status.progress.totalItems`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ClusterRestore is the Schema for the clusterrestores API
+type ClusterRestore struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is a standard object metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty,omitzero"`
+
+	// spec defines the desired state of ClusterRestore
+	// +required
+	Spec ClusterRestoreSpec `json:"spec"`
+
+	// status defines the observed state of ClusterRestore
+	// +optional
+	Status ClusterRestoreStatus `json:"status,omitempty,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterRestoreList contains a list of ClusterRestore
+type ClusterRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterRestore{}, &ClusterRestoreList{})
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+// printArchiveContents lists the objects held by the archive at path, or at
+// an archive URL, as a tree of namespaces, resource types and names.
+func printArchiveContents(ctx context.Context, path string) error {
+	bm := &backup.BackupManager{}
+	storagePath, archiveName := filepath.Dir(path), filepath.Base(path)
+	if backup.IsArchiveURL(path) {
+		storagePath, archiveName = "", path
+	}
+	contents, err := bm.ListArchiveContents(ctx, storagePath, archiveName, backup.ContentsOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list archive contents: %w", err)
+	}
+	writeArchiveContents(os.Stdout, contents)
+	return nil
+}
+
+// extractArchive unpacks the archive at path, or at an archive URL, into dir
+// as a kustomize tree and reports what was left out.
+func extractArchive(ctx context.Context, path, dir string, includeSecrets bool) error {
+	bm := &backup.BackupManager{}
+	storagePath, archiveName := filepath.Dir(path), filepath.Base(path)
+	if backup.IsArchiveURL(path) {
+		storagePath, archiveName = "", path
+	}
+	result, err := bm.ExtractArchive(ctx, storagePath, archiveName, dir, backup.ExtractOptions{IncludeSecrets: includeSecrets})
+	if err != nil {
+		return fmt.Errorf("failed to extract archive: %w", err)
+	}
+	for _, failed := range result.FailedEntries {
+		fmt.Fprintf(os.Stderr, "skipped: %s\n", failed)
+	}
+	fmt.Printf("Extracted %d objects to %s (%d Secrets or controller-created objects skipped)\n", result.Objects, dir, result.Skipped)
+	fmt.Printf("Apply them with: kubectl apply -k %s\n", dir)
+	if len(result.FailedEntries) > 0 {
+		return fmt.Errorf("%d entries failed verification and were not extracted", len(result.FailedEntries))
+	}
+	return nil
+}
+
+// printRunbook writes the recovery runbook of the archive at path, or at an
+// archive URL, as Markdown or JSON.
+func printRunbook(ctx context.Context, path, format string) error {
+	if format != "markdown" && format != "json" {
+		return fmt.Errorf("unsupported runbook format %q, want markdown or json", format)
+	}
+	bm := &backup.BackupManager{}
+	storagePath, archiveName := filepath.Dir(path), filepath.Base(path)
+	if backup.IsArchiveURL(path) {
+		storagePath, archiveName = "", path
+	}
+	runbook, err := bm.GenerateRunbook(ctx, storagePath, archiveName, backup.RunbookOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to generate runbook: %w", err)
+	}
+	if format == "markdown" {
+		_, err = io.WriteString(os.Stdout, runbook.Markdown())
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(runbook)
+}
+
+// writeArchiveContents renders contents as an indented tree.
+func writeArchiveContents(w io.Writer, contents *backup.ArchiveContents) {
+	fmt.Fprintf(w, "%s (format %d, %d objects, %d bytes)\n", contents.Archive, contents.FormatVersion, contents.Objects, contents.Bytes)
+	writeTypes := func(indent string, types []backup.ArchivedResourceType) {
+		for _, resourceType := range types {
+			gvr := resourceType.Resource + "." + resourceType.Version
+			if resourceType.Group != "" {
+				gvr += "." + resourceType.Group
+			}
+			fmt.Fprintf(w, "%s%s (%d objects, %d bytes)\n", indent, gvr, len(resourceType.Objects), resourceType.Bytes)
+			for _, object := range resourceType.Objects {
+				fmt.Fprintf(w, "%s  %s (%d bytes)\n", indent, object.Name, object.Bytes)
+			}
+		}
+	}
+	if len(contents.Cluster) > 0 {
+		fmt.Fprintln(w, "  cluster-scoped")
+		writeTypes("    ", contents.Cluster)
+	}
+	for _, namespace := range contents.Namespaces {
+		fmt.Fprintf(w, "  namespace %s (%d objects, %d bytes)\n", namespace.Name, namespace.Objects, namespace.Bytes)
+		writeTypes("    ", namespace.Resources)
+	}
+}
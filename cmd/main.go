@@ -19,16 +19,24 @@ package main
 import (
 	"crypto/tls"
 	"flag"
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -36,8 +44,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
-	"github.com/zachperkins/backup-operator/internal/backup"
 	"github.com/zachperkins/backup-operator/internal/controller"
+	webhookv1alpha1 "github.com/zachperkins/backup-operator/internal/webhook/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -62,6 +71,31 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var staleBackupThreshold float64
+	var degradedFailureThreshold int
+	var storageProbeInterval time.Duration
+	var driftDetectionInterval time.Duration
+	var archiveVerificationInterval time.Duration
+	var verifyArchive string
+	var listArchive string
+	var extractArchiveFrom string
+	var extractDir string
+	var extractSecrets bool
+	var runbookFor string
+	var runbookFormat string
+	var createMonitoring bool
+	var monitoringNamespace, monitoringNamePrefix string
+	var printRBACFor string
+	var hostStorage backup.HostStorage
+	var memoryBudget resource.Quantity
+	var tempDir string
+	var scratchDirs [][2]string
+	var minScratchSpace resource.Quantity
+	var tempDirCleanupInterval time.Duration
+	var restoreGracePeriod time.Duration
+	var apiAddr, apiTokenFile, apiAudience string
+	var apiCertPath, apiCertName, apiCertKey string
+	controllerOptions := controller.Options{ConcurrentReconciles: map[string]int{}}
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -80,6 +114,125 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.Float64Var(&staleBackupThreshold, "stale-backup-threshold", 2,
+		"Number of schedule periods without a successful run after which a scheduled ClusterBackup is marked Stale.")
+	flag.IntVar(&degradedFailureThreshold, "degraded-failure-threshold", 3,
+		"Number of consecutive failed runs after which a ClusterBackup is marked Degraded.")
+	flag.DurationVar(&storageProbeInterval, "storage-probe-interval", 5*time.Minute,
+		"How often every ClusterBackup storage location is probed for reachability. Set to 0 to disable probing.")
+	flag.DurationVar(&driftDetectionInterval, "drift-detection-interval", 0,
+		"How often the latest archive of every ClusterBackup is compared with live resources to detect drift. "+
+			"Set to 0 to disable drift detection.")
+	flag.DurationVar(&archiveVerificationInterval, "archive-verification-interval", 0,
+		"How often every cataloged archive of every ClusterBackup is read back from storage and checked against its "+
+			"manifest. Set to 0 to disable verification.")
+	flag.BoolVar(&createMonitoring, "create-monitoring-resources", false,
+		"If set, create a ServiceMonitor and PrometheusRule for the operator when the monitoring.coreos.com API is available.")
+	flag.StringVar(&monitoringNamespace, "monitoring-namespace", os.Getenv("POD_NAMESPACE"),
+		"The namespace to create the ServiceMonitor and PrometheusRule in. Defaults to $POD_NAMESPACE.")
+	flag.StringVar(&monitoringNamePrefix, "monitoring-name-prefix", "backup-operator-",
+		"The prefix for the names of the created ServiceMonitor and PrometheusRule.")
+	flag.StringVar(&printRBACFor, "print-rbac-for", "",
+		"Print the least-privilege ClusterRole for the ClusterBackup manifest at this path (\"-\" for stdin) and exit.")
+	flag.StringVar(&verifyArchive, "verify-archive", "",
+		"Read the archive at this path, check every entry against its manifest, print the result and exit. "+
+			"Exits non-zero if the archive fails verification.")
+	flag.StringVar(&listArchive, "list-archive", "",
+		"Print the namespaces, resource types and objects held by the archive at this path or URL and exit.")
+	flag.StringVar(&extractArchiveFrom, "extract-archive", "",
+		"Unpack the archive at this path or URL into --extract-dir as a tree of YAML files with kustomizations, "+
+			"ready for \"kubectl apply -k\", and exit.")
+	flag.StringVar(&extractDir, "extract-dir", "restored",
+		"The empty or missing directory --extract-archive unpacks into.")
+	flag.BoolVar(&extractSecrets, "extract-include-secrets", false,
+		"Also extract Secrets with --extract-archive. They are written unencrypted.")
+	flag.StringVar(&runbookFor, "runbook-for", "",
+		"Print a recovery runbook for the archive at this path or URL, listing the restore steps in order, the "+
+			"CRDs they need and the manual follow-ups, and exit.")
+	flag.StringVar(&runbookFormat, "runbook-format", "markdown", "The format of --runbook-for: markdown or json.")
+	flag.StringVar(&hostStorage.Root, "host-storage-root", "/host",
+		"The container directory node directories for host:// storage locations are mounted below.")
+	flag.Func("host-storage-path", "A node directory mounted below --host-storage-root that host:// storage locations "+
+		"may use. May be repeated.", func(path string) error {
+		hostStorage.Paths = append(hostStorage.Paths, path)
+		return nil
+	})
+	flag.StringVar(&hostStorage.LegacyTmp, "legacy-host-tmp", "",
+		"The container directory the node's /tmp is mounted at. When set, host:// storage locations outside "+
+			"--host-storage-path resolve below it as they did before host storage paths existed. Deprecated.")
+	flag.Func("memory-budget", "Roughly the memory the objects listed by running backups may take, e.g. 64Mi. "+
+		"List calls wait for earlier pages to be archived once it is reached. Leave unset for no bound.",
+		func(value string) error {
+			quantity, err := resource.ParseQuantity(value)
+			if err != nil {
+				return err
+			}
+			memoryBudget = quantity
+			return nil
+		})
+	flag.StringVar(&tempDir, "temp-dir", "",
+		"The directory archives are staged in before they are stored. Defaults to $TMPDIR or /tmp. "+
+			"It must not be shared with other operator instances.")
+	flag.Func("scratch-dir", "A named directory, as name=path, that ClusterBackups may stage archives in instead of "+
+		"--temp-dir through spec.scratchDir, e.g. a memory emptyDir or a PersistentVolumeClaim. May be repeated.",
+		func(value string) error {
+			name, path, ok := strings.Cut(value, "=")
+			if !ok || name == "" || path == "" {
+				return fmt.Errorf("expected name=path, got %q", value)
+			}
+			scratchDirs = append(scratchDirs, [2]string{name, path})
+			return nil
+		})
+	flag.Func("min-scratch-space", "The free space --temp-dir must have before a backup is staged, e.g. 1Gi. "+
+		"Backups also require half again the size of the largest archive in their storage location.",
+		func(value string) error {
+			quantity, err := resource.ParseQuantity(value)
+			if err != nil {
+				return err
+			}
+			minScratchSpace = quantity
+			return nil
+		})
+	flag.DurationVar(&tempDirCleanupInterval, "temp-dir-cleanup-interval", time.Hour,
+		"How often staging directories left in --temp-dir by crashed runs are removed, in addition to on startup. "+
+			"Set to 0 to only clean up on startup.")
+	flag.DurationVar(&restoreGracePeriod, "restore-grace-period", backup.DefaultRestoreGracePeriod,
+		"How long an archive stays protected from retention, tiering and deletion after the last restore or "+
+			"restore plan reading it finished.")
+	flag.StringVar(&apiAddr, "api-bind-address", "0",
+		"The address the management API binds to. Use the default \"0\" to disable the API.")
+	flag.StringVar(&apiTokenFile, "api-token-file", "",
+		"A file holding a static bearer token that may use every operation of the management API. "+
+			"Without it, callers authenticate with Kubernetes tokens and are authorized with SubjectAccessReviews.")
+	flag.StringVar(&apiAudience, "api-token-audience", controller.DefaultAPIAudience,
+		"The audience Kubernetes tokens sent to the management API must be issued for.")
+	flag.StringVar(&apiCertPath, "api-cert-path", "",
+		"The directory that contains the management API certificate. The API is served without TLS when unset.")
+	flag.StringVar(&apiCertName, "api-cert-name", "tls.crt", "The name of the management API certificate file.")
+	flag.StringVar(&apiCertKey, "api-cert-key", "tls.key", "The name of the management API key file.")
+	flag.IntVar(&controllerOptions.MaxConcurrentReconciles, "max-concurrent-reconciles", 1,
+		"The number of objects every controller reconciles in parallel.")
+	flag.Func("concurrent-reconciles", "Override --max-concurrent-reconciles for one controller, e.g. clusterbackup=4. "+
+		"May be repeated.", func(value string) error {
+		name, workers, ok := strings.Cut(value, "=")
+		if !ok {
+			return fmt.Errorf("expected <controller>=<workers>, got %q", value)
+		}
+		n, err := strconv.Atoi(workers)
+		if err != nil {
+			return fmt.Errorf("invalid number of workers for %s: %w", name, err)
+		}
+		controllerOptions.ConcurrentReconciles[name] = n
+		return nil
+	})
+	flag.DurationVar(&controllerOptions.BaseDelay, "workqueue-base-delay", 5*time.Millisecond,
+		"The first delay before a failing object is reconciled again. It doubles with every failure.")
+	flag.DurationVar(&controllerOptions.MaxDelay, "workqueue-max-delay", 1000*time.Second,
+		"The longest delay before a failing object is reconciled again.")
+	flag.Float64Var(&controllerOptions.QPS, "workqueue-qps", 10,
+		"The rate at which each controller requeues objects overall.")
+	flag.IntVar(&controllerOptions.Burst, "workqueue-burst", 100,
+		"The burst of requeues each controller allows above --workqueue-qps.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -88,6 +241,51 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if err := controllerOptions.Validate(); err != nil {
+		setupLog.Error(err, "invalid controller options")
+		os.Exit(1)
+	}
+
+	if printRBACFor != "" {
+		if err := printMinimalClusterRole(ctrl.SetupSignalHandler(), printRBACFor); err != nil {
+			setupLog.Error(err, "unable to print RBAC")
+			os.Exit(1)
+		}
+		return
+	}
+
+	if listArchive != "" {
+		if err := printArchiveContents(ctrl.SetupSignalHandler(), listArchive); err != nil {
+			setupLog.Error(err, "unable to list archive")
+			os.Exit(1)
+		}
+		return
+	}
+
+	if extractArchiveFrom != "" {
+		if err := extractArchive(ctrl.SetupSignalHandler(), extractArchiveFrom, extractDir, extractSecrets); err != nil {
+			setupLog.Error(err, "unable to extract archive")
+			os.Exit(1)
+		}
+		return
+	}
+
+	if runbookFor != "" {
+		if err := printRunbook(ctrl.SetupSignalHandler(), runbookFor, runbookFormat); err != nil {
+			setupLog.Error(err, "unable to generate runbook")
+			os.Exit(1)
+		}
+		return
+	}
+
+	if verifyArchive != "" {
+		if err := verifyArchiveFile(ctrl.SetupSignalHandler(), verifyArchive); err != nil {
+			setupLog.Error(err, "archive failed verification")
+			os.Exit(1)
+		}
+		return
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -162,6 +360,11 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "75b0c8a6.backup.io",
+		// Secrets are only read for restore decryption keys; read them directly
+		// instead of caching every Secret in the cluster.
+		Client: client.Options{
+			Cache: &client.CacheOptions{DisableFor: []client.Object{&corev1.Secret{}}},
+		},
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -185,17 +388,143 @@ func main() {
 		setupLog.Error(err, "unable to create backup manager")
 		os.Exit(1)
 	}
+	backupManager.HostStorage = hostStorage
+	backupManager.SetMemoryBudget(memoryBudget.Value())
+	if err := backupManager.SetTempDir(tempDir); err != nil {
+		setupLog.Error(err, "unable to set up the temp directory")
+		os.Exit(1)
+	}
+	for _, dir := range scratchDirs {
+		if err := backupManager.AddScratchDir(dir[0], dir[1]); err != nil {
+			setupLog.Error(err, "unable to set up a scratch directory", "name", dir[0])
+			os.Exit(1)
+		}
+	}
+	backupManager.SetMinScratchSpace(minScratchSpace.Value())
+	backupManager.SetRestoreGracePeriod(restoreGracePeriod)
 
 	if err := (&controller.ClusterBackupReconciler{
+		Client:            mgr.GetClient(),
+		Scheme:            mgr.GetScheme(),
+		BackupManager:     backupManager,
+		StaleThreshold:    staleBackupThreshold,
+		DegradedThreshold: degradedFailureThreshold,
+		Recorder:          mgr.GetEventRecorderFor("clusterbackup-controller"),
+	}).SetupWithManager(mgr, controllerOptions); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterBackup")
+		os.Exit(1)
+	}
+	if err := (&controller.ClusterRestoreReconciler{
 		Client:        mgr.GetClient(),
 		Scheme:        mgr.GetScheme(),
 		BackupManager: backupManager,
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "ClusterBackup")
+		Recorder:      mgr.GetEventRecorderFor("clusterrestore-controller"),
+	}).SetupWithManager(mgr, controllerOptions); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterRestore")
+		os.Exit(1)
+	}
+	if err := (&controller.BackupOperatorConfigReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		BackupManager: backupManager,
+	}).SetupWithManager(mgr, controllerOptions); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BackupOperatorConfig")
+		os.Exit(1)
+	}
+	if err := (&controller.ArchiveReplicationReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		BackupManager: backupManager,
+	}).SetupWithManager(mgr, controllerOptions); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ArchiveReplication")
+		os.Exit(1)
+	}
+	if err := (&controller.CleanupPolicyReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		BackupManager: backupManager,
+		Recorder:      mgr.GetEventRecorderFor("cleanuppolicy-controller"),
+	}).SetupWithManager(mgr, controllerOptions); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "CleanupPolicy")
+		os.Exit(1)
+	}
+	if err := (&controller.ArchiveTransferReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		BackupManager: backupManager,
+	}).SetupWithManager(mgr, controllerOptions); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ArchiveTransfer")
+		os.Exit(1)
+	}
+	if err := (&controller.ContinuousBackupReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		BackupManager: backupManager,
+	}).SetupWithManager(mgr, controllerOptions); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ContinuousBackup")
+		os.Exit(1)
+	}
+	if err := (&controller.ArchiveDiffReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		BackupManager: backupManager,
+	}).SetupWithManager(mgr, controllerOptions); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ArchiveDiff")
+		os.Exit(1)
+	}
+	if err := (&controller.BackupPolicyReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr, controllerOptions); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BackupPolicy")
+		os.Exit(1)
+	}
+	if err := (&controller.ClusterBackupSetReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr, controllerOptions); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterBackupSet")
 		os.Exit(1)
 	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err := webhookv1alpha1.SetupClusterBackupWebhookWithManager(mgr, backupManager); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ClusterBackup")
+			os.Exit(1)
+		}
+		if err := webhookv1alpha1.SetupClusterRestoreWebhookWithManager(mgr, backupManager); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ClusterRestore")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
+	if createMonitoring {
+		if monitoringNamespace == "" {
+			setupLog.Error(nil, "--monitoring-namespace or POD_NAMESPACE is required with --create-monitoring-resources")
+			os.Exit(1)
+		}
+		discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+		if err != nil {
+			setupLog.Error(err, "unable to create discovery client")
+			os.Exit(1)
+		}
+		if err := mgr.Add(&controller.MonitoringInstaller{
+			Client:     mgr.GetClient(),
+			Discovery:  discoveryClient,
+			Namespace:  monitoringNamespace,
+			NamePrefix: monitoringNamePrefix,
+			ServiceSelector: map[string]string{
+				"control-plane":          "controller-manager",
+				"app.kubernetes.io/name": "backup-operator",
+			},
+			MetricsPort: "https",
+		}); err != nil {
+			setupLog.Error(err, "unable to set up monitoring installer")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -204,6 +533,78 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	if err := mgr.Add(&controller.TempDirCleaner{
+		BackupManager: backupManager,
+		Interval:      tempDirCleanupInterval,
+	}); err != nil {
+		setupLog.Error(err, "unable to set up temp directory cleanup")
+		os.Exit(1)
+	}
+	if err := mgr.Add(&controller.WebhookPolicyRestorer{BackupManager: backupManager}); err != nil {
+		setupLog.Error(err, "unable to set up webhook policy restorer")
+		os.Exit(1)
+	}
+	if storageProbeInterval > 0 {
+		storageProber := &controller.StorageProber{
+			Client:        mgr.GetClient(),
+			BackupManager: backupManager,
+			Interval:      storageProbeInterval,
+		}
+		if err := mgr.Add(storageProber); err != nil {
+			setupLog.Error(err, "unable to set up storage prober")
+			os.Exit(1)
+		}
+		if err := mgr.AddReadyzCheck("storage", storageProber.Check); err != nil {
+			setupLog.Error(err, "unable to set up storage ready check")
+			os.Exit(1)
+		}
+	}
+	if driftDetectionInterval > 0 {
+		if err := mgr.Add(&controller.DriftDetector{
+			Client:        mgr.GetClient(),
+			BackupManager: backupManager,
+			Interval:      driftDetectionInterval,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up drift detector")
+			os.Exit(1)
+		}
+	}
+	if archiveVerificationInterval > 0 {
+		if err := mgr.Add(&controller.ArchiveVerifier{
+			Client:        mgr.GetClient(),
+			BackupManager: backupManager,
+			Interval:      archiveVerificationInterval,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up archive verifier")
+			os.Exit(1)
+		}
+	}
+	if apiAddr != "0" {
+		var apiToken string
+		if apiTokenFile != "" {
+			data, err := os.ReadFile(apiTokenFile)
+			if err != nil {
+				setupLog.Error(err, "unable to read API token")
+				os.Exit(1)
+			}
+			apiToken = strings.TrimSpace(string(data))
+		}
+		if err := mgr.Add(&controller.APIServer{
+			Client:        mgr.GetClient(),
+			BackupManager: backupManager,
+			Recorder:      mgr.GetEventRecorderFor("backup-api"),
+			Addr:          apiAddr,
+			Token:         apiToken,
+			Audience:      apiAudience,
+			CertDir:       apiCertPath,
+			CertName:      apiCertName,
+			KeyName:       apiCertKey,
+			TLSOpts:       tlsOpts,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up management API server")
+			os.Exit(1)
+		}
+	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
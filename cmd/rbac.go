@@ -0,0 +1,80 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/internal/controller"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+// printMinimalClusterRole reads a ClusterBackup manifest from path, or stdin
+// for "-", and writes the least-privilege ClusterRole it needs to stdout.
+// Discovery and BackupPolicies are read from the cluster of the kubeconfig.
+func printMinimalClusterRole(ctx context.Context, path string) error {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read ClusterBackup manifest: %w", err)
+	}
+	clusterBackup := &backupv1alpha1.ClusterBackup{}
+	if err := yaml.UnmarshalStrict(data, clusterBackup); err != nil {
+		return fmt.Errorf("failed to parse ClusterBackup manifest: %w", err)
+	}
+	if clusterBackup.Namespace == "" {
+		clusterBackup.Namespace = "default"
+	}
+
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	c, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+	backupManager, err := backup.NewBackupManager(config)
+	if err != nil {
+		return fmt.Errorf("failed to create backup manager: %w", err)
+	}
+
+	name := fmt.Sprintf("backup-operator-%s-reader", clusterBackup.Name)
+	role, err := controller.MinimalClusterRole(ctx, c, backupManager, clusterBackup, name)
+	if err != nil {
+		return fmt.Errorf("failed to generate ClusterRole: %w", err)
+	}
+	out, err := yaml.Marshal(role)
+	if err != nil {
+		return fmt.Errorf("failed to encode ClusterRole: %w", err)
+	}
+	_, err = os.Stdout.Write(out)
+	return err
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+// verifyArchiveFile reads the archive at path and checks every entry against
+// its manifest, printing the result. Archives encrypted with a KMS key are
+// decrypted with the workload credentials; age-encrypted archives cannot be
+// verified this way.
+func verifyArchiveFile(ctx context.Context, path string) error {
+	// Only the storage of the manager is used, so it needs no cluster
+	bm := &backup.BackupManager{}
+	result, err := bm.VerifyArchive(ctx, filepath.Dir(path), filepath.Base(path), backup.VerifyOptions{})
+	if err != nil {
+		return err
+	}
+	if !result.Verified() {
+		return fmt.Errorf("%s: %s", path, result)
+	}
+	fmt.Printf("%s: %s\n", path, result)
+	return nil
+}
@@ -31,6 +31,26 @@ app.kubernetes.io/managed-by: {{ .Release.Service }}
 app.kubernetes.io/version: {{ .Chart.AppVersion }}
 {{- end -}}
 
+{{/*
+A scratch volume source: emptyDir (default), memory or persistentVolumeClaim.
+*/}}
+{{- define "backup-operator.scratchVolume" -}}
+{{- if eq (default "emptyDir" .type) "persistentVolumeClaim" -}}
+persistentVolumeClaim:
+  claimName: {{ required "persistentVolumeClaim scratch volumes need a claimName" .claimName }}
+{{- else if or (eq (default "emptyDir" .type) "memory") .sizeLimit -}}
+emptyDir:
+  {{- if eq (default "emptyDir" .type) "memory" }}
+  medium: Memory
+  {{- end }}
+  {{- with .sizeLimit }}
+  sizeLimit: {{ . }}
+  {{- end }}
+{{- else -}}
+emptyDir: {}
+{{- end -}}
+{{- end -}}
+
 {{- define "backup-operator.selectorLabels" -}}
 control-plane: controller-manager
 app.kubernetes.io/name: {{ include "backup-operator.name" . }}
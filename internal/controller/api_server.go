@@ -0,0 +1,511 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// RequestedByAnnotation records on a ClusterRestore created through the
+// API server the user who asked for it.
+const RequestedByAnnotation = "backup.backup.io/requested-by"
+
+// apiTokenUser is the user name requests authenticated with the static
+// token act as.
+const apiTokenUser = "backup-operator:api-token"
+
+// DefaultAPIAudience is the audience Kubernetes tokens sent to the API
+// server must be issued for when APIServer.Audience is unset.
+const DefaultAPIAudience = "backup-operator-api"
+
+// APIServer serves a REST API to start backups, list and delete archives
+// and start restores, for integrations that cannot create custom resources
+// themselves. Every request carries a bearer token: either Token, which
+// grants every operation, or a Kubernetes token issued for Audience that is
+// checked with a TokenReview and authorized with a SubjectAccessReview on
+// the backup.backup.io resource the request acts on.
+//
+// The API is only served by the elected leader, so archive deletions take
+// the same in-process storage lock as the backups and retention they race
+// with.
+type APIServer struct {
+	Client        client.Client
+	BackupManager *backup.BackupManager
+	Recorder      record.EventRecorder
+	// Addr is the address the server listens on.
+	Addr string
+	// Token, when set, is a static bearer token allowed to do everything.
+	Token string
+	// Audience is the audience Kubernetes tokens must be issued for, so
+	// tokens meant for other services are refused. Defaults to
+	// DefaultAPIAudience.
+	Audience string
+	// CertDir holds the serving certificate CertName and key KeyName. The
+	// server speaks plain HTTP when it is empty.
+	CertDir  string
+	CertName string
+	KeyName  string
+	TLSOpts  []func(*tls.Config)
+}
+
+// NeedLeaderElection serves the API from the leader only, since the
+// storage lock archive deletions take does not reach other replicas.
+func (s *APIServer) NeedLeaderElection() bool {
+	return true
+}
+
+// Start serves the API until ctx is done.
+func (s *APIServer) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx).WithName("api-server")
+
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.Addr, err)
+	}
+	if s.CertDir != "" {
+		watcher, err := certwatcher.New(filepath.Join(s.CertDir, s.CertName), filepath.Join(s.CertDir, s.KeyName))
+		if err != nil {
+			_ = listener.Close()
+			return fmt.Errorf("failed to load API server certificate: %w", err)
+		}
+		go func() {
+			if err := watcher.Start(ctx); err != nil {
+				log.Error(err, "Failed to watch API server certificate")
+			}
+		}()
+		config := &tls.Config{GetCertificate: watcher.GetCertificate, MinVersion: tls.VersionTLS12}
+		for _, opt := range s.TLSOpts {
+			opt(config)
+		}
+		listener = tls.NewListener(listener, config)
+	} else {
+		log.Info("Serving the API without TLS; bearer tokens are sent in the clear")
+	}
+
+	server := &http.Server{
+		Handler:           s.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Error(err, "Failed to shut down API server")
+		}
+	}()
+
+	log.Info("Serving API", "addr", listener.Addr().String())
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve API: %w", err)
+	}
+	return nil
+}
+
+// handler routes the API.
+func (s *APIServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/clusterbackups", s.listBackups)
+	mux.HandleFunc("GET /api/v1/namespaces/{namespace}/clusterbackups", s.listBackups)
+	mux.HandleFunc("POST /api/v1/namespaces/{namespace}/clusterbackups/{name}/run", s.runBackup)
+	mux.HandleFunc("GET /api/v1/namespaces/{namespace}/clusterbackups/{name}/archives", s.listArchives)
+	mux.HandleFunc("DELETE /api/v1/namespaces/{namespace}/clusterbackups/{name}/archives/{archive}", s.deleteArchive)
+	mux.HandleFunc("POST /api/v1/namespaces/{namespace}/clusterrestores", s.createRestore)
+	mux.HandleFunc("GET /api/v1/namespaces/{namespace}/clusterrestores/{name}", s.getRestore)
+	return s.authenticate(mux)
+}
+
+type apiCallerKey struct{}
+
+// apiCaller is who sent a request.
+type apiCaller struct {
+	user authenticationv1.UserInfo
+	// static is set for the static token, which skips authorization.
+	static bool
+}
+
+// callerOf returns who sent r.
+func callerOf(r *http.Request) apiCaller {
+	caller, _ := r.Context().Value(apiCallerKey{}).(apiCaller)
+	return caller
+}
+
+// authenticate rejects requests without a valid bearer token and passes the
+// others on with the user they authenticated as.
+func (s *APIServer) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			writeAPIError(w, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+
+		var caller apiCaller
+		if s.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) == 1 {
+			caller = apiCaller{user: authenticationv1.UserInfo{Username: apiTokenUser}, static: true}
+		} else {
+			audience := s.Audience
+			if audience == "" {
+				audience = DefaultAPIAudience
+			}
+			review := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{
+				Token: token, Audiences: []string{audience},
+			}}
+			if err := s.Client.Create(r.Context(), review); err != nil {
+				logf.FromContext(r.Context()).Error(err, "Failed to review API token")
+				writeAPIError(w, http.StatusInternalServerError, "failed to review token")
+				return
+			}
+			// Tokens of authenticators that do not check audiences come
+			// back without them
+			if !review.Status.Authenticated || !slices.Contains(review.Status.Audiences, audience) {
+				writeAPIError(w, http.StatusUnauthorized, "invalid bearer token")
+				return
+			}
+			caller = apiCaller{user: review.Status.User}
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiCallerKey{}, caller)))
+	})
+}
+
+// authorize reports whether the user of r may act on attrs, answering the
+// request when not. The static token may do everything.
+func (s *APIServer) authorize(w http.ResponseWriter, r *http.Request, attrs authorizationv1.ResourceAttributes) bool {
+	caller := callerOf(r)
+	if caller.static {
+		return true
+	}
+	user := caller.user
+
+	attrs.Group = backupv1alpha1.GroupVersion.Group
+	attrs.Version = backupv1alpha1.GroupVersion.Version
+	extra := map[string]authorizationv1.ExtraValue{}
+	for key, values := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(values)
+	}
+	review := &authorizationv1.SubjectAccessReview{Spec: authorizationv1.SubjectAccessReviewSpec{
+		ResourceAttributes: &attrs,
+		User:               user.Username,
+		UID:                user.UID,
+		Groups:             user.Groups,
+		Extra:              extra,
+	}}
+	if err := s.Client.Create(r.Context(), review); err != nil {
+		logf.FromContext(r.Context()).Error(err, "Failed to review API access", "user", user.Username)
+		writeAPIError(w, http.StatusInternalServerError, "failed to review access")
+		return false
+	}
+	if !review.Status.Allowed {
+		resource := attrs.Resource
+		if attrs.Subresource != "" {
+			resource += "/" + attrs.Subresource
+		}
+		writeAPIError(w, http.StatusForbidden, fmt.Sprintf("%s may not %s %s in namespace %q",
+			user.Username, attrs.Verb, resource, attrs.Namespace))
+		return false
+	}
+	return true
+}
+
+// listBackups returns the ClusterBackups of a namespace, or of every
+// namespace.
+func (s *APIServer) listBackups(w http.ResponseWriter, r *http.Request) {
+	namespace := r.PathValue("namespace")
+	if !s.authorize(w, r, authorizationv1.ResourceAttributes{Namespace: namespace, Verb: "list", Resource: "clusterbackups"}) {
+		return
+	}
+	var list backupv1alpha1.ClusterBackupList
+	if err := s.Client.List(r.Context(), &list, client.InNamespace(namespace)); err != nil {
+		writeAPIClientError(w, err)
+		return
+	}
+	writeAPIResponse(w, http.StatusOK, &list)
+}
+
+// runBackup starts a run of a ClusterBackup by setting its trigger
+// annotation.
+func (s *APIServer) runBackup(w http.ResponseWriter, r *http.Request) {
+	key := types.NamespacedName{Namespace: r.PathValue("namespace"), Name: r.PathValue("name")}
+	if !s.authorize(w, r, authorizationv1.ResourceAttributes{Namespace: key.Namespace, Name: key.Name, Verb: "update", Resource: "clusterbackups"}) {
+		return
+	}
+	clusterBackup := &backupv1alpha1.ClusterBackup{}
+	if err := s.Client.Get(r.Context(), key, clusterBackup); err != nil {
+		writeAPIClientError(w, err)
+		return
+	}
+	if !clusterBackup.DeletionTimestamp.IsZero() {
+		writeAPIError(w, http.StatusConflict, "ClusterBackup is being deleted")
+		return
+	}
+
+	trigger := time.Now().UTC().Format(time.RFC3339Nano)
+	patch := client.MergeFrom(clusterBackup.DeepCopy())
+	if clusterBackup.Annotations == nil {
+		clusterBackup.Annotations = map[string]string{}
+	}
+	clusterBackup.Annotations[backupv1alpha1.TriggerAnnotation] = trigger
+	if err := s.Client.Patch(r.Context(), clusterBackup, patch); err != nil {
+		writeAPIClientError(w, err)
+		return
+	}
+	logf.FromContext(r.Context()).Info("Backup requested through the API", "namespace", key.Namespace, "name", key.Name,
+		"user", callerOf(r).user.Username)
+	writeAPIResponse(w, http.StatusAccepted, map[string]string{"namespace": key.Namespace, "name": key.Name, "trigger": trigger})
+}
+
+// apiArchive is an archive in the response of listArchives.
+type apiArchive struct {
+	Name   string `json:"name"`
+	Pinned bool   `json:"pinned,omitempty"`
+	// Size and Checksum are taken from the storage catalog, when it lists
+	// the archive.
+	Size     int64  `json:"size,omitempty"`
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// listArchives returns the archives in the storage location of a
+// ClusterBackup, oldest first.
+func (s *APIServer) listArchives(w http.ResponseWriter, r *http.Request) {
+	key := types.NamespacedName{Namespace: r.PathValue("namespace"), Name: r.PathValue("name")}
+	if !s.authorize(w, r, authorizationv1.ResourceAttributes{Namespace: key.Namespace, Name: key.Name, Verb: "get", Resource: "clusterbackups"}) {
+		return
+	}
+	clusterBackup, storagePath, _, ok := s.backupStorage(w, r, key)
+	if !ok {
+		return
+	}
+	names, err := s.BackupManager.ListArchives(storagePath)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	catalog, err := s.BackupManager.ReadCatalog(storagePath)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	archives := make([]apiArchive, 0, len(names))
+	for _, name := range names {
+		archive := apiArchive{
+			Name:   name,
+			Pinned: slices.Contains(clusterBackup.Spec.PinnedArchives, name) || s.BackupManager.IsPinned(storagePath, name),
+		}
+		if catalog != nil {
+			if entry, ok := catalog.Entry(name); ok {
+				archive.Size, archive.Checksum = entry.Size, entry.Checksum
+			}
+		}
+		archives = append(archives, archive)
+	}
+	writeAPIResponse(w, http.StatusOK, map[string]interface{}{"storagePath": storagePath, "archives": archives})
+}
+
+// deleteArchive removes an archive from the storage location of a
+// ClusterBackup. Pinned, immutable and in-use archives and the bases of
+// incremental archives are refused.
+func (s *APIServer) deleteArchive(w http.ResponseWriter, r *http.Request) {
+	key := types.NamespacedName{Namespace: r.PathValue("namespace"), Name: r.PathValue("name")}
+	archive := r.PathValue("archive")
+	if !s.authorize(w, r, authorizationv1.ResourceAttributes{Namespace: key.Namespace, Name: key.Name, Verb: "delete",
+		Resource: "clusterbackups", Subresource: "archives"}) {
+		return
+	}
+	if err := backup.ValidateArchiveName(archive); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	clusterBackup, storagePath, config, ok := s.backupStorage(w, r, key)
+	if !ok {
+		return
+	}
+	if slices.Contains(clusterBackup.Spec.PinnedArchives, archive) {
+		writeAPIError(w, http.StatusConflict, fmt.Sprintf("archive %s is pinned by spec.pinnedArchives", archive))
+		return
+	}
+
+	user := callerOf(r).user.Username
+	unlock, err := s.BackupManager.LockStorage("api/"+user, storagePath)
+	if err != nil {
+		writeAPIError(w, http.StatusConflict, err.Error())
+		return
+	}
+	defer unlock()
+	if err := s.BackupManager.DeleteArchive(r.Context(), storagePath, archive); err != nil {
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			writeAPIError(w, http.StatusNotFound, fmt.Sprintf("archive %s not found", archive))
+		case errors.Is(err, backup.ErrArchiveProtected), errors.Is(err, backup.ErrArchiveImmutable), errors.Is(err, backup.ErrArchiveInUse):
+			writeAPIError(w, http.StatusConflict, err.Error())
+		default:
+			writeAPIError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	recordAudit(r.Context(), s.BackupManager, s.Recorder, config, clusterBackup, []string{storagePath}, backup.AuditRecord{
+		Operation: backup.AuditOperationDelete, Resource: auditResource("ClusterBackup", clusterBackup), User: user,
+		Archive: archive, Result: "success", Message: "deleted through the API",
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// backupStorage returns the named ClusterBackup with its storage location
+// and the operator configuration, answering the request when one of them
+// cannot be found.
+func (s *APIServer) backupStorage(w http.ResponseWriter, r *http.Request, key types.NamespacedName) (
+	*backupv1alpha1.ClusterBackup, string, *backupv1alpha1.BackupOperatorConfigSpec, bool) {
+	clusterBackup := &backupv1alpha1.ClusterBackup{}
+	if err := s.Client.Get(r.Context(), key, clusterBackup); err != nil {
+		writeAPIClientError(w, err)
+		return nil, "", nil, false
+	}
+	config, err := loadOperatorConfig(r.Context(), s.Client)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return nil, "", nil, false
+	}
+	storagePath := storagePathFor(clusterBackup, config)
+	if storagePath == "" {
+		writeAPIError(w, http.StatusConflict, "ClusterBackup has no usable storage location")
+		return nil, "", nil, false
+	}
+	return clusterBackup, storagePath, config, true
+}
+
+// apiRestoreRequest is the body of createRestore. Name is generated when
+// empty.
+type apiRestoreRequest struct {
+	Name string                            `json:"name,omitempty"`
+	Spec backupv1alpha1.ClusterRestoreSpec `json:"spec"`
+}
+
+// createRestore starts a restore by creating a ClusterRestore.
+func (s *APIServer) createRestore(w http.ResponseWriter, r *http.Request) {
+	namespace := r.PathValue("namespace")
+	if !s.authorize(w, r, authorizationv1.ResourceAttributes{Namespace: namespace, Verb: "create", Resource: "clusterrestores"}) {
+		return
+	}
+	var request apiRestoreRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("failed to decode request: %v", err))
+		return
+	}
+
+	// The operator creates the ClusterRestore, so admission cannot check
+	// the impersonation against the user who asked for it
+	caller := callerOf(r)
+	user := caller.user
+	if identity := impersonationIdentity(namespace, request.Spec.Impersonate); identity != nil && !caller.static {
+		allowed, reason, err := s.BackupManager.MayImpersonate(r.Context(), user, *identity)
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !allowed {
+			writeAPIError(w, http.StatusForbidden, reason)
+			return
+		}
+	}
+
+	restore := &backupv1alpha1.ClusterRestore{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        request.Name,
+			Namespace:   namespace,
+			Annotations: map[string]string{RequestedByAnnotation: user.Username},
+		},
+		Spec: request.Spec,
+	}
+	if restore.Name == "" {
+		restore.GenerateName = "api-restore-"
+	}
+	if err := s.Client.Create(r.Context(), restore); err != nil {
+		writeAPIClientError(w, err)
+		return
+	}
+	logf.FromContext(r.Context()).Info("Restore requested through the API", "namespace", namespace, "name", restore.Name,
+		"user", user.Username)
+	writeAPIResponse(w, http.StatusCreated, restore)
+}
+
+// getRestore returns a ClusterRestore, whose status tells how the restore
+// is getting on.
+func (s *APIServer) getRestore(w http.ResponseWriter, r *http.Request) {
+	key := types.NamespacedName{Namespace: r.PathValue("namespace"), Name: r.PathValue("name")}
+	if !s.authorize(w, r, authorizationv1.ResourceAttributes{Namespace: key.Namespace, Name: key.Name, Verb: "get", Resource: "clusterrestores"}) {
+		return
+	}
+	restore := &backupv1alpha1.ClusterRestore{}
+	if err := s.Client.Get(r.Context(), key, restore); err != nil {
+		writeAPIClientError(w, err)
+		return
+	}
+	writeAPIResponse(w, http.StatusOK, restore)
+}
+
+// writeAPIResponse writes body as JSON with status.
+func writeAPIResponse(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// writeAPIError writes message as a JSON error with status.
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeAPIResponse(w, status, map[string]string{"error": message})
+}
+
+// writeAPIClientError writes an error returned by the Kubernetes API with
+// the status it carries.
+func writeAPIClientError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var statusErr apierrors.APIStatus
+	if errors.As(err, &statusErr) && statusErr.Status().Code != 0 {
+		status = int(statusErr.Status().Code)
+	}
+	writeAPIError(w, status, err.Error())
+}
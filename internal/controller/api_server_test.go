@@ -0,0 +1,165 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+var _ = Describe("API server", func() {
+	ctx := context.Background()
+	typeNamespacedName := types.NamespacedName{Name: "test-api", Namespace: "default"}
+
+	AfterEach(func() {
+		resource := &backupv1alpha1.ClusterBackup{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+	})
+
+	It("should run backups and manage archives for the static token", func() {
+		storagePath := GinkgoT().TempDir()
+		archives := []string{"cluster-backup-20250101-000000.tar.gz", "cluster-backup-20250102-000000.tar.gz"}
+		for _, name := range archives {
+			Expect(os.WriteFile(filepath.Join(storagePath, name), []byte(name), 0o644)).To(Succeed())
+		}
+		Expect(k8sClient.Create(ctx, &backupv1alpha1.ClusterBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: typeNamespacedName.Name, Namespace: typeNamespacedName.Namespace},
+			Spec:       backupv1alpha1.ClusterBackupSpec{StoragePath: storagePath, PinnedArchives: archives[:1]},
+		})).To(Succeed())
+
+		handler := (&APIServer{Client: k8sClient, BackupManager: &backup.BackupManager{}, Token: "secret"}).handler()
+		serve := func(method, path, token string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(method, path, nil)
+			if token != "" {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			return rec
+		}
+		base := "/api/v1/namespaces/default/clusterbackups/" + typeNamespacedName.Name
+
+		Expect(serve(http.MethodGet, "/api/v1/clusterbackups", "").Code).To(Equal(http.StatusUnauthorized))
+
+		By("starting a run")
+		Expect(serve(http.MethodPost, base+"/run", "secret").Code).To(Equal(http.StatusAccepted))
+		clusterBackup := &backupv1alpha1.ClusterBackup{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, clusterBackup)).To(Succeed())
+		Expect(triggered(clusterBackup)).To(BeTrue())
+
+		By("listing archives")
+		rec := serve(http.MethodGet, base+"/archives", "secret")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		var listed struct {
+			Archives []apiArchive `json:"archives"`
+		}
+		Expect(json.NewDecoder(rec.Body).Decode(&listed)).To(Succeed())
+		Expect(listed.Archives).To(Equal([]apiArchive{{Name: archives[0], Pinned: true}, {Name: archives[1]}}))
+
+		By("deleting archives")
+		Expect(serve(http.MethodDelete, base+"/archives/"+archives[0], "secret").Code).To(Equal(http.StatusConflict))
+		Expect(serve(http.MethodDelete, base+"/archives/"+archives[1], "secret").Code).To(Equal(http.StatusNoContent))
+		Expect(serve(http.MethodDelete, base+"/archives/"+archives[1], "secret").Code).To(Equal(http.StatusNotFound))
+		Expect(filepath.Join(storagePath, archives[1])).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(storagePath, archives[0])).To(BeAnExistingFile())
+	})
+
+	It("should create restores for the static token", func() {
+		Expect(k8sClient.Create(ctx, &backupv1alpha1.ClusterBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: typeNamespacedName.Name, Namespace: typeNamespacedName.Namespace},
+			Spec:       backupv1alpha1.ClusterBackupSpec{StoragePath: GinkgoT().TempDir()},
+		})).To(Succeed())
+
+		handler := (&APIServer{Client: k8sClient, BackupManager: &backup.BackupManager{}, Token: "secret"}).handler()
+		body := `{"spec": {"backupName": "test-api", "archiveName": "cluster-backup-20250101-000000.tar.gz"}}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/namespaces/default/clusterrestores", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		Expect(rec.Code).To(Equal(http.StatusCreated))
+
+		restore := &backupv1alpha1.ClusterRestore{}
+		Expect(json.NewDecoder(rec.Body).Decode(restore)).To(Succeed())
+		Expect(restore.Name).To(HavePrefix("api-restore-"))
+		Expect(restore.Annotations).To(HaveKeyWithValue(RequestedByAnnotation, apiTokenUser))
+		Expect(k8sClient.Delete(ctx, restore)).To(Succeed())
+	})
+})
+
+// tokenReviewer authenticates every token as issued for audiences and
+// denies every SubjectAccessReview.
+type tokenReviewer struct {
+	client.Client
+	audiences []string
+	reviewed  []string
+}
+
+func (c *tokenReviewer) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	if review, ok := obj.(*authenticationv1.TokenReview); ok {
+		c.reviewed = review.Spec.Audiences
+		for _, audience := range review.Spec.Audiences {
+			if slices.Contains(c.audiences, audience) {
+				review.Status = authenticationv1.TokenReviewStatus{
+					Authenticated: true, User: authenticationv1.UserInfo{Username: "alice"}, Audiences: []string{audience},
+				}
+			}
+		}
+	}
+	return nil
+}
+
+var _ = Describe("API server authentication", func() {
+	serve := func(reviewer *tokenReviewer) int {
+		handler := (&APIServer{Client: reviewer, BackupManager: &backup.BackupManager{}}).handler()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/clusterbackups", nil)
+		req.Header.Set("Authorization", "Bearer kubernetes-token")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	It("should only accept Kubernetes tokens issued for the API audience", func() {
+		reviewer := &tokenReviewer{audiences: []string{"https://kubernetes.default.svc"}}
+		Expect(serve(reviewer)).To(Equal(http.StatusUnauthorized))
+		Expect(reviewer.reviewed).To(Equal([]string{DefaultAPIAudience}))
+
+		// Authenticated, then refused by the SubjectAccessReview
+		reviewer.audiences = append(reviewer.audiences, DefaultAPIAudience)
+		Expect(serve(reviewer)).To(Equal(http.StatusForbidden))
+	})
+
+	It("should only be served by the leader", func() {
+		Expect((&APIServer{}).NeedLeaderElection()).To(BeTrue())
+	})
+})
@@ -0,0 +1,135 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+// archiveVerifiedCondition reports whether a cataloged archive was read back
+// from storage and matched its manifest.
+const archiveVerifiedCondition = "Verified"
+
+// ArchiveVerifier periodically reads back every archive in the catalog of
+// each ClusterBackup and checks its entries against their checksums. Results
+// are surfaced as a Verified condition on each catalog entry and as a metric
+// counting the archives that failed.
+type ArchiveVerifier struct {
+	Client        client.Client
+	BackupManager *backup.BackupManager
+	Interval      time.Duration
+}
+
+// NeedLeaderElection makes only the leader verify archives and update
+// status.
+func (v *ArchiveVerifier) NeedLeaderElection() bool {
+	return true
+}
+
+// Start verifies all cataloged archives every Interval until ctx is done.
+func (v *ArchiveVerifier) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, v.verifyAll, v.Interval)
+	return nil
+}
+
+// verifyAll verifies the catalog of every ClusterBackup.
+func (v *ArchiveVerifier) verifyAll(ctx context.Context) {
+	log := logf.FromContext(ctx).WithName("archive-verifier")
+
+	var list backupv1alpha1.ClusterBackupList
+	if err := v.Client.List(ctx, &list); err != nil {
+		log.Error(err, "Failed to list ClusterBackups")
+		return
+	}
+
+	for i := range list.Items {
+		clusterBackup := &list.Items[i]
+		if !clusterBackup.DeletionTimestamp.IsZero() || len(clusterBackup.Status.Archives) == 0 {
+			continue
+		}
+		if err := v.verify(ctx, clusterBackup); err != nil {
+			log.Error(err, "Failed to verify archives",
+				"namespace", clusterBackup.Namespace, "name", clusterBackup.Name)
+		}
+	}
+}
+
+// verify reads back each cataloged archive of clusterBackup and patches the
+// Verified conditions of its catalog.
+func (v *ArchiveVerifier) verify(ctx context.Context, clusterBackup *backupv1alpha1.ClusterBackup) error {
+	log := logf.FromContext(ctx)
+
+	var opts backup.VerifyOptions
+	if restore := clusterBackup.Spec.Restore; restore != nil {
+		var err error
+		if opts.KeyWrappers, err = ageKeyWrappers(ctx, v.Client, clusterBackup.Namespace, restore.AgeIdentitySecretRef); err != nil {
+			return err
+		}
+	}
+
+	patch := client.MergeFromWithOptions(clusterBackup.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	failed := 0
+	for i := range clusterBackup.Status.Archives {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		entry := &clusterBackup.Status.Archives[i]
+		result, err := v.BackupManager.VerifyArchive(ctx, entry.StoragePath, entry.Name, opts)
+		condition := verifiedCondition(result, err)
+		if condition.Status != metav1.ConditionTrue {
+			failed++
+			log.Info("Archive failed verification", "archive", entry.Name, "storagePath", entry.StoragePath,
+				"reason", condition.Reason, "message", condition.Message)
+		}
+		condition.ObservedGeneration = clusterBackup.Generation
+		meta.SetStatusCondition(&entry.Conditions, condition)
+	}
+	archiveVerificationFailures.WithLabelValues(clusterBackup.Namespace, clusterBackup.Name).Set(float64(failed))
+
+	if err := v.Client.Status().Patch(ctx, clusterBackup, patch); err != nil && !apierrors.IsConflict(err) {
+		return err
+	}
+	return nil
+}
+
+// verifiedCondition builds the Verified condition for a verification.
+func verifiedCondition(result *backup.ArchiveVerification, verifyErr error) metav1.Condition {
+	switch {
+	case errors.Is(verifyErr, backup.ErrNoManifest):
+		return metav1.Condition{Type: archiveVerifiedCondition, Status: metav1.ConditionUnknown, Reason: "NoManifest", Message: verifyErr.Error()}
+	case errors.Is(verifyErr, backup.ErrUnsupportedArchiveFormat):
+		return metav1.Condition{Type: archiveVerifiedCondition, Status: metav1.ConditionUnknown, Reason: "UnsupportedFormat", Message: verifyErr.Error()}
+	case verifyErr != nil:
+		return metav1.Condition{Type: archiveVerifiedCondition, Status: metav1.ConditionFalse, Reason: "Unreadable", Message: verifyErr.Error()}
+	case !result.Verified():
+		return metav1.Condition{Type: archiveVerifiedCondition, Status: metav1.ConditionFalse, Reason: "ChecksumMismatch", Message: result.String()}
+	default:
+		return metav1.Condition{Type: archiveVerifiedCondition, Status: metav1.ConditionTrue, Reason: "ChecksumsMatch", Message: result.String()}
+	}
+}
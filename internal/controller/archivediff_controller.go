@@ -0,0 +1,162 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+// ArchiveDiffReconciler reconciles an ArchiveDiff object
+type ArchiveDiffReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	BackupManager *backup.BackupManager
+}
+
+// +kubebuilder:rbac:groups=backup.backup.io,resources=archivediffs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=backup.backup.io,resources=archivediffs/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=backup.backup.io,resources=archivediffs/finalizers,verbs=update
+
+// Reconcile compares the archive named by an ArchiveDiff with the live
+// cluster, once per generation or every spec.interval, and records the
+// drifted objects in status.
+func (r *ArchiveDiffReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	diff := &backupv1alpha1.ArchiveDiff{}
+	if err := r.Get(ctx, req.NamespacedName, diff); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get ArchiveDiff")
+		return ctrl.Result{}, err
+	}
+
+	var interval time.Duration
+	if diff.Spec.Interval != nil {
+		interval = diff.Spec.Interval.Duration
+	}
+	if diff.Status.ObservedGeneration == diff.Generation && diff.Status.LastDiffTime != nil {
+		if interval <= 0 {
+			return ctrl.Result{}, nil
+		}
+		if wait := time.Until(diff.Status.LastDiffTime.Add(interval)); wait > 0 {
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+	}
+
+	now := metav1.Now()
+	diff.Status.ObservedGeneration = diff.Generation
+	diff.Status.LastDiffTime = &now
+
+	if reason, err := r.diff(ctx, diff); err != nil {
+		log.Error(err, "Archive diff failed")
+		diff.Status.Phase = backupv1alpha1.DiffPhaseFailed
+		diff.Status.Message = fmt.Sprintf("Diff failed: %v", err)
+		backup.SetCondition(&diff.Status.Conditions, "Drifted", metav1.ConditionUnknown, reason, err.Error())
+	}
+
+	if err := r.Status().Update(ctx, diff); err != nil {
+		log.Error(err, "Failed to update ArchiveDiff status")
+		return ctrl.Result{}, err
+	}
+	if interval > 0 {
+		return ctrl.Result{RequeueAfter: interval}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// diff compares the archive with the cluster and records the result in
+// status. On failure it returns the condition reason alongside the error.
+func (r *ArchiveDiffReconciler) diff(ctx context.Context, diff *backupv1alpha1.ArchiveDiff) (string, error) {
+	storagePath := diff.Spec.StoragePath
+	if storagePath == "" {
+		var err error
+		if storagePath, err = backupStoragePath(ctx, r.Client, diff.Namespace, diff.Spec.BackupName); err != nil {
+			return "SourceNotResolved", err
+		}
+	}
+	diff.Status.StoragePath = storagePath
+
+	keyWrappers, err := ageKeyWrappers(ctx, r.Client, diff.Namespace, diff.Spec.AgeIdentitySecretRef)
+	if err != nil {
+		return "InvalidIdentity", err
+	}
+	result, err := r.BackupManager.DiffArchive(ctx, storagePath, diff.Spec.ArchiveName, backup.DiffOptions{
+		KeyWrappers: keyWrappers,
+		IgnoreExtra: diff.Spec.IgnoreExtra,
+	})
+	if err != nil {
+		return "DiffFailed", err
+	}
+
+	diff.Status.Phase = backupv1alpha1.DiffPhaseCompleted
+	diff.Status.Missing = result.Missing
+	diff.Status.Changed = result.Changed
+	diff.Status.Extra = result.Extra
+	diff.Status.Unchanged = result.Unchanged
+	diff.Status.Items = archiveDiffItems(result.Items)
+	diff.Status.Message = fmt.Sprintf("Compared %s with the cluster: %s", diff.Spec.ArchiveName, result)
+	if result.Drifted() {
+		backup.SetCondition(&diff.Status.Conditions, "Drifted", metav1.ConditionTrue, "DriftDetected", result.String())
+	} else {
+		backup.SetCondition(&diff.Status.Conditions, "Drifted", metav1.ConditionFalse, "NoDrift", "The cluster matches the archive")
+	}
+	return "", nil
+}
+
+// archiveDiffItems converts drifted objects to their status form.
+func archiveDiffItems(items []backup.ObjectDrift) []backupv1alpha1.ArchiveDiffItem {
+	if len(items) == 0 {
+		return nil
+	}
+	out := make([]backupv1alpha1.ArchiveDiffItem, 0, len(items))
+	for _, item := range items {
+		out = append(out, backupv1alpha1.ArchiveDiffItem{
+			Resource:  item.GVR.GroupResource().String(),
+			Namespace: item.Namespace,
+			Name:      item.Name,
+			State:     string(item.State),
+			Diff:      item.Diff,
+		})
+	}
+	return out
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ArchiveDiffReconciler) SetupWithManager(mgr ctrl.Manager, options Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		// Status updates must not start another comparison
+		For(&backupv1alpha1.ArchiveDiff{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Named("archivediff").
+		WithOptions(options.forController("archivediff")).
+		Complete(r)
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+var _ = Describe("ArchiveDiff Controller", func() {
+	ctx := context.Background()
+	typeNamespacedName := types.NamespacedName{Name: "test-diff", Namespace: "default"}
+
+	AfterEach(func() {
+		resource := &backupv1alpha1.ArchiveDiff{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+	})
+
+	It("should report a missing archive and retry on the interval", func() {
+		Expect(k8sClient.Create(ctx, &backupv1alpha1.ArchiveDiff{
+			ObjectMeta: metav1.ObjectMeta{Name: typeNamespacedName.Name, Namespace: typeNamespacedName.Namespace},
+			Spec: backupv1alpha1.ArchiveDiffSpec{
+				ArchiveName: "cluster-backup-20250101-000000.tar.gz",
+				StoragePath: GinkgoT().TempDir(),
+				Interval:    &metav1.Duration{Duration: time.Hour},
+			},
+		})).To(Succeed())
+
+		reconciler := &ArchiveDiffReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), BackupManager: &backup.BackupManager{}}
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(time.Hour))
+
+		diff := &backupv1alpha1.ArchiveDiff{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, diff)).To(Succeed())
+		Expect(diff.Status.Phase).To(Equal(backupv1alpha1.DiffPhaseFailed))
+		Expect(diff.Status.Message).To(ContainSubstring("failed to find archive"))
+		Expect(diff.Status.Conditions).To(ContainElement(And(
+			HaveField("Type", "Drifted"),
+			HaveField("Status", metav1.ConditionUnknown),
+			HaveField("Reason", "DiffFailed"),
+		)))
+
+		By("waiting for the interval before comparing again")
+		lastDiff := diff.Status.LastDiffTime
+		result, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically(">", 59*time.Minute))
+		Expect(k8sClient.Get(ctx, typeNamespacedName, diff)).To(Succeed())
+		Expect(diff.Status.LastDiffTime.Equal(lastDiff)).To(BeTrue())
+	})
+})
@@ -0,0 +1,191 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+const (
+	// defaultReplicationInterval applies when spec.interval is unset.
+	defaultReplicationInterval = 15 * time.Minute
+
+	// maxCatalogedArchives bounds how many archives are listed in status.
+	maxCatalogedArchives = 50
+)
+
+// ArchiveReplicationReconciler copies completed archives from a primary
+// storage location to secondary ones
+type ArchiveReplicationReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	BackupManager *backup.BackupManager
+}
+
+// +kubebuilder:rbac:groups=backup.backup.io,resources=archivereplications,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=backup.backup.io,resources=archivereplications/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=backup.backup.io,resources=archivereplications/finalizers,verbs=update
+
+// Reconcile copies the archives a destination is missing and records the
+// replication state of every archive in status.
+func (r *ArchiveReplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	replication := &backupv1alpha1.ArchiveReplication{}
+	if err := r.Get(ctx, req.NamespacedName, replication); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get ArchiveReplication")
+		return ctrl.Result{}, err
+	}
+
+	interval := defaultReplicationInterval
+	if replication.Spec.Interval != nil && replication.Spec.Interval.Duration > 0 {
+		interval = replication.Spec.Interval.Duration
+	}
+
+	now := metav1.Now()
+	replication.Status.ObservedGeneration = replication.Generation
+	replication.Status.LastSyncTime = &now
+
+	source, err := r.resolveSource(ctx, replication)
+	if err == nil {
+		replication.Status.SourceStoragePath = source
+		var archives []string
+		var replicas []backup.ArchiveReplica
+		archives, replicas, err = r.BackupManager.ReplicateArchives(ctx, source, replication.Spec.Destinations)
+		if err == nil {
+			setReplicationCatalog(replication, archives, replicas, now)
+		}
+	}
+	if err != nil {
+		log.Error(err, "Failed to replicate archives")
+		backup.SetCondition(&replication.Status.Conditions, "Ready", metav1.ConditionFalse, "SourceUnavailable", err.Error())
+	}
+
+	if err := r.Status().Update(ctx, replication); err != nil {
+		log.Error(err, "Failed to update ArchiveReplication status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: interval}, nil
+}
+
+// resolveSource returns the storage location archives are replicated from.
+func (r *ArchiveReplicationReconciler) resolveSource(ctx context.Context, replication *backupv1alpha1.ArchiveReplication) (string, error) {
+	if replication.Spec.SourceStoragePath != "" {
+		return replication.Spec.SourceStoragePath, nil
+	}
+
+	return backupStoragePath(ctx, r.Client, replication.Namespace, replication.Spec.BackupName)
+}
+
+// setReplicationCatalog records the state of each archive in every
+// destination, newest archive first, and sets the Ready condition.
+func setReplicationCatalog(replication *backupv1alpha1.ArchiveReplication, archives []string, replicas []backup.ArchiveReplica, now metav1.Time) {
+	// Keep when each archive first reached each destination
+	replicated := map[string]*metav1.Time{}
+	for _, archive := range replication.Status.Archives {
+		for _, replica := range archive.Replicas {
+			replicated[archive.Name+"/"+replica.Destination] = replica.ReplicatedTime
+		}
+	}
+
+	byArchive := map[string][]backupv1alpha1.ArchiveReplicaStatus{}
+	pending := 0
+	for _, replica := range replicas {
+		status := backupv1alpha1.ArchiveReplicaStatus{
+			Destination: replica.Destination,
+			Phase:       backupv1alpha1.ArchiveReplicaReplicated,
+		}
+		if replica.Error != nil {
+			pending++
+			status.Phase = backupv1alpha1.ArchiveReplicaFailed
+			status.Message = replica.Error.Error()
+		} else if previous := replicated[replica.Archive+"/"+replica.Destination]; previous != nil {
+			status.ReplicatedTime = previous
+		} else {
+			status.ReplicatedTime = &now
+		}
+		byArchive[replica.Archive] = append(byArchive[replica.Archive], status)
+	}
+
+	catalog := make([]backupv1alpha1.ReplicatedArchive, 0, min(len(archives), maxCatalogedArchives))
+	for i := len(archives) - 1; i >= 0 && len(catalog) < maxCatalogedArchives; i-- {
+		catalog = append(catalog, backupv1alpha1.ReplicatedArchive{Name: archives[i], Replicas: byArchive[archives[i]]})
+	}
+	replication.Status.Archives = catalog
+	replication.Status.PendingArchives = pending
+
+	if pending > 0 {
+		backup.SetCondition(&replication.Status.Conditions, "Ready", metav1.ConditionFalse, "ReplicationFailed",
+			fmt.Sprintf("%d archive copies failed", pending))
+		return
+	}
+	backup.SetCondition(&replication.Status.Conditions, "Ready", metav1.ConditionTrue, "Replicated",
+		fmt.Sprintf("%d archives replicated to %d destinations", len(archives), len(replication.Spec.Destinations)))
+}
+
+// replicationsForBackup enqueues the ArchiveReplications reading from a
+// ClusterBackup once it completes, so new archives are copied without
+// waiting for the next interval.
+func (r *ArchiveReplicationReconciler) replicationsForBackup(ctx context.Context, obj client.Object) []reconcile.Request {
+	clusterBackup, ok := obj.(*backupv1alpha1.ClusterBackup)
+	if !ok || clusterBackup.Status.Phase != "Completed" {
+		return nil
+	}
+
+	var list backupv1alpha1.ArchiveReplicationList
+	if err := r.List(ctx, &list, client.InNamespace(clusterBackup.Namespace)); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list ArchiveReplications")
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, replication := range list.Items {
+		if replication.Spec.BackupName == clusterBackup.Name {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&replication)})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ArchiveReplicationReconciler) SetupWithManager(mgr ctrl.Manager, options Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		// Status updates must not start another pass
+		For(&backupv1alpha1.ArchiveReplication{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&backupv1alpha1.ClusterBackup{}, handler.EnqueueRequestsFromMapFunc(r.replicationsForBackup)).
+		Named("archivereplication").
+		WithOptions(options.forController("archivereplication")).
+		Complete(r)
+}
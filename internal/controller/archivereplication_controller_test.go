@@ -0,0 +1,94 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+var _ = Describe("ArchiveReplication Controller", func() {
+	ctx := context.Background()
+	typeNamespacedName := types.NamespacedName{Name: "test-replication", Namespace: "default"}
+
+	AfterEach(func() {
+		resource := &backupv1alpha1.ArchiveReplication{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+	})
+
+	It("should copy completed archives and catalog them in status", func() {
+		source := GinkgoT().TempDir()
+		destination := GinkgoT().TempDir()
+		archive := "cluster-backup-20250101-000000.tar.gz"
+		Expect(os.WriteFile(filepath.Join(source, archive), []byte("archive"), 0o644)).To(Succeed())
+
+		Expect(k8sClient.Create(ctx, &backupv1alpha1.ArchiveReplication{
+			ObjectMeta: metav1.ObjectMeta{Name: typeNamespacedName.Name, Namespace: typeNamespacedName.Namespace},
+			Spec: backupv1alpha1.ArchiveReplicationSpec{
+				SourceStoragePath: source,
+				Destinations:      []string{destination},
+				Interval:          &metav1.Duration{Duration: time.Minute},
+			},
+		})).To(Succeed())
+
+		reconciler := &ArchiveReplicationReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), BackupManager: &backup.BackupManager{}}
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(time.Minute))
+		Expect(filepath.Join(destination, archive)).To(BeAnExistingFile())
+
+		replication := &backupv1alpha1.ArchiveReplication{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, replication)).To(Succeed())
+		Expect(replication.Status.Archives).To(HaveLen(1))
+		Expect(replication.Status.Archives[0].Name).To(Equal(archive))
+		Expect(replication.Status.Archives[0].Replicas[0].Phase).To(Equal(backupv1alpha1.ArchiveReplicaReplicated))
+		Expect(meta.IsStatusConditionTrue(replication.Status.Conditions, "Ready")).To(BeTrue())
+	})
+
+	It("should report a missing ClusterBackup", func() {
+		Expect(k8sClient.Create(ctx, &backupv1alpha1.ArchiveReplication{
+			ObjectMeta: metav1.ObjectMeta{Name: typeNamespacedName.Name, Namespace: typeNamespacedName.Namespace},
+			Spec: backupv1alpha1.ArchiveReplicationSpec{
+				BackupName:   "missing",
+				Destinations: []string{GinkgoT().TempDir()},
+			},
+		})).To(Succeed())
+
+		reconciler := &ArchiveReplicationReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), BackupManager: &backup.BackupManager{}}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+
+		replication := &backupv1alpha1.ArchiveReplication{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, replication)).To(Succeed())
+		condition := meta.FindStatusCondition(replication.Status.Conditions, "Ready")
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("SourceUnavailable"))
+	})
+})
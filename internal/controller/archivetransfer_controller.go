@@ -0,0 +1,154 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+// ArchiveTransferReconciler reconciles an ArchiveTransfer object
+type ArchiveTransferReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	BackupManager *backup.BackupManager
+}
+
+// +kubebuilder:rbac:groups=backup.backup.io,resources=archivetransfers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=backup.backup.io,resources=archivetransfers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=backup.backup.io,resources=archivetransfers/finalizers,verbs=update
+
+// Reconcile copies or moves the archive named by an ArchiveTransfer once per
+// generation and records the outcome in status.
+func (r *ArchiveTransferReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	transfer := &backupv1alpha1.ArchiveTransfer{}
+	if err := r.Get(ctx, req.NamespacedName, transfer); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get ArchiveTransfer")
+		return ctrl.Result{}, err
+	}
+
+	// A transfer runs once per generation
+	if transfer.Status.ObservedGeneration == transfer.Generation && transferFinished(transfer.Status.Phase) {
+		return ctrl.Result{}, nil
+	}
+
+	source := transfer.Spec.SourceStoragePath
+	if source == "" {
+		var err error
+		if source, err = backupStoragePath(ctx, r.Client, transfer.Namespace, transfer.Spec.BackupName); err != nil {
+			return ctrl.Result{}, r.markFailed(ctx, transfer, "SourceNotResolved", err)
+		}
+	}
+	if err := backup.ValidateStoragePath(transfer.Spec.DestinationStoragePath); err != nil {
+		return ctrl.Result{}, r.markFailed(ctx, transfer, "InvalidDestination", err)
+	}
+
+	move := transfer.Spec.Mode == backupv1alpha1.TransferModeMove
+	now := metav1.Now()
+	transfer.Status = backupv1alpha1.ArchiveTransferStatus{
+		Phase:              backupv1alpha1.TransferPhaseInProgress,
+		ObservedGeneration: transfer.Generation,
+		StartTime:          &now,
+		SourceStoragePath:  source,
+		Message:            fmt.Sprintf("Transferring %s to %s", transfer.Spec.ArchiveName, transfer.Spec.DestinationStoragePath),
+		Conditions:         transfer.Status.Conditions,
+	}
+	backup.SetCondition(&transfer.Status.Conditions, "Transferred", metav1.ConditionUnknown, "TransferStarted", "Transfer has started")
+	if err := r.Status().Update(ctx, transfer); err != nil {
+		log.Error(err, "Failed to update status to InProgress")
+		return ctrl.Result{}, err
+	}
+
+	archivePath, err := r.BackupManager.TransferArchive(ctx, source, transfer.Spec.ArchiveName, transfer.Spec.DestinationStoragePath, move)
+	if errors.Is(err, backup.ErrArchiveInUse) {
+		// Nothing was copied yet; try again once the restore is done
+		transfer.Status.Message = fmt.Sprintf("Waiting for restores to release %s", transfer.Spec.ArchiveName)
+		if err := r.Status().Update(ctx, transfer); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: referencedArchivesRequeue}, nil
+	}
+	if err != nil {
+		return ctrl.Result{}, r.markFailed(ctx, transfer, "TransferFailed", err)
+	}
+
+	completed := metav1.Now()
+	transfer.Status.Phase = backupv1alpha1.TransferPhaseCompleted
+	transfer.Status.CompletionTime = &completed
+	transfer.Status.BackupLocation = archivePath
+	verb := "Copied"
+	if move {
+		verb = "Moved"
+	}
+	transfer.Status.Message = fmt.Sprintf("%s %s to %s", verb, transfer.Spec.ArchiveName, transfer.Spec.DestinationStoragePath)
+	backup.SetCondition(&transfer.Status.Conditions, "Transferred", metav1.ConditionTrue, "TransferCompleted", transfer.Status.Message)
+	if err := r.Status().Update(ctx, transfer); err != nil {
+		log.Error(err, "Failed to update status after transfer")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// markFailed moves the transfer into the Failed phase. It is not retried
+// until the spec changes.
+func (r *ArchiveTransferReconciler) markFailed(ctx context.Context, transfer *backupv1alpha1.ArchiveTransfer, reason string, err error) error {
+	log := logf.FromContext(ctx)
+	log.Error(err, "Transfer failed")
+
+	now := metav1.Now()
+	transfer.Status.Phase = backupv1alpha1.TransferPhaseFailed
+	transfer.Status.ObservedGeneration = transfer.Generation
+	transfer.Status.CompletionTime = &now
+	transfer.Status.Message = fmt.Sprintf("Transfer failed: %v", err)
+	backup.SetCondition(&transfer.Status.Conditions, "Transferred", metav1.ConditionFalse, reason, err.Error())
+
+	if statusErr := r.Status().Update(ctx, transfer); statusErr != nil {
+		log.Error(statusErr, "Failed to update status after transfer failure")
+		return statusErr
+	}
+	return nil
+}
+
+// transferFinished reports whether phase is terminal
+func transferFinished(phase backupv1alpha1.TransferPhase) bool {
+	return phase == backupv1alpha1.TransferPhaseCompleted || phase == backupv1alpha1.TransferPhaseFailed
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ArchiveTransferReconciler) SetupWithManager(mgr ctrl.Manager, options Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&backupv1alpha1.ArchiveTransfer{}).
+		Named("archivetransfer").
+		WithOptions(options.forController("archivetransfer")).
+		Complete(r)
+}
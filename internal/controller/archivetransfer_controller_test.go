@@ -0,0 +1,97 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+var _ = Describe("ArchiveTransfer Controller", func() {
+	ctx := context.Background()
+	typeNamespacedName := types.NamespacedName{Name: "test-transfer", Namespace: "default"}
+	const archive = "cluster-backup-20250101-000000.tar.gz"
+
+	AfterEach(func() {
+		resource := &backupv1alpha1.ArchiveTransfer{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+	})
+
+	It("should move an archive once per generation", func() {
+		source := GinkgoT().TempDir()
+		destination := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(source, archive), []byte("archive"), 0o644)).To(Succeed())
+
+		Expect(k8sClient.Create(ctx, &backupv1alpha1.ArchiveTransfer{
+			ObjectMeta: metav1.ObjectMeta{Name: typeNamespacedName.Name, Namespace: typeNamespacedName.Namespace},
+			Spec: backupv1alpha1.ArchiveTransferSpec{
+				ArchiveName:            archive,
+				SourceStoragePath:      source,
+				DestinationStoragePath: destination,
+				Mode:                   backupv1alpha1.TransferModeMove,
+			},
+		})).To(Succeed())
+
+		reconciler := &ArchiveTransferReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), BackupManager: &backup.BackupManager{}}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filepath.Join(destination, archive)).To(BeAnExistingFile())
+		Expect(filepath.Join(source, archive)).NotTo(BeAnExistingFile())
+
+		transfer := &backupv1alpha1.ArchiveTransfer{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, transfer)).To(Succeed())
+		Expect(transfer.Status.Phase).To(Equal(backupv1alpha1.TransferPhaseCompleted))
+		Expect(transfer.Status.BackupLocation).To(Equal(filepath.Join(destination, archive)))
+
+		By("not repeating a finished transfer")
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, typeNamespacedName, transfer)).To(Succeed())
+		Expect(transfer.Status.Phase).To(Equal(backupv1alpha1.TransferPhaseCompleted))
+	})
+
+	It("should fail when the archive is missing", func() {
+		Expect(k8sClient.Create(ctx, &backupv1alpha1.ArchiveTransfer{
+			ObjectMeta: metav1.ObjectMeta{Name: typeNamespacedName.Name, Namespace: typeNamespacedName.Namespace},
+			Spec: backupv1alpha1.ArchiveTransferSpec{
+				ArchiveName:            archive,
+				SourceStoragePath:      GinkgoT().TempDir(),
+				DestinationStoragePath: GinkgoT().TempDir(),
+			},
+		})).To(Succeed())
+
+		reconciler := &ArchiveTransferReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), BackupManager: &backup.BackupManager{}}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+
+		transfer := &backupv1alpha1.ArchiveTransfer{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, transfer)).To(Succeed())
+		Expect(transfer.Status.Phase).To(Equal(backupv1alpha1.TransferPhaseFailed))
+		Expect(transfer.Status.Message).To(ContainSubstring("failed to find archive"))
+	})
+})
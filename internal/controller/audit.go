@@ -0,0 +1,66 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+// auditResource returns the resource of an audit record for obj.
+func auditResource(kind string, obj client.Object) string {
+	return fmt.Sprintf("%s/%s/%s", kind, obj.GetNamespace(), obj.GetName())
+}
+
+// recordAudit appends record to the audit log of every storage location,
+// publishes it as a CloudEvent and, when configured, emits it as an event on
+// obj. Failures are logged and never fail the audited operation.
+func recordAudit(ctx context.Context, bm *backup.BackupManager, recorder record.EventRecorder, config *backupv1alpha1.BackupOperatorConfigSpec,
+	obj client.Object, locations []string, record backup.AuditRecord) {
+	log := logf.FromContext(ctx)
+	record.Time = time.Now().UTC()
+	for _, location := range locations {
+		if location == "" {
+			continue
+		}
+		if err := bm.AppendAuditRecord(location, record); err != nil {
+			log.Error(err, "Failed to record audit entry", "storagePath", location, "operation", record.Operation)
+		}
+	}
+	publishCloudEvent(ctx, config, record)
+
+	if config == nil || config.Audit == nil || !config.Audit.Events {
+		return
+	}
+	message := fmt.Sprintf("%s %s", record.Operation, record.Result)
+	if record.Archive != "" {
+		message += " for archive " + record.Archive
+	}
+	if record.Message != "" {
+		message += ": " + record.Message
+	}
+	recordRunEvent(recorder, obj, record.RunID, corev1.EventTypeNormal, "Audited", "%s", message)
+}
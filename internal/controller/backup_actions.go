@@ -0,0 +1,141 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+)
+
+const (
+	// clusterBackupLabel and backupActionLabel identify the Jobs of backup
+	// actions.
+	clusterBackupLabel = "backup.backup.io/cluster-backup"
+	backupActionLabel  = "backup.backup.io/action"
+
+	// defaultActionTimeout bounds actions without a timeout.
+	defaultActionTimeout = 10 * time.Minute
+)
+
+// actionPollInterval is how often the Job of a running action is checked.
+var actionPollInterval = 2 * time.Second
+
+// runBackupActions runs the stage ("pre" or "post") actions of clusterBackup
+// one after another. It stops at the first action that fails with the Fail
+// policy; failures of the others are reported in events.
+func (r *ClusterBackupReconciler) runBackupActions(ctx context.Context, clusterBackup *backupv1alpha1.ClusterBackup, stage string, actions []backupv1alpha1.BackupJobAction) error {
+	log := logf.FromContext(ctx)
+	runID := clusterBackup.Status.LastRunID
+	for _, action := range actions {
+		log.Info("Running backup action", "stage", stage, "action", action.Name)
+		err := r.runBackupAction(ctx, clusterBackup, stage, action)
+		if err == nil {
+			continue
+		}
+		if action.FailurePolicy == "Ignore" {
+			log.Error(err, "Backup action failed, continuing", "stage", stage, "action", action.Name)
+			recordRunEvent(r.Recorder, clusterBackup, runID, corev1.EventTypeWarning, "BackupActionFailed",
+				"The %s-backup action %s of run %s failed: %v", stage, action.Name, runID, err)
+			continue
+		}
+		return fmt.Errorf("%s-backup action %q failed: %w", stage, action.Name, err)
+	}
+	return nil
+}
+
+// runBackupAction creates the Job of action for the current run, unless a
+// run picked up again already created it, and waits for it to finish.
+func (r *ClusterBackupReconciler) runBackupAction(ctx context.Context, clusterBackup *backupv1alpha1.ClusterBackup, stage string, action backupv1alpha1.BackupJobAction) error {
+	job := backupActionJob(clusterBackup, stage, action)
+	if err := controllerutil.SetControllerReference(clusterBackup, job, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.Create(ctx, job); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create Job: %w", err)
+	}
+
+	timeout := defaultActionTimeout
+	if action.Timeout != nil {
+		timeout = action.Timeout.Duration
+	}
+	err := wait.PollUntilContextTimeout(ctx, actionPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		if err := r.Get(ctx, client.ObjectKeyFromObject(job), job); err != nil {
+			return false, fmt.Errorf("failed to get Job %s: %w", job.Name, err)
+		}
+		for _, condition := range job.Status.Conditions {
+			if condition.Status != corev1.ConditionTrue {
+				continue
+			}
+			switch condition.Type {
+			case batchv1.JobComplete:
+				return true, nil
+			case batchv1.JobFailed:
+				return false, fmt.Errorf("job %s failed: %s", job.Name, condition.Message)
+			}
+		}
+		return false, nil
+	})
+	if wait.Interrupted(err) && ctx.Err() == nil {
+		return fmt.Errorf("job %s did not complete within %s", job.Name, timeout)
+	}
+	return err
+}
+
+// backupActionJob returns the Job running action in the current run of
+// clusterBackup. Its name is derived from the run, so it is created once
+// per run.
+func backupActionJob(clusterBackup *backupv1alpha1.ClusterBackup, stage string, action backupv1alpha1.BackupJobAction) *batchv1.Job {
+	runID := clusterBackup.Status.LastRunID
+	if len(runID) > 8 {
+		runID = runID[:8]
+	}
+	suffix := fmt.Sprintf("-%s-%s-%s", stage, action.Name, runID)
+	prefix := clusterBackup.Name
+	if maxPrefix := 63 - len(suffix); len(prefix) > maxPrefix {
+		prefix = strings.TrimRight(prefix[:maxPrefix], "-.")
+	}
+
+	spec := *action.JobSpec.DeepCopy()
+	if spec.Template.Spec.RestartPolicy == "" {
+		spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+	}
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      prefix + suffix,
+			Namespace: clusterBackup.Namespace,
+			Labels: map[string]string{
+				clusterBackupLabel: clusterBackup.Name,
+				backupActionLabel:  action.Name,
+			},
+			Annotations: map[string]string{runIDAnnotation: clusterBackup.Status.LastRunID},
+		},
+		Spec: spec,
+	}
+}
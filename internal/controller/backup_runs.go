@@ -0,0 +1,134 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+)
+
+// +kubebuilder:rbac:groups=backup.backup.io,resources=backupruns,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=backup.backup.io,resources=backupruns/status,verbs=get;update;patch
+
+// backupRunName names the BackupRun of run runID of the ClusterBackup
+// backupName.
+func backupRunName(backupName, runID string) string {
+	if len(runID) > 8 {
+		runID = runID[:8]
+	}
+	suffix := "-" + runID
+	if maxPrefix := validation.DNS1123SubdomainMaxLength - len(suffix); len(backupName) > maxPrefix {
+		backupName = strings.TrimRight(backupName[:maxPrefix], "-.")
+	}
+	return backupName + suffix
+}
+
+// saveBackupRun records status in the BackupRun of the current run of
+// clusterBackup, creating it owned by clusterBackup if needed. Failures are
+// logged; they never fail the backup.
+func (r *ClusterBackupReconciler) saveBackupRun(ctx context.Context, clusterBackup *backupv1alpha1.ClusterBackup, status backupv1alpha1.BackupRunStatus) {
+	log := logf.FromContext(ctx)
+	key := types.NamespacedName{
+		Namespace: clusterBackup.Namespace,
+		Name:      backupRunName(clusterBackup.Name, clusterBackup.Status.LastRunID),
+	}
+
+	run := &backupv1alpha1.BackupRun{}
+	err := r.Get(ctx, key, run)
+	if apierrors.IsNotFound(err) {
+		run = &backupv1alpha1.BackupRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      key.Name,
+				Namespace: key.Namespace,
+				Labels:    map[string]string{clusterBackupLabel: clusterBackup.Name},
+			},
+			Spec: backupv1alpha1.BackupRunSpec{
+				BackupName: clusterBackup.Name,
+				RunID:      clusterBackup.Status.LastRunID,
+				Trigger:    clusterBackup.Status.LastRunTrigger,
+			},
+		}
+		if err = controllerutil.SetControllerReference(clusterBackup, run, r.Scheme); err == nil {
+			err = r.Create(ctx, run)
+		}
+	}
+	if err != nil {
+		log.Error(err, "Failed to record BackupRun", "backupRun", key.Name)
+		return
+	}
+
+	status.Logs = operatorLogs()
+	run.Status = status
+	if err := r.Status().Update(ctx, run); err != nil {
+		log.Error(err, "Failed to update BackupRun status", "backupRun", key.Name)
+	}
+}
+
+// finishBackupRun records the outcome of the current run in its BackupRun
+// and deletes the oldest finished BackupRuns of clusterBackup beyond its
+// history limit, like the Jobs of a CronJob.
+func (r *ClusterBackupReconciler) finishBackupRun(ctx context.Context, clusterBackup *backupv1alpha1.ClusterBackup, status backupv1alpha1.BackupRunStatus) {
+	log := logf.FromContext(ctx)
+	r.saveBackupRun(ctx, clusterBackup, status)
+
+	runs := &backupv1alpha1.BackupRunList{}
+	if err := r.List(ctx, runs, client.InNamespace(clusterBackup.Namespace),
+		client.MatchingLabels{clusterBackupLabel: clusterBackup.Name}); err != nil {
+		log.Error(err, "Failed to list BackupRuns")
+		return
+	}
+	var finished []backupv1alpha1.BackupRun
+	for _, run := range runs.Items {
+		if run.Spec.BackupName == clusterBackup.Name && run.Status.Phase != backupv1alpha1.BackupRunPhaseRunning {
+			finished = append(finished, run)
+		}
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		if !finished[i].CreationTimestamp.Equal(&finished[j].CreationTimestamp) {
+			return finished[j].CreationTimestamp.Before(&finished[i].CreationTimestamp)
+		}
+		return finished[i].Name > finished[j].Name
+	})
+	for i := historyLimit(clusterBackup); i < len(finished); i++ {
+		run := &finished[i]
+		if err := r.Delete(ctx, run); client.IgnoreNotFound(err) != nil {
+			log.Error(err, "Failed to delete BackupRun", "backupRun", run.Name)
+		}
+	}
+}
+
+// operatorLogs locates the logs of this operator pod, whose hostname is the
+// pod name.
+func operatorLogs() *backupv1alpha1.BackupRunLogs {
+	pod, err := os.Hostname()
+	if err != nil {
+		return nil
+	}
+	return &backupv1alpha1.BackupRunLogs{Pod: pod, Namespace: os.Getenv("POD_NAMESPACE")}
+}
@@ -0,0 +1,245 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+// BackupOperatorConfigReconciler applies the operator-wide settings of the
+// BackupOperatorConfig that cannot be read per reconcile, such as the client
+// rate limits, and reports whether the configuration is valid.
+type BackupOperatorConfigReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	BackupManager *backup.BackupManager
+}
+
+// +kubebuilder:rbac:groups=backup.backup.io,resources=backupoperatorconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=backup.backup.io,resources=backupoperatorconfigs/status,verbs=get;update;patch
+
+// Reconcile applies the BackupOperatorConfig named default.
+func (r *BackupOperatorConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	config := &backupv1alpha1.BackupOperatorConfig{}
+	if err := r.Get(ctx, req.NamespacedName, config); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Fall back to the limits the operator was started with
+			if r.BackupManager.SetClientRateLimits(0, 0) {
+				log.Info("Restored the default client rate limits")
+			}
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	condition := metav1.Condition{
+		Type:               "Ready",
+		Status:             metav1.ConditionTrue,
+		Reason:             "Applied",
+		Message:            "Configuration applied",
+		ObservedGeneration: config.Generation,
+	}
+	if err := validateOperatorConfig(&config.Spec); err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "InvalidConfiguration"
+		condition.Message = err.Error()
+	}
+
+	var qps float32
+	var burst int
+	if limits := config.Spec.Client; limits != nil {
+		if limits.QPS != nil {
+			qps = float32(*limits.QPS)
+		}
+		if limits.Burst != nil {
+			burst = int(*limits.Burst)
+		}
+	}
+	if r.BackupManager.SetClientRateLimits(qps, burst) {
+		log.Info("Updated client rate limits", "qps", qps, "burst", burst)
+	}
+
+	config.Status.ObservedGeneration = config.Generation
+	backup.SetCondition(&config.Status.Conditions, condition.Type, condition.Status, condition.Reason, condition.Message)
+	if err := r.Status().Update(ctx, config); err != nil {
+		log.Error(err, "Failed to update BackupOperatorConfig status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// validateOperatorConfig checks the settings that the CRD schema cannot.
+func validateOperatorConfig(spec *backupv1alpha1.BackupOperatorConfigSpec) error {
+	if spec.DefaultStoragePath != "" {
+		if err := backup.ValidateStoragePath(spec.DefaultStoragePath); err != nil {
+			return fmt.Errorf("invalid defaultStoragePath: %w", err)
+		}
+	}
+	if err := backup.ValidateNamespacePatterns(spec.ExcludeNamespacePatterns); err != nil {
+		return fmt.Errorf("invalid excludeNamespacePatterns: %w", err)
+	}
+	for i, sink := range spec.Notifications {
+		if err := backup.ValidateNotificationTarget(sink.Provider, sink.Target); err != nil {
+			return fmt.Errorf("invalid notifications[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BackupOperatorConfigReconciler) SetupWithManager(mgr ctrl.Manager, options Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&backupv1alpha1.BackupOperatorConfig{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			return obj.GetName() == backupv1alpha1.BackupOperatorConfigName
+		}))).
+		Named("backupoperatorconfig").
+		WithOptions(options.forController("backupoperatorconfig")).
+		Complete(r)
+}
+
+// loadOperatorConfig returns the operator-wide defaults, which are empty
+// when no BackupOperatorConfig exists.
+func loadOperatorConfig(ctx context.Context, c client.Reader) (*backupv1alpha1.BackupOperatorConfigSpec, error) {
+	config := &backupv1alpha1.BackupOperatorConfig{}
+	err := c.Get(ctx, client.ObjectKey{Name: backupv1alpha1.BackupOperatorConfigName}, config)
+	if apierrors.IsNotFound(err) {
+		return &backupv1alpha1.BackupOperatorConfigSpec{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get BackupOperatorConfig: %w", err)
+	}
+	return &config.Spec, nil
+}
+
+// storagePathFor returns the storage location of clusterBackup, falling back
+// to the operator default. It is empty when neither is set or the template
+// cannot be expanded; storagePathError tells why.
+func storagePathFor(clusterBackup *backupv1alpha1.ClusterBackup, config *backupv1alpha1.BackupOperatorConfigSpec) string {
+	storagePath := clusterBackup.Spec.StoragePath
+	if storagePath == "" {
+		storagePath = config.DefaultStoragePath
+	}
+	expanded, err := backup.ExpandStoragePath(storagePath, storagePathVars(clusterBackup, config))
+	if err != nil {
+		return ""
+	}
+	return expanded
+}
+
+// replicaStoragePathsFor returns the expanded replica storage locations of
+// clusterBackup, leaving out those that cannot be expanded.
+func replicaStoragePathsFor(clusterBackup *backupv1alpha1.ClusterBackup, config *backupv1alpha1.BackupOperatorConfigSpec) []string {
+	var replicas []string
+	for _, replica := range clusterBackup.Spec.ReplicaStoragePaths {
+		if expanded, err := backup.ExpandStoragePath(replica, storagePathVars(clusterBackup, config)); err == nil {
+			replicas = append(replicas, expanded)
+		}
+	}
+	return replicas
+}
+
+// coldStoragePathFor returns the expanded cold storage location of
+// clusterBackup, or "" without tiering.
+func coldStoragePathFor(clusterBackup *backupv1alpha1.ClusterBackup, config *backupv1alpha1.BackupOperatorConfigSpec) string {
+	if clusterBackup.Spec.Tiering == nil {
+		return ""
+	}
+	expanded, err := backup.ExpandStoragePath(clusterBackup.Spec.Tiering.ColdStoragePath, storagePathVars(clusterBackup, config))
+	if err != nil {
+		return ""
+	}
+	return expanded
+}
+
+// storagePathError reports why a storage location of clusterBackup cannot
+// be used.
+func storagePathError(clusterBackup *backupv1alpha1.ClusterBackup, config *backupv1alpha1.BackupOperatorConfigSpec) error {
+	storagePath := clusterBackup.Spec.StoragePath
+	if storagePath == "" {
+		storagePath = config.DefaultStoragePath
+	}
+	if storagePath == "" {
+		return fmt.Errorf("storagePath is not set and the BackupOperatorConfig has no defaultStoragePath")
+	}
+
+	paths := append([]string{storagePath}, clusterBackup.Spec.ReplicaStoragePaths...)
+	if tiering := clusterBackup.Spec.Tiering; tiering != nil {
+		paths = append(paths, tiering.ColdStoragePath)
+	}
+	vars := storagePathVars(clusterBackup, config)
+	for _, path := range paths {
+		if _, err := backup.ExpandStoragePath(path, vars); err != nil {
+			if vars.ClusterName == "" && strings.Contains(path, ".ClusterName") {
+				return fmt.Errorf("%w; set clusterName in the BackupOperatorConfig or the %s label", err, backupv1alpha1.ClusterNameLabel)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// storagePathVars returns the template variables of the storage locations
+// of clusterBackup.
+func storagePathVars(clusterBackup *backupv1alpha1.ClusterBackup, config *backupv1alpha1.BackupOperatorConfigSpec) backup.StoragePathVars {
+	clusterName := clusterBackup.Labels[backupv1alpha1.ClusterNameLabel]
+	if clusterName == "" {
+		clusterName = config.ClusterName
+	}
+	return backup.StoragePathVars{
+		ClusterName: clusterName,
+		Namespace:   clusterBackup.Namespace,
+		BackupName:  clusterBackup.Name,
+	}
+}
+
+// degradedThresholdFor returns the degraded failure threshold set in config,
+// or fallback.
+func degradedThresholdFor(config *backupv1alpha1.BackupOperatorConfigSpec, fallback int) int {
+	if config.Metrics == nil || config.Metrics.DegradedFailureThreshold == nil || *config.Metrics.DegradedFailureThreshold < 1 {
+		return fallback
+	}
+	return int(*config.Metrics.DegradedFailureThreshold)
+}
+
+// staleThresholdFor returns the stale threshold set in config, or fallback.
+func staleThresholdFor(config *backupv1alpha1.BackupOperatorConfigSpec, fallback float64) float64 {
+	if config.Metrics == nil || config.Metrics.StaleBackupThreshold == "" {
+		return fallback
+	}
+	threshold, err := strconv.ParseFloat(config.Metrics.StaleBackupThreshold, 64)
+	if err != nil || threshold <= 0 {
+		return fallback
+	}
+	return threshold
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+var _ = Describe("BackupOperatorConfig Controller", func() {
+	ctx := context.Background()
+	configName := types.NamespacedName{Name: backupv1alpha1.BackupOperatorConfigName}
+
+	AfterEach(func() {
+		config := &backupv1alpha1.BackupOperatorConfig{}
+		if err := k8sClient.Get(ctx, configName, config); err == nil {
+			Expect(k8sClient.Delete(ctx, config)).To(Succeed())
+		}
+	})
+
+	It("should reject configurations with another name", func() {
+		Expect(k8sClient.Create(ctx, &backupv1alpha1.BackupOperatorConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "other"},
+		})).NotTo(Succeed())
+	})
+
+	It("should reject an invalid default storage path", func() {
+		err := k8sClient.Create(ctx, &backupv1alpha1.BackupOperatorConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: configName.Name},
+			Spec:       backupv1alpha1.BackupOperatorConfigSpec{DefaultStoragePath: "s3://bucket/path"},
+		})
+		Expect(err).To(MatchError(ContainSubstring("must be an absolute path or a host:// URI")))
+	})
+
+	It("should report an invalid notification target", func() {
+		Expect(k8sClient.Create(ctx, &backupv1alpha1.BackupOperatorConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: configName.Name},
+			Spec: backupv1alpha1.BackupOperatorConfigSpec{Notifications: []backupv1alpha1.NotificationSink{
+				{Provider: backup.NotificationProviderSNS, Target: "backup-events"},
+			}},
+		})).To(Succeed())
+
+		reconciler := &BackupOperatorConfigReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), BackupManager: &backup.BackupManager{}}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: configName})
+		Expect(err).NotTo(HaveOccurred())
+
+		config := &backupv1alpha1.BackupOperatorConfig{}
+		Expect(k8sClient.Get(ctx, configName, config)).To(Succeed())
+		condition := meta.FindStatusCondition(config.Status.Conditions, "Ready")
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Message).To(ContainSubstring("invalid SNS topic ARN"))
+	})
+
+	It("should supply defaults to ClusterBackups that leave them unset", func() {
+		Expect(k8sClient.Create(ctx, &backupv1alpha1.BackupOperatorConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: configName.Name},
+			Spec: backupv1alpha1.BackupOperatorConfigSpec{
+				DefaultStoragePath: "/var/lib/backups",
+				Metrics:            &backupv1alpha1.MetricsOptions{StaleBackupThreshold: "3"},
+				Client:             &backupv1alpha1.ClientRateLimits{QPS: ptr.To[int32](50)},
+			},
+		})).To(Succeed())
+
+		config, err := loadOperatorConfig(ctx, k8sClient)
+		Expect(err).NotTo(HaveOccurred())
+
+		clusterBackup := &backupv1alpha1.ClusterBackup{}
+		Expect(storagePathFor(clusterBackup, config)).To(Equal("/var/lib/backups"))
+		clusterBackup.Spec.StoragePath = "/srv/backups"
+		Expect(storagePathFor(clusterBackup, config)).To(Equal("/srv/backups"))
+		Expect(staleThresholdFor(config, defaultStaleThreshold)).To(Equal(3.0))
+	})
+
+	It("should fall back to empty defaults without a configuration", func() {
+		config, err := loadOperatorConfig(ctx, k8sClient)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(config.DefaultStoragePath).To(BeEmpty())
+		Expect(staleThresholdFor(config, defaultStaleThreshold)).To(Equal(defaultStaleThreshold))
+	})
+})
@@ -0,0 +1,161 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+// BackupPolicyReconciler reports whether a BackupPolicy is usable. The
+// policy itself is applied by the ClusterBackup controller on every run.
+type BackupPolicyReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=backup.backup.io,resources=backuppolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=backup.backup.io,resources=backuppolicies/status,verbs=get;update;patch
+
+// Reconcile validates a BackupPolicy and sets its Ready condition.
+func (r *BackupPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	policy := &backupv1alpha1.BackupPolicy{}
+	if err := r.Get(ctx, req.NamespacedName, policy); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	condition := metav1.Condition{
+		Type:    "Ready",
+		Status:  metav1.ConditionTrue,
+		Reason:  "Valid",
+		Message: "Policy is valid",
+	}
+	if err := validateBackupPolicy(&policy.Spec); err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "InvalidPolicy"
+		condition.Message = err.Error()
+	}
+
+	policy.Status.ObservedGeneration = policy.Generation
+	backup.SetCondition(&policy.Status.Conditions, condition.Type, condition.Status, condition.Reason, condition.Message)
+	if err := r.Status().Update(ctx, policy); err != nil {
+		log.Error(err, "Failed to update BackupPolicy status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// validateBackupPolicy checks the settings that the CRD schema cannot.
+func validateBackupPolicy(spec *backupv1alpha1.BackupPolicySpec) error {
+	if spec.Encryption != nil && len(spec.Encryption.AgeRecipients) > 0 {
+		if _, err := backup.NewAgeKeyWrappers(spec.Encryption.AgeRecipients); err != nil {
+			return fmt.Errorf("invalid encryption.ageRecipients: %w", err)
+		}
+	}
+	return nil
+}
+
+// applyBackupPolicy fills the settings clusterBackup leaves unset from the
+// BackupPolicy it references. The merged spec only lives in memory for the
+// current run: it is never written back, and the next status update
+// replaces it with the stored spec.
+func applyBackupPolicy(ctx context.Context, c client.Reader, clusterBackup *backupv1alpha1.ClusterBackup) error {
+	if clusterBackup.Spec.PolicyName == "" {
+		return nil
+	}
+	policy := &backupv1alpha1.BackupPolicy{}
+	key := types.NamespacedName{Namespace: clusterBackup.Namespace, Name: clusterBackup.Spec.PolicyName}
+	if err := c.Get(ctx, key, policy); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("BackupPolicy %q not found", clusterBackup.Spec.PolicyName)
+		}
+		return fmt.Errorf("failed to get BackupPolicy %q: %w", clusterBackup.Spec.PolicyName, err)
+	}
+	if err := validateBackupPolicy(&policy.Spec); err != nil {
+		return fmt.Errorf("BackupPolicy %q is invalid: %w", policy.Name, err)
+	}
+	mergeBackupPolicy(&clusterBackup.Spec, &policy.Spec)
+	return nil
+}
+
+// mergeBackupPolicy copies the policy settings spec leaves unset. Excluded
+// namespaces are combined and switches are enabled by either side.
+func mergeBackupPolicy(spec *backupv1alpha1.ClusterBackupSpec, policy *backupv1alpha1.BackupPolicySpec) {
+	if len(spec.IncludeNamespaces) == 0 {
+		spec.IncludeNamespaces = policy.IncludeNamespaces
+	}
+	for _, namespace := range policy.ExcludeNamespaces {
+		if !slices.Contains(spec.ExcludeNamespaces, namespace) {
+			spec.ExcludeNamespaces = append(spec.ExcludeNamespaces, namespace)
+		}
+	}
+	if len(spec.ResourceTypes) == 0 {
+		spec.ResourceTypes = policy.ResourceTypes
+	}
+	spec.ExcludeGitOpsManaged = spec.ExcludeGitOpsManaged || policy.ExcludeGitOpsManaged
+	spec.IncludeGeneratedResources = spec.IncludeGeneratedResources || policy.IncludeGeneratedResources
+	spec.IncludeFinishedWorkloads = spec.IncludeFinishedWorkloads || policy.IncludeFinishedWorkloads
+	spec.SkipReissuableCertificateSecrets = spec.SkipReissuableCertificateSecrets || policy.SkipReissuableCertificateSecrets
+	spec.IncludeReferencedResources = spec.IncludeReferencedResources || policy.IncludeReferencedResources
+	if spec.Concurrency == nil {
+		spec.Concurrency = policy.Concurrency
+	}
+	if spec.Pacing == nil {
+		spec.Pacing = policy.Pacing
+	}
+	if spec.Encryption == nil {
+		spec.Encryption = policy.Encryption
+	}
+	if spec.RetentionDays == nil {
+		spec.RetentionDays = policy.RetentionDays
+	}
+	// Count-based retention comes from one side only, since rotation and
+	// maxArchives are exclusive
+	if spec.MaxArchives == nil && spec.Rotation == nil {
+		spec.MaxArchives = policy.MaxArchives
+		spec.Rotation = policy.Rotation
+	}
+	if spec.MaxTotalSize == nil {
+		spec.MaxTotalSize = policy.MaxTotalSize
+	}
+	if spec.Immutability == nil {
+		spec.Immutability = policy.Immutability
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BackupPolicyReconciler) SetupWithManager(mgr ctrl.Manager, options Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&backupv1alpha1.BackupPolicy{}).
+		Named("backuppolicy").
+		WithOptions(options.forController("backuppolicy")).
+		Complete(r)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+)
+
+var _ = Describe("BackupPolicy Controller", func() {
+	ctx := context.Background()
+	typeNamespacedName := types.NamespacedName{Name: "test-policy", Namespace: "default"}
+
+	AfterEach(func() {
+		resource := &backupv1alpha1.BackupPolicy{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+	})
+
+	It("should report invalid age recipients", func() {
+		Expect(k8sClient.Create(ctx, &backupv1alpha1.BackupPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: typeNamespacedName.Name, Namespace: typeNamespacedName.Namespace},
+			Spec: backupv1alpha1.BackupPolicySpec{
+				Encryption: &backupv1alpha1.BackupEncryption{AgeRecipients: []string{"age1invalid"}},
+			},
+		})).To(Succeed())
+
+		reconciler := &BackupPolicyReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+
+		policy := &backupv1alpha1.BackupPolicy{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, policy)).To(Succeed())
+		ready := metav1.Condition{}
+		for _, condition := range policy.Status.Conditions {
+			if condition.Type == "Ready" {
+				ready = condition
+			}
+		}
+		Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+		Expect(ready.Reason).To(Equal("InvalidPolicy"))
+	})
+
+	It("should fill unset ClusterBackup settings from the policy", func() {
+		Expect(k8sClient.Create(ctx, &backupv1alpha1.BackupPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: typeNamespacedName.Name, Namespace: typeNamespacedName.Namespace},
+			Spec: backupv1alpha1.BackupPolicySpec{
+				ExcludeNamespaces:    []string{"kube-system"},
+				ResourceTypes:        []string{"configmaps"},
+				ExcludeGitOpsManaged: true,
+				RetentionDays:        ptr.To(30),
+				MaxArchives:          ptr.To(10),
+			},
+		})).To(Succeed())
+
+		clusterBackup := &backupv1alpha1.ClusterBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: "uses-policy", Namespace: typeNamespacedName.Namespace},
+			Spec: backupv1alpha1.ClusterBackupSpec{
+				PolicyName:        typeNamespacedName.Name,
+				ExcludeNamespaces: []string{"scratch"},
+				MaxArchives:       ptr.To(3),
+			},
+		}
+		Expect(applyBackupPolicy(ctx, k8sClient, clusterBackup)).To(Succeed())
+		Expect(clusterBackup.Spec.ExcludeNamespaces).To(Equal([]string{"scratch", "kube-system"}))
+		Expect(clusterBackup.Spec.ResourceTypes).To(Equal([]string{"configmaps"}))
+		Expect(clusterBackup.Spec.ExcludeGitOpsManaged).To(BeTrue())
+		Expect(clusterBackup.Spec.RetentionDays).To(Equal(ptr.To(30)))
+		Expect(clusterBackup.Spec.MaxArchives).To(Equal(ptr.To(3)))
+
+		By("failing when the policy does not exist")
+		clusterBackup.Spec.PolicyName = "missing"
+		Expect(applyBackupPolicy(ctx, k8sClient, clusterBackup)).NotTo(Succeed())
+	})
+})
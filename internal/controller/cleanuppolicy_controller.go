@@ -0,0 +1,199 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+// defaultCleanupInterval applies when spec.interval is unset.
+const defaultCleanupInterval = time.Hour
+
+// CleanupPolicyReconciler enforces retention on storage locations on its own
+// schedule, independently of the backups writing to them
+type CleanupPolicyReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	BackupManager *backup.BackupManager
+
+	// Recorder, when set, receives the audit events of removed archives.
+	Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=backup.backup.io,resources=cleanuppolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=backup.backup.io,resources=cleanuppolicies/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=backup.backup.io,resources=cleanuppolicies/finalizers,verbs=update
+
+// Reconcile removes the archives the policy does not keep from every storage
+// location once per interval and records the outcome in status.
+func (r *CleanupPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	policy := &backupv1alpha1.CleanupPolicy{}
+	if err := r.Get(ctx, req.NamespacedName, policy); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get CleanupPolicy")
+		return ctrl.Result{}, err
+	}
+
+	interval := defaultCleanupInterval
+	if policy.Spec.Interval != nil && policy.Spec.Interval.Duration > 0 {
+		interval = policy.Spec.Interval.Duration
+	}
+	// Spec changes and locations skipped while a backup held them are
+	// enforced right away, anything else waits for the interval
+	ready := meta.FindStatusCondition(policy.Status.Conditions, "Ready")
+	deferred := ready != nil && ready.Reason == "StorageBusy"
+	if last := policy.Status.LastCleanupTime; last != nil && policy.Status.ObservedGeneration == policy.Generation && !deferred {
+		if wait := time.Until(last.Add(interval)); wait > 0 {
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+	}
+
+	config, err := loadOperatorConfig(ctx, r.Client)
+	if err != nil {
+		log.Error(err, "Failed to load operator configuration")
+		return ctrl.Result{}, err
+	}
+
+	now := metav1.Now()
+	policy.Status.ObservedGeneration = policy.Generation
+	policy.Status.LastCleanupTime = &now
+
+	requeueAfter := interval
+	locations, err := r.cleanupLocations(ctx, policy, config)
+	if err != nil {
+		log.Error(err, "Failed to resolve storage locations")
+		backup.SetCondition(&policy.Status.Conditions, "Ready", metav1.ConditionFalse, "SourceUnavailable", err.Error())
+	} else if busy := r.cleanup(ctx, policy, config, locations); busy {
+		requeueAfter = min(interval, storageLockRetryInterval)
+	}
+
+	if err := r.Status().Update(ctx, policy); err != nil {
+		log.Error(err, "Failed to update CleanupPolicy status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// cleanupLocations returns the storage locations policy cleans up.
+func (r *CleanupPolicyReconciler) cleanupLocations(ctx context.Context, policy *backupv1alpha1.CleanupPolicy, config *backupv1alpha1.BackupOperatorConfigSpec) ([]string, error) {
+	if len(policy.Spec.StoragePaths) > 0 {
+		return policy.Spec.StoragePaths, nil
+	}
+
+	clusterBackup := &backupv1alpha1.ClusterBackup{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: policy.Namespace, Name: policy.Spec.BackupName}, clusterBackup); err != nil {
+		return nil, fmt.Errorf("failed to get ClusterBackup %q: %w", policy.Spec.BackupName, err)
+	}
+	locations := storageLocationsFor(clusterBackup, config)
+	if len(locations) == 0 {
+		return nil, fmt.Errorf("ClusterBackup %q has no storage location", policy.Spec.BackupName)
+	}
+	return locations, nil
+}
+
+// cleanup enforces the retention of policy on every location, audits the
+// removed archives and sets the status. A location that fails does not stop
+// the others, and one a backup run holds is skipped; cleanup reports whether
+// any was.
+func (r *CleanupPolicyReconciler) cleanup(ctx context.Context, policy *backupv1alpha1.CleanupPolicy, config *backupv1alpha1.BackupOperatorConfigSpec, locations []string) bool {
+	log := logf.FromContext(ctx)
+	retention := archiveRetention{
+		retentionDays: policy.Spec.RetentionDays,
+		maxArchives:   policy.Spec.MaxArchives,
+		rotation:      policy.Spec.Rotation,
+		maxTotalSize:  policy.Spec.MaxTotalSize,
+	}
+
+	var removedArchives, lockedArchives []string
+	var errs, busy []error
+	for _, location := range locations {
+		release, err := r.BackupManager.LockStorage(storageLockHolder("CleanupPolicy", policy), location)
+		if err != nil {
+			busy = append(busy, err)
+			continue
+		}
+		enforceRetention(r.BackupManager, location, retention, func(removed []string, err error) {
+			for _, archive := range removed {
+				recordAudit(ctx, r.BackupManager, r.Recorder, config, policy, []string{location}, backup.AuditRecord{
+					Operation: backup.AuditOperationDelete, Resource: auditResource("CleanupPolicy", policy),
+					Archive: archive, Result: "success",
+				})
+			}
+			removedArchives = append(removedArchives, removed...)
+			var immutable *backup.ImmutableArchivesError
+			if errors.As(err, &immutable) {
+				lockedArchives = append(lockedArchives, immutable.Archives...)
+			} else if err != nil {
+				log.Error(err, "Failed to clean up archives", "storagePath", location)
+				errs = append(errs, fmt.Errorf("%s: %w", location, err))
+			}
+		})
+		release()
+	}
+	if len(removedArchives) > 0 {
+		log.Info("Removed archives", "archives", removedArchives)
+	}
+
+	slices.Sort(lockedArchives)
+	policy.Status.StoragePaths = locations
+	policy.Status.RemovedArchives = removedArchives[:min(len(removedArchives), maxCatalogedArchives)]
+	policy.Status.LockedArchives = slices.Compact(lockedArchives)
+	if err := errors.Join(errs...); err != nil {
+		backup.SetCondition(&policy.Status.Conditions, "Ready", metav1.ConditionFalse, "CleanupFailed", err.Error())
+		return false
+	}
+	if err := errors.Join(busy...); err != nil {
+		backup.SetCondition(&policy.Status.Conditions, "Ready", metav1.ConditionFalse, "StorageBusy", err.Error())
+		return true
+	}
+	backup.SetCondition(&policy.Status.Conditions, "Ready", metav1.ConditionTrue, "CleanedUp",
+		fmt.Sprintf("Removed %d archives from %d storage locations", len(removedArchives), len(locations)))
+	return false
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *CleanupPolicyReconciler) SetupWithManager(mgr ctrl.Manager, options Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		// Status updates must not start another pass
+		For(&backupv1alpha1.CleanupPolicy{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Named("cleanuppolicy").
+		WithOptions(options.forController("cleanuppolicy")).
+		Complete(r)
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+var _ = Describe("CleanupPolicy Controller", func() {
+	ctx := context.Background()
+	typeNamespacedName := types.NamespacedName{Name: "test-cleanup", Namespace: "default"}
+
+	AfterEach(func() {
+		resource := &backupv1alpha1.CleanupPolicy{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+	})
+
+	It("should remove archives beyond maxArchives and record them in status", func() {
+		storage := GinkgoT().TempDir()
+		for _, archive := range []string{
+			"cluster-backup-20250101-000000.tar.gz",
+			"cluster-backup-20250102-000000.tar.gz",
+			"cluster-backup-20250103-000000.tar.gz",
+		} {
+			Expect(os.WriteFile(filepath.Join(storage, archive), []byte("archive"), 0o644)).To(Succeed())
+		}
+		maxArchives := 1
+
+		Expect(k8sClient.Create(ctx, &backupv1alpha1.CleanupPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: typeNamespacedName.Name, Namespace: typeNamespacedName.Namespace},
+			Spec: backupv1alpha1.CleanupPolicySpec{
+				StoragePaths: []string{storage},
+				MaxArchives:  &maxArchives,
+				Interval:     &metav1.Duration{Duration: time.Minute},
+			},
+		})).To(Succeed())
+
+		reconciler := &CleanupPolicyReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), BackupManager: &backup.BackupManager{}}
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(time.Minute))
+		Expect(filepath.Join(storage, "cluster-backup-20250103-000000.tar.gz")).To(BeAnExistingFile())
+		Expect(filepath.Join(storage, "cluster-backup-20250101-000000.tar.gz")).NotTo(BeAnExistingFile())
+
+		policy := &backupv1alpha1.CleanupPolicy{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, policy)).To(Succeed())
+		Expect(policy.Status.RemovedArchives).To(HaveLen(2))
+		Expect(policy.Status.LastCleanupTime).NotTo(BeNil())
+		Expect(meta.IsStatusConditionTrue(policy.Status.Conditions, "Ready")).To(BeTrue())
+
+		By("waiting for the interval before the next pass")
+		result, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeNumerically("<=", time.Minute))
+	})
+
+	It("should report a missing ClusterBackup", func() {
+		retentionDays := 7
+		Expect(k8sClient.Create(ctx, &backupv1alpha1.CleanupPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: typeNamespacedName.Name, Namespace: typeNamespacedName.Namespace},
+			Spec: backupv1alpha1.CleanupPolicySpec{
+				BackupName:    "missing",
+				RetentionDays: &retentionDays,
+			},
+		})).To(Succeed())
+
+		reconciler := &CleanupPolicyReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), BackupManager: &backup.BackupManager{}}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+
+		policy := &backupv1alpha1.CleanupPolicy{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, policy)).To(Succeed())
+		condition := meta.FindStatusCondition(policy.Status.Conditions, "Ready")
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("SourceUnavailable"))
+	})
+})
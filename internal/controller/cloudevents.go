@@ -0,0 +1,111 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+// cloudEventsClient publishes CloudEvents. The timeout keeps an unreachable
+// sink from stalling reconciles.
+var cloudEventsClient = &http.Client{Timeout: 10 * time.Second}
+
+// cloudEventType maps an audit record to the CloudEvent type published for
+// it, or "" when none is.
+func cloudEventType(record backup.AuditRecord) string {
+	switch record.Operation {
+	case backup.AuditOperationBackup:
+		if record.Result == "failure" {
+			return backup.CloudEventBackupFailed
+		}
+		return backup.CloudEventBackupCompleted
+	case backup.AuditOperationRestore:
+		if record.Result == "failure" {
+			return backup.CloudEventRestoreFailed
+		}
+		return backup.CloudEventRestoreCompleted
+	case backup.AuditOperationDelete:
+		return backup.CloudEventArchiveDeleted
+	}
+	return ""
+}
+
+// publishCloudEvent publishes the CloudEvent for record to the configured
+// sink and notification services, carrying the record as data. Failures are
+// logged and counted and never fail the operation.
+func publishCloudEvent(ctx context.Context, config *backupv1alpha1.BackupOperatorConfigSpec, record backup.AuditRecord) {
+	log := logf.FromContext(ctx)
+	eventType := cloudEventType(record)
+	if config == nil || eventType == "" || (config.CloudEvents == nil && len(config.Notifications) == 0) {
+		return
+	}
+	event := backup.CloudEvent{
+		ID:      string(uuid.NewUUID()),
+		Source:  cloudEventSource(record.Resource),
+		Type:    eventType,
+		Subject: record.Archive,
+		Time:    record.Time,
+		Data:    record,
+	}
+
+	if config.CloudEvents != nil {
+		sink := backup.CloudEventSink{URL: config.CloudEvents.URL}
+		if config.CloudEvents.Protocol == backupv1alpha1.CloudEventsProtocolKafka {
+			sink.Topic = config.CloudEvents.Topic
+		}
+		if err := backup.PublishCloudEvent(ctx, cloudEventsClient, sink, event); err != nil {
+			log.Error(err, "Failed to publish cloud event", "type", eventType, "url", sink.URL)
+			cloudEventsFailedTotal.WithLabelValues("cloudevents", eventType).Inc()
+		}
+	}
+
+	for _, sink := range config.Notifications {
+		if len(sink.Types) > 0 && !slices.Contains(sink.Types, backupv1alpha1.CloudEventType(eventType)) {
+			continue
+		}
+		notifier, err := backup.NewNotifier(ctx, cloudEventsClient, sink.Provider, sink.Target)
+		if err == nil {
+			err = notifier.Notify(ctx, event)
+		}
+		if err != nil {
+			log.Error(err, "Failed to publish notification", "type", eventType, "provider", sink.Provider, "target", sink.Target)
+			cloudEventsFailedTotal.WithLabelValues(sink.Provider, eventType).Inc()
+		}
+	}
+}
+
+// cloudEventSource turns a "Kind/namespace/name" resource into the API path
+// of the object, e.g. /apis/backup.backup.io/v1alpha1/namespaces/default/clusterbackups/nightly.
+func cloudEventSource(resource string) string {
+	parts := strings.SplitN(resource, "/", 3)
+	if len(parts) != 3 {
+		return "/" + resource
+	}
+	return fmt.Sprintf("/apis/%s/namespaces/%s/%ss/%s",
+		backupv1alpha1.GroupVersion.String(), parts[1], strings.ToLower(parts[0]), parts[2])
+}
@@ -18,23 +18,57 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
-	"k8s.io/apimachinery/pkg/api/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
-	"github.com/zachperkins/backup-operator/internal/backup"
+	"github.com/zachperkins/backup-operator/pkg/backup"
 )
 
 const (
 	backupFinalizer = "backup.backup.io/finalizer"
+
+	// defaultStaleThreshold is how many schedule periods may pass without a
+	// successful backup before the ClusterBackup is reported as stale.
+	defaultStaleThreshold = 2.0
+
+	// defaultDegradedThreshold is how many runs in a row may fail before the
+	// ClusterBackup is reported as degraded.
+	defaultDegradedThreshold = 3
+
+	// referencedArchivesRequeue is how often the deletion of a ClusterBackup
+	// whose archives a restore still reads is retried
+	referencedArchivesRequeue = time.Minute
+
+	// storageLockRetryInterval is how often a run waiting for another run to
+	// release a storage location checks again
+	storageLockRetryInterval = 15 * time.Second
+
+	// defaultRetryBackoff is the delay before the first retry of a failed
+	// run when spec.retryPolicy.backoff is unset; maxRetryBackoff caps the
+	// delay of later retries.
+	defaultRetryBackoff = time.Minute
+	maxRetryBackoff     = time.Hour
 )
 
 // ClusterBackupReconciler reconciles a ClusterBackup object
@@ -42,11 +76,25 @@ type ClusterBackupReconciler struct {
 	client.Client
 	Scheme        *runtime.Scheme
 	BackupManager *backup.BackupManager
+
+	// StaleThreshold is the number of schedule periods after which a
+	// scheduled backup without a new success is marked Stale. Defaults to 2.
+	StaleThreshold float64
+
+	// DegradedThreshold is the number of consecutive failed runs after which
+	// a ClusterBackup is marked Degraded. Defaults to 3.
+	DegradedThreshold int
+
+	// Recorder, when set, receives an event for every finished backup and
+	// restore run.
+	Recorder record.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=backup.backup.io,resources=clusterbackups,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=backup.backup.io,resources=clusterbackups/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=backup.backup.io,resources=clusterbackups/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create
 // +kubebuilder:rbac:groups="",resources=*,verbs=get;list
 // +kubebuilder:rbac:groups="*",resources=*,verbs=get;list
 
@@ -58,7 +106,7 @@ func (r *ClusterBackupReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	// Fetch the ClusterBackup instance
 	clusterBackup := &backupv1alpha1.ClusterBackup{}
 	if err := r.Get(ctx, req.NamespacedName, clusterBackup); err != nil {
-		if errors.IsNotFound(err) {
+		if apierrors.IsNotFound(err) {
 			// Object not found, return without error
 			return ctrl.Result{}, nil
 		}
@@ -66,9 +114,20 @@ func (r *ClusterBackupReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, err
 	}
 
+	config, err := loadOperatorConfig(ctx, r.Client)
+	if err != nil {
+		log.Error(err, "Failed to load operator configuration")
+		return ctrl.Result{}, err
+	}
+
 	// Handle deletion
 	if !clusterBackup.ObjectMeta.DeletionTimestamp.IsZero() {
-		return r.handleDeletion(ctx, clusterBackup)
+		return r.handleDeletion(ctx, clusterBackup, config)
+	}
+
+	if clusterBackup.Status.LastBackupTime != nil {
+		backupLastSuccessTimestamp.WithLabelValues(clusterBackup.Namespace, clusterBackup.Name).
+			Set(float64(clusterBackup.Status.LastBackupTime.Unix()))
 	}
 
 	// Add finalizer if not present
@@ -79,45 +138,199 @@ func (r *ClusterBackupReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		}
 	}
 
+	// Pins apply without waiting for the next backup
+	if r.syncPinnedArchives(ctx, clusterBackup, config) {
+		if err := r.Status().Update(ctx, clusterBackup); err != nil {
+			log.Error(err, "Failed to update archive catalog")
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Estimate mode sizes the backup once per generation and never stores
+	// an archive
+	if clusterBackup.Spec.Estimate {
+		return ctrl.Result{}, r.handleEstimate(ctx, clusterBackup, config)
+	}
+
 	// Check if backup has already been completed
 	if clusterBackup.Status.Phase == "Completed" || clusterBackup.Status.Phase == "Failed" {
-		if err := r.handleRestore(ctx, clusterBackup); err != nil {
+		if err := r.handleRestore(ctx, clusterBackup, config); err != nil {
 			return ctrl.Result{}, err
 		}
-		// If there's a schedule, requeue for next run
-		if clusterBackup.Spec.Schedule != "" {
-			// TODO: Implement cron scheduling
-			return ctrl.Result{RequeueAfter: time.Hour}, nil
+		// A due schedule, a new trigger or a due retry starts a new run below
+		due, untilDue := false, time.Duration(0)
+		if scheduled(clusterBackup) {
+			// Admission rejects invalid schedules, so only objects stored
+			// before it did get here; they wait for the schedule to be fixed
+			if _, err := backup.ParseSchedule(clusterBackup.Spec.Schedule); err != nil {
+				if ready := meta.FindStatusCondition(clusterBackup.Status.Conditions, "Ready"); ready == nil || ready.Reason != "InvalidSchedule" {
+					backup.SetCondition(&clusterBackup.Status.Conditions, "Ready", metav1.ConditionFalse, "InvalidSchedule", err.Error())
+					clusterBackup.Status.NextScheduleTime = nil
+					if err := r.Status().Update(ctx, clusterBackup); err != nil {
+						log.Error(err, "Failed to update schedule status")
+						return ctrl.Result{}, err
+					}
+				}
+				return ctrl.Result{}, nil
+			}
+			due, untilDue = scheduleDue(clusterBackup, time.Now())
+		}
+		retry, untilRetry := retryDue(clusterBackup, time.Now())
+		if due || triggered(clusterBackup) {
+			// A fresh run replaces the pending retry
+			clusterBackup.Status.NextRetryTime = nil
+			clusterBackup.Status.Phase = "Pending"
+		} else if retry {
+			clusterBackup.Status.Phase = "Pending"
+		} else if scheduled(clusterBackup) {
+			// If there's a schedule, requeue for next run; the requeue also
+			// refreshes the Stale condition
+			requeueAfter := time.Hour
+			changed, untilStale := r.setStaleCondition(clusterBackup, config, time.Now())
+			// Schedule edits move the next run without waiting for it
+			previous := clusterBackup.Status.NextScheduleTime
+			setNextScheduleTime(clusterBackup, time.Now())
+			if changed || !previous.Equal(clusterBackup.Status.NextScheduleTime) {
+				if err := r.Status().Update(ctx, clusterBackup); err != nil {
+					log.Error(err, "Failed to update schedule status")
+					return ctrl.Result{}, err
+				}
+			}
+			if untilStale > 0 && untilStale < requeueAfter {
+				requeueAfter = untilStale
+			}
+			if untilDue > 0 && untilDue < requeueAfter {
+				requeueAfter = untilDue
+			}
+			if untilRetry > 0 && untilRetry < requeueAfter {
+				requeueAfter = untilRetry
+			}
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		} else {
+			// One-time backup already done, or waiting for its retry
+			return ctrl.Result{RequeueAfter: untilRetry}, nil
 		}
-		// One-time backup already done
-		return ctrl.Result{}, nil
 	}
 
+	// Runs sharing a storage location take turns so their writes and
+	// retention don't interleave; the later one waits its turn
+	releaseStorage, err := r.BackupManager.LockStorage(storageLockHolder("ClusterBackup", clusterBackup),
+		storageLocationsFor(clusterBackup, config)...)
+	var storageLocked *backup.StorageLockedError
+	if errors.As(err, &storageLocked) {
+		return r.queueRun(ctx, clusterBackup, storageLocked)
+	}
+	defer releaseStorage()
+
+	// A run interrupted while Running keeps its ID when it is picked up again
+	runID := clusterBackup.Status.LastRunID
+	if runID == "" || clusterBackup.Status.Phase == "" || clusterBackup.Status.Phase == "Pending" {
+		runID = newRunID()
+	}
+	ctx = withRunID(ctx, runID)
+	log = logf.FromContext(ctx)
+	clusterBackup.Status.LastRunID = runID
+
 	// Update status to Running if not already set
 	if clusterBackup.Status.Phase == "" || clusterBackup.Status.Phase == "Pending" {
 		clusterBackup.Status.Phase = "Running"
 		now := metav1.Now()
 		clusterBackup.Status.StartTime = &now
 		clusterBackup.Status.Message = "Backup in progress"
+		// A retry keeps the trigger of the run it retries and collects every
+		// resource schedule
+		retrying := clusterBackup.Status.NextRetryTime != nil
+		if retrying {
+			clusterBackup.Status.Attempts++
+			clusterBackup.Status.NextRetryTime = nil
+		} else {
+			clusterBackup.Status.Attempts = 1
+			clusterBackup.Status.LastRunTrigger = backupv1alpha1.RunTriggerScheduled
+			if triggered(clusterBackup) || !scheduled(clusterBackup) {
+				clusterBackup.Status.LastRunTrigger = backupv1alpha1.RunTriggerManual
+			} else {
+				clusterBackup.Status.LastScheduleTime = &now
+			}
+			clusterBackup.Status.LastTrigger = clusterBackup.Annotations[backupv1alpha1.TriggerAnnotation]
+		}
+		startResourceSchedules(clusterBackup, now.Time, retrying || clusterBackup.Status.LastRunTrigger == backupv1alpha1.RunTriggerManual)
 		if err := r.Status().Update(ctx, clusterBackup); err != nil {
 			log.Error(err, "Failed to update status to Running")
 			return ctrl.Result{}, err
 		}
+		r.saveBackupRun(ctx, clusterBackup, backupv1alpha1.BackupRunStatus{
+			Phase: backupv1alpha1.BackupRunPhaseRunning, StartTime: clusterBackup.Status.StartTime,
+		})
 	}
 
 	// Perform the backup
-	result, err := r.performBackup(ctx, clusterBackup)
+	result, err := r.performBackup(ctx, clusterBackup, config)
+	partial := err == nil && result.Partial
+	if partial {
+		// The manager is shutting down; record the partial archive before
+		// the process exits
+		ctx = context.WithoutCancel(ctx)
+		err = fmt.Errorf("backup interrupted by operator shutdown, kept a partial archive of %d resources in %s",
+			result.ResourceCount, result.FilePath)
+	}
 	if err != nil {
 		log.Error(err, "Backup failed")
 		clusterBackup.Status.Phase = "Failed"
 		clusterBackup.Status.Message = fmt.Sprintf("Backup failed: %v", err)
 		now := metav1.Now()
 		clusterBackup.Status.CompletionTime = &now
-		backup.SetCondition(&clusterBackup.Status.Conditions, "Ready", metav1.ConditionFalse, "BackupFailed", err.Error())
+		finishResourceSchedules(clusterBackup, now)
+		setNextScheduleTime(clusterBackup, now.Time)
+		reason := "BackupFailed"
+		var missing *backup.MissingPermissionsError
+		switch {
+		case partial:
+			reason = "BackupInterrupted"
+		case errors.As(err, &missing):
+			reason = "MissingPermissions"
+		case errors.Is(err, backup.ErrInsufficientScratchSpace):
+			reason = "InsufficientScratchSpace"
+		case errors.Is(err, backup.ErrUnknownScratchDir):
+			reason = "UnknownScratchDir"
+		}
+		backup.SetCondition(&clusterBackup.Status.Conditions, "Ready", metav1.ConditionFalse, reason, err.Error())
+		clusterBackup.Status.ConsecutiveFailures++
+		r.setDegradedCondition(clusterBackup, config)
+		record := backupv1alpha1.BackupRunRecord{
+			RunID: runID, Trigger: clusterBackup.Status.LastRunTrigger, Attempt: clusterBackup.Status.Attempts,
+			StartTime: clusterBackup.Status.StartTime, CompletionTime: now, Outcome: "Failed", Error: err.Error(),
+		}
+		runStatus := backupv1alpha1.BackupRunStatus{
+			Phase: backupv1alpha1.BackupRunPhaseFailed, StartTime: clusterBackup.Status.StartTime,
+			CompletionTime: &now, Message: err.Error(),
+		}
+		if partial {
+			size := resource.NewQuantity(result.ArchiveBytes, resource.BinarySI)
+			record.Archive, record.Size, record.ResourceCount, record.Partial = filepath.Base(result.FilePath), size, result.ResourceCount, true
+			runStatus.Archive, runStatus.BackupLocation, runStatus.Size = filepath.Base(result.FilePath), result.FilePath, size
+			runStatus.ResourceCount, runStatus.Partial = result.ResourceCount, true
+		}
+		recordRunHistory(clusterBackup, record)
+		retryAfter, retry := nextRetry(clusterBackup)
+		if retry {
+			nextRetryTime := metav1.NewTime(now.Add(retryAfter).Truncate(time.Second))
+			clusterBackup.Status.NextRetryTime = &nextRetryTime
+			clusterBackup.Status.Message += fmt.Sprintf("; retrying at %s (attempt %d of %d)",
+				nextRetryTime.UTC().Format(time.RFC3339), clusterBackup.Status.Attempts+1, clusterBackup.Spec.RetryPolicy.MaxRetries+1)
+		}
+		recordBackupRun(clusterBackup.Namespace, clusterBackup.Name, runID, "failure", runDuration(clusterBackup))
+		recordRunEvent(r.Recorder, clusterBackup, runID, corev1.EventTypeWarning, "BackupFailed", "Backup run %s failed: %v", runID, err)
+		r.audit(ctx, clusterBackup, config, storageLocationsFor(clusterBackup, config), backup.AuditRecord{
+			Operation: backup.AuditOperationBackup, RunID: runID, Result: "failure", Message: err.Error(),
+		})
+		r.finishBackupRun(ctx, clusterBackup, runStatus)
 
-		if statusErr := r.Status().Update(ctx, clusterBackup); statusErr != nil {
+		if statusErr := r.updateRunStatus(ctx, clusterBackup); statusErr != nil {
 			log.Error(statusErr, "Failed to update status after backup failure")
 		}
+		if retry {
+			return ctrl.Result{RequeueAfter: retryAfter}, nil
+		}
 		return ctrl.Result{}, err
 	}
 
@@ -125,87 +338,1029 @@ func (r *ClusterBackupReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	clusterBackup.Status.Phase = "Completed"
 	clusterBackup.Status.ResourceCount = result.ResourceCount
 	clusterBackup.Status.BackupLocation = result.FilePath
+	clusterBackup.Status.LastExportCommit = result.ExportCommit
 	clusterBackup.Status.Message = fmt.Sprintf("Successfully backed up %d resources", result.ResourceCount)
+	if result.ReusedResources > 0 {
+		clusterBackup.Status.Message += fmt.Sprintf(", %d unchanged since %s", result.ReusedResources, result.BaseArchive)
+	}
+	clusterBackup.Status.SkippedResources = permissionGapNames(result.SkippedResources)
+	if len(result.SkippedResources) > 0 {
+		clusterBackup.Status.Message += fmt.Sprintf(", skipped %d resources the operator may not list", len(result.SkippedResources))
+		recordRunEvent(r.Recorder, clusterBackup, runID, corev1.EventTypeWarning, "ResourcesSkipped",
+			"Backup run %s left out resources the operator may not list: %s", runID, strings.Join(clusterBackup.Status.SkippedResources, ", "))
+	}
+	clusterBackup.Status.SlowestResources = slowestResources(result.ResourceTimings)
+	if len(clusterBackup.Status.SlowestResources) > 0 {
+		slowest := clusterBackup.Status.SlowestResources[0]
+		clusterBackup.Status.Message += fmt.Sprintf(", slowest resource %s took %s", slowest.Resource, slowest.Duration.Round(time.Millisecond))
+	}
 	now := metav1.Now()
 	clusterBackup.Status.CompletionTime = &now
 	clusterBackup.Status.LastBackupTime = &now
+	finishResourceSchedules(clusterBackup, now)
+	setNextScheduleTime(clusterBackup, now.Time)
+	clusterBackup.Status.ArchiveSize = resource.NewQuantity(result.ArchiveBytes, resource.BinarySI)
+	clusterBackup.Status.Scratch = scratchUsage(result)
+	recordRunHistory(clusterBackup, backupv1alpha1.BackupRunRecord{
+		RunID: runID, Trigger: clusterBackup.Status.LastRunTrigger, Attempt: clusterBackup.Status.Attempts,
+		StartTime: clusterBackup.Status.StartTime, CompletionTime: now, Outcome: "Succeeded", Archive: filepath.Base(result.FilePath),
+		Size: clusterBackup.Status.ArchiveSize, ResourceCount: result.ResourceCount,
+	})
 	backup.SetCondition(&clusterBackup.Status.Conditions, "Ready", metav1.ConditionTrue, "BackupCompleted", "Backup completed successfully")
+	clusterBackup.Status.ConsecutiveFailures = 0
+	r.setDegradedCondition(clusterBackup, config)
+	r.setStaleCondition(clusterBackup, config, now.Time)
+	setStorageLocations(clusterBackup, storagePathFor(clusterBackup, config), result, now)
+	r.audit(ctx, clusterBackup, config, storageLocationsFor(clusterBackup, config), backup.AuditRecord{
+		Operation: backup.AuditOperationBackup, RunID: runID, Archive: filepath.Base(result.FilePath), Result: "success",
+		Message: clusterBackup.Status.Message,
+	})
+	r.applyLifecycle(ctx, clusterBackup, config)
+	r.setStorageUsage(ctx, clusterBackup)
+	releaseStorage()
+	r.finishBackupRun(ctx, clusterBackup, backupv1alpha1.BackupRunStatus{
+		Phase: backupv1alpha1.BackupRunPhaseSucceeded, StartTime: clusterBackup.Status.StartTime, CompletionTime: &now,
+		Archive: filepath.Base(result.FilePath), BackupLocation: result.FilePath, Size: clusterBackup.Status.ArchiveSize,
+		ResourceCount: result.ResourceCount, BaseArchive: result.BaseArchive,
+		StorageLocations: clusterBackup.Status.StorageLocations, SkippedResources: clusterBackup.Status.SkippedResources,
+		SlowestResources: clusterBackup.Status.SlowestResources, Message: clusterBackup.Status.Message,
+	})
 
-	if err := r.Status().Update(ctx, clusterBackup); err != nil {
+	if err := r.updateRunStatus(ctx, clusterBackup); err != nil {
 		log.Error(err, "Failed to update status after successful backup")
 		return ctrl.Result{}, err
 	}
+	backupLastSuccessTimestamp.WithLabelValues(clusterBackup.Namespace, clusterBackup.Name).Set(float64(now.Unix()))
+	recordBackupRun(clusterBackup.Namespace, clusterBackup.Name, runID, "success", runDuration(clusterBackup))
+	recordResourceTimings(clusterBackup.Namespace, clusterBackup.Name, result.ResourceTimings)
+	recordRunEvent(r.Recorder, clusterBackup, runID, corev1.EventTypeNormal, "BackupCompleted",
+		"Backup run %s stored %d resources in %s", runID, result.ResourceCount, result.FilePath)
 
 	log.Info("Backup completed successfully", "resourceCount", result.ResourceCount, "location", result.FilePath)
 
-	// Run retention cleanup if configured
-	if clusterBackup.Spec.RetentionDays != nil || clusterBackup.Spec.MaxArchives != nil {
-		if err := r.BackupManager.CleanupArchives(clusterBackup.Spec.StoragePath, clusterBackup.Spec.RetentionDays, clusterBackup.Spec.MaxArchives); err != nil {
-			log.Error(err, "Failed to cleanup old archives")
+	if err := r.handleRestore(ctx, clusterBackup, config); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// If there's a schedule, requeue for next run
+	if next := clusterBackup.Status.NextScheduleTime; next != nil {
+		return ctrl.Result{RequeueAfter: max(time.Until(next.Time), time.Second)}, nil
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// scheduled reports whether clusterBackup runs on a schedule rather than once.
+func scheduled(clusterBackup *backupv1alpha1.ClusterBackup) bool {
+	return clusterBackup.Spec.Schedule != "" || len(clusterBackup.Spec.ResourceSchedules) > 0
+}
+
+// scheduleDue reports whether spec.schedule or a resource schedule of
+// clusterBackup is due at now and, when none is, how long until the next one
+// is. Manual runs do not move the schedule.
+func scheduleDue(clusterBackup *backupv1alpha1.ClusterBackup, now time.Time) (bool, time.Duration) {
+	if len(clusterBackup.Spec.ResourceSchedules) > 0 {
+		return nextResourceSchedule(clusterBackup, now)
+	}
+	last := clusterBackup.Status.LastScheduleTime
+	if last == nil {
+		last = clusterBackup.Status.StartTime
+	}
+	if last == nil {
+		return true, 0
+	}
+	next := backupSchedule(clusterBackup).Next(last.Time)
+	if next.IsZero() {
+		return false, 0
+	}
+	if until := next.Sub(now); until > 0 {
+		return false, until
+	}
+	return true, 0
+}
+
+// retryDue reports whether the retry of a failed run of clusterBackup is due
+// at now and, when one is pending but not due, how long until it is.
+func retryDue(clusterBackup *backupv1alpha1.ClusterBackup, now time.Time) (bool, time.Duration) {
+	next := clusterBackup.Status.NextRetryTime
+	if next == nil {
+		return false, 0
+	}
+	if until := next.Sub(now); until > 0 {
+		return false, until
+	}
+	return true, 0
+}
+
+// nextRetry returns how long to wait before retrying the run of
+// clusterBackup that just failed, and false when spec.retryPolicy allows no
+// further retry. The backoff doubles with every attempt.
+func nextRetry(clusterBackup *backupv1alpha1.ClusterBackup) (time.Duration, bool) {
+	policy := clusterBackup.Spec.RetryPolicy
+	if policy == nil || clusterBackup.Status.Attempts > policy.MaxRetries {
+		return 0, false
+	}
+	backoff := defaultRetryBackoff
+	if policy.Backoff != nil && policy.Backoff.Duration > 0 {
+		backoff = policy.Backoff.Duration
+	}
+	for range max(clusterBackup.Status.Attempts-1, 0) {
+		if backoff *= 2; backoff >= maxRetryBackoff {
+			return maxRetryBackoff, true
 		}
 	}
+	return min(backoff, maxRetryBackoff), true
+}
 
-	if err := r.handleRestore(ctx, clusterBackup); err != nil {
-		return ctrl.Result{}, err
+// setNextScheduleTime records when the schedule of clusterBackup starts its
+// next run, as seen at now, and clears it for one-shot backups.
+func setNextScheduleTime(clusterBackup *backupv1alpha1.ClusterBackup, now time.Time) {
+	clusterBackup.Status.NextScheduleTime = nil
+	if !scheduled(clusterBackup) {
+		return
+	}
+	due, until := scheduleDue(clusterBackup, now)
+	if !due && until == 0 {
+		// The schedule never fires again
+		return
 	}
+	// Status keeps whole seconds, so the time compares equal once stored
+	next := metav1.NewTime(now.Add(until).Truncate(time.Second))
+	clusterBackup.Status.NextScheduleTime = &next
+}
 
-	// If there's a schedule, requeue for next run
-	if clusterBackup.Spec.Schedule != "" {
-		// Try to parse schedule as a duration (e.g., "24h"). If parsing fails, fallback to 1h requeue.
-		if d, err := time.ParseDuration(clusterBackup.Spec.Schedule); err == nil {
-			return ctrl.Result{RequeueAfter: d}, nil
+// triggered reports whether the trigger annotation of clusterBackup asks
+// for a run it has not started yet.
+func triggered(clusterBackup *backupv1alpha1.ClusterBackup) bool {
+	trigger := clusterBackup.Annotations[backupv1alpha1.TriggerAnnotation]
+	return trigger != "" && trigger != clusterBackup.Status.LastTrigger
+}
+
+// queueRun holds a due run back while another run holds one of its storage
+// locations and retries it shortly.
+func (r *ClusterBackupReconciler) queueRun(ctx context.Context, clusterBackup *backupv1alpha1.ClusterBackup, locked *backup.StorageLockedError) (ctrl.Result, error) {
+	message := fmt.Sprintf("Queued until %s finishes with %s", locked.Holder, locked.StoragePath)
+	if clusterBackup.Status.Message != message {
+		logf.FromContext(ctx).Info("Backup queued behind another run", "storagePath", locked.StoragePath, "holder", locked.Holder)
+		if clusterBackup.Status.Phase == "" {
+			clusterBackup.Status.Phase = "Pending"
+		}
+		clusterBackup.Status.Message = message
+		if err := r.Status().Update(ctx, clusterBackup); err != nil {
+			return ctrl.Result{}, err
 		}
-		// TODO: Implement proper cron scheduling
-		return ctrl.Result{RequeueAfter: time.Hour}, nil
 	}
+	return ctrl.Result{RequeueAfter: storageLockRetryInterval}, nil
+}
 
-	return ctrl.Result{}, nil
+// setStaleCondition flags scheduled backups whose last success is older than
+// the schedule period multiplied by the stale threshold, which config may
+// override. It reports whether the conditions changed and how long remains
+// until the backup becomes stale.
+func (r *ClusterBackupReconciler) setStaleCondition(clusterBackup *backupv1alpha1.ClusterBackup, config *backupv1alpha1.BackupOperatorConfigSpec, now time.Time) (bool, time.Duration) {
+	if clusterBackup.Spec.Schedule == "" {
+		backupStale.DeleteLabelValues(clusterBackup.Namespace, clusterBackup.Name)
+		return meta.RemoveStatusCondition(&clusterBackup.Status.Conditions, "Stale"), 0
+	}
+
+	threshold := r.StaleThreshold
+	if threshold <= 0 {
+		threshold = defaultStaleThreshold
+	}
+	if config != nil {
+		threshold = staleThresholdFor(config, threshold)
+	}
+
+	// Before the first success, measure from when the ClusterBackup was created
+	lastSuccess := clusterBackup.CreationTimestamp.Time
+	if clusterBackup.Status.LastBackupTime != nil {
+		lastSuccess = clusterBackup.Status.LastBackupTime.Time
+	}
+	period := schedulePeriod(backupSchedule(clusterBackup), lastSuccess)
+	if period == 0 {
+		backupStale.DeleteLabelValues(clusterBackup.Namespace, clusterBackup.Name)
+		return meta.RemoveStatusCondition(&clusterBackup.Status.Conditions, "Stale"), 0
+	}
+	maxAge := time.Duration(float64(period) * threshold)
+	staleAt := lastSuccess.Add(maxAge)
+
+	condition := metav1.Condition{
+		Type:    "Stale",
+		Status:  metav1.ConditionFalse,
+		Reason:  "BackupRecent",
+		Message: fmt.Sprintf("Last successful backup is within %s", maxAge),
+	}
+	stale := 0.0
+	if !now.Before(staleAt) {
+		stale = 1
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "BackupOverdue"
+		condition.Message = fmt.Sprintf("No successful backup since %s, expected at least every %s",
+			lastSuccess.UTC().Format(time.RFC3339), maxAge)
+	}
+	backupStale.WithLabelValues(clusterBackup.Namespace, clusterBackup.Name).Set(stale)
+
+	return meta.SetStatusCondition(&clusterBackup.Status.Conditions, condition), staleAt.Sub(now)
 }
 
-// performBackup executes the backup operation
-func (r *ClusterBackupReconciler) performBackup(ctx context.Context, clusterBackup *backupv1alpha1.ClusterBackup) (*backup.BackupResult, error) {
+// setDegradedCondition sets the Degraded condition, which turns True once
+// the number of consecutive failed runs reaches the threshold config may
+// override, so a persistently broken backup stands out from a single failure.
+func (r *ClusterBackupReconciler) setDegradedCondition(clusterBackup *backupv1alpha1.ClusterBackup, config *backupv1alpha1.BackupOperatorConfigSpec) {
+	threshold := r.DegradedThreshold
+	if threshold <= 0 {
+		threshold = defaultDegradedThreshold
+	}
+	if config != nil {
+		threshold = degradedThresholdFor(config, threshold)
+	}
+
+	failures := int(clusterBackup.Status.ConsecutiveFailures)
+	condition := metav1.Condition{
+		Type:    "Degraded",
+		Status:  metav1.ConditionFalse,
+		Reason:  "BackupSucceeded",
+		Message: "The last run succeeded",
+	}
+	degraded := 0.0
+	switch {
+	case failures >= threshold:
+		degraded = 1
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "ConsecutiveFailures"
+		condition.Message = fmt.Sprintf("The last %d runs failed", failures)
+	case failures > 0:
+		condition.Reason = "BelowFailureThreshold"
+		condition.Message = fmt.Sprintf("%d consecutive runs failed, %d mark the backup degraded", failures, threshold)
+	}
+	meta.SetStatusCondition(&clusterBackup.Status.Conditions, condition)
+	backupConsecutiveFailures.WithLabelValues(clusterBackup.Namespace, clusterBackup.Name).Set(float64(failures))
+	backupDegraded.WithLabelValues(clusterBackup.Namespace, clusterBackup.Name).Set(degraded)
+}
+
+// setStorageUsage records how many archives each storage location holds once
+// retention has run. A location that cannot be read keeps its last usage.
+func (r *ClusterBackupReconciler) setStorageUsage(ctx context.Context, clusterBackup *backupv1alpha1.ClusterBackup) {
+	for i := range clusterBackup.Status.StorageLocations {
+		location := &clusterBackup.Status.StorageLocations[i]
+		usage, err := r.BackupManager.StorageUsage(location.StoragePath)
+		if err != nil {
+			logf.FromContext(ctx).Error(err, "Failed to measure storage usage", "storagePath", location.StoragePath)
+			continue
+		}
+		location.ArchiveCount = usage.Archives
+		location.TotalBytes = usage.Bytes
+		recordStorageUsage(location.StoragePath, usage)
+	}
+}
+
+// setStorageLocations records where the archive of a successful run was
+// stored and sets the Replicated condition when replicas are configured.
+func setStorageLocations(clusterBackup *backupv1alpha1.ClusterBackup, storagePath string, result *backup.BackupResult, now metav1.Time) {
+	previous := map[string]*metav1.Time{}
+	for _, location := range clusterBackup.Status.StorageLocations {
+		previous[location.StoragePath] = location.LastSuccessTime
+	}
+
+	locations := []backupv1alpha1.StorageLocationStatus{{
+		StoragePath:     storagePath,
+		Phase:           "Completed",
+		BackupLocation:  result.FilePath,
+		LastSuccessTime: &now,
+	}}
+	var failed []string
+	for _, replica := range result.Replicas {
+		location := backupv1alpha1.StorageLocationStatus{
+			StoragePath:     replica.StoragePath,
+			Phase:           "Completed",
+			BackupLocation:  replica.FilePath,
+			LastSuccessTime: &now,
+		}
+		if replica.Error != nil {
+			location.Phase = "Failed"
+			location.Message = replica.Error.Error()
+			location.LastSuccessTime = previous[replica.StoragePath]
+			failed = append(failed, replica.StoragePath)
+		}
+		locations = append(locations, location)
+	}
+	clusterBackup.Status.StorageLocations = locations
+
+	switch {
+	case len(result.Replicas) == 0:
+		meta.RemoveStatusCondition(&clusterBackup.Status.Conditions, "Replicated")
+	case len(failed) > 0:
+		clusterBackup.Status.Message += fmt.Sprintf(", %d of %d replicas failed", len(failed), len(result.Replicas))
+		backup.SetCondition(&clusterBackup.Status.Conditions, "Replicated", metav1.ConditionFalse, "ReplicationFailed",
+			fmt.Sprintf("Failed to copy the archive to %s", strings.Join(failed, ", ")))
+	default:
+		backup.SetCondition(&clusterBackup.Status.Conditions, "Replicated", metav1.ConditionTrue, "ReplicationSucceeded",
+			fmt.Sprintf("Archive copied to %d replica storage locations", len(result.Replicas)))
+	}
+}
+
+// applyLifecycle moves old archives to cold storage, enforces retention and
+// refreshes the archive catalog in status. Failures are logged and retried
+// after the next backup.
+func (r *ClusterBackupReconciler) applyLifecycle(ctx context.Context, clusterBackup *backupv1alpha1.ClusterBackup, config *backupv1alpha1.BackupOperatorConfigSpec) {
+	log := logf.FromContext(ctx)
+	storagePath := storagePathFor(clusterBackup, config)
+	coldStoragePath := coldStoragePathFor(clusterBackup, config)
+
+	tiering := clusterBackup.Spec.Tiering
+	if tiering != nil {
+		after := time.Duration(tiering.TransitionAfterDays) * 24 * time.Hour
+		moved, err := r.BackupManager.TransitionArchives(ctx, storagePath, coldStoragePath, after)
+		if err != nil {
+			log.Error(err, "Failed to move archives to cold storage", "coldStoragePath", coldStoragePath)
+		}
+		if len(moved) > 0 {
+			log.Info("Moved archives to cold storage", "archives", moved, "coldStoragePath", coldStoragePath)
+		}
+	}
+
+	clusterBackup.Status.LockedArchives = nil
+	recordLocked := func(location string, removed []string, err error) {
+		r.auditDeleted(ctx, clusterBackup, config, location, removed)
+		var immutable *backup.ImmutableArchivesError
+		if errors.As(err, &immutable) {
+			log.Info("Retention kept locked archives", "storagePath", location, "archives", immutable.Archives)
+			clusterBackup.Status.LockedArchives = append(clusterBackup.Status.LockedArchives, immutable.Archives...)
+		} else if err != nil {
+			log.Error(err, "Failed to cleanup old archives", "storagePath", location)
+		}
+	}
+	retention := archiveRetention{
+		retentionDays: clusterBackup.Spec.RetentionDays,
+		maxArchives:   clusterBackup.Spec.MaxArchives,
+		rotation:      clusterBackup.Spec.Rotation,
+		maxTotalSize:  clusterBackup.Spec.MaxTotalSize,
+	}
+	for _, location := range storageLocationsFor(clusterBackup, config) {
+		enforceRetention(r.BackupManager, location, retention, func(removed []string, err error) {
+			recordLocked(location, removed, err)
+		})
+	}
+	if tiering != nil && clusterBackup.Spec.RetentionDays != nil {
+		removed, err := r.BackupManager.CleanupArchives(coldStoragePath, clusterBackup.Spec.RetentionDays, nil)
+		recordLocked(coldStoragePath, removed, err)
+	}
+	slices.Sort(clusterBackup.Status.LockedArchives)
+	clusterBackup.Status.LockedArchives = slices.Compact(clusterBackup.Status.LockedArchives)
+
+	if encryption := clusterBackup.Spec.Encryption; encryption != nil && encryption.ReencryptArchives {
+		r.reencryptArchives(ctx, clusterBackup, config)
+	}
+
+	catalog, err := r.archiveCatalog(storagePath, coldStoragePath)
+	if err != nil {
+		log.Error(err, "Failed to list archives")
+		return
+	}
+	// Verification results outlive the listing they were recorded on
+	previous := map[string][]metav1.Condition{}
+	for _, entry := range clusterBackup.Status.Archives {
+		previous[entry.Name] = entry.Conditions
+	}
+	for i := range catalog {
+		catalog[i].Conditions = previous[catalog[i].Name]
+	}
+	clusterBackup.Status.Archives = catalog
+}
+
+// reencryptArchives re-encrypts the archives of every storage location that
+// are not encrypted with the current keys. Failures are logged and retried
+// after the next run.
+func (r *ClusterBackupReconciler) reencryptArchives(ctx context.Context, clusterBackup *backupv1alpha1.ClusterBackup, config *backupv1alpha1.BackupOperatorConfigSpec) {
+	log := logf.FromContext(ctx)
+
+	opts := backup.ReencryptOptions{}
+	var err error
+	if opts.KeyWrappers, err = encryptionKeyWrappers(ctx, clusterBackup.Spec.Encryption); err != nil {
+		log.Error(err, "Failed to set up re-encryption")
+		return
+	}
+	if restore := clusterBackup.Spec.Restore; restore != nil {
+		if opts.DecryptKeyWrappers, err = ageKeyWrappers(ctx, r.Client, clusterBackup.Namespace, restore.AgeIdentitySecretRef); err != nil {
+			log.Error(err, "Failed to set up re-encryption")
+			return
+		}
+	}
+
+	locations := storageLocationsFor(clusterBackup, config)
+	if coldStoragePath := coldStoragePathFor(clusterBackup, config); coldStoragePath != "" {
+		locations = append(locations, coldStoragePath)
+	}
+	for _, location := range locations {
+		rewritten, err := r.BackupManager.ReencryptArchives(ctx, location, opts)
+		if len(rewritten) > 0 {
+			log.Info("Re-encrypted archives with the current keys", "storagePath", location, "archives", rewritten)
+		}
+		var immutable *backup.ImmutableArchivesError
+		if errors.As(err, &immutable) {
+			log.Info("Locked archives cannot be re-encrypted", "storagePath", location, "archives", immutable.Archives)
+		} else if err != nil {
+			log.Error(err, "Failed to re-encrypt archives", "storagePath", location)
+		}
+	}
+}
+
+// syncPinnedArchives writes and removes the keep markers of
+// spec.pinnedArchives in every storage location and refreshes the pinned
+// flag of the catalog. It reports whether status changed. Failures are logged
+// and retried on the next reconcile.
+func (r *ClusterBackupReconciler) syncPinnedArchives(ctx context.Context, clusterBackup *backupv1alpha1.ClusterBackup, config *backupv1alpha1.BackupOperatorConfigSpec) bool {
 	log := logf.FromContext(ctx)
 
+	owner := fmt.Sprintf("clusterbackup/%s/%s", clusterBackup.Namespace, clusterBackup.Name)
+	locations := storageLocationsFor(clusterBackup, config)
+	if coldStoragePath := coldStoragePathFor(clusterBackup, config); coldStoragePath != "" {
+		locations = append(locations, coldStoragePath)
+	}
+	for _, location := range locations {
+		if err := r.BackupManager.SyncPinnedArchives(location, owner, clusterBackup.Spec.PinnedArchives); err != nil {
+			log.Error(err, "Failed to pin archives", "storagePath", location)
+		}
+	}
+
+	changed := false
+	for i := range clusterBackup.Status.Archives {
+		entry := &clusterBackup.Status.Archives[i]
+		if pinned := r.BackupManager.IsPinned(entry.StoragePath, entry.Name); pinned != entry.Pinned {
+			entry.Pinned = pinned
+			changed = true
+		}
+	}
+	return changed
+}
+
+// rotationCounts returns the archives to keep per rotation tag.
+func rotationCounts(rotation *backupv1alpha1.ArchiveRotation) map[backup.RotationTag]int {
+	keep := map[backup.RotationTag]int{}
+	for tag, count := range map[backup.RotationTag]*int{
+		backup.RotationDaily:   rotation.Daily,
+		backup.RotationWeekly:  rotation.Weekly,
+		backup.RotationMonthly: rotation.Monthly,
+	} {
+		if count != nil {
+			keep[tag] = *count
+		}
+	}
+	return keep
+}
+
+// archiveRetention holds the limits retention enforces on a storage
+// location; nil limits are not enforced.
+type archiveRetention struct {
+	retentionDays *int
+	maxArchives   *int
+	rotation      *backupv1alpha1.ArchiveRotation
+	maxTotalSize  *resource.Quantity
+}
+
+// enforceRetention applies the rotation, age and count, and size limits of
+// retention to location in that order, passing what each step removed and
+// its error to record.
+func enforceRetention(bm *backup.BackupManager, location string, retention archiveRetention, record func(removed []string, err error)) {
+	if retention.rotation != nil {
+		record(bm.RotateArchives(location, rotationCounts(retention.rotation)))
+	}
+	if retention.retentionDays != nil || retention.maxArchives != nil {
+		record(bm.CleanupArchives(location, retention.retentionDays, retention.maxArchives))
+	}
+	if retention.maxTotalSize != nil {
+		record(bm.EnforceMaxTotalSize(location, retention.maxTotalSize.Value()))
+	}
+}
+
+// storageLockHolder names obj as the holder of storage locations.
+func storageLockHolder(kind string, obj client.Object) string {
+	return fmt.Sprintf("%s %s/%s", kind, obj.GetNamespace(), obj.GetName())
+}
+
+// archiveCatalog lists the newest archives in the hot and cold locations;
+// coldStoragePath is empty without tiering. An archive present in both,
+// mid-transition, is reported as hot.
+func (r *ClusterBackupReconciler) archiveCatalog(storagePath, coldStoragePath string) ([]backupv1alpha1.ArchiveCatalogEntry, error) {
+	entries := map[string]backupv1alpha1.ArchiveCatalogEntry{}
+	if coldStoragePath != "" {
+		cold, err := r.BackupManager.ListArchives(coldStoragePath)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range cold {
+			entries[name] = backupv1alpha1.ArchiveCatalogEntry{Name: name, Tier: backupv1alpha1.ArchiveTierCold, StoragePath: coldStoragePath}
+		}
+	}
+	hot, err := r.BackupManager.ListArchives(storagePath)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range hot {
+		entries[name] = backupv1alpha1.ArchiveCatalogEntry{Name: name, Tier: backupv1alpha1.ArchiveTierHot, StoragePath: storagePath}
+	}
+
+	names := slices.Collect(maps.Keys(entries))
+	// Archive names sort chronologically; newest first
+	slices.Sort(names)
+	slices.Reverse(names)
+	catalog := make([]backupv1alpha1.ArchiveCatalogEntry, 0, min(len(names), maxCatalogedArchives))
+	for _, name := range names[:min(len(names), maxCatalogedArchives)] {
+		entry := entries[name]
+		entry.Pinned = r.BackupManager.IsPinned(entry.StoragePath, name)
+		entry.RotationTag = string(r.BackupManager.ArchiveRotationTag(entry.StoragePath, name))
+		entry.EncryptionKeys = r.BackupManager.ArchiveEncryptionKeys(entry.StoragePath, name)
+		catalog = append(catalog, entry)
+	}
+	return catalog, nil
+}
+
+// archiveStoragePath returns the location holding archiveName: storagePath,
+// or the cold storage location once the archive has been moved there.
+func archiveStoragePath(bm *backup.BackupManager, clusterBackup *backupv1alpha1.ClusterBackup,
+	config *backupv1alpha1.BackupOperatorConfigSpec, storagePath, archiveName string) string {
+	coldStoragePath := coldStoragePathFor(clusterBackup, config)
+	if coldStoragePath == "" || bm.HasArchive(storagePath, archiveName) || !bm.HasArchive(coldStoragePath, archiveName) {
+		return storagePath
+	}
+	return coldStoragePath
+}
+
+// storageLocationsFor returns the storage location of clusterBackup followed
+// by its replicas.
+func storageLocationsFor(clusterBackup *backupv1alpha1.ClusterBackup, config *backupv1alpha1.BackupOperatorConfigSpec) []string {
+	var locations []string
+	if storagePath := storagePathFor(clusterBackup, config); storagePath != "" {
+		locations = append(locations, storagePath)
+	}
+	return append(locations, replicaStoragePathsFor(clusterBackup, config)...)
+}
+
+// backupStoragePath returns the storage location of the named ClusterBackup.
+func backupStoragePath(ctx context.Context, c client.Reader, namespace, backupName string) (string, error) {
+	clusterBackup := &backupv1alpha1.ClusterBackup{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: backupName}, clusterBackup); err != nil {
+		return "", fmt.Errorf("failed to get ClusterBackup %q: %w", backupName, err)
+	}
+	config, err := loadOperatorConfig(ctx, c)
+	if err != nil {
+		return "", err
+	}
+	storagePath := storagePathFor(clusterBackup, config)
+	if storagePath == "" {
+		return "", fmt.Errorf("ClusterBackup %q has no storage location", backupName)
+	}
+	return storagePath, nil
+}
+
+// runDuration returns how long the current run has taken so far, measured from
+// its StartTime.
+func runDuration(clusterBackup *backupv1alpha1.ClusterBackup) time.Duration {
+	if clusterBackup.Status.StartTime == nil {
+		return 0
+	}
+	return time.Since(clusterBackup.Status.StartTime.Time)
+}
+
+// backupSchedule returns the parsed spec.schedule of clusterBackup. An
+// invalid schedule never fires; Reconcile reports it on the Ready condition.
+func backupSchedule(clusterBackup *backupv1alpha1.ClusterBackup) backup.Schedule {
+	schedule, err := backup.ParseSchedule(clusterBackup.Spec.Schedule)
+	if err != nil {
+		return neverSchedule{}
+	}
+	return schedule
+}
+
+// neverSchedule is a schedule that never fires.
+type neverSchedule struct{}
+
+func (neverSchedule) Next(time.Time) time.Time {
+	return time.Time{}
+}
+
+// schedulePeriod returns the interval between the first two runs of
+// schedule after from, or 0 when it does not run twice.
+func schedulePeriod(schedule backup.Schedule, from time.Time) time.Duration {
+	first := schedule.Next(from)
+	if first.IsZero() {
+		return 0
+	}
+	second := schedule.Next(first)
+	if second.IsZero() {
+		return 0
+	}
+	return second.Sub(first)
+}
+
+// performBackup executes the backup operation, filling in the settings the
+// ClusterBackup leaves unset from the operator configuration
+func (r *ClusterBackupReconciler) performBackup(ctx context.Context, clusterBackup *backupv1alpha1.ClusterBackup, config *backupv1alpha1.BackupOperatorConfigSpec) (*backup.BackupResult, error) {
+	log := logf.FromContext(ctx)
+
+	// Nothing updates the object between here and applyLifecycle, so
+	// retention also sees the merged policy settings
+	opts, err := r.backupOptions(ctx, clusterBackup, config)
+	if err != nil {
+		return nil, err
+	}
+	// A shutdown mid-run keeps what was collected instead of discarding it
+	opts.PartialOnCancel = true
+
+	if err := storagePathError(clusterBackup, config); err != nil {
+		return nil, err
+	}
+	storagePath := storagePathFor(clusterBackup, config)
+
+	bm, err := targetManager(ctx, r.Client, r.BackupManager, clusterBackup.Namespace,
+		clusterBackup.Spec.ClusterRef, clusterBackup.Spec.Impersonate)
+	if err != nil {
+		return nil, err
+	}
+
+	var preActions, postActions []backupv1alpha1.BackupJobAction
+	if actions := clusterBackup.Spec.Actions; actions != nil {
+		preActions, postActions = actions.Pre, actions.Post
+	}
+
+	var result *backup.BackupResult
+	err = r.runBackupActions(ctx, clusterBackup, "pre", preActions)
+	if err == nil {
+		log.Info("Starting backup operation", "options", opts)
+		result, err = bm.CreateBackup(ctx, storagePath, opts)
+	}
+	if err == nil && result.Partial {
+		// Undo what the pre actions started even though the operator is
+		// shutting down
+		ctx = context.WithoutCancel(ctx)
+	}
+	// Post actions run after failed backups too, so they can undo what the
+	// pre actions started
+	if postErr := r.runBackupActions(ctx, clusterBackup, "post", postActions); postErr != nil {
+		if err != nil {
+			log.Error(postErr, "Post-backup actions failed after the backup failed")
+			return nil, err
+		}
+		return nil, postErr
+	}
+	return result, err
+}
+
+// handleEstimate estimates the size of the backup described by
+// clusterBackup and records it in status.estimate and the Estimated
+// condition.
+func (r *ClusterBackupReconciler) handleEstimate(ctx context.Context, clusterBackup *backupv1alpha1.ClusterBackup, config *backupv1alpha1.BackupOperatorConfigSpec) error {
+	if estimate := clusterBackup.Status.Estimate; estimate != nil && estimate.ObservedGeneration == clusterBackup.Generation {
+		return nil
+	}
+	log := logf.FromContext(ctx)
+
+	opts, err := r.backupOptions(ctx, clusterBackup, config)
+	var bm *backup.BackupManager
+	if err == nil {
+		bm, err = targetManager(ctx, r.Client, r.BackupManager, clusterBackup.Namespace,
+			clusterBackup.Spec.ClusterRef, clusterBackup.Spec.Impersonate)
+	}
+	var estimate *backup.BackupEstimate
+	if err == nil {
+		log.Info("Estimating backup", "options", opts)
+		estimate, err = bm.EstimateBackup(ctx, opts)
+	}
+	if err != nil {
+		log.Error(err, "Backup estimate failed")
+		backup.SetCondition(&clusterBackup.Status.Conditions, "Estimated", metav1.ConditionFalse, "EstimateFailed", err.Error())
+		if statusErr := r.Status().Update(ctx, clusterBackup); statusErr != nil {
+			log.Error(statusErr, "Failed to update status after estimate failure")
+		}
+		return err
+	}
+
+	clusterBackup.Status.Estimate = &backupv1alpha1.BackupEstimate{
+		ObservedGeneration: clusterBackup.Generation,
+		Time:               metav1.Now(),
+		ItemCount:          estimate.Items,
+		Bytes:              estimate.Bytes,
+		CompressedBytes:    estimate.CompressedBytes,
+		SkippedResources:   permissionGapNames(estimate.SkippedResources),
+	}
+	for _, resource := range estimate.Resources {
+		clusterBackup.Status.Estimate.ResourceTypes = append(clusterBackup.Status.Estimate.ResourceTypes, backupv1alpha1.ResourceEstimate{
+			Resource:  resource.GVR.GroupResource().String(),
+			ItemCount: resource.Items,
+			Bytes:     resource.Bytes,
+		})
+	}
+	backup.SetCondition(&clusterBackup.Status.Conditions, "Estimated", metav1.ConditionTrue, "EstimateCompleted",
+		fmt.Sprintf("A backup would hold %d resources in about %s (%s before compression)",
+			estimate.Items, formatBytes(estimate.CompressedBytes), formatBytes(estimate.Bytes)))
+	return r.Status().Update(ctx, clusterBackup)
+}
+
+// updateRunStatus writes the status of a finished backup run. The copy the
+// run started from is stale once the continuous controller has patched
+// status.continuous, so on a conflict the status is carried over to the
+// latest ClusterBackup and written again; otherwise the run would stay
+// Running and be executed a second time.
+func (r *ClusterBackupReconciler) updateRunStatus(ctx context.Context, clusterBackup *backupv1alpha1.ClusterBackup) error {
+	status := clusterBackup.Status.DeepCopy()
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		err := r.Status().Update(ctx, clusterBackup)
+		if !apierrors.IsConflict(err) {
+			return err
+		}
+		latest := &backupv1alpha1.ClusterBackup{}
+		if getErr := r.Get(ctx, client.ObjectKeyFromObject(clusterBackup), latest); getErr != nil {
+			return getErr
+		}
+		status.Continuous = latest.Status.Continuous
+		latest.Status = *status
+		*clusterBackup = *latest
+		return err
+	})
+}
+
+// slowestResources returns the first backup.MaxReportedSlowResources timings,
+// which are sorted slowest first, as reported in status.
+func slowestResources(timings []backup.ResourceTiming) []backupv1alpha1.ResourceTiming {
+	var slowest []backupv1alpha1.ResourceTiming
+	for _, timing := range timings[:min(len(timings), backup.MaxReportedSlowResources)] {
+		slowest = append(slowest, backupv1alpha1.ResourceTiming{
+			Resource:     timing.GVR.GroupResource().String(),
+			ItemCount:    timing.Items,
+			Duration:     metav1.Duration{Duration: timing.Duration},
+			ListDuration: metav1.Duration{Duration: timing.List},
+		})
+	}
+	return slowest
+}
+
+// permissionGapNames lists gaps as reported in status.
+func permissionGapNames(gaps []backup.PermissionGap) []string {
+	var names []string
+	for _, gap := range gaps {
+		names = append(names, gap.String())
+	}
+	return names
+}
+
+// formatBytes renders n bytes with a binary unit, e.g. "1.5 MiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// backupOptions merges the referenced BackupPolicy into clusterBackup and
+// returns the options of its backup runs
+func (r *ClusterBackupReconciler) backupOptions(ctx context.Context, clusterBackup *backupv1alpha1.ClusterBackup, config *backupv1alpha1.BackupOperatorConfigSpec) (backup.BackupOptions, error) {
+	if err := applyBackupPolicy(ctx, r.Client, clusterBackup); err != nil {
+		return backup.BackupOptions{}, err
+	}
+
+	opts, err := selectionOptions(clusterBackup, config)
+	if err != nil {
+		return backup.BackupOptions{}, err
+	}
+	if opts.ResourceTypes, err = scheduledResourceTypes(clusterBackup, opts.ResourceTypes); err != nil {
+		return backup.BackupOptions{}, err
+	}
+	opts.Compression = backup.Compression(clusterBackup.Spec.Compression)
+	if incremental := clusterBackup.Spec.Incremental; incremental != nil {
+		opts.Incremental = &backup.IncrementalOptions{}
+		if incremental.FullInterval != nil {
+			opts.Incremental.FullInterval = incremental.FullInterval.Duration
+		}
+	}
+	opts.ReplicaStoragePaths = replicaStoragePathsFor(clusterBackup, config)
+	opts.RunID = clusterBackup.Status.LastRunID
+	opts.MissingPermissionPolicy = backup.MissingPermissionSkip
+	if clusterBackup.Spec.MissingPermissionPolicy != "" {
+		opts.MissingPermissionPolicy = backup.MissingPermissionPolicy(clusterBackup.Spec.MissingPermissionPolicy)
+	}
+
+	if immutability := clusterBackup.Spec.Immutability; immutability != nil {
+		opts.Immutability = &backup.Immutability{LegalHold: immutability.LegalHold}
+		if immutability.RetentionDays != nil {
+			opts.Immutability.RetainFor = time.Duration(*immutability.RetentionDays) * 24 * time.Hour
+		}
+	}
+
+	if clusterBackup.Spec.Rotation != nil {
+		var previous time.Time
+		if clusterBackup.Status.LastBackupTime != nil {
+			previous = clusterBackup.Status.LastBackupTime.Time
+		}
+		opts.RotationTag = backup.RotationTagFor(time.Now(), previous)
+	}
+
+	concurrency := clusterBackup.Spec.Concurrency
+	if concurrency == nil {
+		concurrency = config.Concurrency
+	}
+	if concurrency != nil {
+		if concurrency.ResourceTypes != nil {
+			opts.ConcurrentResourceTypes = *concurrency.ResourceTypes
+		}
+		if concurrency.Namespaces != nil {
+			opts.ConcurrentNamespaces = *concurrency.Namespaces
+		}
+	}
+
+	opts.ScratchDir = clusterBackup.Spec.ScratchDir
+	if opts.ScratchDir == "" {
+		opts.ScratchDir = config.ScratchDir
+	}
+
+	pacing := clusterBackup.Spec.Pacing
+	if pacing == nil {
+		pacing = config.Pacing
+	}
+	if pacing != nil {
+		if pacing.PageSize != nil {
+			opts.ListPageSize = *pacing.PageSize
+		}
+		if pacing.PageInterval != nil {
+			opts.PageInterval = pacing.PageInterval.Duration
+		}
+	}
+
+	if opts.KeyWrappers, err = encryptionKeyWrappers(ctx, clusterBackup.Spec.Encryption); err != nil {
+		return backup.BackupOptions{}, err
+	}
+	if secretEncryption := clusterBackup.Spec.SecretEncryption; secretEncryption != nil {
+		opts.SecretEncryption = &backup.SecretEncryption{Format: backup.SecretFormat(secretEncryption.Format)}
+		if sealed := secretEncryption.SealedSecrets; sealed != nil {
+			opts.SecretEncryption.SealingCertificate = sealed.Certificate
+			opts.SecretEncryption.SealedSecretsNamespace = sealed.ControllerNamespace
+		}
+		if sops := secretEncryption.SOPS; sops != nil {
+			opts.SecretEncryption.AgeRecipients = sops.AgeRecipients
+		}
+	}
+
+	if clusterBackup.Spec.GitExport != nil {
+		export, err := gitOpsExport(ctx, r.Client, clusterBackup, config)
+		if err != nil {
+			return backup.BackupOptions{}, err
+		}
+		opts.Export = export
+	}
+	return opts, nil
+}
+
+// selectionOptions returns the options that decide which objects a
+// ClusterBackup reads, with its BackupPolicy already merged.
+func selectionOptions(clusterBackup *backupv1alpha1.ClusterBackup, config *backupv1alpha1.BackupOperatorConfigSpec) (backup.BackupOptions, error) {
 	includeClusterResources := true
 	if clusterBackup.Spec.IncludeClusterResources != nil {
 		includeClusterResources = *clusterBackup.Spec.IncludeClusterResources
 	}
 
 	opts := backup.BackupOptions{
-		IncludeNamespaces:       clusterBackup.Spec.IncludeNamespaces,
-		ExcludeNamespaces:       clusterBackup.Spec.ExcludeNamespaces,
-		IncludeClusterResources: includeClusterResources,
-		ResourceTypes:           clusterBackup.Spec.ResourceTypes,
+		IncludeNamespaces:                clusterBackup.Spec.IncludeNamespaces,
+		ExcludeNamespaces:                append(slices.Clone(clusterBackup.Spec.ExcludeNamespaces), config.ExcludeNamespaces...),
+		ExcludeNamespacePatterns:         slices.Clone(config.ExcludeNamespacePatterns),
+		IncludeClusterResources:          includeClusterResources,
+		ResourceTypes:                    slices.Clone(clusterBackup.Spec.ResourceTypes),
+		ExcludeGitOpsManaged:             clusterBackup.Spec.ExcludeGitOpsManaged,
+		IncludeGeneratedResources:        clusterBackup.Spec.IncludeGeneratedResources,
+		IncludeFinishedWorkloads:         clusterBackup.Spec.IncludeFinishedWorkloads,
+		SkipReissuableCertificateSecrets: clusterBackup.Spec.SkipReissuableCertificateSecrets,
+		IncludeReferencedResources:       clusterBackup.Spec.IncludeReferencedResources,
+	}
+	if clusterResources := clusterBackup.Spec.ClusterResources; clusterResources != nil {
+		opts.IncludeClusterResourceTypes = slices.Clone(clusterResources.Include)
+		opts.ExcludeClusterResourceTypes = slices.Clone(clusterResources.Exclude)
+	}
+
+	for _, entry := range clusterBackup.Spec.Items {
+		item, err := backup.ParseBackupItem(entry)
+		if err != nil {
+			return backup.BackupOptions{}, err
+		}
+		opts.Items = append(opts.Items, item)
+	}
+	if clusterBackup.Spec.Application != "" {
+		root, err := backup.ParseBackupItem(clusterBackup.Spec.Application)
+		if err != nil {
+			return backup.BackupOptions{}, err
+		}
+		opts.Application = &root
 	}
 
 	// If no specific resource types specified, use defaults
-	if len(opts.ResourceTypes) == 0 {
+	if len(opts.ResourceTypes) == 0 && len(opts.Items) == 0 && opts.Application == nil {
 		opts.ResourceTypes = backup.GetDefaultResourceTypes()
 	}
-
-	log.Info("Starting backup operation", "options", opts)
-
-	return r.BackupManager.CreateBackup(ctx, clusterBackup.Spec.StoragePath, opts)
+	// Resource types with their own cadence are selected even when the
+	// resource type filter leaves them out
+	for _, schedule := range clusterBackup.Spec.ResourceSchedules {
+		for _, resourceType := range schedule.ResourceTypes {
+			if !slices.ContainsFunc(opts.ResourceTypes, func(s string) bool { return strings.EqualFold(s, resourceType) }) {
+				opts.ResourceTypes = append(opts.ResourceTypes, resourceType)
+			}
+		}
+	}
+	return opts, nil
 }
 
-func (r *ClusterBackupReconciler) handleRestore(ctx context.Context, clusterBackup *backupv1alpha1.ClusterBackup) error {
+func (r *ClusterBackupReconciler) handleRestore(ctx context.Context, clusterBackup *backupv1alpha1.ClusterBackup, config *backupv1alpha1.BackupOperatorConfigSpec) error {
 	restoreSpec := clusterBackup.Spec.Restore
-	if restoreSpec == nil || restoreSpec.ArchiveName == "" {
+	if restoreSpec == nil || (restoreSource(restoreSpec) == "" && restoreSpec.PointInTime == nil) {
 		return nil
 	}
 
-	if clusterBackup.Status.LastRestoreArchive == restoreSpec.ArchiveName &&
+	archive := restoreArchiveLabel(restoreSpec)
+	if clusterBackup.Status.LastRestoreArchive == archive &&
 		clusterBackup.Status.LastRestoreObservedGeneration == clusterBackup.Generation {
 		return nil
 	}
 
+	runID := newRunID()
+	ctx = withRunID(ctx, runID)
+	clusterBackup.Status.LastRestoreRunID = runID
 	log := logf.FromContext(ctx)
-	log.Info("Restoring from archive", "archive", restoreSpec.ArchiveName)
+	log.Info("Restoring from archive", "archive", archive)
+	// Restores are audited in the location the archive is read from, or the
+	// primary location for archives downloaded from a URL
+	auditPath := storagePathFor(clusterBackup, config)
+	auditRestore := func(result, message string) {
+		r.audit(ctx, clusterBackup, config, []string{auditPath}, backup.AuditRecord{
+			Operation: backup.AuditOperationRestore, RunID: runID, Archive: archive, Result: result, Message: message,
+		})
+	}
+
+	keyWrappers, err := ageKeyWrappers(ctx, r.Client, clusterBackup.Namespace, restoreSpec.AgeIdentitySecretRef)
+	if err != nil {
+		clusterBackup.Status.RestoreMessage = fmt.Sprintf("Restore failed: %v", err)
+		backup.SetCondition(&clusterBackup.Status.Conditions, "Restored", metav1.ConditionFalse, "DecryptionKeyUnavailable", err.Error())
+		recordRestoreRun(clusterBackup.Namespace, clusterBackup.Name, "ClusterBackup", runID, "failure")
+		recordRunEvent(r.Recorder, clusterBackup, runID, corev1.EventTypeWarning, "RestoreFailed", "Restore run %s failed: %v", runID, err)
+		auditRestore("failure", err.Error())
+		if statusErr := r.Status().Update(ctx, clusterBackup); statusErr != nil {
+			log.Error(statusErr, "Failed to update restore status")
+		}
+		return err
+	}
+
+	opts := backup.RestoreOptions{
+		FailurePolicy:             backup.RestoreFailurePolicy(restoreSpec.FailurePolicy),
+		ExistingResourcePolicy:    backup.ExistingResourcePolicy(restoreSpec.ExistingResourcePolicy),
+		ExcludeGitOpsManaged:      restoreSpec.ExcludeGitOpsManaged,
+		IncludeGeneratedResources: restoreSpec.IncludeGeneratedResources,
+		HelmReleasePolicy:         backup.HelmReleasePolicy(restoreSpec.HelmReleasePolicy),
+		QuotaPolicy:               backup.QuotaPolicy(restoreSpec.QuotaPolicy),
+		ClusterMismatchPolicy:     backup.ClusterMismatchPolicy(restoreSpec.ClusterMismatchPolicy),
+		RemovedAPIPolicy:          backup.RemovedAPIPolicy(restoreSpec.RemovedAPIPolicy),
+		IgnoreWebhookFailures:     restoreSpec.IgnoreWebhookFailures,
+		KeyWrappers:               keyWrappers,
+		ArchiveSHA256:             restoreSpec.ArchiveSHA256,
+		PointInTime:               restorePointInTime(restoreSpec),
+		ScaleWorkloadsToZero:      restoreSpec.ScaleWorkloadsToZero,
+	}
+	opts.ApplyRate, opts.ApplyBurst = restoreThrottle(restoreSpec)
+	opts.FieldManager, opts.KeepConflictingFields = restoreFieldManager(restoreSpec)
+	opts.Callouts = restoreCallouts(restoreSpec)
+	opts.Quiesce = quiescedWorkloads(restoreSpec)
 
-	result, err := r.BackupManager.RestoreBackup(ctx, clusterBackup.Spec.StoragePath, restoreSpec.ArchiveName)
+	var storagePath string
+	if restoreSpec.ArchiveURL == "" {
+		storagePath = archiveStoragePath(r.BackupManager, clusterBackup, config, storagePathFor(clusterBackup, config), restoreSpec.ArchiveName)
+		auditPath = storagePath
+	}
+	bm, err := targetManager(ctx, r.Client, r.BackupManager, clusterBackup.Namespace,
+		restoreClusterRef(clusterBackup), restoreImpersonation(clusterBackup))
+	var result *backup.RestoreResult
+	if err == nil {
+		result, err = bm.RestoreBackup(ctx, storagePath, restoreSource(restoreSpec), opts)
+	}
 	if err != nil {
+		reason := "RestoreFailed"
+		if errors.Is(err, backup.ErrQuotaExceeded) {
+			reason = "QuotaExceeded"
+		}
+		if errors.Is(err, backup.ErrClusterMismatch) {
+			reason = "ClusterMismatch"
+		}
+		if errors.Is(err, backup.ErrAPIRemoved) {
+			reason = "RemovedAPI"
+		}
 		clusterBackup.Status.RestoreMessage = fmt.Sprintf("Restore failed: %v", err)
-		backup.SetCondition(&clusterBackup.Status.Conditions, "Restored", metav1.ConditionFalse, "RestoreFailed", err.Error())
+		// FailFast returns what was applied before the failing item
+		if result != nil {
+			clusterBackup.Status.LastRestoreSummary = restoreSummary(result)
+		}
+		backup.SetCondition(&clusterBackup.Status.Conditions, "Restored", metav1.ConditionFalse, reason, err.Error())
+		recordRestoreRun(clusterBackup.Namespace, clusterBackup.Name, "ClusterBackup", runID, "failure")
+		recordRunEvent(r.Recorder, clusterBackup, runID, corev1.EventTypeWarning, "RestoreFailed", "Restore run %s failed: %v", runID, err)
+		auditRestore("failure", err.Error())
 		if statusErr := r.Status().Update(ctx, clusterBackup); statusErr != nil {
 			log.Error(statusErr, "Failed to update status after restore failure")
 		}
@@ -214,11 +1369,31 @@ func (r *ClusterBackupReconciler) handleRestore(ctx context.Context, clusterBack
 
 	now := metav1.Now()
 	clusterBackup.Status.LastRestoreTime = &now
-	clusterBackup.Status.LastRestoreArchive = restoreSpec.ArchiveName
+	clusterBackup.Status.LastRestoreArchive = archive
 	clusterBackup.Status.LastRestoreResourceCount = result.ResourcesApplied
+	clusterBackup.Status.LastRestoreSummary = restoreSummary(result)
 	clusterBackup.Status.LastRestoreObservedGeneration = clusterBackup.Generation
-	clusterBackup.Status.RestoreMessage = fmt.Sprintf("Restored %d resources from %s", result.ResourcesApplied, restoreSpec.ArchiveName)
-	backup.SetCondition(&clusterBackup.Status.Conditions, "Restored", metav1.ConditionTrue, "RestoreCompleted", "Restore completed successfully")
+	if result.ClusterMismatch != "" {
+		recordRunEvent(r.Recorder, clusterBackup, runID, corev1.EventTypeWarning, "ClusterMismatch",
+			"Restore run %s: %s", runID, result.ClusterMismatch)
+	}
+	if result.Failed > 0 {
+		clusterBackup.Status.RestoreMessage = fmt.Sprintf("Restored %d resources from %s, %d failed: %v",
+			result.ResourcesApplied, archive, result.Failed, result.FailedItems[0])
+		backup.SetCondition(&clusterBackup.Status.Conditions, "Restored", metav1.ConditionFalse, "RestorePartiallyFailed",
+			fmt.Sprintf("%d resources failed to restore", result.Failed))
+		recordRestoreRun(clusterBackup.Namespace, clusterBackup.Name, "ClusterBackup", runID, "partial")
+		recordRunEvent(r.Recorder, clusterBackup, runID, corev1.EventTypeWarning, "RestorePartiallyFailed",
+			"Restore run %s: %s", runID, clusterBackup.Status.RestoreMessage)
+		auditRestore("partial", clusterBackup.Status.RestoreMessage)
+	} else {
+		clusterBackup.Status.RestoreMessage = fmt.Sprintf("Restored %d resources from %s", result.ResourcesApplied, archive)
+		backup.SetCondition(&clusterBackup.Status.Conditions, "Restored", metav1.ConditionTrue, "RestoreCompleted", "Restore completed successfully")
+		recordRestoreRun(clusterBackup.Namespace, clusterBackup.Name, "ClusterBackup", runID, "success")
+		recordRunEvent(r.Recorder, clusterBackup, runID, corev1.EventTypeNormal, "RestoreCompleted",
+			"Restore run %s: %s", runID, clusterBackup.Status.RestoreMessage)
+		auditRestore("success", clusterBackup.Status.RestoreMessage)
+	}
 
 	if err := r.Status().Update(ctx, clusterBackup); err != nil {
 		log.Error(err, "Failed to update status after successful restore")
@@ -228,18 +1403,330 @@ func (r *ClusterBackupReconciler) handleRestore(ctx context.Context, clusterBack
 	return nil
 }
 
+// restoreSource returns the archive a restore reads: its URL or its name in
+// the storage location.
+func restoreSource(spec *backupv1alpha1.RestoreOptions) string {
+	if spec.ArchiveURL != "" {
+		return spec.ArchiveURL
+	}
+	return spec.ArchiveName
+}
+
+// restoreArchiveLabel names the archive of a restore in status and logs,
+// without the signature of a pre-signed URL.
+func restoreArchiveLabel(spec *backupv1alpha1.RestoreOptions) string {
+	if spec.ArchiveURL != "" {
+		return backup.RedactArchiveURL(spec.ArchiveURL)
+	}
+	if spec.PointInTime != nil {
+		archive := spec.ArchiveName
+		if archive == "" {
+			archive = "the latest archive"
+		}
+		return fmt.Sprintf("%s at %s", archive, spec.PointInTime.UTC().Format(time.RFC3339))
+	}
+	return spec.ArchiveName
+}
+
+// restorePointInTime returns the time a point-in-time restore goes back to.
+func restorePointInTime(spec *backupv1alpha1.RestoreOptions) *time.Time {
+	if spec.PointInTime == nil {
+		return nil
+	}
+	return &spec.PointInTime.Time
+}
+
+// restoreThrottle returns the apply rate and burst of a restore, zero when
+// it is not throttled.
+func restoreThrottle(spec *backupv1alpha1.RestoreOptions) (float64, int) {
+	if spec.Throttle == nil {
+		return 0, 0
+	}
+	burst := 0
+	if spec.Throttle.Burst != nil {
+		burst = int(*spec.Throttle.Burst)
+	}
+	return float64(spec.Throttle.ObjectsPerSecond), burst
+}
+
+// restoreFieldManager returns the field manager of a restore and whether it
+// must leave fields owned by other managers alone.
+func restoreFieldManager(spec *backupv1alpha1.RestoreOptions) (string, bool) {
+	return spec.FieldManager, spec.ForceConflicts != nil && !*spec.ForceConflicts
+}
+
+// restoreCallouts returns the call-outs a restore passes every object
+// through.
+func restoreCallouts(spec *backupv1alpha1.RestoreOptions) []backup.RestoreCallout {
+	var callouts []backup.RestoreCallout
+	for _, callout := range spec.Callouts {
+		c := backup.RestoreCallout{
+			Name:          callout.Name,
+			URL:           callout.URL,
+			Resources:     callout.Resources,
+			FailurePolicy: backup.CalloutFailurePolicy(callout.FailurePolicy),
+		}
+		if callout.TimeoutSeconds != nil {
+			c.Timeout = time.Duration(*callout.TimeoutSeconds) * time.Second
+		}
+		callouts = append(callouts, c)
+	}
+	return callouts
+}
+
+// scratchUsage reports the staging space a backup run used.
+func scratchUsage(result *backup.BackupResult) *backupv1alpha1.ScratchUsage {
+	return &backupv1alpha1.ScratchUsage{
+		Directory:   result.ScratchDir,
+		Peak:        resource.NewQuantity(result.ScratchBytes, resource.BinarySI),
+		Recommended: resource.NewQuantity(result.ScratchBytes+result.ScratchBytes/2, resource.BinarySI),
+	}
+}
+
+// quiescedWorkloads returns the workloads a restore scales down while it
+// runs.
+func quiescedWorkloads(spec *backupv1alpha1.RestoreOptions) []backup.Workload {
+	var workloads []backup.Workload
+	for _, w := range spec.Quiesce {
+		workloads = append(workloads, backup.Workload{Kind: w.Kind, Namespace: w.Namespace, Name: w.Name})
+	}
+	return workloads
+}
+
+// restoreSummary converts a restore result into its status representation
+func restoreSummary(result *backup.RestoreResult) *backupv1alpha1.RestoreSummary {
+	summary := &backupv1alpha1.RestoreSummary{
+		RestoreCounts: restoreCounts(result.RestoreCounts),
+	}
+
+	resources := make([]string, 0, len(result.ByResource))
+	for resource := range result.ByResource {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+	for _, resource := range resources {
+		summary.ResourceTypes = append(summary.ResourceTypes, backupv1alpha1.ResourceRestoreCounts{
+			Resource:      resource,
+			RestoreCounts: restoreCounts(*result.ByResource[resource]),
+		})
+	}
+
+	for _, failure := range result.FailedItems {
+		summary.FailedItems = append(summary.FailedItems, backupv1alpha1.RestoreFailure{
+			Resource:  failure.GVR.GroupResource().String(),
+			Namespace: failure.Namespace,
+			Name:      failure.Name,
+			Reason:    failure.Err.Error(),
+		})
+	}
+
+	for _, violation := range result.QuotaViolations {
+		summary.QuotaWarnings = append(summary.QuotaWarnings, violation.String())
+	}
+	summary.HelmRollbackCommands = result.HelmRollbackCommands
+	summary.ClusterWarning = result.ClusterMismatch
+	summary.APIFindings = apiVersionFindings(result.APIFindings)
+	summary.BaseArchive = result.BaseArchive
+	summary.ReplayedChanges = result.ReplayedChanges
+
+	return summary
+}
+
+// apiVersionFindings converts API version findings into their status
+// representation.
+func apiVersionFindings(findings []backup.APIFinding) []backupv1alpha1.APIVersionFinding {
+	var converted []backupv1alpha1.APIVersionFinding
+	for _, finding := range findings {
+		converted = append(converted, backupv1alpha1.APIVersionFinding{
+			APIVersion:       finding.GVR.GroupVersion().String(),
+			Kind:             finding.Kind,
+			Namespace:        finding.Namespace,
+			Name:             finding.Name,
+			Removed:          finding.Removed,
+			TargetAPIVersion: finding.Target.String(),
+			Decision:         string(finding.Decision),
+		})
+	}
+	return converted
+}
+
+func restoreCounts(counts backup.RestoreCounts) backupv1alpha1.RestoreCounts {
+	return backupv1alpha1.RestoreCounts{
+		Created: counts.Created,
+		Updated: counts.Updated,
+		Skipped: counts.Skipped,
+		Failed:  counts.Failed,
+	}
+}
+
+// targetManager returns the BackupManager for the cluster ref points at,
+// or the operator's own cluster when it is unset, acting as impersonate
+// when that is set. Both are resolved in namespace.
+func targetManager(ctx context.Context, c client.Reader, bm *backup.BackupManager, namespace string,
+	ref *backupv1alpha1.ClusterReference, impersonate *backupv1alpha1.Impersonation) (*backup.BackupManager, error) {
+	if ref != nil {
+		var err error
+		if bm, err = remoteClusterManager(ctx, c, bm, namespace, ref); err != nil {
+			return nil, err
+		}
+	}
+	return impersonatedManager(bm, namespace, impersonate)
+}
+
+// restoreClusterRef returns the cluster inline restores of clusterBackup
+// apply to.
+func restoreClusterRef(clusterBackup *backupv1alpha1.ClusterBackup) *backupv1alpha1.ClusterReference {
+	if restore := clusterBackup.Spec.Restore; restore != nil && restore.ClusterRef != nil {
+		return restore.ClusterRef
+	}
+	return clusterBackup.Spec.ClusterRef
+}
+
+// remoteClusterManager builds a BackupManager from the kubeconfig Secret ref
+// points at.
+func remoteClusterManager(ctx context.Context, c client.Reader, bm *backup.BackupManager, namespace string,
+	ref *backupv1alpha1.ClusterReference) (*backup.BackupManager, error) {
+	key := ref.Key
+	if key == "" {
+		key = "kubeconfig"
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig secret %q: %w", ref.Name, err)
+	}
+	kubeconfig, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("kubeconfig secret %q has no key %q", ref.Name, key)
+	}
+	remote, err := bm.ForKubeconfig(kubeconfig, ref.Context)
+	if err != nil {
+		return nil, fmt.Errorf("kubeconfig secret %q: %w", ref.Name, err)
+	}
+	return remote, nil
+}
+
+// encryptionKeyWrappers returns the key wrappers archives are encrypted
+// with, none without encryption.
+func encryptionKeyWrappers(ctx context.Context, encryption *backupv1alpha1.BackupEncryption) ([]backup.KeyWrapper, error) {
+	if encryption == nil {
+		return nil, nil
+	}
+	var wrappers []backup.KeyWrapper
+	if encryption.KMS != nil {
+		wrapper, err := backup.NewKMSKeyWrapper(ctx, encryption.KMS.Provider, encryption.KMS.KeyID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up KMS encryption: %w", err)
+		}
+		wrappers = append(wrappers, wrapper)
+	}
+	if len(encryption.AgeRecipients) > 0 {
+		ageWrappers, err := backup.NewAgeKeyWrappers(encryption.AgeRecipients)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up age encryption: %w", err)
+		}
+		wrappers = append(wrappers, ageWrappers...)
+	}
+	return wrappers, nil
+}
+
+// ageKeyWrappers loads the age identities held by the referenced Secret.
+// KMS-wrapped keys need no configuration and are resolved from the archive.
+func ageKeyWrappers(ctx context.Context, c client.Client, namespace string, ref *backupv1alpha1.SecretKeyReference) ([]backup.KeyWrapper, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	key := ref.Key
+	if key == "" {
+		key = "identity"
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get age identity secret %q: %w", ref.Name, err)
+	}
+	identities, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("age identity secret %q has no key %q", ref.Name, key)
+	}
+	wrapper, err := backup.NewAgeIdentityKeyWrapper(string(identities))
+	if err != nil {
+		return nil, err
+	}
+	return []backup.KeyWrapper{wrapper}, nil
+}
+
+// gitOpsExport converts spec.gitExport into export options, reading the git
+// credentials from the referenced Secret.
+func gitOpsExport(ctx context.Context, c client.Client, clusterBackup *backupv1alpha1.ClusterBackup,
+	config *backupv1alpha1.BackupOperatorConfigSpec) (*backup.GitOpsExport, error) {
+	spec := clusterBackup.Spec.GitExport
+	export := &backup.GitOpsExport{
+		Path:           spec.Path,
+		IncludeSecrets: spec.IncludeSecrets,
+	}
+	if export.Path == "" {
+		export.Path = clusterBackup.Name
+	}
+	vars := storagePathVars(clusterBackup, config)
+	vars.Date = time.Now().UTC().Format(time.DateOnly)
+	path, err := backup.ExpandStoragePath(export.Path, vars)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gitExport.path: %w", err)
+	}
+	export.Path = path
+	if spec.Repository == nil {
+		return export, nil
+	}
+
+	export.Repository = &backup.GitRepository{URL: spec.Repository.URL, Branch: spec.Repository.Branch}
+	if ref := spec.Repository.CredentialsSecretRef; ref != nil {
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, client.ObjectKey{Namespace: clusterBackup.Namespace, Name: ref.Name}, secret); err != nil {
+			return nil, fmt.Errorf("failed to get git credentials secret %q: %w", ref.Name, err)
+		}
+		export.Repository.Credentials = &backup.GitCredentials{
+			Username:      string(secret.Data["username"]),
+			Password:      string(secret.Data["password"]),
+			SSHPrivateKey: secret.Data["identity"],
+			KnownHosts:    secret.Data["known_hosts"],
+		}
+	}
+	return export, nil
+}
+
 // handleDeletion handles cleanup when the ClusterBackup is being deleted
-func (r *ClusterBackupReconciler) handleDeletion(ctx context.Context, clusterBackup *backupv1alpha1.ClusterBackup) (ctrl.Result, error) {
+func (r *ClusterBackupReconciler) handleDeletion(ctx context.Context, clusterBackup *backupv1alpha1.ClusterBackup, config *backupv1alpha1.BackupOperatorConfigSpec) (ctrl.Result, error) {
 	log := logf.FromContext(ctx)
 
+	deleteBackupMetrics(clusterBackup.Namespace, clusterBackup.Name)
+
 	if controllerutil.ContainsFinalizer(clusterBackup, backupFinalizer) {
 		// If configured, remove archives created by this ClusterBackup
 		if clusterBackup.Spec.DeleteOnDelete != nil && *clusterBackup.Spec.DeleteOnDelete {
-			log.Info("Deleting archives for ClusterBackup", "name", clusterBackup.Name, "storagePath", clusterBackup.Spec.StoragePath)
-			// Attempt to delete all archives in the storage path by setting maxArchives=0
-			zero := 0
-			if err := r.BackupManager.CleanupArchives(clusterBackup.Spec.StoragePath, nil, &zero); err != nil {
-				log.Error(err, "Failed to delete archives for ClusterBackup", "name", clusterBackup.Name)
+			locations := storageLocationsFor(clusterBackup, config)
+			if coldStoragePath := coldStoragePathFor(clusterBackup, config); coldStoragePath != "" {
+				locations = append(locations, coldStoragePath)
+			}
+			// Archives are only deleted once no restore reads any of them
+			for _, storagePath := range locations {
+				if referenced := r.BackupManager.ReferencedArchives(storagePath); len(referenced) > 0 {
+					log.Info("Waiting for restores to release archives before deleting them",
+						"storagePath", storagePath, "archives", referenced)
+					return ctrl.Result{RequeueAfter: referencedArchivesRequeue}, nil
+				}
+			}
+			for _, storagePath := range locations {
+				log.Info("Deleting archives for ClusterBackup", "name", clusterBackup.Name, "storagePath", storagePath)
+				// Attempt to delete all archives in the storage path by setting maxArchives=0
+				zero := 0
+				removed, err := r.BackupManager.CleanupArchives(storagePath, nil, &zero)
+				r.auditDeleted(ctx, clusterBackup, config, storagePath, removed)
+				if errors.Is(err, backup.ErrArchiveImmutable) {
+					log.Info("Leaving locked archives in place", "name", clusterBackup.Name, "storagePath", storagePath, "reason", err.Error())
+				} else if err != nil {
+					log.Error(err, "Failed to delete archives for ClusterBackup", "name", clusterBackup.Name, "storagePath", storagePath)
+				}
 			}
 		}
 
@@ -253,10 +1740,36 @@ func (r *ClusterBackupReconciler) handleDeletion(ctx context.Context, clusterBac
 	return ctrl.Result{}, nil
 }
 
+// audit records an operation run for clusterBackup in the audit log of
+// locations.
+func (r *ClusterBackupReconciler) audit(ctx context.Context, clusterBackup *backupv1alpha1.ClusterBackup, config *backupv1alpha1.BackupOperatorConfigSpec,
+	locations []string, record backup.AuditRecord) {
+	record.Resource = auditResource("ClusterBackup", clusterBackup)
+	switch record.Operation {
+	case backup.AuditOperationBackup:
+		record.User = impersonatedUser(clusterBackup.Namespace, clusterBackup.Spec.Impersonate)
+	case backup.AuditOperationRestore:
+		record.User = impersonatedUser(clusterBackup.Namespace, restoreImpersonation(clusterBackup))
+	}
+	recordAudit(ctx, r.BackupManager, r.Recorder, config, clusterBackup, locations, record)
+}
+
+// auditDeleted records each archive removed from storagePath by retention,
+// rotation or deleteOnDelete.
+func (r *ClusterBackupReconciler) auditDeleted(ctx context.Context, clusterBackup *backupv1alpha1.ClusterBackup, config *backupv1alpha1.BackupOperatorConfigSpec,
+	storagePath string, removed []string) {
+	for _, archive := range removed {
+		r.audit(ctx, clusterBackup, config, []string{storagePath}, backup.AuditRecord{
+			Operation: backup.AuditOperationDelete, RunID: clusterBackup.Status.LastRunID, Archive: archive, Result: "success",
+		})
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
-func (r *ClusterBackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *ClusterBackupReconciler) SetupWithManager(mgr ctrl.Manager, options Options) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&backupv1alpha1.ClusterBackup{}).
 		Named("clusterbackup").
+		WithOptions(options.forController("clusterbackup")).
 		Complete(r)
 }
@@ -18,16 +18,29 @@ package controller
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
 )
 
 var _ = Describe("ClusterBackup Controller", func() {
@@ -81,4 +94,528 @@ var _ = Describe("ClusterBackup Controller", func() {
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
 	})
+
+	Context("When evaluating backup staleness", func() {
+		newScheduledBackup := func(created time.Time, lastSuccess *time.Time) *backupv1alpha1.ClusterBackup {
+			cb := &backupv1alpha1.ClusterBackup{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)},
+				Spec:       backupv1alpha1.ClusterBackupSpec{Schedule: "1h"},
+			}
+			if lastSuccess != nil {
+				t := metav1.NewTime(*lastSuccess)
+				cb.Status.LastBackupTime = &t
+			}
+			return cb
+		}
+
+		It("should report a recent backup as not stale", func() {
+			now := time.Now()
+			last := now.Add(-30 * time.Minute)
+			cb := newScheduledBackup(now.Add(-24*time.Hour), &last)
+
+			reconciler := &ClusterBackupReconciler{}
+			changed, untilStale := reconciler.setStaleCondition(cb, nil, now)
+			Expect(changed).To(BeTrue())
+			Expect(untilStale).To(Equal(90 * time.Minute))
+			Expect(meta.IsStatusConditionFalse(cb.Status.Conditions, "Stale")).To(BeTrue())
+		})
+
+		It("should flag a backup older than the threshold as stale", func() {
+			now := time.Now()
+			last := now.Add(-3 * time.Hour)
+			cb := newScheduledBackup(now.Add(-24*time.Hour), &last)
+
+			reconciler := &ClusterBackupReconciler{StaleThreshold: 2}
+			reconciler.setStaleCondition(cb, nil, now)
+			Expect(meta.IsStatusConditionTrue(cb.Status.Conditions, "Stale")).To(BeTrue())
+		})
+
+		It("should measure from creation before the first success", func() {
+			now := time.Now()
+			cb := newScheduledBackup(now.Add(-5*time.Hour), nil)
+
+			reconciler := &ClusterBackupReconciler{}
+			reconciler.setStaleCondition(cb, nil, now)
+			Expect(meta.IsStatusConditionTrue(cb.Status.Conditions, "Stale")).To(BeTrue())
+		})
+	})
+
+	Context("Resource schedules", func() {
+		It("should follow a cron schedule for the remaining resource types", func() {
+			now := time.Date(2025, time.January, 3, 2, 0, 0, 0, time.UTC)
+			cb := &backupv1alpha1.ClusterBackup{Spec: backupv1alpha1.ClusterBackupSpec{
+				Schedule:      "0 2 * * *",
+				ResourceTypes: []string{"Deployment", "Secret"},
+				ResourceSchedules: []backupv1alpha1.ResourceSchedule{
+					{ResourceTypes: []string{"Secret"}, Interval: metav1.Duration{Duration: 6 * time.Hour}},
+				},
+			}}
+			startResourceSchedules(cb, now, false)
+			finishResourceSchedules(cb, metav1.NewTime(now.Add(5*time.Minute)))
+			Expect(cb.Status.ResourceSchedules[0].NextRunTime.Time).To(BeTemporally("==", now.Add(24*time.Hour)))
+			Expect(cb.Status.ResourceSchedules[1].NextRunTime.Time).To(BeTemporally("==", now.Add(6*time.Hour+5*time.Minute)))
+
+			due, wait := nextResourceSchedule(cb, now.Add(time.Hour))
+			Expect(due).To(BeFalse())
+			Expect(wait).To(Equal(5*time.Hour + 5*time.Minute))
+		})
+
+		It("should run only the cadences that are due", func() {
+			now := time.Now()
+			cb := &backupv1alpha1.ClusterBackup{Spec: backupv1alpha1.ClusterBackupSpec{
+				Schedule:      "@daily",
+				ResourceTypes: []string{"Deployment", "Secret"},
+				ResourceSchedules: []backupv1alpha1.ResourceSchedule{
+					{ResourceTypes: []string{"Secret", "ConfigMap"}, Interval: metav1.Duration{Duration: time.Hour}},
+				},
+			}}
+
+			due, _ := nextResourceSchedule(cb, now)
+			Expect(due).To(BeTrue())
+			startResourceSchedules(cb, now, false)
+			selected, err := selectionOptions(cb, &backupv1alpha1.BackupOperatorConfigSpec{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(scheduledResourceTypes(cb, selected.ResourceTypes)).To(ConsistOf("Deployment", "Secret", "ConfigMap"))
+			finishResourceSchedules(cb, metav1.NewTime(now))
+
+			due, wait := nextResourceSchedule(cb, now.Add(30*time.Minute))
+			Expect(due).To(BeFalse())
+			Expect(wait).To(Equal(30 * time.Minute))
+
+			later := now.Add(2 * time.Hour)
+			due, _ = nextResourceSchedule(cb, later)
+			Expect(due).To(BeTrue())
+			startResourceSchedules(cb, later, false)
+			Expect(scheduledResourceTypes(cb, selected.ResourceTypes)).To(ConsistOf("Secret", "ConfigMap"))
+			finishResourceSchedules(cb, metav1.NewTime(later))
+			Expect(cb.Status.ResourceSchedules).To(HaveLen(2))
+			Expect(cb.Status.ResourceSchedules[0].LastRunTime.Time).To(BeTemporally("==", now))
+			Expect(cb.Status.ResourceSchedules[1].NextRunTime.Time).To(BeTemporally("==", later.Add(time.Hour)))
+		})
+	})
+
+	Context("Schedule", func() {
+		It("should run again once the schedule period has passed", func() {
+			last := metav1.NewTime(time.Now().Add(-2 * time.Hour).Truncate(time.Second))
+			cb := &backupv1alpha1.ClusterBackup{
+				Spec:   backupv1alpha1.ClusterBackupSpec{Schedule: "24h"},
+				Status: backupv1alpha1.ClusterBackupStatus{LastScheduleTime: &last},
+			}
+			due, wait := scheduleDue(cb, last.Add(time.Hour))
+			Expect(due).To(BeFalse())
+			Expect(wait).To(Equal(23 * time.Hour))
+			due, _ = scheduleDue(cb, last.Add(24*time.Hour))
+			Expect(due).To(BeTrue())
+
+			setNextScheduleTime(cb, last.Add(time.Hour))
+			Expect(cb.Status.NextScheduleTime.Time).To(BeTemporally("==", last.Add(24*time.Hour)))
+
+			cb.Spec.Schedule = ""
+			setNextScheduleTime(cb, last.Add(time.Hour))
+			Expect(cb.Status.NextScheduleTime).To(BeNil())
+		})
+
+		It("should run cron schedules at the times they match", func() {
+			last := metav1.NewTime(time.Date(2025, time.January, 3, 2, 0, 0, 0, time.UTC))
+			cb := &backupv1alpha1.ClusterBackup{
+				Spec:   backupv1alpha1.ClusterBackupSpec{Schedule: "0 2 * * *"},
+				Status: backupv1alpha1.ClusterBackupStatus{LastScheduleTime: &last},
+			}
+			due, wait := scheduleDue(cb, last.Add(time.Hour))
+			Expect(due).To(BeFalse())
+			Expect(wait).To(Equal(23 * time.Hour))
+			due, _ = scheduleDue(cb, last.Add(24*time.Hour))
+			Expect(due).To(BeTrue())
+
+			setNextScheduleTime(cb, last.Add(time.Hour))
+			Expect(cb.Status.NextScheduleTime.Time).To(BeTemporally("==", last.Add(24*time.Hour)))
+
+			// Runs that were missed while the operator was down run once
+			due, _ = scheduleDue(cb, last.Add(72*time.Hour))
+			Expect(due).To(BeTrue())
+
+			cb.Spec.Schedule = "@monthly"
+			setNextScheduleTime(cb, last.Add(time.Hour))
+			Expect(cb.Status.NextScheduleTime.Time).To(BeTemporally("==", time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)))
+
+			cb.Spec.Schedule = "0 0 30 2 *"
+			due, wait = scheduleDue(cb, last.Add(time.Hour))
+			Expect(due).To(BeFalse())
+			Expect(wait).To(BeZero())
+			setNextScheduleTime(cb, last.Add(time.Hour))
+			Expect(cb.Status.NextScheduleTime).To(BeNil())
+		})
+
+		It("should measure staleness by the gap between cron runs", func() {
+			lastSuccess := time.Date(2025, time.January, 3, 2, 0, 0, 0, time.UTC)
+			cb := &backupv1alpha1.ClusterBackup{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(lastSuccess.Add(-time.Hour))},
+				Spec:       backupv1alpha1.ClusterBackupSpec{Schedule: "0 2 * * *"},
+				Status:     backupv1alpha1.ClusterBackupStatus{LastBackupTime: &metav1.Time{Time: lastSuccess}},
+			}
+			reconciler := &ClusterBackupReconciler{StaleThreshold: 2}
+			_, untilStale := reconciler.setStaleCondition(cb, nil, lastSuccess.Add(time.Hour))
+			Expect(untilStale).To(Equal(47 * time.Hour))
+		})
+	})
+
+	Context("Run history", func() {
+		It("should keep the newest runs up to the history limit", func() {
+			limit := int32(2)
+			cb := &backupv1alpha1.ClusterBackup{Spec: backupv1alpha1.ClusterBackupSpec{HistoryLimit: &limit}}
+			for _, runID := range []string{"first", "second", "third"} {
+				recordRunHistory(cb, backupv1alpha1.BackupRunRecord{RunID: runID, Outcome: "Succeeded"})
+			}
+			recordRunHistory(cb, backupv1alpha1.BackupRunRecord{
+				RunID: "fourth", Outcome: "Failed", Error: strings.Repeat("é", maxHistoryErrorLength),
+			})
+
+			Expect(cb.Status.History).To(HaveLen(2))
+			Expect(cb.Status.History[0].RunID).To(Equal("fourth"))
+			Expect(cb.Status.History[1].RunID).To(Equal("third"))
+			Expect(len(cb.Status.History[0].Error)).To(BeNumerically("<=", maxHistoryErrorLength))
+			Expect(utf8.ValidString(cb.Status.History[0].Error)).To(BeTrue())
+		})
+	})
+
+	Context("BackupRuns", func() {
+		It("should record each run and prune the oldest beyond the history limit", func() {
+			limit := int32(1)
+			cb := &backupv1alpha1.ClusterBackup{
+				ObjectMeta: metav1.ObjectMeta{Name: "backup-runs", Namespace: "default"},
+				Spec:       backupv1alpha1.ClusterBackupSpec{HistoryLimit: &limit},
+			}
+			Expect(k8sClient.Create(ctx, cb)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, cb)).To(Succeed())
+			})
+			reconciler := &ClusterBackupReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+
+			for _, runID := range []string{"11111111-aaaa", "22222222-bbbb"} {
+				cb.Status.LastRunID = runID
+				cb.Status.LastRunTrigger = backupv1alpha1.RunTriggerManual
+				reconciler.saveBackupRun(ctx, cb, backupv1alpha1.BackupRunStatus{Phase: backupv1alpha1.BackupRunPhaseRunning})
+				now := metav1.Now()
+				reconciler.finishBackupRun(ctx, cb, backupv1alpha1.BackupRunStatus{
+					Phase: backupv1alpha1.BackupRunPhaseSucceeded, CompletionTime: &now, Archive: runID + ".tar.gz",
+				})
+			}
+
+			runs := &backupv1alpha1.BackupRunList{}
+			Expect(k8sClient.List(ctx, runs, client.InNamespace("default"),
+				client.MatchingLabels{clusterBackupLabel: cb.Name})).To(Succeed())
+			Expect(runs.Items).To(HaveLen(1))
+			run := runs.Items[0]
+			Expect(run.Name).To(Equal("backup-runs-22222222"))
+			Expect(run.Spec.Trigger).To(Equal(backupv1alpha1.RunTriggerManual))
+			Expect(run.Status.Phase).To(Equal(backupv1alpha1.BackupRunPhaseSucceeded))
+			Expect(run.Status.Archive).To(Equal("22222222-bbbb.tar.gz"))
+			Expect(metav1.IsControlledBy(&run, cb)).To(BeTrue())
+		})
+	})
+
+	Context("Degraded condition", func() {
+		It("should turn Degraded once the failures in a row reach the threshold", func() {
+			cb := &backupv1alpha1.ClusterBackup{ObjectMeta: metav1.ObjectMeta{Name: "degraded", Namespace: "default"}}
+			reconciler := &ClusterBackupReconciler{DegradedThreshold: 2}
+
+			cb.Status.ConsecutiveFailures = 1
+			reconciler.setDegradedCondition(cb, nil)
+			Expect(meta.IsStatusConditionFalse(cb.Status.Conditions, "Degraded")).To(BeTrue())
+			Expect(meta.FindStatusCondition(cb.Status.Conditions, "Degraded").Reason).To(Equal("BelowFailureThreshold"))
+
+			cb.Status.ConsecutiveFailures = 2
+			reconciler.setDegradedCondition(cb, nil)
+			Expect(meta.IsStatusConditionTrue(cb.Status.Conditions, "Degraded")).To(BeTrue())
+
+			threshold := int32(5)
+			reconciler.setDegradedCondition(cb, &backupv1alpha1.BackupOperatorConfigSpec{
+				Metrics: &backupv1alpha1.MetricsOptions{DegradedFailureThreshold: &threshold},
+			})
+			Expect(meta.IsStatusConditionFalse(cb.Status.Conditions, "Degraded")).To(BeTrue())
+
+			cb.Status.ConsecutiveFailures = 0
+			reconciler.setDegradedCondition(cb, nil)
+			Expect(meta.FindStatusCondition(cb.Status.Conditions, "Degraded").Reason).To(Equal("BackupSucceeded"))
+		})
+	})
+
+	Context("Retry policy", func() {
+		It("should back off exponentially until the retries are used up", func() {
+			cb := &backupv1alpha1.ClusterBackup{Spec: backupv1alpha1.ClusterBackupSpec{
+				RetryPolicy: &backupv1alpha1.BackupRetryPolicy{MaxRetries: 2, Backoff: &metav1.Duration{Duration: 40 * time.Minute}},
+			}}
+			_, retry := nextRetry(&backupv1alpha1.ClusterBackup{Status: backupv1alpha1.ClusterBackupStatus{Attempts: 1}})
+			Expect(retry).To(BeFalse())
+
+			cb.Status.Attempts = 1
+			delay, retry := nextRetry(cb)
+			Expect(retry).To(BeTrue())
+			Expect(delay).To(Equal(40 * time.Minute))
+			cb.Status.Attempts = 2
+			delay, retry = nextRetry(cb)
+			Expect(retry).To(BeTrue())
+			Expect(delay).To(Equal(time.Hour))
+			cb.Status.Attempts = 3
+			_, retry = nextRetry(cb)
+			Expect(retry).To(BeFalse())
+
+			now := time.Now()
+			next := metav1.NewTime(now.Add(time.Minute))
+			cb.Status.NextRetryTime = &next
+			due, wait := retryDue(cb, now)
+			Expect(due).To(BeFalse())
+			Expect(wait).To(Equal(time.Minute))
+			due, _ = retryDue(cb, now.Add(time.Minute))
+			Expect(due).To(BeTrue())
+		})
+	})
+
+	Context("Backup now trigger", func() {
+		It("should start one manual run per trigger value", func() {
+			now := time.Now()
+			cb := &backupv1alpha1.ClusterBackup{Spec: backupv1alpha1.ClusterBackupSpec{
+				Schedule:      "@daily",
+				ResourceTypes: []string{"Deployment"},
+				ResourceSchedules: []backupv1alpha1.ResourceSchedule{
+					{ResourceTypes: []string{"Secret"}, Interval: metav1.Duration{Duration: time.Hour}},
+				},
+			}}
+			Expect(triggered(cb)).To(BeFalse())
+
+			cb.Annotations = map[string]string{backupv1alpha1.TriggerAnnotation: "1"}
+			Expect(triggered(cb)).To(BeTrue())
+			cb.Status.LastTrigger = "1"
+			Expect(triggered(cb)).To(BeFalse())
+
+			startResourceSchedules(cb, now, false)
+			finishResourceSchedules(cb, metav1.NewTime(now))
+			startResourceSchedules(cb, now.Add(time.Minute), true)
+			for _, status := range cb.Status.ResourceSchedules {
+				Expect(status.Running).To(BeTrue())
+			}
+		})
+	})
+
+	Context("Archive tiering", func() {
+		It("should catalog archives by tier and restore from the tier holding them", func() {
+			hot := GinkgoT().TempDir()
+			cold := GinkgoT().TempDir()
+			Expect(os.WriteFile(filepath.Join(cold, "cluster-backup-20250101-000000.tar.gz"), nil, 0o644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(hot, "cluster-backup-20250102-000000.tar.gz"), nil, 0o644)).To(Succeed())
+
+			cb := &backupv1alpha1.ClusterBackup{Spec: backupv1alpha1.ClusterBackupSpec{
+				StoragePath: hot,
+				Tiering:     &backupv1alpha1.ArchiveTiering{ColdStoragePath: cold, TransitionAfterDays: 7},
+			}}
+			reconciler := &ClusterBackupReconciler{BackupManager: &backup.BackupManager{}}
+			catalog, err := reconciler.archiveCatalog(hot, cold)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(catalog).To(Equal([]backupv1alpha1.ArchiveCatalogEntry{
+				{Name: "cluster-backup-20250102-000000.tar.gz", Tier: backupv1alpha1.ArchiveTierHot, StoragePath: hot},
+				{Name: "cluster-backup-20250101-000000.tar.gz", Tier: backupv1alpha1.ArchiveTierCold, StoragePath: cold},
+			}))
+
+			config := &backupv1alpha1.BackupOperatorConfigSpec{}
+			Expect(archiveStoragePath(reconciler.BackupManager, cb, config, hot, "cluster-backup-20250101-000000.tar.gz")).To(Equal(cold))
+			Expect(archiveStoragePath(reconciler.BackupManager, cb, config, hot, "cluster-backup-20250102-000000.tar.gz")).To(Equal(hot))
+		})
+	})
+
+	Context("Storage path templates", func() {
+		It("should expand the cluster, namespace and backup name in every storage location", func() {
+			cb := &backupv1alpha1.ClusterBackup{
+				ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "payments"},
+				Spec: backupv1alpha1.ClusterBackupSpec{
+					ReplicaStoragePaths: []string{"/mnt/offsite/{{.ClusterName}}/{{.BackupName}}"},
+					Tiering:             &backupv1alpha1.ArchiveTiering{ColdStoragePath: "/mnt/cold/{{.ClusterName}}", TransitionAfterDays: 7},
+				},
+			}
+			config := &backupv1alpha1.BackupOperatorConfigSpec{
+				DefaultStoragePath: "host:///var/lib/backups/{{.ClusterName}}/{{.Namespace}}/{{.BackupName}}",
+			}
+
+			Expect(storagePathFor(cb, config)).To(BeEmpty())
+			Expect(storagePathError(cb, config)).To(MatchError(ContainSubstring("set clusterName in the BackupOperatorConfig")))
+
+			config.ClusterName = "prod-eu"
+			Expect(storagePathError(cb, config)).To(Succeed())
+			Expect(storageLocationsFor(cb, config)).To(Equal([]string{
+				"host:///var/lib/backups/prod-eu/payments/nightly",
+				"/mnt/offsite/prod-eu/nightly",
+			}))
+			Expect(coldStoragePathFor(cb, config)).To(Equal("/mnt/cold/prod-eu"))
+
+			cb.Labels = map[string]string{backupv1alpha1.ClusterNameLabel: "edge-1"}
+			Expect(storagePathFor(cb, config)).To(Equal("host:///var/lib/backups/edge-1/payments/nightly"))
+		})
+	})
+
+	Context("Storage locations", func() {
+		It("should track each replica and keep the last success of failed ones", func() {
+			earlier := metav1.NewTime(time.Now().Add(-time.Hour))
+			cb := &backupv1alpha1.ClusterBackup{}
+			cb.Status.StorageLocations = []backupv1alpha1.StorageLocationStatus{
+				{StoragePath: "/replica-b", Phase: "Completed", LastSuccessTime: &earlier},
+			}
+			cb.Status.Message = "Successfully backed up 3 resources"
+
+			now := metav1.Now()
+			setStorageLocations(cb, "/primary", &backup.BackupResult{
+				FilePath: "/primary/a.tar.gz",
+				Replicas: []backup.ReplicaResult{
+					{StoragePath: "/replica-a", FilePath: "/replica-a/a.tar.gz"},
+					{StoragePath: "/replica-b", Error: fmt.Errorf("disk full")},
+				},
+			}, now)
+
+			Expect(cb.Status.StorageLocations).To(HaveLen(3))
+			Expect(cb.Status.StorageLocations[1].Phase).To(Equal("Completed"))
+			Expect(cb.Status.StorageLocations[2].Phase).To(Equal("Failed"))
+			Expect(cb.Status.StorageLocations[2].Message).To(Equal("disk full"))
+			Expect(cb.Status.StorageLocations[2].LastSuccessTime).To(Equal(&earlier))
+			Expect(cb.Status.Message).To(HaveSuffix("1 of 2 replicas failed"))
+			Expect(meta.IsStatusConditionFalse(cb.Status.Conditions, "Replicated")).To(BeTrue())
+		})
+	})
+
+	Context("Schema validation", func() {
+		ctx := context.Background()
+
+		create := func(name string, spec backupv1alpha1.ClusterBackupSpec) error {
+			return k8sClient.Create(ctx, &backupv1alpha1.ClusterBackup{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				Spec:       spec,
+			}, client.DryRunAll)
+		}
+
+		It("should accept durations, cron expressions and macros as schedules", func() {
+			for _, schedule := range []string{"24h", "1h30m", "0 2 * * *", "*/15 0-6 * * MON-FRI", "@daily"} {
+				Expect(create("valid-schedule", backupv1alpha1.ClusterBackupSpec{Schedule: schedule})).To(Succeed(), schedule)
+			}
+		})
+
+		It("should reject clearly invalid specs", func() {
+			zero := 0
+			for name, spec := range map[string]backupv1alpha1.ClusterBackupSpec{
+				"bad-schedule":       {Schedule: "every night"},
+				"bad-cron-minute":    {Schedule: "61 * * * *"},
+				"bad-cron-fields":    {Schedule: "0 2 * * * *"},
+				"bad-retention":      {RetentionDays: &zero},
+				"relative-storage":   {StoragePath: "backups"},
+				"unsupported-scheme": {StoragePath: "s3://bucket/backups"},
+				"relative-replica":   {ReplicaStoragePaths: []string{"/mnt/a", "replica"}},
+			} {
+				err := create(name, spec)
+				Expect(errors.IsInvalid(err)).To(BeTrue(), "%s: %v", name, err)
+			}
+		})
+	})
+
+	Context("Estimate mode", func() {
+		ctx := context.Background()
+
+		It("should report the expected size without writing an archive", func() {
+			Expect(k8sClient.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "estimate-payload", Namespace: "default"},
+				Data:       map[string]string{"payload": strings.Repeat("x", 2048)},
+			})).To(Succeed())
+			storagePath := GinkgoT().TempDir()
+			cb := &backupv1alpha1.ClusterBackup{
+				ObjectMeta: metav1.ObjectMeta{Name: "estimate", Namespace: "default"},
+				Spec: backupv1alpha1.ClusterBackupSpec{
+					StoragePath:             storagePath,
+					Estimate:                true,
+					IncludeNamespaces:       []string{"default"},
+					IncludeClusterResources: ptr.To(false),
+					ResourceTypes:           []string{"ConfigMap"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, cb)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, cb)).To(Succeed())
+			})
+
+			bm, err := backup.NewBackupManager(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			reconciler := &ClusterBackupReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), BackupManager: bm}
+			key := client.ObjectKeyFromObject(cb)
+			for range 2 {
+				_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(k8sClient.Get(ctx, key, cb)).To(Succeed())
+			Expect(cb.Status.Estimate).NotTo(BeNil())
+			Expect(cb.Status.Estimate.ObservedGeneration).To(Equal(cb.Generation))
+			Expect(cb.Status.Estimate.ItemCount).To(BeNumerically(">=", 1))
+			Expect(cb.Status.Estimate.Bytes).To(BeNumerically(">", 2048))
+			Expect(cb.Status.Estimate.ResourceTypes).To(ContainElement(HaveField("Resource", "configmaps")))
+			Expect(meta.IsStatusConditionTrue(cb.Status.Conditions, "Estimated")).To(BeTrue())
+			Expect(cb.Status.Phase).To(BeEmpty())
+			Expect(os.ReadDir(storagePath)).To(BeEmpty())
+		})
+
+		It("should format sizes with binary units", func() {
+			Expect(formatBytes(512)).To(Equal("512 B"))
+			Expect(formatBytes(1536)).To(Equal("1.5 KiB"))
+			Expect(formatBytes(3 << 30)).To(Equal("3.0 GiB"))
+		})
+
+		It("should name action Jobs after the run and default their restart policy", func() {
+			cb := &backupv1alpha1.ClusterBackup{
+				ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("nightly-", 8), Namespace: "default"},
+				Status:     backupv1alpha1.ClusterBackupStatus{LastRunID: "0f8fad5b-d9cb-469f-a165-70867728950e"},
+			}
+			action := backupv1alpha1.BackupJobAction{Name: "dump-db"}
+			job := backupActionJob(cb, "pre", action)
+			Expect(job.Name).To(HaveLen(63))
+			Expect(job.Name).To(HaveSuffix("-pre-dump-db-0f8fad5b"))
+			Expect(job.Namespace).To(Equal("default"))
+			Expect(job.Labels).To(HaveKeyWithValue(backupActionLabel, "dump-db"))
+			Expect(job.Spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+			Expect(action.JobSpec.Template.Spec.RestartPolicy).To(BeEmpty())
+
+			// The same run creates the same Job, so a resumed run waits for it
+			Expect(backupActionJob(cb, "pre", action).Name).To(Equal(job.Name))
+			cb.Status.LastRunID = "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+			Expect(backupActionJob(cb, "pre", action).Name).NotTo(Equal(job.Name))
+		})
+	})
+
+	Context("Remote clusters", func() {
+		ctx := context.Background()
+
+		It("should build clients from the kubeconfig Secret", func() {
+			kubeconfig := clientcmdapi.NewConfig()
+			kubeconfig.Clusters["edge"] = &clientcmdapi.Cluster{Server: cfg.Host, CertificateAuthorityData: cfg.CAData}
+			kubeconfig.AuthInfos["edge"] = &clientcmdapi.AuthInfo{ClientCertificateData: cfg.CertData, ClientKeyData: cfg.KeyData}
+			kubeconfig.Contexts["edge"] = &clientcmdapi.Context{Cluster: "edge", AuthInfo: "edge"}
+			kubeconfig.CurrentContext = "edge"
+			data, err := clientcmd.Write(*kubeconfig)
+			Expect(err).NotTo(HaveOccurred())
+
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "edge-kubeconfig", Namespace: "default"},
+				Data:       map[string][]byte{"kubeconfig": data},
+			}
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+			})
+
+			bm, err := backup.NewBackupManager(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			remote, err := remoteClusterManager(ctx, k8sClient, bm, "default", &backupv1alpha1.ClusterReference{Name: "edge-kubeconfig"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(remote).NotTo(BeIdenticalTo(bm))
+			Expect(remote.Config.Host).To(Equal(cfg.Host))
+			Expect(remote.DiscoveryClient.ServerVersion()).Error().NotTo(HaveOccurred())
+
+			_, err = remoteClusterManager(ctx, k8sClient, bm, "default", &backupv1alpha1.ClusterReference{Name: "edge-kubeconfig", Key: "value"})
+			Expect(err).To(MatchError(ContainSubstring(`has no key "value"`)))
+			_, err = remoteClusterManager(ctx, k8sClient, bm, "default", &backupv1alpha1.ClusterReference{Name: "missing"})
+			Expect(err).To(MatchError(ContainSubstring(`failed to get kubeconfig secret "missing"`)))
+		})
+	})
 })
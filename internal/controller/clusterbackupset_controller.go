@@ -0,0 +1,297 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+const (
+	// backupSetLabel names the ClusterBackupSet a ClusterBackup belongs to.
+	backupSetLabel = "backup.backup.io/cluster-backup-set"
+
+	// clusterSetResyncInterval is how often a ClusterBackupSet with a
+	// clusterSelector looks for added or removed Cluster API Clusters.
+	clusterSetResyncInterval = 5 * time.Minute
+)
+
+// capiClusterList is the Cluster API kind selected by spec.clusterSelector.
+var capiClusterList = schema.GroupVersionKind{Group: "cluster.x-k8s.io", Version: "v1beta1", Kind: "ClusterList"}
+
+// ClusterBackupSetReconciler creates a ClusterBackup per cluster of a fleet
+// and aggregates their status
+type ClusterBackupSetReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=backup.backup.io,resources=clusterbackupsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=backup.backup.io,resources=clusterbackupsets/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=backup.backup.io,resources=clusterbackupsets/finalizers,verbs=update
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list
+
+// Reconcile brings the ClusterBackups of a set in line with its clusters and
+// records their state in status.
+func (r *ClusterBackupSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	set := &backupv1alpha1.ClusterBackupSet{}
+	if err := r.Get(ctx, req.NamespacedName, set); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get ClusterBackupSet")
+		return ctrl.Result{}, err
+	}
+
+	var result ctrl.Result
+	if set.Spec.ClusterSelector != nil {
+		result.RequeueAfter = clusterSetResyncInterval
+	}
+	set.Status.ObservedGeneration = set.Generation
+
+	config, err := loadOperatorConfig(ctx, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	clusters, err := r.fleetClusters(ctx, set)
+	if err != nil {
+		// Without the full list, removed clusters cannot be told apart
+		log.Error(err, "Failed to list clusters")
+		backup.SetCondition(&set.Status.Conditions, "Ready", metav1.ConditionFalse, "ClusterDiscoveryFailed", err.Error())
+		return result, r.updateStatus(ctx, set)
+	}
+
+	members := make([]backupv1alpha1.ClusterBackupSetMember, 0, len(clusters))
+	var failures []string
+	for _, cluster := range clusters {
+		member, err := r.syncClusterBackup(ctx, set, cluster, config)
+		if err != nil {
+			log.Error(err, "Failed to sync ClusterBackup", "cluster", cluster.Name)
+			failures = append(failures, fmt.Sprintf("%s: %v", cluster.Name, err))
+		}
+		members = append(members, member)
+	}
+	if err := r.pruneClusterBackups(ctx, set, clusters); err != nil {
+		log.Error(err, "Failed to delete ClusterBackups of removed clusters")
+		failures = append(failures, err.Error())
+	}
+
+	setFleetStatus(set, members, failures)
+	return result, r.updateStatus(ctx, set)
+}
+
+// fleetClusters returns the clusters listed in spec.clusters followed by the
+// Cluster API Clusters matching spec.clusterSelector. A listed cluster takes
+// precedence over a Cluster API Cluster of the same name.
+func (r *ClusterBackupSetReconciler) fleetClusters(ctx context.Context, set *backupv1alpha1.ClusterBackupSet) ([]backupv1alpha1.FleetCluster, error) {
+	clusters := append([]backupv1alpha1.FleetCluster(nil), set.Spec.Clusters...)
+	if set.Spec.ClusterSelector == nil {
+		return clusters, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(set.Spec.ClusterSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid clusterSelector: %w", err)
+	}
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(capiClusterList)
+	if err := r.List(ctx, list, client.InNamespace(set.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("failed to list Cluster API Clusters: %w", err)
+	}
+
+	listed := map[string]bool{}
+	for _, cluster := range clusters {
+		listed[cluster.Name] = true
+	}
+	for _, item := range list.Items {
+		if listed[item.GetName()] {
+			continue
+		}
+		clusters = append(clusters, backupv1alpha1.FleetCluster{
+			Name: item.GetName(),
+			// Cluster API keeps the admin kubeconfig in <cluster>-kubeconfig
+			ClusterRef: backupv1alpha1.ClusterReference{Name: item.GetName() + "-kubeconfig", Key: "value"},
+		})
+	}
+	return clusters, nil
+}
+
+// syncClusterBackup creates or updates the ClusterBackup of one cluster and
+// returns its state.
+func (r *ClusterBackupSetReconciler) syncClusterBackup(ctx context.Context, set *backupv1alpha1.ClusterBackupSet,
+	cluster backupv1alpha1.FleetCluster, config *backupv1alpha1.BackupOperatorConfigSpec) (backupv1alpha1.ClusterBackupSetMember, error) {
+	member := backupv1alpha1.ClusterBackupSetMember{Name: cluster.Name, BackupName: set.Name + "-" + cluster.Name}
+
+	spec, err := fleetBackupSpec(set, cluster, config)
+	if err != nil {
+		member.Message = err.Error()
+		return member, err
+	}
+
+	clusterBackup := &backupv1alpha1.ClusterBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: member.BackupName, Namespace: set.Namespace},
+	}
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, clusterBackup, func() error {
+		if clusterBackup.Labels == nil {
+			clusterBackup.Labels = map[string]string{}
+		}
+		clusterBackup.Labels[backupSetLabel] = set.Name
+		clusterBackup.Labels[backupv1alpha1.ClusterNameLabel] = cluster.Name
+		clusterBackup.Spec = *spec
+		return controllerutil.SetControllerReference(set, clusterBackup, r.Scheme)
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to apply ClusterBackup %q: %w", member.BackupName, err)
+		member.Message = err.Error()
+		return member, err
+	}
+
+	member.Phase = clusterBackup.Status.Phase
+	member.LastBackupTime = clusterBackup.Status.LastBackupTime
+	member.Message = clusterBackup.Status.Message
+	return member, nil
+}
+
+// fleetBackupSpec returns the template of set pointed at cluster, with every
+// storage location moved into a per-cluster directory.
+func fleetBackupSpec(set *backupv1alpha1.ClusterBackupSet, cluster backupv1alpha1.FleetCluster,
+	config *backupv1alpha1.BackupOperatorConfigSpec) (*backupv1alpha1.ClusterBackupSpec, error) {
+	spec := set.Spec.Template.DeepCopy()
+
+	base := spec.StoragePath
+	if base == "" {
+		base = config.DefaultStoragePath
+	}
+	if base == "" {
+		return nil, fmt.Errorf("set template.storagePath or the defaultStoragePath of the BackupOperatorConfig")
+	}
+	spec.StoragePath = clusterStoragePath(base, cluster.Name)
+	for i, replica := range spec.ReplicaStoragePaths {
+		spec.ReplicaStoragePaths[i] = clusterStoragePath(replica, cluster.Name)
+	}
+	if spec.Tiering != nil {
+		spec.Tiering.ColdStoragePath = clusterStoragePath(spec.Tiering.ColdStoragePath, cluster.Name)
+	}
+
+	ref := cluster.ClusterRef
+	if ref.Key == "" {
+		ref.Key = "kubeconfig"
+	}
+	spec.ClusterRef = &ref
+	return spec, nil
+}
+
+// clusterStoragePath returns the directory of cluster below storagePath. A
+// template placing {{.ClusterName}} itself is kept as is; the ClusterBackup
+// expands it from its cluster label.
+func clusterStoragePath(storagePath, cluster string) string {
+	if strings.Contains(storagePath, ".ClusterName") {
+		return storagePath
+	}
+	return strings.TrimSuffix(storagePath, "/") + "/" + cluster
+}
+
+// pruneClusterBackups deletes the ClusterBackups of clusters no longer in the
+// set. Their archives are kept unless the template sets deleteOnDelete.
+func (r *ClusterBackupSetReconciler) pruneClusterBackups(ctx context.Context, set *backupv1alpha1.ClusterBackupSet, clusters []backupv1alpha1.FleetCluster) error {
+	var list backupv1alpha1.ClusterBackupList
+	if err := r.List(ctx, &list, client.InNamespace(set.Namespace), client.MatchingLabels{backupSetLabel: set.Name}); err != nil {
+		return fmt.Errorf("failed to list ClusterBackups: %w", err)
+	}
+
+	current := map[string]bool{}
+	for _, cluster := range clusters {
+		current[cluster.Name] = true
+	}
+	for i := range list.Items {
+		clusterBackup := &list.Items[i]
+		if current[clusterBackup.Labels[backupv1alpha1.ClusterNameLabel]] || !metav1.IsControlledBy(clusterBackup, set) {
+			continue
+		}
+		if err := r.Delete(ctx, clusterBackup); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete ClusterBackup %q: %w", clusterBackup.Name, err)
+		}
+	}
+	return nil
+}
+
+// setFleetStatus records the member states, their counts and the Ready
+// condition.
+func setFleetStatus(set *backupv1alpha1.ClusterBackupSet, members []backupv1alpha1.ClusterBackupSetMember, failures []string) {
+	set.Status.Clusters = members
+	set.Status.Total = len(members)
+	set.Status.Succeeded = 0
+	set.Status.Failed = 0
+	for _, member := range members {
+		switch member.Phase {
+		case "Completed":
+			set.Status.Succeeded++
+		case "Failed":
+			set.Status.Failed++
+		}
+	}
+
+	switch {
+	case len(failures) > 0:
+		backup.SetCondition(&set.Status.Conditions, "Ready", metav1.ConditionFalse, "SyncFailed", strings.Join(failures, "; "))
+	case set.Status.Failed > 0:
+		backup.SetCondition(&set.Status.Conditions, "Ready", metav1.ConditionFalse, "BackupsFailed",
+			fmt.Sprintf("%d of %d cluster backups failed", set.Status.Failed, set.Status.Total))
+	default:
+		backup.SetCondition(&set.Status.Conditions, "Ready", metav1.ConditionTrue, "Synced",
+			fmt.Sprintf("%d of %d cluster backups completed", set.Status.Succeeded, set.Status.Total))
+	}
+}
+
+func (r *ClusterBackupSetReconciler) updateStatus(ctx context.Context, set *backupv1alpha1.ClusterBackupSet) error {
+	if err := r.Status().Update(ctx, set); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to update ClusterBackupSet status")
+		return err
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterBackupSetReconciler) SetupWithManager(mgr ctrl.Manager, options Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		// Status updates must not start another pass
+		For(&backupv1alpha1.ClusterBackupSet{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&backupv1alpha1.ClusterBackup{}).
+		Named("clusterbackupset").
+		WithOptions(options.forController("clusterbackupset")).
+		Complete(r)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+)
+
+var _ = Describe("ClusterBackupSet Controller", func() {
+	ctx := context.Background()
+	typeNamespacedName := types.NamespacedName{Name: "fleet", Namespace: "default"}
+
+	AfterEach(func() {
+		resource := &backupv1alpha1.ClusterBackupSet{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+	})
+
+	It("should create a ClusterBackup per cluster and remove those of dropped clusters", func() {
+		Expect(k8sClient.Create(ctx, &backupv1alpha1.ClusterBackupSet{
+			ObjectMeta: metav1.ObjectMeta{Name: typeNamespacedName.Name, Namespace: typeNamespacedName.Namespace},
+			Spec: backupv1alpha1.ClusterBackupSetSpec{
+				Clusters: []backupv1alpha1.FleetCluster{
+					{Name: "east", ClusterRef: backupv1alpha1.ClusterReference{Name: "east-kubeconfig"}},
+					{Name: "west", ClusterRef: backupv1alpha1.ClusterReference{Name: "west-kubeconfig", Context: "admin"}},
+				},
+				Template: backupv1alpha1.ClusterBackupSpec{
+					StoragePath:         "/tmp/fleet/",
+					ReplicaStoragePaths: []string{"host://offsite/fleet"},
+					Schedule:            "@daily",
+				},
+			},
+		})).To(Succeed())
+
+		reconciler := &ClusterBackupSetReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+
+		west := &backupv1alpha1.ClusterBackup{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "fleet-west", Namespace: "default"}, west)).To(Succeed())
+		Expect(west.Spec.StoragePath).To(Equal("/tmp/fleet/west"))
+		Expect(west.Spec.ReplicaStoragePaths).To(Equal([]string{"host://offsite/fleet/west"}))
+		Expect(west.Spec.Schedule).To(Equal("@daily"))
+		Expect(west.Spec.ClusterRef).To(Equal(&backupv1alpha1.ClusterReference{Name: "west-kubeconfig", Key: "kubeconfig", Context: "admin"}))
+		Expect(west.Labels).To(HaveKeyWithValue(backupv1alpha1.ClusterNameLabel, "west"))
+		Expect(west.OwnerReferences).To(HaveLen(1))
+
+		set := &backupv1alpha1.ClusterBackupSet{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, set)).To(Succeed())
+		Expect(set.Status.Total).To(Equal(2))
+		Expect(set.Status.Clusters[0].BackupName).To(Equal("fleet-east"))
+		Expect(meta.IsStatusConditionTrue(set.Status.Conditions, "Ready")).To(BeTrue())
+
+		set.Spec.Clusters = set.Spec.Clusters[:1]
+		Expect(k8sClient.Update(ctx, set)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = k8sClient.Get(ctx, types.NamespacedName{Name: "fleet-west", Namespace: "default"}, west)
+		Expect(apierrors.IsNotFound(err) || west.DeletionTimestamp != nil).To(BeTrue())
+		Expect(k8sClient.Get(ctx, typeNamespacedName, set)).To(Succeed())
+		Expect(set.Status.Total).To(Equal(1))
+	})
+
+	It("should report a template without a storage location", func() {
+		Expect(k8sClient.Create(ctx, &backupv1alpha1.ClusterBackupSet{
+			ObjectMeta: metav1.ObjectMeta{Name: typeNamespacedName.Name, Namespace: typeNamespacedName.Namespace},
+			Spec: backupv1alpha1.ClusterBackupSetSpec{
+				Clusters: []backupv1alpha1.FleetCluster{
+					{Name: "east", ClusterRef: backupv1alpha1.ClusterReference{Name: "east-kubeconfig"}},
+				},
+			},
+		})).To(Succeed())
+
+		reconciler := &ClusterBackupSetReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+
+		set := &backupv1alpha1.ClusterBackupSet{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, set)).To(Succeed())
+		condition := meta.FindStatusCondition(set.Status.Conditions, "Ready")
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal("SyncFailed"))
+	})
+})
@@ -0,0 +1,397 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+const (
+	// restoreProgressInterval throttles how often progress is written to status
+	restoreProgressInterval = 5 * time.Second
+
+	// pendingBackupRequeue is how long to wait for a referenced ClusterBackup
+	// that is still running
+	pendingBackupRequeue = 10 * time.Second
+)
+
+// ClusterRestoreReconciler reconciles a ClusterRestore object
+type ClusterRestoreReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	BackupManager *backup.BackupManager
+
+	// Recorder, when set, receives an event for every finished restore run.
+	Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=backup.backup.io,resources=clusterrestores,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=backup.backup.io,resources=clusterrestores/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=backup.backup.io,resources=clusterrestores/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
+// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingwebhookconfigurations;mutatingwebhookconfigurations,verbs=get;list;update
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;update
+
+// Reconcile runs the restore described by a ClusterRestore once per generation
+// and records its lifecycle in status.
+func (r *ClusterRestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	clusterRestore := &backupv1alpha1.ClusterRestore{}
+	if err := r.Get(ctx, req.NamespacedName, clusterRestore); err != nil {
+		if apierrors.IsNotFound(err) {
+			deleteRestoreMetrics(req.Namespace, req.Name, "ClusterRestore")
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get ClusterRestore")
+		return ctrl.Result{}, err
+	}
+
+	// A restore runs once per generation
+	if clusterRestore.Status.ObservedGeneration == clusterRestore.Generation && restoreFinished(clusterRestore.Status.Phase) {
+		return ctrl.Result{}, nil
+	}
+
+	// A restore waiting for its backup keeps its run ID
+	runID := clusterRestore.Status.RunID
+	if runID == "" || clusterRestore.Status.ObservedGeneration != clusterRestore.Generation {
+		runID = newRunID()
+	}
+	ctx = withRunID(ctx, runID)
+	log = logf.FromContext(ctx)
+	clusterRestore.Status.RunID = runID
+
+	archive := restoreArchiveLabel(&clusterRestore.Spec.RestoreOptions)
+	storagePath, waiting, err := r.resolveStoragePath(ctx, clusterRestore)
+	if err != nil {
+		return ctrl.Result{}, r.markFailed(ctx, clusterRestore, "Validated", "StorageNotResolved", err)
+	}
+	if waiting {
+		if clusterRestore.Status.Phase != backupv1alpha1.RestorePhasePending {
+			clusterRestore.Status.Phase = backupv1alpha1.RestorePhasePending
+			clusterRestore.Status.ObservedGeneration = clusterRestore.Generation
+			clusterRestore.Status.Message = fmt.Sprintf("Waiting for ClusterBackup %q to finish", clusterRestore.Spec.BackupName)
+			if err := r.Status().Update(ctx, clusterRestore); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{RequeueAfter: pendingBackupRequeue}, nil
+	}
+
+	keyWrappers, err := ageKeyWrappers(ctx, r.Client, clusterRestore.Namespace, clusterRestore.Spec.AgeIdentitySecretRef)
+	if err != nil {
+		return ctrl.Result{}, r.markFailed(ctx, clusterRestore, "Validated", "DecryptionKeyUnavailable", err)
+	}
+
+	bm, err := targetManager(ctx, r.Client, r.BackupManager, clusterRestore.Namespace,
+		clusterRestore.Spec.ClusterRef, clusterRestore.Spec.Impersonate)
+	if err != nil {
+		return ctrl.Result{}, r.markFailed(ctx, clusterRestore, "Validated", "TargetClusterUnavailable", err)
+	}
+
+	now := metav1.Now()
+	clusterRestore.Status = backupv1alpha1.ClusterRestoreStatus{
+		Phase:              backupv1alpha1.RestorePhaseValidating,
+		ObservedGeneration: clusterRestore.Generation,
+		StartTime:          &now,
+		StoragePath:        storagePath,
+		RunID:              runID,
+		Message:            fmt.Sprintf("Reading archive %s", archive),
+		Conditions:         clusterRestore.Status.Conditions,
+	}
+	backup.SetCondition(&clusterRestore.Status.Conditions, "Restored", metav1.ConditionUnknown, "RestoreStarted", "Restore has started")
+	if err := r.Status().Update(ctx, clusterRestore); err != nil {
+		log.Error(err, "Failed to update status to Validating")
+		return ctrl.Result{}, err
+	}
+
+	log.Info("Restoring from archive", "archive", archive, "storagePath", storagePath)
+
+	var lastProgressUpdate time.Time
+	opts := backup.RestoreOptions{
+		FailurePolicy:             backup.RestoreFailurePolicy(clusterRestore.Spec.FailurePolicy),
+		ExistingResourcePolicy:    backup.ExistingResourcePolicy(clusterRestore.Spec.ExistingResourcePolicy),
+		ExcludeGitOpsManaged:      clusterRestore.Spec.ExcludeGitOpsManaged,
+		IncludeGeneratedResources: clusterRestore.Spec.IncludeGeneratedResources,
+		HelmReleasePolicy:         backup.HelmReleasePolicy(clusterRestore.Spec.HelmReleasePolicy),
+		QuotaPolicy:               backup.QuotaPolicy(clusterRestore.Spec.QuotaPolicy),
+		ClusterMismatchPolicy:     backup.ClusterMismatchPolicy(clusterRestore.Spec.ClusterMismatchPolicy),
+		RemovedAPIPolicy:          backup.RemovedAPIPolicy(clusterRestore.Spec.RemovedAPIPolicy),
+		IgnoreWebhookFailures:     clusterRestore.Spec.IgnoreWebhookFailures,
+		KeyWrappers:               keyWrappers,
+		ArchiveSHA256:             clusterRestore.Spec.ArchiveSHA256,
+		PointInTime:               restorePointInTime(&clusterRestore.Spec.RestoreOptions),
+		ScaleWorkloadsToZero:      clusterRestore.Spec.ScaleWorkloadsToZero,
+		Progress: func(processed, total int) {
+			clusterRestore.Status.Progress = &backupv1alpha1.RestoreProgress{TotalItems: total, ItemsProcessed: processed}
+			if clusterRestore.Status.Phase == backupv1alpha1.RestorePhaseValidating {
+				clusterRestore.Status.Phase = backupv1alpha1.RestorePhaseInProgress
+				clusterRestore.Status.Message = fmt.Sprintf("Applying %d resources", total)
+				backup.SetCondition(&clusterRestore.Status.Conditions, "Validated", metav1.ConditionTrue, "ArchiveRead",
+					fmt.Sprintf("Read %d resources from archive", total))
+			} else if time.Since(lastProgressUpdate) < restoreProgressInterval {
+				return
+			}
+			lastProgressUpdate = time.Now()
+			if err := r.Status().Update(ctx, clusterRestore); err != nil {
+				log.Error(err, "Failed to update restore progress")
+			}
+		},
+	}
+	opts.ApplyRate, opts.ApplyBurst = restoreThrottle(&clusterRestore.Spec.RestoreOptions)
+	opts.FieldManager, opts.KeepConflictingFields = restoreFieldManager(&clusterRestore.Spec.RestoreOptions)
+	opts.Callouts = restoreCallouts(&clusterRestore.Spec.RestoreOptions)
+	opts.Quiesce = quiescedWorkloads(&clusterRestore.Spec.RestoreOptions)
+
+	if clusterRestore.Spec.Plan {
+		return ctrl.Result{}, r.plan(ctx, clusterRestore, bm, storagePath, opts)
+	}
+
+	result, err := bm.RestoreBackup(ctx, storagePath, restoreSource(&clusterRestore.Spec.RestoreOptions), opts)
+	if err != nil {
+		var itemErr backup.RestoreItemError
+		if errors.As(err, &itemErr) {
+			// FailFast returns what was applied before the failing item
+			if result != nil {
+				clusterRestore.Status.Summary = restoreSummary(result)
+			}
+			return ctrl.Result{}, r.markFailed(ctx, clusterRestore, "Restored", "RestoreFailed", err)
+		}
+		return ctrl.Result{}, r.markFailed(ctx, clusterRestore, "Validated", validationFailureReason(err), err)
+	}
+
+	completed := metav1.Now()
+	clusterRestore.Status.CompletionTime = &completed
+	clusterRestore.Status.Summary = restoreSummary(result)
+	if result.ClusterMismatch != "" {
+		recordRunEvent(r.Recorder, clusterRestore, runID, corev1.EventTypeWarning, "ClusterMismatch",
+			"Restore run %s: %s", runID, result.ClusterMismatch)
+	}
+	if result.Failed > 0 {
+		clusterRestore.Status.Phase = backupv1alpha1.RestorePhasePartiallyFailed
+		clusterRestore.Status.Message = fmt.Sprintf("Restored %d resources from %s, %d failed: %v",
+			result.ResourcesApplied, archive, result.Failed, result.FailedItems[0])
+		backup.SetCondition(&clusterRestore.Status.Conditions, "Restored", metav1.ConditionFalse, "RestorePartiallyFailed",
+			fmt.Sprintf("%d resources failed to restore", result.Failed))
+		recordRestoreRun(clusterRestore.Namespace, clusterRestore.Name, "ClusterRestore", runID, "partial")
+		recordRunEvent(r.Recorder, clusterRestore, runID, corev1.EventTypeWarning, "RestorePartiallyFailed",
+			"Restore run %s: %s", runID, clusterRestore.Status.Message)
+		r.audit(ctx, clusterRestore, "partial")
+	} else {
+		clusterRestore.Status.Phase = backupv1alpha1.RestorePhaseCompleted
+		clusterRestore.Status.Message = fmt.Sprintf("Restored %d resources from %s", result.ResourcesApplied, archive)
+		backup.SetCondition(&clusterRestore.Status.Conditions, "Restored", metav1.ConditionTrue, "RestoreCompleted", "Restore completed successfully")
+		recordRestoreRun(clusterRestore.Namespace, clusterRestore.Name, "ClusterRestore", runID, "success")
+		recordRunEvent(r.Recorder, clusterRestore, runID, corev1.EventTypeNormal, "RestoreCompleted",
+			"Restore run %s: %s", runID, clusterRestore.Status.Message)
+		r.audit(ctx, clusterRestore, "success")
+	}
+
+	if err := r.Status().Update(ctx, clusterRestore); err != nil {
+		log.Error(err, "Failed to update status after restore")
+		return ctrl.Result{}, err
+	}
+
+	log.Info("Restore finished", "phase", clusterRestore.Status.Phase, "applied", result.ResourcesApplied, "failed", result.Failed)
+
+	return ctrl.Result{}, nil
+}
+
+// plan previews the restore in status.plan without applying anything.
+func (r *ClusterRestoreReconciler) plan(ctx context.Context, clusterRestore *backupv1alpha1.ClusterRestore, bm *backup.BackupManager, storagePath string, opts backup.RestoreOptions) error {
+	plan, err := bm.PlanRestore(ctx, storagePath, restoreSource(&clusterRestore.Spec.RestoreOptions), opts)
+	if err != nil {
+		return r.markFailed(ctx, clusterRestore, "Validated", validationFailureReason(err), err)
+	}
+
+	completed := metav1.Now()
+	clusterRestore.Status.CompletionTime = &completed
+	clusterRestore.Status.Phase = backupv1alpha1.RestorePhasePlanned
+	clusterRestore.Status.Plan = restorePlan(plan)
+	clusterRestore.Status.Message = fmt.Sprintf("Plan for %s: %s", restoreArchiveLabel(&clusterRestore.Spec.RestoreOptions), plan)
+	backup.SetCondition(&clusterRestore.Status.Conditions, "Validated", metav1.ConditionTrue, "ArchiveRead", "Archive was read and checked")
+	backup.SetCondition(&clusterRestore.Status.Conditions, "Restored", metav1.ConditionFalse, "Planned",
+		"Restore was planned only; unset spec.plan to run it")
+	recordRunEvent(r.Recorder, clusterRestore, clusterRestore.Status.RunID, corev1.EventTypeNormal, "RestorePlanned",
+		"Restore run %s: %s", clusterRestore.Status.RunID, clusterRestore.Status.Message)
+	return r.Status().Update(ctx, clusterRestore)
+}
+
+// restorePlan converts a restore plan into its status representation.
+func restorePlan(plan *backup.RestorePlan) *backupv1alpha1.RestorePlan {
+	converted := &backupv1alpha1.RestorePlan{
+		Create:         plan.Create,
+		Update:         plan.Update,
+		Unchanged:      plan.Unchanged,
+		Skip:           plan.Skip,
+		Fail:           plan.Fail,
+		Conflicts:      plan.Conflicts,
+		ClusterWarning: plan.Checks.ClusterMismatch,
+		APIFindings:    apiVersionFindings(plan.Checks.APIFindings),
+	}
+	for _, item := range plan.Items {
+		converted.Items = append(converted.Items, backupv1alpha1.RestorePlanItem{
+			Resource:  item.GVR.GroupResource().String(),
+			Namespace: item.Namespace,
+			Name:      item.Name,
+			Action:    string(item.Action),
+			Conflict:  item.Conflict,
+			Changes:   item.Changes,
+			Reason:    item.Reason,
+		})
+	}
+	for _, violation := range plan.Checks.QuotaViolations {
+		converted.QuotaWarnings = append(converted.QuotaWarnings, violation.String())
+	}
+	return converted
+}
+
+// validationFailureReason returns the condition reason for a restore that
+// failed before applying anything.
+func validationFailureReason(err error) string {
+	switch {
+	case errors.Is(err, backup.ErrQuotaExceeded):
+		return "QuotaExceeded"
+	case errors.Is(err, backup.ErrClusterMismatch):
+		return "ClusterMismatch"
+	case errors.Is(err, backup.ErrAPIRemoved):
+		return "RemovedAPI"
+	}
+	return "ArchiveUnreadable"
+}
+
+// resolveStoragePath returns the storage location holding the archive, or
+// an empty one for archives read from a URL. It reports waiting when the
+// referenced ClusterBackup has not finished yet.
+func (r *ClusterRestoreReconciler) resolveStoragePath(ctx context.Context, clusterRestore *backupv1alpha1.ClusterRestore) (string, bool, error) {
+	if clusterRestore.Spec.ArchiveURL != "" {
+		return "", false, nil
+	}
+	if clusterRestore.Spec.StoragePath != "" {
+		return clusterRestore.Spec.StoragePath, false, nil
+	}
+	if clusterRestore.Spec.BackupName == "" {
+		return "", false, fmt.Errorf("either storagePath or backupName must be set")
+	}
+
+	clusterBackup := &backupv1alpha1.ClusterBackup{}
+	key := types.NamespacedName{Namespace: clusterRestore.Namespace, Name: clusterRestore.Spec.BackupName}
+	if err := r.Get(ctx, key, clusterBackup); err != nil {
+		return "", false, fmt.Errorf("failed to get ClusterBackup %q: %w", clusterRestore.Spec.BackupName, err)
+	}
+
+	if clusterBackup.Status.Phase == "Running" {
+		return "", true, nil
+	}
+
+	config, err := loadOperatorConfig(ctx, r.Client)
+	if err != nil {
+		return "", false, err
+	}
+	storagePath := storagePathFor(clusterBackup, config)
+	if storagePath == "" {
+		return "", false, fmt.Errorf("ClusterBackup %q has no storage location", clusterRestore.Spec.BackupName)
+	}
+	return archiveStoragePath(r.BackupManager, clusterBackup, config, storagePath, clusterRestore.Spec.ArchiveName), false, nil
+}
+
+// markFailed moves the restore into the Failed phase, recording err on the
+// given condition. The restore is not retried until the spec changes.
+func (r *ClusterRestoreReconciler) markFailed(ctx context.Context, clusterRestore *backupv1alpha1.ClusterRestore, conditionType, reason string, err error) error {
+	log := logf.FromContext(ctx)
+	log.Error(err, "Restore failed")
+
+	now := metav1.Now()
+	clusterRestore.Status.Phase = backupv1alpha1.RestorePhaseFailed
+	clusterRestore.Status.ObservedGeneration = clusterRestore.Generation
+	clusterRestore.Status.CompletionTime = &now
+	clusterRestore.Status.Message = fmt.Sprintf("Restore failed: %v", err)
+	backup.SetCondition(&clusterRestore.Status.Conditions, conditionType, metav1.ConditionFalse, reason, err.Error())
+	if conditionType != "Restored" {
+		backup.SetCondition(&clusterRestore.Status.Conditions, "Restored", metav1.ConditionFalse, reason, err.Error())
+	}
+	recordRestoreRun(clusterRestore.Namespace, clusterRestore.Name, "ClusterRestore", clusterRestore.Status.RunID, "failure")
+	recordRunEvent(r.Recorder, clusterRestore, clusterRestore.Status.RunID, corev1.EventTypeWarning, reason,
+		"Restore run %s failed: %v", clusterRestore.Status.RunID, err)
+	r.audit(ctx, clusterRestore, "failure")
+
+	if statusErr := r.Status().Update(ctx, clusterRestore); statusErr != nil {
+		log.Error(statusErr, "Failed to update status after restore failure")
+		return statusErr
+	}
+	return nil
+}
+
+// audit records the outcome of the restore in the audit log of the storage
+// location it read from, or the operator default for archives read from a
+// URL.
+func (r *ClusterRestoreReconciler) audit(ctx context.Context, clusterRestore *backupv1alpha1.ClusterRestore, result string) {
+	config, err := loadOperatorConfig(ctx, r.Client)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to record audit entry")
+		return
+	}
+	location := clusterRestore.Status.StoragePath
+	if location == "" {
+		location = config.DefaultStoragePath
+	}
+	recordAudit(ctx, r.BackupManager, r.Recorder, config, clusterRestore, []string{location}, backup.AuditRecord{
+		Operation: backup.AuditOperationRestore,
+		Resource:  auditResource("ClusterRestore", clusterRestore),
+		User:      impersonatedUser(clusterRestore.Namespace, clusterRestore.Spec.Impersonate),
+		RunID:     clusterRestore.Status.RunID,
+		Archive:   restoreArchiveLabel(&clusterRestore.Spec.RestoreOptions),
+		Result:    result,
+		Message:   clusterRestore.Status.Message,
+	})
+}
+
+// restoreFinished reports whether phase is terminal
+func restoreFinished(phase backupv1alpha1.RestorePhase) bool {
+	switch phase {
+	case backupv1alpha1.RestorePhaseCompleted, backupv1alpha1.RestorePhasePartiallyFailed, backupv1alpha1.RestorePhaseFailed,
+		backupv1alpha1.RestorePhasePlanned:
+		return true
+	}
+	return false
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterRestoreReconciler) SetupWithManager(mgr ctrl.Manager, options Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&backupv1alpha1.ClusterRestore{}).
+		Named("clusterrestore").
+		WithOptions(options.forController("clusterrestore")).
+		Complete(r)
+}
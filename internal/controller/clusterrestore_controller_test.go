@@ -0,0 +1,165 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+var _ = Describe("ClusterRestore Controller", func() {
+	Context("When reconciling a resource", func() {
+		const resourceName = "test-restore"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind ClusterRestore")
+			err := k8sClient.Get(ctx, typeNamespacedName, &backupv1alpha1.ClusterRestore{})
+			if err != nil && errors.IsNotFound(err) {
+				resource := &backupv1alpha1.ClusterRestore{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      resourceName,
+						Namespace: "default",
+					},
+					Spec: backupv1alpha1.ClusterRestoreSpec{
+						StoragePath: GinkgoT().TempDir(),
+						RestoreOptions: backupv1alpha1.RestoreOptions{
+							ArchiveName: "cluster-backup-missing.tar.gz",
+						},
+					},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			resource := &backupv1alpha1.ClusterRestore{}
+			err := k8sClient.Get(ctx, typeNamespacedName, resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Cleanup the specific resource instance ClusterRestore")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+		It("should mark the restore failed when the archive is missing", func() {
+			By("Reconciling the created resource")
+			controllerReconciler := &ClusterRestoreReconciler{
+				Client:        k8sClient,
+				Scheme:        k8sClient.Scheme(),
+				BackupManager: &backup.BackupManager{},
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			restore := &backupv1alpha1.ClusterRestore{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, restore)).To(Succeed())
+			Expect(restore.Status.Phase).To(Equal(backupv1alpha1.RestorePhaseFailed))
+			Expect(restore.Status.CompletionTime).NotTo(BeNil())
+		})
+
+		It("should fail before starting when the target cluster is unreachable", func() {
+			restore := &backupv1alpha1.ClusterRestore{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, restore)).To(Succeed())
+			restore.Spec.ClusterRef = &backupv1alpha1.ClusterReference{Name: "standby-kubeconfig"}
+			Expect(k8sClient.Update(ctx, restore)).To(Succeed())
+
+			controllerReconciler := &ClusterRestoreReconciler{
+				Client:        k8sClient,
+				Scheme:        k8sClient.Scheme(),
+				BackupManager: &backup.BackupManager{},
+			}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, restore)).To(Succeed())
+			Expect(restore.Status.Phase).To(Equal(backupv1alpha1.RestorePhaseFailed))
+			Expect(restore.Status.StartTime).To(BeNil())
+			condition := meta.FindStatusCondition(restore.Status.Conditions, "Validated")
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Reason).To(Equal("TargetClusterUnavailable"))
+			Expect(condition.Message).To(ContainSubstring(`failed to get kubeconfig secret "standby-kubeconfig"`))
+		})
+
+		It("should preview the restore without applying it when plan is set", func() {
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "plan-settings", Namespace: "default"},
+				Data:       map[string]string{"mode": "active"},
+			}
+			Expect(k8sClient.Create(ctx, configMap)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, configMap)).To(Succeed())
+			})
+
+			bm, err := backup.NewBackupManager(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			storagePath := GinkgoT().TempDir()
+			result, err := bm.CreateBackup(ctx, storagePath, backup.BackupOptions{
+				IncludeNamespaces: []string{"default"},
+				ResourceTypes:     []string{"ConfigMap"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			restore := &backupv1alpha1.ClusterRestore{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, restore)).To(Succeed())
+			restore.Spec.StoragePath = storagePath
+			restore.Spec.ArchiveName = filepath.Base(result.FilePath)
+			restore.Spec.Plan = true
+			Expect(k8sClient.Update(ctx, restore)).To(Succeed())
+
+			controllerReconciler := &ClusterRestoreReconciler{
+				Client:        k8sClient,
+				Scheme:        k8sClient.Scheme(),
+				BackupManager: bm,
+			}
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, restore)).To(Succeed())
+			Expect(restore.Status.Phase).To(Equal(backupv1alpha1.RestorePhasePlanned))
+			Expect(restore.Status.Plan).NotTo(BeNil())
+			// The archived ConfigMap is still in the cluster
+			Expect(restore.Status.Plan.Unchanged).To(BeNumerically(">=", 1))
+			Expect(restore.Status.Plan.Create).To(BeZero())
+			Expect(restore.Status.Summary).To(BeNil())
+		})
+	})
+})
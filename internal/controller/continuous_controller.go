@@ -0,0 +1,226 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+// continuousStatusInterval is how often the change log state is copied
+// into the status of a ClusterBackup.
+const continuousStatusInterval = 30 * time.Second
+
+// ContinuousBackupReconciler keeps a change log running for every
+// ClusterBackup with spec.continuous set.
+type ContinuousBackupReconciler struct {
+	client.Client
+	Scheme        *runtime.Scheme
+	BackupManager *backup.BackupManager
+
+	mu       sync.Mutex
+	watchers map[types.NamespacedName]*changeWatcher
+}
+
+// changeWatcher is the change log of one ClusterBackup generation.
+type changeWatcher struct {
+	generation int64
+	cancel     context.CancelFunc
+	changeLog  *backup.ChangeLog
+}
+
+// +kubebuilder:rbac:groups="",resources=*,verbs=watch
+// +kubebuilder:rbac:groups="*",resources=*,verbs=watch
+
+// Reconcile starts, restarts or stops the change log of a ClusterBackup and
+// reports its state in status.continuous.
+func (r *ContinuousBackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	clusterBackup := &backupv1alpha1.ClusterBackup{}
+	if err := r.Get(ctx, req.NamespacedName, clusterBackup); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.stop(req.NamespacedName)
+			forgetContinuousStats(req.Namespace, req.Name)
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get ClusterBackup")
+		return ctrl.Result{}, err
+	}
+
+	if clusterBackup.Spec.Continuous == nil || !clusterBackup.DeletionTimestamp.IsZero() {
+		r.stop(req.NamespacedName)
+		forgetContinuousStats(req.Namespace, req.Name)
+		if clusterBackup.Status.Continuous == nil {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, r.patchStatus(ctx, clusterBackup, nil)
+	}
+
+	watcher, err := r.ensure(ctx, clusterBackup)
+	status := &backupv1alpha1.ContinuousBackupStatus{}
+	if err != nil {
+		log.Error(err, "Failed to start change log")
+		status.Message = err.Error()
+	} else {
+		stats := watcher.changeLog.Stats()
+		status.Active = true
+		status.Segment = stats.Segment
+		status.RecordedChanges = stats.Records
+		if !stats.LastChange.IsZero() {
+			lastChange := metav1.NewTime(stats.LastChange)
+			status.LastChangeTime = &lastChange
+		}
+		if stats.Err != nil {
+			status.Message = stats.Err.Error()
+		}
+		recordContinuousStats(clusterBackup.Namespace, clusterBackup.Name, stats)
+	}
+	// The counters move with every recorded change. Patching them each
+	// interval would make the ClusterBackup controller's status writes
+	// conflict, so they are only refreshed along with a state change and
+	// exported as metrics in between.
+	if current := clusterBackup.Status.Continuous; current == nil || current.Active != status.Active ||
+		current.Segment != status.Segment || current.Message != status.Message {
+		if err := r.patchStatus(ctx, clusterBackup, status); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: continuousStatusInterval}, nil
+}
+
+// ensure returns the change log of the current generation of
+// clusterBackup, starting it first if needed.
+func (r *ContinuousBackupReconciler) ensure(ctx context.Context, clusterBackup *backupv1alpha1.ClusterBackup) (*changeWatcher, error) {
+	key := client.ObjectKeyFromObject(clusterBackup)
+	r.mu.Lock()
+	watcher := r.watchers[key]
+	r.mu.Unlock()
+	if watcher != nil && watcher.generation == clusterBackup.Generation {
+		return watcher, nil
+	}
+	r.stop(key)
+
+	spec := clusterBackup.DeepCopy()
+	if err := applyBackupPolicy(ctx, r.Client, spec); err != nil {
+		return nil, err
+	}
+	if spec.Spec.Encryption != nil {
+		return nil, errors.New("change logs are not encrypted, so continuous backups cannot be combined with encryption")
+	}
+	if spec.Spec.SecretEncryption != nil {
+		return nil, errors.New("change logs are not encrypted, so continuous backups cannot be combined with secretEncryption")
+	}
+	config, err := loadOperatorConfig(ctx, r.Client)
+	if err != nil {
+		return nil, err
+	}
+	selection, err := selectionOptions(spec, config)
+	if err != nil {
+		return nil, err
+	}
+	bm, err := targetManager(ctx, r.Client, r.BackupManager, spec.Namespace, spec.Spec.ClusterRef, spec.Spec.Impersonate)
+	if err != nil {
+		return nil, err
+	}
+	opts := backup.ContinuousOptions{Selection: selection}
+	if interval := spec.Spec.Continuous.SegmentInterval; interval != nil {
+		opts.SegmentInterval = interval.Duration
+	}
+
+	// The watch outlives this reconcile and stops with the manager
+	watchCtx, cancel := context.WithCancel(logf.IntoContext(context.Background(), logf.FromContext(ctx)))
+	changeLog, err := bm.WatchChanges(watchCtx, storagePathFor(spec, config), opts)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	watcher = &changeWatcher{generation: clusterBackup.Generation, cancel: cancel, changeLog: changeLog}
+	r.mu.Lock()
+	if r.watchers == nil {
+		r.watchers = map[types.NamespacedName]*changeWatcher{}
+	}
+	r.watchers[key] = watcher
+	r.mu.Unlock()
+	return watcher, nil
+}
+
+// stop ends the change log of a ClusterBackup, if one is running.
+func (r *ContinuousBackupReconciler) stop(key types.NamespacedName) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if watcher, ok := r.watchers[key]; ok {
+		watcher.cancel()
+		delete(r.watchers, key)
+	}
+}
+
+// stopAll ends every change log.
+func (r *ContinuousBackupReconciler) stopAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, watcher := range r.watchers {
+		watcher.cancel()
+		delete(r.watchers, key)
+	}
+}
+
+// patchStatus replaces status.continuous without touching the fields the
+// ClusterBackup controller owns.
+func (r *ContinuousBackupReconciler) patchStatus(ctx context.Context, clusterBackup *backupv1alpha1.ClusterBackup,
+	status *backupv1alpha1.ContinuousBackupStatus) error {
+	patch := client.MergeFrom(clusterBackup.DeepCopy())
+	clusterBackup.Status.Continuous = status
+	if err := r.Status().Patch(ctx, clusterBackup, patch); err != nil && !apierrors.IsNotFound(err) {
+		logf.FromContext(ctx).Error(err, "Failed to update continuous backup status")
+		return err
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ContinuousBackupReconciler) SetupWithManager(mgr ctrl.Manager, options Options) error {
+	if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		r.stopAll()
+		return nil
+	})); err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		// Status updates must not restart the watch
+		For(&backupv1alpha1.ClusterBackup{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Named("continuousbackup").
+		WithOptions(options.forController("continuousbackup")).
+		Complete(r)
+}
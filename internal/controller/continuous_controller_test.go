@@ -0,0 +1,97 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+var _ = Describe("ContinuousBackup Controller", func() {
+	ctx := context.Background()
+	typeNamespacedName := types.NamespacedName{Name: "test-continuous", Namespace: "default"}
+
+	AfterEach(func() {
+		resource := &backupv1alpha1.ClusterBackup{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+	})
+
+	It("should record changes while spec.continuous is set", func() {
+		Expect(k8sClient.Create(ctx, &backupv1alpha1.ClusterBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: typeNamespacedName.Name, Namespace: typeNamespacedName.Namespace},
+			Spec: backupv1alpha1.ClusterBackupSpec{
+				StoragePath:       GinkgoT().TempDir(),
+				IncludeNamespaces: []string{"default"},
+				ResourceTypes:     []string{"ConfigMap"},
+				Continuous:        &backupv1alpha1.ContinuousBackup{},
+			},
+		})).To(Succeed())
+
+		bm, err := backup.NewBackupManager(cfg)
+		Expect(err).NotTo(HaveOccurred())
+		reconciler := &ContinuousBackupReconciler{Client: k8sClient, Scheme: k8sClient.Scheme(), BackupManager: bm}
+		defer reconciler.stopAll()
+		result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(continuousStatusInterval))
+
+		configMap := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "continuous-settings", Namespace: "default"},
+			Data:       map[string]string{"key": "value"},
+		}
+		Expect(k8sClient.Create(ctx, configMap)).To(Succeed())
+		defer func() { Expect(k8sClient.Delete(ctx, configMap)).To(Succeed()) }()
+
+		clusterBackup := &backupv1alpha1.ClusterBackup{}
+		Eventually(func(g Gomega) {
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(k8sClient.Get(ctx, typeNamespacedName, clusterBackup)).To(Succeed())
+			g.Expect(clusterBackup.Status.Continuous).NotTo(BeNil())
+			g.Expect(clusterBackup.Status.Continuous.Active).To(BeTrue())
+			g.Expect(clusterBackup.Status.Continuous.Segment).NotTo(BeEmpty())
+			g.Expect(reconciler.watchers[typeNamespacedName].changeLog.Stats().Records).To(BeNumerically(">=", 1))
+		}, 10*time.Second, 100*time.Millisecond).Should(Succeed())
+
+		By("not patching the status while only the counters move")
+		resourceVersion := clusterBackup.ResourceVersion
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, typeNamespacedName, clusterBackup)).To(Succeed())
+		Expect(clusterBackup.ResourceVersion).To(Equal(resourceVersion))
+
+		By("stopping when spec.continuous is unset")
+		clusterBackup.Spec.Continuous = nil
+		Expect(k8sClient.Update(ctx, clusterBackup)).To(Succeed())
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, typeNamespacedName, clusterBackup)).To(Succeed())
+		Expect(clusterBackup.Status.Continuous).To(BeNil())
+		Expect(reconciler.watchers).To(BeEmpty())
+	})
+})
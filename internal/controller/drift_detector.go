@@ -0,0 +1,147 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+// driftDetectedCondition reports whether live resources differ from the
+// latest successful archive of a ClusterBackup.
+const driftDetectedCondition = "DriftDetected"
+
+// DriftDetector periodically compares the latest successful archive of every
+// ClusterBackup with the live resources in its scope. Results are surfaced
+// as a DriftDetected condition on each ClusterBackup and as metrics listing
+// the drifted objects.
+type DriftDetector struct {
+	Client        client.Client
+	BackupManager *backup.BackupManager
+	Interval      time.Duration
+}
+
+// NeedLeaderElection makes only the leader compare archives and update
+// status.
+func (d *DriftDetector) NeedLeaderElection() bool {
+	return true
+}
+
+// Start compares all ClusterBackups every Interval until ctx is done.
+func (d *DriftDetector) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, d.detectAll, d.Interval)
+	return nil
+}
+
+// detectAll compares every ClusterBackup with a successful archive.
+func (d *DriftDetector) detectAll(ctx context.Context) {
+	log := logf.FromContext(ctx).WithName("drift-detector")
+
+	var list backupv1alpha1.ClusterBackupList
+	if err := d.Client.List(ctx, &list); err != nil {
+		log.Error(err, "Failed to list ClusterBackups")
+		return
+	}
+
+	config, err := loadOperatorConfig(ctx, d.Client)
+	if err != nil {
+		log.Error(err, "Failed to load operator configuration")
+		return
+	}
+
+	for i := range list.Items {
+		clusterBackup := &list.Items[i]
+		if !clusterBackup.DeletionTimestamp.IsZero() || clusterBackup.Status.BackupLocation == "" {
+			continue
+		}
+		result, err := d.detect(ctx, clusterBackup, config)
+		if err != nil {
+			log.Error(err, "Failed to compare archive with the cluster",
+				"namespace", clusterBackup.Namespace, "name", clusterBackup.Name)
+		}
+		recordDrift(clusterBackup.Namespace, clusterBackup.Name, result)
+		if err := patchBackupCondition(ctx, d.Client, clusterBackup, driftCondition(result, err)); err != nil {
+			log.Error(err, "Failed to update drift condition",
+				"namespace", clusterBackup.Namespace, "name", clusterBackup.Name)
+		}
+	}
+}
+
+// detect compares the latest successful archive of clusterBackup with the
+// cluster it was taken from.
+func (d *DriftDetector) detect(ctx context.Context, clusterBackup *backupv1alpha1.ClusterBackup,
+	config *backupv1alpha1.BackupOperatorConfigSpec) (*backup.DiffResult, error) {
+	bm, err := targetManager(ctx, d.Client, d.BackupManager, clusterBackup.Namespace,
+		clusterBackup.Spec.ClusterRef, clusterBackup.Spec.Impersonate)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts backup.DiffOptions
+	if restore := clusterBackup.Spec.Restore; restore != nil {
+		if opts.KeyWrappers, err = ageKeyWrappers(ctx, d.Client, clusterBackup.Namespace, restore.AgeIdentitySecretRef); err != nil {
+			return nil, err
+		}
+	}
+
+	archiveName := filepath.Base(clusterBackup.Status.BackupLocation)
+	storagePath := storagePathFor(clusterBackup, config)
+	// Archives moved to another tier are read from where they are now
+	for _, archive := range clusterBackup.Status.Archives {
+		if archive.Name == archiveName {
+			storagePath = archive.StoragePath
+		}
+	}
+	return bm.DiffArchive(ctx, storagePath, archiveName, opts)
+}
+
+// driftCondition builds the DriftDetected condition for a comparison.
+func driftCondition(result *backup.DiffResult, diffErr error) metav1.Condition {
+	switch {
+	case diffErr != nil:
+		return metav1.Condition{Type: driftDetectedCondition, Status: metav1.ConditionUnknown, Reason: "DiffFailed", Message: diffErr.Error()}
+	case result.Drifted():
+		return metav1.Condition{Type: driftDetectedCondition, Status: metav1.ConditionTrue, Reason: "DriftDetected", Message: result.String()}
+	default:
+		return metav1.Condition{Type: driftDetectedCondition, Status: metav1.ConditionFalse, Reason: "NoDrift", Message: "Live resources match the latest archive"}
+	}
+}
+
+// patchBackupCondition patches a status condition of clusterBackup if it
+// changed. Conflicts are ignored; the next round retries with a fresh copy.
+func patchBackupCondition(ctx context.Context, c client.Client, clusterBackup *backupv1alpha1.ClusterBackup, condition metav1.Condition) error {
+	condition.ObservedGeneration = clusterBackup.Generation
+	patch := client.MergeFromWithOptions(clusterBackup.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	if !meta.SetStatusCondition(&clusterBackup.Status.Conditions, condition) {
+		return nil
+	}
+	if err := c.Status().Patch(ctx, clusterBackup, patch); err != nil && !errors.IsConflict(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+var _ = Describe("Drift detector", func() {
+	ctx := context.Background()
+	typeNamespacedName := types.NamespacedName{Name: "test-drift", Namespace: "default"}
+
+	AfterEach(func() {
+		resource := &backupv1alpha1.ClusterBackup{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+	})
+
+	It("should only compare backups with a successful archive", func() {
+		storagePath := GinkgoT().TempDir()
+		Expect(k8sClient.Create(ctx, &backupv1alpha1.ClusterBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: typeNamespacedName.Name, Namespace: typeNamespacedName.Namespace},
+			Spec:       backupv1alpha1.ClusterBackupSpec{StoragePath: storagePath},
+		})).To(Succeed())
+
+		detector := &DriftDetector{Client: k8sClient, BackupManager: &backup.BackupManager{}}
+		detector.detectAll(ctx)
+
+		clusterBackup := &backupv1alpha1.ClusterBackup{}
+		Expect(k8sClient.Get(ctx, typeNamespacedName, clusterBackup)).To(Succeed())
+		Expect(meta.FindStatusCondition(clusterBackup.Status.Conditions, driftDetectedCondition)).To(BeNil())
+
+		By("recording an archive that can no longer be read")
+		clusterBackup.Status.Phase = "Completed"
+		clusterBackup.Status.BackupLocation = filepath.Join(storagePath, "cluster-backup-20250101-000000.tar.gz")
+		Expect(k8sClient.Status().Update(ctx, clusterBackup)).To(Succeed())
+		detector.detectAll(ctx)
+
+		Expect(k8sClient.Get(ctx, typeNamespacedName, clusterBackup)).To(Succeed())
+		condition := meta.FindStatusCondition(clusterBackup.Status.Conditions, driftDetectedCondition)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionUnknown))
+		Expect(condition.Reason).To(Equal("DiffFailed"))
+	})
+})
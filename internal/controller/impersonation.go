@@ -0,0 +1,65 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+// +kubebuilder:rbac:groups="",resources=users;groups;serviceaccounts,verbs=impersonate
+
+// impersonationIdentity returns the identity impersonate names for a
+// resource in namespace, or nil when it is unset.
+func impersonationIdentity(namespace string, impersonate *backupv1alpha1.Impersonation) *backup.Identity {
+	switch {
+	case impersonate == nil:
+		return nil
+	case impersonate.ServiceAccountName != "":
+		identity := backup.ServiceAccountIdentity(namespace, impersonate.ServiceAccountName)
+		return &identity
+	}
+	return &backup.Identity{User: impersonate.User, Groups: impersonate.Groups}
+}
+
+// impersonatedUser returns the user name impersonate acts as, or an empty
+// string for the operator itself.
+func impersonatedUser(namespace string, impersonate *backupv1alpha1.Impersonation) string {
+	if identity := impersonationIdentity(namespace, impersonate); identity != nil {
+		return identity.User
+	}
+	return ""
+}
+
+// impersonatedManager returns bm acting as impersonate, or bm itself when it
+// is unset.
+func impersonatedManager(bm *backup.BackupManager, namespace string, impersonate *backupv1alpha1.Impersonation) (*backup.BackupManager, error) {
+	identity := impersonationIdentity(namespace, impersonate)
+	if identity == nil {
+		return bm, nil
+	}
+	return bm.Impersonate(*identity)
+}
+
+// restoreImpersonation returns the identity inline restores of
+// clusterBackup act as.
+func restoreImpersonation(clusterBackup *backupv1alpha1.ClusterBackup) *backupv1alpha1.Impersonation {
+	if restore := clusterBackup.Spec.Restore; restore != nil && restore.Impersonate != nil {
+		return restore.Impersonate
+	}
+	return clusterBackup.Spec.Impersonate
+}
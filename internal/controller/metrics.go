@@ -0,0 +1,316 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+var (
+	// backupLastSuccessTimestamp records when each ClusterBackup last
+	// completed successfully, so stalled schedules can be alerted on.
+	backupLastSuccessTimestamp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "backup_last_success_timestamp",
+			Help: "Unix timestamp of the last successful backup of a ClusterBackup.",
+		},
+		[]string{"namespace", "name"},
+	)
+
+	// backupRunsTotal counts finished backup runs by result ("success" or
+	// "failure").
+	backupRunsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "backup_runs_total",
+			Help: "Number of finished backup runs of a ClusterBackup, by result.",
+		},
+		[]string{"namespace", "name", "result"},
+	)
+
+	// backupLastDurationSeconds records how long the most recent backup run
+	// took, so a steadily growing duration can be alerted on.
+	backupLastDurationSeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "backup_last_duration_seconds",
+			Help: "Duration in seconds of the most recent backup run of a ClusterBackup.",
+		},
+		[]string{"namespace", "name"},
+	)
+
+	// backupLastRunInfo carries the ID and result of the most recent backup
+	// run as labels, joining metrics to the logs and events of that run.
+	backupLastRunInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "backup_last_run_info",
+			Help: "Always 1; labels identify the most recent backup run of a ClusterBackup.",
+		},
+		[]string{"namespace", "name", "run_id", "result"},
+	)
+
+	// restoreLastRunInfo carries the ID and result of the most recent
+	// restore run of a ClusterBackup or ClusterRestore.
+	restoreLastRunInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "restore_last_run_info",
+			Help: "Always 1; labels identify the most recent restore run of a ClusterBackup or ClusterRestore.",
+		},
+		[]string{"namespace", "name", "kind", "run_id", "result"},
+	)
+
+	// continuousRecordedChanges counts the changes the change log of a
+	// ClusterBackup recorded since its watch started.
+	continuousRecordedChanges = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "backup_continuous_recorded_changes",
+			Help: "Number of changes recorded by the change log of a ClusterBackup since its watch started.",
+		},
+		[]string{"namespace", "name"},
+	)
+
+	// continuousLastChangeTimestamp records when the change log of a
+	// ClusterBackup last recorded a change.
+	continuousLastChangeTimestamp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "backup_continuous_last_change_timestamp",
+			Help: "Unix timestamp of the latest change recorded by the change log of a ClusterBackup.",
+		},
+		[]string{"namespace", "name"},
+	)
+
+	// backupStale mirrors the Stale condition of scheduled backups.
+	backupStale = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "backup_stale",
+			Help: "Whether a scheduled ClusterBackup is overdue (1) or not (0).",
+		},
+		[]string{"namespace", "name"},
+	)
+
+	// backupConsecutiveFailures counts the runs that failed since the last
+	// success, and backupDegraded mirrors the Degraded condition.
+	backupConsecutiveFailures = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "backup_consecutive_failures",
+			Help: "Number of runs of a ClusterBackup that failed in a row since its last success.",
+		},
+		[]string{"namespace", "name"},
+	)
+	backupDegraded = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "backup_degraded",
+			Help: "Whether a ClusterBackup failed too many runs in a row (1) or not (0).",
+		},
+		[]string{"namespace", "name"},
+	)
+
+	// backupDriftObjects counts the objects that differ from the latest
+	// archive of a ClusterBackup, by state ("Missing", "Changed" or "Extra").
+	backupDriftObjects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "backup_drift_objects",
+			Help: "Number of live objects differing from the latest archive of a ClusterBackup, by state.",
+		},
+		[]string{"namespace", "name", "state"},
+	)
+
+	// backupDriftedObject lists the drifted objects themselves, capped at
+	// backup.MaxReportedDrift per ClusterBackup.
+	backupDriftedObject = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "backup_drifted_object",
+			Help: "Always 1; labels identify an object differing from the latest archive of a ClusterBackup.",
+		},
+		[]string{"namespace", "name", "resource", "object_namespace", "object_name", "state"},
+	)
+
+	// backupResourceDurationSeconds and backupResourceListDurationSeconds
+	// break the most recent backup run down per resource type, so the APIs
+	// dominating its duration can be found.
+	backupResourceDurationSeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "backup_resource_duration_seconds",
+			Help: "Seconds the most recent backup run of a ClusterBackup spent listing and archiving a resource type.",
+		},
+		[]string{"namespace", "name", "resource"},
+	)
+	backupResourceListDurationSeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "backup_resource_list_duration_seconds",
+			Help: "Seconds the most recent backup run of a ClusterBackup spent waiting for List calls of a resource type.",
+		},
+		[]string{"namespace", "name", "resource"},
+	)
+
+	// storageArchives and storageBytes track what each storage location
+	// holds, so capacity pressure shows before retention falls behind. They
+	// are keyed by location because backups may share one.
+	storageArchives = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "backup_storage_archives",
+			Help: "Number of archives held by a storage location.",
+		},
+		[]string{"storage_path"},
+	)
+	storageBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "backup_storage_bytes",
+			Help: "Combined size in bytes of the archives held by a storage location.",
+		},
+		[]string{"storage_path"},
+	)
+
+	// archiveVerificationFailures counts the cataloged archives of a
+	// ClusterBackup that failed their latest verification.
+	archiveVerificationFailures = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "backup_archive_verification_failures",
+			Help: "Number of cataloged archives of a ClusterBackup that failed their latest verification.",
+		},
+		[]string{"namespace", "name"},
+	)
+
+	// cloudEventsFailedTotal counts CloudEvents that could not be published,
+	// by sink ("cloudevents" or a notification provider) and event type.
+	cloudEventsFailedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cloudevents_publish_failures_total",
+			Help: "Number of CloudEvents that could not be delivered to a sink, by sink and type.",
+		},
+		[]string{"sink", "type"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		backupLastSuccessTimestamp,
+		backupRunsTotal,
+		backupLastDurationSeconds,
+		backupLastRunInfo,
+		restoreLastRunInfo,
+		continuousRecordedChanges,
+		continuousLastChangeTimestamp,
+		backupStale,
+		backupConsecutiveFailures,
+		backupDegraded,
+		backupDriftObjects,
+		backupDriftedObject,
+		backupResourceDurationSeconds,
+		backupResourceListDurationSeconds,
+		storageArchives,
+		storageBytes,
+		archiveVerificationFailures,
+		cloudEventsFailedTotal,
+	)
+}
+
+// recordBackupRun updates the run counter, duration gauge and last run info
+// for a finished backup run.
+func recordBackupRun(namespace, name, runID, result string, duration time.Duration) {
+	backupRunsTotal.WithLabelValues(namespace, name, result).Inc()
+	backupLastDurationSeconds.WithLabelValues(namespace, name).Set(duration.Seconds())
+	// Only the latest run is kept, so run IDs do not pile up as series
+	backupLastRunInfo.DeletePartialMatch(prometheus.Labels{"namespace": namespace, "name": name})
+	backupLastRunInfo.WithLabelValues(namespace, name, runID, result).Set(1)
+}
+
+// recordContinuousStats exports the counters of a running change log.
+func recordContinuousStats(namespace, name string, stats backup.ChangeLogStats) {
+	continuousRecordedChanges.WithLabelValues(namespace, name).Set(float64(stats.Records))
+	if !stats.LastChange.IsZero() {
+		continuousLastChangeTimestamp.WithLabelValues(namespace, name).Set(float64(stats.LastChange.Unix()))
+	}
+}
+
+// forgetContinuousStats drops the change log series of a ClusterBackup once
+// its change log stops.
+func forgetContinuousStats(namespace, name string) {
+	continuousRecordedChanges.DeleteLabelValues(namespace, name)
+	continuousLastChangeTimestamp.DeleteLabelValues(namespace, name)
+}
+
+// recordResourceTimings replaces the per resource type series of a
+// ClusterBackup with those of its latest run.
+func recordResourceTimings(namespace, name string, timings []backup.ResourceTiming) {
+	labels := prometheus.Labels{"namespace": namespace, "name": name}
+	backupResourceDurationSeconds.DeletePartialMatch(labels)
+	backupResourceListDurationSeconds.DeletePartialMatch(labels)
+	for _, timing := range timings {
+		resource := timing.GVR.GroupResource().String()
+		backupResourceDurationSeconds.WithLabelValues(namespace, name, resource).Set(timing.Duration.Seconds())
+		backupResourceListDurationSeconds.WithLabelValues(namespace, name, resource).Set(timing.List.Seconds())
+	}
+}
+
+// recordStorageUsage updates the usage series of a storage location.
+func recordStorageUsage(storagePath string, usage backup.StorageUsage) {
+	storageArchives.WithLabelValues(storagePath).Set(float64(usage.Archives))
+	storageBytes.WithLabelValues(storagePath).Set(float64(usage.Bytes))
+}
+
+// recordRestoreRun replaces the last restore run info of a ClusterBackup or
+// ClusterRestore. result is "success", "partial" or "failure".
+func recordRestoreRun(namespace, name, kind, runID, result string) {
+	deleteRestoreMetrics(namespace, name, kind)
+	restoreLastRunInfo.WithLabelValues(namespace, name, kind, runID, result).Set(1)
+}
+
+// deleteRestoreMetrics drops the restore series of a ClusterBackup or
+// ClusterRestore.
+func deleteRestoreMetrics(namespace, name, kind string) {
+	restoreLastRunInfo.DeletePartialMatch(prometheus.Labels{"namespace": namespace, "name": name, "kind": kind})
+}
+
+// recordDrift replaces the drift series of a ClusterBackup. A nil result,
+// from a comparison that failed, only drops them.
+func recordDrift(namespace, name string, result *backup.DiffResult) {
+	labels := prometheus.Labels{"namespace": namespace, "name": name}
+	backupDriftObjects.DeletePartialMatch(labels)
+	backupDriftedObject.DeletePartialMatch(labels)
+	if result == nil {
+		return
+	}
+	backupDriftObjects.WithLabelValues(namespace, name, string(backup.DriftMissing)).Set(float64(result.Missing))
+	backupDriftObjects.WithLabelValues(namespace, name, string(backup.DriftChanged)).Set(float64(result.Changed))
+	backupDriftObjects.WithLabelValues(namespace, name, string(backup.DriftExtra)).Set(float64(result.Extra))
+	for _, item := range result.Items {
+		backupDriftedObject.WithLabelValues(namespace, name, item.GVR.GroupResource().String(),
+			item.Namespace, item.Name, string(item.State)).Set(1)
+	}
+}
+
+// deleteBackupMetrics drops all series of a deleted ClusterBackup.
+func deleteBackupMetrics(namespace, name string) {
+	labels := prometheus.Labels{"namespace": namespace, "name": name}
+	backupLastSuccessTimestamp.DeletePartialMatch(labels)
+	backupRunsTotal.DeletePartialMatch(labels)
+	backupLastDurationSeconds.DeletePartialMatch(labels)
+	backupLastRunInfo.DeletePartialMatch(labels)
+	backupStale.DeletePartialMatch(labels)
+	backupConsecutiveFailures.DeletePartialMatch(labels)
+	backupDegraded.DeletePartialMatch(labels)
+	backupDriftObjects.DeletePartialMatch(labels)
+	backupDriftedObject.DeletePartialMatch(labels)
+	backupResourceDurationSeconds.DeletePartialMatch(labels)
+	backupResourceListDurationSeconds.DeletePartialMatch(labels)
+	archiveVerificationFailures.DeletePartialMatch(labels)
+	deleteRestoreMetrics(namespace, name, "ClusterBackup")
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	monitoringGroupVersion = "monitoring.coreos.com/v1"
+	monitoringFieldOwner   = "backup-operator"
+)
+
+// MonitoringInstaller creates a ServiceMonitor and PrometheusRule for the
+// operator when the prometheus-operator APIs are served by the cluster. It
+// runs once on the leader at startup.
+type MonitoringInstaller struct {
+	Client    client.Client
+	Discovery discovery.DiscoveryInterface
+
+	// Namespace the monitoring objects are created in, normally the
+	// operator's own namespace.
+	Namespace string
+	// NamePrefix is prepended to the names of the created objects.
+	NamePrefix string
+	// ServiceSelector selects the metrics Service to scrape.
+	ServiceSelector map[string]string
+	// MetricsPort is the name of the metrics port on that Service.
+	MetricsPort string
+}
+
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors;prometheusrules,verbs=get;create;patch
+
+// NeedLeaderElection makes only the elected leader install the objects.
+func (m *MonitoringInstaller) NeedLeaderElection() bool {
+	return true
+}
+
+// Start installs the monitoring objects, or does nothing if the
+// monitoring.coreos.com API group is not available.
+func (m *MonitoringInstaller) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx).WithName("monitoring")
+
+	available, err := m.monitoringAvailable()
+	if err != nil {
+		return err
+	}
+	if !available {
+		log.Info("monitoring.coreos.com API not found, skipping ServiceMonitor and PrometheusRule")
+		return nil
+	}
+
+	for _, obj := range []*unstructured.Unstructured{m.serviceMonitor(), m.prometheusRule()} {
+		if err := m.Client.Patch(ctx, obj, client.Apply,
+			client.ForceOwnership, client.FieldOwner(monitoringFieldOwner)); err != nil {
+			return fmt.Errorf("failed to apply %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		log.Info("Applied monitoring object", "kind", obj.GetKind(), "name", obj.GetName())
+	}
+	return nil
+}
+
+// monitoringAvailable reports whether both ServiceMonitor and PrometheusRule
+// are served by the apiserver.
+func (m *MonitoringInstaller) monitoringAvailable() (bool, error) {
+	resources, err := m.Discovery.ServerResourcesForGroupVersion(monitoringGroupVersion)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to discover %s: %w", monitoringGroupVersion, err)
+	}
+
+	found := map[string]bool{}
+	for _, r := range resources.APIResources {
+		found[r.Kind] = true
+	}
+	return found["ServiceMonitor"] && found["PrometheusRule"], nil
+}
+
+func (m *MonitoringInstaller) newObject(kind, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(monitoringGroupVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(m.Namespace)
+	obj.SetName(m.NamePrefix + name)
+	obj.SetLabels(map[string]string{
+		"app.kubernetes.io/name":       "backup-operator",
+		"app.kubernetes.io/managed-by": monitoringFieldOwner,
+	})
+	return obj
+}
+
+// serviceMonitor scrapes the operator's HTTPS metrics endpoint with the
+// Prometheus service account token.
+func (m *MonitoringInstaller) serviceMonitor() *unstructured.Unstructured {
+	obj := m.newObject("ServiceMonitor", "metrics-monitor")
+
+	matchLabels := map[string]interface{}{}
+	for k, v := range m.ServiceSelector {
+		matchLabels[k] = v
+	}
+	obj.Object["spec"] = map[string]interface{}{
+		"endpoints": []interface{}{
+			map[string]interface{}{
+				"path":            "/metrics",
+				"port":            m.MetricsPort,
+				"scheme":          "https",
+				"bearerTokenFile": "/var/run/secrets/kubernetes.io/serviceaccount/token",
+				"tlsConfig": map[string]interface{}{
+					"insecureSkipVerify": true,
+				},
+			},
+		},
+		"selector": map[string]interface{}{
+			"matchLabels": matchLabels,
+		},
+	}
+	return obj
+}
+
+// prometheusRule alerts on failed, degraded, stale and slowing backups.
+func (m *MonitoringInstaller) prometheusRule() *unstructured.Unstructured {
+	obj := m.newObject("PrometheusRule", "alerts")
+
+	rules := []interface{}{
+		alertRule("ClusterBackupFailed",
+			`increase(backup_runs_total{result="failure"}[1h]) > 0`,
+			"0m", "warning",
+			"ClusterBackup {{ $labels.namespace }}/{{ $labels.name }} failed",
+			"A backup run of {{ $labels.namespace }}/{{ $labels.name }} failed within the last hour."),
+		alertRule("ClusterBackupDegraded",
+			`backup_consecutive_failures and on(namespace, name) backup_degraded == 1`,
+			"0m", "critical",
+			"ClusterBackup {{ $labels.namespace }}/{{ $labels.name }} keeps failing",
+			"The last {{ $value }} runs of {{ $labels.namespace }}/{{ $labels.name }} failed."),
+		alertRule("ClusterBackupStale",
+			`backup_stale == 1`,
+			"15m", "critical",
+			"ClusterBackup {{ $labels.namespace }}/{{ $labels.name }} is overdue",
+			"No successful backup of {{ $labels.namespace }}/{{ $labels.name }} within the expected schedule."),
+		alertRule("ClusterBackupDurationGrowing",
+			`backup_last_duration_seconds > 2 * avg_over_time(backup_last_duration_seconds[7d]) and backup_last_duration_seconds > 300`,
+			"1h", "info",
+			"ClusterBackup {{ $labels.namespace }}/{{ $labels.name }} is getting slower",
+			"The last backup of {{ $labels.namespace }}/{{ $labels.name }} took more than twice its weekly average."),
+	}
+
+	obj.Object["spec"] = map[string]interface{}{
+		"groups": []interface{}{
+			map[string]interface{}{
+				"name":  "backup-operator",
+				"rules": rules,
+			},
+		},
+	}
+	return obj
+}
+
+func alertRule(name, expr, forDuration, severity, summary, description string) map[string]interface{} {
+	return map[string]interface{}{
+		"alert": name,
+		"expr":  expr,
+		"for":   forDuration,
+		"labels": map[string]interface{}{
+			"severity": severity,
+		},
+		"annotations": map[string]interface{}{
+			"summary":     summary,
+			"description": description,
+		},
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+	crcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// controllerNames are the names the controllers are registered under.
+var controllerNames = []string{
+	"archivediff",
+	"archivereplication",
+	"archivetransfer",
+	"backupoperatorconfig",
+	"backuppolicy",
+	"clusterbackup",
+	"clusterbackupset",
+	"cleanuppolicy",
+	"clusterrestore",
+	"continuousbackup",
+}
+
+// Options tunes the workers and workqueues of the controllers, so busy
+// clusters with many objects are not serialized behind a single worker.
+type Options struct {
+	// MaxConcurrentReconciles is the number of objects every controller
+	// reconciles in parallel. Zero uses 1.
+	MaxConcurrentReconciles int
+	// ConcurrentReconciles overrides MaxConcurrentReconciles per controller
+	// name, e.g. "clusterbackup".
+	ConcurrentReconciles map[string]int
+
+	// BaseDelay and MaxDelay bound the exponential backoff between retries
+	// of a failing object, and QPS and Burst limit how fast each controller
+	// requeues objects overall. Zero values keep the controller-runtime
+	// defaults of 5ms, 1000s, 10 and 100.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	QPS       float64
+	Burst     int
+}
+
+// Validate rejects unknown controller names and negative values.
+func (o Options) Validate() error {
+	if o.MaxConcurrentReconciles < 0 {
+		return fmt.Errorf("max concurrent reconciles must not be negative")
+	}
+	for name, workers := range o.ConcurrentReconciles {
+		if !slices.Contains(controllerNames, name) {
+			return fmt.Errorf("unknown controller %q, expected one of %s", name, strings.Join(controllerNames, ", "))
+		}
+		if workers < 1 {
+			return fmt.Errorf("controller %q needs at least one worker", name)
+		}
+	}
+	if o.BaseDelay < 0 || o.MaxDelay < 0 || o.QPS < 0 || o.Burst < 0 {
+		return fmt.Errorf("workqueue rate limits must not be negative")
+	}
+	return nil
+}
+
+// forController returns the options of the controller registered as name.
+// Every controller gets its own rate limiter.
+func (o Options) forController(name string) crcontroller.Options {
+	workers := o.MaxConcurrentReconciles
+	if n, ok := o.ConcurrentReconciles[name]; ok {
+		workers = n
+	}
+
+	baseDelay := cmp.Or(o.BaseDelay, 5*time.Millisecond)
+	maxDelay := cmp.Or(o.MaxDelay, 1000*time.Second)
+	qps := cmp.Or(o.QPS, 10)
+	burst := cmp.Or(o.Burst, 100)
+	return crcontroller.Options{
+		MaxConcurrentReconciles: workers,
+		RateLimiter: workqueue.NewTypedMaxOfRateLimiter(
+			workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](baseDelay, maxDelay),
+			&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(qps), burst)},
+		),
+	}
+}
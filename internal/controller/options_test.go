@@ -0,0 +1,52 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Controller options", func() {
+	It("applies per-controller overrides of the worker count", func() {
+		options := Options{MaxConcurrentReconciles: 2, ConcurrentReconciles: map[string]int{"clusterbackup": 8}}
+		Expect(options.Validate()).To(Succeed())
+		Expect(options.forController("clusterbackup").MaxConcurrentReconciles).To(Equal(8))
+		Expect(options.forController("clusterrestore").MaxConcurrentReconciles).To(Equal(2))
+	})
+
+	It("rejects unknown controllers and invalid values", func() {
+		Expect(Options{ConcurrentReconciles: map[string]int{"backups": 2}}.Validate()).To(MatchError(ContainSubstring(`unknown controller "backups"`)))
+		Expect(Options{ConcurrentReconciles: map[string]int{"clusterbackup": 0}}.Validate()).NotTo(Succeed())
+		Expect(Options{MaxDelay: -time.Second}.Validate()).NotTo(Succeed())
+	})
+
+	It("backs off failing objects between the configured delays", func() {
+		limiter := Options{BaseDelay: time.Second, MaxDelay: 4 * time.Second}.forController("clusterbackup").RateLimiter
+		request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "nightly"}}
+		Expect(limiter.When(request)).To(Equal(time.Second))
+		Expect(limiter.When(request)).To(Equal(2 * time.Second))
+		Expect(limiter.When(request)).To(Equal(4 * time.Second))
+		Expect(limiter.When(request)).To(Equal(4 * time.Second))
+		limiter.Forget(request)
+		Expect(limiter.When(request)).To(Equal(time.Second))
+	})
+})
@@ -0,0 +1,62 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+// MinimalClusterRole returns the least-privilege ClusterRole named name that
+// lets the operator read everything clusterBackup selects, after merging its
+// BackupPolicy. Installs that drop the operator's wildcard read rule bind it
+// to the operator's service account instead. For a ClusterBackup with a
+// clusterRef, resources are discovered in the remote cluster, where the role
+// belongs. Continuous backups may also watch the selected resources.
+func MinimalClusterRole(ctx context.Context, c client.Reader, bm *backup.BackupManager, clusterBackup *backupv1alpha1.ClusterBackup, name string) (*rbacv1.ClusterRole, error) {
+	clusterBackup = clusterBackup.DeepCopy()
+	if err := applyBackupPolicy(ctx, c, clusterBackup); err != nil {
+		return nil, err
+	}
+	opts, err := selectionOptions(clusterBackup, &backupv1alpha1.BackupOperatorConfigSpec{})
+	if err != nil {
+		return nil, err
+	}
+	if ref := clusterBackup.Spec.ClusterRef; ref != nil {
+		if bm, err = remoteClusterManager(ctx, c, bm, clusterBackup.Namespace, ref); err != nil {
+			return nil, err
+		}
+	}
+	role, err := bm.MinimalClusterRole(ctx, name, opts)
+	if err != nil {
+		return nil, err
+	}
+	if clusterBackup.Spec.Continuous != nil {
+		for i, rule := range role.Rules {
+			if slices.Equal(rule.Verbs, []string{"get", "list"}) {
+				role.Rules[i].Verbs = append(rule.Verbs, "watch")
+			}
+		}
+	}
+	return role, nil
+}
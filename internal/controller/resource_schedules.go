@@ -0,0 +1,178 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+// resourceCadence is one cadence of a ClusterBackup with resource schedules.
+type resourceCadence struct {
+	// resourceTypes is nil for the resource types following spec.schedule.
+	resourceTypes []string
+	schedule      backup.Schedule
+}
+
+// resourceCadences returns the cadence of spec.schedule followed by those of
+// spec.resourceSchedules.
+func resourceCadences(clusterBackup *backupv1alpha1.ClusterBackup) []resourceCadence {
+	cadences := []resourceCadence{{schedule: backupSchedule(clusterBackup)}}
+	for _, schedule := range clusterBackup.Spec.ResourceSchedules {
+		cadences = append(cadences, resourceCadence{resourceTypes: schedule.ResourceTypes, schedule: backup.IntervalSchedule(schedule.Interval.Duration)})
+	}
+	return cadences
+}
+
+// resourceScheduleStatus returns the status of the cadence with the given
+// resource types, or nil when it has none yet.
+func resourceScheduleStatus(clusterBackup *backupv1alpha1.ClusterBackup, resourceTypes []string) *backupv1alpha1.ResourceScheduleStatus {
+	for i := range clusterBackup.Status.ResourceSchedules {
+		if slices.Equal(clusterBackup.Status.ResourceSchedules[i].ResourceTypes, resourceTypes) {
+			return &clusterBackup.Status.ResourceSchedules[i]
+		}
+	}
+	return nil
+}
+
+// cadenceDue reports whether a cadence last run at lastRun is due at now.
+func cadenceDue(cadence resourceCadence, lastRun *metav1.Time, now time.Time) bool {
+	if lastRun == nil {
+		return true
+	}
+	next := cadence.schedule.Next(lastRun.Time)
+	return !next.IsZero() && !next.After(now)
+}
+
+// nextResourceSchedule reports whether a cadence of clusterBackup is due at
+// now and, when none is, how long until the next one is.
+func nextResourceSchedule(clusterBackup *backupv1alpha1.ClusterBackup, now time.Time) (bool, time.Duration) {
+	var wait time.Duration
+	for _, cadence := range resourceCadences(clusterBackup) {
+		var lastRun *metav1.Time
+		if status := resourceScheduleStatus(clusterBackup, cadence.resourceTypes); status != nil {
+			lastRun = status.LastRunTime
+		}
+		if cadenceDue(cadence, lastRun, now) {
+			return true, 0
+		}
+		next := cadence.schedule.Next(lastRun.Time)
+		if next.IsZero() {
+			continue
+		}
+		if until := next.Sub(now); wait == 0 || until < wait {
+			wait = until
+		}
+	}
+	return false, wait
+}
+
+// startResourceSchedules marks the cadences due at now, or all of them for
+// a manual run, as collected by the run that is starting. Without resource
+// schedules it clears their status.
+func startResourceSchedules(clusterBackup *backupv1alpha1.ClusterBackup, now time.Time, manual bool) {
+	if len(clusterBackup.Spec.ResourceSchedules) == 0 {
+		clusterBackup.Status.ResourceSchedules = nil
+		return
+	}
+	statuses := make([]backupv1alpha1.ResourceScheduleStatus, 0, len(clusterBackup.Spec.ResourceSchedules)+1)
+	for _, cadence := range resourceCadences(clusterBackup) {
+		status := backupv1alpha1.ResourceScheduleStatus{ResourceTypes: cadence.resourceTypes}
+		if existing := resourceScheduleStatus(clusterBackup, cadence.resourceTypes); existing != nil {
+			status = *existing
+		}
+		status.Running = manual || cadenceDue(cadence, status.LastRunTime, now)
+		statuses = append(statuses, status)
+	}
+	clusterBackup.Status.ResourceSchedules = statuses
+}
+
+// finishResourceSchedules records that the current run, successful or not,
+// finished at now for the cadences it collected.
+func finishResourceSchedules(clusterBackup *backupv1alpha1.ClusterBackup, now metav1.Time) {
+	for _, cadence := range resourceCadences(clusterBackup) {
+		status := resourceScheduleStatus(clusterBackup, cadence.resourceTypes)
+		if status == nil {
+			continue
+		}
+		if status.Running {
+			status.LastRunTime = &now
+			status.Running = false
+		}
+		status.NextRunTime = nil
+		if status.LastRunTime == nil {
+			continue
+		}
+		if next := cadence.schedule.Next(status.LastRunTime.Time); !next.IsZero() {
+			next := metav1.NewTime(next)
+			status.NextRunTime = &next
+		}
+	}
+}
+
+// scheduledResourceTypes narrows the resource types of a run to the
+// cadences it collects. selected holds the resource types of spec.schedule
+// before the ones with their own cadence are taken out.
+func scheduledResourceTypes(clusterBackup *backupv1alpha1.ClusterBackup, selected []string) ([]string, error) {
+	if len(clusterBackup.Spec.ResourceSchedules) == 0 {
+		return selected, nil
+	}
+
+	cadences := resourceCadences(clusterBackup)
+	running := make([]resourceCadence, 0, len(cadences))
+	for _, cadence := range cadences {
+		if status := resourceScheduleStatus(clusterBackup, cadence.resourceTypes); status != nil && status.Running {
+			running = append(running, cadence)
+		}
+	}
+	// A run started before the resource schedules changed collects everything
+	if len(running) == 0 {
+		running = cadences
+	}
+
+	ownCadence := map[string]struct{}{}
+	for _, schedule := range clusterBackup.Spec.ResourceSchedules {
+		for _, resourceType := range schedule.ResourceTypes {
+			ownCadence[strings.ToLower(resourceType)] = struct{}{}
+		}
+	}
+	var resourceTypes []string
+	for _, cadence := range running {
+		if cadence.resourceTypes != nil {
+			resourceTypes = append(resourceTypes, cadence.resourceTypes...)
+			continue
+		}
+		remaining := 0
+		for _, resourceType := range selected {
+			if _, ok := ownCadence[strings.ToLower(resourceType)]; !ok {
+				resourceTypes = append(resourceTypes, resourceType)
+				remaining++
+			}
+		}
+		if remaining == 0 {
+			return nil, fmt.Errorf("every selected resource type has its own cadence, so none is left for spec.schedule")
+		}
+	}
+	return resourceTypes, nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/tools/record"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+)
+
+const (
+	// runIDAnnotation carries the run ID on the events of a backup or restore
+	// run.
+	runIDAnnotation = "backup.backup.io/run-id"
+
+	// defaultHistoryLimit is how many runs status.history keeps unless
+	// spec.historyLimit says otherwise.
+	defaultHistoryLimit = 10
+
+	// maxHistoryErrorLength bounds the error summary of a run in
+	// status.history.
+	maxHistoryErrorLength = 256
+)
+
+// newRunID returns the ID correlating the log lines, events, metrics and
+// archive manifest of one backup or restore run.
+func newRunID() string {
+	return string(uuid.NewUUID())
+}
+
+// withRunID returns ctx with runID attached to every line of its logger.
+func withRunID(ctx context.Context, runID string) context.Context {
+	return logf.IntoContext(ctx, logf.FromContext(ctx).WithValues("runID", runID))
+}
+
+// recordRunEvent emits an event on obj annotated with runID. It does nothing
+// without a recorder.
+func recordRunEvent(recorder record.EventRecorder, obj runtime.Object, runID, eventType, reason, messageFmt string, args ...interface{}) {
+	if recorder == nil {
+		return
+	}
+	recorder.AnnotatedEventf(obj, map[string]string{runIDAnnotation: runID}, eventType, reason, messageFmt, args...)
+}
+
+// recordRunHistory adds the run that just finished to the front of the
+// history of clusterBackup and drops the runs beyond its history limit.
+func recordRunHistory(clusterBackup *backupv1alpha1.ClusterBackup, run backupv1alpha1.BackupRunRecord) {
+	if len(run.Error) > maxHistoryErrorLength {
+		cut := maxHistoryErrorLength - len("...")
+		for cut > 0 && !utf8.RuneStart(run.Error[cut]) {
+			cut--
+		}
+		run.Error = run.Error[:cut] + "..."
+	}
+	history := append([]backupv1alpha1.BackupRunRecord{run}, clusterBackup.Status.History...)
+	clusterBackup.Status.History = history[:min(len(history), historyLimit(clusterBackup))]
+}
+
+// historyLimit returns how many runs of clusterBackup are kept in its history
+// and as BackupRuns.
+func historyLimit(clusterBackup *backupv1alpha1.ClusterBackup) int {
+	if clusterBackup.Spec.HistoryLimit != nil {
+		return int(*clusterBackup.Spec.HistoryLimit)
+	}
+	return defaultHistoryLimit
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+// storageReachableCondition reports whether the storage location of a
+// ClusterBackup passed its most recent probe.
+const storageReachableCondition = "StorageReachable"
+
+// StorageProber periodically writes and deletes a probe object in every
+// storage location referenced by a ClusterBackup. Results are surfaced as a
+// StorageReachable condition on each ClusterBackup and through Check, which
+// is meant to back the readyz endpoint.
+type StorageProber struct {
+	Client        client.Client
+	BackupManager *backup.BackupManager
+	Interval      time.Duration
+
+	mu sync.RWMutex
+	// unreachable maps storage paths to the error of their last probe.
+	unreachable map[string]error
+}
+
+// NeedLeaderElection makes only the leader probe and update status.
+func (p *StorageProber) NeedLeaderElection() bool {
+	return true
+}
+
+// Start probes all storage locations every Interval until ctx is done.
+func (p *StorageProber) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, p.probeAll, p.Interval)
+	return nil
+}
+
+// Check fails while any storage location is unreachable.
+func (p *StorageProber) Check(_ *http.Request) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if len(p.unreachable) == 0 {
+		return nil
+	}
+	paths := make([]string, 0, len(p.unreachable))
+	for storagePath, err := range p.unreachable {
+		paths = append(paths, fmt.Sprintf("%s: %v", storagePath, err))
+	}
+	sort.Strings(paths)
+	return fmt.Errorf("storage unreachable: %s", strings.Join(paths, "; "))
+}
+
+// probeAll probes each distinct storage path once and records the result on
+// every ClusterBackup using it. The usage series of reachable locations are
+// refreshed along the way and those of locations no longer in use dropped.
+func (p *StorageProber) probeAll(ctx context.Context) {
+	log := logf.FromContext(ctx).WithName("storage-probe")
+
+	var list backupv1alpha1.ClusterBackupList
+	if err := p.Client.List(ctx, &list); err != nil {
+		log.Error(err, "Failed to list ClusterBackups")
+		return
+	}
+
+	config, err := loadOperatorConfig(ctx, p.Client)
+	if err != nil {
+		log.Error(err, "Failed to load operator configuration")
+		return
+	}
+
+	results := map[string]error{}
+	unreachable := map[string]error{}
+	usage := map[string]backup.StorageUsage{}
+	for i := range list.Items {
+		for _, storagePath := range storageLocationsFor(&list.Items[i], config) {
+			if _, ok := results[storagePath]; ok {
+				continue
+			}
+			err := p.BackupManager.ProbeStorage(storagePath)
+			results[storagePath] = err
+			if err != nil {
+				log.Error(err, "Storage location unreachable", "storagePath", storagePath)
+				unreachable[storagePath] = err
+				continue
+			}
+			locationUsage, err := p.BackupManager.StorageUsage(storagePath)
+			if err != nil {
+				log.Error(err, "Failed to measure storage usage", "storagePath", storagePath)
+				continue
+			}
+			usage[storagePath] = locationUsage
+		}
+	}
+
+	storageArchives.Reset()
+	storageBytes.Reset()
+	for storagePath, locationUsage := range usage {
+		recordStorageUsage(storagePath, locationUsage)
+	}
+
+	p.mu.Lock()
+	p.unreachable = unreachable
+	p.mu.Unlock()
+
+	for i := range list.Items {
+		clusterBackup := &list.Items[i]
+		locations := storageLocationsFor(clusterBackup, config)
+		if !clusterBackup.DeletionTimestamp.IsZero() || len(locations) == 0 {
+			continue
+		}
+		var probeErrs []error
+		for _, storagePath := range locations {
+			if err := results[storagePath]; err != nil {
+				probeErrs = append(probeErrs, fmt.Errorf("%s: %w", storagePath, err))
+			}
+		}
+		if err := p.setCondition(ctx, clusterBackup, stderrors.Join(probeErrs...)); err != nil {
+			log.Error(err, "Failed to update storage condition",
+				"namespace", clusterBackup.Namespace, "name", clusterBackup.Name)
+		}
+	}
+}
+
+// setCondition patches the StorageReachable condition if it changed.
+func (p *StorageProber) setCondition(ctx context.Context, clusterBackup *backupv1alpha1.ClusterBackup, probeErr error) error {
+	condition := metav1.Condition{
+		Type:    storageReachableCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  "ProbeSucceeded",
+		Message: "Probe object written and deleted successfully",
+	}
+	if probeErr != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ProbeFailed"
+		condition.Message = probeErr.Error()
+	}
+	return patchBackupCondition(ctx, p.Client, clusterBackup, condition)
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+// TempDirCleaner removes the staging directories that backup runs of a
+// crashed operator left in the temp directory, once on startup and then
+// every Interval.
+type TempDirCleaner struct {
+	BackupManager *backup.BackupManager
+	// Interval between passes. Zero only cleans up on startup.
+	Interval time.Duration
+}
+
+// NeedLeaderElection lets every replica clean up its own temp directory.
+func (c *TempDirCleaner) NeedLeaderElection() bool {
+	return false
+}
+
+// Start cleans up until ctx is done.
+func (c *TempDirCleaner) Start(ctx context.Context) error {
+	if c.Interval <= 0 {
+		c.cleanup(ctx)
+		return nil
+	}
+	wait.UntilWithContext(ctx, c.cleanup, c.Interval)
+	return nil
+}
+
+func (c *TempDirCleaner) cleanup(ctx context.Context) {
+	log := logf.FromContext(ctx).WithName("temp-dir-cleaner")
+	removed, err := c.BackupManager.CleanupTempDirs(logf.IntoContext(ctx, log))
+	if err != nil {
+		log.Error(err, "Failed to clean up the temp directory")
+		return
+	}
+	if removed > 0 {
+		log.Info("Cleaned up the temp directory", "removed", removed)
+	}
+}
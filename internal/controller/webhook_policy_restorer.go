@@ -0,0 +1,47 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+// WebhookPolicyRestorer puts back, once on startup, the webhook failure
+// policies that restores with ignoreWebhookFailures left relaxed when the
+// operator stopped in the middle of them.
+type WebhookPolicyRestorer struct {
+	BackupManager *backup.BackupManager
+}
+
+// NeedLeaderElection makes only the leader, which runs the restores, revert
+// the policies.
+func (w *WebhookPolicyRestorer) NeedLeaderElection() bool {
+	return true
+}
+
+// Start reverts the policies and returns.
+func (w *WebhookPolicyRestorer) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx).WithName("webhook-policy-restorer")
+	if err := w.BackupManager.RestoreWebhookPolicies(ctx); err != nil {
+		log.Error(err, "Failed to restore webhook failure policies left relaxed by an interrupted restore")
+	}
+	return nil
+}
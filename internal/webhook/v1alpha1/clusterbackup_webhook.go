@@ -0,0 +1,494 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+// defaultStorageProbeTimeout bounds how long admission waits for the storage
+// location to answer.
+const defaultStorageProbeTimeout = 5 * time.Second
+
+// clusterbackuplog is for logging in this package.
+var clusterbackuplog = logf.Log.WithName("clusterbackup-resource")
+
+// SetupClusterBackupWebhookWithManager registers the webhook for ClusterBackup in the manager.
+func SetupClusterBackupWebhookWithManager(mgr ctrl.Manager, backupManager *backup.BackupManager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&backupv1alpha1.ClusterBackup{}).
+		WithValidator(&ClusterBackupCustomValidator{
+			ProbeStorage:   backupManager.ProbeStorage,
+			AuditLog:       backupManager.AppendAuditRecord,
+			Reader:         mgr.GetClient(),
+			MayImpersonate: backupManager.MayImpersonate,
+			MayCreateJobs:  backupManager.MayCreateJobs,
+		}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-backup-backup-io-v1alpha1-clusterbackup,mutating=false,failurePolicy=fail,sideEffects=NoneOnDryRun,groups=backup.backup.io,resources=clusterbackups,verbs=create;update;delete,versions=v1alpha1,name=vclusterbackup-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// ClusterBackupCustomValidator rejects ClusterBackups whose storage location
+// is malformed or cannot be written to, or that impersonate an identity
+// their author may not, when they are created or updated, and records who
+// created, changed or deleted them in the audit log.
+type ClusterBackupCustomValidator struct {
+	// ProbeStorage checks that a storage path is reachable. It is skipped for
+	// dry-run requests because it writes a probe object.
+	ProbeStorage func(storagePath string) error
+	// ProbeTimeout bounds ProbeStorage. Zero uses a five second default.
+	ProbeTimeout time.Duration
+
+	// AuditLog, when set, appends a record to the audit log of a storage
+	// location for every admitted request that is not a dry run.
+	AuditLog func(storagePath string, record backup.AuditRecord) error
+	// Reader reads the BackupOperatorConfig for the default storage path of
+	// ClusterBackups without one.
+	Reader client.Reader
+
+	// MayImpersonate, when set, checks that the requesting user may
+	// impersonate the identities a ClusterBackup runs as.
+	MayImpersonate ImpersonationReviewer
+	// MayCreateJobs, when set, checks that the requesting user may create
+	// the Jobs of the backup actions themselves.
+	MayCreateJobs JobCreationReviewer
+}
+
+var _ webhook.CustomValidator = &ClusterBackupCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type ClusterBackup.
+func (v *ClusterBackupCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	clusterbackup, ok := obj.(*backupv1alpha1.ClusterBackup)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterBackup object but got %T", obj)
+	}
+	clusterbackuplog.Info("Validation for ClusterBackup upon creation", "name", clusterbackup.GetName())
+
+	if err := v.validateClusterBackup(ctx, clusterbackup, true, true); err != nil {
+		return nil, err
+	}
+	v.audit(ctx, clusterbackup, backup.AuditOperationCreate)
+	return nil, nil
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type ClusterBackup.
+func (v *ClusterBackupCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	clusterbackup, ok := newObj.(*backupv1alpha1.ClusterBackup)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterBackup object for the newObj but got %T", newObj)
+	}
+	oldClusterbackup, ok := oldObj.(*backupv1alpha1.ClusterBackup)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterBackup object for the oldObj but got %T", oldObj)
+	}
+	clusterbackuplog.Info("Validation for ClusterBackup upon update", "name", clusterbackup.GetName())
+
+	// Only probe again when the location changed, so unrelated edits are not
+	// blocked by a storage outage
+	storageChanged := clusterbackup.Spec.StoragePath != oldClusterbackup.Spec.StoragePath ||
+		!slices.Equal(clusterbackup.Spec.ReplicaStoragePaths, oldClusterbackup.Spec.ReplicaStoragePaths)
+	// Any spec change is reviewed, since it changes what the impersonated
+	// identity does on the author's behalf. Metadata-only updates, such as
+	// the operator adding its finalizer, are neither reviewed nor audited
+	specChanged := !equality.Semantic.DeepEqual(clusterbackup.Spec, oldClusterbackup.Spec)
+	if err := v.validateClusterBackup(ctx, clusterbackup, storageChanged, specChanged); err != nil {
+		return nil, err
+	}
+	if specChanged {
+		v.audit(ctx, clusterbackup, backup.AuditOperationUpdate)
+	}
+	return nil, nil
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type ClusterBackup.
+func (v *ClusterBackupCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	clusterbackup, ok := obj.(*backupv1alpha1.ClusterBackup)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterBackup object but got %T", obj)
+	}
+	v.audit(ctx, clusterbackup, backup.AuditOperationDelete)
+	return nil, nil
+}
+
+// audit records an admitted request in the audit log of the storage
+// location of clusterbackup. Failures are logged and never reject the
+// request.
+func (v *ClusterBackupCustomValidator) audit(ctx context.Context, clusterbackup *backupv1alpha1.ClusterBackup, operation string) {
+	req, err := admission.RequestFromContext(ctx)
+	if v.AuditLog == nil || err != nil || (req.DryRun != nil && *req.DryRun) {
+		return
+	}
+	storagePath := clusterbackup.Spec.StoragePath
+	if storagePath == "" && v.Reader != nil {
+		config := &backupv1alpha1.BackupOperatorConfig{}
+		if err := v.Reader.Get(ctx, client.ObjectKey{Name: backupv1alpha1.BackupOperatorConfigName}, config); err == nil {
+			storagePath = config.Spec.DefaultStoragePath
+		}
+	}
+	storagePath, ok := v.expandStoragePath(ctx, clusterbackup, storagePath)
+	if !ok || storagePath == "" {
+		return
+	}
+	record := backup.AuditRecord{
+		Operation: operation,
+		Resource:  fmt.Sprintf("ClusterBackup/%s/%s", clusterbackup.Namespace, clusterbackup.Name),
+		User:      req.UserInfo.Username,
+		Result:    "admitted",
+	}
+	if err := v.AuditLog(storagePath, record); err != nil {
+		clusterbackuplog.Error(err, "Failed to record audit entry", "name", clusterbackup.GetName(), "operation", operation)
+	}
+}
+
+// expandStoragePath expands the template variables of storagePath for
+// clusterbackup. It reports false when they cannot be expanded yet, such as
+// before a generated name is assigned; the operator reports what is missing
+// once it runs the backup.
+func (v *ClusterBackupCustomValidator) expandStoragePath(ctx context.Context, clusterbackup *backupv1alpha1.ClusterBackup, storagePath string) (string, bool) {
+	vars := backup.StoragePathVars{
+		ClusterName: clusterbackup.Labels[backupv1alpha1.ClusterNameLabel],
+		Namespace:   clusterbackup.Namespace,
+		BackupName:  clusterbackup.Name,
+	}
+	if vars.ClusterName == "" && v.Reader != nil && strings.Contains(storagePath, ".ClusterName") {
+		config := &backupv1alpha1.BackupOperatorConfig{}
+		if err := v.Reader.Get(ctx, client.ObjectKey{Name: backupv1alpha1.BackupOperatorConfigName}, config); err == nil {
+			vars.ClusterName = config.Spec.ClusterName
+		}
+	}
+	expanded, err := backup.ExpandStoragePath(storagePath, vars)
+	return expanded, err == nil
+}
+
+func (v *ClusterBackupCustomValidator) validateClusterBackup(ctx context.Context, clusterbackup *backupv1alpha1.ClusterBackup, probe, reviewImpersonation bool) error {
+	storagePathField := field.NewPath("spec", "storagePath")
+	storagePath := clusterbackup.Spec.StoragePath
+
+	// An empty storagePath selects the BackupOperatorConfig default, which
+	// the operator validates itself
+	var allErrs field.ErrorList
+	if storagePath != "" {
+		if err := backup.ValidateStoragePath(storagePath); err != nil {
+			allErrs = append(allErrs, field.Invalid(storagePathField, storagePath, err.Error()))
+		} else if expanded, ok := v.expandStoragePath(ctx, clusterbackup, storagePath); ok && probe && !isDryRun(ctx) {
+			if err := v.probe(ctx, expanded); err != nil {
+				allErrs = append(allErrs, field.Invalid(storagePathField, storagePath,
+					fmt.Sprintf("storage location is not reachable: %v", err)))
+			}
+		}
+	}
+
+	replicasField := field.NewPath("spec", "replicaStoragePaths")
+	for i, replica := range clusterbackup.Spec.ReplicaStoragePaths {
+		if replica == storagePath {
+			allErrs = append(allErrs, field.Duplicate(replicasField.Index(i), replica))
+		} else if err := backup.ValidateStoragePath(replica); err != nil {
+			allErrs = append(allErrs, field.Invalid(replicasField.Index(i), replica, err.Error()))
+		} else if expanded, ok := v.expandStoragePath(ctx, clusterbackup, replica); ok && probe && !isDryRun(ctx) {
+			if err := v.probe(ctx, expanded); err != nil {
+				allErrs = append(allErrs, field.Invalid(replicasField.Index(i), replica,
+					fmt.Sprintf("storage location is not reachable: %v", err)))
+			}
+		}
+	}
+
+	if tiering := clusterbackup.Spec.Tiering; tiering != nil {
+		coldField := field.NewPath("spec", "tiering", "coldStoragePath")
+		if tiering.ColdStoragePath == storagePath {
+			allErrs = append(allErrs, field.Invalid(coldField, tiering.ColdStoragePath, "must differ from storagePath"))
+		} else if err := backup.ValidateStoragePath(tiering.ColdStoragePath); err != nil {
+			allErrs = append(allErrs, field.Invalid(coldField, tiering.ColdStoragePath, err.Error()))
+		}
+	}
+
+	itemsField := field.NewPath("spec", "items")
+	for i, item := range clusterbackup.Spec.Items {
+		if _, err := backup.ParseBackupItem(item); err != nil {
+			allErrs = append(allErrs, field.Invalid(itemsField.Index(i), item, err.Error()))
+		}
+	}
+
+	if application := clusterbackup.Spec.Application; application != "" {
+		applicationField := field.NewPath("spec", "application")
+		if len(clusterbackup.Spec.Items) > 0 {
+			allErrs = append(allErrs, field.Forbidden(applicationField, "cannot be combined with spec.items"))
+		} else if _, err := backup.ParseBackupItem(application); err != nil {
+			allErrs = append(allErrs, field.Invalid(applicationField, application, err.Error()))
+		}
+	}
+
+	if clusterbackup.Spec.Continuous != nil {
+		continuousField := field.NewPath("spec", "continuous")
+		switch {
+		case len(clusterbackup.Spec.Items) > 0:
+			allErrs = append(allErrs, field.Forbidden(continuousField, "cannot be combined with spec.items"))
+		case clusterbackup.Spec.Application != "":
+			allErrs = append(allErrs, field.Forbidden(continuousField, "cannot be combined with spec.application"))
+		case clusterbackup.Spec.Encryption != nil:
+			allErrs = append(allErrs, field.Forbidden(continuousField, "change logs are not encrypted, so cannot be combined with spec.encryption"))
+		case clusterbackup.Spec.SecretEncryption != nil:
+			allErrs = append(allErrs, field.Forbidden(continuousField, "change logs are not encrypted, so cannot be combined with spec.secretEncryption"))
+		}
+	}
+
+	if clusterbackup.Spec.Incremental != nil {
+		incrementalField := field.NewPath("spec", "incremental")
+		switch {
+		case clusterbackup.Spec.Encryption != nil:
+			allErrs = append(allErrs, field.Forbidden(incrementalField, "base archives cannot be read without their keys, so cannot be combined with spec.encryption"))
+		case len(clusterbackup.Spec.Items) > 0:
+			allErrs = append(allErrs, field.Forbidden(incrementalField, "cannot be combined with spec.items"))
+		case clusterbackup.Spec.Application != "":
+			allErrs = append(allErrs, field.Forbidden(incrementalField, "cannot be combined with spec.application"))
+		}
+	}
+
+	if schedule := clusterbackup.Spec.Schedule; schedule != "" {
+		if _, err := backup.ParseSchedule(schedule); err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "schedule"), schedule, err.Error()))
+		}
+	}
+
+	allErrs = append(allErrs, validateResourceTypes(clusterbackup.Spec.ResourceTypes, field.NewPath("spec", "resourceTypes"))...)
+	if len(clusterbackup.Spec.ResourceSchedules) > 0 {
+		allErrs = append(allErrs, validateResourceSchedules(clusterbackup)...)
+	}
+
+	if clusterResources := clusterbackup.Spec.ClusterResources; clusterResources != nil {
+		clusterResourcesField := field.NewPath("spec", "clusterResources")
+		allErrs = append(allErrs, validateResourceTypes(clusterResources.Include, clusterResourcesField.Child("include"))...)
+		allErrs = append(allErrs, validateResourceTypes(clusterResources.Exclude, clusterResourcesField.Child("exclude"))...)
+		if include := clusterbackup.Spec.IncludeClusterResources; include != nil && !*include {
+			allErrs = append(allErrs, field.Forbidden(clusterResourcesField, "requires spec.includeClusterResources"))
+		}
+		for i, kind := range clusterResources.Exclude {
+			if slices.ContainsFunc(clusterResources.Include, func(included string) bool { return strings.EqualFold(included, kind) }) {
+				allErrs = append(allErrs, field.Invalid(clusterResourcesField.Child("exclude").Index(i), kind, "is also listed in include"))
+			}
+		}
+	}
+
+	if maxTotalSize := clusterbackup.Spec.MaxTotalSize; maxTotalSize != nil && maxTotalSize.Sign() <= 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "maxTotalSize"), maxTotalSize.String(), "must be greater than zero"))
+	}
+
+	pinnedField := field.NewPath("spec", "pinnedArchives")
+	for i, archive := range clusterbackup.Spec.PinnedArchives {
+		if err := backup.ValidateArchiveName(archive); err != nil {
+			allErrs = append(allErrs, field.Invalid(pinnedField.Index(i), archive, err.Error()))
+		}
+	}
+
+	if restore := clusterbackup.Spec.Restore; restore != nil && restore.ArchiveURL != "" {
+		if err := backup.ValidateArchiveURL(restore.ArchiveURL); err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "restore", "archiveURL"),
+				backup.RedactArchiveURL(restore.ArchiveURL), err.Error()))
+		}
+	}
+
+	if encryption := clusterbackup.Spec.Encryption; encryption != nil {
+		recipientsField := field.NewPath("spec", "encryption", "ageRecipients")
+		for i, recipient := range encryption.AgeRecipients {
+			if _, err := backup.NewAgeKeyWrappers([]string{recipient}); err != nil {
+				allErrs = append(allErrs, field.Invalid(recipientsField.Index(i), recipient, err.Error()))
+			}
+		}
+		if encryption.ReencryptArchives && encryption.KMS == nil && len(encryption.AgeRecipients) == 0 {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "encryption", "reencryptArchives"), true,
+				"requires a KMS key or age recipients to re-encrypt archives with"))
+		}
+	}
+
+	if secretEncryption := clusterbackup.Spec.SecretEncryption; secretEncryption != nil {
+		allErrs = append(allErrs, validateSecretEncryption(secretEncryption, field.NewPath("spec", "secretEncryption"))...)
+	}
+
+	if export := clusterbackup.Spec.GitExport; export != nil && export.Path != "" {
+		if err := backup.ValidateExportPath(export.Path, export.Repository != nil); err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "gitExport", "path"), export.Path, err.Error()))
+		}
+	}
+
+	if reviewImpersonation {
+		if err := validateImpersonation(ctx, v.MayImpersonate, clusterbackup.Namespace, clusterbackup.Spec.Impersonate,
+			field.NewPath("spec", "impersonate")); err != nil {
+			allErrs = append(allErrs, err)
+		}
+		if restore := clusterbackup.Spec.Restore; restore != nil {
+			if err := validateImpersonation(ctx, v.MayImpersonate, clusterbackup.Namespace, restore.Impersonate,
+				field.NewPath("spec", "restore", "impersonate")); err != nil {
+				allErrs = append(allErrs, err)
+			}
+		}
+		if err := v.validateActionJobs(ctx, clusterbackup); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(
+		schema.GroupKind{Group: backupv1alpha1.GroupVersion.Group, Kind: "ClusterBackup"},
+		clusterbackup.Name, allErrs)
+}
+
+// probe runs ProbeStorage, giving up after ProbeTimeout.
+func (v *ClusterBackupCustomValidator) probe(ctx context.Context, storagePath string) error {
+	if v.ProbeStorage == nil {
+		return nil
+	}
+	timeout := v.ProbeTimeout
+	if timeout <= 0 {
+		timeout = defaultStorageProbeTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result := make(chan error, 1)
+	go func() {
+		result <- v.ProbeStorage(storagePath)
+	}()
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("probe timed out after %s", timeout)
+	}
+}
+
+func isDryRun(ctx context.Context) bool {
+	req, err := admission.RequestFromContext(ctx)
+	return err == nil && req.DryRun != nil && *req.DryRun
+}
+
+// validateResourceSchedules checks that every resource type has at most one
+// cadence of its own and that spec.schedule is left with something to run.
+func validateResourceSchedules(clusterbackup *backupv1alpha1.ClusterBackup) field.ErrorList {
+	var allErrs field.ErrorList
+	schedulesField := field.NewPath("spec", "resourceSchedules")
+	switch {
+	case clusterbackup.Spec.Schedule == "":
+		allErrs = append(allErrs, field.Required(field.NewPath("spec", "schedule"), "required by spec.resourceSchedules"))
+	case len(clusterbackup.Spec.Items) > 0:
+		allErrs = append(allErrs, field.Forbidden(schedulesField, "cannot be combined with spec.items"))
+	case clusterbackup.Spec.Application != "":
+		allErrs = append(allErrs, field.Forbidden(schedulesField, "cannot be combined with spec.application"))
+	}
+
+	scheduled := map[string]struct{}{}
+	for i, schedule := range clusterbackup.Spec.ResourceSchedules {
+		if schedule.Interval.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(schedulesField.Index(i).Child("interval"), schedule.Interval.Duration.String(), "must be positive"))
+		}
+		allErrs = append(allErrs, validateResourceTypes(schedule.ResourceTypes, schedulesField.Index(i).Child("resourceTypes"))...)
+		for j, resourceType := range schedule.ResourceTypes {
+			key := strings.ToLower(resourceType)
+			if _, ok := scheduled[key]; ok {
+				allErrs = append(allErrs, field.Duplicate(schedulesField.Index(i).Child("resourceTypes").Index(j), resourceType))
+			}
+			scheduled[key] = struct{}{}
+		}
+	}
+
+	if len(clusterbackup.Spec.ResourceTypes) > 0 && !slices.ContainsFunc(clusterbackup.Spec.ResourceTypes, func(resourceType string) bool {
+		_, ok := scheduled[strings.ToLower(resourceType)]
+		return !ok
+	}) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "resourceTypes"), clusterbackup.Spec.ResourceTypes,
+			"every resource type has its own cadence, so none is left for spec.schedule"))
+	}
+	return allErrs
+}
+
+// validateResourceTypes checks that every entry is a kind or a group
+// wildcard.
+func validateResourceTypes(resourceTypes []string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, resourceType := range resourceTypes {
+		if err := backup.ValidateResourceType(resourceType); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), resourceType, err.Error()))
+		}
+	}
+	return allErrs
+}
+
+// validateSecretEncryption checks that the keys of the selected format are
+// configured and parse.
+func validateSecretEncryption(secretEncryption *backupv1alpha1.SecretEncryption, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	switch backup.SecretFormat(secretEncryption.Format) {
+	case backup.SecretFormatSealedSecrets:
+		if sealed := secretEncryption.SealedSecrets; sealed != nil && sealed.Certificate != "" {
+			if _, err := backup.ParseSealingCertificate(sealed.Certificate); err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("sealedSecrets", "certificate"), "", err.Error()))
+			}
+		}
+	case backup.SecretFormatSOPS:
+		if secretEncryption.SOPS == nil || len(secretEncryption.SOPS.AgeRecipients) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("sops", "ageRecipients"), "required for the SOPS format"))
+			break
+		}
+		recipientsField := fldPath.Child("sops", "ageRecipients")
+		for i, recipient := range secretEncryption.SOPS.AgeRecipients {
+			if _, err := backup.NewAgeKeyWrappers([]string{recipient}); err != nil {
+				allErrs = append(allErrs, field.Invalid(recipientsField.Index(i), recipient, err.Error()))
+			}
+		}
+	}
+	return allErrs
+}
+
+// validateActionJobs rejects backup actions whose Jobs the requesting user
+// could not create themselves.
+func (v *ClusterBackupCustomValidator) validateActionJobs(ctx context.Context, clusterbackup *backupv1alpha1.ClusterBackup) *field.Error {
+	actions := clusterbackup.Spec.Actions
+	if v.MayCreateJobs == nil || actions == nil || len(actions.Pre)+len(actions.Post) == 0 {
+		return nil
+	}
+	fldPath := field.NewPath("spec", "actions")
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return field.InternalError(fldPath, err)
+	}
+	allowed, reason, err := v.MayCreateJobs(ctx, req.UserInfo, clusterbackup.Namespace)
+	if err != nil {
+		return field.InternalError(fldPath, err)
+	}
+	if !allowed {
+		return field.Forbidden(fldPath, reason)
+	}
+	return nil
+}
@@ -0,0 +1,429 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+var _ = Describe("ClusterBackup Webhook", func() {
+	var (
+		ctx       context.Context
+		obj       *backupv1alpha1.ClusterBackup
+		oldObj    *backupv1alpha1.ClusterBackup
+		validator ClusterBackupCustomValidator
+		probed    []string
+		probeErr  error
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		obj = &backupv1alpha1.ClusterBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "default"},
+			Spec:       backupv1alpha1.ClusterBackupSpec{StoragePath: "host:///tmp/backups"},
+		}
+		oldObj = obj.DeepCopy()
+		probed = nil
+		probeErr = nil
+		validator = ClusterBackupCustomValidator{
+			ProbeStorage: func(storagePath string) error {
+				probed = append(probed, storagePath)
+				return probeErr
+			},
+		}
+	})
+
+	Context("When creating ClusterBackup under Validating Webhook", func() {
+		It("Should admit a reachable storage location", func() {
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+			Expect(probed).To(ConsistOf("host:///tmp/backups"))
+		})
+
+		It("Should deny an unsupported storage scheme without probing", func() {
+			obj.Spec.StoragePath = "s3://bucket/path"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("unsupported storage scheme")))
+			Expect(probed).To(BeEmpty())
+		})
+
+		It("Should admit an empty storage location without probing", func() {
+			obj.Spec.StoragePath = ""
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+			Expect(probed).To(BeEmpty())
+		})
+
+		It("Should deny an unreachable storage location", func() {
+			probeErr = errors.New("permission denied")
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("storage location is not reachable: permission denied")))
+		})
+
+		It("Should deny a storage location that does not answer in time", func() {
+			validator.ProbeTimeout = 10 * time.Millisecond
+			validator.ProbeStorage = func(string) error {
+				time.Sleep(time.Second)
+				return nil
+			}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(MatchError(ContainSubstring("timed out")))
+		})
+
+		It("Should not probe on dry-run requests", func() {
+			ctx = admission.NewContextWithRequest(ctx, admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{DryRun: ptr.To(true)},
+			})
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+			Expect(probed).To(BeEmpty())
+		})
+
+		It("Should probe the expanded storage location of a template", func() {
+			obj.Labels = map[string]string{backupv1alpha1.ClusterNameLabel: "edge-1"}
+			obj.Spec.StoragePath = "host:///tmp/{{.ClusterName}}/{{.Namespace}}/{{.BackupName}}"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+			Expect(probed).To(ConsistOf("host:///tmp/edge-1/default/sample"))
+		})
+
+		It("Should validate and probe replica storage locations", func() {
+			obj.Spec.ReplicaStoragePaths = []string{"host:///tmp/replica", "s3://bucket/path", obj.Spec.StoragePath}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(SatisfyAll(
+				MatchError(ContainSubstring("spec.replicaStoragePaths[1]")),
+				MatchError(ContainSubstring("spec.replicaStoragePaths[2]")),
+			))
+			Expect(probed).To(ConsistOf("host:///tmp/backups", "host:///tmp/replica"))
+		})
+
+		It("Should deny a cold storage location equal to the storage location", func() {
+			obj.Spec.Tiering = &backupv1alpha1.ArchiveTiering{ColdStoragePath: obj.Spec.StoragePath, TransitionAfterDays: 30}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.tiering.coldStoragePath")))
+
+			obj.Spec.Tiering.ColdStoragePath = "/mnt/cold"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny malformed items", func() {
+			obj.Spec.Items = []string{"deployment.apps/prod/api", "configmap/"}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.items[1]")))
+
+			obj.Spec.Items = []string{"deployment.apps/prod/api", "clusterrole/admin"}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should validate the application root", func() {
+			obj.Spec.Application = "deployment.apps"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.application")))
+
+			obj.Spec.Application = "deployment.apps/payments/api"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+
+			obj.Spec.Items = []string{"configmap/payments/api-settings"}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("cannot be combined with spec.items")))
+		})
+
+		It("Should deny continuous backups of items or encrypted archives", func() {
+			obj.Spec.Continuous = &backupv1alpha1.ContinuousBackup{}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+
+			obj.Spec.Encryption = &backupv1alpha1.BackupEncryption{KMS: &backupv1alpha1.KMSKey{Provider: "aws-kms", KeyID: "alias/backups"}}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("change logs are not encrypted")))
+
+			obj.Spec.Encryption = nil
+			obj.Spec.SecretEncryption = &backupv1alpha1.SecretEncryption{Format: "SealedSecrets"}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.secretEncryption")))
+
+			obj.Spec.SecretEncryption = nil
+			obj.Spec.Items = []string{"configmap/payments/api-settings"}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.continuous")))
+		})
+
+		It("Should deny incremental backups of encrypted archives", func() {
+			obj.Spec.Incremental = &backupv1alpha1.IncrementalBackup{}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+
+			obj.Spec.Encryption = &backupv1alpha1.BackupEncryption{KMS: &backupv1alpha1.KMSKey{Provider: "aws-kms", KeyID: "alias/backups"}}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.incremental")))
+		})
+
+		It("Should deny schedules the operator cannot run", func() {
+			for _, schedule := range []string{"0 2 * * *", "*/15 0-6 * * MON-FRI", "@weekly", "90m"} {
+				obj.Spec.Schedule = schedule
+				Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred(), schedule)
+			}
+			for _, schedule := range []string{"61 * * * *", "0 2 * * 8", "0 5-1 * * *", "@every 1h"} {
+				obj.Spec.Schedule = schedule
+				Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+					MatchError(ContainSubstring("spec.schedule")), schedule)
+			}
+		})
+
+		It("Should deny resource schedules without a schedule or listing a type twice", func() {
+			hourly := metav1.Duration{Duration: time.Hour}
+			obj.Spec.ResourceSchedules = []backupv1alpha1.ResourceSchedule{
+				{ResourceTypes: []string{"Secret", "ConfigMap"}, Interval: hourly},
+			}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.schedule")))
+
+			obj.Spec.Schedule = "@daily"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+
+			obj.Spec.ResourceSchedules = append(obj.Spec.ResourceSchedules, backupv1alpha1.ResourceSchedule{
+				ResourceTypes: []string{"secret"}, Interval: hourly,
+			})
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.resourceSchedules[1].resourceTypes[0]")))
+
+			obj.Spec.ResourceSchedules = obj.Spec.ResourceSchedules[:1]
+			obj.Spec.ResourceTypes = []string{"Secret"}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("none is left for spec.schedule")))
+		})
+
+		It("Should deny wildcards other than group wildcards", func() {
+			obj.Spec.ResourceTypes = []string{"Deployment", "*.istio.io", "*"}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.resourceTypes[2]")))
+
+			obj.Spec.ResourceTypes = obj.Spec.ResourceTypes[:2]
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny cluster resource filters that conflict", func() {
+			obj.Spec.ClusterResources = &backupv1alpha1.ClusterResourceFilter{
+				Include: []string{"StorageClass", "ClusterRole"},
+				Exclude: []string{"Node", "clusterrole"},
+			}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.clusterResources.exclude[1]")))
+
+			obj.Spec.ClusterResources.Exclude = []string{"Node"}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+
+			obj.Spec.IncludeClusterResources = ptr.To(false)
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("requires spec.includeClusterResources")))
+		})
+
+		It("Should deny pinned archives that are not archive names", func() {
+			obj.Spec.PinnedArchives = []string{"cluster-backup-20250101-000000.tar.gz", "../etc/passwd"}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.pinnedArchives[1]")))
+
+			obj.Spec.PinnedArchives = obj.Spec.PinnedArchives[:1]
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny a size limit that is not positive", func() {
+			obj.Spec.MaxTotalSize = ptr.To(resource.MustParse("0"))
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.maxTotalSize")))
+
+			obj.Spec.MaxTotalSize = ptr.To(resource.MustParse("50Gi"))
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny restore URLs that are not https", func() {
+			obj.Spec.Restore = &backupv1alpha1.RestoreOptions{ArchiveURL: "https://"}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.restore.archiveURL")))
+
+			obj.Spec.Restore.ArchiveURL = "https://bucket.example.com/cluster-backup-20250101-000000.tar.gz?sig=abc"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny malformed age recipients", func() {
+			obj.Spec.Encryption = &backupv1alpha1.BackupEncryption{AgeRecipients: []string{"age1notakey"}}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.encryption.ageRecipients[0]")))
+		})
+
+		It("Should deny re-encrypting archives without keys", func() {
+			obj.Spec.Encryption = &backupv1alpha1.BackupEncryption{ReencryptArchives: true}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.encryption.reencryptArchives")))
+		})
+
+		It("Should deny SOPS secret encryption without valid age recipients", func() {
+			obj.Spec.SecretEncryption = &backupv1alpha1.SecretEncryption{Format: "SOPS"}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.secretEncryption.sops.ageRecipients")))
+
+			obj.Spec.SecretEncryption.SOPS = &backupv1alpha1.SOPSEncryption{AgeRecipients: []string{"not-a-key"}}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.secretEncryption.sops.ageRecipients[0]")))
+
+			obj.Spec.SecretEncryption = &backupv1alpha1.SecretEncryption{
+				Format:        "SealedSecrets",
+				SealedSecrets: &backupv1alpha1.SealedSecretsEncryption{Certificate: "not a certificate"},
+			}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.secretEncryption.sealedSecrets.certificate")))
+		})
+
+		It("Should deny export paths that leave the storage location", func() {
+			obj.Spec.GitExport = &backupv1alpha1.GitExport{Path: "../elsewhere"}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.gitExport.path")))
+
+			obj.Spec.GitExport.Path = "."
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.gitExport.path")))
+
+			obj.Spec.GitExport.Repository = &backupv1alpha1.GitRepository{URL: "https://git.example.com/cluster.git"}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+	})
+
+	Context("When updating ClusterBackup under Validating Webhook", func() {
+		It("Should only probe when the storage location changes", func() {
+			probeErr = errors.New("unreachable")
+			obj.Spec.RetentionDays = ptr.To(7)
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().NotTo(HaveOccurred())
+			Expect(probed).To(BeEmpty())
+
+			obj.Spec.StoragePath = "/var/lib/backups"
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().To(HaveOccurred())
+			Expect(probed).To(ConsistOf("/var/lib/backups"))
+		})
+	})
+
+	Context("When auditing ClusterBackup changes", func() {
+		var audited []backup.AuditRecord
+
+		BeforeEach(func() {
+			audited = nil
+			validator.AuditLog = func(storagePath string, record backup.AuditRecord) error {
+				Expect(storagePath).To(Equal("host:///tmp/backups"))
+				audited = append(audited, record)
+				return nil
+			}
+			ctx = admission.NewContextWithRequest(ctx, admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{UserInfo: authenticationv1.UserInfo{Username: "alice"}},
+			})
+		})
+
+		It("Should record who created, changed and deleted it", func() {
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+			obj.Spec.RetentionDays = ptr.To(7)
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().NotTo(HaveOccurred())
+			Expect(validator.ValidateDelete(ctx, obj)).Error().NotTo(HaveOccurred())
+
+			Expect(audited).To(HaveLen(3))
+			for i, operation := range []string{backup.AuditOperationCreate, backup.AuditOperationUpdate, backup.AuditOperationDelete} {
+				Expect(audited[i].Operation).To(Equal(operation))
+				Expect(audited[i].User).To(Equal("alice"))
+				Expect(audited[i].Resource).To(Equal("ClusterBackup/default/sample"))
+			}
+		})
+
+		It("Should not record metadata-only updates, denied or dry-run requests", func() {
+			obj.Finalizers = []string{"backup.backup.io/finalizer"}
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().NotTo(HaveOccurred())
+
+			obj.Spec.ReplicaStoragePaths = []string{"s3://bucket/path"}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(HaveOccurred())
+
+			ctx = admission.NewContextWithRequest(ctx, admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{DryRun: ptr.To(true)},
+			})
+			Expect(validator.ValidateDelete(ctx, obj)).Error().NotTo(HaveOccurred())
+			Expect(audited).To(BeEmpty())
+		})
+	})
+
+	Context("When a ClusterBackup impersonates another identity", func() {
+		var reviewed []backup.Identity
+
+		BeforeEach(func() {
+			reviewed = nil
+			// alice may impersonate the backup ServiceAccount only
+			validator.MayImpersonate = func(_ context.Context, requester authenticationv1.UserInfo, identity backup.Identity) (bool, string, error) {
+				reviewed = append(reviewed, identity)
+				if requester.Username == "alice" && identity.User == "system:serviceaccount:default:backup" {
+					return true, "", nil
+				}
+				return false, requester.Username + " may not impersonate " + identity.User, nil
+			}
+			ctx = admission.NewContextWithRequest(ctx, admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{UserInfo: authenticationv1.UserInfo{Username: "alice"}},
+			})
+		})
+
+		It("Should admit identities the requesting user may impersonate", func() {
+			obj.Spec.Impersonate = &backupv1alpha1.Impersonation{ServiceAccountName: "backup"}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+			Expect(reviewed).To(ConsistOf(backup.Identity{User: "system:serviceaccount:default:backup"}))
+		})
+
+		It("Should deny identities the requesting user may not impersonate", func() {
+			obj.Spec.Restore = &backupv1alpha1.RestoreOptions{
+				ArchiveName: "backup.tar.gz",
+				Impersonate: &backupv1alpha1.Impersonation{User: "breakglass", Groups: []string{"system:masters"}},
+			}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.restore.impersonate: Forbidden: alice may not impersonate breakglass")))
+			Expect(reviewed).To(ConsistOf(backup.Identity{User: "breakglass", Groups: []string{"system:masters"}}))
+		})
+
+		It("Should review spec changes but not metadata-only updates", func() {
+			oldObj.Spec.Impersonate = &backupv1alpha1.Impersonation{User: "breakglass"}
+			obj.Spec.Impersonate = &backupv1alpha1.Impersonation{User: "breakglass"}
+			obj.Finalizers = []string{"backup.backup.io/finalizer"}
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().NotTo(HaveOccurred())
+			Expect(reviewed).To(BeEmpty())
+
+			obj.Spec.IncludeNamespaces = []string{"payments"}
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().To(HaveOccurred())
+			Expect(reviewed).To(HaveLen(1))
+		})
+
+		It("Should deny backup actions when the requesting user may not create Jobs", func() {
+			var namespaces []string
+			validator.MayCreateJobs = func(_ context.Context, requester authenticationv1.UserInfo, namespace string) (bool, string, error) {
+				namespaces = append(namespaces, namespace)
+				return false, requester.Username + " may not create Jobs", nil
+			}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+			Expect(namespaces).To(BeEmpty())
+
+			obj.Spec.Actions = &backupv1alpha1.BackupActions{Pre: []backupv1alpha1.BackupJobAction{{Name: "dump-db"}}}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.actions: Forbidden: alice may not create Jobs")))
+			Expect(namespaces).To(ConsistOf(obj.Namespace))
+		})
+	})
+})
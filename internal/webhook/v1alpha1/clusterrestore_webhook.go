@@ -0,0 +1,109 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+// clusterrestorelog is for logging in this package.
+var clusterrestorelog = logf.Log.WithName("clusterrestore-resource")
+
+// SetupClusterRestoreWebhookWithManager registers the webhook for ClusterRestore in the manager.
+func SetupClusterRestoreWebhookWithManager(mgr ctrl.Manager, backupManager *backup.BackupManager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&backupv1alpha1.ClusterRestore{}).
+		WithValidator(&ClusterRestoreCustomValidator{
+			MayImpersonate: backupManager.MayImpersonate,
+		}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-backup-backup-io-v1alpha1-clusterrestore,mutating=false,failurePolicy=fail,sideEffects=None,groups=backup.backup.io,resources=clusterrestores,verbs=create;update,versions=v1alpha1,name=vclusterrestore-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// ClusterRestoreCustomValidator rejects ClusterRestores that impersonate an
+// identity their author may not.
+type ClusterRestoreCustomValidator struct {
+	// MayImpersonate, when set, checks that the requesting user may
+	// impersonate the identity a ClusterRestore runs as.
+	MayImpersonate ImpersonationReviewer
+}
+
+var _ webhook.CustomValidator = &ClusterRestoreCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type ClusterRestore.
+func (v *ClusterRestoreCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	clusterrestore, ok := obj.(*backupv1alpha1.ClusterRestore)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterRestore object but got %T", obj)
+	}
+	clusterrestorelog.Info("Validation for ClusterRestore upon creation", "name", clusterrestore.GetName())
+
+	return nil, v.validateClusterRestore(ctx, clusterrestore)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type ClusterRestore.
+func (v *ClusterRestoreCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	clusterrestore, ok := newObj.(*backupv1alpha1.ClusterRestore)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterRestore object for the newObj but got %T", newObj)
+	}
+	oldClusterrestore, ok := oldObj.(*backupv1alpha1.ClusterRestore)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterRestore object for the oldObj but got %T", oldObj)
+	}
+	clusterrestorelog.Info("Validation for ClusterRestore upon update", "name", clusterrestore.GetName())
+
+	// A changed spec runs the restore again as the impersonated identity
+	if equality.Semantic.DeepEqual(clusterrestore.Spec, oldClusterrestore.Spec) {
+		return nil, nil
+	}
+	return nil, v.validateClusterRestore(ctx, clusterrestore)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type ClusterRestore.
+func (v *ClusterRestoreCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *ClusterRestoreCustomValidator) validateClusterRestore(ctx context.Context, clusterrestore *backupv1alpha1.ClusterRestore) error {
+	var allErrs field.ErrorList
+	if err := validateImpersonation(ctx, v.MayImpersonate, clusterrestore.Namespace, clusterrestore.Spec.Impersonate,
+		field.NewPath("spec", "impersonate")); err != nil {
+		allErrs = append(allErrs, err)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(
+		schema.GroupKind{Group: backupv1alpha1.GroupVersion.Group, Kind: "ClusterRestore"},
+		clusterrestore.Name, allErrs)
+}
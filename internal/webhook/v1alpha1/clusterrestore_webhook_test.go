@@ -0,0 +1,105 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+var _ = Describe("ClusterRestore Webhook", func() {
+	var (
+		ctx       context.Context
+		obj       *backupv1alpha1.ClusterRestore
+		oldObj    *backupv1alpha1.ClusterRestore
+		validator ClusterRestoreCustomValidator
+		reviewErr error
+	)
+
+	BeforeEach(func() {
+		ctx = admission.NewContextWithRequest(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{UserInfo: authenticationv1.UserInfo{Username: "alice"}},
+		})
+		obj = &backupv1alpha1.ClusterRestore{
+			ObjectMeta: metav1.ObjectMeta{Name: "sample", Namespace: "team-a"},
+			Spec: backupv1alpha1.ClusterRestoreSpec{
+				BackupName:     "nightly",
+				RestoreOptions: backupv1alpha1.RestoreOptions{ArchiveName: "backup.tar.gz"},
+			},
+		}
+		oldObj = obj.DeepCopy()
+		reviewErr = nil
+		// alice may impersonate the ServiceAccounts of team-a only
+		validator = ClusterRestoreCustomValidator{
+			MayImpersonate: func(_ context.Context, requester authenticationv1.UserInfo, identity backup.Identity) (bool, string, error) {
+				if reviewErr != nil {
+					return false, "", reviewErr
+				}
+				if requester.Username == "alice" && identity.User == "system:serviceaccount:team-a:restore" {
+					return true, "", nil
+				}
+				return false, requester.Username + " may not impersonate " + identity.User, nil
+			},
+		}
+	})
+
+	Context("When creating ClusterRestore under Validating Webhook", func() {
+		It("Should admit restores that do not impersonate", func() {
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should admit a ServiceAccount of its namespace the user may impersonate", func() {
+			obj.Spec.Impersonate = &backupv1alpha1.Impersonation{ServiceAccountName: "restore"}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny identities the user may not impersonate", func() {
+			obj.Spec.Impersonate = &backupv1alpha1.Impersonation{User: "breakglass"}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("spec.impersonate: Forbidden: alice may not impersonate breakglass")))
+		})
+
+		It("Should deny the restore when the review fails", func() {
+			obj.Spec.Impersonate = &backupv1alpha1.Impersonation{ServiceAccountName: "restore"}
+			reviewErr = errors.New("apiserver unavailable")
+			Expect(validator.ValidateCreate(ctx, obj)).Error().To(
+				MatchError(ContainSubstring("apiserver unavailable")))
+		})
+	})
+
+	Context("When updating ClusterRestore under Validating Webhook", func() {
+		It("Should only review spec changes", func() {
+			oldObj.Spec.Impersonate = &backupv1alpha1.Impersonation{User: "breakglass"}
+			obj.Spec.Impersonate = &backupv1alpha1.Impersonation{User: "breakglass"}
+			obj.Labels = map[string]string{"team": "a"}
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().NotTo(HaveOccurred())
+
+			obj.Spec.ArchiveName = "older.tar.gz"
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,65 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	backupv1alpha1 "github.com/zachperkins/backup-operator/api/v1alpha1"
+	"github.com/zachperkins/backup-operator/pkg/backup"
+)
+
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// ImpersonationReviewer reports whether requester may impersonate identity,
+// and if not, why.
+type ImpersonationReviewer func(ctx context.Context, requester authenticationv1.UserInfo, identity backup.Identity) (bool, string, error)
+
+// JobCreationReviewer reports whether requester may create Jobs in
+// namespace, and if not, why.
+type JobCreationReviewer func(ctx context.Context, requester authenticationv1.UserInfo, namespace string) (bool, string, error)
+
+// validateImpersonation rejects an impersonation the requesting user could
+// not perform themselves, so nobody gains the rights of another identity
+// through the operator.
+func validateImpersonation(ctx context.Context, review ImpersonationReviewer, namespace string,
+	impersonate *backupv1alpha1.Impersonation, fldPath *field.Path) *field.Error {
+	if impersonate == nil || review == nil {
+		return nil
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return field.InternalError(fldPath, err)
+	}
+
+	identity := backup.Identity{User: impersonate.User, Groups: impersonate.Groups}
+	if impersonate.ServiceAccountName != "" {
+		identity = backup.ServiceAccountIdentity(namespace, impersonate.ServiceAccountName)
+	}
+	allowed, reason, err := review(ctx, req.UserInfo, identity)
+	if err != nil {
+		return field.InternalError(fldPath, err)
+	}
+	if !allowed {
+		return field.Forbidden(fldPath, reason)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+)
+
+const (
+	// AgeProvider names data keys wrapped for an age recipient.
+	AgeProvider = "age"
+
+	// ageMagic starts every binary age file.
+	ageMagic = "age-encryption.org/v1\n"
+)
+
+// ageRecipientWrapper wraps data keys for a single age public key.
+type ageRecipientWrapper struct {
+	recipient *age.X25519Recipient
+}
+
+// NewAgeKeyWrappers returns one KeyWrapper per age public key ("age1...").
+// Archives encrypted only for age recipients are written as standard age
+// files, so they can be decrypted with the age CLI as well as by the operator.
+func NewAgeKeyWrappers(recipients []string) ([]KeyWrapper, error) {
+	wrappers := make([]KeyWrapper, 0, len(recipients))
+	for _, value := range recipients {
+		recipient, err := age.ParseX25519Recipient(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid age recipient %q: %w", value, err)
+		}
+		wrappers = append(wrappers, &ageRecipientWrapper{recipient: recipient})
+	}
+	return wrappers, nil
+}
+
+func (w *ageRecipientWrapper) WrapKey(_ context.Context, dataKey []byte) (WrappedKey, error) {
+	var wrapped bytes.Buffer
+	enc, err := age.Encrypt(&wrapped, w.recipient)
+	if err != nil {
+		return WrappedKey{}, fmt.Errorf("failed to wrap data key for age recipient: %w", err)
+	}
+	if _, err := enc.Write(dataKey); err != nil {
+		return WrappedKey{}, fmt.Errorf("failed to wrap data key for age recipient: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return WrappedKey{}, fmt.Errorf("failed to wrap data key for age recipient: %w", err)
+	}
+	return WrappedKey{Provider: AgeProvider, KeyID: w.recipient.String(), Key: wrapped.Bytes()}, nil
+}
+
+func (w *ageRecipientWrapper) UnwrapKey(context.Context, WrappedKey) ([]byte, error) {
+	return nil, errors.New("age recipients cannot unwrap keys, an identity is required")
+}
+
+// AgeIdentityKeyWrapper unwraps data keys, and decrypts native age archives,
+// with age identities ("AGE-SECRET-KEY-1...").
+type AgeIdentityKeyWrapper struct {
+	identities []age.Identity
+}
+
+// NewAgeIdentityKeyWrapper parses one or more age identities in the format of
+// an age key file.
+func NewAgeIdentityKeyWrapper(identities string) (*AgeIdentityKeyWrapper, error) {
+	parsed, err := age.ParseIdentities(strings.NewReader(identities))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse age identities: %w", err)
+	}
+	return &AgeIdentityKeyWrapper{identities: parsed}, nil
+}
+
+func (w *AgeIdentityKeyWrapper) WrapKey(context.Context, []byte) (WrappedKey, error) {
+	return WrappedKey{}, errors.New("age identities cannot wrap keys, use a recipient")
+}
+
+func (w *AgeIdentityKeyWrapper) UnwrapKey(_ context.Context, wrapped WrappedKey) ([]byte, error) {
+	if wrapped.Provider != AgeProvider {
+		return nil, fmt.Errorf("not an age recipient")
+	}
+	r, err := age.Decrypt(bytes.NewReader(wrapped.Key), w.identities...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key with age identity: %w", err)
+	}
+	return io.ReadAll(r)
+}
+
+// ageRecipients returns the recipients of wrappers when every one of them is
+// an age recipient, in which case the archive is written as a native age file.
+func ageRecipients(wrappers []KeyWrapper) ([]age.Recipient, bool) {
+	recipients := make([]age.Recipient, 0, len(wrappers))
+	for _, wrapper := range wrappers {
+		ageWrapper, ok := wrapper.(*ageRecipientWrapper)
+		if !ok {
+			return nil, false
+		}
+		recipients = append(recipients, ageWrapper.recipient)
+	}
+	return recipients, len(recipients) > 0
+}
+
+// isAgeEncrypted reports whether r starts with a binary age header.
+func isAgeEncrypted(r *bufio.Reader) bool {
+	magic, err := r.Peek(len(ageMagic))
+	return err == nil && string(magic) == ageMagic
+}
+
+// newAgeDecryptionReader decrypts a native age archive with the identities of
+// any AgeIdentityKeyWrapper among wrappers.
+func newAgeDecryptionReader(r io.Reader, wrappers []KeyWrapper) (io.Reader, error) {
+	identities := ageIdentities(wrappers)
+	if len(identities) == 0 {
+		return nil, fmt.Errorf("%w: the archive is age encrypted and no age identity is configured", ErrNoKeyWrapper)
+	}
+	dec, err := age.Decrypt(r, identities...)
+	var noMatch *age.NoIdentityMatchError
+	if errors.As(err, &noMatch) {
+		return nil, fmt.Errorf("%w: %v", ErrNoKeyWrapper, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open age archive: %w", err)
+	}
+	return dec, nil
+}
+
+// ageIdentities returns the identities of every AgeIdentityKeyWrapper among
+// wrappers.
+func ageIdentities(wrappers []KeyWrapper) []age.Identity {
+	var identities []age.Identity
+	for _, wrapper := range wrappers {
+		if identityWrapper, ok := wrapper.(*AgeIdentityKeyWrapper); ok {
+			identities = append(identities, identityWrapper.identities...)
+		}
+	}
+	return identities
+}
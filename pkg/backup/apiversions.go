@@ -0,0 +1,253 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// RemovedAPIPolicy decides how a restore treats archived resources whose API
+// version the target cluster no longer serves.
+type RemovedAPIPolicy string
+
+const (
+	// RemovedAPIPolicyConvert moves resources to a served version of their
+	// API, and fails the items that have none.
+	RemovedAPIPolicyConvert RemovedAPIPolicy = "Convert"
+	// RemovedAPIPolicySkip leaves the resources out of the restore.
+	RemovedAPIPolicySkip RemovedAPIPolicy = "Skip"
+	// RemovedAPIPolicyFail aborts the restore before anything is applied.
+	RemovedAPIPolicyFail RemovedAPIPolicy = "Fail"
+)
+
+// APIDecision is what a restore does with a resource archived in a removed
+// or deprecated API version.
+type APIDecision string
+
+const (
+	// APIDecisionConvert applies the resource in its replacement version.
+	APIDecisionConvert APIDecision = "Convert"
+	// APIDecisionSkip leaves the resource out.
+	APIDecisionSkip APIDecision = "Skip"
+	// APIDecisionFail reports the resource as failed.
+	APIDecisionFail APIDecision = "Fail"
+	// APIDecisionKeep applies the resource as archived; its version is
+	// deprecated but still served.
+	APIDecisionKeep APIDecision = "Keep"
+)
+
+// ErrAPIRemoved is reported for archived resources whose API version the
+// target cluster does not serve.
+var ErrAPIRemoved = errors.New("API version is not served by the target cluster")
+
+// maxListedRemovedAPIs caps how many resources the error of a failed check
+// names.
+const maxListedRemovedAPIs = 5
+
+// MaxReportedAPIFindings caps how many resources a RestoreResult lists in
+// its API version report.
+const MaxReportedAPIFindings = 50
+
+// APIFinding reports an archived resource stored in an API version that the
+// target cluster has removed or that is deprecated.
+type APIFinding struct {
+	GVR       schema.GroupVersionResource
+	Kind      string
+	Namespace string
+	Name      string
+	// Removed is false for deprecated versions the target still serves.
+	Removed bool
+	// Target is the version the resource is converted to, if any.
+	Target   schema.GroupVersion
+	Decision APIDecision
+}
+
+func (f APIFinding) String() string {
+	item := f.Name
+	if f.Namespace != "" {
+		item = f.Namespace + "/" + f.Name
+	}
+	state := "deprecated"
+	if f.Removed {
+		state = "not served"
+	}
+	s := fmt.Sprintf("%s %s %s: %s is %s", f.Decision, f.Kind, item, f.GVR.GroupVersion(), state)
+	if !f.Target.Empty() {
+		s += ", replaced by " + f.Target.String()
+	}
+	return s
+}
+
+// servedAPIs maps the resources served by a cluster, per version, to their
+// kind.
+type servedAPIs map[schema.GroupVersionResource]string
+
+// servedResources lists the resources the manager's cluster serves in every
+// version. Groups that fail discovery are left out.
+func (bm *BackupManager) servedResources(ctx context.Context) (servedAPIs, error) {
+	_, lists, err := bm.DiscoveryClient.ServerGroupsAndResources()
+	if err != nil {
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return nil, fmt.Errorf("failed to discover served APIs: %w", err)
+		}
+		ctrl.LoggerFrom(ctx).Error(err, "Some API groups failed discovery, their resources are checked as not served")
+	}
+
+	served := servedAPIs{}
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, resource := range list.APIResources {
+			// Subresources such as deployments/scale are not archived
+			if strings.Contains(resource.Name, "/") {
+				continue
+			}
+			served[gv.WithResource(resource.Name)] = resource.Kind
+		}
+	}
+	return served, nil
+}
+
+// conversionFor returns the conversion of an archived resource to a served
+// version. Only the mechanical conversions in apiConversions are used, as
+// other versions of a resource may differ in ways a restore cannot repair.
+func (s servedAPIs) conversionFor(gvr schema.GroupVersionResource) (apiConversion, bool) {
+	conversion, ok := apiConversions[gvr]
+	if !ok {
+		return apiConversion{}, false
+	}
+	_, served := s[conversion.target.WithResource(gvr.Resource)]
+	return conversion, served
+}
+
+// checkRestoreAPIs runs checkAPIVersions over the cluster-scoped and
+// namespaced resources of a restore, recording skipped resources and
+// findings in result. Resources whose kind differs from the one the target
+// cluster serves for their resource type are failed.
+func (bm *BackupManager) checkRestoreAPIs(ctx context.Context, clusterResources, namespacedResources []archivedResource, policy RemovedAPIPolicy, result *RestoreResult) ([]archivedResource, []archivedResource, error) {
+	served, err := bm.servedResources(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if policy == "" {
+		policy = RemovedAPIPolicyConvert
+	}
+
+	lists := [][]archivedResource{clusterResources, namespacedResources}
+	for i, resources := range lists {
+		kept, skipped, findings, err := checkAPIVersions(resources, served, policy)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, res := range skipped {
+			result.record(res, outcomeSkipped)
+		}
+		for j := range kept {
+			if kept[j].err == nil {
+				kept[j].err = served.checkKind(kept[j])
+			}
+		}
+		for _, finding := range findings {
+			ctrl.LoggerFrom(ctx).Info("Archived resource uses a removed or deprecated API version", "finding", finding.String())
+			if len(result.APIFindings) < MaxReportedAPIFindings {
+				result.APIFindings = append(result.APIFindings, finding)
+			}
+		}
+		lists[i] = kept
+	}
+	return lists[0], lists[1], nil
+}
+
+// checkAPIVersions compares the API versions of resources with those the
+// target cluster serves. Resources in removed versions are converted,
+// skipped or failed according to policy, and resources in deprecated
+// versions that are still served are reported and kept. It returns the
+// resources to restore, those left out, and a finding per affected
+// resource. Under RemovedAPIPolicyFail any removed version is an error.
+func checkAPIVersions(resources []archivedResource, served servedAPIs, policy RemovedAPIPolicy) ([]archivedResource, []archivedResource, []APIFinding, error) {
+	var (
+		kept, skipped []archivedResource
+		findings      []APIFinding
+		removed       []string
+	)
+	for _, res := range resources {
+		if res.err != nil {
+			kept = append(kept, res)
+			continue
+		}
+
+		finding := APIFinding{
+			GVR:       res.gvr,
+			Kind:      nestedString(res.object, "kind"),
+			Namespace: res.namespace,
+			Name:      res.name,
+		}
+		if _, ok := served[res.gvr]; ok {
+			if conversion, deprecated := apiConversions[res.gvr]; deprecated {
+				finding.Target = conversion.target
+				finding.Decision = APIDecisionKeep
+				findings = append(findings, finding)
+			}
+			kept = append(kept, res)
+			continue
+		}
+
+		finding.Removed = true
+		conversion, convertible := served.conversionFor(res.gvr)
+		switch {
+		case policy == RemovedAPIPolicyFail:
+			finding.Decision = APIDecisionFail
+			removed = append(removed, finding.String())
+		case policy == RemovedAPIPolicySkip:
+			finding.Decision = APIDecisionSkip
+			skipped = append(skipped, res)
+		case convertible:
+			converted, err := convertAPIVersion(res, conversion)
+			if err != nil {
+				finding.Decision = APIDecisionFail
+				res.err = err
+				kept = append(kept, res)
+				break
+			}
+			finding.Target = conversion.target
+			finding.Decision = APIDecisionConvert
+			kept = append(kept, converted)
+		default:
+			finding.Decision = APIDecisionFail
+			res.err = fmt.Errorf("%w: %s", ErrAPIRemoved, res.gvr.GroupVersion())
+			kept = append(kept, res)
+		}
+		findings = append(findings, finding)
+	}
+
+	if len(removed) > maxListedRemovedAPIs {
+		removed = append(removed[:maxListedRemovedAPIs], fmt.Sprintf("and %d more", len(removed)-maxListedRemovedAPIs))
+	}
+	if len(removed) > 0 {
+		return nil, nil, findings, fmt.Errorf("%w: %s", ErrAPIRemoved, strings.Join(removed, "; "))
+	}
+	return kept, skipped, findings, nil
+}
@@ -0,0 +1,95 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestCheckAPIVersions(t *testing.T) {
+	t.Parallel()
+
+	bm := &BackupManager{DiscoveryClient: &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{
+		{GroupVersion: "networking.k8s.io/v1", APIResources: []metav1.APIResource{{Name: "ingresses"}}},
+		{GroupVersion: "policy/v1", APIResources: []metav1.APIResource{{Name: "poddisruptionbudgets"}}},
+		{GroupVersion: "policy/v1beta1", APIResources: []metav1.APIResource{{Name: "poddisruptionbudgets"}}},
+		{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{{Name: "deployments"}, {Name: "deployments/scale"}}},
+	}}}}
+	served, err := bm.servedResources(context.Background())
+	if err != nil {
+		t.Fatalf("servedResources: %v", err)
+	}
+
+	ingress := archived("extensions", "v1beta1", "ingresses", &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "extensions/v1beta1", "kind": "Ingress",
+		"metadata": map[string]interface{}{"name": "web", "namespace": "apps"},
+		"spec": map[string]interface{}{
+			"backend": map[string]interface{}{"serviceName": "default", "servicePort": float64(80)},
+			"rules": []interface{}{map[string]interface{}{"http": map[string]interface{}{"paths": []interface{}{
+				map[string]interface{}{"path": "/", "backend": map[string]interface{}{"serviceName": "web", "servicePort": "http"}},
+			}}}},
+		},
+	}})
+	budget := archived("policy", "v1beta1", "poddisruptionbudgets", &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "policy/v1beta1", "kind": "PodDisruptionBudget",
+		"metadata": map[string]interface{}{"name": "web", "namespace": "apps"},
+	}})
+	widget := archived("example.com", "v1alpha1", "widgets", &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1alpha1", "kind": "Widget",
+		"metadata": map[string]interface{}{"name": "w", "namespace": "apps"},
+	}})
+	deployment := archived("apps", "v1", "deployments", &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1", "kind": "Deployment",
+		"metadata": map[string]interface{}{"name": "web", "namespace": "apps"},
+	}})
+	resources := []archivedResource{ingress, budget, widget, deployment}
+
+	kept, skipped, findings, err := checkAPIVersions(resources, served, RemovedAPIPolicyConvert)
+	if err != nil {
+		t.Fatalf("checkAPIVersions: %v", err)
+	}
+	if len(kept) != 4 || len(skipped) != 0 || len(findings) != 3 {
+		t.Fatalf("Convert: kept %d, skipped %d, findings %v; want 4, 0 and 3", len(kept), len(skipped), findings)
+	}
+	for i, want := range []APIDecision{APIDecisionConvert, APIDecisionKeep, APIDecisionFail} {
+		if findings[i].Decision != want {
+			t.Fatalf("finding %d = %s, want decision %s", i, findings[i], want)
+		}
+	}
+
+	converted := kept[0]
+	if converted.gvr.GroupVersion().String() != "networking.k8s.io/v1" || converted.object["apiVersion"] != "networking.k8s.io/v1" {
+		t.Fatalf("ingress converted to %s (%v), want networking.k8s.io/v1", converted.gvr, converted.object["apiVersion"])
+	}
+	if port, _, _ := unstructured.NestedInt64(converted.object, "spec", "defaultBackend", "service", "port", "number"); port != 80 {
+		t.Fatalf("default backend port = %d, want 80", port)
+	}
+	paths, _, _ := unstructured.NestedSlice(converted.object, "spec", "rules")
+	path := paths[0].(map[string]interface{})["http"].(map[string]interface{})["paths"].([]interface{})[0].(map[string]interface{})
+	if path["pathType"] != "ImplementationSpecific" {
+		t.Fatalf("pathType = %v, want ImplementationSpecific", path["pathType"])
+	}
+	if name, _, _ := unstructured.NestedString(path, "backend", "service", "port", "name"); name != "http" {
+		t.Fatalf("path backend port name = %q, want http", name)
+	}
+	if ingress.object["apiVersion"] != "extensions/v1beta1" {
+		t.Fatalf("conversion modified the archived object")
+	}
+	if !errors.Is(kept[2].err, ErrAPIRemoved) {
+		t.Fatalf("unconvertible resource err = %v, want ErrAPIRemoved", kept[2].err)
+	}
+
+	kept, skipped, _, err = checkAPIVersions(resources, served, RemovedAPIPolicySkip)
+	if err != nil || len(kept) != 2 || len(skipped) != 2 {
+		t.Fatalf("Skip: kept %d, skipped %d, err %v; want 2, 2 and no error", len(kept), len(skipped), err)
+	}
+
+	if _, _, _, err := checkAPIVersions(resources, served, RemovedAPIPolicyFail); !errors.Is(err, ErrAPIRemoved) {
+		t.Fatalf("Fail: expected ErrAPIRemoved, got %v", err)
+	}
+}
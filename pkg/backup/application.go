@@ -0,0 +1,262 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/pager"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var (
+	configMapsResource = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	secretsResource    = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	pvcsResource       = schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"}
+)
+
+// appObject is an object collected for an application backup.
+type appObject struct {
+	gvr schema.GroupVersionResource
+	obj *unstructured.Unstructured
+}
+
+// collectApplication writes opts.Application, the objects it transitively
+// owns and the ConfigMaps, Secrets and PersistentVolumeClaims their pod
+// templates reference. Owned objects are searched in the root's namespace,
+// or among cluster-scoped types for a cluster-scoped root. The root must
+// exist; references to missing objects are skipped.
+func (bm *BackupManager) collectApplication(ctx context.Context, archive *archiveWriter, opts BackupOptions) (int, error) {
+	log := ctrl.LoggerFrom(ctx)
+	root := *opts.Application
+
+	rootGVR := root.Resource
+	if rootGVR.Resource == "" {
+		apiResourceLists, err := bm.DiscoveryClient.ServerPreferredResources()
+		if err != nil {
+			log.Error(err, "Warning: Error discovering some API resources (continuing anyway)")
+		}
+		if rootGVR, err = resolveItemResource(apiResourceLists, root.Kind); err != nil {
+			return 0, fmt.Errorf("failed to resolve application %s: %w", root, err)
+		}
+	}
+	rootObj, err := bm.getObject(ctx, rootGVR, root.Namespace, root.Name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get application %s: %w", root, err)
+	}
+
+	objects := append([]appObject{{gvr: rootGVR, obj: rootObj}}, bm.ownedObjects(ctx, rootObj)...)
+
+	for _, ref := range podTemplateReferences(objects) {
+		obj, err := bm.getObject(ctx, ref.gvr, rootObj.GetNamespace(), ref.name)
+		if apierrors.IsNotFound(err) {
+			log.V(1).Info("Skipping missing referenced object", "resource", ref.gvr.Resource, "name", ref.name)
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to get referenced %s %q: %w", ref.gvr.Resource, ref.name, err)
+		}
+		objects = append(objects, appObject{gvr: ref.gvr, obj: obj})
+	}
+
+	count := 0
+	written := map[string]struct{}{}
+	for i, object := range objects {
+		name := path.Join(archiveDir(object.gvr, object.obj.GetNamespace()), fmt.Sprintf("%s.json", object.obj.GetName()))
+		if _, ok := written[name]; ok {
+			continue
+		}
+		written[name] = struct{}{}
+		// The root is always kept, like an explicitly named item
+		if i > 0 && archive.exclude != nil && archive.exclude(object.obj.Object) {
+			continue
+		}
+		cleanResource(object.obj)
+		if err := archive.writeObject(name, object.obj.Object); err != nil {
+			return count, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		count++
+	}
+	log.V(1).Info("Collected application", "application", root.String(), "objects", count)
+	return count, nil
+}
+
+// getObject reads one object, namespaced when namespace is set.
+func (bm *BackupManager) getObject(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+	if namespace == "" {
+		return bm.DynamicClient.Resource(gvr).Get(ctx, name, metav1.GetOptions{})
+	}
+	return bm.DynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// ownedObjects returns the objects root transitively owns through
+// ownerReferences. Types that cannot be listed are skipped.
+func (bm *BackupManager) ownedObjects(ctx context.Context, root *unstructured.Unstructured) []appObject {
+	log := ctrl.LoggerFrom(ctx)
+	namespace := root.GetNamespace()
+
+	// Index every object with owners by owner UID
+	byOwner := map[types.UID][]appObject{}
+	targets := bm.discoverResources(ctx, BackupOptions{IncludeClusterResources: namespace == ""})
+	for _, target := range targets {
+		if target.namespaced != (namespace != "") {
+			continue
+		}
+		resourceClient := bm.DynamicClient.Resource(target.gvr)
+		listPage := func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return resourceClient.List(ctx, opts)
+		}
+		if namespace != "" {
+			listPage = func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+				return resourceClient.Namespace(namespace).List(ctx, opts)
+			}
+		}
+		p := pager.New(listPage)
+		p.PageSize = listPageSize
+		err := p.EachListItem(ctx, metav1.ListOptions{}, func(obj runtime.Object) error {
+			item, ok := obj.(*unstructured.Unstructured)
+			if !ok {
+				return fmt.Errorf("unexpected list item type %T", obj)
+			}
+			for _, owner := range item.GetOwnerReferences() {
+				byOwner[owner.UID] = append(byOwner[owner.UID], appObject{gvr: target.gvr, obj: item})
+			}
+			return nil
+		})
+		if err != nil {
+			log.Error(err, "Failed to list resource for application", "gvr", target.gvr)
+		}
+	}
+
+	var owned []appObject
+	visited := map[types.UID]struct{}{root.GetUID(): {}}
+	queue := []types.UID{root.GetUID()}
+	for len(queue) > 0 {
+		uid := queue[0]
+		queue = queue[1:]
+		for _, child := range byOwner[uid] {
+			if _, ok := visited[child.obj.GetUID()]; ok {
+				continue
+			}
+			visited[child.obj.GetUID()] = struct{}{}
+			owned = append(owned, child)
+			queue = append(queue, child.obj.GetUID())
+		}
+	}
+	return owned
+}
+
+// objectReference names a ConfigMap, Secret or PersistentVolumeClaim in the
+// application's namespace.
+type objectReference struct {
+	gvr  schema.GroupVersionResource
+	name string
+}
+
+// podTemplateReferences returns the ConfigMaps, Secrets and
+// PersistentVolumeClaims referenced by the pod specs of objects, without
+// duplicates.
+func podTemplateReferences(objects []appObject) []objectReference {
+	var refs []objectReference
+	seen := map[objectReference]struct{}{}
+	for _, object := range objects {
+		for _, ref := range podSpecReferences(object.obj.Object) {
+			if _, ok := seen[ref]; ok {
+				continue
+			}
+			seen[ref] = struct{}{}
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// podSpecReferences returns the ConfigMaps, Secrets and
+// PersistentVolumeClaims referenced by the pod spec of obj: a Pod, or the
+// pod template of a workload or CronJob.
+func podSpecReferences(obj map[string]interface{}) []objectReference {
+	var refs []objectReference
+	add := func(gvr schema.GroupVersionResource, name string) {
+		if name != "" {
+			refs = append(refs, objectReference{gvr: gvr, name: name})
+		}
+	}
+
+	for _, fields := range [][]string{
+		{"spec"},
+		{"spec", "template", "spec"},
+		{"spec", "jobTemplate", "spec", "template", "spec"},
+	} {
+		podSpec, found, _ := unstructured.NestedMap(obj, fields...)
+		if !found {
+			continue
+		}
+		for _, volume := range nestedMaps(podSpec, "volumes") {
+			add(configMapsResource, nestedString(volume, "configMap", "name"))
+			add(secretsResource, nestedString(volume, "secret", "secretName"))
+			add(pvcsResource, nestedString(volume, "persistentVolumeClaim", "claimName"))
+			projected, _, _ := unstructured.NestedMap(volume, "projected")
+			for _, source := range nestedMaps(projected, "sources") {
+				add(configMapsResource, nestedString(source, "configMap", "name"))
+				add(secretsResource, nestedString(source, "secret", "name"))
+			}
+		}
+		for _, secret := range nestedMaps(podSpec, "imagePullSecrets") {
+			add(secretsResource, nestedString(secret, "name"))
+		}
+		for _, list := range []string{"initContainers", "containers", "ephemeralContainers"} {
+			for _, container := range nestedMaps(podSpec, list) {
+				for _, env := range nestedMaps(container, "env") {
+					add(configMapsResource, nestedString(env, "valueFrom", "configMapKeyRef", "name"))
+					add(secretsResource, nestedString(env, "valueFrom", "secretKeyRef", "name"))
+				}
+				for _, envFrom := range nestedMaps(container, "envFrom") {
+					add(configMapsResource, nestedString(envFrom, "configMapRef", "name"))
+					add(secretsResource, nestedString(envFrom, "secretRef", "name"))
+				}
+			}
+		}
+	}
+	return refs
+}
+
+// nestedMaps returns the objects in the list at fields, skipping other
+// elements.
+func nestedMaps(obj map[string]interface{}, fields ...string) []map[string]interface{} {
+	list, _, _ := unstructured.NestedSlice(obj, fields...)
+	maps := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		if m, ok := item.(map[string]interface{}); ok {
+			maps = append(maps, m)
+		}
+	}
+	return maps
+}
+
+// nestedString returns the string at fields, or "" when it is missing.
+func nestedString(obj map[string]interface{}, fields ...string) string {
+	value, _, _ := unstructured.NestedString(obj, fields...)
+	return value
+}
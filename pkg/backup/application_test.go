@@ -0,0 +1,129 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+// preferredResources serves a fixed discovery result.
+type preferredResources struct {
+	discovery.DiscoveryInterface
+	lists []*metav1.APIResourceList
+}
+
+func (p preferredResources) ServerPreferredResources() ([]*metav1.APIResourceList, error) {
+	return p.lists, nil
+}
+
+func TestCollectApplication(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := appsv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	owner := func(kind, name string, uid types.UID) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: kind, Name: name, UID: uid}}
+	}
+	podSpec := corev1.PodSpec{
+		Containers: []corev1.Container{{
+			Name: "api",
+			EnvFrom: []corev1.EnvFromSource{{
+				ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "api-settings"}},
+			}},
+			Env: []corev1.EnvVar{{Name: "TOKEN", ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "api-token"}, Key: "token"},
+			}}},
+		}},
+		Volumes: []corev1.Volume{
+			{Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "api-data"}}},
+			{Name: "optional", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "missing"}}}},
+		},
+	}
+	dynamicClient := fake.NewSimpleDynamicClient(scheme,
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "payments", UID: "deploy"},
+			Spec:       appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: podSpec}},
+		},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "api-7f9", Namespace: "payments", UID: "rs", OwnerReferences: owner("Deployment", "api", "deploy")}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api-7f9-x", Namespace: "payments", UID: "pod", OwnerReferences: owner("ReplicaSet", "api-7f9", "rs")}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "payments", UID: "other", OwnerReferences: owner("ReplicaSet", "other", "elsewhere")}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "api-settings", Namespace: "payments"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "unreferenced", Namespace: "payments"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "api-token", Namespace: "payments"}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "api-data", Namespace: "payments"}},
+	)
+	bm := &BackupManager{
+		DynamicClient: dynamicClient,
+		DiscoveryClient: preferredResources{lists: []*metav1.APIResourceList{
+			{GroupVersion: "v1", APIResources: []metav1.APIResource{
+				{Name: "pods", SingularName: "pod", Kind: "Pod", Namespaced: true, Verbs: []string{"list", "get"}},
+				{Name: "configmaps", SingularName: "configmap", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"list", "get"}},
+			}},
+			{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{
+				{Name: "deployments", SingularName: "deployment", Kind: "Deployment", Namespaced: true, Verbs: []string{"list", "get"}},
+				{Name: "replicasets", SingularName: "replicaset", Kind: "ReplicaSet", Namespaced: true, Verbs: []string{"list", "get"}},
+			}},
+		}},
+	}
+
+	var out bytes.Buffer
+	archive := newArchiveWriter(&out)
+	count, err := bm.collectApplication(context.Background(), archive, BackupOptions{
+		Application: &BackupItem{Kind: "deployment.apps", Namespace: "payments", Name: "api"},
+	})
+	if err != nil {
+		t.Fatalf("collectApplication: %v", err)
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	tarReader := tar.NewReader(&out)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.Name != manifestName {
+			names = append(names, header.Name)
+		}
+	}
+	sort.Strings(names)
+	want := []string{
+		"namespaces/payments/apps/v1/deployments/api.json",
+		"namespaces/payments/apps/v1/replicasets/api-7f9.json",
+		"namespaces/payments/v1/configmaps/api-settings.json",
+		"namespaces/payments/v1/persistentvolumeclaims/api-data.json",
+		"namespaces/payments/v1/pods/api-7f9-x.json",
+		"namespaces/payments/v1/secrets/api-token.json",
+	}
+	if count != len(want) || len(names) != len(want) {
+		t.Fatalf("archived %d objects %v, want %v", count, names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("archived %v, want %v", names, want)
+		}
+	}
+}
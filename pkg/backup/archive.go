@@ -0,0 +1,1492 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/pager"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// listPageSize is the number of objects requested per List call when
+	// collecting resources.
+	listPageSize = 500
+
+	// resourceTypesPerWorker and namespacesPerWorker control how the default
+	// concurrency scales with the size of the discovered work.
+	resourceTypesPerWorker = 8
+	namespacesPerWorker    = 10
+
+	// maxDefaultResourceTypeWorkers and maxDefaultNamespaceWorkers cap the
+	// derived defaults so large clusters don't flood the apiserver.
+	maxDefaultResourceTypeWorkers = 4
+	maxDefaultNamespaceWorkers    = 4
+
+	// maxRetainedBufferSize caps the encode buffer kept between archive
+	// entries.
+	maxRetainedBufferSize = 1 << 20
+
+	// archiveDeleteWorkers bounds the archives a cleanup removes at once,
+	// which hides the latency of object storage mounts.
+	archiveDeleteWorkers = 8
+)
+
+// BackupManager handles the backup operations
+type BackupManager struct {
+	Config          *rest.Config
+	DynamicClient   dynamic.Interface
+	DiscoveryClient discovery.DiscoveryInterface
+
+	// RESTMapper maps the kinds of restored objects to the resources of the
+	// target cluster. Nil uses a mapper over DiscoveryClient. Mappers
+	// implementing meta.ResettableRESTMapper are reset, at most once per
+	// restore, when a kind does not match or an object does not fit its
+	// mapping.
+	RESTMapper meta.RESTMapper
+
+	// HTTPClient downloads archives restored from a URL. Nil uses a client
+	// that only follows redirects to https.
+	HTTPClient *http.Client
+
+	// HostStorage maps host:// storage locations to node directories
+	// mounted into the operator container.
+	HostStorage HostStorage
+
+	// rateLimiter is shared by the clients so their limits can be changed
+	// at runtime.
+	rateLimiter *adjustableRateLimiter
+
+	// memoryBudget, when set, bounds the listed objects held in memory by
+	// all backups run through the manager.
+	memoryBudget *memoryBudget
+
+	// tempDirs tracks the staging directories of the runs in progress.
+	tempDirs *tempDirs
+
+	// archiveRefs protects the archives read by restores from retention.
+	archiveRefs *archiveReferences
+
+	// storageLocks keeps runs sharing a storage location from interleaving.
+	storageLocks *storageLocks
+
+	// webhookRelaxations counts the restores relaxing the webhook failure
+	// policies of the cluster.
+	webhookRelaxations *webhookRelaxations
+
+	// auditMu serializes appends to audit logs.
+	auditMu sync.Mutex
+
+	// restMapperOnce builds discoveredMapper, the mapper used when
+	// RESTMapper is nil.
+	restMapperOnce   sync.Once
+	discoveredMapper meta.RESTMapper
+}
+
+// BackupOptions contains configuration for a backup operation
+type BackupOptions struct {
+	IncludeNamespaces       []string
+	ExcludeNamespaces       []string
+	IncludeClusterResources bool
+	ResourceTypes           []string
+
+	// IncludeClusterResourceTypes, when set, selects the cluster-scoped
+	// kinds other than Namespace instead of ResourceTypes, and
+	// ExcludeClusterResourceTypes leaves cluster-scoped kinds out.
+	IncludeClusterResourceTypes []string
+	ExcludeClusterResourceTypes []string
+
+	// ExcludeNamespacePatterns are regular expressions matched against
+	// namespace names. Matching namespaces are left out even when
+	// IncludeNamespaces, Items, Application or a pod template names them.
+	ExcludeNamespacePatterns []string
+
+	// Items, when set, backs up only the named objects instead of scanning
+	// the cluster. Namespace and resource type filters do not apply.
+	Items []BackupItem
+
+	// IncludeReferencedResources also backs up the ConfigMaps, Secrets and
+	// PersistentVolumeClaims referenced by the pod specs of backed-up
+	// objects, even when their types or names were not selected.
+	IncludeReferencedResources bool
+
+	// ScratchDir names the scratch directory, added with AddScratchDir, the
+	// archive is staged in. Empty stages it in the temp directory.
+	// WriteBackup stages nothing and ignores it.
+	ScratchDir string
+
+	// Application, when set, backs up this root object, the objects it
+	// transitively owns and the ConfigMaps, Secrets and
+	// PersistentVolumeClaims their pod templates reference. It takes
+	// precedence over Items and the namespace and resource type filters.
+	Application *BackupItem
+
+	// ConcurrentResourceTypes bounds how many resource types are collected in
+	// parallel. Zero derives a default from the number of discovered types.
+	ConcurrentResourceTypes int
+	// ConcurrentNamespaces bounds how many namespaces are listed in parallel
+	// for each resource type. Zero derives a default from the namespace count.
+	ConcurrentNamespaces int
+
+	// ListPageSize is the number of objects requested per List call. Zero
+	// uses 500. Smaller pages keep less in memory at once.
+	ListPageSize int
+	// PageInterval is waited between the List calls for each resource type
+	// and namespace, spreading the load of a backup over time.
+	PageInterval time.Duration
+
+	// ExcludeGitOpsManaged leaves out objects tracked by Argo CD or Flux.
+	ExcludeGitOpsManaged bool
+
+	// IncludeGeneratedResources backs up service account token Secrets and
+	// kube-root-ca.crt ConfigMaps, which are left out by default.
+	IncludeGeneratedResources bool
+
+	// IncludeFinishedWorkloads backs up Pods that succeeded or failed and
+	// Jobs that completed or failed, which are left out by default.
+	IncludeFinishedWorkloads bool
+
+	// SkipReissuableCertificateSecrets leaves out Secrets that cert-manager
+	// can issue again from the Certificates in the backup. CA Secrets used
+	// by issuers are always kept.
+	SkipReissuableCertificateSecrets bool
+
+	// Compression of the tar stream. Empty defaults to gzip.
+	Compression Compression
+
+	// KeyWrappers, when set, encrypt the archive with a fresh data key that is
+	// wrapped by each of them. Only the wrapped keys are stored.
+	KeyWrappers []KeyWrapper
+
+	// SecretEncryption, when set, encrypts every Secret on its own before
+	// it is written, so the archive holds no plaintext credentials even
+	// without KeyWrappers.
+	SecretEncryption *SecretEncryption
+
+	// Export, when set, also writes the backed-up objects as a GitOps
+	// directory tree.
+	Export *GitOpsExport
+
+	// ReplicaStoragePaths are additional storage locations the archive is
+	// copied to. Failed copies are reported in BackupResult.Replicas.
+	ReplicaStoragePaths []string
+
+	// Immutability, when set, locks the archive and its replicas against
+	// deletion by retention.
+	Immutability *Immutability
+
+	// RotationTag, when set, is recorded next to the archive and its
+	// replicas for RotateArchives.
+	RotationTag RotationTag
+
+	// RunID identifies the backup run in the archive manifest.
+	RunID string
+
+	// Incremental, when set, references the objects whose resourceVersion
+	// is unchanged since the latest full archive in the storage location
+	// instead of writing them again. It cannot be combined with
+	// KeyWrappers, since the base could not be read without its keys.
+	Incremental *IncrementalOptions
+
+	// MissingPermissionPolicy, when set, checks that the operator may list
+	// every selected resource before anything is collected. Empty skips the
+	// check.
+	MissingPermissionPolicy MissingPermissionPolicy
+
+	// Progress, when set, is called once the resource types to scan are
+	// discovered and then after each of them is collected, with the running
+	// and total resource type counts. Items and Application backups do not
+	// report progress.
+	Progress func(processed, total int)
+
+	// PartialOnCancel stores the objects collected so far as an archive
+	// marked partial when ctx is cancelled mid-collection, as on operator
+	// shutdown, instead of failing the backup.
+	PartialOnCancel bool
+
+	// skipped holds the resources the pre-flight check found the operator
+	// may not list.
+	skipped map[PermissionGap]struct{}
+	// namespacePatterns are the compiled ExcludeNamespacePatterns.
+	namespacePatterns namespacePatterns
+	// base is the archive an incremental run references objects from.
+	base *incrementalBase
+	// timings, when set, receives the time spent per resource type.
+	timings map[schema.GroupVersionResource]*ResourceTiming
+	// partial, when set, lets an interrupted collection finish as a partial
+	// archive and receives whether it did.
+	partial *bool
+	// manifestDigest, when set, receives the digest of the manifest written.
+	manifestDigest *string
+}
+
+// BackupResult contains the results of a backup operation
+type BackupResult struct {
+	ResourceCount int
+	FilePath      string
+	// ExportPath is the directory holding the GitOps export, if written to
+	// storage, and ExportCommit the commit it was pushed as.
+	ExportPath   string
+	ExportCommit string
+	// Replicas holds the outcome for each replica storage location.
+	Replicas []ReplicaResult
+	// SkippedResources lists the resources left out because the operator
+	// may not list them.
+	SkippedResources []PermissionGap
+	// BaseArchive is the archive an incremental backup referenced
+	// ReusedResources unchanged objects from.
+	BaseArchive     string
+	ReusedResources int
+	// ResourceTimings holds the time spent on each resource type, slowest
+	// first.
+	ResourceTimings []ResourceTiming
+	// Partial is set when the backup was interrupted and the archive holds
+	// only the objects collected until then.
+	Partial bool
+	// ArchiveBytes is the size of the stored archive.
+	ArchiveBytes int64
+	// ScratchDir is the directory the archive was staged in and
+	// ScratchBytes the most the run held there at once, the staged archive
+	// and export, to size the volume backing it. The working copy of a git
+	// export is not counted.
+	ScratchDir   string
+	ScratchBytes int64
+	Error        error
+}
+
+// NewBackupManager creates a new BackupManager
+func NewBackupManager(config *rest.Config) (*BackupManager, error) {
+	rateLimiter := newAdjustableRateLimiter(config)
+	config = rest.CopyConfig(config)
+	config.RateLimiter = rateLimiter
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery client: %w", err)
+	}
+
+	return &BackupManager{
+		Config:             config,
+		DynamicClient:      dynamicClient,
+		DiscoveryClient:    discoveryClient,
+		rateLimiter:        rateLimiter,
+		tempDirs:           &tempDirs{active: map[string]struct{}{}},
+		archiveRefs:        newArchiveReferences(),
+		storageLocks:       newStorageLocks(),
+		webhookRelaxations: &webhookRelaxations{},
+	}, nil
+}
+
+// CreateBackup performs a full cluster backup
+func (bm *BackupManager) CreateBackup(ctx context.Context, storagePath string, opts BackupOptions) (*BackupResult, error) {
+	log := ctrl.LoggerFrom(ctx)
+	log.Info("Starting cluster backup", "storagePath", storagePath)
+
+	skipped, err := bm.prepareBackup(ctx, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create temporary directory used to stage the archive before it is
+	// moved into the storage location
+	tempDir, removeTempDir, err := bm.makeTempDir(opts.ScratchDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer removeTempDir()
+	if err := bm.checkScratchSpace(ctx, tempDir, storagePath); err != nil {
+		return nil, err
+	}
+
+	// Create archive file with timestamp
+	timestamp := time.Now().Format("20060102-150405")
+	archiveName := fmt.Sprintf("cluster-backup-%s%s", timestamp, archiveExtension(opts.Compression))
+	stagingPath := filepath.Join(tempDir, archiveName)
+
+	var export *exportWriter
+	if opts.Export != nil {
+		export = newExportWriter(filepath.Join(tempDir, "export"), opts.Export.IncludeSecrets)
+	}
+
+	if opts.Incremental != nil {
+		if len(opts.KeyWrappers) > 0 {
+			return nil, errors.New("incremental backups cannot be encrypted, since their base could not be read without its keys")
+		}
+		if opts.base, err = bm.loadIncrementalBase(ctx, storagePath, *opts.Incremental); err != nil {
+			return nil, err
+		}
+	}
+
+	opts.timings = map[schema.GroupVersionResource]*ResourceTiming{}
+	if opts.PartialOnCancel {
+		opts.partial = new(bool)
+	}
+	opts.manifestDigest = new(string)
+	resourceCount, err := bm.stageArchive(ctx, stagingPath, export, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive: %w", err)
+	}
+	// Staging only grows until the archive is moved out
+	scratchBytes := scratchUsage(tempDir)
+	partial := opts.partial != nil && *opts.partial
+	if partial {
+		// Storing what was collected must not be cut short by the
+		// cancellation that interrupted the collection
+		log.Info("Backup interrupted, storing the resources collected so far as a partial archive", "resourceCount", resourceCount)
+		ctx = context.WithoutCancel(ctx)
+	}
+
+	// Replicas are copied before the staged archive is moved to storagePath
+	replicas := bm.replicateArchive(ctx, stagingPath, opts.ReplicaStoragePaths)
+
+	archivePath, err := bm.publishArchive(stagingPath, storagePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store archive: %w", err)
+	}
+
+	result := &BackupResult{
+		ResourceCount:    resourceCount,
+		FilePath:         archivePath,
+		Replicas:         replicas,
+		SkippedResources: skipped,
+		ResourceTimings:  slowestResources(opts.timings),
+		Partial:          partial,
+		ScratchDir:       filepath.Dir(filepath.Dir(tempDir)),
+		ScratchBytes:     scratchBytes,
+	}
+	if info, err := os.Stat(archivePath); err == nil {
+		result.ArchiveBytes = info.Size()
+	}
+	// The archive is already in storagePath. Whatever fails from here on
+	// removes it again, so a run reported as failed leaves nothing behind for
+	// its retry to duplicate, and the lock comes last so a removed archive
+	// was never locked.
+	if opts.base != nil && opts.base.reused > 0 {
+		result.BaseArchive = opts.base.name
+		result.ReusedResources = opts.base.reused
+		if err := writeBaseMarker(archivePath, opts.base.name); err != nil {
+			return nil, discardArchive(result, fmt.Errorf("failed to record base archive: %w", err))
+		}
+		for i, replica := range result.Replicas {
+			if replica.Error != nil {
+				continue
+			}
+			if err := writeBaseMarker(replica.FilePath, opts.base.name); err != nil {
+				result.Replicas[i].Error = fmt.Errorf("failed to record base archive: %w", err)
+			}
+		}
+	}
+	if keys := EncryptionKeyIDs(opts.KeyWrappers); len(keys) > 0 {
+		if err := recordArchiveKeys(archivePath, keys); err != nil {
+			return nil, discardArchive(result, fmt.Errorf("failed to record archive keys: %w", err))
+		}
+		for i, replica := range result.Replicas {
+			if replica.Error != nil {
+				continue
+			}
+			if err := recordArchiveKeys(replica.FilePath, keys); err != nil {
+				result.Replicas[i].Error = fmt.Errorf("failed to record archive keys: %w", err)
+			}
+		}
+	}
+	if opts.RotationTag != "" {
+		if err := tagArchive(archivePath, opts.RotationTag); err != nil {
+			return nil, discardArchive(result, fmt.Errorf("failed to tag archive: %w", err))
+		}
+		for i, replica := range result.Replicas {
+			if replica.Error != nil {
+				continue
+			}
+			if err := tagArchive(replica.FilePath, opts.RotationTag); err != nil {
+				result.Replicas[i].Error = fmt.Errorf("failed to tag archive: %w", err)
+			}
+		}
+	}
+	if err := catalogArchive(archivePath, *opts.manifestDigest); err != nil {
+		return nil, discardArchive(result, fmt.Errorf("failed to update catalog: %w", err))
+	}
+	for i, replica := range result.Replicas {
+		if replica.Error != nil {
+			continue
+		}
+		if err := catalogArchive(replica.FilePath, *opts.manifestDigest); err != nil {
+			result.Replicas[i].Error = fmt.Errorf("failed to update catalog: %w", err)
+		}
+	}
+	// A partial export would delete the objects it is missing from git
+	if export != nil && !partial {
+		message := fmt.Sprintf("Export %s\n\n%d resources backed up.", archiveName, resourceCount)
+		if err := bm.publishExport(ctx, export.dir, storagePath, opts.Export, message, result); err != nil {
+			return nil, discardArchive(result, fmt.Errorf("failed to export backup: %w", err))
+		}
+	}
+	if opts.Immutability != nil {
+		now := time.Now()
+		if err := lockArchive(archivePath, *opts.Immutability, now); err != nil {
+			return nil, discardArchive(result, fmt.Errorf("failed to lock archive: %w", err))
+		}
+		for i, replica := range result.Replicas {
+			if replica.Error != nil {
+				continue
+			}
+			if err := lockArchive(replica.FilePath, *opts.Immutability, now); err != nil {
+				result.Replicas[i].Error = fmt.Errorf("failed to lock archive: %w", err)
+			}
+		}
+	}
+
+	log.Info("Backup completed successfully", "resourceCount", resourceCount, "archivePath", archivePath)
+
+	return result, nil
+}
+
+// discardArchive removes the archive of result, and the replicas copied
+// along with it, after a step that follows storing it failed. err is
+// returned, annotated if the archive could not be removed.
+func discardArchive(result *BackupResult, err error) error {
+	paths := []string{result.FilePath}
+	for _, replica := range result.Replicas {
+		if replica.Error == nil {
+			paths = append(paths, replica.FilePath)
+		}
+	}
+	for _, path := range paths {
+		_ = os.Chmod(path, 0644)
+		if removeErr := removeArchive(path); removeErr != nil {
+			err = fmt.Errorf("%w; failed to remove %s: %v", err, path, removeErr)
+		}
+	}
+	return err
+}
+
+// WriteBackup streams the archive of the resources selected by opts to w
+// instead of storing it, for example to stdout or an HTTP response.
+// Settings that refer to a storage location, Incremental, Export,
+// ReplicaStoragePaths, Immutability and RotationTag, are rejected.
+func (bm *BackupManager) WriteBackup(ctx context.Context, w io.Writer, opts BackupOptions) (*BackupResult, error) {
+	switch {
+	case opts.Incremental != nil:
+		return nil, fmt.Errorf("incremental backups need a storage location holding their base")
+	case opts.Export != nil:
+		return nil, fmt.Errorf("GitOps exports need a storage location or repository")
+	case len(opts.ReplicaStoragePaths) > 0, opts.Immutability != nil, opts.RotationTag != "":
+		return nil, fmt.Errorf("replicas, immutability and rotation tags need a storage location")
+	}
+	skipped, err := bm.prepareBackup(ctx, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.timings = map[schema.GroupVersionResource]*ResourceTiming{}
+	if opts.PartialOnCancel {
+		opts.partial = new(bool)
+	}
+	var written byteCounter
+	resourceCount, err := bm.writeArchive(ctx, io.MultiWriter(w, &written), nil, nil, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	return &BackupResult{
+		ResourceCount:    resourceCount,
+		SkippedResources: skipped,
+		ResourceTimings:  slowestResources(opts.timings),
+		Partial:          opts.partial != nil && *opts.partial,
+		ArchiveBytes:     int64(written),
+	}, nil
+}
+
+// prepareBackup compiles the filters of opts and runs the pre-flight
+// permission check, returning the resources it leaves out.
+func (bm *BackupManager) prepareBackup(ctx context.Context, opts *BackupOptions) ([]PermissionGap, error) {
+	var err error
+	if opts.namespacePatterns, err = compileNamespacePatterns(opts.ExcludeNamespacePatterns); err != nil {
+		return nil, err
+	}
+	skipped, err := bm.checkListPermissions(ctx, *opts)
+	if err != nil {
+		return nil, err
+	}
+	opts.skipped = permissionGapSet(skipped)
+	return skipped, nil
+}
+
+// publishExport moves a staged GitOps export below the storage path, or
+// commits it to the configured repository, and records where it went
+func (bm *BackupManager) publishExport(ctx context.Context, stagedDir, storagePath string, export *GitOpsExport, message string, result *BackupResult) error {
+	if export.Repository != nil {
+		commit, err := commitExport(ctx, export.Repository, stagedDir, export.Path, message)
+		if err != nil {
+			return err
+		}
+		result.ExportCommit = commit
+		return nil
+	}
+
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return err
+	}
+	target, err := exportDir(resolvedStoragePath, export.Path, false)
+	if err != nil {
+		return err
+	}
+	if err := syncDir(stagedDir, target); err != nil {
+		return err
+	}
+	result.ExportPath = target
+	return nil
+}
+
+// stageArchive streams every selected resource into a compressed tar file at
+// stagingPath, mirroring them into export when it is set
+func (bm *BackupManager) stageArchive(ctx context.Context, stagingPath string, export *exportWriter, opts BackupOptions) (int, error) {
+	file, err := os.Create(stagingPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create archive file: %w", err)
+	}
+	defer file.Close()
+
+	resourceCount, err := bm.writeArchive(ctx, file, export, nil, opts)
+	if err != nil {
+		return 0, err
+	}
+	if err := file.Close(); err != nil {
+		return 0, fmt.Errorf("failed to close archive file: %w", err)
+	}
+	return resourceCount, nil
+}
+
+// writeArchive streams every selected resource into a compressed tar stream
+// written to file. When sizes is set, it receives the number and encoded
+// size of the objects written per resource type.
+func (bm *BackupManager) writeArchive(ctx context.Context, file io.Writer, export *exportWriter, sizes map[schema.GroupVersionResource]*ResourceEstimate, opts BackupOptions) (int, error) {
+	var err error
+	out := file
+	var encWriter io.WriteCloser
+	if len(opts.KeyWrappers) > 0 {
+		encWriter, err = newArchiveEncrypter(ctx, file, opts.KeyWrappers)
+		if err != nil {
+			return 0, fmt.Errorf("failed to set up encryption: %w", err)
+		}
+		out = encWriter
+	}
+
+	compressor, err := newCompressor(out, opts.Compression)
+	if err != nil {
+		return 0, err
+	}
+	archive := newArchiveWriter(compressor)
+	archive.compression = opts.Compression
+	if archive.compression == "" {
+		archive.compression = CompressionGzip
+	}
+	archive.encryption = encryptionFormat(opts.KeyWrappers)
+	archive.runID = opts.RunID
+	// Estimates discard the archive, so there is no manifest to identify
+	// the cluster in
+	if sizes == nil {
+		archive.cluster = bm.clusterIdentity(ctx)
+	}
+	archive.sizes = sizes
+	archive.base = opts.base
+	archive.memoryBudget = bm.memoryBudget
+	archive.pageSize = opts.ListPageSize
+	archive.pageInterval = opts.PageInterval
+	if opts.timings != nil {
+		archive.timings = opts.timings
+	}
+	if !opts.IncludeGeneratedResources {
+		archive.exclude = isClusterGenerated
+	}
+	if !opts.IncludeFinishedWorkloads {
+		archive.exclude = excludeEither(archive.exclude, isFinishedWorkload)
+	}
+	if opts.ExcludeGitOpsManaged {
+		archive.exclude = excludeEither(archive.exclude, isGitOpsManaged)
+	}
+	if len(opts.namespacePatterns) > 0 {
+		archive.exclude = excludeEither(archive.exclude, opts.namespacePatterns.excludes)
+	}
+	if opts.SkipReissuableCertificateSecrets {
+		reissuable, err := bm.reissuableCertificateSecrets(ctx)
+		if err != nil {
+			return 0, err
+		}
+		archive.exclude = excludeEither(archive.exclude, reissuable)
+	}
+	archive.export = export
+	if opts.SecretEncryption != nil {
+		if archive.secrets, err = bm.newSecretEncrypter(ctx, opts.SecretEncryption); err != nil {
+			return 0, fmt.Errorf("failed to set up secret encryption: %w", err)
+		}
+	}
+
+	collect := bm.collectResources
+	switch {
+	case opts.Application != nil:
+		collect = bm.collectApplication
+	case len(opts.Items) > 0:
+		collect = bm.collectItems
+	}
+	if opts.IncludeReferencedResources && opts.Application == nil {
+		archive.references = map[namespacedReference]struct{}{}
+	}
+	resourceCount, err := collect(ctx, archive, opts)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		// Resource types that failed to list once ctx was cancelled are
+		// missing, so the archive is never complete
+		if opts.partial == nil {
+			return 0, fmt.Errorf("backup interrupted: %w", ctxErr)
+		}
+		*opts.partial = true
+		archive.partial = true
+		resourceCount = archive.objectCount()
+	} else if err != nil {
+		return 0, err
+	}
+	if archive.references != nil && !archive.partial {
+		referenced, err := bm.collectReferences(ctx, archive)
+		if err != nil {
+			return 0, err
+		}
+		resourceCount += referenced
+	}
+
+	if err := archive.Close(); err != nil {
+		return 0, fmt.Errorf("failed to finalize tar archive: %w", err)
+	}
+	if opts.manifestDigest != nil {
+		*opts.manifestDigest = archive.manifestDigest
+	}
+	if err := compressor.Close(); err != nil {
+		return 0, fmt.Errorf("failed to finalize compressed stream: %w", err)
+	}
+	if encWriter != nil {
+		if err := encWriter.Close(); err != nil {
+			return 0, fmt.Errorf("failed to finalize encrypted stream: %w", err)
+		}
+	}
+	if export != nil {
+		if err := export.Close(); err != nil {
+			return 0, fmt.Errorf("failed to finalize export: %w", err)
+		}
+	}
+
+	return resourceCount, nil
+}
+
+// collectResources discovers the resources selected by opts and writes them
+// to the archive, returning the number of objects written
+func (bm *BackupManager) collectResources(ctx context.Context, archive *archiveWriter, opts BackupOptions) (int, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	targets := bm.discoverResources(ctx, opts)
+
+	var namespaces []string
+	for _, target := range targets {
+		if !target.namespaced {
+			continue
+		}
+		// The namespace list remains constant for the run, so load it once
+		// before any workers start
+		var err error
+		namespaces, err = bm.getNamespacesToBackup(ctx, opts)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get namespaces: %w", err)
+		}
+		break
+	}
+
+	resourceTypeWorkers := opts.ConcurrentResourceTypes
+	if resourceTypeWorkers <= 0 {
+		resourceTypeWorkers = defaultConcurrency(len(targets), resourceTypesPerWorker, maxDefaultResourceTypeWorkers)
+	}
+	namespaceWorkers := opts.ConcurrentNamespaces
+	if namespaceWorkers <= 0 {
+		namespaceWorkers = defaultConcurrency(len(namespaces), namespacesPerWorker, maxDefaultNamespaceWorkers)
+	}
+
+	log.V(1).Info("Collecting resources", "resourceTypes", len(targets), "namespaces", len(namespaces),
+		"resourceTypeWorkers", resourceTypeWorkers, "namespaceWorkers", namespaceWorkers)
+
+	var resourceCount atomic.Int64
+
+	var progressMu sync.Mutex
+	processed := 0
+	collected := func() {
+		if opts.Progress == nil {
+			return
+		}
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		processed++
+		opts.Progress(processed, len(targets))
+	}
+	if opts.Progress != nil {
+		opts.Progress(0, len(targets))
+	}
+
+	group := &errgroup.Group{}
+	group.SetLimit(resourceTypeWorkers)
+	for _, target := range targets {
+		gvr := target.gvr
+
+		// Handle namespaced vs cluster-scoped resources
+		if !target.namespaced {
+			if _, denied := opts.skipped[PermissionGap{GVR: gvr}]; denied {
+				collected()
+				continue
+			}
+			group.Go(func() error {
+				defer collected()
+				count, err := bm.backupResource(ctx, gvr, "", archive)
+				resourceCount.Add(int64(count))
+				if err != nil {
+					log.Error(err, "Failed to backup cluster resource", "gvr", gvr)
+				}
+				return nil
+			})
+			continue
+		}
+
+		if len(namespaces) == 0 {
+			collected()
+			continue
+		}
+
+		group.Go(func() error {
+			defer collected()
+			nsGroup := &errgroup.Group{}
+			nsGroup.SetLimit(namespaceWorkers)
+			for _, ns := range namespaces {
+				if _, denied := opts.skipped[PermissionGap{GVR: gvr, Namespace: ns}]; denied {
+					continue
+				}
+				nsGroup.Go(func() error {
+					count, err := bm.backupResource(ctx, gvr, ns, archive)
+					resourceCount.Add(int64(count))
+					if err != nil {
+						log.Error(err, "Failed to backup resource", "gvr", gvr, "namespace", ns)
+					}
+					return nil
+				})
+			}
+			return nsGroup.Wait()
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return 0, err
+	}
+
+	return int(resourceCount.Load()), nil
+}
+
+// resourceTarget is a listable resource type selected for backup
+type resourceTarget struct {
+	gvr        schema.GroupVersionResource
+	namespaced bool
+	// watchable is set when the resource type supports watches.
+	watchable bool
+}
+
+// discoverResources returns the listable resource types matching opts
+func (bm *BackupManager) discoverResources(ctx context.Context, opts BackupOptions) []resourceTarget {
+	log := ctrl.LoggerFrom(ctx)
+
+	resourceTypeFilter := newKindFilter(opts.ResourceTypes)
+	clusterIncludeFilter := newKindFilter(opts.IncludeClusterResourceTypes)
+	clusterExcludeFilter := newKindFilter(opts.ExcludeClusterResourceTypes)
+
+	// Discover all API resources
+	apiResourceLists, err := bm.DiscoveryClient.ServerPreferredResources()
+	if err != nil {
+		log.Error(err, "Warning: Error discovering some API resources (continuing anyway)")
+	}
+
+	var targets []resourceTarget
+	for _, apiResourceList := range apiResourceLists {
+		if apiResourceList == nil {
+			continue
+		}
+
+		gv, err := schema.ParseGroupVersion(apiResourceList.GroupVersion)
+		if err != nil {
+			log.Error(err, "Failed to parse group version", "groupVersion", apiResourceList.GroupVersion)
+			continue
+		}
+
+		for _, apiResource := range apiResourceList.APIResources {
+			// Skip subresources (like "pods/status")
+			if strings.Contains(apiResource.Name, "/") {
+				continue
+			}
+
+			// Skip resources that can't be listed
+			if !contains(apiResource.Verbs, "list") {
+				continue
+			}
+
+			clusterKind := !apiResource.Namespaced && apiResource.Kind != "Namespace"
+
+			// Filter resource types if specified; cluster-scoped kinds
+			// follow their own include list when one is set
+			filter := resourceTypeFilter
+			if clusterKind && !clusterIncludeFilter.empty() {
+				filter = clusterIncludeFilter
+			}
+			if !filter.empty() && !filter.matches(gv.Group, apiResource.Kind) {
+				continue
+			}
+			if clusterKind && clusterExcludeFilter.matches(gv.Group, apiResource.Kind) {
+				continue
+			}
+
+			// Cluster-scoped resources are only collected when requested
+			if !apiResource.Namespaced && !opts.IncludeClusterResources {
+				continue
+			}
+
+			targets = append(targets, resourceTarget{
+				gvr:        gv.WithResource(apiResource.Name),
+				namespaced: apiResource.Namespaced,
+				watchable:  contains(apiResource.Verbs, "watch"),
+			})
+		}
+	}
+
+	return targets
+}
+
+// defaultConcurrency derives a worker count from the amount of work: one
+// worker per perWorker items, clamped to [1, limit]
+func defaultConcurrency(items, perWorker, limit int) int {
+	workers := items / perWorker
+	if workers < 1 {
+		return 1
+	}
+	if workers > limit {
+		return limit
+	}
+	return workers
+}
+
+// getNamespacesToBackup returns the list of namespaces to backup based on options
+func (bm *BackupManager) getNamespacesToBackup(ctx context.Context, opts BackupOptions) ([]string, error) {
+	// If specific namespaces are included, use those
+	if len(opts.IncludeNamespaces) > 0 {
+		return opts.namespacePatterns.filter(opts.IncludeNamespaces), nil
+	}
+
+	// Otherwise, get all namespaces and filter exclusions
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "namespaces"}
+	list, err := bm.DynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	excludeSet := makeStringSet(opts.ExcludeNamespaces, func(s string) string {
+		return strings.TrimSpace(s)
+	})
+
+	var namespaces []string
+	for _, item := range list.Items {
+		ns := item.GetName()
+		if len(excludeSet) > 0 {
+			if _, skip := excludeSet[ns]; skip {
+				continue
+			}
+		}
+		if opts.namespacePatterns.matches(ns) {
+			continue
+		}
+
+		namespaces = append(namespaces, ns)
+	}
+
+	return namespaces, nil
+}
+
+// backupResource backs up a specific resource type
+func (bm *BackupManager) backupResource(ctx context.Context, gvr schema.GroupVersionResource, namespace string, archive *archiveWriter) (int, error) {
+	var resourceClient dynamic.ResourceInterface = bm.DynamicClient.Resource(gvr)
+	if namespace != "" {
+		resourceClient = bm.DynamicClient.Resource(gvr).Namespace(namespace)
+	}
+
+	listPage := func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return resourceClient.List(ctx, opts)
+	}
+
+	start := time.Now()
+	var listTime atomic.Int64
+	count, err := writeResourcePages(ctx, archive, archiveDir(gvr, namespace), timedListPage(listPage, &listTime))
+	archive.recordTiming(gvr, count, time.Since(start), time.Duration(listTime.Load()))
+	return count, err
+}
+
+// writeResourcePages lists objects page by page and streams each one into the
+// archive under dir, so only a bounded number of pages is held in memory
+func writeResourcePages(ctx context.Context, archive *archiveWriter, dir string, listPage pager.ListPageFunc) (int, error) {
+	pageSize := int64(listPageSize)
+	if archive.pageSize > 0 {
+		pageSize = int64(archive.pageSize)
+	}
+	budget := &pageBudget{archive: archive, pageSize: pageSize}
+	defer budget.release()
+
+	p := pager.New(budget.wrap(listPage))
+	p.PageSize = pageSize
+
+	count := 0
+	err := p.EachListItem(ctx, metav1.ListOptions{}, func(obj runtime.Object) error {
+		defer budget.done()
+		item, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return fmt.Errorf("unexpected list item type %T", obj)
+		}
+
+		if archive.exclude != nil && archive.exclude(item.Object) {
+			return nil
+		}
+
+		resourceVersion := item.GetResourceVersion()
+
+		// Remove managed fields and other runtime data
+		cleanResource(item)
+
+		name := path.Join(dir, fmt.Sprintf("%s.json", item.GetName()))
+		reused, err := archive.reuseObject(name, resourceVersion, item.Object)
+		if err != nil {
+			return fmt.Errorf("failed to reference resource %q: %w", item.GetName(), err)
+		}
+		if reused {
+			count++
+			return nil
+		}
+		if err := archive.writeObject(name, item.Object); err != nil {
+			return fmt.Errorf("failed to write resource %q: %w", item.GetName(), err)
+		}
+		archive.recordResourceVersion(name, resourceVersion)
+		count++
+		return nil
+	})
+
+	return count, err
+}
+
+// excludeEither combines two exclusion filters, either of which may be nil
+func excludeEither(a, b func(obj map[string]interface{}) bool) func(obj map[string]interface{}) bool {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	return func(obj map[string]interface{}) bool {
+		return a(obj) || b(obj)
+	}
+}
+
+// archiveDir returns the directory inside the archive holding objects of gvr
+func archiveDir(gvr schema.GroupVersionResource, namespace string) string {
+	if namespace != "" {
+		return path.Join("namespaces", namespace, gvr.Group, gvr.Version, gvr.Resource)
+	}
+	return path.Join("cluster", gvr.Group, gvr.Version, gvr.Resource)
+}
+
+// cleanResource removes runtime fields that shouldn't be in backups
+func cleanResource(obj *unstructured.Unstructured) {
+	// Remove managed fields
+	unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+
+	// Remove resource version and UID as they are cluster-specific
+	unstructured.RemoveNestedField(obj.Object, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "uid")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "selfLink")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "generation")
+
+	// Remove status as it will be regenerated
+	unstructured.RemoveNestedField(obj.Object, "status")
+}
+
+// archiveWriter streams JSON encoded resources into a tar stream. A single
+// buffer is reused for every entry, so memory use is bounded by the largest
+// object rather than by the number of objects being archived.
+type archiveWriter struct {
+	mu      sync.Mutex
+	tw      *tar.Writer
+	buf     bytes.Buffer
+	enc     *json.Encoder
+	modTime time.Time
+	// digests collects the checksum of every entry for the manifest.
+	digests map[string]string
+	// compression and encryption describe the archive format in the manifest.
+	compression Compression
+	encryption  string
+	// runID and cluster are recorded in the manifest.
+	runID   string
+	cluster *ClusterIdentity
+	// helmReleases inventories the Helm release Secrets written.
+	helmReleases []helmRelease
+	// exclude, when set, reports objects to leave out of the archive.
+	exclude func(obj map[string]interface{}) bool
+	// export, when set, receives a YAML copy of every object written.
+	export *exportWriter
+	// secrets, when set, encrypts Secrets before they are written.
+	secrets secretEncrypter
+	// references, when set, collects the ConfigMaps, Secrets and
+	// PersistentVolumeClaims referenced by the namespaced objects written.
+	references map[namespacedReference]struct{}
+	// sizes, when set, accumulates the objects written per resource type.
+	sizes map[schema.GroupVersionResource]*ResourceEstimate
+	// resourceVersions records the resourceVersion of every listed object
+	// for the manifest, so the next incremental run can spot unchanged ones.
+	resourceVersions map[string]string
+	// base, when set, holds the objects this archive may reference instead
+	// of writing them again.
+	base *incrementalBase
+	// partial marks the archive as interrupted in the manifest.
+	partial bool
+	// manifestDigest is the digest of the manifest, set by Close.
+	manifestDigest string
+	// memoryBudget, pageSize and pageInterval bound and pace the List calls
+	// of writeResourcePages.
+	memoryBudget *memoryBudget
+	pageSize     int
+	pageInterval time.Duration
+	// encodedBytes and encodedObjects size the budget reserved per page.
+	encodedBytes   int64
+	encodedObjects int64
+	// timings accumulates the time spent per resource type.
+	timings map[schema.GroupVersionResource]*ResourceTiming
+}
+
+func newArchiveWriter(w io.Writer) *archiveWriter {
+	aw := &archiveWriter{
+		tw:               tar.NewWriter(w),
+		modTime:          time.Now(),
+		digests:          map[string]string{},
+		resourceVersions: map[string]string{},
+		timings:          map[schema.GroupVersionResource]*ResourceTiming{},
+	}
+	aw.enc = json.NewEncoder(&aw.buf)
+	aw.enc.SetIndent("", "  ")
+	return aw
+}
+
+// writeObject encodes obj and appends it to the archive as name. It is safe
+// to call from multiple goroutines.
+func (aw *archiveWriter) writeObject(name string, obj map[string]interface{}) error {
+	stored := obj
+	if aw.secrets != nil && isSecret(obj) {
+		var err error
+		if name, stored, err = aw.secrets.encryptSecret(name, obj); err != nil {
+			return err
+		}
+	}
+
+	aw.mu.Lock()
+	defer aw.mu.Unlock()
+
+	aw.buf.Reset()
+	if err := aw.enc.Encode(stored); err != nil {
+		return fmt.Errorf("failed to encode resource: %w", err)
+	}
+	if err := aw.writeEntryLocked(name, aw.buf.Bytes()); err != nil {
+		return err
+	}
+	aw.digests[name] = digest(aw.buf.Bytes())
+	aw.encodedBytes += int64(aw.buf.Len())
+	aw.encodedObjects++
+	if aw.sizes != nil {
+		aw.recordSizeLocked(name, stored, int64(aw.buf.Len()))
+	}
+	if err := aw.recordObjectLocked(name, obj, stored); err != nil {
+		return err
+	}
+
+	// Don't pin the memory of an unusually large object for the rest of the run
+	if aw.buf.Cap() > maxRetainedBufferSize {
+		aw.buf = bytes.Buffer{}
+	}
+	return nil
+}
+
+// recordObjectLocked collects what the archive keeps track of for every
+// object, whether written or referenced. stored is obj as archived, which
+// differs for encrypted Secrets; aw.mu must be held
+func (aw *archiveWriter) recordObjectLocked(name string, obj, stored map[string]interface{}) error {
+	if aw.references != nil {
+		if namespace := nestedString(obj, "metadata", "namespace"); namespace != "" {
+			for _, ref := range podSpecReferences(obj) {
+				aw.references[namespacedReference{namespace: namespace, objectReference: ref}] = struct{}{}
+			}
+		}
+	}
+	if release, ok := helmReleaseFromSecret(obj); ok {
+		aw.helmReleases = append(aw.helmReleases, release)
+	}
+	if aw.export != nil {
+		if err := aw.export.writeObject(name, stored); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeEntryLocked appends a regular file entry; aw.mu must be held
+func (aw *archiveWriter) writeEntryLocked(name string, data []byte) error {
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     int64(len(data)),
+		ModTime:  aw.modTime,
+	}
+	if err := aw.tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := aw.tw.Write(data)
+	return err
+}
+
+// Close appends the manifest and flushes the tar footer; it does not close
+// the underlying writer
+func (aw *archiveWriter) Close() error {
+	aw.mu.Lock()
+	defer aw.mu.Unlock()
+
+	sortHelmReleases(aw.helmReleases)
+	manifest := archiveManifest{
+		FormatVersion:    ArchiveFormatVersion,
+		CreatedAt:        aw.modTime.UTC(),
+		ResourceCount:    len(aw.digests),
+		RunID:            aw.runID,
+		Cluster:          aw.cluster,
+		Compression:      aw.compression,
+		Encryption:       aw.encryption,
+		HelmReleases:     aw.helmReleases,
+		Files:            aw.digests,
+		ResourceVersions: aw.resourceVersions,
+		SlowestResources: aw.manifestTimingsLocked(),
+	}
+	if aw.base != nil && aw.base.reused > 0 {
+		manifest.Base = aw.base.name
+	}
+	manifest.Partial = aw.partial
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := aw.writeEntryLocked(manifestName, data); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	aw.manifestDigest = digest(data)
+
+	return aw.tw.Close()
+}
+
+// objectCount returns the number of entries written so far.
+func (aw *archiveWriter) objectCount() int {
+	aw.mu.Lock()
+	defer aw.mu.Unlock()
+	return len(aw.digests)
+}
+
+// publishArchive moves a staged archive into the storage location, falling
+// back to a copy when the staging directory lives on another filesystem
+func (bm *BackupManager) publishArchive(stagingPath, storagePath string) (string, error) {
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return "", err
+	}
+
+	// Ensure storage directory exists
+	if err := os.MkdirAll(resolvedStoragePath, 0755); err != nil {
+		return "", fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	archivePath := filepath.Join(resolvedStoragePath, filepath.Base(stagingPath))
+	if err := os.Rename(stagingPath, archivePath); err == nil {
+		return archivePath, nil
+	}
+
+	return bm.copyArchive(stagingPath, storagePath)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open staged archive: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("failed to create archive file: %w", err)
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to copy archive: %w", err)
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to sync archive: %w", err)
+	}
+	return out.Close()
+}
+
+// CleanupArchives removes old archives based on retention days and max archives.
+// Pinned archives are skipped and do not count towards maxArchives. Archives
+// read by a restore are kept until a later run. Locked archives are kept;
+// they are listed in an ImmutableArchivesError once every other archive has
+// been processed. The storage location is listed once and the archives
+// selected are removed in parallel. It returns the archives removed, also
+// when it fails.
+func (bm *BackupManager) CleanupArchives(storagePath string, retentionDays *int, maxArchives *int) ([]string, error) {
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	entries, err := os.ReadDir(resolvedStoragePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read storage directory: %w", err)
+	}
+
+	// collect archive files with info; bases of incremental archives are
+	// kept like pinned ones
+	bases := referencedBases(resolvedStoragePath)
+	var files []os.DirEntry
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if _, ok := bases[e.Name()]; ok {
+			continue
+		}
+		if isArchiveName(e.Name()) && !isPinned(filepath.Join(resolvedStoragePath, e.Name())) {
+			files = append(files, e)
+		}
+	}
+
+	// sort by name (timestamp in name gives chronological order)
+	sort.Slice(files, func(i, j int) bool { return files[i].Name() < files[j].Name() })
+
+	// removable reports whether a selected archive may be removed now;
+	// locked ones are remembered for the ImmutableArchivesError
+	kept := map[string]struct{}{}
+	removable := func(name string) bool {
+		archivePath := filepath.Join(resolvedStoragePath, name)
+		if bm.isReferenced(archivePath, now) {
+			return false
+		}
+		if checkArchiveMutable(archivePath, now) != nil {
+			kept[name] = struct{}{}
+			return false
+		}
+		return true
+	}
+
+	// Apply retentionDays; the archives it leaves count towards maxArchives
+	var selected []string
+	remaining := files
+	if retentionDays != nil {
+		cutoff := now.Add(-time.Duration(*retentionDays) * 24 * time.Hour)
+		remaining = nil
+		for _, f := range files {
+			if fi, err := f.Info(); err == nil && fi.ModTime().Before(cutoff) && removable(f.Name()) {
+				selected = append(selected, f.Name())
+				continue
+			}
+			remaining = append(remaining, f)
+		}
+	}
+	if maxArchives != nil && len(remaining) > *maxArchives {
+		for _, f := range remaining[:len(remaining)-*maxArchives] {
+			if removable(f.Name()) {
+				selected = append(selected, f.Name())
+			}
+		}
+	}
+
+	removed, err := removeArchives(resolvedStoragePath, selected)
+	if err != nil {
+		return removed, err
+	}
+
+	if len(kept) > 0 {
+		archives := make([]string, 0, len(kept))
+		for name := range kept {
+			archives = append(archives, name)
+		}
+		sort.Strings(archives)
+		return removed, &ImmutableArchivesError{Archives: archives}
+	}
+	return removed, nil
+}
+
+// removeArchives deletes the named archives of dir, archiveDeleteWorkers at
+// a time, and drops them from the catalog in a single update. Every archive
+// is attempted; it returns those removed, in name order, and the failures.
+func removeArchives(dir string, names []string) ([]string, error) {
+	done := make([]bool, len(names))
+	errs := make([]error, len(names))
+	group := &errgroup.Group{}
+	group.SetLimit(archiveDeleteWorkers)
+	for i, name := range names {
+		group.Go(func() error {
+			if err := removeArchiveFiles(filepath.Join(dir, name)); err != nil {
+				errs[i] = fmt.Errorf("failed to remove archive %q: %w", name, err)
+				return nil
+			}
+			done[i] = true
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	var removed []string
+	for i, name := range names {
+		if done[i] {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+	if err := uncatalogArchives(dir, removed...); err != nil {
+		errs = append(errs, fmt.Errorf("failed to update catalog: %w", err))
+	}
+	return removed, errors.Join(errs...)
+}
+
+func makeStringSet(values []string, normalize func(string) string) map[string]struct{} {
+	if len(values) == 0 {
+		return nil
+	}
+	set := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		if normalize != nil {
+			value = normalize(value)
+		}
+		if value == "" {
+			continue
+		}
+		set[value] = struct{}{}
+	}
+	return set
+}
+
+// contains checks if a slice contains a string
+func contains(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDefaultResourceTypes returns a list of common Kubernetes resource types to backup
+func GetDefaultResourceTypes() []string {
+	return []string{
+		// Core resources
+		"Namespace",
+		"ConfigMap",
+		"Secret",
+		"Service",
+		"ServiceAccount",
+		"PersistentVolume",
+		"PersistentVolumeClaim",
+		"Pod",
+
+		// Workloads
+		"Deployment",
+		"StatefulSet",
+		"DaemonSet",
+		"ReplicaSet",
+		"Job",
+		"CronJob",
+
+		// Networking
+		"Ingress",
+		"NetworkPolicy",
+		"Endpoints",
+
+		// RBAC
+		"Role",
+		"RoleBinding",
+		"ClusterRole",
+		"ClusterRoleBinding",
+
+		// Storage
+		"StorageClass",
+
+		// Custom Resources (will attempt to backup all CRDs)
+		"CustomResourceDefinition",
+	}
+}
+
+// SetCondition updates or adds a condition to the status
+func SetCondition(conditions *[]metav1.Condition, conditionType string, status metav1.ConditionStatus, reason, message string) {
+	now := metav1.Now()
+	condition := metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		LastTransitionTime: now,
+		Reason:             reason,
+		Message:            message,
+	}
+
+	meta.SetStatusCondition(conditions, condition)
+}
@@ -0,0 +1,666 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestCleanupArchivesRetentionAndMax(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	bm := &BackupManager{}
+
+	createArchiveFile(t, dir, "cluster-backup-20240101-000000.tar.gz", 48*time.Hour)
+	createArchiveFile(t, dir, "cluster-backup-20250101-010000.tar.gz", 2*time.Hour)
+	createArchiveFile(t, dir, "cluster-backup-20250102-010000.tar.gz", time.Hour)
+	createArchiveFile(t, dir, "cluster-backup-20250103-010000.tar.gz", 0)
+
+	retention := 1
+	maxArchives := 2
+
+	removed, err := bm.CleanupArchives(dir, &retention, &maxArchives)
+	if err != nil {
+		t.Fatalf("CleanupArchives returned error: %v", err)
+	}
+	if want := []string{"cluster-backup-20240101-000000.tar.gz", "cluster-backup-20250101-010000.tar.gz"}; !slices.Equal(removed, want) {
+		t.Fatalf("CleanupArchives removed %v, want %v", removed, want)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+
+	expected := []string{
+		"cluster-backup-20250102-010000.tar.gz",
+		"cluster-backup-20250103-010000.tar.gz",
+	}
+
+	if len(names) != len(expected) {
+		t.Fatalf("expected %d archives, got %d (%v)", len(expected), len(names), names)
+	}
+	for i, name := range expected {
+		if names[i] != name {
+			t.Fatalf("expected archive %q at position %d, got %q", name, i, names[i])
+		}
+	}
+}
+
+func TestCleanupArchivesMissingDirectory(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "missing")
+	bm := &BackupManager{}
+
+	if _, err := bm.CleanupArchives(path, nil, nil); err != nil {
+		t.Fatalf("expected no error for missing directory, got %v", err)
+	}
+}
+
+func TestCleanupArchivesRemovesManyArchives(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	bm := &BackupManager{}
+	var names []string
+	for i := 0; i < 40; i++ {
+		name := fmt.Sprintf("cluster-backup-20250101-%06d.tar.gz", i)
+		createArchiveFile(t, dir, name, time.Duration(40-i)*time.Hour)
+		names = append(names, name)
+	}
+	if _, err := bm.RebuildCatalog(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// Retention removes the 16 archives older than a day, maxArchives 20 more
+	retention := 1
+	maxArchives := 4
+	removed, err := bm.CleanupArchives(dir, &retention, &maxArchives)
+	if err != nil {
+		t.Fatalf("CleanupArchives returned error: %v", err)
+	}
+	if !slices.Equal(removed, names[:36]) {
+		t.Fatalf("CleanupArchives removed %v, want %v", removed, names[:36])
+	}
+	listed, err := bm.ListArchives(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	catalog, err := bm.ReadCatalog(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cataloged []string
+	for _, entry := range catalog.Archives {
+		cataloged = append(cataloged, entry.Name)
+	}
+	if !slices.Equal(listed, names[36:]) || !slices.Equal(cataloged, names[36:]) {
+		t.Fatalf("archives left = %v, cataloged %v, want %v", listed, cataloged, names[36:])
+	}
+}
+
+func TestDiscardArchiveRemovesStoredCopies(t *testing.T) {
+	t.Parallel()
+
+	const name = "cluster-backup-20250101-000000.tar.gz"
+	primary, replica := t.TempDir(), t.TempDir()
+	result := &BackupResult{
+		FilePath: filepath.Join(primary, name),
+		Replicas: []ReplicaResult{
+			{StoragePath: replica, FilePath: filepath.Join(replica, name)},
+			{StoragePath: "unreachable", Error: errors.New("copy failed")},
+		},
+	}
+	for _, path := range []string{result.FilePath, result.Replicas[0].FilePath} {
+		if err := os.WriteFile(path, []byte("archive"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := tagArchive(path, RotationDaily); err != nil {
+			t.Fatal(err)
+		}
+		if err := catalogArchive(path, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := lockArchive(result.FilePath, Immutability{RetainFor: time.Hour}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	exportErr := errors.New("push rejected")
+	if err := discardArchive(result, exportErr); err != exportErr {
+		t.Fatalf("discardArchive error = %v, want %v", err, exportErr)
+	}
+	for _, dir := range []string{primary, replica} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, entry := range entries {
+			if entry.Name() != CatalogName {
+				t.Errorf("%s left behind in %s", entry.Name(), dir)
+			}
+		}
+		catalog, err := readCatalog(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := catalog.Entry(name); ok {
+			t.Errorf("%s still catalogued in %s", name, dir)
+		}
+	}
+}
+
+func TestProbeStorage(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "nested")
+	bm := &BackupManager{}
+
+	if err := bm.ProbeStorage(dir); err != nil {
+		t.Fatalf("ProbeStorage returned error: %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected probe object to be removed, found %d entries", len(entries))
+	}
+
+	// A regular file in place of the directory can never be written to
+	blocked := filepath.Join(t.TempDir(), "blocked")
+	if err := os.WriteFile(blocked, nil, 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := bm.ProbeStorage(blocked); err == nil {
+		t.Fatalf("expected ProbeStorage to fail for %s", blocked)
+	}
+}
+
+func TestValidateStoragePath(t *testing.T) {
+	t.Parallel()
+
+	valid := []string{"/var/lib/backups", "host:///tmp/backups", "host://", "/var/lib/backups/{{.ClusterName}}/{{.Namespace}}/{{.BackupName}}"}
+	for _, storagePath := range valid {
+		if err := ValidateStoragePath(storagePath); err != nil {
+			t.Errorf("expected %q to be valid, got %v", storagePath, err)
+		}
+	}
+
+	invalid := []string{"", "relative/dir", "s3://bucket/path", "ftp://host/dir", "/var/lib/backups/{{.Region}}", "/var/lib/backups/{{.Date}}", "/var/lib/backups/{{"}
+	for _, storagePath := range invalid {
+		if err := ValidateStoragePath(storagePath); err == nil {
+			t.Errorf("expected %q to be rejected", storagePath)
+		}
+	}
+}
+
+func TestExpandStoragePath(t *testing.T) {
+	t.Parallel()
+
+	vars := StoragePathVars{ClusterName: "prod-eu", Namespace: "payments", BackupName: "nightly"}
+	got, err := ExpandStoragePath("host:///var/lib/backups/{{.ClusterName}}/{{.Namespace}}/{{.BackupName}}", vars)
+	if err != nil || got != "host:///var/lib/backups/prod-eu/payments/nightly" {
+		t.Fatalf("expected the expanded path, got %q (%v)", got, err)
+	}
+
+	if got, err := ExpandStoragePath("/var/lib/backups", StoragePathVars{}); err != nil || got != "/var/lib/backups" {
+		t.Fatalf("expected a plain path to be kept, got %q (%v)", got, err)
+	}
+
+	if _, err := ExpandStoragePath("/var/lib/backups/{{.ClusterName}}", StoragePathVars{Namespace: "payments"}); err == nil {
+		t.Fatal("expected an unset variable to be rejected")
+	}
+}
+
+func TestResolveStoragePath(t *testing.T) {
+	t.Parallel()
+
+	bm := &BackupManager{HostStorage: HostStorage{Paths: []string{"/var/backups", "/srv/archive/"}}}
+
+	if got, err := bm.resolveStoragePath("/var/backups"); err != nil || got != "/var/backups" {
+		t.Fatalf("expected %q, got %q (%v)", "/var/backups", got, err)
+	}
+
+	if got, err := bm.resolveStoragePath("host:///var/backups/edge"); err != nil || got != filepath.Join("/host", "var", "backups", "edge") {
+		t.Fatalf("expected the mounted node directory, got %q (%v)", got, err)
+	}
+
+	if got, err := bm.resolveStoragePath("host://srv/archive"); err != nil || got != filepath.Join("/host", "srv", "archive") {
+		t.Fatalf("expected the mounted node directory, got %q (%v)", got, err)
+	}
+
+	for _, storagePath := range []string{"host:///var/backups-other", "host:///var/backups/../../etc", "host:///tmp", "host://"} {
+		if got, err := bm.resolveStoragePath(storagePath); err == nil {
+			t.Fatalf("expected %q outside the mounted paths to be rejected, got %q", storagePath, got)
+		}
+	}
+
+	bm.HostStorage.Root = "/mnt/node"
+	if got, err := bm.resolveStoragePath("host:///var/backups"); err != nil || got != filepath.Join("/mnt/node", "var", "backups") {
+		t.Fatalf("expected the configured root, got %q (%v)", got, err)
+	}
+
+	bm.HostStorage.LegacyTmp = "/host-tmp"
+	for storagePath, want := range map[string]string{
+		"host:///tmp":                 "/host-tmp",
+		"host:///tmp/prod":            "/host-tmp/prod",
+		"host:///prod":                "/host-tmp/prod",
+		"host:///tmpfs/prod":          "/host-tmp/fs/prod",
+		"host:///var/backups/edge":    filepath.Join("/mnt/node", "var", "backups", "edge"),
+		"host:///var/backups/../../x": "/host-tmp/x",
+	} {
+		if got, err := bm.resolveStoragePath(storagePath); err != nil || got != want {
+			t.Fatalf("expected %q to resolve to %q, got %q (%v)", storagePath, want, got, err)
+		}
+	}
+}
+
+func TestGetNamespacesToBackupExcludes(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed adding corev1 to scheme: %v", err)
+	}
+
+	objects := []runtime.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "custom"}},
+	}
+
+	dynamicClient := fake.NewSimpleDynamicClient(scheme, objects...)
+	bm := &BackupManager{DynamicClient: dynamicClient}
+
+	opts := BackupOptions{ExcludeNamespaces: []string{"kube-system"}}
+	namespaces, err := bm.getNamespacesToBackup(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("getNamespacesToBackup returned error: %v", err)
+	}
+
+	if len(namespaces) != 2 {
+		t.Fatalf("expected 2 namespaces, got %d (%v)", len(namespaces), namespaces)
+	}
+
+	got := make(map[string]struct{})
+	for _, ns := range namespaces {
+		got[ns] = struct{}{}
+	}
+
+	for _, want := range []string{"custom", "default"} {
+		if _, ok := got[want]; !ok {
+			t.Fatalf("expected namespace %q to be present (got %v)", want, namespaces)
+		}
+	}
+}
+
+func TestWriteResourcePagesStreamsAllPages(t *testing.T) {
+	t.Parallel()
+
+	const total = 1234
+
+	var pages int
+	listPage := func(_ context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		if opts.Limit != listPageSize {
+			return nil, fmt.Errorf("expected page limit %d, got %d", listPageSize, opts.Limit)
+		}
+		pages++
+		return generateConfigMapPage(opts, total, "data")
+	}
+
+	var out bytes.Buffer
+	archive := newArchiveWriter(&out)
+	count, err := writeResourcePages(context.Background(), archive, "namespaces/bench/v1/configmaps", listPage)
+	if err != nil {
+		t.Fatalf("writeResourcePages returned error: %v", err)
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("failed closing archive: %v", err)
+	}
+
+	if count != total {
+		t.Fatalf("expected %d resources written, got %d", total, count)
+	}
+	if want := (total + listPageSize - 1) / listPageSize; pages != want {
+		t.Fatalf("expected %d pages to be requested, got %d", want, pages)
+	}
+
+	tarReader := tar.NewReader(&out)
+	entries := 0
+	var manifest *archiveManifest
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed reading archive: %v", err)
+		}
+
+		if header.Name == manifestName {
+			manifest = &archiveManifest{}
+			if err := json.NewDecoder(tarReader).Decode(manifest); err != nil {
+				t.Fatalf("failed decoding manifest: %v", err)
+			}
+			continue
+		}
+		if manifest != nil {
+			t.Fatalf("expected the manifest to be the last entry, found %s after it", header.Name)
+		}
+
+		var obj map[string]interface{}
+		if err := json.NewDecoder(tarReader).Decode(&obj); err != nil {
+			t.Fatalf("failed decoding %s: %v", header.Name, err)
+		}
+		if _, found := obj["metadata"].(map[string]interface{})["resourceVersion"]; found {
+			t.Fatalf("expected resourceVersion to be stripped from %s", header.Name)
+		}
+		entries++
+	}
+
+	if entries != total {
+		t.Fatalf("expected %d archive entries, got %d", total, entries)
+	}
+	if manifest == nil {
+		t.Fatalf("expected archive to contain %s", manifestName)
+	}
+	if manifest.ResourceCount != total || len(manifest.Files) != total {
+		t.Fatalf("expected manifest to describe %d entries, got count %d with %d digests",
+			total, manifest.ResourceCount, len(manifest.Files))
+	}
+}
+
+func TestArchiveManifestRecordsRunID(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	archive := newArchiveWriter(&out)
+	archive.runID = "0b7e6a4c-5f0e-4c1e-9a55-2f1d8c3e7a10"
+	if err := archive.Close(); err != nil {
+		t.Fatalf("failed closing archive: %v", err)
+	}
+	if manifest := readTestManifest(t, &out); manifest.RunID != archive.runID {
+		t.Fatalf("manifest runID = %q, want %q", manifest.RunID, archive.runID)
+	}
+}
+
+func TestDiscoverResourcesClusterResourceFilters(t *testing.T) {
+	t.Parallel()
+
+	bm := &BackupManager{DiscoveryClient: preferredResources{lists: []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{
+			{Name: "namespaces", Kind: "Namespace", Verbs: []string{"list"}},
+			{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"list"}},
+			{Name: "nodes", Kind: "Node", Verbs: []string{"list"}},
+		}},
+		{GroupVersion: "storage.k8s.io/v1", APIResources: []metav1.APIResource{
+			{Name: "storageclasses", Kind: "StorageClass", Verbs: []string{"list"}},
+		}},
+		{GroupVersion: "rbac.authorization.k8s.io/v1", APIResources: []metav1.APIResource{
+			{Name: "clusterroles", Kind: "ClusterRole", Verbs: []string{"list"}},
+		}},
+	}}}
+	resources := func(opts BackupOptions) []string {
+		var names []string
+		for _, target := range bm.discoverResources(context.Background(), opts) {
+			names = append(names, target.gvr.Resource)
+		}
+		return names
+	}
+
+	// The include list replaces resourceTypes for cluster-scoped kinds only
+	got := resources(BackupOptions{
+		IncludeClusterResources:     true,
+		ResourceTypes:               []string{"Namespace", "ConfigMap", "Node"},
+		IncludeClusterResourceTypes: []string{"StorageClass", "clusterrole"},
+	})
+	if want := []string{"namespaces", "configmaps", "storageclasses", "clusterroles"}; !slices.Equal(got, want) {
+		t.Errorf("resources with include = %v, want %v", got, want)
+	}
+
+	got = resources(BackupOptions{
+		IncludeClusterResources:     true,
+		ExcludeClusterResourceTypes: []string{"Node", "ClusterRole"},
+	})
+	if want := []string{"namespaces", "configmaps", "storageclasses"}; !slices.Equal(got, want) {
+		t.Errorf("resources with exclude = %v, want %v", got, want)
+	}
+
+	got = resources(BackupOptions{
+		IncludeClusterResources:     true,
+		ResourceTypes:               []string{"ConfigMap"},
+		IncludeClusterResourceTypes: []string{"*.k8s.io"},
+	})
+	if want := []string{"configmaps", "storageclasses", "clusterroles"}; !slices.Equal(got, want) {
+		t.Errorf("resources with a group wildcard = %v, want %v", got, want)
+	}
+
+	got = resources(BackupOptions{IncludeClusterResourceTypes: []string{"StorageClass"}})
+	if want := []string{"configmaps"}; !slices.Equal(got, want) {
+		t.Errorf("resources without cluster resources = %v, want %v", got, want)
+	}
+}
+
+func TestWriteArchiveKeepsPartialArchiveWhenInterrupted(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	newManager := func(cancel context.CancelFunc) *BackupManager {
+		dynamicClient := fake.NewSimpleDynamicClient(scheme,
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "first", Namespace: "payments"}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "second", Namespace: "payments"}},
+			&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "payments"}},
+		)
+		// The operator shuts down while the services are listed
+		dynamicClient.PrependReactor("list", "services", func(clienttesting.Action) (bool, runtime.Object, error) {
+			cancel()
+			return true, nil, context.Canceled
+		})
+		return &BackupManager{
+			DynamicClient: dynamicClient,
+			DiscoveryClient: preferredResources{lists: []*metav1.APIResourceList{
+				{GroupVersion: "v1", APIResources: []metav1.APIResource{
+					{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"list"}},
+					{Name: "services", Kind: "Service", Namespaced: true, Verbs: []string{"list"}},
+				}},
+			}},
+		}
+	}
+	opts := BackupOptions{
+		IncludeNamespaces:       []string{"payments"},
+		ResourceTypes:           []string{"ConfigMap", "Service"},
+		ConcurrentResourceTypes: 1,
+		Compression:             CompressionNone,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	partial := false
+	partialOpts := opts
+	partialOpts.partial = &partial
+	// Passing sizes leaves out the cluster identity, which the fake discovery cannot serve
+	sizes := map[schema.GroupVersionResource]*ResourceEstimate{}
+	var out bytes.Buffer
+	count, err := newManager(cancel).writeArchive(ctx, &out, nil, sizes, partialOpts)
+	if err != nil {
+		t.Fatalf("writeArchive() error = %v", err)
+	}
+	if !partial || count != 2 {
+		t.Fatalf("writeArchive() = %d resources, partial %v, want the 2 configmaps collected before the interruption", count, partial)
+	}
+	if manifest := readTestManifest(t, &out); !manifest.Partial || manifest.ResourceCount != 2 {
+		t.Fatalf("manifest = %+v, want a partial archive of 2 resources", manifest)
+	}
+
+	// Without PartialOnCancel an interrupted collection fails
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	out.Reset()
+	if _, err := newManager(cancel).writeArchive(ctx, &out, nil, sizes, opts); !errors.Is(err, context.Canceled) {
+		t.Fatalf("writeArchive() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestDefaultConcurrency(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		items, perWorker, limit, want int
+	}{
+		{items: 0, perWorker: 8, limit: 4, want: 1},
+		{items: 7, perWorker: 8, limit: 4, want: 1},
+		{items: 24, perWorker: 8, limit: 4, want: 3},
+		{items: 500, perWorker: 8, limit: 4, want: 4},
+	}
+
+	for _, tc := range cases {
+		if got := defaultConcurrency(tc.items, tc.perWorker, tc.limit); got != tc.want {
+			t.Fatalf("defaultConcurrency(%d, %d, %d) = %d, want %d", tc.items, tc.perWorker, tc.limit, got, tc.want)
+		}
+	}
+}
+
+// BenchmarkWriteResourcePagesLargeNamespace archives a 100k object namespace.
+// Allocations per op scale with the object count, but the live heap only
+// holds the pager's page buffer and the manifest's digest of each object,
+// never the list: it is sampled every few pages and must stay below
+// maxRetainedBytes, a fraction of the list's size.
+func BenchmarkWriteResourcePagesLargeNamespace(b *testing.B) {
+	const (
+		total            = 100000
+		maxRetainedBytes = 64 << 20
+		samplePages      = 20
+	)
+
+	payload := strings.Repeat("x", 512)
+	var baseline, retained uint64
+	pages := 0
+	listPage := func(_ context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		if pages++; pages%samplePages == 0 {
+			b.StopTimer()
+			if live := liveHeapBytes(); live > baseline {
+				retained = max(retained, live-baseline)
+			}
+			b.StartTimer()
+		}
+		return generateConfigMapPage(opts, total, payload)
+	}
+
+	b.ReportAllocs()
+	baseline = liveHeapBytes()
+	for i := 0; i < b.N; i++ {
+		archive := newArchiveWriter(gzip.NewWriter(io.Discard))
+		count, err := writeResourcePages(context.Background(), archive, "namespaces/bench/v1/configmaps", listPage)
+		if err != nil {
+			b.Fatalf("writeResourcePages returned error: %v", err)
+		}
+		if count != total {
+			b.Fatalf("expected %d resources, got %d", total, count)
+		}
+	}
+	b.ReportMetric(float64(total), "objects/op")
+	b.ReportMetric(float64(retained), "peak-retained-B")
+	if retained > maxRetainedBytes {
+		b.Fatalf("retained %d bytes while archiving %d objects, want at most %d", retained, total, maxRetainedBytes)
+	}
+}
+
+// liveHeapBytes returns the bytes of live heap objects after a collection.
+func liveHeapBytes() uint64 {
+	goruntime.GC()
+	var stats goruntime.MemStats
+	goruntime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+// generateConfigMapPage serves one page of a synthetic ConfigMap list,
+// honouring the limit and continue token in opts.
+func generateConfigMapPage(opts metav1.ListOptions, total int, payload string) (runtime.Object, error) {
+	start := 0
+	if opts.Continue != "" {
+		var err error
+		if start, err = strconv.Atoi(opts.Continue); err != nil {
+			return nil, err
+		}
+	}
+
+	end := total
+	if opts.Limit > 0 && start+int(opts.Limit) < total {
+		end = start + int(opts.Limit)
+	}
+
+	list := &unstructured.UnstructuredList{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMapList",
+	}}
+	list.Items = make([]unstructured.Unstructured, 0, end-start)
+	for i := start; i < end; i++ {
+		list.Items = append(list.Items, unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":            fmt.Sprintf("config-%06d", i),
+				"namespace":       "bench",
+				"resourceVersion": strconv.Itoa(i),
+			},
+			"data": map[string]interface{}{
+				"payload": payload,
+			},
+		}})
+	}
+	if end < total {
+		list.SetContinue(strconv.Itoa(end))
+	}
+
+	return list, nil
+}
+
+func createArchiveFile(t *testing.T, dir, name string, age time.Duration) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("test"), 0o644); err != nil {
+		t.Fatalf("failed writing archive %s: %v", name, err)
+	}
+
+	modTime := time.Now().Add(-age)
+	if err := os.Chtimes(filepath.Join(dir, name), modTime, modTime); err != nil {
+		t.Fatalf("failed setting modtime for %s: %v", name, err)
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// AuditLogName is the append-only audit log kept in each storage location.
+const AuditLogName = "audit.jsonl"
+
+// Audited operations.
+const (
+	AuditOperationBackup  = "backup"
+	AuditOperationRestore = "restore"
+	AuditOperationDelete  = "delete"
+	AuditOperationCreate  = "create"
+	AuditOperationUpdate  = "update"
+)
+
+// AuditRecord is one line of the audit log.
+type AuditRecord struct {
+	Time time.Time `json:"time"`
+	// Operation is one of the AuditOperation constants.
+	Operation string `json:"operation"`
+	// Resource is the object the operation was run for, as
+	// "Kind/namespace/name".
+	Resource string `json:"resource"`
+	// User is the requesting user, known for operations recorded at
+	// admission, or the identity a backup or restore impersonated.
+	User string `json:"user,omitempty"`
+	// RunID identifies the backup or restore run.
+	RunID   string `json:"runID,omitempty"`
+	Archive string `json:"archive,omitempty"`
+	// Result is "success", "partial", "failure" or, at admission,
+	// "admitted".
+	Result  string `json:"result"`
+	Message string `json:"message,omitempty"`
+}
+
+// AppendAuditRecord appends record as a JSON line to the audit log in
+// storagePath. The log is only ever appended to; existing lines are never
+// rewritten. A zero Time is set to now.
+func (bm *BackupManager) AppendAuditRecord(storagePath string, record AuditRecord) error {
+	if record.Time.IsZero() {
+		record.Time = time.Now()
+	}
+	record.Time = record.Time.UTC()
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(resolvedStoragePath, 0755); err != nil {
+		return fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	bm.auditMu.Lock()
+	defer bm.auditMu.Unlock()
+	f, err := os.OpenFile(filepath.Join(resolvedStoragePath, AuditLogName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	// A single write keeps each record on its own line
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to append audit record: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to sync audit log: %w", err)
+	}
+	return f.Close()
+}
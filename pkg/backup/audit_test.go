@@ -0,0 +1,53 @@
+package backup
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAppendAuditRecordAppendsJSONLines(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	bm := &BackupManager{}
+	records := []AuditRecord{
+		{Operation: AuditOperationBackup, Resource: "ClusterBackup/default/nightly", RunID: "run-1",
+			Archive: "cluster-backup-20250101-000000.tar.gz", Result: "success"},
+		{Operation: AuditOperationDelete, Resource: "ClusterBackup/default/nightly",
+			Archive: "cluster-backup-20240101-000000.tar.gz", Result: "success"},
+	}
+	for _, record := range records {
+		if err := bm.AppendAuditRecord(dir, record); err != nil {
+			t.Fatalf("AppendAuditRecord: %v", err)
+		}
+	}
+
+	f, err := os.Open(filepath.Join(dir, AuditLogName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var got []AuditRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("line %q is not a record: %v", scanner.Text(), err)
+		}
+		got = append(got, record)
+	}
+	if len(got) != len(records) {
+		t.Fatalf("got %d records, want %d", len(got), len(records))
+	}
+	for i, record := range got {
+		if record.Time.IsZero() {
+			t.Errorf("record %d has no time", i)
+		}
+		if record.Operation != records[i].Operation || record.Archive != records[i].Archive {
+			t.Errorf("record %d = %+v, want %+v", i, record, records[i])
+		}
+	}
+}
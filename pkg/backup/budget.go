@@ -0,0 +1,186 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/semaphore"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/pager"
+)
+
+const (
+	// defaultObjectSize is the decoded size assumed for a listed object
+	// until the archive has encoded minEstimateObjects of them.
+	defaultObjectSize  = 16 << 10
+	minEstimateObjects = 16
+
+	// decodedSizeFactor scales the encoded size of objects to the space
+	// their decoded unstructured form takes.
+	decodedSizeFactor = 4
+)
+
+// memoryBudget bounds the estimated bytes of listed objects held in memory
+// across every concurrent List call of the manager.
+type memoryBudget struct {
+	sem  *semaphore.Weighted
+	size int64
+}
+
+// SetMemoryBudget bounds the memory the listed objects of all running
+// backups may take to roughly bytes, by holding back List calls until
+// earlier pages have been archived. Zero or less removes the bound. It must
+// be called before the manager is used.
+func (bm *BackupManager) SetMemoryBudget(bytes int64) {
+	if bytes <= 0 {
+		bm.memoryBudget = nil
+		return
+	}
+	bm.memoryBudget = &memoryBudget{sem: semaphore.NewWeighted(bytes), size: bytes}
+}
+
+// acquire reserves n bytes, or the whole budget when n exceeds it so a
+// single large page can still proceed on its own
+func (b *memoryBudget) acquire(ctx context.Context, n int64) (int64, error) {
+	n = min(max(n, 1), b.size)
+	if err := b.sem.Acquire(ctx, n); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// pageReservation is the budget held for the not yet archived items of a
+// listed page.
+type pageReservation struct {
+	items int
+	bytes int64
+}
+
+// pageBudget paces the List calls of writeResourcePages and holds a budget
+// reservation for every page until its items have been archived.
+type pageBudget struct {
+	archive  *archiveWriter
+	pageSize int64
+
+	mu      sync.Mutex
+	pending []pageReservation
+	listed  bool
+	// closed is set once writeResourcePages returned, so pages a
+	// cancelled background List still delivers are not held on to.
+	closed bool
+	// inflight tracks the List calls release waits for.
+	inflight sync.WaitGroup
+}
+
+// wrap returns listPage waiting for the archive's page interval between
+// calls and for room in its memory budget before each call
+func (pb *pageBudget) wrap(listPage pager.ListPageFunc) pager.ListPageFunc {
+	return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		pb.mu.Lock()
+		if pb.closed {
+			pb.mu.Unlock()
+			return nil, context.Canceled
+		}
+		pb.inflight.Add(1)
+		defer pb.inflight.Done()
+		paced := pb.listed
+		pb.listed = true
+		pb.mu.Unlock()
+		if paced && pb.archive.pageInterval > 0 {
+			timer := time.NewTimer(pb.archive.pageInterval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		budget := pb.archive.memoryBudget
+		if budget == nil {
+			return listPage(ctx, opts)
+		}
+		reserved, err := budget.acquire(ctx, pb.pageSize*pb.archive.objectSizeEstimate())
+		if err != nil {
+			return nil, err
+		}
+		list, err := listPage(ctx, opts)
+		items := 0
+		if err == nil {
+			items = meta.LenList(list)
+		}
+		pb.mu.Lock()
+		defer pb.mu.Unlock()
+		if items == 0 || pb.closed {
+			budget.sem.Release(reserved)
+			return list, err
+		}
+		pb.pending = append(pb.pending, pageReservation{items: items, bytes: reserved})
+		return list, nil
+	}
+}
+
+// done records that an item of the oldest pending page was handled,
+// releasing the page's reservation after its last item
+func (pb *pageBudget) done() {
+	if pb.archive.memoryBudget == nil {
+		return
+	}
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	if len(pb.pending) == 0 {
+		return
+	}
+	pb.pending[0].items--
+	if pb.pending[0].items == 0 {
+		pb.archive.memoryBudget.sem.Release(pb.pending[0].bytes)
+		pb.pending = pb.pending[1:]
+	}
+}
+
+// release returns every reservation still held, e.g. after an error. The
+// pager has cancelled its background List by then, so waiting for it is
+// brief.
+func (pb *pageBudget) release() {
+	pb.mu.Lock()
+	pb.closed = true
+	pb.mu.Unlock()
+	pb.inflight.Wait()
+
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	for _, page := range pb.pending {
+		pb.archive.memoryBudget.sem.Release(page.bytes)
+	}
+	pb.pending = nil
+}
+
+// objectSizeEstimate returns the decoded size assumed for each listed
+// object, derived from the average encoded size of the objects written
+func (aw *archiveWriter) objectSizeEstimate() int64 {
+	aw.mu.Lock()
+	defer aw.mu.Unlock()
+	if aw.encodedObjects < minEstimateObjects {
+		return defaultObjectSize
+	}
+	return aw.encodedBytes / aw.encodedObjects * decodedSizeFactor
+}
@@ -0,0 +1,87 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestWriteResourcePagesMemoryBudget(t *testing.T) {
+	t.Parallel()
+
+	const total = 95
+
+	bm := &BackupManager{}
+	bm.SetMemoryBudget(1024)
+
+	archive := newArchiveWriter(io.Discard)
+	archive.memoryBudget = bm.memoryBudget
+	archive.pageSize = 10
+	archive.pageInterval = time.Millisecond
+
+	var pages int
+	var last time.Time
+	listPage := func(_ context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		if opts.Limit != 10 {
+			return nil, fmt.Errorf("expected page limit 10, got %d", opts.Limit)
+		}
+		// Every page reserves the whole budget, so the previous page must
+		// have been archived before the next one is listed
+		archive.mu.Lock()
+		written := archive.encodedObjects
+		archive.mu.Unlock()
+		if written != int64(pages*10) {
+			return nil, fmt.Errorf("page %d listed with %d of %d objects archived", pages+1, written, pages*10)
+		}
+		if pages > 0 && time.Since(last) < time.Millisecond {
+			return nil, fmt.Errorf("page %d listed %v after the previous one", pages+1, time.Since(last))
+		}
+		pages++
+		last = time.Now()
+		return generateConfigMapPage(opts, total, "data")
+	}
+
+	count, err := writeResourcePages(context.Background(), archive, "namespaces/bench/v1/configmaps", listPage)
+	if err != nil {
+		t.Fatalf("writeResourcePages returned error: %v", err)
+	}
+	if count != total {
+		t.Fatalf("expected %d resources written, got %d", total, count)
+	}
+	if pages != 10 {
+		t.Fatalf("expected 10 pages to be requested, got %d", pages)
+	}
+	if !bm.memoryBudget.sem.TryAcquire(1024) {
+		t.Fatal("budget reservations were not released")
+	}
+}
+
+func TestWriteResourcePagesReleasesBudgetOnError(t *testing.T) {
+	t.Parallel()
+
+	bm := &BackupManager{}
+	bm.SetMemoryBudget(1 << 20)
+
+	archive := newArchiveWriter(io.Discard)
+	archive.memoryBudget = bm.memoryBudget
+	archive.pageSize = 10
+
+	listPage := func(_ context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return generateConfigMapPage(opts, 100, "data")
+	}
+	// A write to a closed tar stream fails on the first item
+	if err := archive.Close(); err != nil {
+		t.Fatalf("failed closing archive: %v", err)
+	}
+	if _, err := writeResourcePages(context.Background(), archive, "namespaces/bench/v1/configmaps", listPage); err == nil {
+		t.Fatal("expected writing to a closed archive to fail")
+	}
+	if !bm.memoryBudget.sem.TryAcquire(1 << 20) {
+		t.Fatal("budget reservations were not released after an error")
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// CalloutFailurePolicy decides what happens to an object when its restore
+// call-out fails.
+type CalloutFailurePolicy string
+
+const (
+	// CalloutFailurePolicyFail fails the object.
+	CalloutFailurePolicyFail CalloutFailurePolicy = "Fail"
+	// CalloutFailurePolicyIgnore applies the object as if the call-out was
+	// not configured.
+	CalloutFailurePolicyIgnore CalloutFailurePolicy = "Ignore"
+)
+
+// DefaultCalloutTimeout bounds each call of a RestoreCallout without a
+// Timeout.
+const DefaultCalloutTimeout = 10 * time.Second
+
+// maxCalloutResponseBytes bounds the answer of a call-out, comfortably above
+// the largest object the apiserver stores.
+const maxCalloutResponseBytes = 8 << 20
+
+// RestoreCallout is an HTTP endpoint that receives every archived object as
+// a CalloutRequest before a restore applies it, and answers with a
+// CalloutResponse.
+type RestoreCallout struct {
+	// Name identifies the call-out in logs and errors.
+	Name string
+	URL  string
+	// Resources limits the call-out to these group-qualified resources,
+	// e.g. "deployments.apps". Empty sends every object.
+	Resources []string
+	// Timeout bounds each call. Zero uses DefaultCalloutTimeout.
+	Timeout time.Duration
+	// FailurePolicy defaults to CalloutFailurePolicyFail when empty.
+	FailurePolicy CalloutFailurePolicy
+}
+
+// CalloutRequest is the JSON body posted to a RestoreCallout.
+type CalloutRequest struct {
+	// Resource is the group-qualified resource, e.g. "deployments.apps".
+	Resource  string                 `json:"resource"`
+	Namespace string                 `json:"namespace,omitempty"`
+	Name      string                 `json:"name"`
+	Object    map[string]interface{} `json:"object"`
+}
+
+// CalloutResponse is the JSON answer of a RestoreCallout. An empty answer
+// applies the object unchanged.
+type CalloutResponse struct {
+	// Skip leaves the object out of the restore.
+	Skip bool `json:"skip,omitempty"`
+	// Object replaces the archived object. Its apiVersion, kind, name and
+	// namespace must not change.
+	Object map[string]interface{} `json:"object,omitempty"`
+	// Message explains the decision in the operator logs.
+	Message string `json:"message,omitempty"`
+}
+
+// callOut passes res through every matching call-out in order, returning
+// the object to apply or whether to skip it.
+func (bm *BackupManager) callOut(ctx context.Context, callouts []RestoreCallout, res archivedResource) (archivedResource, bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+	resource := res.gvr.GroupResource().String()
+	for _, callout := range callouts {
+		if len(callout.Resources) > 0 && !slices.Contains(callout.Resources, resource) {
+			continue
+		}
+		response, err := bm.call(ctx, callout, CalloutRequest{Resource: resource, Namespace: res.namespace, Name: res.name, Object: res.object})
+		if err == nil && response.Object != nil {
+			err = checkCalloutObject(res.object, response.Object)
+		}
+		if err != nil {
+			if callout.FailurePolicy == CalloutFailurePolicyIgnore {
+				log.Error(err, "Restore call-out failed, applying the object as is", "callout", callout.Name,
+					"resource", resource, "namespace", res.namespace, "name", res.name)
+				continue
+			}
+			return res, false, fmt.Errorf("call-out %q failed: %w", callout.Name, err)
+		}
+		if response.Skip {
+			log.V(1).Info("Restore call-out skipped object", "callout", callout.Name,
+				"resource", resource, "namespace", res.namespace, "name", res.name, "message", response.Message)
+			return res, true, nil
+		}
+		if response.Object != nil {
+			res.object = response.Object
+		}
+	}
+	return res, false, nil
+}
+
+// call posts request to callout and decodes its answer.
+func (bm *BackupManager) call(ctx context.Context, callout RestoreCallout, request CalloutRequest) (*CalloutResponse, error) {
+	timeout := callout.Timeout
+	if timeout <= 0 {
+		timeout = DefaultCalloutTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callout.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := bm.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("call-out answered %s", resp.Status)
+	}
+
+	response := &CalloutResponse{}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxCalloutResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read answer: %w", err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return response, nil
+	}
+	if err := json.Unmarshal(data, response); err != nil {
+		return nil, fmt.Errorf("failed to decode answer: %w", err)
+	}
+	return response, nil
+}
+
+// checkCalloutObject rejects a returned object that is not the archived
+// object anymore, which would restore something else in its place.
+func checkCalloutObject(archived, returned map[string]interface{}) error {
+	before, after := unstructured.Unstructured{Object: archived}, unstructured.Unstructured{Object: returned}
+	if before.GetAPIVersion() != after.GetAPIVersion() || before.GetKind() != after.GetKind() ||
+		before.GetName() != after.GetName() || before.GetNamespace() != after.GetNamespace() {
+		return fmt.Errorf("returned %s %s/%s in place of %s %s/%s", after.GetKind(), after.GetNamespace(), after.GetName(),
+			before.GetKind(), before.GetNamespace(), before.GetName())
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestRestoreCallouts(t *testing.T) {
+	t.Parallel()
+
+	storageDir := t.TempDir()
+	archiveName := "cluster-backup-callouts.tar.gz"
+	file, err := os.Create(filepath.Join(storageDir, archiveName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gz)
+	for _, name := range []string{"rewritten", "skipped", "unchanged"} {
+		writeJSONTarEntry(t, tarWriter, "namespaces/apps/v1/configmaps/"+name+".json", configMapObject("apps", name).Object)
+	}
+	for _, closer := range []interface{ Close() error }{tarWriter, gz, file} {
+		if err := closer.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request CalloutRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Resource != "configmaps" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		switch request.Name {
+		case "rewritten":
+			request.Object["data"] = map[string]interface{}{"rewritten": "true"}
+			_ = json.NewEncoder(w).Encode(CalloutResponse{Object: request.Object})
+		case "skipped":
+			_ = json.NewEncoder(w).Encode(CalloutResponse{Skip: true, Message: "not wanted"})
+		}
+	}))
+	defer hook.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer broken.Close()
+
+	scheme := runtime.NewScheme()
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+	bm := &BackupManager{DynamicClient: fake.NewSimpleDynamicClient(scheme)}
+	result, err := bm.RestoreBackup(context.Background(), storageDir, archiveName, RestoreOptions{Callouts: []RestoreCallout{
+		{Name: "down", URL: broken.URL, FailurePolicy: CalloutFailurePolicyIgnore},
+		{Name: "secrets-only", URL: broken.URL, Resources: []string{"secrets"}},
+		{Name: "hook", URL: hook.URL},
+	}})
+	if err != nil {
+		t.Fatalf("RestoreBackup returned error: %v", err)
+	}
+	if want := (RestoreCounts{Created: 2, Skipped: 1}); result.RestoreCounts != want {
+		t.Fatalf("restore counts = %+v, want %+v", result.RestoreCounts, want)
+	}
+	configMaps := bm.DynamicClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).Namespace("apps")
+	rewritten, err := configMaps.Get(context.Background(), "rewritten", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := nestedString(rewritten.Object, "data", "rewritten"); got != "true" {
+		t.Fatalf("rewritten configmap data = %q, want the call-out's change", got)
+	}
+
+	// A failing call-out fails the object under the default policy
+	bm = &BackupManager{DynamicClient: fake.NewSimpleDynamicClient(scheme)}
+	result, err = bm.RestoreBackup(context.Background(), storageDir, archiveName, RestoreOptions{Callouts: []RestoreCallout{
+		{Name: "down", URL: broken.URL},
+	}})
+	if err != nil {
+		t.Fatalf("RestoreBackup returned error: %v", err)
+	}
+	if result.Failed != 3 || len(result.FailedItems) != 3 {
+		t.Fatalf("restore result = %+v, want every object failed", result.RestoreCounts)
+	}
+}
+
+func TestCheckCalloutObjectRejectsOtherObjects(t *testing.T) {
+	t.Parallel()
+
+	archived := configMapObject("apps", "settings").Object
+	if err := checkCalloutObject(archived, configMapObject("apps", "settings").Object); err != nil {
+		t.Fatalf("checkCalloutObject() of the same object = %v", err)
+	}
+	if err := checkCalloutObject(archived, configMapObject("other", "settings").Object); err == nil {
+		t.Fatal("checkCalloutObject() of an object in another namespace = nil, want an error")
+	}
+}
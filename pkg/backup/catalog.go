@@ -0,0 +1,285 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CatalogName is the file at the root of a storage location summarizing
+// the archives it holds, so they can be listed with their sizes and
+// checksums without reading or stating each of them.
+const CatalogName = "catalog.json"
+
+// Catalog is the content of CatalogName.
+type Catalog struct {
+	UpdatedAt time.Time      `json:"updatedAt"`
+	Archives  []CatalogEntry `json:"archives"`
+}
+
+// CatalogEntry describes one archive in a Catalog.
+type CatalogEntry struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	// Checksum is the "sha256:<hex>" digest of the stored archive file, as
+	// accepted by RestoreOptions.ArchiveSHA256.
+	Checksum string `json:"checksum"`
+	// ManifestDigest is the digest of the archive's manifest, which stays
+	// the same when the archive is copied or re-encrypted. It is empty for
+	// archives cataloged after they were written.
+	ManifestDigest string `json:"manifestDigest,omitempty"`
+}
+
+// catalogMu serializes catalog updates, which read, change and replace the
+// whole file.
+var catalogMu sync.Mutex
+
+// ReadCatalog returns the catalog of storagePath, or nil when the location
+// has none yet. One is written with the first archive stored or removed
+// there after upgrading, or by RebuildCatalog.
+func (bm *BackupManager) ReadCatalog(storagePath string) (*Catalog, error) {
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := readCatalog(resolvedStoragePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return catalog, err
+}
+
+// RebuildCatalog replaces the catalog of storagePath with one listing the
+// archives it holds, reusing the manifest digests of the current catalog.
+// It brings the catalog back in line after archives were added or removed
+// by hand.
+func (bm *BackupManager) RebuildCatalog(storagePath string) (*Catalog, error) {
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return nil, err
+	}
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	previous, err := readCatalog(resolvedStoragePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	catalog, err := scanCatalog(resolvedStoragePath, previous)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeCatalog(resolvedStoragePath, catalog); err != nil {
+		return nil, err
+	}
+	return catalog, nil
+}
+
+// Entry returns the catalog entry of the named archive.
+func (c *Catalog) Entry(archiveName string) (CatalogEntry, bool) {
+	for _, entry := range c.Archives {
+		if entry.Name == archiveName {
+			return entry, true
+		}
+	}
+	return CatalogEntry{}, false
+}
+
+// catalogArchive records the archive at archivePath in the catalog of its
+// storage location. An empty manifestDigest keeps the one already recorded.
+// A location without a catalog gets one listing every archive it holds.
+func catalogArchive(archivePath, manifestDigest string) error {
+	dir, name := filepath.Split(archivePath)
+	dir = filepath.Clean(dir)
+	entry, err := catalogEntryFor(archivePath)
+	if err != nil {
+		return err
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	catalog, err := readCatalog(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		catalog, err = scanCatalog(dir, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	if previous, ok := catalog.Entry(name); ok && manifestDigest == "" {
+		manifestDigest = previous.ManifestDigest
+	}
+	entry.ManifestDigest = manifestDigest
+	catalog.Archives = append(withoutEntry(catalog.Archives, name), entry)
+	return writeCatalog(dir, catalog)
+}
+
+// uncatalogArchives drops the named archives from the catalog in dir, if
+// there is one, rewriting it once.
+func uncatalogArchives(dir string, names ...string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	catalog, err := readCatalog(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	listed := len(catalog.Archives)
+	for _, name := range names {
+		catalog.Archives = withoutEntry(catalog.Archives, name)
+	}
+	if len(catalog.Archives) == listed {
+		return nil
+	}
+	return writeCatalog(dir, catalog)
+}
+
+// catalogedManifestDigest returns the manifest digest the catalog next to
+// archivePath records for it, if any.
+func catalogedManifestDigest(archivePath string) string {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	catalog, err := readCatalog(filepath.Dir(archivePath))
+	if err != nil {
+		return ""
+	}
+	entry, _ := catalog.Entry(filepath.Base(archivePath))
+	return entry.ManifestDigest
+}
+
+func withoutEntry(entries []CatalogEntry, name string) []CatalogEntry {
+	kept := entries[:0]
+	for _, entry := range entries {
+		if entry.Name != name {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+// scanCatalog builds a catalog of the archives in dir, taking manifest
+// digests from previous when it is set. catalogMu must be held.
+func scanCatalog(dir string, previous *Catalog) (*Catalog, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read storage directory: %w", err)
+	}
+	catalog := &Catalog{Archives: []CatalogEntry{}}
+	for _, e := range entries {
+		if e.IsDir() || !isArchiveName(e.Name()) {
+			continue
+		}
+		entry, err := catalogEntryFor(filepath.Join(dir, e.Name()))
+		if errors.Is(err, os.ErrNotExist) {
+			// Removed while scanning
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if previous != nil {
+			old, _ := previous.Entry(e.Name())
+			entry.ManifestDigest = old.ManifestDigest
+		}
+		catalog.Archives = append(catalog.Archives, entry)
+	}
+	return catalog, nil
+}
+
+// catalogEntryFor sizes and checksums the archive at archivePath.
+func catalogEntryFor(archivePath string) (CatalogEntry, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return CatalogEntry{}, fmt.Errorf("failed to open archive for the catalog: %w", err)
+	}
+	defer file.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return CatalogEntry{}, fmt.Errorf("failed to checksum archive for the catalog: %w", err)
+	}
+	return CatalogEntry{
+		Name:     filepath.Base(archivePath),
+		Size:     size,
+		Checksum: digestPrefix + hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
+
+// readCatalog reads the catalog in dir; catalogMu must be held.
+func readCatalog(dir string) (*Catalog, error) {
+	data, err := os.ReadFile(filepath.Join(dir, CatalogName))
+	if err != nil {
+		return nil, err
+	}
+	catalog := &Catalog{}
+	if err := json.Unmarshal(data, catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", CatalogName, err)
+	}
+	return catalog, nil
+}
+
+// writeCatalog replaces the catalog in dir. It is written to a temporary
+// file first and renamed over the old one, so readers never see a partial
+// catalog. catalogMu must be held.
+func writeCatalog(dir string, catalog *Catalog) error {
+	// Sorted by name, which is oldest first
+	sort.Slice(catalog.Archives, func(i, j int) bool { return catalog.Archives[i].Name < catalog.Archives[j].Name })
+	catalog.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(catalog, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode catalog: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+CatalogName+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to create catalog: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write catalog: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync catalog: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write catalog: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write catalog: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, CatalogName)); err != nil {
+		return fmt.Errorf("failed to replace catalog: %w", err)
+	}
+	return nil
+}
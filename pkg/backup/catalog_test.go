@@ -0,0 +1,93 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCatalogTracksArchives(t *testing.T) {
+	t.Parallel()
+
+	storageDir := t.TempDir()
+	first := filepath.Join(storageDir, "cluster-backup-20250101-000000.tar.gz")
+	second := filepath.Join(storageDir, "cluster-backup-20250102-000000.tar.gz")
+	for _, path := range []string{first, second} {
+		if err := os.WriteFile(path, []byte("archive "+filepath.Base(path)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bm := &BackupManager{}
+
+	if catalog, err := bm.ReadCatalog(storageDir); err != nil || catalog != nil {
+		t.Fatalf("ReadCatalog without a catalog = %v, %v, want nil, nil", catalog, err)
+	}
+
+	// The first archive cataloged lists those stored before
+	if err := catalogArchive(second, "sha256:manifest"); err != nil {
+		t.Fatalf("catalogArchive returned error: %v", err)
+	}
+	catalog, err := bm.ReadCatalog(storageDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(catalog.Archives) != 2 || catalog.Archives[0].Name != filepath.Base(first) {
+		t.Fatalf("catalog archives = %+v, want both archives oldest first", catalog.Archives)
+	}
+	entry, _ := catalog.Entry(filepath.Base(second))
+	want := digest([]byte("archive " + filepath.Base(second)))
+	if entry.Size != int64(len("archive "+filepath.Base(second))) || entry.Checksum != want || entry.ManifestDigest != "sha256:manifest" {
+		t.Fatalf("catalog entry = %+v, want size, checksum %s and manifest digest", entry, want)
+	}
+
+	// A transferred archive keeps its manifest digest
+	destination := t.TempDir()
+	if _, err := bm.TransferArchive(context.Background(), storageDir, filepath.Base(second), destination, false); err != nil {
+		t.Fatalf("TransferArchive returned error: %v", err)
+	}
+	copied, err := bm.ReadCatalog(destination)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry, ok := copied.Entry(filepath.Base(second)); !ok || entry.ManifestDigest != "sha256:manifest" || entry.Checksum != want {
+		t.Fatalf("destination catalog = %+v, want the transferred archive with its digests", copied.Archives)
+	}
+
+	// Usage is summed from the catalog, without stating the archives
+	if err := os.WriteFile(first, []byte("rewritten by hand"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	usage, err := bm.StorageUsage(storageDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wantBytes := int64(len("archive "+filepath.Base(first)) + len("archive "+filepath.Base(second))); usage.Bytes != wantBytes {
+		t.Fatalf("storage usage = %+v, want %d bytes from the catalog", usage, wantBytes)
+	}
+	rebuilt, err := bm.RebuildCatalog(storageDir)
+	if err != nil {
+		t.Fatalf("RebuildCatalog returned error: %v", err)
+	}
+	if entry, _ := rebuilt.Entry(filepath.Base(first)); entry.Size != int64(len("rewritten by hand")) {
+		t.Fatalf("rebuilt entry = %+v, want the new size", entry)
+	}
+	if entry, _ := rebuilt.Entry(filepath.Base(second)); entry.ManifestDigest != "sha256:manifest" {
+		t.Fatalf("rebuilt entry = %+v, want the manifest digest kept", entry)
+	}
+
+	if err := removeArchive(second); err != nil {
+		t.Fatalf("removeArchive returned error: %v", err)
+	}
+	catalog, err = bm.ReadCatalog(storageDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(catalog.Archives) != 1 || catalog.Archives[0].Name != filepath.Base(first) {
+		t.Fatalf("catalog archives after removal = %+v, want only %s", catalog.Archives, filepath.Base(first))
+	}
+	leftovers, _ := filepath.Glob(filepath.Join(storageDir, ".*"))
+	if len(leftovers) > 0 {
+		t.Fatalf("temporary catalog files left behind: %v", leftovers)
+	}
+}
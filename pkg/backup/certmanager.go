@@ -0,0 +1,194 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	certManagerGroup = "cert-manager.io"
+	acmeGroup        = "acme.cert-manager.io"
+
+	// certificateNameAnnotation is set by cert-manager on the Secrets it
+	// issues for a Certificate.
+	certificateNameAnnotation = "cert-manager.io/certificate-name"
+	// nextPrivateKeyLabel marks the Secrets cert-manager keeps the private key
+	// of an in-flight issuance in.
+	nextPrivateKeyLabel = "cert-manager.io/next-private-key"
+
+	// defaultCertManagerWaitTimeout bounds how long a restore waits for the
+	// cert-manager CRDs to be established.
+	defaultCertManagerWaitTimeout = 2 * time.Minute
+)
+
+// crdPollInterval is how often a restore checks whether CRDs are established.
+var crdPollInterval = 2 * time.Second
+
+var (
+	certificateGVR   = schema.GroupVersionResource{Group: certManagerGroup, Version: "v1", Resource: "certificates"}
+	issuerGVR        = schema.GroupVersionResource{Group: certManagerGroup, Version: "v1", Resource: "issuers"}
+	clusterIssuerGVR = schema.GroupVersionResource{Group: certManagerGroup, Version: "v1", Resource: "clusterissuers"}
+	crdGVR           = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+)
+
+// reissuableCertificateSecrets returns a filter matching the Secrets that
+// cert-manager can issue again on its own: Secrets of Certificates that exist
+// in the cluster, and the private keys of in-flight issuances. Secrets that a
+// CA Issuer or ClusterIssuer signs with are never matched, since a new CA
+// would invalidate every certificate it issued.
+func (bm *BackupManager) reissuableCertificateSecrets(ctx context.Context) (func(obj map[string]interface{}) bool, error) {
+	certificates, err := bm.listCertManager(ctx, certificateGVR)
+	if err != nil {
+		return nil, err
+	}
+	// namespace/certificate -> the Secret it writes
+	certificateSecrets := map[string]string{}
+	for _, certificate := range certificates {
+		secretName, _, _ := unstructured.NestedString(certificate.Object, "spec", "secretName")
+		certificateSecrets[certificate.GetNamespace()+"/"+certificate.GetName()] = secretName
+	}
+
+	issuers, err := bm.listCertManager(ctx, issuerGVR)
+	if err != nil {
+		return nil, err
+	}
+	clusterIssuers, err := bm.listCertManager(ctx, clusterIssuerGVR)
+	if err != nil {
+		return nil, err
+	}
+	// CA secrets of Issuers by namespace/name; those of ClusterIssuers live
+	// in cert-manager's cluster resource namespace, which is not known here,
+	// so they are protected by name in every namespace
+	caSecrets := map[string]struct{}{}
+	for _, issuer := range issuers {
+		if secretName, _, _ := unstructured.NestedString(issuer.Object, "spec", "ca", "secretName"); secretName != "" {
+			caSecrets[issuer.GetNamespace()+"/"+secretName] = struct{}{}
+		}
+	}
+	clusterCASecrets := map[string]struct{}{}
+	for _, issuer := range clusterIssuers {
+		if secretName, _, _ := unstructured.NestedString(issuer.Object, "spec", "ca", "secretName"); secretName != "" {
+			clusterCASecrets[secretName] = struct{}{}
+		}
+	}
+
+	return func(obj map[string]interface{}) bool {
+		u := unstructured.Unstructured{Object: obj}
+		if u.GetAPIVersion() != "v1" || u.GetKind() != "Secret" {
+			return false
+		}
+		if _, ok := caSecrets[u.GetNamespace()+"/"+u.GetName()]; ok {
+			return false
+		}
+		if _, ok := clusterCASecrets[u.GetName()]; ok {
+			return false
+		}
+		if u.GetLabels()[nextPrivateKeyLabel] == "true" {
+			return true
+		}
+		certificate := u.GetAnnotations()[certificateNameAnnotation]
+		if certificate == "" {
+			return false
+		}
+		secretName, ok := certificateSecrets[u.GetNamespace()+"/"+certificate]
+		return ok && secretName == u.GetName()
+	}, nil
+}
+
+// listCertManager lists every object of gvr, treating a cluster without
+// cert-manager as having none.
+func (bm *BackupManager) listCertManager(ctx context.Context, gvr schema.GroupVersionResource) ([]unstructured.Unstructured, error) {
+	list, err := bm.DynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", gvr.GroupResource(), err)
+	}
+	return list.Items, nil
+}
+
+// isCertManagerResource reports whether gvr is served by cert-manager's CRDs.
+func isCertManagerResource(gvr schema.GroupVersionResource) bool {
+	return gvr.Group == certManagerGroup || gvr.Group == acmeGroup
+}
+
+// splitCertManagerResources separates cert-manager resources from the other
+// resources, so they can be applied once cert-manager's CRDs are established
+// and the Secrets their issuers reference are back.
+func splitCertManagerResources(resources []archivedResource) ([]archivedResource, []archivedResource) {
+	var others, certManager []archivedResource
+	for _, res := range resources {
+		if isCertManagerResource(res.gvr) {
+			certManager = append(certManager, res)
+		} else {
+			others = append(others, res)
+		}
+	}
+	return others, certManager
+}
+
+// waitForCRDs waits until the CRDs serving every resource in resources are
+// established. It gives up after timeout, leaving the resources to fail on
+// their own.
+func (bm *BackupManager) waitForCRDs(ctx context.Context, resources []archivedResource, timeout time.Duration) error {
+	pending := map[string]struct{}{}
+	for _, res := range resources {
+		pending[res.gvr.GroupResource().String()] = struct{}{}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	log := ctrl.LoggerFrom(ctx)
+	return wait.PollUntilContextTimeout(ctx, crdPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		for name := range pending {
+			crd, err := bm.DynamicClient.Resource(crdGVR).Get(ctx, name, metav1.GetOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				return false, err
+			}
+			if err != nil || !crdEstablished(crd) {
+				log.V(1).Info("Waiting for CRD to be established", "crd", name)
+				return false, nil
+			}
+			delete(pending, name)
+		}
+		return true, nil
+	})
+}
+
+// crdEstablished reports whether crd has the Established condition.
+func crdEstablished(crd *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == "Established" && condition["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestReissuableCertificateSecrets(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	for _, kind := range []string{"Certificate", "Issuer", "ClusterIssuer"} {
+		registerUnstructuredType(scheme, schema.GroupVersionKind{Group: certManagerGroup, Version: "v1", Kind: kind})
+	}
+	dynamicClient := fake.NewSimpleDynamicClient(scheme,
+		certManagerObject("Certificate", "apps", "web", map[string]interface{}{"secretName": "web-tls"}),
+		certManagerObject("Certificate", "apps", "root", map[string]interface{}{"secretName": "root-ca", "isCA": true}),
+		certManagerObject("Certificate", "cert-manager", "cluster-root", map[string]interface{}{"secretName": "cluster-ca", "isCA": true}),
+		certManagerObject("Issuer", "apps", "ca", map[string]interface{}{"ca": map[string]interface{}{"secretName": "root-ca"}}),
+		certManagerObject("ClusterIssuer", "", "cluster-ca", map[string]interface{}{"ca": map[string]interface{}{"secretName": "cluster-ca"}}),
+	)
+
+	bm := &BackupManager{DynamicClient: dynamicClient}
+	reissuable, err := bm.reissuableCertificateSecrets(context.Background())
+	if err != nil {
+		t.Fatalf("reissuableCertificateSecrets returned error: %v", err)
+	}
+
+	cases := map[string]struct {
+		obj  *unstructured.Unstructured
+		want bool
+	}{
+		"issued secret":         {obj: certificateSecret("apps", "web-tls", "web", nil), want: true},
+		"in-flight private key": {obj: certificateSecret("apps", "web-k8p2x", "", map[string]string{nextPrivateKeyLabel: "true"}), want: true},
+		"issuer CA secret":      {obj: certificateSecret("apps", "root-ca", "root", nil), want: false},
+		"cluster CA secret":     {obj: certificateSecret("cert-manager", "cluster-ca", "cluster-root", nil), want: false},
+		"deleted certificate":   {obj: certificateSecret("apps", "old-tls", "old", nil), want: false},
+		"renamed secret":        {obj: certificateSecret("apps", "web-tls-previous", "web", nil), want: false},
+		"unmanaged secret":      {obj: certificateSecret("apps", "token", "", nil), want: false},
+		"not a secret":          {obj: configMapObject("apps", "web-tls"), want: false},
+	}
+	for name, tc := range cases {
+		if got := reissuable(tc.obj.Object); got != tc.want {
+			t.Errorf("%s: expected reissuable %v, got %v", name, tc.want, got)
+		}
+	}
+
+	// Without cert-manager nothing is skipped
+	withoutCertManager := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		certificateGVR:   "CertificateList",
+		issuerGVR:        "IssuerList",
+		clusterIssuerGVR: "ClusterIssuerList",
+	})
+	withoutCertManager.PrependReactor("list", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(action.GetResource().GroupResource(), "")
+	})
+	bm = &BackupManager{DynamicClient: withoutCertManager}
+	reissuable, err = bm.reissuableCertificateSecrets(context.Background())
+	if err != nil {
+		t.Fatalf("expected a cluster without cert-manager to be handled, got %v", err)
+	}
+	if reissuable(certificateSecret("apps", "web-tls", "web", nil).Object) {
+		t.Fatalf("expected no secret to be skipped without cert-manager")
+	}
+}
+
+func TestRestoreBackupWaitsForCertManagerCRDs(t *testing.T) {
+	pollInterval := crdPollInterval
+	crdPollInterval = time.Millisecond
+	t.Cleanup(func() { crdPollInterval = pollInterval })
+
+	storageDir := t.TempDir()
+	archiveName := "cluster-backup-cert-manager.tar.gz"
+	file, err := os.Create(filepath.Join(storageDir, archiveName))
+	if err != nil {
+		t.Fatalf("failed to create archive: %v", err)
+	}
+	gz := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gz)
+	writeJSONTarEntry(t, tarWriter, "cluster/cert-manager.io/v1/clusterissuers/cluster-ca.json",
+		certManagerObject("ClusterIssuer", "", "cluster-ca", map[string]interface{}{"ca": map[string]interface{}{"secretName": "cluster-ca"}}).Object)
+	writeJSONTarEntry(t, tarWriter, "cluster/v1/namespaces/apps.json", map[string]interface{}{
+		"apiVersion": "v1", "kind": "Namespace", "metadata": map[string]interface{}{"name": "apps"},
+	})
+	writeJSONTarEntry(t, tarWriter, "namespaces/apps/cert-manager.io/v1/certificates/web.json",
+		certManagerObject("Certificate", "apps", "web", map[string]interface{}{"secretName": "web-tls"}).Object)
+	writeJSONTarEntry(t, tarWriter, "namespaces/apps/v1/secrets/root-ca.json", certificateSecret("apps", "root-ca", "", nil).Object)
+	for _, closer := range []interface{ Close() error }{tarWriter, gz, file} {
+		if err := closer.Close(); err != nil {
+			t.Fatalf("failed to finalize archive: %v", err)
+		}
+	}
+
+	scheme := runtime.NewScheme()
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Version: "v1", Kind: "Namespace"})
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Version: "v1", Kind: "Secret"})
+	for _, kind := range []string{"Certificate", "ClusterIssuer"} {
+		registerUnstructuredType(scheme, schema.GroupVersionKind{Group: certManagerGroup, Version: "v1", Kind: kind})
+	}
+	dynamicClient := fake.NewSimpleDynamicClient(scheme)
+
+	// The CRDs become established on the third check
+	var events []string
+	checks := 0
+	dynamicClient.PrependReactor("get", "customresourcedefinitions", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		checks++
+		name := action.(clienttesting.GetAction).GetName()
+		crd := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apiextensions.k8s.io/v1", "kind": "CustomResourceDefinition",
+			"metadata": map[string]interface{}{"name": name},
+		}}
+		if checks >= 3 {
+			events = append(events, "established "+name)
+			crd.Object["status"] = map[string]interface{}{"conditions": []interface{}{
+				map[string]interface{}{"type": "Established", "status": "True"},
+			}}
+		}
+		return true, crd, nil
+	})
+	dynamicClient.PrependReactor("create", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		events = append(events, "create "+action.GetResource().Resource)
+		return false, nil, nil
+	})
+
+	bm := &BackupManager{DynamicClient: dynamicClient}
+	result, err := bm.RestoreBackup(context.Background(), storageDir, archiveName, RestoreOptions{})
+	if err != nil {
+		t.Fatalf("RestoreBackup returned error: %v", err)
+	}
+	if result.Created != 4 || result.Failed != 0 {
+		t.Fatalf("expected every resource to be created, got %+v", result.RestoreCounts)
+	}
+
+	if len(events) != 6 || events[0] != "create namespaces" || events[1] != "create secrets" ||
+		events[4] != "create clusterissuers" || events[5] != "create certificates" {
+		t.Fatalf("expected cert-manager resources to be created last, once their CRDs are established, got %v", events)
+	}
+}
+
+func certManagerObject(kind, namespace, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": certManagerGroup + "/v1",
+		"kind":       kind,
+		"spec":       spec,
+	}}
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	return obj
+}
+
+func certificateSecret(namespace, name, certificate string, labels map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"type":       "kubernetes.io/tls",
+	}}
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	obj.SetLabels(labels)
+	if certificate != "" {
+		obj.SetAnnotations(map[string]string{certificateNameAnnotation: certificate})
+	}
+	return obj
+}
@@ -0,0 +1,444 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// ChangeType is the kind of change a ChangeRecord holds.
+type ChangeType string
+
+const (
+	// ChangeUpsert records an object as it is after being created or
+	// updated.
+	ChangeUpsert ChangeType = "Upsert"
+	// ChangeDelete is a tombstone for a deleted object.
+	ChangeDelete ChangeType = "Delete"
+)
+
+const (
+	// ChangeLogDir is the directory below a storage location holding change
+	// log segments.
+	ChangeLogDir = "changes"
+
+	// DefaultSegmentInterval is how long a change log segment covers when
+	// ContinuousOptions.SegmentInterval is unset.
+	DefaultSegmentInterval = time.Hour
+
+	changeLogPrefix = "changes-"
+	changeLogSuffix = ".jsonl"
+)
+
+// continuousSkippedResources churn constantly without describing desired
+// state, so they are never watched.
+var continuousSkippedResources = map[schema.GroupResource]struct{}{
+	{Resource: "events"}:                               {},
+	{Group: "events.k8s.io", Resource: "events"}:       {},
+	{Group: "coordination.k8s.io", Resource: "leases"}: {},
+}
+
+// ChangeRecord is one line of a change log.
+type ChangeRecord struct {
+	Time            time.Time  `json:"time"`
+	Type            ChangeType `json:"type"`
+	Group           string     `json:"group,omitempty"`
+	Version         string     `json:"version"`
+	Resource        string     `json:"resource"`
+	Namespace       string     `json:"namespace,omitempty"`
+	Name            string     `json:"name"`
+	ResourceVersion string     `json:"resourceVersion,omitempty"`
+	// Object is the object after the change with runtime fields removed, as
+	// in archives. Tombstones leave it empty.
+	Object map[string]interface{} `json:"object,omitempty"`
+}
+
+// GVR returns the resource type of the changed object.
+func (r ChangeRecord) GVR() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: r.Group, Version: r.Version, Resource: r.Resource}
+}
+
+// ContinuousOptions configures WatchChanges.
+type ContinuousOptions struct {
+	// Selection chooses the resources to watch through its namespace and
+	// resource type filters, ExcludeGitOpsManaged, IncludeGeneratedResources
+	// and IncludeFinishedWorkloads. Items and Application are not supported.
+	Selection BackupOptions
+
+	// SegmentInterval is how long each change log segment covers before
+	// the next one is started. Zero uses DefaultSegmentInterval.
+	SegmentInterval time.Duration
+}
+
+// ChangeLogStats describes a running change log.
+type ChangeLogStats struct {
+	// Segment is the path of the segment being written.
+	Segment string
+	// Records counts the changes written since the log started.
+	Records int64
+	// LastChange is when the latest change was written.
+	LastChange time.Time
+	// Err is the latest write error, cleared by the next successful write.
+	Err error
+}
+
+// ChangeLog appends the changes of watched resources to segments below
+// ChangeLogDir of a storage location.
+type ChangeLog struct {
+	dir             string
+	storagePath     string
+	bm              *BackupManager
+	segmentInterval time.Duration
+	exclude         func(obj map[string]interface{}) bool
+	// dropFinished records workloads that finish as deleted.
+	dropFinished bool
+
+	mu           sync.Mutex
+	file         *os.File
+	segmentStart time.Time
+	stats        ChangeLogStats
+}
+
+// WatchChanges watches the resources selected by opts and appends every
+// change to a change log in storagePath until ctx is done. Objects that
+// exist when the watch starts are not recorded; full backups provide that
+// baseline. Updates that only touch runtime fields or status are dropped.
+// Segments ending before the oldest archive in storagePath are removed
+// whenever a new segment starts. WatchChanges returns once the initial
+// lists have synced.
+func (bm *BackupManager) WatchChanges(ctx context.Context, storagePath string, opts ContinuousOptions) (*ChangeLog, error) {
+	log := ctrl.LoggerFrom(ctx)
+	selection := opts.Selection
+	if len(selection.Items) > 0 || selection.Application != nil {
+		return nil, fmt.Errorf("continuous backups cannot select items or an application")
+	}
+
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return nil, err
+	}
+	changeLog := &ChangeLog{
+		dir:             filepath.Join(resolvedStoragePath, ChangeLogDir),
+		storagePath:     storagePath,
+		bm:              bm,
+		segmentInterval: opts.SegmentInterval,
+	}
+	if changeLog.segmentInterval <= 0 {
+		changeLog.segmentInterval = DefaultSegmentInterval
+	}
+	if !selection.IncludeGeneratedResources {
+		changeLog.exclude = isClusterGenerated
+	}
+	changeLog.dropFinished = !selection.IncludeFinishedWorkloads
+	if selection.ExcludeGitOpsManaged {
+		changeLog.exclude = excludeEither(changeLog.exclude, isGitOpsManaged)
+	}
+	patterns, err := compileNamespacePatterns(selection.ExcludeNamespacePatterns)
+	if err != nil {
+		return nil, err
+	}
+	if len(patterns) > 0 {
+		changeLog.exclude = excludeEither(changeLog.exclude, patterns.excludes)
+	}
+	if err := os.MkdirAll(changeLog.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create change log directory: %w", err)
+	}
+	if err := changeLog.rotate(time.Now()); err != nil {
+		return nil, err
+	}
+
+	excluded := makeStringSet(selection.ExcludeNamespaces, strings.TrimSpace)
+	namespaceFactories := map[string]dynamicinformer.DynamicSharedInformerFactory{}
+	factoryFor := func(namespace string) dynamicinformer.DynamicSharedInformerFactory {
+		if factory, ok := namespaceFactories[namespace]; ok {
+			return factory
+		}
+		factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(bm.DynamicClient, 0, namespace, nil)
+		namespaceFactories[namespace] = factory
+		return factory
+	}
+
+	watched := 0
+	for _, target := range bm.discoverResources(ctx, selection) {
+		if _, skip := continuousSkippedResources[target.gvr.GroupResource()]; skip || !target.watchable {
+			continue
+		}
+		namespaces := []string{""}
+		if target.namespaced && len(selection.IncludeNamespaces) > 0 {
+			namespaces = selection.IncludeNamespaces
+		}
+		for _, namespace := range namespaces {
+			handler := &changeHandler{log: changeLog, gvr: target.gvr}
+			if target.namespaced && namespace == "" {
+				handler.excludedNamespaces = excluded
+			}
+			if _, err := factoryFor(namespace).ForResource(target.gvr).Informer().AddEventHandler(handler); err != nil {
+				return nil, fmt.Errorf("failed to watch %s: %w", target.gvr.GroupResource(), err)
+			}
+			watched++
+		}
+	}
+	if watched == 0 {
+		changeLog.close()
+		return nil, fmt.Errorf("no watchable resources are selected")
+	}
+
+	for _, factory := range namespaceFactories {
+		factory.Start(ctx.Done())
+	}
+	for _, factory := range namespaceFactories {
+		for gvr, synced := range factory.WaitForCacheSync(ctx.Done()) {
+			if !synced {
+				log.Info("Change log watch did not sync", "gvr", gvr)
+			}
+		}
+	}
+	go func() {
+		<-ctx.Done()
+		for _, factory := range namespaceFactories {
+			factory.Shutdown()
+		}
+		changeLog.close()
+	}()
+	log.Info("Watching for changes", "resources", watched, "storagePath", storagePath)
+	return changeLog, nil
+}
+
+// Stats returns the current state of the change log.
+func (c *ChangeLog) Stats() ChangeLogStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// append writes record to the current segment, starting a new one first
+// when the current one is due.
+func (c *ChangeLog) append(record ChangeRecord) {
+	data, err := json.Marshal(record)
+	if err == nil {
+		data = append(data, '\n')
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.file == nil {
+		return
+	}
+	if err == nil && record.Time.Sub(c.segmentStart) >= c.segmentInterval {
+		err = c.rotateLocked(record.Time)
+	}
+	if err == nil {
+		_, err = c.file.Write(data)
+	}
+	if err != nil {
+		c.stats.Err = fmt.Errorf("failed to record change of %s %s: %w", record.GVR().GroupResource(), record.Name, err)
+		return
+	}
+	c.stats.Records++
+	c.stats.LastChange = record.Time
+	c.stats.Err = nil
+}
+
+// rotate starts a new segment.
+func (c *ChangeLog) rotate(now time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rotateLocked(now)
+}
+
+func (c *ChangeLog) rotateLocked(now time.Time) error {
+	if c.file != nil {
+		if err := c.file.Close(); err != nil {
+			return fmt.Errorf("failed to close change log segment: %w", err)
+		}
+		c.file = nil
+	}
+	name := changeLogPrefix + now.UTC().Format("20060102-150405") + changeLogSuffix
+	file, err := os.OpenFile(filepath.Join(c.dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open change log segment: %w", err)
+	}
+	c.file = file
+	c.segmentStart = now
+	c.stats.Segment = file.Name()
+	if err := c.prune(); err != nil {
+		c.stats.Err = err
+	}
+	return nil
+}
+
+// prune removes the segments that ended before the oldest archive in the
+// storage location was written, since no restore can replay them.
+func (c *ChangeLog) prune() error {
+	archives, err := c.bm.ListArchives(c.storagePath)
+	if err != nil || len(archives) == 0 {
+		return err
+	}
+	oldest, err := os.Stat(filepath.Join(filepath.Dir(c.dir), archives[0]))
+	if err != nil {
+		return err
+	}
+	segments, err := listChangeLogSegments(c.dir)
+	if err != nil {
+		return err
+	}
+	// A segment ends when the next one starts
+	for i := 0; i+1 < len(segments); i++ {
+		if !segments[i+1].start.Before(oldest.ModTime()) {
+			break
+		}
+		if err := os.Remove(segments[i].path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove change log segment: %w", err)
+		}
+	}
+	return nil
+}
+
+// close closes the current segment; later changes are dropped.
+func (c *ChangeLog) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.file != nil {
+		_ = c.file.Close()
+		c.file = nil
+	}
+}
+
+// changeLogSegment is one segment file of a change log.
+type changeLogSegment struct {
+	path  string
+	start time.Time
+}
+
+// listChangeLogSegments returns the segments in dir, oldest first.
+func listChangeLogSegments(dir string) ([]changeLogSegment, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read change log directory: %w", err)
+	}
+	var segments []changeLogSegment
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, changeLogPrefix) || !strings.HasSuffix(name, changeLogSuffix) {
+			continue
+		}
+		start, err := time.Parse("20060102-150405", strings.TrimSuffix(strings.TrimPrefix(name, changeLogPrefix), changeLogSuffix))
+		if err != nil {
+			continue
+		}
+		segments = append(segments, changeLogSegment{path: filepath.Join(dir, name), start: start})
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].start.Before(segments[j].start) })
+	return segments, nil
+}
+
+// changeHandler turns the informer events of one resource type into change
+// records.
+type changeHandler struct {
+	log *ChangeLog
+	gvr schema.GroupVersionResource
+	// excludedNamespaces filters cluster-wide watches of namespaced types.
+	excludedNamespaces map[string]struct{}
+}
+
+func (h *changeHandler) OnAdd(obj interface{}, isInInitialList bool) {
+	if !isInInitialList {
+		h.record(ChangeUpsert, obj)
+	}
+}
+
+func (h *changeHandler) OnUpdate(oldObj, newObj interface{}) {
+	oldItem, ok := oldObj.(*unstructured.Unstructured)
+	newItem, newOK := newObj.(*unstructured.Unstructured)
+	if ok && newOK {
+		if oldItem.GetResourceVersion() == newItem.GetResourceVersion() {
+			return
+		}
+		// A workload that finished leaves the backup as if it was deleted
+		if h.log.dropFinished && isFinishedWorkload(newItem.Object) {
+			if !isFinishedWorkload(oldItem.Object) {
+				h.record(ChangeDelete, newObj)
+			}
+			return
+		}
+		// Status and runtime fields are not recorded, so changes only to
+		// them are not either
+		oldJSON, oldErr := json.Marshal(cleanedCopy(oldItem.Object))
+		newJSON, newErr := json.Marshal(cleanedCopy(newItem.Object))
+		if oldErr == nil && newErr == nil && string(oldJSON) == string(newJSON) {
+			return
+		}
+	}
+	h.record(ChangeUpsert, newObj)
+}
+
+func (h *changeHandler) OnDelete(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	h.record(ChangeDelete, obj)
+}
+
+// record appends a change of obj unless it is filtered out.
+func (h *changeHandler) record(changeType ChangeType, obj interface{}) {
+	item, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	if _, skip := h.excludedNamespaces[item.GetNamespace()]; skip {
+		return
+	}
+	if h.log.exclude != nil && h.log.exclude(item.Object) {
+		return
+	}
+	if changeType == ChangeUpsert && h.log.dropFinished && isFinishedWorkload(item.Object) {
+		return
+	}
+
+	record := ChangeRecord{
+		Time:            time.Now().UTC(),
+		Type:            changeType,
+		Group:           h.gvr.Group,
+		Version:         h.gvr.Version,
+		Resource:        h.gvr.Resource,
+		Namespace:       item.GetNamespace(),
+		Name:            item.GetName(),
+		ResourceVersion: item.GetResourceVersion(),
+	}
+	if changeType == ChangeUpsert {
+		record.Object = cleanedCopy(item.Object)
+	}
+	h.log.append(record)
+}
@@ -0,0 +1,139 @@
+package backup
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestWatchChanges(t *testing.T) {
+	t.Parallel()
+
+	storageDir := t.TempDir()
+	configMaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	bm := &BackupManager{
+		DynamicClient: newQuotaTestClient(configMapObject("app", "existing")),
+		DiscoveryClient: preferredResources{lists: []*metav1.APIResourceList{
+			{GroupVersion: "v1", APIResources: []metav1.APIResource{
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"list", "watch"}},
+				{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: []string{"list"}},
+			}},
+		}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changeLog, err := bm.WatchChanges(ctx, storageDir, ContinuousOptions{
+		Selection: BackupOptions{ExcludeNamespaces: []string{"ignored"}},
+	})
+	if err != nil {
+		t.Fatalf("WatchChanges returned error: %v", err)
+	}
+
+	client := bm.DynamicClient.Resource(configMaps)
+	created := configMapObject("app", "settings")
+	created.Object["data"] = map[string]interface{}{"key": "value"}
+	if _, err := client.Namespace("app").Create(ctx, created, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Namespace("ignored").Create(ctx, configMapObject("ignored", "settings"), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	statusOnly := created.DeepCopy()
+	statusOnly.SetResourceVersion("2")
+	statusOnly.Object["status"] = map[string]interface{}{"observed": "yes"}
+	if _, err := client.Namespace("app").Update(ctx, statusOnly, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Namespace("app").Delete(ctx, "settings", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for changeLog.Stats().Records < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	stats := changeLog.Stats()
+	if stats.Records != 2 || stats.Err != nil {
+		t.Fatalf("stats = %+v, want 2 records", stats)
+	}
+
+	records := readChangeRecords(t, stats.Segment)
+	if len(records) != 2 {
+		t.Fatalf("records = %+v, want an upsert and a tombstone", records)
+	}
+	if upsert := records[0]; upsert.Type != ChangeUpsert || upsert.Namespace != "app" || upsert.Name != "settings" ||
+		upsert.GVR() != configMaps || upsert.Object["data"] == nil {
+		t.Fatalf("first record = %+v, want the created ConfigMap", upsert)
+	}
+	if tombstone := records[1]; tombstone.Type != ChangeDelete || tombstone.Name != "settings" || tombstone.Object != nil {
+		t.Fatalf("second record = %+v, want a tombstone", tombstone)
+	}
+	if filepath.Dir(stats.Segment) != filepath.Join(storageDir, ChangeLogDir) {
+		t.Fatalf("segment = %s, want it below %s", stats.Segment, ChangeLogDir)
+	}
+}
+
+func TestChangeLogPrune(t *testing.T) {
+	t.Parallel()
+
+	storageDir := t.TempDir()
+	dir := filepath.Join(storageDir, ChangeLogDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"changes-20250101-000000.jsonl", "changes-20250102-000000.jsonl", "changes-20250103-000000.jsonl"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	archive := filepath.Join(storageDir, "cluster-backup-20250102-120000.tar.gz")
+	if err := os.WriteFile(archive, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	archiveTime := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(archive, archiveTime, archiveTime); err != nil {
+		t.Fatal(err)
+	}
+
+	changeLog := &ChangeLog{dir: dir, storagePath: storageDir, bm: &BackupManager{}}
+	if err := changeLog.prune(); err != nil {
+		t.Fatalf("prune returned error: %v", err)
+	}
+	segments, err := listChangeLogSegments(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The segment of January 2nd covers the archive and must be kept
+	if len(segments) != 2 || filepath.Base(segments[0].path) != "changes-20250102-000000.jsonl" {
+		t.Fatalf("segments = %+v, want the first one removed", segments)
+	}
+}
+
+func readChangeRecords(t *testing.T, path string) []ChangeRecord {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var records []ChangeRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record ChangeRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("invalid change record %q: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
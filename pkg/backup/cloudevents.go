@@ -0,0 +1,113 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CloudEvent types published for the backup lifecycle.
+const (
+	CloudEventBackupCompleted  = "backup.completed"
+	CloudEventBackupFailed     = "backup.failed"
+	CloudEventRestoreCompleted = "restore.completed"
+	CloudEventRestoreFailed    = "restore.failed"
+	CloudEventArchiveDeleted   = "archive.deleted"
+)
+
+// CloudEvent is a CloudEvents 1.0 event in its JSON format.
+type CloudEvent struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Subject         string    `json:"subject,omitempty"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype,omitempty"`
+	Data            any       `json:"data,omitempty"`
+}
+
+// CloudEventSink is where PublishCloudEvent delivers events. With a Topic
+// the URL is a Kafka REST Proxy the event is produced through; otherwise
+// the event is posted to the URL.
+type CloudEventSink struct {
+	URL   string
+	Topic string
+}
+
+// kafkaRecords is the body of a Kafka REST Proxy v2 produce request.
+type kafkaRecords struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+type kafkaRecord struct {
+	Key   string     `json:"key,omitempty"`
+	Value CloudEvent `json:"value"`
+}
+
+// withCloudEventDefaults sets the spec version and, for events with data,
+// the data content type.
+func withCloudEventDefaults(event CloudEvent) CloudEvent {
+	event.SpecVersion = "1.0"
+	if event.Data != nil && event.DataContentType == "" {
+		event.DataContentType = "application/json"
+	}
+	return event
+}
+
+// PublishCloudEvent delivers event to sink. Over HTTP the event is sent in
+// structured content mode; through Kafka it is the record value, keyed by
+// its source so the events of one object stay ordered.
+func PublishCloudEvent(ctx context.Context, client *http.Client, sink CloudEventSink, event CloudEvent) error {
+	event = withCloudEventDefaults(event)
+	endpoint := sink.URL
+	contentType := "application/cloudevents+json"
+	var payload any = event
+	if sink.Topic != "" {
+		endpoint = strings.TrimSuffix(sink.URL, "/") + "/topics/" + url.PathEscape(sink.Topic)
+		contentType = "application/vnd.kafka.json.v2+json"
+		payload = kafkaRecords{Records: []kafkaRecord{{Key: event.Source, Value: event}}}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode cloud event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create cloud event request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to publish cloud event: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("failed to publish cloud event: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPublishCloudEvent(t *testing.T) {
+	t.Parallel()
+
+	event := CloudEvent{
+		ID:      "1",
+		Source:  "/apis/backup.backup.io/v1alpha1/namespaces/default/clusterbackups/nightly",
+		Type:    CloudEventBackupCompleted,
+		Subject: "cluster-backup-20250101-000000.tar.gz",
+		Time:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Data:    map[string]string{"result": "success"},
+	}
+
+	tests := []struct {
+		name        string
+		topic       string
+		path        string
+		contentType string
+		decode      func(t *testing.T, body []byte) CloudEvent
+	}{
+		{
+			name:        "http",
+			path:        "/events",
+			contentType: "application/cloudevents+json",
+			decode: func(t *testing.T, body []byte) CloudEvent {
+				var got CloudEvent
+				if err := json.Unmarshal(body, &got); err != nil {
+					t.Fatal(err)
+				}
+				return got
+			},
+		},
+		{
+			name:        "kafka",
+			topic:       "backups",
+			path:        "/events/topics/backups",
+			contentType: "application/vnd.kafka.json.v2+json",
+			decode: func(t *testing.T, body []byte) CloudEvent {
+				var got kafkaRecords
+				if err := json.Unmarshal(body, &got); err != nil {
+					t.Fatal(err)
+				}
+				if len(got.Records) != 1 || got.Records[0].Key != event.Source {
+					t.Fatalf("records = %+v, want one keyed by the source", got.Records)
+				}
+				return got.Records[0].Value
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.path || r.Header.Get("Content-Type") != tt.contentType {
+					http.Error(w, "unexpected request", http.StatusBadRequest)
+					return
+				}
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer server.Close()
+
+			sink := CloudEventSink{URL: server.URL + "/events", Topic: tt.topic}
+			if err := PublishCloudEvent(context.Background(), server.Client(), sink, event); err != nil {
+				t.Fatalf("PublishCloudEvent: %v", err)
+			}
+			got := tt.decode(t, body)
+			if got.SpecVersion != "1.0" || got.Type != event.Type || got.Subject != event.Subject ||
+				got.DataContentType != "application/json" || !got.Time.Equal(event.Time) {
+				t.Errorf("event = %+v", got)
+			}
+		})
+	}
+}
+
+func TestPublishCloudEventReportsRejectedEvents(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	err := PublishCloudEvent(context.Background(), server.Client(), CloudEventSink{URL: server.URL}, CloudEvent{Type: CloudEventArchiveDeleted})
+	if err == nil {
+		t.Fatal("expected an error for a rejected event")
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression selects how the tar stream of an archive is compressed.
+type Compression string
+
+const (
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+	CompressionNone Compression = "none"
+)
+
+// Encryption formats recorded in the archive manifest.
+const (
+	encryptionFormatEnvelope = "envelope"
+	encryptionFormatAge      = "age"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// tarMagicOffset is where the "ustar" magic sits in a tar header block.
+const tarMagicOffset = 257
+
+// archiveExtensions lists the file extensions written for each compression.
+var archiveExtensions = map[Compression]string{
+	CompressionGzip: ".tar.gz",
+	CompressionZstd: ".tar.zst",
+	CompressionNone: ".tar",
+}
+
+// archiveExtension returns the file extension for archives compressed with c.
+func archiveExtension(c Compression) string {
+	if ext, ok := archiveExtensions[c]; ok {
+		return ext
+	}
+	return archiveExtensions[CompressionGzip]
+}
+
+// isArchiveName reports whether name is an archive written by CreateBackup.
+func isArchiveName(name string) bool {
+	if !strings.HasPrefix(name, "cluster-backup-") {
+		return false
+	}
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateArchiveName checks that name is the file name of an archive
+// written by CreateBackup.
+func ValidateArchiveName(name string) error {
+	if filepath.Base(name) != name || !isArchiveName(name) {
+		return fmt.Errorf("invalid archive name %q", name)
+	}
+	return nil
+}
+
+// newCompressor returns a writer compressing into w with c. Closing it
+// flushes the compressed stream but does not close w.
+func newCompressor(w io.Writer, c Compression) (io.WriteCloser, error) {
+	switch c {
+	case CompressionGzip, "":
+		return gzip.NewWriter(w), nil
+	case CompressionZstd:
+		zw, err := zstd.NewWriter(w)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zstd writer: %w", err)
+		}
+		return zw, nil
+	case CompressionNone:
+		return nopWriteCloser{w}, nil
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", c)
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// detectCompression identifies the compression of r from its leading bytes
+// without consuming them.
+func detectCompression(r *bufio.Reader) (Compression, error) {
+	if magic, err := r.Peek(len(zstdMagic)); err == nil && bytes.Equal(magic, zstdMagic) {
+		return CompressionZstd, nil
+	}
+	if magic, err := r.Peek(len(gzipMagic)); err == nil && bytes.Equal(magic, gzipMagic) {
+		return CompressionGzip, nil
+	}
+	if block, err := r.Peek(tarMagicOffset + 5); err == nil && string(block[tarMagicOffset:]) == "ustar" {
+		return CompressionNone, nil
+	}
+	return "", fmt.Errorf("unrecognized archive format")
+}
+
+// newDecompressor detects the compression of r and returns a reader yielding
+// the tar stream along with the detected compression.
+func newDecompressor(r *bufio.Reader) (io.ReadCloser, Compression, error) {
+	compression, err := detectCompression(r)
+	if err != nil {
+		return nil, "", err
+	}
+	switch compression {
+	case CompressionGzip:
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to open gzip reader: %w", err)
+		}
+		return gr, compression, nil
+	case CompressionZstd:
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to open zstd reader: %w", err)
+		}
+		return zr.IOReadCloser(), compression, nil
+	default:
+		return io.NopCloser(r), compression, nil
+	}
+}
+
+// encryptionFormat names the encryption stageArchive applies for wrappers.
+func encryptionFormat(wrappers []KeyWrapper) string {
+	if len(wrappers) == 0 {
+		return ""
+	}
+	if _, ok := ageRecipients(wrappers); ok {
+		return encryptionFormatAge
+	}
+	return encryptionFormatEnvelope
+}
+
+// detectEncryption names the encryption format of r from its leading bytes,
+// or returns "" when r is not encrypted.
+func detectEncryption(r *bufio.Reader) string {
+	switch {
+	case isEncrypted(r):
+		return encryptionFormatEnvelope
+	case isAgeEncrypted(r):
+		return encryptionFormatAge
+	default:
+		return ""
+	}
+}
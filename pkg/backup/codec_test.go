@@ -0,0 +1,64 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadArchiveDetectsCompression(t *testing.T) {
+	t.Parallel()
+
+	for _, compression := range []Compression{CompressionGzip, CompressionZstd, CompressionNone} {
+		var out bytes.Buffer
+		compressor, err := newCompressor(&out, compression)
+		if err != nil {
+			t.Fatalf("newCompressor(%s) failed: %v", compression, err)
+		}
+		archive := newArchiveWriter(compressor)
+		archive.compression = compression
+		if err := archive.writeObject("cluster/v1/namespaces/codec.json", map[string]interface{}{
+			"apiVersion": "v1", "kind": "Namespace",
+			"metadata": map[string]interface{}{"name": "codec"},
+		}); err != nil {
+			t.Fatalf("writeObject failed: %v", err)
+		}
+		if err := archive.Close(); err != nil {
+			t.Fatalf("failed closing archive: %v", err)
+		}
+		if err := compressor.Close(); err != nil {
+			t.Fatalf("failed closing %s stream: %v", compression, err)
+		}
+
+		name := "cluster-backup-20250101-000000" + archiveExtension(compression)
+		if !isArchiveName(name) {
+			t.Fatalf("expected %q to be recognised as an archive", name)
+		}
+		archivePath := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(archivePath, out.Bytes(), 0o644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+
+		cluster, _, err := readArchive(context.Background(), archivePath, RestoreOptions{}.keyWrappersFor(context.Background()))
+		if err != nil {
+			t.Fatalf("readArchive failed for %s: %v", compression, err)
+		}
+		if len(cluster) != 1 || cluster[0].name != "codec" || cluster[0].err != nil {
+			t.Fatalf("expected the %s archive to be read back, got %+v", compression, cluster)
+		}
+	}
+}
+
+func TestReadArchiveRejectsUnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	archivePath := filepath.Join(t.TempDir(), "cluster-backup-unknown.tar.gz")
+	if err := os.WriteFile(archivePath, bytes.Repeat([]byte("not an archive "), 64), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if _, _, err := readArchive(context.Background(), archivePath, RestoreOptions{}.keyWrappersFor(context.Background())); err == nil {
+		t.Fatalf("expected an unrecognized archive format to be rejected")
+	}
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ContentsOptions configures ListArchiveContents.
+type ContentsOptions struct {
+	// KeyWrappers decrypt encrypted archives, as for RestoreOptions.
+	KeyWrappers []KeyWrapper
+}
+
+// ArchiveContents is the tree of objects held by an archive. Sizes are the
+// bytes of the uncompressed JSON entries.
+type ArchiveContents struct {
+	Archive string `json:"archive"`
+	// FormatVersion is the format the archive was written in.
+	FormatVersion int   `json:"formatVersion"`
+	Objects       int   `json:"objects"`
+	Bytes         int64 `json:"bytes"`
+	// Cluster lists the cluster-scoped resource types.
+	Cluster    []ArchivedResourceType `json:"cluster,omitempty"`
+	Namespaces []ArchivedNamespace    `json:"namespaces,omitempty"`
+}
+
+// ArchivedNamespace lists the resource types archived in a namespace.
+type ArchivedNamespace struct {
+	Name      string                 `json:"name"`
+	Objects   int                    `json:"objects"`
+	Bytes     int64                  `json:"bytes"`
+	Resources []ArchivedResourceType `json:"resources"`
+}
+
+// ArchivedResourceType lists the archived objects of a resource type.
+type ArchivedResourceType struct {
+	Group    string           `json:"group,omitempty"`
+	Version  string           `json:"version"`
+	Resource string           `json:"resource"`
+	Bytes    int64            `json:"bytes"`
+	Objects  []ArchivedObject `json:"objects"`
+}
+
+// ArchivedObject is a single archived object.
+type ArchivedObject struct {
+	Name  string `json:"name"`
+	Bytes int64  `json:"bytes"`
+}
+
+// ListArchiveContents returns the objects held by storagePath/archiveName,
+// or by the archive URL archiveName, without decoding them. The entries are
+// taken from the manifest, including those of an incremental archive held
+// by its base, and from the archive itself when it has no manifest.
+func (bm *BackupManager) ListArchiveContents(ctx context.Context, storagePath, archiveName string, opts ContentsOptions) (*ArchiveContents, error) {
+	if !IsArchiveURL(archiveName) {
+		if err := ValidateArchiveName(archiveName); err != nil {
+			return nil, err
+		}
+	}
+	resolve := RestoreOptions{KeyWrappers: opts.KeyWrappers}.keyWrappersFor(ctx)
+	sizes, manifest, name, err := bm.readArchiveIndex(ctx, storagePath, archiveName, resolve)
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := archiveFormatOf(manifest)
+	if err != nil {
+		return nil, err
+	}
+	entries := slices.Collect(maps.Keys(sizes))
+	if manifest != nil {
+		entries = slices.Collect(maps.Keys(manifest.Files))
+		if manifest.Base != "" {
+			baseSizes, _, _, err := bm.readArchiveIndex(ctx, storagePath, manifest.Base, resolve)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read base archive %s: %w", manifest.Base, err)
+			}
+			for _, entry := range entries {
+				if _, ok := sizes[entry]; !ok {
+					sizes[entry] = baseSizes[entry]
+				}
+			}
+		}
+	}
+	contents, err := buildArchiveContents(name, entries, sizes, format)
+	if err != nil {
+		return nil, err
+	}
+	contents.FormatVersion = formatVersion(manifest)
+	return contents, nil
+}
+
+// readArchiveIndex returns the size of every JSON entry of the named archive
+// along with its manifest, skipping over the entry contents.
+func (bm *BackupManager) readArchiveIndex(ctx context.Context, storagePath, archiveName string, resolve func(WrappedKey) []KeyWrapper) (map[string]int64, *archiveManifest, string, error) {
+	source, name, err := bm.openArchive(ctx, storagePath, archiveName)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	defer source.Close()
+
+	compressed, err := decryptArchive(ctx, bufio.NewReader(source), resolve)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to decrypt archive: %w", err)
+	}
+	tarStream, _, err := newDecompressor(bufio.NewReader(compressed))
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to open archive %q: %w", name, err)
+	}
+	defer tarStream.Close()
+
+	sizes := map[string]int64{}
+	var manifest *archiveManifest
+	tarReader := tar.NewReader(tarStream)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return sizes, manifest, name, nil
+		}
+		if err != nil {
+			return nil, nil, "", fmt.Errorf("failed to read archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg || !strings.HasSuffix(header.Name, ".json") {
+			continue
+		}
+		if header.Name != manifestName {
+			sizes[header.Name] = header.Size
+			continue
+		}
+		manifest = &archiveManifest{}
+		if err := json.NewDecoder(tarReader).Decode(manifest); err != nil {
+			return nil, nil, "", fmt.Errorf("failed to unmarshal manifest: %w", err)
+		}
+	}
+}
+
+// buildArchiveContents arranges entries into a tree sorted by namespace,
+// resource type and name.
+func buildArchiveContents(archiveName string, entries []string, sizes map[string]int64, format archiveFormat) (*ArchiveContents, error) {
+	type scope struct {
+		objects   int
+		bytes     int64
+		resources map[schema.GroupVersionResource]*ArchivedResourceType
+	}
+	scopes := map[string]*scope{}
+	contents := &ArchiveContents{Archive: archiveName}
+	for _, entry := range entries {
+		gvr, namespace, name, err := format.parseEntry(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse archive entry %q: %w", entry, err)
+		}
+		s, ok := scopes[namespace]
+		if !ok {
+			s = &scope{resources: map[schema.GroupVersionResource]*ArchivedResourceType{}}
+			scopes[namespace] = s
+		}
+		resourceType, ok := s.resources[gvr]
+		if !ok {
+			resourceType = &ArchivedResourceType{Group: gvr.Group, Version: gvr.Version, Resource: gvr.Resource}
+			s.resources[gvr] = resourceType
+		}
+		size := sizes[entry]
+		resourceType.Objects = append(resourceType.Objects, ArchivedObject{Name: name, Bytes: size})
+		resourceType.Bytes += size
+		s.objects++
+		s.bytes += size
+		contents.Objects++
+		contents.Bytes += size
+	}
+
+	sortedTypes := func(resources map[schema.GroupVersionResource]*ArchivedResourceType) []ArchivedResourceType {
+		list := make([]ArchivedResourceType, 0, len(resources))
+		for _, resourceType := range resources {
+			slices.SortFunc(resourceType.Objects, func(a, b ArchivedObject) int { return strings.Compare(a.Name, b.Name) })
+			list = append(list, *resourceType)
+		}
+		slices.SortFunc(list, func(a, b ArchivedResourceType) int {
+			return strings.Compare(a.Group+"/"+a.Version+"/"+a.Resource, b.Group+"/"+b.Version+"/"+b.Resource)
+		})
+		return list
+	}
+	for _, namespace := range slices.Sorted(maps.Keys(scopes)) {
+		s := scopes[namespace]
+		if namespace == "" {
+			contents.Cluster = sortedTypes(s.resources)
+			continue
+		}
+		contents.Namespaces = append(contents.Namespaces, ArchivedNamespace{
+			Name:      namespace,
+			Objects:   s.objects,
+			Bytes:     s.bytes,
+			Resources: sortedTypes(s.resources),
+		})
+	}
+	return contents, nil
+}
@@ -0,0 +1,65 @@
+package backup
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestListArchiveContents(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storageDir := t.TempDir()
+	bm := &BackupManager{}
+
+	baseName := "cluster-backup-20260101-000000.tar.gz"
+	writeConfigMapArchive(t, filepath.Join(storageDir, baseName), nil,
+		incrementalConfigMap("stable", "1", "kept"), incrementalConfigMap("changed", "1", "old"))
+	base, err := bm.loadIncrementalBase(ctx, storageDir, IncrementalOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	incrementalName := "cluster-backup-20260101-010000.tar.gz"
+	writeConfigMapArchive(t, filepath.Join(storageDir, incrementalName), base,
+		incrementalConfigMap("stable", "1", "kept"), incrementalConfigMap("changed", "2", "new"))
+
+	// The unchanged ConfigMap is only held by the base
+	contents, err := bm.ListArchiveContents(ctx, storageDir, incrementalName, ContentsOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contents.Objects != 2 || len(contents.Cluster) != 0 || len(contents.Namespaces) != 1 {
+		t.Fatalf("got %+v", contents)
+	}
+	namespace := contents.Namespaces[0]
+	if namespace.Name != "app" || len(namespace.Resources) != 1 || namespace.Resources[0].Resource != "configmaps" {
+		t.Fatalf("got %+v", namespace)
+	}
+	objects := namespace.Resources[0].Objects
+	if len(objects) != 2 || objects[0].Name != "changed" || objects[1].Name != "stable" {
+		t.Fatalf("got %+v", objects)
+	}
+	for _, object := range objects {
+		if object.Bytes == 0 {
+			t.Fatalf("object %s has no size", object.Name)
+		}
+	}
+	if contents.Bytes != objects[0].Bytes+objects[1].Bytes || namespace.Bytes != contents.Bytes {
+		t.Fatalf("sizes do not add up: %+v", contents)
+	}
+
+	legacyName := "cluster-backup-20260101-020000.tar.gz"
+	writeRestoreArchive(t, filepath.Join(storageDir, legacyName))
+	contents, err = bm.ListArchiveContents(ctx, storageDir, legacyName, ContentsOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contents.Cluster) != 1 || contents.Cluster[0].Resource != "namespaces" || len(contents.Namespaces) != 1 {
+		t.Fatalf("got %+v", contents)
+	}
+
+	if _, err := bm.ListArchiveContents(ctx, storageDir, "../"+legacyName, ContentsOptions{}); err == nil {
+		t.Fatal("expected an invalid archive name to be rejected")
+	}
+}
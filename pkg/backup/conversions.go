@@ -0,0 +1,284 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// apiConversion moves archived objects from a removed API version to the
+// version that replaced it. convert, when set, rewrites the fields that
+// differ between the two; nil means only apiVersion changes.
+type apiConversion struct {
+	target  schema.GroupVersion
+	convert func(obj map[string]interface{}) error
+}
+
+var (
+	networkingV1  = schema.GroupVersion{Group: "networking.k8s.io", Version: "v1"}
+	appsV1        = schema.GroupVersion{Group: "apps", Version: "v1"}
+	rbacV1        = schema.GroupVersion{Group: "rbac.authorization.k8s.io", Version: "v1"}
+	storageV1     = schema.GroupVersion{Group: "storage.k8s.io", Version: "v1"}
+	autoscalingV2 = schema.GroupVersion{Group: "autoscaling", Version: "v2"}
+)
+
+// apiConversions lists the mechanical conversions from deprecated API
+// versions, keyed by the archived version.
+var apiConversions = map[schema.GroupVersionResource]apiConversion{
+	{Group: "extensions", Version: "v1beta1", Resource: "ingresses"}:                          {networkingV1, convertIngressToV1},
+	{Group: "networking.k8s.io", Version: "v1beta1", Resource: "ingresses"}:                   {networkingV1, convertIngressToV1},
+	{Group: "networking.k8s.io", Version: "v1beta1", Resource: "ingressclasses"}:              {networkingV1, nil},
+	{Group: "extensions", Version: "v1beta1", Resource: "networkpolicies"}:                    {networkingV1, nil},
+	{Group: "extensions", Version: "v1beta1", Resource: "deployments"}:                        {appsV1, convertWorkloadToAppsV1},
+	{Group: "extensions", Version: "v1beta1", Resource: "daemonsets"}:                         {appsV1, convertExtensionsDaemonSet},
+	{Group: "extensions", Version: "v1beta1", Resource: "replicasets"}:                        {appsV1, convertWorkloadToAppsV1},
+	{Group: "apps", Version: "v1beta1", Resource: "deployments"}:                              {appsV1, convertWorkloadToAppsV1},
+	{Group: "apps", Version: "v1beta1", Resource: "statefulsets"}:                             {appsV1, convertWorkloadToAppsV1},
+	{Group: "apps", Version: "v1beta2", Resource: "deployments"}:                              {appsV1, convertWorkloadToAppsV1},
+	{Group: "apps", Version: "v1beta2", Resource: "statefulsets"}:                             {appsV1, convertWorkloadToAppsV1},
+	{Group: "apps", Version: "v1beta2", Resource: "daemonsets"}:                               {appsV1, convertWorkloadToAppsV1},
+	{Group: "apps", Version: "v1beta2", Resource: "replicasets"}:                              {appsV1, convertWorkloadToAppsV1},
+	{Group: "batch", Version: "v1beta1", Resource: "cronjobs"}:                                {schema.GroupVersion{Group: "batch", Version: "v1"}, nil},
+	{Group: "policy", Version: "v1beta1", Resource: "poddisruptionbudgets"}:                   {schema.GroupVersion{Group: "policy", Version: "v1"}, convertPodDisruptionBudgetToV1},
+	{Group: "autoscaling", Version: "v2beta1", Resource: "horizontalpodautoscalers"}:          {autoscalingV2, convertHorizontalPodAutoscalerToV2},
+	{Group: "autoscaling", Version: "v2beta2", Resource: "horizontalpodautoscalers"}:          {autoscalingV2, nil},
+	{Group: "discovery.k8s.io", Version: "v1beta1", Resource: "endpointslices"}:               {schema.GroupVersion{Group: "discovery.k8s.io", Version: "v1"}, convertEndpointSliceToV1},
+	{Group: "scheduling.k8s.io", Version: "v1beta1", Resource: "priorityclasses"}:             {schema.GroupVersion{Group: "scheduling.k8s.io", Version: "v1"}, nil},
+	{Group: "coordination.k8s.io", Version: "v1beta1", Resource: "leases"}:                    {schema.GroupVersion{Group: "coordination.k8s.io", Version: "v1"}, nil},
+	{Group: "storage.k8s.io", Version: "v1beta1", Resource: "storageclasses"}:                 {storageV1, nil},
+	{Group: "storage.k8s.io", Version: "v1beta1", Resource: "csidrivers"}:                     {storageV1, nil},
+	{Group: "rbac.authorization.k8s.io", Version: "v1beta1", Resource: "roles"}:               {rbacV1, nil},
+	{Group: "rbac.authorization.k8s.io", Version: "v1beta1", Resource: "rolebindings"}:        {rbacV1, nil},
+	{Group: "rbac.authorization.k8s.io", Version: "v1beta1", Resource: "clusterroles"}:        {rbacV1, nil},
+	{Group: "rbac.authorization.k8s.io", Version: "v1beta1", Resource: "clusterrolebindings"}: {rbacV1, nil},
+}
+
+// convertAPIVersion returns a copy of res converted by conversion; res
+// itself is left untouched.
+func convertAPIVersion(res archivedResource, conversion apiConversion) (archivedResource, error) {
+	obj := (&unstructured.Unstructured{Object: res.object}).DeepCopy()
+	if conversion.convert != nil {
+		if err := conversion.convert(obj.Object); err != nil {
+			return res, fmt.Errorf("failed to convert %s to %s: %w", res.gvr.GroupVersion(), conversion.target, err)
+		}
+	}
+	obj.SetAPIVersion(conversion.target.String())
+
+	res.gvr = conversion.target.WithResource(res.gvr.Resource)
+	res.object = obj.Object
+	return res, nil
+}
+
+// convertIngressToV1 rewrites the backends of a v1beta1 Ingress in the
+// networking.k8s.io/v1 layout and sets the pathType v1 requires.
+func convertIngressToV1(obj map[string]interface{}) error {
+	if backend, ok, _ := unstructured.NestedMap(obj, "spec", "backend"); ok {
+		unstructured.RemoveNestedField(obj, "spec", "backend")
+		if err := unstructured.SetNestedMap(obj, ingressBackendToV1(backend), "spec", "defaultBackend"); err != nil {
+			return err
+		}
+	}
+
+	return updateNestedMaps(obj, []string{"spec", "rules"}, func(rule map[string]interface{}) error {
+		return updateNestedMaps(rule, []string{"http", "paths"}, func(path map[string]interface{}) error {
+			if _, ok := path["pathType"]; !ok {
+				path["pathType"] = "ImplementationSpecific"
+			}
+			if backend, ok := path["backend"].(map[string]interface{}); ok {
+				path["backend"] = ingressBackendToV1(backend)
+			}
+			return nil
+		})
+	})
+}
+
+// ingressBackendToV1 turns a serviceName/servicePort backend into a service
+// backend; resource backends are unchanged.
+func ingressBackendToV1(backend map[string]interface{}) map[string]interface{} {
+	name, ok := backend["serviceName"].(string)
+	if !ok {
+		return backend
+	}
+	port := map[string]interface{}{}
+	switch p := backend["servicePort"].(type) {
+	case string:
+		port["name"] = p
+	case int64:
+		port["number"] = p
+	case float64:
+		port["number"] = int64(p)
+	}
+	return map[string]interface{}{
+		"service": map[string]interface{}{"name": name, "port": port},
+	}
+}
+
+// convertWorkloadToAppsV1 sets the selector apps/v1 requires from the pod
+// template labels, as the beta versions defaulted it, and drops the fields
+// apps/v1 removed.
+func convertWorkloadToAppsV1(obj map[string]interface{}) error {
+	if _, ok, _ := unstructured.NestedFieldNoCopy(obj, "spec", "selector"); !ok {
+		labels, ok, err := unstructured.NestedMap(obj, "spec", "template", "metadata", "labels")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("spec.selector is unset and the pod template has no labels to default it from")
+		}
+		if err := unstructured.SetNestedMap(obj, labels, "spec", "selector", "matchLabels"); err != nil {
+			return err
+		}
+	}
+	unstructured.RemoveNestedField(obj, "spec", "rollbackTo")
+	unstructured.RemoveNestedField(obj, "spec", "templateGeneration")
+	return nil
+}
+
+// convertExtensionsDaemonSet keeps the OnDelete update strategy that
+// extensions/v1beta1 defaulted to, where apps/v1 defaults to RollingUpdate.
+func convertExtensionsDaemonSet(obj map[string]interface{}) error {
+	if _, ok, _ := unstructured.NestedFieldNoCopy(obj, "spec", "updateStrategy"); !ok {
+		if err := unstructured.SetNestedField(obj, "OnDelete", "spec", "updateStrategy", "type"); err != nil {
+			return err
+		}
+	}
+	return convertWorkloadToAppsV1(obj)
+}
+
+// convertPodDisruptionBudgetToV1 drops an empty selector, which selects no
+// pods in policy/v1beta1 but every pod of the namespace in policy/v1. A
+// missing selector selects no pods in both.
+func convertPodDisruptionBudgetToV1(obj map[string]interface{}) error {
+	selector, ok, err := unstructured.NestedMap(obj, "spec", "selector")
+	if err != nil {
+		return err
+	}
+	if ok && len(selector) == 0 {
+		unstructured.RemoveNestedField(obj, "spec", "selector")
+	}
+	return nil
+}
+
+// convertHorizontalPodAutoscalerToV2 moves the metric targets of an
+// autoscaling/v2beta1 HorizontalPodAutoscaler into the target and metric
+// identifier structs of autoscaling/v2.
+func convertHorizontalPodAutoscalerToV2(obj map[string]interface{}) error {
+	return updateNestedMaps(obj, []string{"spec", "metrics"}, func(metric map[string]interface{}) error {
+		for _, sourceType := range []string{"resource", "containerResource", "pods", "object", "external"} {
+			source, ok := metric[sourceType].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			metric[sourceType] = metricSourceToV2(source)
+		}
+		return nil
+	})
+}
+
+// metricSourceToV2 converts a single v2beta1 metric source.
+func metricSourceToV2(source map[string]interface{}) map[string]interface{} {
+	converted := map[string]interface{}{}
+	for _, field := range []string{"name", "container"} {
+		if value, ok := source[field]; ok {
+			converted[field] = value
+		}
+	}
+	if target, ok := source["target"]; ok {
+		// Object metrics named the object they describe "target"
+		converted["describedObject"] = target
+	}
+
+	if name, ok := source["metricName"]; ok {
+		identifier := map[string]interface{}{"name": name}
+		for _, field := range []string{"selector", "metricSelector"} {
+			if selector, ok := source[field]; ok {
+				identifier["selector"] = selector
+			}
+		}
+		converted["metric"] = identifier
+	}
+
+	target := map[string]interface{}{}
+	switch {
+	case source["targetAverageUtilization"] != nil:
+		target["type"] = "Utilization"
+		target["averageUtilization"] = source["targetAverageUtilization"]
+	case source["targetAverageValue"] != nil:
+		target["type"] = "AverageValue"
+		target["averageValue"] = source["targetAverageValue"]
+	case source["averageValue"] != nil:
+		target["type"] = "AverageValue"
+		target["averageValue"] = source["averageValue"]
+	case source["targetValue"] != nil:
+		target["type"] = "Value"
+		target["value"] = source["targetValue"]
+	}
+	if len(target) > 0 {
+		converted["target"] = target
+	}
+	return converted
+}
+
+// convertEndpointSliceToV1 moves endpoint topology to the fields that
+// replaced it in discovery.k8s.io/v1, keeping the rest as deprecatedTopology.
+func convertEndpointSliceToV1(obj map[string]interface{}) error {
+	return updateNestedMaps(obj, []string{"endpoints"}, func(endpoint map[string]interface{}) error {
+		topology, ok := endpoint["topology"].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		delete(endpoint, "topology")
+		if node, ok := topology["kubernetes.io/hostname"]; ok {
+			if _, set := endpoint["nodeName"]; !set {
+				endpoint["nodeName"] = node
+			}
+			delete(topology, "kubernetes.io/hostname")
+		}
+		if zone, ok := topology["topology.kubernetes.io/zone"]; ok {
+			endpoint["zone"] = zone
+			delete(topology, "topology.kubernetes.io/zone")
+		}
+		if len(topology) > 0 {
+			endpoint["deprecatedTopology"] = topology
+		}
+		return nil
+	})
+}
+
+// updateNestedMaps calls update on every object in the list at fields of
+// obj, skipping entries that are not objects.
+func updateNestedMaps(obj map[string]interface{}, fields []string, update func(map[string]interface{}) error) error {
+	items, ok, err := unstructured.NestedFieldNoCopy(obj, fields...)
+	if err != nil || !ok {
+		return err
+	}
+	list, ok := items.([]interface{})
+	if !ok {
+		return fmt.Errorf("%v is not a list", fields)
+	}
+	for _, item := range list {
+		if item, ok := item.(map[string]interface{}); ok {
+			if err := update(item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
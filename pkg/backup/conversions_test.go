@@ -0,0 +1,135 @@
+package backup
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestConvertAPIVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		gvr   schema.GroupVersionResource
+		spec  map[string]interface{}
+		check func(t *testing.T, spec map[string]interface{})
+	}{
+		{
+			name: "cronjob",
+			gvr:  schema.GroupVersionResource{Group: "batch", Version: "v1beta1", Resource: "cronjobs"},
+			spec: map[string]interface{}{"schedule": "0 1 * * *"},
+			check: func(t *testing.T, spec map[string]interface{}) {
+				if spec["schedule"] != "0 1 * * *" {
+					t.Fatalf("spec = %v, want it unchanged", spec)
+				}
+			},
+		},
+		{
+			name: "empty disruption budget selector",
+			gvr:  schema.GroupVersionResource{Group: "policy", Version: "v1beta1", Resource: "poddisruptionbudgets"},
+			spec: map[string]interface{}{"minAvailable": int64(1), "selector": map[string]interface{}{}},
+			check: func(t *testing.T, spec map[string]interface{}) {
+				if _, ok := spec["selector"]; ok {
+					t.Fatalf("spec = %v, want the empty selector dropped", spec)
+				}
+			},
+		},
+		{
+			name: "deployment without selector",
+			gvr:  schema.GroupVersionResource{Group: "extensions", Version: "v1beta1", Resource: "deployments"},
+			spec: map[string]interface{}{
+				"rollbackTo": map[string]interface{}{"revision": int64(2)},
+				"template":   map[string]interface{}{"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "web"}}},
+			},
+			check: func(t *testing.T, spec map[string]interface{}) {
+				labels, _, _ := unstructured.NestedStringMap(spec, "selector", "matchLabels")
+				if labels["app"] != "web" {
+					t.Fatalf("selector = %v, want it defaulted from the template labels", spec["selector"])
+				}
+				if _, ok := spec["rollbackTo"]; ok {
+					t.Fatalf("spec = %v, want rollbackTo dropped", spec)
+				}
+			},
+		},
+		{
+			name: "extensions daemonset",
+			gvr:  schema.GroupVersionResource{Group: "extensions", Version: "v1beta1", Resource: "daemonsets"},
+			spec: map[string]interface{}{"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"app": "agent"}}},
+			check: func(t *testing.T, spec map[string]interface{}) {
+				if strategy, _, _ := unstructured.NestedString(spec, "updateStrategy", "type"); strategy != "OnDelete" {
+					t.Fatalf("updateStrategy = %q, want OnDelete", strategy)
+				}
+			},
+		},
+		{
+			name: "autoscaler metrics",
+			gvr:  schema.GroupVersionResource{Group: "autoscaling", Version: "v2beta1", Resource: "horizontalpodautoscalers"},
+			spec: map[string]interface{}{"metrics": []interface{}{
+				map[string]interface{}{"type": "Resource", "resource": map[string]interface{}{"name": "cpu", "targetAverageUtilization": int64(80)}},
+				map[string]interface{}{"type": "External", "external": map[string]interface{}{"metricName": "queue", "targetValue": "10"}},
+			}},
+			check: func(t *testing.T, spec map[string]interface{}) {
+				metrics := spec["metrics"].([]interface{})
+				want := map[string]interface{}{"name": "cpu", "target": map[string]interface{}{"type": "Utilization", "averageUtilization": int64(80)}}
+				if got := metrics[0].(map[string]interface{})["resource"]; !reflect.DeepEqual(got, want) {
+					t.Fatalf("resource metric = %v, want %v", got, want)
+				}
+				want = map[string]interface{}{"metric": map[string]interface{}{"name": "queue"}, "target": map[string]interface{}{"type": "Value", "value": "10"}}
+				if got := metrics[1].(map[string]interface{})["external"]; !reflect.DeepEqual(got, want) {
+					t.Fatalf("external metric = %v, want %v", got, want)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := archived(tt.gvr.Group, tt.gvr.Version, tt.gvr.Resource, &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": tt.gvr.GroupVersion().String(),
+				"metadata":   map[string]interface{}{"name": "example", "namespace": "apps"},
+				"spec":       tt.spec,
+			}})
+			conversion, ok := apiConversions[tt.gvr]
+			if !ok {
+				t.Fatalf("no conversion for %s", tt.gvr)
+			}
+			converted, err := convertAPIVersion(res, conversion)
+			if err != nil {
+				t.Fatalf("convertAPIVersion: %v", err)
+			}
+			if converted.gvr != conversion.target.WithResource(tt.gvr.Resource) || converted.object["apiVersion"] != conversion.target.String() {
+				t.Fatalf("converted to %s (%v), want %s", converted.gvr, converted.object["apiVersion"], conversion.target)
+			}
+			tt.check(t, converted.object["spec"].(map[string]interface{}))
+		})
+	}
+}
+
+func TestConvertEndpointSliceToV1(t *testing.T) {
+	t.Parallel()
+
+	obj := map[string]interface{}{"endpoints": []interface{}{map[string]interface{}{
+		"addresses": []interface{}{"10.0.0.1"},
+		"topology": map[string]interface{}{
+			"kubernetes.io/hostname":      "node-a",
+			"topology.kubernetes.io/zone": "zone-1",
+			"example.com/rack":            "r1",
+		},
+	}}}
+	if err := convertEndpointSliceToV1(obj); err != nil {
+		t.Fatalf("convertEndpointSliceToV1: %v", err)
+	}
+	endpoint := obj["endpoints"].([]interface{})[0].(map[string]interface{})
+	want := map[string]interface{}{
+		"addresses":          []interface{}{"10.0.0.1"},
+		"nodeName":           "node-a",
+		"zone":               "zone-1",
+		"deprecatedTopology": map[string]interface{}{"example.com/rack": "r1"},
+	}
+	if !reflect.DeepEqual(endpoint, want) {
+		t.Fatalf("endpoint = %v, want %v", endpoint, want)
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// ErrArchiveProtected is returned when an archive cannot be deleted because
+// it is pinned or is the base of an incremental archive.
+var ErrArchiveProtected = errors.New("archive is protected")
+
+// DeleteArchive removes the named archive from storagePath along with its
+// sidecar files. Pinned, immutable and in-use archives and the bases of
+// incremental archives are refused. A missing archive returns an error
+// wrapping os.ErrNotExist.
+func (bm *BackupManager) DeleteArchive(ctx context.Context, storagePath, archiveName string) error {
+	if err := ValidateArchiveName(archiveName); err != nil {
+		return err
+	}
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return err
+	}
+	archivePath := filepath.Join(resolvedStoragePath, archiveName)
+	if _, err := os.Stat(archivePath); err != nil {
+		return fmt.Errorf("failed to find archive: %w", err)
+	}
+
+	if isPinned(archivePath) {
+		return fmt.Errorf("%w: %s is pinned", ErrArchiveProtected, archiveName)
+	}
+	if _, ok := referencedBases(resolvedStoragePath)[archiveName]; ok {
+		return fmt.Errorf("%w: %s is the base of an incremental archive", ErrArchiveProtected, archiveName)
+	}
+	now := time.Now()
+	if err := checkArchiveMutable(archivePath, now); err != nil {
+		return err
+	}
+	if bm.isReferenced(archivePath, now) {
+		return fmt.Errorf("%w: %s", ErrArchiveInUse, archiveName)
+	}
+
+	if err := removeArchive(archivePath); err != nil {
+		return fmt.Errorf("failed to delete archive: %w", err)
+	}
+	ctrl.LoggerFrom(ctx).Info("Deleted archive", "archive", archiveName, "storagePath", storagePath)
+	return nil
+}
@@ -0,0 +1,71 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDeleteArchive(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	names := []string{
+		"cluster-backup-20250101-000000.tar.gz", // pinned
+		"cluster-backup-20250102-000000.tar.gz", // base of the next one
+		"cluster-backup-20250103-000000.tar.gz", // incremental
+		"cluster-backup-20250104-000000.tar.gz", // legal hold
+		"cluster-backup-20250105-000000.tar.gz", // being restored
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, names[0]+keepSuffix), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeBaseMarker(filepath.Join(dir, names[2]), names[1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := lockArchive(filepath.Join(dir, names[3]), Immutability{LegalHold: true}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	bm := &BackupManager{}
+	bm.SetRestoreGracePeriod(time.Hour)
+	release := bm.referenceArchive(dir, names[4])
+	defer release()
+
+	for _, tc := range []struct {
+		archive string
+		want    error
+	}{
+		{names[0], ErrArchiveProtected},
+		{names[1], ErrArchiveProtected},
+		{names[3], ErrArchiveImmutable},
+		{names[4], ErrArchiveInUse},
+		{"cluster-backup-20250106-000000.tar.gz", os.ErrNotExist},
+	} {
+		if err := bm.DeleteArchive(ctx, dir, tc.archive); !errors.Is(err, tc.want) {
+			t.Fatalf("DeleteArchive(%s) = %v, want %v", tc.archive, err, tc.want)
+		}
+	}
+	if err := bm.DeleteArchive(ctx, dir, "../"+names[2]); err == nil {
+		t.Fatal("expected an invalid archive name to be refused")
+	}
+
+	// Deleting the incremental archive releases its base
+	if err := bm.DeleteArchive(ctx, dir, names[2]); err != nil {
+		t.Fatalf("DeleteArchive: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, names[2]+baseSuffix)); !os.IsNotExist(err) {
+		t.Fatalf("base marker left behind: %v", err)
+	}
+	if err := bm.DeleteArchive(ctx, dir, names[1]); err != nil {
+		t.Fatalf("DeleteArchive: %v", err)
+	}
+}
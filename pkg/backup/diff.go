@@ -0,0 +1,295 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	jsonpatch "gopkg.in/evanphx/json-patch.v4"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// DriftState describes how a live object differs from its archived copy.
+type DriftState string
+
+const (
+	// DriftMissing is an archived object absent from the cluster.
+	DriftMissing DriftState = "Missing"
+	// DriftChanged is an object whose live content differs from the archive.
+	DriftChanged DriftState = "Changed"
+	// DriftExtra is a live object of an archived namespaced type, in an
+	// archived namespace, that the archive does not hold.
+	DriftExtra DriftState = "Extra"
+)
+
+// MaxReportedDrift caps how many objects a DiffResult lists.
+const MaxReportedDrift = 100
+
+// DiffOptions contains configuration for comparing an archive with the
+// cluster.
+type DiffOptions struct {
+	// KeyWrappers decrypt encrypted archives, as for RestoreOptions.
+	KeyWrappers []KeyWrapper
+
+	// IgnoreExtra skips listing the cluster for objects the archive does
+	// not hold, for archives of selected objects rather than whole
+	// namespaces.
+	IgnoreExtra bool
+}
+
+// ObjectDrift is one object that differs between the archive and the
+// cluster.
+type ObjectDrift struct {
+	GVR       schema.GroupVersionResource
+	Namespace string
+	Name      string
+	State     DriftState
+	// Diff is the JSON merge patch turning the archived object into the
+	// live one, for changed objects.
+	Diff string
+}
+
+func (d ObjectDrift) String() string {
+	item := d.Name
+	if d.Namespace != "" {
+		item = d.Namespace + "/" + d.Name
+	}
+	return fmt.Sprintf("%s %s %s", d.State, d.GVR.GroupResource(), item)
+}
+
+// DiffResult compares an archive with the cluster.
+type DiffResult struct {
+	Unchanged int
+	Missing   int
+	Changed   int
+	Extra     int
+	// Items lists the drifted objects, capped at MaxReportedDrift.
+	Items []ObjectDrift
+}
+
+// Drifted reports whether any object differs.
+func (r *DiffResult) Drifted() bool {
+	return r.Missing+r.Changed+r.Extra > 0
+}
+
+func (r *DiffResult) add(drift ObjectDrift) {
+	switch drift.State {
+	case DriftMissing:
+		r.Missing++
+	case DriftChanged:
+		r.Changed++
+	case DriftExtra:
+		r.Extra++
+	}
+	if len(r.Items) < MaxReportedDrift {
+		r.Items = append(r.Items, drift)
+	}
+}
+
+func (r *DiffResult) String() string {
+	return fmt.Sprintf("%d missing, %d changed, %d extra, %d unchanged", r.Missing, r.Changed, r.Extra, r.Unchanged)
+}
+
+// DiffArchive compares every object in the archive at
+// storagePath/archiveName with its live counterpart. Unless
+// opts.IgnoreExtra is set, live objects of the archived namespaced types in
+// the archived namespaces that the archive does not hold are reported too;
+// cluster-scoped objects are never extra, since archives usually hold only
+// some of them.
+// Runtime fields and status are ignored on both sides, as are resources
+// the cluster generates itself.
+func (bm *BackupManager) DiffArchive(ctx context.Context, storagePath, archiveName string, opts DiffOptions) (*DiffResult, error) {
+	if archiveName == "" {
+		return nil, fmt.Errorf("archive name must be provided")
+	}
+	log := ctrl.LoggerFrom(ctx)
+
+	clusterResources, namespacedResources, _, err := bm.readStoredArchive(ctx, storagePath, archiveName,
+		RestoreOptions{KeyWrappers: opts.KeyWrappers}.keyWrappersFor(ctx), "")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DiffResult{}
+	// archived records, per type and namespace, the names the archive holds
+	archived := map[schema.GroupVersionResource]map[string]map[string]struct{}{}
+	for _, res := range append(clusterResources, namespacedResources...) {
+		if res.err != nil {
+			log.Info("Skipping archive entry that failed verification", "gvr", res.gvr, "namespace", res.namespace, "name", res.name, "error", res.err.Error())
+			continue
+		}
+		if isClusterGenerated(res.object) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		name := (&unstructured.Unstructured{Object: res.object}).GetName()
+		if archived[res.gvr] == nil {
+			archived[res.gvr] = map[string]map[string]struct{}{}
+		}
+		if archived[res.gvr][res.namespace] == nil {
+			archived[res.gvr][res.namespace] = map[string]struct{}{}
+		}
+		archived[res.gvr][res.namespace][name] = struct{}{}
+
+		drift := ObjectDrift{GVR: res.gvr, Namespace: res.namespace, Name: name}
+		live, err := bm.resourceClientFor(res).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			drift.State = DriftMissing
+			result.add(drift)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s %s: %w", res.gvr.GroupResource(), name, err)
+		}
+
+		diff, err := objectDiff(res.object, live.Object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare %s %s: %w", res.gvr.GroupResource(), name, err)
+		}
+		if diff == "" {
+			result.Unchanged++
+			continue
+		}
+		drift.State = DriftChanged
+		drift.Diff = diff
+		result.add(drift)
+	}
+
+	if !opts.IgnoreExtra {
+		if err := bm.addExtraObjects(ctx, archived, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// addExtraObjects records the live objects of every archived namespaced
+// type and namespace that the archive does not hold.
+func (bm *BackupManager) addExtraObjects(ctx context.Context, archived map[schema.GroupVersionResource]map[string]map[string]struct{}, result *DiffResult) error {
+	gvrs := make([]schema.GroupVersionResource, 0, len(archived))
+	for gvr := range archived {
+		gvrs = append(gvrs, gvr)
+	}
+	sort.Slice(gvrs, func(i, j int) bool { return gvrs[i].String() < gvrs[j].String() })
+
+	for _, gvr := range gvrs {
+		namespaces := make([]string, 0, len(archived[gvr]))
+		for namespace := range archived[gvr] {
+			if namespace == "" {
+				continue
+			}
+			namespaces = append(namespaces, namespace)
+		}
+		sort.Strings(namespaces)
+
+		for _, namespace := range namespaces {
+			list, err := bm.resourceClientFor(archivedResource{gvr: gvr, namespace: namespace}).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to list %s: %w", gvr.GroupResource(), err)
+			}
+			for _, item := range list.Items {
+				if _, ok := archived[gvr][namespace][item.GetName()]; ok || isClusterGenerated(item.Object) || isFinishedWorkload(item.Object) {
+					continue
+				}
+				result.add(ObjectDrift{GVR: gvr, Namespace: namespace, Name: item.GetName(), State: DriftExtra})
+			}
+		}
+	}
+	return nil
+}
+
+// objectDiff returns the JSON merge patch turning archived into live, or ""
+// when they match. Runtime fields are removed from both sides, and fields
+// only the live object sets are ignored so server defaults do not show as
+// drift; added labels and annotations are still reported.
+func objectDiff(archived, live map[string]interface{}) (string, error) {
+	archivedObj := cleanedCopy(archived)
+	cleanedLive := cleanedCopy(live)
+	liveObj, _ := withoutDefaults(archivedObj, cleanedLive).(map[string]interface{})
+	for _, field := range []string{"labels", "annotations"} {
+		if value, ok, _ := unstructured.NestedMap(cleanedLive, "metadata", field); ok {
+			if err := unstructured.SetNestedMap(liveObj, value, "metadata", field); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	archivedJSON, err := json.Marshal(archivedObj)
+	if err != nil {
+		return "", err
+	}
+	liveJSON, err := json.Marshal(liveObj)
+	if err != nil {
+		return "", err
+	}
+	patch, err := jsonpatch.CreateMergePatch(archivedJSON, liveJSON)
+	if err != nil {
+		return "", err
+	}
+	if string(patch) == "{}" {
+		return "", nil
+	}
+	return string(patch), nil
+}
+
+// withoutDefaults returns live restricted to the object fields archived
+// sets, descending into objects and into lists of the same length.
+func withoutDefaults(archived, live interface{}) interface{} {
+	switch archivedValue := archived.(type) {
+	case map[string]interface{}:
+		liveMap, ok := live.(map[string]interface{})
+		if !ok {
+			return live
+		}
+		pruned := make(map[string]interface{}, len(archivedValue))
+		for key, value := range archivedValue {
+			if liveValue, ok := liveMap[key]; ok {
+				pruned[key] = withoutDefaults(value, liveValue)
+			}
+		}
+		return pruned
+	case []interface{}:
+		liveList, ok := live.([]interface{})
+		if !ok || len(liveList) != len(archivedValue) {
+			return live
+		}
+		pruned := make([]interface{}, len(liveList))
+		for i := range liveList {
+			pruned[i] = withoutDefaults(archivedValue[i], liveList[i])
+		}
+		return pruned
+	default:
+		return live
+	}
+}
+
+// cleanedCopy returns a copy of obj without the fields backups leave out.
+func cleanedCopy(obj map[string]interface{}) map[string]interface{} {
+	cleaned := (&unstructured.Unstructured{Object: obj}).DeepCopy()
+	cleanResource(cleaned)
+	return cleaned.Object
+}
@@ -0,0 +1,90 @@
+package backup
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDiffArchive(t *testing.T) {
+	t.Parallel()
+
+	storageDir := t.TempDir()
+	archiveName := "cluster-backup-diff.tar.gz"
+	writeRestoreArchive(t, filepath.Join(storageDir, archiveName))
+
+	changed := configMapObject("restore-ns", "sample-config")
+	changed.Object["data"] = map[string]interface{}{"key": "drifted"}
+	extra := configMapObject("restore-ns", "added-config")
+	other := configMapObject("other-ns", "unrelated-config")
+	bm := &BackupManager{DynamicClient: newQuotaTestClient(changed, extra, other)}
+
+	result, err := bm.DiffArchive(context.Background(), storageDir, archiveName, DiffOptions{})
+	if err != nil {
+		t.Fatalf("DiffArchive returned error: %v", err)
+	}
+	if result.Missing != 1 || result.Changed != 1 || result.Extra != 1 || result.Unchanged != 0 {
+		t.Fatalf("result = %s, want 1 missing, 1 changed and 1 extra", result)
+	}
+	for _, item := range result.Items {
+		switch item.State {
+		case DriftMissing:
+			if item.Name != "restore-ns" {
+				t.Fatalf("missing = %+v, want the namespace", item)
+			}
+		case DriftChanged:
+			if item.Name != "sample-config" || item.Diff != `{"data":{"key":"drifted"}}` {
+				t.Fatalf("changed = %+v, want the drifted data key", item)
+			}
+		case DriftExtra:
+			if item.Namespace != "restore-ns" || item.Name != "added-config" {
+				t.Fatalf("extra = %+v, want added-config", item)
+			}
+		}
+	}
+
+	result, err = bm.DiffArchive(context.Background(), storageDir, archiveName, DiffOptions{IgnoreExtra: true})
+	if err != nil {
+		t.Fatalf("DiffArchive returned error: %v", err)
+	}
+	if result.Extra != 0 || !result.Drifted() {
+		t.Fatalf("result = %s, want no extra objects", result)
+	}
+}
+
+func TestObjectDiff(t *testing.T) {
+	t.Parallel()
+
+	archived := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"metadata":   map[string]interface{}{"name": "web"},
+		"spec": map[string]interface{}{
+			"replicas":   float64(3),
+			"containers": []interface{}{map[string]interface{}{"name": "web", "image": "web:1"}},
+		},
+	}
+	live := (&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"metadata":   map[string]interface{}{"name": "web", "uid": "1", "resourceVersion": "7"},
+		"spec": map[string]interface{}{
+			"replicas":   int64(3),
+			"strategy":   "RollingUpdate",
+			"containers": []interface{}{map[string]interface{}{"name": "web", "image": "web:1", "imagePullPolicy": "IfNotPresent"}},
+		},
+		"status": map[string]interface{}{"replicas": int64(1)},
+	}}).Object
+
+	diff, err := objectDiff(archived, live)
+	if err != nil || diff != "" {
+		t.Fatalf("objectDiff = %q, %v, want server defaults ignored", diff, err)
+	}
+
+	live["metadata"].(map[string]interface{})["labels"] = map[string]interface{}{"team": "a"}
+	live["spec"].(map[string]interface{})["replicas"] = int64(5)
+	diff, err = objectDiff(archived, live)
+	if want := `{"metadata":{"labels":{"team":"a"}},"spec":{"replicas":5}}`; err != nil || diff != want {
+		t.Fatalf("objectDiff = %q, %v, want %s", diff, err, want)
+	}
+}
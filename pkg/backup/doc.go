@@ -0,0 +1,41 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package backup is the engine behind the backup operator, usable on its
+// own by tools and scripts that want to back up or restore a cluster
+// without running the operator.
+//
+// A BackupManager is created with NewBackupManager from a rest.Config. Its
+// methods take options structs whose zero values select the defaults the
+// operator uses:
+//
+//   - CreateBackup writes an archive into a storage path, an absolute
+//     directory or a host:// URI, and RestoreBackup reads one back.
+//   - WriteBackup and RestoreBackupFrom stream an archive through an
+//     io.Writer or io.Reader instead, so archives can be piped to stdout,
+//     served over HTTP or stored in any backend the caller provides.
+//   - EstimateBackup, VerifyArchive, ListArchives and CleanupArchives size,
+//     check and prune archives.
+//
+// BackupOptions.Progress and RestoreOptions.Progress report how far a run
+// has come. Errors wrap the sentinel errors and types of this package, such
+// as ErrArchiveDigestMismatch and *MissingPermissionsError, for errors.Is
+// and errors.As.
+//
+// Exported identifiers keep their meaning across minor releases of the
+// operator; new fields are added to the options structs with zero values
+// that preserve existing behavior.
+package backup
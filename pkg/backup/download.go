@@ -0,0 +1,122 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrArchiveDigestMismatch is reported when an archive does not match the
+// SHA-256 digest it was expected to have.
+var ErrArchiveDigestMismatch = errors.New("archive does not match its expected digest")
+
+// downloadClient fetches archives when the BackupManager has no HTTPClient.
+// It refuses redirects away from https.
+var downloadClient = &http.Client{
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if req.URL.Scheme != "https" {
+			return fmt.Errorf("refusing redirect to %s", req.URL.Scheme)
+		}
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	},
+}
+
+// IsArchiveURL reports whether source is an archive URL rather than the
+// name of an archive in a storage location.
+func IsArchiveURL(source string) bool {
+	return strings.HasPrefix(source, "https://")
+}
+
+// ValidateArchiveURL checks that rawURL is an absolute https URL.
+func ValidateArchiveURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return errors.New("archive URL is not a valid URL")
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return errors.New("archive URL must be an absolute https:// URL")
+	}
+	return nil
+}
+
+// RedactArchiveURL drops the credentials and query of rawURL, which hold the
+// signature of pre-signed links, so it can be logged and put in status.
+func RedactArchiveURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "<invalid URL>"
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// openArchive opens archiveName in storagePath or, for an archive URL,
+// starts streaming it over HTTP. It also returns the name to report the
+// archive under.
+func (bm *BackupManager) openArchive(ctx context.Context, storagePath, archiveName string) (io.ReadCloser, string, error) {
+	if !IsArchiveURL(archiveName) {
+		resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+		if err != nil {
+			return nil, "", err
+		}
+		file, err := os.Open(filepath.Join(resolvedStoragePath, archiveName))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to open archive %q: %w", archiveName, err)
+		}
+		return file, archiveName, nil
+	}
+
+	name := RedactArchiveURL(archiveName)
+	if err := ValidateArchiveURL(archiveName); err != nil {
+		return nil, "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, archiveName, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request for %s: %w", name, err)
+	}
+	client := bm.HTTPClient
+	if client == nil {
+		client = downloadClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		// url.Error repeats the full URL, signature included
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, "", fmt.Errorf("failed to download archive %s: %w", name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, "", fmt.Errorf("failed to download archive %s: %s", name, resp.Status)
+	}
+	return resp.Body, name, nil
+}
@@ -0,0 +1,344 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bufio"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"filippo.io/age"
+)
+
+// Encrypted archives start with encryptionMagic, followed by a big-endian
+// uint32 length and a JSON encryptionHeader. The compressed tar stream follows
+// as a sequence of AES-256-GCM sealed chunks. Each chunk nonce combines the
+// header's random prefix, the chunk counter and a final-chunk flag, so chunks
+// cannot be reordered, dropped or appended without detection.
+const (
+	encryptionMagic        = "BKOPENC1"
+	encryptionCipher       = "AES-256-GCM"
+	encryptionChunkSize    = 64 * 1024
+	encryptionNoncePrefix  = 7
+	dataKeySize            = 32
+	maxEncryptionHeaderLen = 1 << 20
+	maxEncryptionChunkSize = 16 << 20
+)
+
+// ErrNoKeyWrapper is returned when none of the recipients of an encrypted
+// archive can be unwrapped with the available key wrappers.
+var ErrNoKeyWrapper = errors.New("no key wrapper can decrypt the archive data key")
+
+// WrappedKey is the archive data key encrypted for one recipient. Only wrapped
+// keys are stored with the archive; the plaintext data key never is.
+type WrappedKey struct {
+	// Provider names the KeyWrapper implementation, for example "aws-kms".
+	Provider string `json:"provider"`
+	// KeyID identifies the key-encryption key within the provider.
+	KeyID string `json:"keyID"`
+	// Key is the wrapped data key.
+	Key []byte `json:"key"`
+}
+
+// KeyWrapper encrypts and decrypts archive data keys with a key-encryption
+// key it controls, such as a cloud KMS key.
+type KeyWrapper interface {
+	// WrapKey encrypts dataKey for this wrapper's key.
+	WrapKey(ctx context.Context, dataKey []byte) (WrappedKey, error)
+	// UnwrapKey recovers the data key from wrapped.
+	UnwrapKey(ctx context.Context, wrapped WrappedKey) ([]byte, error)
+}
+
+// encryptionHeader is the plaintext envelope written in front of the
+// encrypted stream.
+type encryptionHeader struct {
+	Version     int          `json:"version"`
+	Cipher      string       `json:"cipher"`
+	ChunkSize   int          `json:"chunkSize"`
+	NoncePrefix []byte       `json:"noncePrefix"`
+	Recipients  []WrappedKey `json:"recipients"`
+}
+
+// newEncryptionWriter generates a data key, wraps it for every wrapper, writes
+// the envelope header to w and returns a writer sealing everything written to
+// it. The returned writer must be closed to emit the final chunk.
+func newEncryptionWriter(ctx context.Context, w io.Writer, wrappers []KeyWrapper) (io.WriteCloser, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	header := encryptionHeader{
+		Version:     1,
+		Cipher:      encryptionCipher,
+		ChunkSize:   encryptionChunkSize,
+		NoncePrefix: make([]byte, encryptionNoncePrefix),
+	}
+	if _, err := rand.Read(header.NoncePrefix); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce prefix: %w", err)
+	}
+	for _, wrapper := range wrappers {
+		wrapped, err := wrapper.WrapKey(ctx, dataKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to wrap data key: %w", err)
+		}
+		header.Recipients = append(header.Recipients, wrapped)
+	}
+
+	headerBytes, err := json.Marshal(header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode encryption header: %w", err)
+	}
+	prefix := make([]byte, 0, len(encryptionMagic)+4)
+	prefix = append(prefix, encryptionMagic...)
+	prefix = binary.BigEndian.AppendUint32(prefix, uint32(len(headerBytes)))
+	if _, err := w.Write(prefix); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(headerBytes); err != nil {
+		return nil, err
+	}
+
+	aead, err := newChunkAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptionWriter{
+		w:      w,
+		aead:   aead,
+		header: header,
+		aad:    headerBytes,
+		buf:    make([]byte, 0, header.ChunkSize),
+	}, nil
+}
+
+type encryptionWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	header  encryptionHeader
+	aad     []byte
+	buf     []byte
+	sealed  []byte
+	counter uint32
+	closed  bool
+}
+
+func (ew *encryptionWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		// A full buffer is only sealed once more data arrives, so the final
+		// chunk is always the one emitted by Close
+		if len(ew.buf) == ew.header.ChunkSize {
+			if err := ew.sealChunk(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(ew.buf[len(ew.buf):ew.header.ChunkSize], p)
+		ew.buf = ew.buf[:len(ew.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close seals the final chunk; it does not close the underlying writer.
+func (ew *encryptionWriter) Close() error {
+	if ew.closed {
+		return nil
+	}
+	ew.closed = true
+	return ew.sealChunk(true)
+}
+
+func (ew *encryptionWriter) sealChunk(last bool) error {
+	nonce := chunkNonce(ew.header.NoncePrefix, ew.counter, last)
+	ew.sealed = ew.aead.Seal(ew.sealed[:0], nonce, ew.buf, ew.aad)
+	if _, err := ew.w.Write(ew.sealed); err != nil {
+		return err
+	}
+	ew.counter++
+	ew.buf = ew.buf[:0]
+	return nil
+}
+
+// newArchiveEncrypter returns a writer encrypting the archive for wrappers:
+// a native age stream when they are all age recipients, and the envelope
+// format otherwise.
+func newArchiveEncrypter(ctx context.Context, w io.Writer, wrappers []KeyWrapper) (io.WriteCloser, error) {
+	if recipients, ok := ageRecipients(wrappers); ok {
+		return age.Encrypt(w, recipients...)
+	}
+	return newEncryptionWriter(ctx, w, wrappers)
+}
+
+// decryptArchive returns a reader yielding the decrypted archive when r is
+// encrypted, and r itself otherwise.
+func decryptArchive(ctx context.Context, r *bufio.Reader, resolve func(WrappedKey) []KeyWrapper) (io.Reader, error) {
+	switch detectEncryption(r) {
+	case encryptionFormatEnvelope:
+		return newDecryptionReader(ctx, r, resolve)
+	case encryptionFormatAge:
+		return newAgeDecryptionReader(r, resolve(WrappedKey{Provider: AgeProvider}))
+	default:
+		return r, nil
+	}
+}
+
+// isEncrypted reports whether r starts with the encryption envelope.
+func isEncrypted(r *bufio.Reader) bool {
+	magic, err := r.Peek(len(encryptionMagic))
+	return err == nil && string(magic) == encryptionMagic
+}
+
+// newDecryptionReader parses the envelope header from r, recovers the data key
+// with the first wrapper able to unwrap one of the recipients, and returns a
+// reader yielding the decrypted stream.
+func newDecryptionReader(ctx context.Context, r *bufio.Reader, resolve func(WrappedKey) []KeyWrapper) (io.Reader, error) {
+	prefix := make([]byte, len(encryptionMagic)+4)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return nil, fmt.Errorf("failed to read encryption header: %w", err)
+	}
+	if string(prefix[:len(encryptionMagic)]) != encryptionMagic {
+		return nil, fmt.Errorf("archive is not encrypted")
+	}
+	headerLen := binary.BigEndian.Uint32(prefix[len(encryptionMagic):])
+	if headerLen > maxEncryptionHeaderLen {
+		return nil, fmt.Errorf("encryption header too large (%d bytes)", headerLen)
+	}
+	headerBytes := make([]byte, headerLen)
+	if _, err := io.ReadFull(r, headerBytes); err != nil {
+		return nil, fmt.Errorf("failed to read encryption header: %w", err)
+	}
+
+	var header encryptionHeader
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return nil, fmt.Errorf("failed to decode encryption header: %w", err)
+	}
+	if header.Cipher != encryptionCipher || header.ChunkSize <= 0 || header.ChunkSize > maxEncryptionChunkSize || len(header.NoncePrefix) != encryptionNoncePrefix {
+		return nil, fmt.Errorf("unsupported encryption header (cipher %q)", header.Cipher)
+	}
+
+	dataKey, err := unwrapDataKey(ctx, header.Recipients, resolve)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newChunkAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &decryptionReader{
+		r:      r,
+		aead:   aead,
+		header: header,
+		aad:    headerBytes,
+		chunk:  make([]byte, header.ChunkSize+aead.Overhead()),
+	}, nil
+}
+
+// unwrapDataKey tries every recipient against the wrappers resolved for it.
+func unwrapDataKey(ctx context.Context, recipients []WrappedKey, resolve func(WrappedKey) []KeyWrapper) ([]byte, error) {
+	var errs []error
+	for _, recipient := range recipients {
+		for _, wrapper := range resolve(recipient) {
+			dataKey, err := wrapper.UnwrapKey(ctx, recipient)
+			if err == nil {
+				return dataKey, nil
+			}
+			errs = append(errs, fmt.Errorf("%s %s: %w", recipient.Provider, recipient.KeyID, err))
+		}
+	}
+	return nil, errors.Join(append([]error{ErrNoKeyWrapper}, errs...)...)
+}
+
+type decryptionReader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	header  encryptionHeader
+	aad     []byte
+	chunk   []byte
+	plain   []byte
+	counter uint32
+	done    bool
+}
+
+func (dr *decryptionReader) Read(p []byte) (int, error) {
+	for len(dr.plain) == 0 {
+		if dr.done {
+			return 0, io.EOF
+		}
+		if err := dr.openChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, dr.plain)
+	dr.plain = dr.plain[n:]
+	return n, nil
+}
+
+func (dr *decryptionReader) openChunk() error {
+	n, err := io.ReadFull(dr.r, dr.chunk)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read encrypted chunk: %w", err)
+	}
+	// The chunk is final when nothing follows it
+	last := err != nil
+	if !last {
+		if _, peekErr := dr.r.Peek(1); errors.Is(peekErr, io.EOF) {
+			last = true
+		}
+	}
+
+	nonce := chunkNonce(dr.header.NoncePrefix, dr.counter, last)
+	plain, openErr := dr.aead.Open(dr.chunk[:0], nonce, dr.chunk[:n], dr.aad)
+	if openErr != nil {
+		return fmt.Errorf("encrypted archive is corrupt or truncated at chunk %d: %w", dr.counter, openErr)
+	}
+	dr.plain = plain
+	dr.counter++
+	dr.done = last
+	return nil
+}
+
+func newChunkAEAD(dataKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return aead, nil
+}
+
+func chunkNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, 0, len(prefix)+5)
+	nonce = append(nonce, prefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, counter)
+	if last {
+		return append(nonce, 1)
+	}
+	return append(nonce, 0)
+}
@@ -0,0 +1,280 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+)
+
+func TestEncryptionRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	wrapper := newTestKeyWrapper(t, "primary")
+	for _, size := range []int{0, 1, encryptionChunkSize, 3*encryptionChunkSize + 17} {
+		plaintext := make([]byte, size)
+		if _, err := rand.Read(plaintext); err != nil {
+			t.Fatalf("rand.Read failed: %v", err)
+		}
+
+		sealed := encryptForTest(t, plaintext, wrapper)
+		if bytes.Contains(sealed, wrapper.lastDataKey) {
+			t.Fatalf("expected the plaintext data key not to be stored")
+		}
+
+		got, err := decryptForTest(sealed, wrapper)
+		if err != nil {
+			t.Fatalf("decrypting %d bytes failed: %v", size, err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Fatalf("decrypted %d bytes do not match the plaintext", size)
+		}
+	}
+}
+
+func TestEncryptionDetectsTampering(t *testing.T) {
+	t.Parallel()
+
+	wrapper := newTestKeyWrapper(t, "primary")
+	plaintext := bytes.Repeat([]byte("cluster backup "), encryptionChunkSize/4)
+	sealed := encryptForTest(t, plaintext, wrapper)
+
+	flipped := append([]byte(nil), sealed...)
+	flipped[len(flipped)-100] ^= 0x01
+	if _, err := decryptForTest(flipped, wrapper); err == nil {
+		t.Fatalf("expected a flipped ciphertext bit to be detected")
+	}
+
+	// Dropping the final chunk leaves a stream that ends on a non-final chunk
+	aead, err := newChunkAEAD(make([]byte, dataKeySize))
+	if err != nil {
+		t.Fatalf("newChunkAEAD failed: %v", err)
+	}
+	lastChunk := len(plaintext)%encryptionChunkSize + aead.Overhead()
+	if _, err := decryptForTest(sealed[:len(sealed)-lastChunk], wrapper); err == nil {
+		t.Fatalf("expected a truncated stream to be detected")
+	}
+
+	if _, err := decryptForTest(sealed, newTestKeyWrapper(t, "other")); !errors.Is(err, ErrNoKeyWrapper) {
+		t.Fatalf("expected ErrNoKeyWrapper for an unrelated key, got %v", err)
+	}
+}
+
+func TestReadArchiveDecryptsEncryptedArchives(t *testing.T) {
+	t.Parallel()
+
+	var sealed bytes.Buffer
+	wrapper := newTestKeyWrapper(t, "primary")
+	encWriter, err := newEncryptionWriter(context.Background(), &sealed, []KeyWrapper{wrapper})
+	if err != nil {
+		t.Fatalf("newEncryptionWriter failed: %v", err)
+	}
+	gz := gzip.NewWriter(encWriter)
+	archive := newArchiveWriter(gz)
+	if err := archive.writeObject("cluster/v1/namespaces/encrypted.json", map[string]interface{}{
+		"apiVersion": "v1", "kind": "Namespace",
+		"metadata": map[string]interface{}{"name": "encrypted"},
+	}); err != nil {
+		t.Fatalf("writeObject failed: %v", err)
+	}
+	for _, c := range []io.Closer{archive, gz, encWriter} {
+		if err := c.Close(); err != nil {
+			t.Fatalf("failed to finalize archive: %v", err)
+		}
+	}
+
+	archivePath := filepath.Join(t.TempDir(), "cluster-backup-encrypted.tar.gz")
+	if err := os.WriteFile(archivePath, sealed.Bytes(), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	opts := RestoreOptions{KeyWrappers: []KeyWrapper{wrapper}}
+	cluster, _, err := readArchive(context.Background(), archivePath, opts.keyWrappersFor(context.Background()))
+	if err != nil {
+		t.Fatalf("readArchive failed: %v", err)
+	}
+	if len(cluster) != 1 || cluster[0].name != "encrypted" || cluster[0].err != nil {
+		t.Fatalf("expected the encrypted namespace to be read back, got %+v", cluster)
+	}
+
+	if _, _, err := readArchive(context.Background(), archivePath, RestoreOptions{}.keyWrappersFor(context.Background())); !errors.Is(err, ErrNoKeyWrapper) {
+		t.Fatalf("expected ErrNoKeyWrapper without a matching key, got %v", err)
+	}
+}
+
+func TestAgeRecipientEncryption(t *testing.T) {
+	t.Parallel()
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("GenerateX25519Identity failed: %v", err)
+	}
+	recipients, err := NewAgeKeyWrappers([]string{identity.Recipient().String()})
+	if err != nil {
+		t.Fatalf("NewAgeKeyWrappers failed: %v", err)
+	}
+	identityWrapper, err := NewAgeIdentityKeyWrapper("# laptop key\n" + identity.String() + "\n")
+	if err != nil {
+		t.Fatalf("NewAgeIdentityKeyWrapper failed: %v", err)
+	}
+	plaintext := bytes.Repeat([]byte("cluster backup "), encryptionChunkSize/8)
+
+	// Age-only archives are plain age files
+	var sealed bytes.Buffer
+	w, err := newArchiveEncrypter(context.Background(), &sealed, recipients)
+	if err != nil {
+		t.Fatalf("newArchiveEncrypter failed: %v", err)
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	cli, err := age.Decrypt(bytes.NewReader(sealed.Bytes()), identity)
+	if err != nil {
+		t.Fatalf("expected a native age file: %v", err)
+	}
+	if got, _ := io.ReadAll(cli); !bytes.Equal(got, plaintext) {
+		t.Fatalf("age decrypted content does not match the plaintext")
+	}
+	got, err := decryptForArchive(sealed.Bytes(), identityWrapper)
+	if err != nil || !bytes.Equal(got, plaintext) {
+		t.Fatalf("expected decryptArchive to open the age file, got err %v", err)
+	}
+	if _, err := decryptForArchive(sealed.Bytes()); !errors.Is(err, ErrNoKeyWrapper) {
+		t.Fatalf("expected ErrNoKeyWrapper without an identity, got %v", err)
+	}
+
+	// Mixed with a KMS key, the age recipient is one envelope recipient
+	mixed := encryptForTest(t, plaintext, append([]KeyWrapper{newTestKeyWrapper(t, "kms")}, recipients...)...)
+	got, err = decryptForArchive(mixed, identityWrapper)
+	if err != nil || !bytes.Equal(got, plaintext) {
+		t.Fatalf("expected the age identity to unwrap the envelope, got err %v", err)
+	}
+
+	if _, err := NewAgeKeyWrappers([]string{"age1invalid"}); err == nil {
+		t.Fatalf("expected a malformed recipient to be rejected")
+	}
+}
+
+func TestParseAzureKeyURL(t *testing.T) {
+	t.Parallel()
+
+	vault, name, version, err := parseAzureKeyURL("https://backups.vault.azure.net/keys/archive/0123abcd")
+	if err != nil {
+		t.Fatalf("parseAzureKeyURL returned error: %v", err)
+	}
+	if vault != "https://backups.vault.azure.net" || name != "archive" || version != "0123abcd" {
+		t.Fatalf("unexpected parse result %q %q %q", vault, name, version)
+	}
+
+	for _, keyURL := range []string{"http://backups.vault.azure.net/keys/archive", "https://backups.vault.azure.net/secrets/archive", "archive"} {
+		if _, _, _, err := parseAzureKeyURL(keyURL); err == nil {
+			t.Errorf("expected %q to be rejected", keyURL)
+		}
+	}
+}
+
+// testKeyWrapper wraps data keys with a local AES-GCM key.
+type testKeyWrapper struct {
+	keyID       string
+	aead        cipher.AEAD
+	lastDataKey []byte
+}
+
+func newTestKeyWrapper(t *testing.T, keyID string) *testKeyWrapper {
+	t.Helper()
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("rand.Read failed: %v", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatalf("aes.NewCipher failed: %v", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatalf("cipher.NewGCM failed: %v", err)
+	}
+	return &testKeyWrapper{keyID: keyID, aead: aead}
+}
+
+func (w *testKeyWrapper) WrapKey(_ context.Context, dataKey []byte) (WrappedKey, error) {
+	w.lastDataKey = append([]byte(nil), dataKey...)
+	nonce := make([]byte, w.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return WrappedKey{}, err
+	}
+	return WrappedKey{Provider: "test", KeyID: w.keyID, Key: w.aead.Seal(nonce, nonce, dataKey, nil)}, nil
+}
+
+func (w *testKeyWrapper) UnwrapKey(_ context.Context, wrapped WrappedKey) ([]byte, error) {
+	nonceSize := w.aead.NonceSize()
+	if len(wrapped.Key) < nonceSize {
+		return nil, errors.New("wrapped key too short")
+	}
+	return w.aead.Open(nil, wrapped.Key[:nonceSize], wrapped.Key[nonceSize:], nil)
+}
+
+func encryptForTest(t *testing.T, plaintext []byte, wrappers ...KeyWrapper) []byte {
+	t.Helper()
+
+	var sealed bytes.Buffer
+	w, err := newEncryptionWriter(context.Background(), &sealed, wrappers)
+	if err != nil {
+		t.Fatalf("newEncryptionWriter failed: %v", err)
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	return sealed.Bytes()
+}
+
+func decryptForTest(sealed []byte, wrappers ...KeyWrapper) ([]byte, error) {
+	r, err := newDecryptionReader(context.Background(), bufio.NewReader(bytes.NewReader(sealed)),
+		func(WrappedKey) []KeyWrapper { return wrappers })
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func decryptForArchive(sealed []byte, wrappers ...KeyWrapper) ([]byte, error) {
+	r, err := decryptArchive(context.Background(), bufio.NewReader(bytes.NewReader(sealed)),
+		func(WrappedKey) []KeyWrapper { return wrappers })
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
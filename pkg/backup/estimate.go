@@ -0,0 +1,108 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"path"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// ResourceEstimate is the share of one resource type in a BackupEstimate.
+type ResourceEstimate struct {
+	GVR   schema.GroupVersionResource
+	Items int
+	// Bytes is the encoded size of the objects before compression.
+	Bytes int64
+}
+
+// BackupEstimate is the expected size of a backup.
+type BackupEstimate struct {
+	Items int
+	// Bytes is the encoded size of all objects before compression.
+	Bytes int64
+	// CompressedBytes is the size of the archive with the configured
+	// compression, before encryption.
+	CompressedBytes int64
+	// Resources break the estimate down per resource type, largest first.
+	Resources []ResourceEstimate
+	// SkippedResources lists the resources a backup would leave out because
+	// the operator may not list them.
+	SkippedResources []PermissionGap
+}
+
+// byteCounter is an io.Writer that only counts what is written to it.
+type byteCounter int64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
+
+// EstimateBackup collects the resources selected by opts exactly as
+// CreateBackup does, but discards the archive instead of storing it. No
+// archive, export or replica is written and archives are not encrypted.
+func (bm *BackupManager) EstimateBackup(ctx context.Context, opts BackupOptions) (*BackupEstimate, error) {
+	opts.Export = nil
+	opts.KeyWrappers = nil
+	skipped, err := bm.prepareBackup(ctx, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var compressed byteCounter
+	sizes := map[schema.GroupVersionResource]*ResourceEstimate{}
+	items, err := bm.writeArchive(ctx, &compressed, nil, sizes, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate backup: %w", err)
+	}
+
+	estimate := &BackupEstimate{Items: items, CompressedBytes: int64(compressed), SkippedResources: skipped}
+	for _, size := range sizes {
+		estimate.Bytes += size.Bytes
+		estimate.Resources = append(estimate.Resources, *size)
+	}
+	slices.SortFunc(estimate.Resources, func(a, b ResourceEstimate) int {
+		return cmp.Or(cmp.Compare(b.Bytes, a.Bytes), cmp.Compare(a.GVR.String(), b.GVR.String()))
+	})
+	ctrl.LoggerFrom(ctx).Info("Estimated backup", "items", estimate.Items, "bytes", estimate.Bytes,
+		"compressedBytes", estimate.CompressedBytes)
+	return estimate, nil
+}
+
+// recordSizeLocked adds an object written as name to the size of its
+// resource type, which is the directory it is written in; aw.mu must be
+// held.
+func (aw *archiveWriter) recordSizeLocked(name string, obj map[string]interface{}, size int64) {
+	gv, err := schema.ParseGroupVersion(nestedString(obj, "apiVersion"))
+	if err != nil {
+		return
+	}
+	gvr := gv.WithResource(path.Base(path.Dir(name)))
+	entry, ok := aw.sizes[gvr]
+	if !ok {
+		entry = &ResourceEstimate{GVR: gvr}
+		aw.sizes[gvr] = entry
+	}
+	entry.Items++
+	entry.Bytes += size
+}
@@ -0,0 +1,72 @@
+package backup
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestEstimateBackup(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	dynamicClient := fake.NewSimpleDynamicClient(scheme,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payments"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "large", Namespace: "payments"},
+			Data: map[string]string{"payload": strings.Repeat("x", 4096)}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "small", Namespace: "payments"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "payments"}},
+	)
+	bm := &BackupManager{
+		DynamicClient: dynamicClient,
+		DiscoveryClient: preferredResources{lists: []*metav1.APIResourceList{
+			{GroupVersion: "v1", APIResources: []metav1.APIResource{
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"list"}},
+				{Name: "services", Kind: "Service", Namespaced: true, Verbs: []string{"list"}},
+			}},
+		}},
+	}
+
+	var progress [][2]int
+	estimate, err := bm.EstimateBackup(context.Background(), BackupOptions{
+		IncludeNamespaces: []string{"payments"},
+		ResourceTypes:     []string{"ConfigMap", "Service"},
+		Progress: func(processed, total int) {
+			progress = append(progress, [2]int{processed, total})
+		},
+	})
+	if err != nil {
+		t.Fatalf("EstimateBackup: %v", err)
+	}
+	if want := [][2]int{{0, 2}, {1, 2}, {2, 2}}; !slices.Equal(progress, want) {
+		t.Errorf("progress = %v, want %v", progress, want)
+	}
+
+	if estimate.Items != 3 {
+		t.Errorf("Items = %d, want 3", estimate.Items)
+	}
+	if len(estimate.Resources) != 2 {
+		t.Fatalf("Resources = %+v, want configmaps and services", estimate.Resources)
+	}
+	configMaps := estimate.Resources[0]
+	if configMaps.GVR != (schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}) || configMaps.Items != 2 {
+		t.Errorf("largest resource = %+v, want the two configmaps", configMaps)
+	}
+	if configMaps.Bytes < 4096 || estimate.Bytes != configMaps.Bytes+estimate.Resources[1].Bytes {
+		t.Errorf("Bytes = %d with configmaps at %d", estimate.Bytes, configMaps.Bytes)
+	}
+	// The repeated payload compresses well
+	if estimate.CompressedBytes <= 0 || estimate.CompressedBytes >= estimate.Bytes {
+		t.Errorf("CompressedBytes = %d, want between 0 and %d", estimate.CompressedBytes, estimate.Bytes)
+	}
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	kustomizationName = "kustomization.yaml"
+
+	// lastAppliedAnnotation duplicates the whole object, which only adds
+	// noise to reviewed diffs.
+	lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+)
+
+// GitOpsExport writes the objects of a backup as plain YAML into a
+// kustomize-friendly directory tree, next to the archive or into a git
+// repository.
+type GitOpsExport struct {
+	// Path of the export directory, relative to the storage path, or to the
+	// repository root when Repository is set.
+	Path string
+	// IncludeSecrets exports Secrets, which are left out by default because
+	// the export is not encrypted.
+	IncludeSecrets bool
+	// Repository, when set, receives the export as a commit.
+	Repository *GitRepository
+}
+
+// kustomization is the subset of a kustomize Kustomization written by exports.
+type kustomization struct {
+	APIVersion string   `json:"apiVersion"`
+	Kind       string   `json:"kind"`
+	Resources  []string `json:"resources"`
+}
+
+// exportWriter mirrors archived objects into a directory tree with one YAML
+// file per object and a kustomization for the cluster-scoped objects and for
+// every namespace.
+type exportWriter struct {
+	dir            string
+	includeSecrets bool
+	// resources lists the files of each kustomization directory.
+	resources map[string][]string
+}
+
+func newExportWriter(dir string, includeSecrets bool) *exportWriter {
+	return &exportWriter{dir: dir, includeSecrets: includeSecrets, resources: map[string][]string{}}
+}
+
+// writeObject exports obj, stored in the archive as name. Objects created by
+// a controller are skipped since applying their owner recreates them.
+func (ew *exportWriter) writeObject(name string, obj map[string]interface{}) error {
+	u := &unstructured.Unstructured{Object: obj}
+	if metav1.GetControllerOf(u) != nil {
+		return nil
+	}
+	if !ew.includeSecrets && u.GetAPIVersion() == "v1" && u.GetKind() == "Secret" {
+		return nil
+	}
+	if annotations := u.GetAnnotations(); annotations[lastAppliedAnnotation] != "" {
+		u = u.DeepCopy()
+		delete(annotations, lastAppliedAnnotation)
+		u.SetAnnotations(annotations)
+	}
+
+	data, err := yaml.Marshal(u.Object)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s as YAML: %w", name, err)
+	}
+	file := strings.TrimSuffix(name, path.Ext(name)) + ".yaml"
+	target := filepath.Join(ew.dir, filepath.FromSlash(file))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+	if err := os.WriteFile(target, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+
+	base := exportBase(file)
+	rel := strings.TrimPrefix(file, base+"/")
+	ew.resources[base] = append(ew.resources[base], rel)
+	return nil
+}
+
+// Close writes the kustomizations, including a root one that references
+// every other.
+func (ew *exportWriter) Close() error {
+	if err := os.MkdirAll(ew.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+	bases := make([]string, 0, len(ew.resources))
+	for base, files := range ew.resources {
+		sort.Strings(files)
+		if err := writeKustomization(filepath.Join(ew.dir, filepath.FromSlash(base)), files); err != nil {
+			return err
+		}
+		bases = append(bases, base)
+	}
+	sort.Strings(bases)
+	return writeKustomization(ew.dir, bases)
+}
+
+// exportBase returns the kustomization directory of an exported file:
+// "cluster" or "namespaces/<namespace>".
+func exportBase(file string) string {
+	parts := strings.SplitN(file, "/", 3)
+	if parts[0] == "namespaces" && len(parts) == 3 {
+		return path.Join(parts[0], parts[1])
+	}
+	return parts[0]
+}
+
+func writeKustomization(dir string, resources []string) error {
+	data, err := yaml.Marshal(kustomization{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+		Resources:  resources,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode kustomization: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, kustomizationName), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write kustomization: %w", err)
+	}
+	return nil
+}
+
+// ValidateExportPath checks that exportPath is a relative path that stays
+// inside its root, whatever its template variables expand to. Only
+// repository exports may target the root itself.
+func ValidateExportPath(exportPath string, repository bool) error {
+	exportPath, err := ExpandStoragePath(exportPath, StoragePathVars{
+		ClusterName: "cluster", Namespace: "namespace", BackupName: "backup", Date: "2006-01-02",
+	})
+	if err != nil {
+		return err
+	}
+	_, err = exportDir("/", exportPath, repository)
+	return err
+}
+
+// exportDir resolves the export path below root, refusing paths that would
+// leave it. allowRoot permits exporting into root itself.
+func exportDir(root, exportPath string, allowRoot bool) (string, error) {
+	if exportPath == "" {
+		return "", errors.New("export path is required")
+	}
+	clean := filepath.Clean(filepath.FromSlash(exportPath))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("export path %q must be relative and stay inside its root", exportPath)
+	}
+	if clean == "." && !allowRoot {
+		return "", fmt.Errorf("export path %q must name a subdirectory", exportPath)
+	}
+	return filepath.Join(root, clean), nil
+}
+
+// syncDir replaces the contents of dst with those of src, keeping a .git
+// directory in dst so exports can target the root of a repository.
+func syncDir(src, dst string) error {
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+	entries, err := os.ReadDir(dst)
+	if err != nil {
+		return fmt.Errorf("failed to read export directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.Name() == ".git" {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(dst, entry.Name())); err != nil {
+			return fmt.Errorf("failed to clear previous export: %w", err)
+		}
+	}
+
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		return copyFile(p, target)
+	})
+}
@@ -0,0 +1,175 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/plumbing/transport/server"
+)
+
+func TestExportWriterLayout(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	export := newExportWriter(dir, false)
+	objects := map[string]map[string]interface{}{
+		"cluster/v1/namespaces/apps.json": {
+			"apiVersion": "v1", "kind": "Namespace",
+			"metadata": map[string]interface{}{"name": "apps"},
+		},
+		"namespaces/apps/v1/configmaps/settings.json": {
+			"apiVersion": "v1", "kind": "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name": "settings", "namespace": "apps",
+				"annotations": map[string]interface{}{lastAppliedAnnotation: "{}", "team": "web"},
+			},
+			"data": map[string]interface{}{"mode": "production"},
+		},
+		"namespaces/apps/v1/secrets/token.json": {
+			"apiVersion": "v1", "kind": "Secret",
+			"metadata": map[string]interface{}{"name": "token", "namespace": "apps"},
+		},
+		"namespaces/apps/v1/pods/web-abc.json": {
+			"apiVersion": "v1", "kind": "Pod",
+			"metadata": map[string]interface{}{
+				"name": "web-abc", "namespace": "apps",
+				"ownerReferences": []interface{}{map[string]interface{}{
+					"apiVersion": "apps/v1", "kind": "ReplicaSet", "name": "web", "uid": "1", "controller": true,
+				}},
+			},
+		},
+	}
+	for name, obj := range objects {
+		if err := export.writeObject(name, obj); err != nil {
+			t.Fatalf("writeObject(%s) failed: %v", name, err)
+		}
+	}
+	if err := export.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	configMap := readExportFile(t, dir, "namespaces/apps/v1/configmaps/settings.yaml")
+	if !strings.Contains(configMap, "mode: production") || !strings.Contains(configMap, "team: web") {
+		t.Fatalf("expected the configmap as YAML, got:\n%s", configMap)
+	}
+	if strings.Contains(configMap, lastAppliedAnnotation) {
+		t.Fatalf("expected the last-applied annotation to be dropped, got:\n%s", configMap)
+	}
+	if _, ok := objects["namespaces/apps/v1/configmaps/settings.json"]["metadata"].(map[string]interface{})["annotations"].(map[string]interface{})[lastAppliedAnnotation]; !ok {
+		t.Fatalf("expected the archived object to be left untouched")
+	}
+	for _, skipped := range []string{"namespaces/apps/v1/secrets/token.yaml", "namespaces/apps/v1/pods/web-abc.yaml"} {
+		if _, err := os.Stat(filepath.Join(dir, skipped)); !os.IsNotExist(err) {
+			t.Fatalf("expected %s not to be exported", skipped)
+		}
+	}
+
+	wantKustomizations := map[string]string{
+		"kustomization.yaml":                 "resources:\n- cluster\n- namespaces/apps\n",
+		"cluster/kustomization.yaml":         "resources:\n- v1/namespaces/apps.yaml\n",
+		"namespaces/apps/kustomization.yaml": "resources:\n- v1/configmaps/settings.yaml\n",
+	}
+	for file, want := range wantKustomizations {
+		if got := readExportFile(t, dir, file); !strings.Contains(got, "kind: Kustomization") || !strings.HasSuffix(got, want) {
+			t.Fatalf("unexpected %s:\n%s", file, got)
+		}
+	}
+
+	if err := ValidateExportPath(".", false); err == nil {
+		t.Fatalf("expected the storage root to be rejected as an export path")
+	}
+	if err := ValidateExportPath("../outside", true); err == nil {
+		t.Fatalf("expected a path leaving the repository to be rejected")
+	}
+}
+
+func TestCommitExport(t *testing.T) {
+	// Serve file:// remotes in process instead of through git-receive-pack
+	client.InstallProtocol("file", server.NewClient(server.NewFilesystemLoader(osfs.New("/"))))
+
+	remote := t.TempDir()
+	if _, err := git.PlainInit(remote, true); err != nil {
+		t.Fatalf("PlainInit failed: %v", err)
+	}
+	repo := &GitRepository{URL: remote, Branch: "backups"}
+	ctx := context.Background()
+
+	staged := t.TempDir()
+	writeStagedFile(t, staged, "kustomization.yaml", "resources: []\n")
+	first, err := commitExport(ctx, repo, staged, "clusters/prod", "Export one")
+	if err != nil {
+		t.Fatalf("commitExport into an empty remote failed: %v", err)
+	}
+
+	again, err := commitExport(ctx, repo, staged, "clusters/prod", "Export two")
+	if err != nil {
+		t.Fatalf("commitExport without changes failed: %v", err)
+	}
+	if again != first {
+		t.Fatalf("expected an unchanged export not to be committed, got %s after %s", again, first)
+	}
+
+	// Files removed from the export are removed from the repository
+	staged = t.TempDir()
+	writeStagedFile(t, staged, "kustomization.yaml", "resources:\n- cluster\n")
+	writeStagedFile(t, staged, "cluster/kustomization.yaml", "resources: []\n")
+	second, err := commitExport(ctx, repo, staged, "clusters/prod", "Export three")
+	if err != nil {
+		t.Fatalf("commitExport with changes failed: %v", err)
+	}
+
+	r, err := git.PlainOpen(remote)
+	if err != nil {
+		t.Fatalf("PlainOpen failed: %v", err)
+	}
+	ref, err := r.Reference(plumbing.NewBranchReferenceName("backups"), true)
+	if err != nil {
+		t.Fatalf("expected the branch to be created: %v", err)
+	}
+	if ref.Hash().String() != second {
+		t.Fatalf("expected branch head %s, got %s", second, ref.Hash())
+	}
+	commit, err := r.CommitObject(ref.Hash())
+	if err != nil {
+		t.Fatalf("CommitObject failed: %v", err)
+	}
+	if commit.Message != "Export three" || commit.NumParents() != 1 || commit.ParentHashes[0].String() != first {
+		t.Fatalf("unexpected commit %q with parents %v", commit.Message, commit.ParentHashes)
+	}
+	file, err := commit.File("clusters/prod/cluster/kustomization.yaml")
+	if err != nil {
+		t.Fatalf("expected the export under clusters/prod: %v", err)
+	}
+	if content, _ := file.Contents(); content != "resources: []\n" {
+		t.Fatalf("unexpected exported content %q", content)
+	}
+}
+
+func readExportFile(t *testing.T, dir, name string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	if err != nil {
+		t.Fatalf("expected %s to be exported: %v", name, err)
+	}
+	return string(data)
+}
+
+func writeStagedFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	target := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	if err := os.WriteFile(target, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+)
+
+// ExtractOptions configures ExtractArchive.
+type ExtractOptions struct {
+	// KeyWrappers decrypt encrypted archives, as for RestoreOptions.
+	KeyWrappers []KeyWrapper
+	// IncludeSecrets extracts Secrets, which are left out by default because
+	// the extracted files are not encrypted.
+	IncludeSecrets bool
+}
+
+// ExtractResult reports what ExtractArchive wrote.
+type ExtractResult struct {
+	// Objects is the number of objects written to the extracted tree.
+	Objects int
+	// Skipped counts the objects left out because a controller creates
+	// them or because they are Secrets.
+	Skipped int
+	// FailedEntries lists the entries that failed verification and were
+	// not extracted.
+	FailedEntries []string
+}
+
+// ExtractArchive unpacks storagePath/archiveName, or the archive URL
+// archiveName, into dir as one YAML file per object with a kustomization for
+// the cluster-scoped objects, for every namespace and at the root, so it can
+// be applied with "kubectl apply -k". dir must not exist or be empty.
+// Objects created by a controller are left out like for GitOpsExport, and
+// entries that fail verification are reported instead of extracted.
+func (bm *BackupManager) ExtractArchive(ctx context.Context, storagePath, archiveName, dir string, opts ExtractOptions) (*ExtractResult, error) {
+	if !IsArchiveURL(archiveName) {
+		if err := ValidateArchiveName(archiveName); err != nil {
+			return nil, err
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read extract directory: %w", err)
+	}
+	if len(entries) > 0 {
+		return nil, fmt.Errorf("extract directory %s is not empty", dir)
+	}
+
+	clusterResources, namespacedResources, _, err := bm.readStoredArchive(ctx, storagePath, archiveName,
+		RestoreOptions{KeyWrappers: opts.KeyWrappers}.keyWrappersFor(ctx), "")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ExtractResult{}
+	export := newExportWriter(dir, opts.IncludeSecrets)
+	read := 0
+	for _, res := range append(clusterResources, namespacedResources...) {
+		if res.err != nil {
+			result.FailedEntries = append(result.FailedEntries, res.err.Error())
+			continue
+		}
+		if err := export.writeObject(path.Join(archiveDir(res.gvr, res.namespace), res.name+".json"), res.object); err != nil {
+			return nil, err
+		}
+		read++
+	}
+	if err := export.Close(); err != nil {
+		return nil, err
+	}
+	for _, files := range export.resources {
+		result.Objects += len(files)
+	}
+	result.Skipped = read - result.Objects
+	return result, nil
+}
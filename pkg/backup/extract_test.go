@@ -0,0 +1,50 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtractArchive(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storageDir := t.TempDir()
+	bm := &BackupManager{}
+	archiveName := "cluster-backup-20260101-000000.tar.gz"
+	writeRestoreArchive(t, filepath.Join(storageDir, archiveName))
+
+	dir := filepath.Join(t.TempDir(), "recovered")
+	result, err := bm.ExtractArchive(ctx, storageDir, archiveName, dir, ExtractOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Objects != 2 || len(result.FailedEntries) != 0 {
+		t.Fatalf("got %+v", result)
+	}
+
+	root, err := os.ReadFile(filepath.Join(dir, kustomizationName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(root), "- cluster") || !strings.Contains(string(root), "- namespaces/restore-ns") {
+		t.Fatalf("root kustomization does not reference every base:\n%s", root)
+	}
+	namespace, err := os.ReadFile(filepath.Join(dir, "namespaces", "restore-ns", kustomizationName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(namespace), "v1/configmaps/sample-config.yaml") {
+		t.Fatalf("namespace kustomization is missing the ConfigMap:\n%s", namespace)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "cluster", "v1", "namespaces", "restore-ns.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := bm.ExtractArchive(ctx, storageDir, archiveName, dir, ExtractOptions{}); err == nil {
+		t.Fatal("expected extracting into a non-empty directory to fail")
+	}
+}
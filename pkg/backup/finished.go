@@ -0,0 +1,45 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// isFinishedWorkload reports whether obj is a Pod that succeeded or failed
+// or a Job that completed or failed. Neither runs again once restored, so
+// they only clutter archives and the clusters restored from them.
+func isFinishedWorkload(obj map[string]interface{}) bool {
+	u := unstructured.Unstructured{Object: obj}
+	switch {
+	case u.GetAPIVersion() == "v1" && u.GetKind() == "Pod":
+		phase, _, _ := unstructured.NestedString(obj, "status", "phase")
+		return phase == "Succeeded" || phase == "Failed"
+	case u.GetAPIVersion() == "batch/v1" && u.GetKind() == "Job":
+		conditions, _, _ := unstructured.NestedSlice(obj, "status", "conditions")
+		for _, c := range conditions {
+			condition, ok := c.(map[string]interface{})
+			if !ok || condition["status"] != "True" {
+				continue
+			}
+			if condition["type"] == "Complete" || condition["type"] == "Failed" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestIsFinishedWorkload(t *testing.T) {
+	t.Parallel()
+
+	job := func(conditionType, status string) map[string]interface{} {
+		return map[string]interface{}{
+			"apiVersion": "batch/v1", "kind": "Job",
+			"metadata": map[string]interface{}{"name": "migrate", "namespace": "apps"},
+			"status": map[string]interface{}{"conditions": []interface{}{
+				map[string]interface{}{"type": conditionType, "status": status},
+			}},
+		}
+	}
+	for _, tc := range []struct {
+		name string
+		obj  map[string]interface{}
+		want bool
+	}{
+		{"succeeded pod", podInPhase("Succeeded").Object, true},
+		{"failed pod", podInPhase("Failed").Object, true},
+		{"running pod", podInPhase("Running").Object, false},
+		{"completed job", job("Complete", "True"), true},
+		{"failed job", job("Failed", "True"), true},
+		{"suspended job", job("Suspended", "True"), false},
+		{"running job", job("Complete", "False"), false},
+		{"configmap", configMapObject("apps", "settings").Object, false},
+	} {
+		if got := isFinishedWorkload(tc.obj); got != tc.want {
+			t.Errorf("isFinishedWorkload(%s) = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestWriteBackupLeavesOutFinishedPods(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	running, succeeded := podInPhase("Running"), podInPhase("Succeeded")
+	running.SetName("web")
+	succeeded.SetName("migrate")
+	bm := &BackupManager{
+		DynamicClient: fake.NewSimpleDynamicClient(scheme, running, succeeded),
+		DiscoveryClient: versionedResources{preferredResources{lists: []*metav1.APIResourceList{
+			{GroupVersion: "v1", APIResources: []metav1.APIResource{
+				{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: []string{"list"}},
+			}},
+		}}},
+	}
+
+	for _, tc := range []struct {
+		include bool
+		want    int
+	}{
+		{include: false, want: 1},
+		{include: true, want: 2},
+	} {
+		result, err := bm.WriteBackup(context.Background(), &bytes.Buffer{}, BackupOptions{
+			IncludeNamespaces:        []string{"apps"},
+			ResourceTypes:            []string{"Pod"},
+			IncludeFinishedWorkloads: tc.include,
+		})
+		if err != nil {
+			t.Fatalf("WriteBackup() error = %v", err)
+		}
+		if result.ResourceCount != tc.want {
+			t.Errorf("IncludeFinishedWorkloads=%v: backed up %d pods, want %d", tc.include, result.ResourceCount, tc.want)
+		}
+	}
+}
+
+func podInPhase(phase string) *unstructured.Unstructured {
+	pod := podObject("apps", "pod", "100m", "")
+	pod.Object["status"] = map[string]interface{}{"phase": phase}
+	return pod
+}
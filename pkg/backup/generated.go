@@ -0,0 +1,64 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// serviceAccountTokenType is the type of the legacy token Secrets,
+	// including the <serviceaccount>-token-* Secrets generated before
+	// Kubernetes 1.24.
+	serviceAccountTokenType = "kubernetes.io/service-account-token"
+	// rootCAConfigMapName is published into every namespace by the
+	// kube-controller-manager.
+	rootCAConfigMapName = "kube-root-ca.crt"
+)
+
+// isClusterGenerated reports whether obj is generated by Kubernetes for the
+// cluster it lives in. Its content is only valid there: token Secrets are
+// signed by the cluster's service account key and kube-root-ca.crt holds its
+// CA, and the target cluster creates its own copies.
+func isClusterGenerated(obj map[string]interface{}) bool {
+	u := unstructured.Unstructured{Object: obj}
+	if u.GetAPIVersion() != "v1" {
+		return false
+	}
+	switch u.GetKind() {
+	case "Secret":
+		secretType, _, _ := unstructured.NestedString(obj, "type")
+		return secretType == serviceAccountTokenType
+	case "ConfigMap":
+		return u.GetName() == rootCAConfigMapName
+	}
+	return false
+}
+
+// excludeResources splits resources into those to restore and those matched
+// by exclude.
+func excludeResources(resources []archivedResource, exclude func(obj map[string]interface{}) bool) ([]archivedResource, []archivedResource) {
+	var kept, excluded []archivedResource
+	for _, res := range resources {
+		if res.err == nil && exclude(res.object) {
+			excluded = append(excluded, res)
+		} else {
+			kept = append(kept, res)
+		}
+	}
+	return kept, excluded
+}
@@ -0,0 +1,84 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestIsClusterGenerated(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		obj  map[string]interface{}
+		want bool
+	}{
+		"legacy token secret": {obj: map[string]interface{}{
+			"apiVersion": "v1", "kind": "Secret", "type": serviceAccountTokenType,
+			"metadata": map[string]interface{}{"name": "default-token-x7k2p", "namespace": "apps"},
+		}, want: true},
+		"root CA configmap": {obj: configMapObject("apps", rootCAConfigMapName).Object, want: true},
+		"opaque secret": {obj: map[string]interface{}{
+			"apiVersion": "v1", "kind": "Secret", "type": "Opaque",
+			"metadata": map[string]interface{}{"name": "default-token-x7k2p", "namespace": "apps"},
+		}, want: false},
+		"other configmap": {obj: configMapObject("apps", "settings").Object, want: false},
+	}
+	for name, tc := range cases {
+		if got := isClusterGenerated(tc.obj); got != tc.want {
+			t.Errorf("%s: expected isClusterGenerated %v, got %v", name, tc.want, got)
+		}
+	}
+}
+
+func TestRestoreBackupSkipsClusterGeneratedResources(t *testing.T) {
+	t.Parallel()
+
+	storageDir := t.TempDir()
+	archiveName := "cluster-backup-generated.tar.gz"
+	file, err := os.Create(filepath.Join(storageDir, archiveName))
+	if err != nil {
+		t.Fatalf("failed to create archive: %v", err)
+	}
+	gz := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gz)
+	writeJSONTarEntry(t, tarWriter, "namespaces/apps/v1/configmaps/kube-root-ca.crt.json", configMapObject("apps", rootCAConfigMapName).Object)
+	writeJSONTarEntry(t, tarWriter, "namespaces/apps/v1/configmaps/settings.json", configMapObject("apps", "settings").Object)
+	writeJSONTarEntry(t, tarWriter, "namespaces/apps/v1/secrets/default-token-x7k2p.json", map[string]interface{}{
+		"apiVersion": "v1", "kind": "Secret", "type": serviceAccountTokenType,
+		"metadata": map[string]interface{}{"name": "default-token-x7k2p", "namespace": "apps"},
+	})
+	for _, closer := range []interface{ Close() error }{tarWriter, gz, file} {
+		if err := closer.Close(); err != nil {
+			t.Fatalf("failed to finalize archive: %v", err)
+		}
+	}
+
+	for _, tc := range []struct {
+		include bool
+		want    RestoreCounts
+	}{
+		{include: false, want: RestoreCounts{Created: 1, Skipped: 2}},
+		{include: true, want: RestoreCounts{Created: 3}},
+	} {
+		scheme := runtime.NewScheme()
+		registerUnstructuredType(scheme, schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+		registerUnstructuredType(scheme, schema.GroupVersionKind{Version: "v1", Kind: "Secret"})
+		bm := &BackupManager{DynamicClient: fake.NewSimpleDynamicClient(scheme)}
+
+		result, err := bm.RestoreBackup(context.Background(), storageDir, archiveName, RestoreOptions{IncludeGeneratedResources: tc.include})
+		if err != nil {
+			t.Fatalf("RestoreBackup returned error: %v", err)
+		}
+		if result.RestoreCounts != tc.want {
+			t.Fatalf("IncludeGeneratedResources=%v: expected counts %+v, got %+v", tc.include, tc.want, result.RestoreCounts)
+		}
+	}
+}
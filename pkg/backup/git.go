@@ -0,0 +1,198 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+const (
+	defaultGitBranch = "main"
+
+	gitAuthorName  = "backup-operator"
+	gitAuthorEmail = "backup-operator@backup.io"
+)
+
+// GitRepository is the branch of a git remote that receives exports.
+type GitRepository struct {
+	URL string
+	// Branch defaults to main. It is created when the remote is empty.
+	Branch string
+	// Credentials, when set, authenticate to the remote.
+	Credentials *GitCredentials
+}
+
+// GitCredentials authenticate to a git remote, with a username and password
+// (or token) over HTTPS or with a private key over SSH.
+type GitCredentials struct {
+	Username string
+	Password string
+	// SSHPrivateKey is a PEM encoded private key for SSH remotes.
+	SSHPrivateKey []byte
+	// KnownHosts verifies the host key of SSH remotes.
+	KnownHosts []byte
+}
+
+// commitExport commits the export staged in stagedDir to repo under
+// exportPath and pushes it, returning the hash of the branch head. Nothing is
+// committed when the export matches the branch. The repository is cloned
+// next to stagedDir, so it is cleaned up with the run's staging directory.
+func commitExport(ctx context.Context, repo *GitRepository, stagedDir, exportPath, message string) (string, error) {
+	workDir, err := os.MkdirTemp(filepath.Dir(stagedDir), "git-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create git work directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	auth, err := gitAuth(repo, workDir)
+	if err != nil {
+		return "", err
+	}
+	branchName := repo.Branch
+	if branchName == "" {
+		branchName = defaultGitBranch
+	}
+	branch := plumbing.NewBranchReferenceName(branchName)
+
+	checkout := filepath.Join(workDir, "repository")
+	r, err := git.PlainCloneContext(ctx, checkout, false, &git.CloneOptions{
+		URL:           repo.URL,
+		Auth:          auth,
+		ReferenceName: branch,
+		SingleBranch:  true,
+	})
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		r, err = initExportRepository(checkout, repo.URL, branch)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to clone %s: %w", repo.URL, err)
+	}
+
+	target, err := exportDir(checkout, exportPath, true)
+	if err != nil {
+		return "", err
+	}
+	if err := syncDir(stagedDir, target); err != nil {
+		return "", err
+	}
+
+	worktree, err := r.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to open worktree: %w", err)
+	}
+	if err := worktree.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+		return "", fmt.Errorf("failed to stage export: %w", err)
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return "", fmt.Errorf("failed to read worktree status: %w", err)
+	}
+	if status.IsClean() {
+		head, err := r.Head()
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %s: %w", branchName, err)
+		}
+		return head.Hash().String(), nil
+	}
+
+	hash, err := worktree.Commit(message, &git.CommitOptions{
+		Author: &object.Signature{Name: gitAuthorName, Email: gitAuthorEmail, When: time.Now()},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to commit export: %w", err)
+	}
+	if err := r.PushContext(ctx, &git.PushOptions{
+		RemoteName: git.DefaultRemoteName,
+		Auth:       auth,
+		RefSpecs:   []config.RefSpec{config.RefSpec(branch + ":" + branch)},
+	}); err != nil {
+		return "", fmt.Errorf("failed to push to %s: %w", repo.URL, err)
+	}
+	return hash.String(), nil
+}
+
+// initExportRepository prepares a checkout for an empty remote whose first
+// commit creates branch.
+func initExportRepository(dir, url string, branch plumbing.ReferenceName) (*git.Repository, error) {
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, err
+	}
+	r, err := git.PlainInit(dir, false)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.CreateRemote(&config.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{url}}); err != nil {
+		return nil, err
+	}
+	if err := r.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, branch)); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// gitAuth converts the repository credentials into a go-git auth method.
+// Known hosts are written below workDir since go-git reads them from files.
+func gitAuth(repo *GitRepository, workDir string) (transport.AuthMethod, error) {
+	creds := repo.Credentials
+	if creds == nil {
+		return nil, nil
+	}
+	if len(creds.SSHPrivateKey) == 0 {
+		return &githttp.BasicAuth{Username: creds.Username, Password: creds.Password}, nil
+	}
+
+	user := creds.Username
+	if user == "" {
+		endpoint, err := transport.NewEndpoint(repo.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid repository URL %q: %w", repo.URL, err)
+		}
+		user = endpoint.User
+	}
+	if user == "" {
+		user = "git"
+	}
+	auth, err := gitssh.NewPublicKeys(user, creds.SSHPrivateKey, creds.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SSH private key: %w", err)
+	}
+	if len(creds.KnownHosts) > 0 {
+		knownHostsPath := filepath.Join(workDir, "known_hosts")
+		if err := os.WriteFile(knownHostsPath, creds.KnownHosts, 0o600); err != nil {
+			return nil, fmt.Errorf("failed to write known hosts: %w", err)
+		}
+		callback, err := gitssh.NewKnownHostsCallback(knownHostsPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load known hosts: %w", err)
+		}
+		auth.HostKeyCallback = callback
+	}
+	return auth, nil
+}
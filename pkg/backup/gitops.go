@@ -0,0 +1,55 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Metadata set by GitOps controllers on the objects they manage. Argo CD's
+// default label tracking uses app.kubernetes.io/instance, which Helm charts
+// set as well, so only its dedicated label and annotation tracking are
+// recognised.
+var (
+	gitOpsTrackingLabels = []string{
+		"argocd.argoproj.io/instance",
+		"kustomize.toolkit.fluxcd.io/name",
+		"helm.toolkit.fluxcd.io/name",
+	}
+	gitOpsTrackingAnnotations = []string{
+		"argocd.argoproj.io/tracking-id",
+	}
+)
+
+// isGitOpsManaged reports whether obj is tracked by Argo CD or Flux, which
+// recreate such objects from git on their own.
+func isGitOpsManaged(obj map[string]interface{}) bool {
+	u := unstructured.Unstructured{Object: obj}
+	labels := u.GetLabels()
+	for _, label := range gitOpsTrackingLabels {
+		if _, ok := labels[label]; ok {
+			return true
+		}
+	}
+	annotations := u.GetAnnotations()
+	for _, annotation := range gitOpsTrackingAnnotations {
+		if _, ok := annotations[annotation]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestIsGitOpsManaged(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		labels, annotations map[string]string
+		want                bool
+	}{
+		"argo cd label":      {labels: map[string]string{"argocd.argoproj.io/instance": "web"}, want: true},
+		"argo cd annotation": {annotations: map[string]string{"argocd.argoproj.io/tracking-id": "web:apps/Deployment:apps/web"}, want: true},
+		"flux kustomization": {labels: map[string]string{"kustomize.toolkit.fluxcd.io/name": "apps"}, want: true},
+		"flux helm release":  {labels: map[string]string{"helm.toolkit.fluxcd.io/name": "web"}, want: true},
+		"helm instance":      {labels: map[string]string{"app.kubernetes.io/instance": "web"}, want: false},
+		"unlabelled":         {want: false},
+	}
+	for name, tc := range cases {
+		obj := gitOpsConfigMap("sample", tc.labels, tc.annotations)
+		if got := isGitOpsManaged(obj.Object); got != tc.want {
+			t.Errorf("%s: expected isGitOpsManaged %v, got %v", name, tc.want, got)
+		}
+	}
+}
+
+func TestGitOpsManagedResourcesExcluded(t *testing.T) {
+	t.Parallel()
+
+	managed := gitOpsConfigMap("managed", map[string]string{"kustomize.toolkit.fluxcd.io/name": "apps"}, nil)
+	unmanaged := gitOpsConfigMap("unmanaged", nil, nil)
+	listPage := func(context.Context, metav1.ListOptions) (runtime.Object, error) {
+		return &unstructured.UnstructuredList{
+			Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMapList"},
+			Items:  []unstructured.Unstructured{*managed.DeepCopy(), *unmanaged.DeepCopy()},
+		}, nil
+	}
+
+	// Backup leaves managed objects out of the archive
+	var out bytes.Buffer
+	gz := gzip.NewWriter(&out)
+	archive := newArchiveWriter(gz)
+	archive.exclude = isGitOpsManaged
+	count, err := writeResourcePages(context.Background(), archive, "namespaces/apps/v1/configmaps", listPage)
+	if err != nil {
+		t.Fatalf("writeResourcePages returned error: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected only the unmanaged configmap to be written, got %d", count)
+	}
+
+	// Restore skips managed objects found in an archive
+	out.Reset()
+	gz.Reset(&out)
+	archive = newArchiveWriter(gz)
+	for _, obj := range []*unstructured.Unstructured{managed, unmanaged} {
+		if err := archive.writeObject("namespaces/apps/v1/configmaps/"+obj.GetName()+".json", obj.Object); err != nil {
+			t.Fatalf("writeObject failed: %v", err)
+		}
+	}
+	for _, closer := range []io.Closer{archive, gz} {
+		if err := closer.Close(); err != nil {
+			t.Fatalf("failed to finalize archive: %v", err)
+		}
+	}
+	storageDir := t.TempDir()
+	archiveName := "cluster-backup-gitops.tar.gz"
+	if err := os.WriteFile(filepath.Join(storageDir, archiveName), out.Bytes(), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	scheme := runtime.NewScheme()
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+	dynamicClient := fake.NewSimpleDynamicClient(scheme)
+	bm := &BackupManager{DynamicClient: dynamicClient}
+	result, err := bm.RestoreBackup(context.Background(), storageDir, archiveName, RestoreOptions{ExcludeGitOpsManaged: true})
+	if err != nil {
+		t.Fatalf("RestoreBackup returned error: %v", err)
+	}
+	if want := (RestoreCounts{Created: 1, Skipped: 1}); result.RestoreCounts != want {
+		t.Fatalf("expected counts %+v, got %+v", want, result.RestoreCounts)
+	}
+	configMapGVR := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	if _, err := dynamicClient.Resource(configMapGVR).Namespace("apps").Get(context.Background(), "managed", metav1.GetOptions{}); err == nil {
+		t.Fatalf("expected the GitOps managed configmap not to be restored")
+	}
+}
+
+func gitOpsConfigMap(name string, labels, annotations map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap"}}
+	obj.SetName(name)
+	obj.SetNamespace("apps")
+	obj.SetLabels(labels)
+	obj.SetAnnotations(annotations)
+	return obj
+}
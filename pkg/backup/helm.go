@@ -0,0 +1,194 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// helmReleaseSecretType is the Secret type Helm 3 stores release revisions as.
+const helmReleaseSecretType = "helm.sh/release.v1"
+
+// HelmReleasePolicy decides how a restore treats Helm release Secrets.
+type HelmReleasePolicy string
+
+const (
+	// HelmReleasePolicyIntact restores release Secrets like any other
+	// resource, bringing back the archived release history as it was.
+	HelmReleasePolicyIntact HelmReleasePolicy = "Intact"
+	// HelmReleasePolicyRollback keeps release Secrets that already exist,
+	// adds the archived revisions that are missing and reports the
+	// `helm rollback` commands returning each release to its archived
+	// revision.
+	HelmReleasePolicyRollback HelmReleasePolicy = "Rollback"
+)
+
+// helmRelease describes one archived Helm release revision.
+type helmRelease struct {
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace"`
+	Revision     int    `json:"revision"`
+	Status       string `json:"status,omitempty"`
+	Chart        string `json:"chart,omitempty"`
+	ChartVersion string `json:"chartVersion,omitempty"`
+	AppVersion   string `json:"appVersion,omitempty"`
+}
+
+// helmReleaseRecord is the subset of Helm's release encoding that is read.
+type helmReleaseRecord struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Version   int    `json:"version"`
+	Info      struct {
+		Status string `json:"status"`
+	} `json:"info"`
+	Chart struct {
+		Metadata struct {
+			Name       string `json:"name"`
+			Version    string `json:"version"`
+			AppVersion string `json:"appVersion"`
+		} `json:"metadata"`
+	} `json:"chart"`
+}
+
+// isHelmReleaseSecret reports whether obj is a Helm release Secret.
+func isHelmReleaseSecret(obj map[string]interface{}) bool {
+	kind, _ := obj["kind"].(string)
+	secretType, _ := obj["type"].(string)
+	return kind == "Secret" && secretType == helmReleaseSecretType
+}
+
+// helmReleaseFromSecret describes the release revision stored in a Helm
+// release Secret. The Secret labels identify the revision; the chart is read
+// from the encoded release when it can be decoded.
+func helmReleaseFromSecret(obj map[string]interface{}) (helmRelease, bool) {
+	if !isHelmReleaseSecret(obj) {
+		return helmRelease{}, false
+	}
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	labels, _ := metadata["labels"].(map[string]interface{})
+	release := helmRelease{}
+	release.Name, _ = labels["name"].(string)
+	release.Status, _ = labels["status"].(string)
+	release.Namespace, _ = metadata["namespace"].(string)
+	if version, ok := labels["version"].(string); ok {
+		release.Revision, _ = strconv.Atoi(version)
+	}
+
+	data, _ := obj["data"].(map[string]interface{})
+	if encoded, ok := data["release"].(string); ok {
+		if record, err := decodeHelmRelease(encoded); err == nil {
+			if release.Name == "" {
+				release.Name = record.Name
+			}
+			if release.Revision == 0 {
+				release.Revision = record.Version
+			}
+			if release.Status == "" {
+				release.Status = record.Info.Status
+			}
+			release.Chart = record.Chart.Metadata.Name
+			release.ChartVersion = record.Chart.Metadata.Version
+			release.AppVersion = record.Chart.Metadata.AppVersion
+		}
+	}
+	return release, release.Name != "" && release.Revision > 0
+}
+
+// decodeHelmRelease decodes the release field of a Helm release Secret: the
+// Secret's own base64 around Helm's base64 encoded, gzipped JSON.
+func decodeHelmRelease(secretData string) (*helmReleaseRecord, error) {
+	helmEncoded, err := base64.StdEncoding.DecodeString(secretData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode secret data: %w", err)
+	}
+	payload, err := base64.StdEncoding.DecodeString(string(helmEncoded))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode release: %w", err)
+	}
+	if bytes.HasPrefix(payload, gzipMagic) {
+		gr, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress release: %w", err)
+		}
+		defer gr.Close()
+		if payload, err = io.ReadAll(gr); err != nil {
+			return nil, fmt.Errorf("failed to decompress release: %w", err)
+		}
+	}
+
+	record := &helmReleaseRecord{}
+	if err := json.Unmarshal(payload, record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal release: %w", err)
+	}
+	return record, nil
+}
+
+// sortHelmReleases orders releases by namespace, name and revision.
+func sortHelmReleases(releases []helmRelease) {
+	sort.Slice(releases, func(i, j int) bool {
+		a, b := releases[i], releases[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Revision < b.Revision
+	})
+}
+
+// helmRollbackCommands returns, per release, the `helm rollback` command that
+// returns it to the revision that was deployed when the archive was taken,
+// or to its latest archived revision when none was deployed.
+func helmRollbackCommands(releases []helmRelease) []string {
+	type releaseKey struct{ namespace, name string }
+	targets := map[releaseKey]helmRelease{}
+	for _, release := range releases {
+		key := releaseKey{release.Namespace, release.Name}
+		current, ok := targets[key]
+		deployed := release.Status == "deployed"
+		switch {
+		case !ok:
+			targets[key] = release
+		case deployed && (current.Status != "deployed" || release.Revision > current.Revision):
+			targets[key] = release
+		case !deployed && current.Status != "deployed" && release.Revision > current.Revision:
+			targets[key] = release
+		}
+	}
+
+	selected := make([]helmRelease, 0, len(targets))
+	for _, release := range targets {
+		selected = append(selected, release)
+	}
+	sortHelmReleases(selected)
+
+	commands := make([]string, 0, len(selected))
+	for _, release := range selected {
+		commands = append(commands, fmt.Sprintf("helm rollback %s %d --namespace %s",
+			release.Name, release.Revision, release.Namespace))
+	}
+	return commands
+}
@@ -0,0 +1,198 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestArchiveManifestInventoriesHelmReleases(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	archive := newArchiveWriter(&out)
+	for _, secret := range []*unstructured.Unstructured{
+		helmReleaseSecret(t, "web", "apps", 1, "superseded"),
+		helmReleaseSecret(t, "web", "apps", 2, "deployed"),
+	} {
+		name := fmt.Sprintf("namespaces/apps/v1/secrets/%s.json", secret.GetName())
+		if err := archive.writeObject(name, secret.Object); err != nil {
+			t.Fatalf("writeObject failed: %v", err)
+		}
+	}
+	if err := archive.writeObject("namespaces/apps/v1/secrets/plain.json", map[string]interface{}{
+		"apiVersion": "v1", "kind": "Secret", "type": "Opaque",
+		"metadata": map[string]interface{}{"name": "plain", "namespace": "apps"},
+	}); err != nil {
+		t.Fatalf("writeObject failed: %v", err)
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("failed closing archive: %v", err)
+	}
+
+	manifest := readTestManifest(t, &out)
+	want := []helmRelease{
+		{Name: "web", Namespace: "apps", Revision: 1, Status: "superseded", Chart: "web", ChartVersion: "1.0.1", AppVersion: "2.1.0"},
+		{Name: "web", Namespace: "apps", Revision: 2, Status: "deployed", Chart: "web", ChartVersion: "1.0.2", AppVersion: "2.1.0"},
+	}
+	if len(manifest.HelmReleases) != len(want) {
+		t.Fatalf("expected %d helm releases, got %+v", len(want), manifest.HelmReleases)
+	}
+	for i := range want {
+		if manifest.HelmReleases[i] != want[i] {
+			t.Fatalf("expected helm release %+v, got %+v", want[i], manifest.HelmReleases[i])
+		}
+	}
+}
+
+func TestHelmRollbackCommands(t *testing.T) {
+	t.Parallel()
+
+	commands := helmRollbackCommands([]helmRelease{
+		{Name: "web", Namespace: "apps", Revision: 3, Status: "failed"},
+		{Name: "web", Namespace: "apps", Revision: 2, Status: "deployed"},
+		{Name: "web", Namespace: "apps", Revision: 1, Status: "superseded"},
+		{Name: "db", Namespace: "data", Revision: 4, Status: "pending-upgrade"},
+		{Name: "db", Namespace: "data", Revision: 5, Status: "failed"},
+	})
+	want := []string{
+		"helm rollback web 2 --namespace apps",
+		"helm rollback db 5 --namespace data",
+	}
+	if len(commands) != len(want) || commands[0] != want[0] || commands[1] != want[1] {
+		t.Fatalf("expected %v, got %v", want, commands)
+	}
+}
+
+func TestRestoreBackupHelmRollbackPolicy(t *testing.T) {
+	t.Parallel()
+
+	storageDir := t.TempDir()
+	archiveName := "cluster-backup-helm.tar.gz"
+	file, err := os.Create(filepath.Join(storageDir, archiveName))
+	if err != nil {
+		t.Fatalf("failed to create archive: %v", err)
+	}
+	gz := gzip.NewWriter(file)
+	archive := newArchiveWriter(gz)
+	for revision, status := range map[int]string{1: "superseded", 2: "deployed"} {
+		secret := helmReleaseSecret(t, "web", "apps", revision, status)
+		if err := archive.writeObject("namespaces/apps/v1/secrets/"+secret.GetName()+".json", secret.Object); err != nil {
+			t.Fatalf("writeObject failed: %v", err)
+		}
+	}
+	for _, closer := range []io.Closer{archive, gz, file} {
+		if err := closer.Close(); err != nil {
+			t.Fatalf("failed to finalize archive: %v", err)
+		}
+	}
+
+	// The cluster already has its own first revision of the release
+	live := helmReleaseSecret(t, "web", "apps", 1, "deployed")
+	scheme := runtime.NewScheme()
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Version: "v1", Kind: "Secret"})
+	dynamicClient := fake.NewSimpleDynamicClient(scheme, live)
+
+	bm := &BackupManager{DynamicClient: dynamicClient}
+	result, err := bm.RestoreBackup(context.Background(), storageDir, archiveName, RestoreOptions{
+		HelmReleasePolicy: HelmReleasePolicyRollback,
+	})
+	if err != nil {
+		t.Fatalf("RestoreBackup returned error: %v", err)
+	}
+	if want := (RestoreCounts{Created: 1, Skipped: 1}); result.RestoreCounts != want {
+		t.Fatalf("expected counts %+v, got %+v", want, result.RestoreCounts)
+	}
+	if len(result.HelmRollbackCommands) != 1 || result.HelmRollbackCommands[0] != "helm rollback web 2 --namespace apps" {
+		t.Fatalf("expected a rollback to revision 2, got %v", result.HelmRollbackCommands)
+	}
+
+	secretGVR := schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	kept, err := dynamicClient.Resource(secretGVR).Namespace("apps").Get(context.Background(), live.GetName(), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get release secret: %v", err)
+	}
+	if kept.GetLabels()["status"] != "deployed" {
+		t.Fatalf("expected the live release revision to be kept, got status %q", kept.GetLabels()["status"])
+	}
+}
+
+func helmReleaseSecret(t *testing.T, name, namespace string, revision int, status string) *unstructured.Unstructured {
+	t.Helper()
+
+	record, err := json.Marshal(map[string]interface{}{
+		"name":      name,
+		"namespace": namespace,
+		"version":   revision,
+		"info":      map[string]interface{}{"status": status},
+		"chart": map[string]interface{}{"metadata": map[string]interface{}{
+			"name": name, "version": fmt.Sprintf("1.0.%d", revision), "appVersion": "2.1.0",
+		}},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal release: %v", err)
+	}
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	if _, err := gz.Write(record); err != nil {
+		t.Fatalf("failed to compress release: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("failed to compress release: %v", err)
+	}
+	helmEncoded := base64.StdEncoding.EncodeToString(compressed.Bytes())
+
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"type":       helmReleaseSecretType,
+		"metadata": map[string]interface{}{
+			"name":      fmt.Sprintf("sh.helm.release.v1.%s.v%d", name, revision),
+			"namespace": namespace,
+			"labels": map[string]interface{}{
+				"name": name, "owner": "helm", "status": status, "version": fmt.Sprint(revision),
+			},
+		},
+		"data": map[string]interface{}{
+			"release": base64.StdEncoding.EncodeToString([]byte(helmEncoded)),
+		},
+	}}
+}
+
+func readTestManifest(t *testing.T, r io.Reader) *archiveManifest {
+	t.Helper()
+
+	tarReader := tar.NewReader(r)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			t.Fatalf("archive has no %s", manifestName)
+		}
+		if err != nil {
+			t.Fatalf("failed reading archive: %v", err)
+		}
+		if header.Name != manifestName {
+			continue
+		}
+		manifest := &archiveManifest{}
+		if err := json.NewDecoder(tarReader).Decode(manifest); err != nil {
+			t.Fatalf("failed decoding manifest: %v", err)
+		}
+		return manifest
+	}
+}
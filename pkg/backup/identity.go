@@ -0,0 +1,140 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// ClusterMismatchPolicy decides how a restore reacts when the archive was
+// taken from a different cluster than the one it is restored into.
+type ClusterMismatchPolicy string
+
+const (
+	// ClusterMismatchPolicyWarn reports the mismatch and restores anyway.
+	ClusterMismatchPolicyWarn ClusterMismatchPolicy = "Warn"
+	// ClusterMismatchPolicyFail aborts the restore before anything is applied.
+	ClusterMismatchPolicyFail ClusterMismatchPolicy = "Fail"
+	// ClusterMismatchPolicyIgnore skips the check.
+	ClusterMismatchPolicyIgnore ClusterMismatchPolicy = "Ignore"
+)
+
+// ErrClusterMismatch is returned by restores under ClusterMismatchPolicyFail
+// whose archive was taken from another cluster.
+var ErrClusterMismatch = errors.New("archive was taken from a different cluster")
+
+const kubeSystemNamespace = "kube-system"
+
+var nodesResource = schema.GroupVersionResource{Version: "v1", Resource: "nodes"}
+
+// ClusterIdentity describes the cluster an archive was taken from.
+type ClusterIdentity struct {
+	// KubeSystemUID is the UID of the kube-system namespace, which lives as
+	// long as the cluster and differs between clusters.
+	KubeSystemUID string `json:"kubeSystemUID,omitempty"`
+	// ServerVersion is the Kubernetes version of the API server.
+	ServerVersion string `json:"serverVersion,omitempty"`
+	// NodeCount is the number of nodes when the archive was taken.
+	NodeCount int `json:"nodeCount,omitempty"`
+}
+
+func (c ClusterIdentity) String() string {
+	s := "kube-system UID " + c.KubeSystemUID
+	if c.ServerVersion != "" {
+		s += ", " + c.ServerVersion
+	}
+	if c.NodeCount > 0 {
+		s += fmt.Sprintf(", %d nodes", c.NodeCount)
+	}
+	return s
+}
+
+// clusterIdentity describes the manager's cluster. It is best effort: what
+// cannot be read is logged and left out, and nil is returned when nothing
+// could be read.
+func (bm *BackupManager) clusterIdentity(ctx context.Context) *ClusterIdentity {
+	log := ctrl.LoggerFrom(ctx)
+	identity := &ClusterIdentity{}
+
+	uid, err := bm.kubeSystemUID(ctx)
+	if err != nil {
+		log.Error(err, "Failed to read the cluster identity, the archive will not record it")
+	}
+	identity.KubeSystemUID = uid
+
+	if bm.DiscoveryClient != nil {
+		if version, err := bm.DiscoveryClient.ServerVersion(); err != nil {
+			log.Error(err, "Failed to read the server version, the archive will not record it")
+		} else {
+			identity.ServerVersion = version.GitVersion
+		}
+	}
+
+	if nodes, err := bm.DynamicClient.Resource(nodesResource).List(ctx, metav1.ListOptions{}); err != nil {
+		log.Error(err, "Failed to count nodes, the archive will not record it")
+	} else {
+		identity.NodeCount = len(nodes.Items)
+	}
+
+	if *identity == (ClusterIdentity{}) {
+		return nil
+	}
+	return identity
+}
+
+// kubeSystemUID returns the UID of the kube-system namespace.
+func (bm *BackupManager) kubeSystemUID(ctx context.Context) (string, error) {
+	namespace, err := bm.DynamicClient.Resource(namespacesResource.WithVersion("v1")).Get(ctx, kubeSystemNamespace, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get namespace %s: %w", kubeSystemNamespace, err)
+	}
+	return string(namespace.GetUID()), nil
+}
+
+// checkClusterIdentity compares the cluster source was taken from with the
+// manager's cluster. It returns a description of the mismatch, or an error
+// under ClusterMismatchPolicyFail. Archives that did not record their
+// cluster are not checked.
+func (bm *BackupManager) checkClusterIdentity(ctx context.Context, source *ClusterIdentity, policy ClusterMismatchPolicy) (string, error) {
+	if policy == ClusterMismatchPolicyIgnore || source == nil || source.KubeSystemUID == "" {
+		return "", nil
+	}
+
+	uid, err := bm.kubeSystemUID(ctx)
+	if err != nil {
+		if policy == ClusterMismatchPolicyFail {
+			return "", fmt.Errorf("failed to identify the target cluster: %w", err)
+		}
+		ctrl.LoggerFrom(ctx).Error(err, "Failed to identify the target cluster, restoring anyway")
+		return "", nil
+	}
+	if uid == source.KubeSystemUID {
+		return "", nil
+	}
+
+	mismatch := fmt.Sprintf("archive was taken from cluster with %s, target cluster has kube-system UID %s", source, uid)
+	if policy == ClusterMismatchPolicyFail {
+		return "", fmt.Errorf("%w: %s", ErrClusterMismatch, mismatch)
+	}
+	return mismatch, nil
+}
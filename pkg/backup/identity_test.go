@@ -0,0 +1,66 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestClusterIdentity(t *testing.T) {
+	t.Parallel()
+
+	bm := newIdentityTestManager(t, "source-uid", 2)
+	identity := bm.clusterIdentity(context.Background())
+	if identity == nil || identity.KubeSystemUID != "source-uid" || identity.NodeCount != 2 {
+		t.Fatalf("clusterIdentity = %+v, want kube-system UID source-uid and 2 nodes", identity)
+	}
+}
+
+func TestCheckClusterIdentity(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	bm := newIdentityTestManager(t, "target-uid", 1)
+	source := &ClusterIdentity{KubeSystemUID: "source-uid", ServerVersion: "v1.33.1", NodeCount: 3}
+
+	mismatch, err := bm.checkClusterIdentity(ctx, source, ClusterMismatchPolicyWarn)
+	if err != nil || mismatch == "" {
+		t.Fatalf("Warn: mismatch = %q, err = %v, want a mismatch and no error", mismatch, err)
+	}
+	if _, err := bm.checkClusterIdentity(ctx, source, ClusterMismatchPolicyFail); !errors.Is(err, ErrClusterMismatch) {
+		t.Fatalf("Fail: expected ErrClusterMismatch, got %v", err)
+	}
+	if mismatch, err := bm.checkClusterIdentity(ctx, source, ClusterMismatchPolicyIgnore); err != nil || mismatch != "" {
+		t.Fatalf("Ignore: mismatch = %q, err = %v, want neither", mismatch, err)
+	}
+
+	// The same cluster and archives without an identity are not reported
+	for _, source := range []*ClusterIdentity{{KubeSystemUID: "target-uid"}, nil} {
+		if mismatch, err := bm.checkClusterIdentity(ctx, source, ClusterMismatchPolicyFail); err != nil || mismatch != "" {
+			t.Fatalf("source %+v: mismatch = %q, err = %v, want neither", source, mismatch, err)
+		}
+	}
+}
+
+func newIdentityTestManager(t *testing.T, kubeSystemUID string, nodes int) *BackupManager {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed adding corev1 to scheme: %v", err)
+	}
+	objects := []runtime.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system", UID: types.UID(kubeSystemUID)}},
+	}
+	for i := 0; i < nodes; i++ {
+		objects = append(objects, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node-%d", i)}})
+	}
+	return &BackupManager{DynamicClient: fake.NewSimpleDynamicClient(scheme, objects...)}
+}
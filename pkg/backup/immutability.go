@@ -0,0 +1,153 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// lockSuffix names the sidecar file holding the lock of an archive.
+const lockSuffix = ".lock"
+
+// ErrArchiveImmutable is reported when an archive cannot be deleted or moved
+// because it is locked.
+var ErrArchiveImmutable = errors.New("archive is immutable")
+
+// Immutability locks archives against deletion by the operator, either until
+// a retention period has passed or, with LegalHold, until the lock is removed.
+type Immutability struct {
+	RetainFor time.Duration
+	LegalHold bool
+}
+
+// archiveLock is the content of an archive's lock file.
+type archiveLock struct {
+	RetainUntil *time.Time `json:"retainUntil,omitempty"`
+	LegalHold   bool       `json:"legalHold,omitempty"`
+}
+
+// ImmutableArchivesError lists the archives retention had to keep because
+// they are locked. It matches ErrArchiveImmutable.
+type ImmutableArchivesError struct {
+	Archives []string
+}
+
+func (e *ImmutableArchivesError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrArchiveImmutable, strings.Join(e.Archives, ", "))
+}
+
+func (e *ImmutableArchivesError) Is(target error) bool {
+	return target == ErrArchiveImmutable
+}
+
+// lockArchive writes the lock file of archivePath and makes the archive
+// read-only. Locks only ever extend an existing retention period.
+func lockArchive(archivePath string, immutability Immutability, now time.Time) error {
+	lock, err := readArchiveLock(archivePath)
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		lock = &archiveLock{}
+	}
+	if immutability.RetainFor > 0 {
+		retainUntil := now.Add(immutability.RetainFor).UTC()
+		if lock.RetainUntil == nil || lock.RetainUntil.Before(retainUntil) {
+			lock.RetainUntil = &retainUntil
+		}
+	}
+	lock.LegalHold = lock.LegalHold || immutability.LegalHold
+
+	data, err := json.Marshal(lock)
+	if err != nil {
+		return fmt.Errorf("failed to encode archive lock: %w", err)
+	}
+	lockPath := archivePath + lockSuffix
+	_ = os.Chmod(lockPath, 0644)
+	if err := os.WriteFile(lockPath, data, 0444); err != nil {
+		return fmt.Errorf("failed to write archive lock: %w", err)
+	}
+	if err := os.Chmod(archivePath, 0444); err != nil {
+		return fmt.Errorf("failed to make archive read-only: %w", err)
+	}
+	return nil
+}
+
+// readArchiveLock returns the lock of archivePath, or nil when it has none.
+func readArchiveLock(archivePath string) (*archiveLock, error) {
+	data, err := os.ReadFile(archivePath + lockSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive lock: %w", err)
+	}
+	lock := &archiveLock{}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("failed to decode archive lock %s: %w", filepath.Base(archivePath)+lockSuffix, err)
+	}
+	return lock, nil
+}
+
+// checkArchiveMutable returns ErrArchiveImmutable, wrapped with the reason,
+// while archivePath is locked. An unreadable lock counts as locked.
+func checkArchiveMutable(archivePath string, now time.Time) error {
+	lock, err := readArchiveLock(archivePath)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrArchiveImmutable, err)
+	}
+	switch {
+	case lock == nil:
+		return nil
+	case lock.LegalHold:
+		return fmt.Errorf("%w: %s is under legal hold", ErrArchiveImmutable, filepath.Base(archivePath))
+	case lock.RetainUntil != nil && now.Before(*lock.RetainUntil):
+		return fmt.Errorf("%w: %s is retained until %s", ErrArchiveImmutable, filepath.Base(archivePath),
+			lock.RetainUntil.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// removeArchive deletes an archive whose lock has expired, along with its
+// lock file, keep marker, rotation tag and key list, and drops it from the
+// catalog.
+func removeArchive(archivePath string) error {
+	if err := removeArchiveFiles(archivePath); err != nil {
+		return err
+	}
+	if err := uncatalogArchives(filepath.Dir(archivePath), filepath.Base(archivePath)); err != nil {
+		return fmt.Errorf("failed to update catalog: %w", err)
+	}
+	return nil
+}
+
+// removeArchiveFiles deletes an archive and the files kept next to it.
+func removeArchiveFiles(archivePath string) error {
+	for _, path := range []string{archivePath, archivePath + lockSuffix, archivePath + keepSuffix, archivePath + rotationSuffix,
+		archivePath + baseSuffix, archivePath + keysSuffix} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCleanupArchivesKeepsLockedArchives(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	old := time.Now().Add(-10 * 24 * time.Hour)
+	names := []string{
+		"cluster-backup-20250101-000000.tar.gz", // retained
+		"cluster-backup-20250102-000000.tar.gz", // legal hold
+		"cluster-backup-20250103-000000.tar.gz", // lock expired
+		"cluster-backup-20250104-000000.tar.gz", // unlocked
+	}
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now()
+	if err := lockArchive(filepath.Join(dir, names[0]), Immutability{RetainFor: 24 * time.Hour}, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := lockArchive(filepath.Join(dir, names[1]), Immutability{LegalHold: true}, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := lockArchive(filepath.Join(dir, names[2]), Immutability{RetainFor: time.Hour}, now.Add(-2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		if err := os.Chtimes(filepath.Join(dir, name), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	retentionDays := 1
+	_, err := (&BackupManager{}).CleanupArchives(dir, &retentionDays, nil)
+	var immutable *ImmutableArchivesError
+	if !errors.As(err, &immutable) || !errors.Is(err, ErrArchiveImmutable) {
+		t.Fatalf("CleanupArchives error = %v, want ImmutableArchivesError", err)
+	}
+	if len(immutable.Archives) != 2 || immutable.Archives[0] != names[0] || immutable.Archives[1] != names[1] {
+		t.Fatalf("kept %v, want the retained and held archives", immutable.Archives)
+	}
+
+	for i, name := range names {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if exists := err == nil; exists != (i < 2) {
+			t.Fatalf("%s exists = %v", name, exists)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, names[2]+lockSuffix)); !os.IsNotExist(err) {
+		t.Fatalf("expired lock file left behind: %v", err)
+	}
+	if info, err := os.Stat(filepath.Join(dir, names[0])); err != nil || info.Mode().Perm()&0o222 != 0 {
+		t.Fatalf("locked archive is writable: %v, %v", info.Mode(), err)
+	}
+}
+
+func TestLockArchiveOnlyExtendsRetention(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "cluster-backup-20250101-000000.tar.gz")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if err := lockArchive(path, Immutability{RetainFor: 48 * time.Hour}, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := lockArchive(path, Immutability{RetainFor: time.Hour}, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkArchiveMutable(path, now.Add(24*time.Hour)); !errors.Is(err, ErrArchiveImmutable) {
+		t.Fatalf("retention was shortened: %v", err)
+	}
+	if err := checkArchiveMutable(path, now.Add(72*time.Hour)); err != nil {
+		t.Fatalf("lock did not expire: %v", err)
+	}
+}
+
+func TestTransferArchiveRefusesToMoveLockedArchive(t *testing.T) {
+	t.Parallel()
+
+	const archive = "cluster-backup-20250101-000000.tar.gz"
+	source := t.TempDir()
+	path := filepath.Join(source, archive)
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := lockArchive(path, Immutability{LegalHold: true}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := (&BackupManager{}).TransferArchive(context.Background(), source, archive, t.TempDir(), true)
+	if !errors.Is(err, ErrArchiveImmutable) {
+		t.Fatalf("move of a held archive: %v", err)
+	}
+	if _, err := (&BackupManager{}).TransferArchive(context.Background(), source, archive, t.TempDir(), false); err != nil {
+		t.Fatalf("copy of a held archive: %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+// serviceAccountUserPrefix starts the user names of ServiceAccounts.
+const serviceAccountUserPrefix = "system:serviceaccount:"
+
+var subjectAccessReviews = schema.GroupVersionResource{
+	Group: "authorization.k8s.io", Version: "v1", Resource: "subjectaccessreviews",
+}
+
+// Identity is a user, with its groups, the operator acts as through
+// impersonation. The apiserver adds the groups of a ServiceAccount user
+// itself.
+type Identity struct {
+	User   string
+	Groups []string
+}
+
+// ServiceAccountIdentity returns the identity of the ServiceAccount name in
+// namespace.
+func ServiceAccountIdentity(namespace, name string) Identity {
+	return Identity{User: serviceAccountUserPrefix + namespace + ":" + name}
+}
+
+// Impersonate returns a BackupManager whose API requests act as identity,
+// so backups and restores run with its RBAC instead of the operator's. It
+// shares the rate limit and HTTP client of bm.
+func (bm *BackupManager) Impersonate(identity Identity) (*BackupManager, error) {
+	config := rest.CopyConfig(bm.Config)
+	config.Impersonate = rest.ImpersonationConfig{UserName: identity.User, Groups: identity.Groups}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client for %s: %w", identity.User, err)
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery client for %s: %w", identity.User, err)
+	}
+	return &BackupManager{
+		Config:             config,
+		DynamicClient:      dynamicClient,
+		DiscoveryClient:    discoveryClient,
+		HTTPClient:         bm.HTTPClient,
+		HostStorage:        bm.HostStorage,
+		rateLimiter:        bm.rateLimiter,
+		memoryBudget:       bm.memoryBudget,
+		tempDirs:           bm.tempDirs,
+		archiveRefs:        bm.archiveRefs,
+		storageLocks:       bm.storageLocks,
+		webhookRelaxations: bm.webhookRelaxations,
+	}, nil
+}
+
+// MayImpersonate reports whether requester may impersonate identity, so
+// admission can refuse resources that would let their author act as
+// someone they could not become themselves. The reason names the first
+// part of identity that was denied.
+func (bm *BackupManager) MayImpersonate(ctx context.Context, requester authenticationv1.UserInfo, identity Identity) (bool, string, error) {
+	user := map[string]interface{}{"resource": "users", "name": identity.User}
+	if serviceAccount, ok := strings.CutPrefix(identity.User, serviceAccountUserPrefix); ok {
+		if namespace, name, ok := strings.Cut(serviceAccount, ":"); ok {
+			user = map[string]interface{}{"resource": "serviceaccounts", "namespace": namespace, "name": name}
+		}
+	}
+	attributes := []map[string]interface{}{user}
+	for _, group := range identity.Groups {
+		attributes = append(attributes, map[string]interface{}{"resource": "groups", "name": group})
+	}
+
+	for _, attrs := range attributes {
+		attrs["verb"] = "impersonate"
+		allowed, err := bm.subjectAccessReview(ctx, requester, attrs)
+		if err != nil {
+			return false, "", err
+		}
+		if !allowed {
+			return false, fmt.Sprintf("%s may not impersonate %s %q", requester.Username, attrs["resource"], attrs["name"]), nil
+		}
+	}
+	return true, "", nil
+}
+
+// MayCreateJobs reports whether requester may create Jobs in namespace, so
+// admission can refuse backup actions that would let their author run pods
+// they could not run themselves.
+func (bm *BackupManager) MayCreateJobs(ctx context.Context, requester authenticationv1.UserInfo, namespace string) (bool, string, error) {
+	allowed, err := bm.subjectAccessReview(ctx, requester, map[string]interface{}{
+		"group": "batch", "resource": "jobs", "namespace": namespace, "verb": "create",
+	})
+	if err != nil || allowed {
+		return allowed, "", err
+	}
+	return false, fmt.Sprintf("%s may not create Jobs in namespace %q", requester.Username, namespace), nil
+}
+
+// subjectAccessReview asks the apiserver whether requester may act on
+// attrs.
+func (bm *BackupManager) subjectAccessReview(ctx context.Context, requester authenticationv1.UserInfo, attrs map[string]interface{}) (bool, error) {
+	spec := map[string]interface{}{
+		"resourceAttributes": attrs,
+		"user":               requester.Username,
+		"uid":                requester.UID,
+	}
+	if len(requester.Groups) > 0 {
+		groups := make([]interface{}, len(requester.Groups))
+		for i, group := range requester.Groups {
+			groups[i] = group
+		}
+		spec["groups"] = groups
+	}
+	if len(requester.Extra) > 0 {
+		extra := map[string]interface{}{}
+		for key, values := range requester.Extra {
+			list := make([]interface{}, len(values))
+			for i, value := range values {
+				list[i] = value
+			}
+			extra[key] = list
+		}
+		spec["extra"] = extra
+	}
+	review := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "authorization.k8s.io/v1",
+		"kind":       "SubjectAccessReview",
+		"spec":       spec,
+	}}
+	result, err := bm.DynamicClient.Resource(subjectAccessReviews).Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to review access of %s: %w", requester.Username, err)
+	}
+	allowed, _, _ := unstructured.NestedBool(result.Object, "status", "allowed")
+	return allowed, nil
+}
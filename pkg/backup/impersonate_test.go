@@ -0,0 +1,107 @@
+package backup
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestImpersonate(t *testing.T) {
+	t.Parallel()
+
+	var user string
+	var groups []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user = r.Header.Get("Impersonate-User")
+		groups = r.Header.Values("Impersonate-Group")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"ConfigMapList","items":[]}`))
+	}))
+	defer server.Close()
+
+	bm, err := NewBackupManager(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	impersonated, err := bm.Impersonate(Identity{User: "breakglass", Groups: []string{"oncall"}})
+	if err != nil {
+		t.Fatalf("Impersonate: %v", err)
+	}
+	if _, err := impersonated.DynamicClient.Resource(configMapsResource).Namespace("apps").List(context.Background(), metav1.ListOptions{}); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if user != "breakglass" || len(groups) != 1 || groups[0] != "oncall" {
+		t.Fatalf("impersonated %q %v, want breakglass [oncall]", user, groups)
+	}
+	if impersonated.rateLimiter != bm.rateLimiter {
+		t.Fatal("impersonated manager does not share the rate limiter")
+	}
+
+	// The operator's own requests are not impersonated
+	if _, err := bm.DynamicClient.Resource(configMapsResource).Namespace("apps").List(context.Background(), metav1.ListOptions{}); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if user != "" {
+		t.Fatalf("operator request impersonated %q", user)
+	}
+
+	if got := ServiceAccountIdentity("team-a", "backup").User; got != "system:serviceaccount:team-a:backup" {
+		t.Fatalf("ServiceAccountIdentity = %q", got)
+	}
+}
+
+func TestMayImpersonate(t *testing.T) {
+	t.Parallel()
+
+	var reviewed []map[string]interface{}
+	dynamicClient := fake.NewSimpleDynamicClient(runtime.NewScheme())
+	dynamicClient.PrependReactor("create", "subjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		review := action.(clienttesting.CreateAction).GetObject().(*unstructured.Unstructured).DeepCopy()
+		reviewed = append(reviewed, review.Object)
+		user, _, _ := unstructured.NestedString(review.Object, "spec", "user")
+		attrs, _, _ := unstructured.NestedStringMap(review.Object, "spec", "resourceAttributes")
+		// alice may impersonate users and the backup ServiceAccount, but no
+		// groups except oncall
+		allowed := user == "alice" && attrs["verb"] == "impersonate" &&
+			(attrs["resource"] == "users" ||
+				(attrs["resource"] == "serviceaccounts" && attrs["namespace"] == "team-a" && attrs["name"] == "backup") ||
+				(attrs["resource"] == "groups" && attrs["name"] == "oncall"))
+		review.Object["status"] = map[string]interface{}{"allowed": allowed}
+		return true, review, nil
+	})
+	bm := &BackupManager{DynamicClient: dynamicClient}
+	alice := authenticationv1.UserInfo{Username: "alice", Groups: []string{"developers"}}
+
+	for _, tc := range []struct {
+		identity Identity
+		allowed  bool
+		reason   string
+	}{
+		{identity: ServiceAccountIdentity("team-a", "backup"), allowed: true},
+		{identity: ServiceAccountIdentity("team-b", "backup"), reason: `alice may not impersonate serviceaccounts "backup"`},
+		{identity: Identity{User: "breakglass", Groups: []string{"oncall"}}, allowed: true},
+		{identity: Identity{User: "breakglass", Groups: []string{"oncall", "system:masters"}}, reason: `alice may not impersonate groups "system:masters"`},
+	} {
+		allowed, reason, err := bm.MayImpersonate(context.Background(), alice, tc.identity)
+		if err != nil {
+			t.Fatalf("MayImpersonate(%v): %v", tc.identity, err)
+		}
+		if allowed != tc.allowed || reason != tc.reason {
+			t.Errorf("MayImpersonate(%v) = %t %q, want %t %q", tc.identity, allowed, reason, tc.allowed, tc.reason)
+		}
+	}
+
+	groups, _, _ := unstructured.NestedStringSlice(reviewed[0], "spec", "groups")
+	if len(groups) != 1 || groups[0] != "developers" {
+		t.Fatalf("reviewed groups = %v, want the requester's", groups)
+	}
+}
@@ -0,0 +1,286 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// baseSuffix names the sidecar recording the archive an incremental archive
+// references unchanged objects from. Retention, rotation and tiering leave
+// an archive in place while a sidecar names it.
+const baseSuffix = ".base"
+
+// DefaultFullBackupInterval is how long a full archive serves as the base
+// of incremental archives when IncrementalOptions leaves it unset.
+const DefaultFullBackupInterval = 7 * 24 * time.Hour
+
+// IncrementalOptions configures incremental backups, which reference the
+// objects unchanged since the latest full archive instead of writing them
+// again.
+type IncrementalOptions struct {
+	// FullInterval is how old the latest full archive may get before the
+	// next run writes a full archive again. Zero defaults to
+	// DefaultFullBackupInterval.
+	FullInterval time.Duration
+}
+
+// incrementalBase is the full archive an incremental run references
+// unchanged objects from.
+type incrementalBase struct {
+	name             string
+	files            map[string]string
+	resourceVersions map[string]string
+	// reused counts the objects referenced instead of written; it is
+	// guarded by the archive writer's lock.
+	reused int
+}
+
+// reuseObject references the object listed at resourceVersion from the
+// base archive when the base holds it at the same resourceVersion, and
+// reports whether it did.
+func (aw *archiveWriter) reuseObject(name, resourceVersion string, obj map[string]interface{}) (bool, error) {
+	// Encrypted Secrets are written again, so the export never receives
+	// them in plaintext
+	if aw.base == nil || resourceVersion == "" || (aw.secrets != nil && isSecret(obj)) {
+		return false, nil
+	}
+	aw.mu.Lock()
+	defer aw.mu.Unlock()
+
+	digest, ok := aw.base.files[name]
+	if !ok || aw.base.resourceVersions[name] != resourceVersion {
+		return false, nil
+	}
+	aw.digests[name] = digest
+	aw.resourceVersions[name] = resourceVersion
+	aw.base.reused++
+	return true, aw.recordObjectLocked(name, obj, obj)
+}
+
+// recordResourceVersion records the resourceVersion of a written object.
+func (aw *archiveWriter) recordResourceVersion(name, resourceVersion string) {
+	if resourceVersion == "" {
+		return
+	}
+	aw.mu.Lock()
+	defer aw.mu.Unlock()
+	aw.resourceVersions[name] = resourceVersion
+}
+
+// loadIncrementalBase returns the newest full archive in storagePath when
+// it is recent enough to serve as a base, or nil when the run should write
+// a full archive.
+func (bm *BackupManager) loadIncrementalBase(ctx context.Context, storagePath string, opts IncrementalOptions) (*incrementalBase, error) {
+	fullInterval := opts.FullInterval
+	if fullInterval <= 0 {
+		fullInterval = DefaultFullBackupInterval
+	}
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return nil, err
+	}
+	archives, err := bm.ListArchives(storagePath)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(archives) - 1; i >= 0; i-- {
+		archivePath := filepath.Join(resolvedStoragePath, archives[i])
+		if _, err := os.Stat(archivePath + baseSuffix); err == nil {
+			continue
+		}
+		info, err := os.Stat(archivePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat archive %q: %w", archives[i], err)
+		}
+		if time.Since(info.ModTime()) > fullInterval {
+			return nil, nil
+		}
+		// Archives written while encryption was enabled cannot be read
+		if keys, known := archiveKeys(archivePath); len(keys) > 0 || !known {
+			return nil, nil
+		}
+		manifest, err := readManifestFile(ctx, archivePath)
+		if err != nil {
+			// The run still succeeds, it just writes everything
+			ctrl.LoggerFrom(ctx).Error(err, "Failed to read the base of an incremental backup, writing a full archive", "archive", archives[i])
+			return nil, nil
+		}
+		// Only archives of the current format can share their entries
+		if manifest == nil || len(manifest.ResourceVersions) == 0 || manifest.FormatVersion != ArchiveFormatVersion {
+			return nil, nil
+		}
+		return &incrementalBase{name: archives[i], files: manifest.Files, resourceVersions: manifest.ResourceVersions}, nil
+	}
+	return nil, nil
+}
+
+// readManifestFile returns the manifest of the unencrypted archive at
+// archivePath, or nil when it has none.
+func readManifestFile(ctx context.Context, archivePath string) (*archiveManifest, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	compressed, err := decryptArchive(ctx, bufio.NewReader(file), func(WrappedKey) []KeyWrapper { return nil })
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt archive: %w", err)
+	}
+	tarStream, _, err := newDecompressor(bufio.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer tarStream.Close()
+
+	tarReader := tar.NewReader(tarStream)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if header.Name != manifestName {
+			continue
+		}
+		manifest := &archiveManifest{}
+		if err := json.NewDecoder(tarReader).Decode(manifest); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal manifest: %w", err)
+		}
+		return manifest, nil
+	}
+}
+
+// writeBaseMarker records next to archivePath the base archive it
+// references.
+func writeBaseMarker(archivePath, base string) error {
+	return os.WriteFile(archivePath+baseSuffix, []byte(base+"\n"), 0644)
+}
+
+// referencedBases returns the archives in dir that incremental archives
+// still reference.
+func referencedBases(dir string) map[string]struct{} {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	bases := map[string]struct{}{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), baseSuffix) {
+			continue
+		}
+		// Markers of archives removed by hand no longer hold their base
+		if _, err := os.Stat(filepath.Join(dir, strings.TrimSuffix(e.Name(), baseSuffix))); err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		bases[strings.TrimSpace(string(data))] = struct{}{}
+	}
+	return bases
+}
+
+// isIncremental reports whether archivePath references a base archive.
+func isIncremental(archivePath string) bool {
+	_, err := os.Stat(archivePath + baseSuffix)
+	return err == nil
+}
+
+// readStoredArchive reads the named archive from storagePath, or from a URL
+// when archiveName is one, like readArchiveFrom. The entries of an
+// incremental archive held by its base are read from the base, which must
+// be in the same storage location.
+func (bm *BackupManager) readStoredArchive(ctx context.Context, storagePath, archiveName string, resolve func(WrappedKey) []KeyWrapper, wantDigest string) ([]archivedResource, []archivedResource, *archiveManifest, error) {
+	source, name, err := bm.openArchive(ctx, storagePath, archiveName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer source.Close()
+
+	clusterResources, namespacedResources, manifest, err := readArchiveFrom(ctx, source, name, resolve, wantDigest)
+	if errors.Is(err, ErrNoKeyWrapper) && !IsArchiveURL(archiveName) {
+		if keys := bm.ArchiveEncryptionKeys(storagePath, archiveName); len(keys) > 0 {
+			err = fmt.Errorf("%w (the archive is encrypted with %s)", err, strings.Join(keys, ", "))
+		}
+	}
+	if err != nil || manifest == nil || manifest.Base == "" {
+		return clusterResources, namespacedResources, manifest, err
+	}
+
+	base, err := bm.readBaseEntries(ctx, storagePath, manifest, resolve)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	fill := func(resources []archivedResource) {
+		for i, res := range resources {
+			if !errors.Is(res.err, ErrEntryMissing) {
+				continue
+			}
+			if held, ok := base[path.Join(archiveDir(res.gvr, res.namespace), res.name+".json")]; ok {
+				resources[i] = held
+			}
+		}
+	}
+	fill(clusterResources)
+	fill(namespacedResources)
+	return clusterResources, namespacedResources, manifest, nil
+}
+
+// readBaseEntries returns the entries of the base of manifest whose digest
+// matches the one manifest records, keyed by entry name.
+func (bm *BackupManager) readBaseEntries(ctx context.Context, storagePath string, manifest *archiveManifest, resolve func(WrappedKey) []KeyWrapper) (map[string]archivedResource, error) {
+	source, name, err := bm.openArchive(ctx, storagePath, manifest.Base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open base archive: %w", err)
+	}
+	defer source.Close()
+
+	clusterResources, namespacedResources, baseManifest, err := readArchiveFrom(ctx, source, name, resolve, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read base archive %s: %w", manifest.Base, err)
+	}
+	if baseManifest == nil {
+		return nil, fmt.Errorf("base archive %s has no manifest", manifest.Base)
+	}
+	entries := map[string]archivedResource{}
+	for _, res := range append(clusterResources, namespacedResources...) {
+		entry := path.Join(archiveDir(res.gvr, res.namespace), res.name+".json")
+		if res.err != nil || baseManifest.Files[entry] != manifest.Files[entry] {
+			continue
+		}
+		entries[entry] = res
+	}
+	return entries, nil
+}
@@ -0,0 +1,129 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestIncrementalArchive(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storageDir := t.TempDir()
+	bm := &BackupManager{}
+
+	baseName := "cluster-backup-20260101-000000.tar.gz"
+	writeConfigMapArchive(t, filepath.Join(storageDir, baseName), nil,
+		incrementalConfigMap("stable", "1", "kept"), incrementalConfigMap("changed", "1", "old"))
+
+	base, err := bm.loadIncrementalBase(ctx, storageDir, IncrementalOptions{})
+	if err != nil || base == nil || base.name != baseName {
+		t.Fatalf("base = %+v, %v, want %s", base, err, baseName)
+	}
+
+	incrementalName := "cluster-backup-20260101-010000.tar.gz"
+	incrementalPath := filepath.Join(storageDir, incrementalName)
+	writeConfigMapArchive(t, incrementalPath, base,
+		incrementalConfigMap("stable", "1", "kept"), incrementalConfigMap("changed", "2", "new"))
+	if base.reused != 1 {
+		t.Fatalf("reused = %d, want only the unchanged ConfigMap", base.reused)
+	}
+	if err := writeBaseMarker(incrementalPath, baseName); err != nil {
+		t.Fatal(err)
+	}
+	if next, err := bm.loadIncrementalBase(ctx, storageDir, IncrementalOptions{}); err != nil || next == nil || next.name != baseName {
+		t.Fatalf("base = %+v, %v, want incremental archives skipped", next, err)
+	}
+
+	_, namespaced, manifest, err := bm.readStoredArchive(ctx, storageDir, incrementalName, nil, "")
+	if err != nil {
+		t.Fatalf("readStoredArchive returned error: %v", err)
+	}
+	if manifest.Base != baseName {
+		t.Fatalf("manifest base = %q, want %q", manifest.Base, baseName)
+	}
+	data := map[string]interface{}{}
+	for _, res := range namespaced {
+		if res.err != nil {
+			t.Fatalf("resource %s failed: %v", res.name, res.err)
+		}
+		data[res.name] = res.object["data"].(map[string]interface{})["value"]
+	}
+	if data["stable"] != "kept" || data["changed"] != "new" {
+		t.Fatalf("restored data = %v, want the base copy of stable and the new copy of changed", data)
+	}
+
+	zero := 0
+	removed, err := bm.CleanupArchives(storageDir, nil, &zero)
+	if err != nil || len(removed) != 1 || removed[0] != incrementalName {
+		t.Fatalf("removed = %v, %v, want only the incremental archive while it references its base", removed, err)
+	}
+	if _, err := os.Stat(incrementalPath + baseSuffix); !os.IsNotExist(err) {
+		t.Fatalf("base marker still present: %v", err)
+	}
+	if removed, err := bm.CleanupArchives(storageDir, nil, &zero); err != nil || len(removed) != 1 || removed[0] != baseName {
+		t.Fatalf("removed = %v, %v, want the base once unreferenced", removed, err)
+	}
+}
+
+func TestIncrementalSkipsEncryptedBase(t *testing.T) {
+	t.Parallel()
+
+	storageDir := t.TempDir()
+	basePath := filepath.Join(storageDir, "cluster-backup-20260101-000000.tar.gz")
+	writeConfigMapArchive(t, basePath, nil, incrementalConfigMap("stable", "1", "kept"))
+	if err := recordArchiveKeys(basePath, []string{"age:age1example"}); err != nil {
+		t.Fatal(err)
+	}
+
+	base, err := (&BackupManager{}).loadIncrementalBase(context.Background(), storageDir, IncrementalOptions{})
+	if err != nil || base != nil {
+		t.Fatalf("base = %+v, %v, want a full archive instead of an encrypted base", base, err)
+	}
+}
+
+func incrementalConfigMap(name, resourceVersion, value string) unstructured.Unstructured {
+	return unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "app", "resourceVersion": resourceVersion},
+		"data":       map[string]interface{}{"value": value},
+	}}
+}
+
+// writeConfigMapArchive writes the ConfigMaps to a gzip archive at
+// archivePath, referencing unchanged ones from base when it is set.
+func writeConfigMapArchive(t *testing.T, archivePath string, base *incrementalBase, items ...unstructured.Unstructured) {
+	t.Helper()
+
+	file, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	compressor, err := newCompressor(file, CompressionGzip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := newArchiveWriter(compressor)
+	archive.base = base
+
+	listPage := func(context.Context, metav1.ListOptions) (runtime.Object, error) {
+		return &unstructured.UnstructuredList{Items: items}, nil
+	}
+	if _, err := writeResourcePages(context.Background(), archive, "namespaces/app/v1/configmaps", listPage); err != nil {
+		t.Fatal(err)
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := compressor.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
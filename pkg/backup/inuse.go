@@ -0,0 +1,135 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrArchiveInUse is returned when an archive cannot be moved because a
+// restore is reading it.
+var ErrArchiveInUse = errors.New("archive is in use by a restore")
+
+// DefaultRestoreGracePeriod is how long an archive stays protected after the
+// last restore reading it finished.
+const DefaultRestoreGracePeriod = 15 * time.Minute
+
+// archiveReferences counts the restores and restore plans reading each
+// archive, keyed by its resolved path. Retention, tiering and moving
+// transfers leave referenced archives alone.
+type archiveReferences struct {
+	mu sync.Mutex
+	// grace is how long an archive stays referenced after its last
+	// reference is released, e.g. between a restore plan and its run.
+	grace time.Duration
+	refs  map[string]*archiveReference
+}
+
+type archiveReference struct {
+	count int
+	// until is when the archive stops being referenced once count is 0.
+	until time.Time
+}
+
+// SetRestoreGracePeriod sets how long archives stay protected from
+// retention after the last restore reading them finished. It must be called
+// before the manager is used.
+func (bm *BackupManager) SetRestoreGracePeriod(grace time.Duration) {
+	if bm.archiveRefs == nil {
+		bm.archiveRefs = newArchiveReferences()
+	}
+	bm.archiveRefs.grace = grace
+}
+
+func newArchiveReferences() *archiveReferences {
+	return &archiveReferences{grace: DefaultRestoreGracePeriod, refs: map[string]*archiveReference{}}
+}
+
+// referenceArchive protects storagePath/archiveName until the returned
+// function is called and the grace period has passed.
+func (bm *BackupManager) referenceArchive(storagePath, archiveName string) func() {
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if bm.archiveRefs == nil || storagePath == "" || err != nil {
+		return func() {}
+	}
+	refs := bm.archiveRefs
+	archivePath := filepath.Join(resolvedStoragePath, archiveName)
+
+	refs.mu.Lock()
+	defer refs.mu.Unlock()
+	ref, ok := refs.refs[archivePath]
+	if !ok {
+		ref = &archiveReference{}
+		refs.refs[archivePath] = ref
+	}
+	ref.count++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			refs.mu.Lock()
+			defer refs.mu.Unlock()
+			ref.count--
+			ref.until = time.Now().Add(refs.grace)
+		})
+	}
+}
+
+// isReferenced reports whether a restore is reading archivePath or finished
+// within the grace period. Expired references are dropped along the way.
+func (bm *BackupManager) isReferenced(archivePath string, now time.Time) bool {
+	if bm.archiveRefs == nil {
+		return false
+	}
+	refs := bm.archiveRefs
+
+	refs.mu.Lock()
+	defer refs.mu.Unlock()
+	ref, ok := refs.refs[archivePath]
+	if !ok {
+		return false
+	}
+	if ref.count > 0 || now.Before(ref.until) {
+		return true
+	}
+	delete(refs.refs, archivePath)
+	return false
+}
+
+// ReferencedArchives returns the archives in storagePath that a restore is
+// reading or finished reading within the grace period.
+func (bm *BackupManager) ReferencedArchives(storagePath string) []string {
+	archives, err := bm.ListArchives(storagePath)
+	if err != nil {
+		return nil
+	}
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return nil
+	}
+	now := time.Now()
+	var referenced []string
+	for _, archive := range archives {
+		if bm.isReferenced(filepath.Join(resolvedStoragePath, archive), now) {
+			referenced = append(referenced, archive)
+		}
+	}
+	return referenced
+}
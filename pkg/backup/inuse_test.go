@@ -0,0 +1,79 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestReferencedArchivesSurviveRetention(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	names := []string{
+		"cluster-backup-20250101-000000.tar.gz",
+		"cluster-backup-20250102-000000.tar.gz",
+		"cluster-backup-20250103-000000.tar.gz",
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bm := &BackupManager{}
+	bm.SetRestoreGracePeriod(time.Hour)
+	release := bm.referenceArchive(dir, names[0])
+	if got := bm.ReferencedArchives(dir); !slices.Equal(got, names[:1]) {
+		t.Fatalf("referenced archives = %v", got)
+	}
+
+	zero := 0
+	removed, err := bm.CleanupArchives(dir, nil, &zero)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(removed, names[1:]) {
+		t.Fatalf("removed %v, want %v", removed, names[1:])
+	}
+	if _, err := bm.TransferArchive(context.Background(), dir, names[0], t.TempDir(), true); !errors.Is(err, ErrArchiveInUse) {
+		t.Fatalf("expected a move to be refused, got %v", err)
+	}
+
+	// The archive stays protected for the grace period after release
+	release()
+	release()
+	if !bm.isReferenced(filepath.Join(dir, names[0]), time.Now()) {
+		t.Fatal("archive unprotected within the grace period")
+	}
+	if bm.isReferenced(filepath.Join(dir, names[0]), time.Now().Add(2*time.Hour)) {
+		t.Fatal("archive still protected after the grace period")
+	}
+	if removed, err := bm.CleanupArchives(dir, nil, &zero); err != nil || !slices.Equal(removed, names[:1]) {
+		t.Fatalf("removed %v, %v", removed, err)
+	}
+}
+
+func TestReferencesCountRestores(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "cluster-backup-20250101-000000.tar.gz")
+	bm := &BackupManager{}
+	bm.SetRestoreGracePeriod(0)
+
+	first := bm.referenceArchive(dir, filepath.Base(archivePath))
+	second := bm.referenceArchive(dir, filepath.Base(archivePath))
+	first()
+	if !bm.isReferenced(archivePath, time.Now().Add(time.Minute)) {
+		t.Fatal("archive released while a second restore reads it")
+	}
+	second()
+	if bm.isReferenced(archivePath, time.Now().Add(time.Minute)) {
+		t.Fatal("archive still referenced after both restores finished")
+	}
+}
@@ -0,0 +1,128 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestParseBackupItem(t *testing.T) {
+	t.Parallel()
+
+	for input, want := range map[string]BackupItem{
+		"deployment.apps/prod/api": {Kind: "deployment.apps", Namespace: "prod", Name: "api"},
+		"ClusterRole/admin":        {Kind: "ClusterRole", Name: "admin"},
+	} {
+		got, err := ParseBackupItem(input)
+		if err != nil || got != want {
+			t.Errorf("ParseBackupItem(%q) = %+v, %v, want %+v", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"configmap", "configmap/", "a/b/c/d", "/prod/api"} {
+		if _, err := ParseBackupItem(input); err == nil {
+			t.Errorf("ParseBackupItem(%q) succeeded, want an error", input)
+		}
+	}
+}
+
+func TestResolveItemResource(t *testing.T) {
+	t.Parallel()
+
+	lists := []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{
+			{Name: "configmaps", SingularName: "configmap", Kind: "ConfigMap", Namespaced: true},
+		}},
+		{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{
+			{Name: "deployments", SingularName: "deployment", Kind: "Deployment", Namespaced: true},
+			{Name: "deployments/scale", Kind: "Scale", Namespaced: true},
+		}},
+		{GroupVersion: "example.com/v1", APIResources: []metav1.APIResource{
+			{Name: "deployments", SingularName: "deployment", Kind: "Deployment", Namespaced: true},
+		}},
+	}
+
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	for kind, want := range map[string]schema.GroupVersionResource{
+		"ConfigMap":              {Version: "v1", Resource: "configmaps"},
+		"configmaps":             {Version: "v1", Resource: "configmaps"},
+		"deployment":             deployments,
+		"Deployment.example.com": {Group: "example.com", Version: "v1", Resource: "deployments"},
+	} {
+		got, err := resolveItemResource(lists, kind)
+		if err != nil || got != want {
+			t.Errorf("resolveItemResource(%q) = %v, %v, want %v", kind, got, err, want)
+		}
+	}
+	if _, err := resolveItemResource(lists, "scale"); err == nil {
+		t.Error("expected subresources not to be resolved")
+	}
+}
+
+func TestCollectItems(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	dynamicClient := fake.NewSimpleDynamicClient(scheme,
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "prod", ResourceVersion: "7"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "prod"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod"}},
+	)
+	bm := &BackupManager{DynamicClient: dynamicClient}
+	configMaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	namespaces := schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+	var out bytes.Buffer
+	archive := newArchiveWriter(&out)
+	count, err := bm.collectItems(context.Background(), archive, BackupOptions{Items: []BackupItem{
+		{Resource: configMaps, Namespace: "prod", Name: "settings"},
+		{Resource: configMaps, Namespace: "prod", Name: "settings"},
+		{Resource: namespaces, Name: "prod"},
+	}})
+	if err != nil {
+		t.Fatalf("collectItems: %v", err)
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("collected %d items, want 2", count)
+	}
+
+	var names []string
+	tarReader := tar.NewReader(&out)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.Name != manifestName {
+			names = append(names, header.Name)
+		}
+	}
+	want := []string{"namespaces/prod/v1/configmaps/settings.json", "cluster/v1/namespaces/prod.json"}
+	if len(names) != len(want) || names[0] != want[0] || names[1] != want[1] {
+		t.Fatalf("archived %v, want %v", names, want)
+	}
+
+	_, err = bm.collectItems(context.Background(), newArchiveWriter(io.Discard), BackupOptions{Items: []BackupItem{
+		{Resource: configMaps, Namespace: "prod", Name: "missing"},
+	}})
+	if err == nil {
+		t.Fatal("expected a missing item to fail the backup")
+	}
+}
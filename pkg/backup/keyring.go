@@ -0,0 +1,244 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// keysSuffix names the sidecar file listing the keys an archive is
+	// encrypted with.
+	keysSuffix = ".keys"
+
+	// reencryptSuffix names the file an archive is re-encrypted into before
+	// it replaces the archive.
+	reencryptSuffix = ".reencrypt"
+)
+
+// identifiedKeyWrapper is implemented by key wrappers that can name the key
+// they are configured with before wrapping anything.
+type identifiedKeyWrapper interface {
+	// keyRef returns "<provider>:<key ID>".
+	keyRef() string
+}
+
+func (w *ageRecipientWrapper) keyRef() string { return AgeProvider + ":" + w.recipient.String() }
+func (w *awsKeyWrapper) keyRef() string       { return KMSProviderAWS + ":" + w.keyID }
+func (w *gcpKeyWrapper) keyRef() string       { return KMSProviderGCP + ":" + w.keyName }
+func (w *azureKeyWrapper) keyRef() string     { return KMSProviderAzure + ":" + w.keyURL }
+
+// EncryptionKeyIDs names the keys wrappers encrypt archives with, as
+// "<provider>:<key ID>", sorted.
+func EncryptionKeyIDs(wrappers []KeyWrapper) []string {
+	var keys []string
+	for _, wrapper := range wrappers {
+		if identified, ok := wrapper.(identifiedKeyWrapper); ok {
+			keys = append(keys, identified.keyRef())
+		}
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+// recordArchiveKeys lists the keys of an encrypted archive next to it.
+func recordArchiveKeys(archivePath string, keys []string) error {
+	if len(keys) == 0 {
+		if err := os.Remove(archivePath + keysSuffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	return os.WriteFile(archivePath+keysSuffix, []byte(strings.Join(keys, "\n")+"\n"), 0644)
+}
+
+// archiveKeys returns the keys recorded for an archive. known is false for
+// encrypted archives written before keys were recorded.
+func archiveKeys(archivePath string) (keys []string, known bool) {
+	data, err := os.ReadFile(archivePath + keysSuffix)
+	if err == nil {
+		return strings.Fields(string(data)), true
+	}
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, false
+	}
+	defer file.Close()
+	return nil, detectEncryption(bufio.NewReader(file)) == ""
+}
+
+// copyArchiveKeys gives the copy of an archive at dst the key list of the
+// archive at src, if it has one.
+func copyArchiveKeys(src, dst string) error {
+	if data, err := os.ReadFile(src + keysSuffix); err == nil {
+		return os.WriteFile(dst+keysSuffix, data, 0644)
+	}
+	return nil
+}
+
+// ArchiveEncryptionKeys returns the keys the named archive in storagePath is
+// encrypted with, or nil when it is not encrypted or they are unknown.
+func (bm *BackupManager) ArchiveEncryptionKeys(storagePath, archiveName string) []string {
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return nil
+	}
+	keys, _ := archiveKeys(filepath.Join(resolvedStoragePath, archiveName))
+	return keys
+}
+
+// ReencryptOptions configures ReencryptArchive and ReencryptArchives.
+type ReencryptOptions struct {
+	// KeyWrappers are the current keys archives are encrypted with.
+	KeyWrappers []KeyWrapper
+	// DecryptKeyWrappers decrypt the archives, as RestoreOptions.KeyWrappers
+	// do. They must include the identities of retired age keys.
+	DecryptKeyWrappers []KeyWrapper
+}
+
+// ReencryptArchive rewrites the named archive in storagePath encrypted with
+// a fresh data key for opts.KeyWrappers, so the keys it was encrypted with
+// before can be retired. Unencrypted archives get encrypted. The archive is
+// only replaced once it was decrypted and encrypted in full. Locked archives
+// fail with ErrArchiveImmutable and archives read by a restore with
+// ErrArchiveInUse.
+func (bm *BackupManager) ReencryptArchive(ctx context.Context, storagePath, archiveName string, opts ReencryptOptions) error {
+	if err := ValidateArchiveName(archiveName); err != nil {
+		return err
+	}
+	if len(opts.KeyWrappers) == 0 {
+		return errors.New("no keys to re-encrypt the archive with")
+	}
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return err
+	}
+	archivePath := filepath.Join(resolvedStoragePath, archiveName)
+	now := time.Now()
+	if err := checkArchiveMutable(archivePath, now); err != nil {
+		return err
+	}
+	if bm.isReferenced(archivePath, now) {
+		return fmt.Errorf("%w: %s", ErrArchiveInUse, archiveName)
+	}
+
+	source, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive %q: %w", archiveName, err)
+	}
+	defer source.Close()
+	compressed, err := decryptArchive(ctx, bufio.NewReader(source), RestoreOptions{KeyWrappers: opts.DecryptKeyWrappers}.keyWrappersFor(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to decrypt archive %q: %w", archiveName, err)
+	}
+
+	tmpPath := archivePath + reencryptSuffix
+	if err := writeReencrypted(ctx, tmpPath, compressed, opts.KeyWrappers); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to re-encrypt archive %q: %w", archiveName, err)
+	}
+	if err := os.Rename(tmpPath, archivePath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace archive %q: %w", archiveName, err)
+	}
+	if err := recordArchiveKeys(archivePath, EncryptionKeyIDs(opts.KeyWrappers)); err != nil {
+		return fmt.Errorf("failed to record the keys of archive %q: %w", archiveName, err)
+	}
+	if err := catalogArchive(archivePath, ""); err != nil {
+		return fmt.Errorf("failed to update catalog: %w", err)
+	}
+	return nil
+}
+
+// writeReencrypted encrypts the compressed archive stream r for wrappers
+// into a new file at path.
+func writeReencrypted(ctx context.Context, path string, r io.Reader, wrappers []KeyWrapper) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	encrypter, err := newArchiveEncrypter(ctx, file, wrappers)
+	if err != nil {
+		return err
+	}
+	// Decryption fails here if any chunk was tampered with or truncated
+	if _, err := io.Copy(encrypter, r); err != nil {
+		return err
+	}
+	if err := encrypter.Close(); err != nil {
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// ReencryptArchives re-encrypts every archive in storagePath that is not
+// encrypted with exactly the keys of opts.KeyWrappers, oldest first.
+// Archives read by a restore are left for a later call. Locked archives are
+// kept and reported through an ImmutableArchivesError. It returns the
+// archives rewritten, also when it fails.
+func (bm *BackupManager) ReencryptArchives(ctx context.Context, storagePath string, opts ReencryptOptions) ([]string, error) {
+	archives, err := bm.ListArchives(storagePath)
+	if err != nil {
+		return nil, err
+	}
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return nil, err
+	}
+	current := EncryptionKeyIDs(opts.KeyWrappers)
+	now := time.Now()
+
+	var locked, rewritten []string
+	for _, archive := range archives {
+		if err := ctx.Err(); err != nil {
+			return rewritten, err
+		}
+		archivePath := filepath.Join(resolvedStoragePath, archive)
+		if keys, known := archiveKeys(archivePath); known && slices.Equal(keys, current) {
+			continue
+		}
+		if bm.isReferenced(archivePath, now) {
+			continue
+		}
+		if checkArchiveMutable(archivePath, now) != nil {
+			locked = append(locked, archive)
+			continue
+		}
+		if err := bm.ReencryptArchive(ctx, storagePath, archive, opts); err != nil {
+			return rewritten, err
+		}
+		rewritten = append(rewritten, archive)
+	}
+
+	if len(locked) > 0 {
+		return rewritten, &ImmutableArchivesError{Archives: locked}
+	}
+	return rewritten, nil
+}
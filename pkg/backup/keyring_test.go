@@ -0,0 +1,80 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+)
+
+func TestReencryptArchives(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storageDir := t.TempDir()
+	bm := &BackupManager{}
+	archiveName := "cluster-backup-20260101-000000.tar.gz"
+	lockedName := "cluster-backup-20260101-010000.tar.gz"
+	writeRestoreArchive(t, filepath.Join(storageDir, archiveName))
+	writeRestoreArchive(t, filepath.Join(storageDir, lockedName))
+	if err := lockArchive(filepath.Join(storageDir, lockedName), Immutability{RetainFor: time.Hour}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	keyring := func() ([]KeyWrapper, KeyWrapper) {
+		identity, err := age.GenerateX25519Identity()
+		if err != nil {
+			t.Fatal(err)
+		}
+		recipients, err := NewAgeKeyWrappers([]string{identity.Recipient().String()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		identityWrapper, err := NewAgeIdentityKeyWrapper(identity.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return recipients, identityWrapper
+	}
+	oldRecipients, oldIdentity := keyring()
+	newRecipients, newIdentity := keyring()
+
+	// Unencrypted archives get encrypted; locked ones are reported
+	rewritten, err := bm.ReencryptArchives(ctx, storageDir, ReencryptOptions{KeyWrappers: oldRecipients})
+	var immutable *ImmutableArchivesError
+	if !errors.As(err, &immutable) || !slices.Equal(immutable.Archives, []string{lockedName}) {
+		t.Fatalf("expected the locked archive to be reported, got %v", err)
+	}
+	if !slices.Equal(rewritten, []string{archiveName}) {
+		t.Fatalf("rewritten = %v", rewritten)
+	}
+	if keys := bm.ArchiveEncryptionKeys(storageDir, archiveName); !slices.Equal(keys, EncryptionKeyIDs(oldRecipients)) {
+		t.Fatalf("keys = %v", keys)
+	}
+
+	// Rotating to a new key needs the retired identity to decrypt
+	opts := ReencryptOptions{KeyWrappers: newRecipients, DecryptKeyWrappers: []KeyWrapper{oldIdentity}}
+	if rewritten, _ := bm.ReencryptArchives(ctx, storageDir, opts); !slices.Equal(rewritten, []string{archiveName}) {
+		t.Fatalf("rewritten = %v", rewritten)
+	}
+	if rewritten, _ := bm.ReencryptArchives(ctx, storageDir, opts); len(rewritten) != 0 {
+		t.Fatalf("archives already encrypted with the current keys were rewritten: %v", rewritten)
+	}
+
+	resolve := func(keys ...KeyWrapper) func(WrappedKey) []KeyWrapper {
+		return func(WrappedKey) []KeyWrapper { return keys }
+	}
+	_, namespaced, _, err := bm.readStoredArchive(ctx, storageDir, archiveName, resolve(newIdentity), "")
+	if err != nil || len(namespaced) != 1 || namespaced[0].err != nil {
+		t.Fatalf("reading with the new identity: %v, %+v", err, namespaced)
+	}
+	_, _, _, err = bm.readStoredArchive(ctx, storageDir, archiveName, resolve(oldIdentity), "")
+	if !errors.Is(err, ErrNoKeyWrapper) || !strings.Contains(err.Error(), EncryptionKeyIDs(newRecipients)[0]) {
+		t.Fatalf("expected the retired identity to fail naming the current key, got %v", err)
+	}
+}
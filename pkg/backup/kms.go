@@ -0,0 +1,221 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	kmsapi "cloud.google.com/go/kms/apiv1"
+	"cloud.google.com/go/kms/apiv1/kmspb"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azkeys"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	awskms "github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// KMS providers supported for envelope encryption. Credentials come from each
+// cloud's default chain (IRSA or pod identity, GKE workload identity, Azure
+// workload or managed identity).
+const (
+	KMSProviderAWS   = "aws-kms"
+	KMSProviderGCP   = "gcp-kms"
+	KMSProviderAzure = "azure-keyvault"
+)
+
+// NewKMSKeyWrapper returns a KeyWrapper backed by the given cloud KMS key:
+//   - aws-kms: a key ARN, alias ARN, key ID or alias name
+//   - gcp-kms: projects/<p>/locations/<l>/keyRings/<r>/cryptoKeys/<k>
+//   - azure-keyvault: https://<vault>.vault.azure.net/keys/<name>[/<version>]
+func NewKMSKeyWrapper(ctx context.Context, provider, keyID string) (KeyWrapper, error) {
+	if keyID == "" {
+		return nil, fmt.Errorf("%s key ID must not be empty", provider)
+	}
+	switch provider {
+	case KMSProviderAWS:
+		return newAWSKeyWrapper(ctx, keyID)
+	case KMSProviderGCP:
+		return &gcpKeyWrapper{keyName: keyID}, nil
+	case KMSProviderAzure:
+		return newAzureKeyWrapper(keyID)
+	default:
+		return nil, fmt.Errorf("unsupported KMS provider %q", provider)
+	}
+}
+
+// isKMSProvider reports whether provider is handled by NewKMSKeyWrapper.
+func isKMSProvider(provider string) bool {
+	switch provider {
+	case KMSProviderAWS, KMSProviderGCP, KMSProviderAzure:
+		return true
+	}
+	return false
+}
+
+type awsKeyWrapper struct {
+	client *awskms.Client
+	keyID  string
+}
+
+func newAWSKeyWrapper(ctx context.Context, keyID string) (*awsKeyWrapper, error) {
+	var opts []func(*awsconfig.LoadOptions) error
+	// Key ARNs carry their region, which lets restores run from any region
+	if parts := strings.Split(keyID, ":"); len(parts) > 3 && parts[0] == "arn" {
+		opts = append(opts, awsconfig.WithRegion(parts[3]))
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+	return &awsKeyWrapper{client: awskms.NewFromConfig(cfg), keyID: keyID}, nil
+}
+
+func (w *awsKeyWrapper) WrapKey(ctx context.Context, dataKey []byte) (WrappedKey, error) {
+	out, err := w.client.Encrypt(ctx, &awskms.EncryptInput{
+		KeyId:     aws.String(w.keyID),
+		Plaintext: dataKey,
+	})
+	if err != nil {
+		return WrappedKey{}, fmt.Errorf("failed to encrypt data key with AWS KMS: %w", err)
+	}
+	// Record the resolved key ARN so restores do not depend on aliases
+	keyID := w.keyID
+	if out.KeyId != nil {
+		keyID = *out.KeyId
+	}
+	return WrappedKey{Provider: KMSProviderAWS, KeyID: keyID, Key: out.CiphertextBlob}, nil
+}
+
+func (w *awsKeyWrapper) UnwrapKey(ctx context.Context, wrapped WrappedKey) ([]byte, error) {
+	out, err := w.client.Decrypt(ctx, &awskms.DecryptInput{
+		KeyId:          aws.String(wrapped.KeyID),
+		CiphertextBlob: wrapped.Key,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data key with AWS KMS: %w", err)
+	}
+	return out.Plaintext, nil
+}
+
+type gcpKeyWrapper struct {
+	keyName string
+}
+
+func (w *gcpKeyWrapper) WrapKey(ctx context.Context, dataKey []byte) (WrappedKey, error) {
+	client, err := kmsapi.NewKeyManagementClient(ctx)
+	if err != nil {
+		return WrappedKey{}, fmt.Errorf("failed to create GCP KMS client: %w", err)
+	}
+	defer client.Close()
+
+	resp, err := client.Encrypt(ctx, &kmspb.EncryptRequest{Name: w.keyName, Plaintext: dataKey})
+	if err != nil {
+		return WrappedKey{}, fmt.Errorf("failed to encrypt data key with GCP KMS: %w", err)
+	}
+	return WrappedKey{Provider: KMSProviderGCP, KeyID: w.keyName, Key: resp.Ciphertext}, nil
+}
+
+func (w *gcpKeyWrapper) UnwrapKey(ctx context.Context, wrapped WrappedKey) ([]byte, error) {
+	client, err := kmsapi.NewKeyManagementClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCP KMS client: %w", err)
+	}
+	defer client.Close()
+
+	resp, err := client.Decrypt(ctx, &kmspb.DecryptRequest{Name: wrapped.KeyID, Ciphertext: wrapped.Key})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data key with GCP KMS: %w", err)
+	}
+	return resp.Plaintext, nil
+}
+
+type azureKeyWrapper struct {
+	client  *azkeys.Client
+	keyURL  string
+	name    string
+	version string
+}
+
+func newAzureKeyWrapper(keyURL string) (*azureKeyWrapper, error) {
+	vaultURL, name, version, err := parseAzureKeyURL(keyURL)
+	if err != nil {
+		return nil, err
+	}
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Azure credentials: %w", err)
+	}
+	client, err := azkeys.NewClient(vaultURL, cred, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure Key Vault client: %w", err)
+	}
+	return &azureKeyWrapper{client: client, keyURL: keyURL, name: name, version: version}, nil
+}
+
+func (w *azureKeyWrapper) WrapKey(ctx context.Context, dataKey []byte) (WrappedKey, error) {
+	algorithm := azkeys.EncryptionAlgorithmRSAOAEP256
+	resp, err := w.client.WrapKey(ctx, w.name, w.version, azkeys.KeyOperationParameters{
+		Algorithm: &algorithm,
+		Value:     dataKey,
+	}, nil)
+	if err != nil {
+		return WrappedKey{}, fmt.Errorf("failed to wrap data key with Azure Key Vault: %w", err)
+	}
+	// The returned key ID pins the key version used for wrapping
+	keyID := ""
+	if resp.KID != nil {
+		keyID = string(*resp.KID)
+	}
+	return WrappedKey{Provider: KMSProviderAzure, KeyID: keyID, Key: resp.Result}, nil
+}
+
+func (w *azureKeyWrapper) UnwrapKey(ctx context.Context, wrapped WrappedKey) ([]byte, error) {
+	_, name, version, err := parseAzureKeyURL(wrapped.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	algorithm := azkeys.EncryptionAlgorithmRSAOAEP256
+	resp, err := w.client.UnwrapKey(ctx, name, version, azkeys.KeyOperationParameters{
+		Algorithm: &algorithm,
+		Value:     wrapped.Key,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key with Azure Key Vault: %w", err)
+	}
+	return resp.Result, nil
+}
+
+// parseAzureKeyURL splits a Key Vault key URL into the vault URL, key name
+// and optional version.
+func parseAzureKeyURL(keyURL string) (string, string, string, error) {
+	u, err := url.Parse(keyURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return "", "", "", fmt.Errorf("invalid Azure Key Vault key URL %q", keyURL)
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] != "keys" || parts[1] == "" {
+		return "", "", "", fmt.Errorf("invalid Azure Key Vault key URL %q", keyURL)
+	}
+	version := ""
+	if len(parts) == 3 {
+		version = parts[2]
+	}
+	return "https://" + u.Host, parts[1], version, nil
+}
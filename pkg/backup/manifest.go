@@ -0,0 +1,135 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// manifestName is the archive entry describing the archive contents. It
+	// is written last, once every resource digest is known.
+	manifestName = "manifest.json"
+
+	digestPrefix = "sha256:"
+)
+
+var (
+	// ErrChecksumMismatch is reported for archive entries whose content does
+	// not match the digest recorded in the manifest.
+	ErrChecksumMismatch = errors.New("archive entry does not match its manifest checksum")
+	// ErrEntryMissing is reported for entries listed in the manifest that are
+	// absent from the archive, typically because it was truncated.
+	ErrEntryMissing = errors.New("archive entry listed in the manifest is missing")
+)
+
+const (
+	// ArchiveFormatUnversioned is the format of archives written without a
+	// manifest. Their entries follow the layout of format 1 but carry no
+	// checksums.
+	ArchiveFormatUnversioned = 0
+	// ArchiveFormatVersion is the format CreateBackup writes. Bump it
+	// whenever the layout of entries or of the manifest changes, and keep a
+	// reader for the previous format in archiveFormats.
+	ArchiveFormatVersion = 1
+)
+
+// ErrUnsupportedArchiveFormat is returned for archives written in a format
+// newer than the operator reads.
+var ErrUnsupportedArchiveFormat = errors.New("archive format is not supported")
+
+// archiveFormat reads the archives of one format version.
+type archiveFormat struct {
+	// parseEntry returns the type, namespace and name of the object held by
+	// an entry.
+	parseEntry func(name string) (schema.GroupVersionResource, string, string, error)
+}
+
+// archiveFormats holds a reader for every format archives were ever
+// written in, so upgrading the operator never orphans existing archives.
+var archiveFormats = map[int]archiveFormat{
+	ArchiveFormatUnversioned: {parseEntry: parseArchiveEntry},
+	1:                        {parseEntry: parseArchiveEntry},
+}
+
+// formatVersion returns the format the archive of manifest was written in.
+// manifest is nil for archives written without one.
+func formatVersion(manifest *archiveManifest) int {
+	if manifest == nil {
+		return ArchiveFormatUnversioned
+	}
+	return manifest.FormatVersion
+}
+
+// archiveFormatOf returns the reader of the archive of manifest, failing
+// for archives written by a newer operator.
+func archiveFormatOf(manifest *archiveManifest) (archiveFormat, error) {
+	version := formatVersion(manifest)
+	format, ok := archiveFormats[version]
+	if !ok {
+		return archiveFormat{}, fmt.Errorf("%w: format %d was written by a newer operator, this one reads formats up to %d",
+			ErrUnsupportedArchiveFormat, version, ArchiveFormatVersion)
+	}
+	return format, nil
+}
+
+// archiveManifest records what an archive contains so every entry can be
+// verified independently on restore.
+type archiveManifest struct {
+	FormatVersion int       `json:"formatVersion"`
+	CreatedAt     time.Time `json:"createdAt"`
+	ResourceCount int       `json:"resourceCount"`
+	// RunID identifies the backup run that wrote the archive in the
+	// operator's logs, events and metrics.
+	RunID string `json:"runID,omitempty"`
+	// Cluster identifies the cluster the archive was taken from.
+	Cluster *ClusterIdentity `json:"cluster,omitempty"`
+	// Compression and Encryption record the format the archive was written
+	// in. The format is detected from the archive bytes on restore; these
+	// are kept to spot archives that were repackaged since.
+	Compression Compression `json:"compression,omitempty"`
+	Encryption  string      `json:"encryption,omitempty"`
+	// HelmReleases inventories the Helm release revisions in the archive.
+	HelmReleases []helmRelease `json:"helmReleases,omitempty"`
+	// Files maps each archive entry to its "sha256:<hex>" digest. It also
+	// lists the entries held by Base.
+	Files map[string]string `json:"files"`
+	// ResourceVersions maps the entries of listed objects to the
+	// resourceVersion they were read at.
+	ResourceVersions map[string]string `json:"resourceVersions,omitempty"`
+	// Base names the archive in the same storage location holding the
+	// entries of Files this incremental archive does not.
+	Base string `json:"base,omitempty"`
+	// SlowestResources are the resource types the backup spent the most
+	// time on, slowest first.
+	SlowestResources []manifestTiming `json:"slowestResources,omitempty"`
+	// Partial is set when the backup run was interrupted and the archive
+	// holds only the resources collected until then.
+	Partial bool `json:"partial,omitempty"`
+}
+
+// digest returns the manifest representation of the SHA-256 digest of data.
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return digestPrefix + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,67 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestArchiveFormatsCoverEveryVersion(t *testing.T) {
+	t.Parallel()
+
+	for version := ArchiveFormatUnversioned; version <= ArchiveFormatVersion; version++ {
+		if _, ok := archiveFormats[version]; !ok {
+			t.Errorf("no reader for archive format %d", version)
+		}
+	}
+}
+
+func TestReadArchiveFormats(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storageDir := t.TempDir()
+	bm := &BackupManager{}
+
+	currentName := "cluster-backup-20260101-000000.tar.gz"
+	writeConfigMapArchive(t, filepath.Join(storageDir, currentName), nil, incrementalConfigMap("settings", "1", "a"))
+	_, namespaced, manifest, err := bm.readStoredArchive(ctx, storageDir, currentName, nil, "")
+	if err != nil || len(namespaced) != 1 || formatVersion(manifest) != ArchiveFormatVersion {
+		t.Fatalf("current format: %d resources, manifest %+v, %v", len(namespaced), manifest, err)
+	}
+
+	unversionedName := "cluster-backup-20260101-010000.tar.gz"
+	writeRestoreArchive(t, filepath.Join(storageDir, unversionedName))
+	contents, err := bm.ListArchiveContents(ctx, storageDir, unversionedName, ContentsOptions{})
+	if err != nil || contents.FormatVersion != ArchiveFormatUnversioned || contents.Objects != 2 {
+		t.Fatalf("unversioned format: %+v, %v", contents, err)
+	}
+
+	futureName := "cluster-backup-20260101-020000.tar.gz"
+	file, err := os.Create(filepath.Join(storageDir, futureName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+	writeJSONTarEntry(t, tw, "objects/v1/configmaps/settings.json", map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap"})
+	writeJSONTarEntry(t, tw, manifestName, map[string]interface{}{
+		"formatVersion": ArchiveFormatVersion + 1,
+		"files":         map[string]string{"objects/v1/configmaps/settings.json": "sha256:00"},
+	})
+	for _, closer := range []interface{ Close() error }{tw, gz, file} {
+		if err := closer.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, _, err := bm.readStoredArchive(ctx, storageDir, futureName, nil, ""); !errors.Is(err, ErrUnsupportedArchiveFormat) {
+		t.Fatalf("expected ErrUnsupportedArchiveFormat from restore, got %v", err)
+	}
+	if _, err := bm.ListArchiveContents(ctx, storageDir, futureName, ContentsOptions{}); !errors.Is(err, ErrUnsupportedArchiveFormat) {
+		t.Fatalf("expected ErrUnsupportedArchiveFormat from listing, got %v", err)
+	}
+}
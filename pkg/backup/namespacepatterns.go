@@ -0,0 +1,78 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// namespacePatterns are the compiled ExcludeNamespacePatterns of a backup.
+type namespacePatterns []*regexp.Regexp
+
+// ValidateNamespacePatterns reports the first of patterns that is not a
+// valid regular expression.
+func ValidateNamespacePatterns(patterns []string) error {
+	_, err := compileNamespacePatterns(patterns)
+	return err
+}
+
+func compileNamespacePatterns(patterns []string) (namespacePatterns, error) {
+	var compiled namespacePatterns
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// matches reports whether namespace matches any of the patterns.
+func (p namespacePatterns) matches(namespace string) bool {
+	return namespace != "" && slices.ContainsFunc(p, func(re *regexp.Regexp) bool {
+		return re.MatchString(namespace)
+	})
+}
+
+// filter returns the namespaces that match none of the patterns.
+func (p namespacePatterns) filter(namespaces []string) []string {
+	if len(p) == 0 {
+		return namespaces
+	}
+	var kept []string
+	for _, namespace := range namespaces {
+		if !p.matches(namespace) {
+			kept = append(kept, namespace)
+		}
+	}
+	return kept
+}
+
+// excludes reports whether obj lives in a matching namespace or is the
+// Namespace object of one.
+func (p namespacePatterns) excludes(obj map[string]interface{}) bool {
+	item := unstructured.Unstructured{Object: obj}
+	if namespace := item.GetNamespace(); namespace != "" {
+		return p.matches(namespace)
+	}
+	return item.GetAPIVersion() == "v1" && item.GetKind() == "Namespace" && p.matches(item.GetName())
+}
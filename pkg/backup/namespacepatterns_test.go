@@ -0,0 +1,50 @@
+package backup
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNamespacePatternsExcludePlatformNamespaces(t *testing.T) {
+	t.Parallel()
+
+	patterns, err := compileNamespacePatterns([]string{"^kube-", "^openshift-"})
+	if err != nil {
+		t.Fatalf("compileNamespacePatterns() error = %v", err)
+	}
+
+	got := patterns.filter([]string{"kube-system", "default", "openshift-monitoring", "team-kube-tools"})
+	if want := []string{"default", "team-kube-tools"}; !slices.Equal(got, want) {
+		t.Errorf("filter() = %v, want %v", got, want)
+	}
+
+	for _, tc := range []struct {
+		name string
+		obj  map[string]interface{}
+		want bool
+	}{
+		{"namespaced object", map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap",
+			"metadata": map[string]interface{}{"name": "config", "namespace": "kube-public"}}, true},
+		{"namespace object", map[string]interface{}{"apiVersion": "v1", "kind": "Namespace",
+			"metadata": map[string]interface{}{"name": "openshift-etcd"}}, true},
+		{"other namespace", map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap",
+			"metadata": map[string]interface{}{"name": "config", "namespace": "default"}}, false},
+		{"cluster-scoped object", map[string]interface{}{"apiVersion": "rbac.authorization.k8s.io/v1", "kind": "ClusterRole",
+			"metadata": map[string]interface{}{"name": "kube-admin"}}, false},
+	} {
+		if got := patterns.excludes(tc.obj); got != tc.want {
+			t.Errorf("excludes(%s) = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestValidateNamespacePatternsRejectsInvalidExpressions(t *testing.T) {
+	t.Parallel()
+
+	if err := ValidateNamespacePatterns([]string{"^kube-", "[unclosed"}); err == nil {
+		t.Fatal("ValidateNamespacePatterns() error = nil, want an invalid pattern error")
+	}
+	if err := ValidateNamespacePatterns(nil); err != nil {
+		t.Fatalf("ValidateNamespacePatterns(nil) error = %v", err)
+	}
+}
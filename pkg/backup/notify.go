@@ -0,0 +1,270 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// Notification providers supported alongside the CloudEvents sink.
+// Credentials come from each cloud's default chain, as for the KMS
+// providers.
+const (
+	NotificationProviderSNS        = "aws-sns"
+	NotificationProviderPubSub     = "gcp-pubsub"
+	NotificationProviderServiceBus = "azure-servicebus"
+)
+
+// Notifier publishes CloudEvents to a cloud messaging service.
+type Notifier interface {
+	Notify(ctx context.Context, event CloudEvent) error
+}
+
+// NewNotifier returns a Notifier publishing to the given target:
+//   - aws-sns: a topic ARN
+//   - gcp-pubsub: projects/<p>/topics/<t>
+//   - azure-servicebus: https://<namespace>.servicebus.windows.net/<queue or topic>
+func NewNotifier(ctx context.Context, client *http.Client, provider, target string) (Notifier, error) {
+	if err := ValidateNotificationTarget(provider, target); err != nil {
+		return nil, err
+	}
+	switch provider {
+	case NotificationProviderSNS:
+		return newSNSNotifier(ctx, client, target)
+	case NotificationProviderPubSub:
+		return newPubSubNotifier(ctx, client, target)
+	case NotificationProviderServiceBus:
+		return newServiceBusNotifier(client, target)
+	default:
+		return nil, fmt.Errorf("unsupported notification provider %q", provider)
+	}
+}
+
+// ValidateNotificationTarget checks the format of a notification target
+// without loading credentials.
+func ValidateNotificationTarget(provider, target string) error {
+	switch provider {
+	case NotificationProviderSNS:
+		parts := strings.Split(target, ":")
+		if len(parts) != 6 || parts[0] != "arn" || parts[2] != "sns" || parts[3] == "" || parts[5] == "" {
+			return fmt.Errorf("invalid SNS topic ARN %q", target)
+		}
+	case NotificationProviderPubSub:
+		parts := strings.Split(target, "/")
+		if len(parts) != 4 || parts[0] != "projects" || parts[1] == "" || parts[2] != "topics" || parts[3] == "" {
+			return fmt.Errorf("invalid Pub/Sub topic %q", target)
+		}
+	case NotificationProviderServiceBus:
+		u, err := url.Parse(target)
+		if err != nil || u.Scheme != "https" || u.Host == "" || strings.Trim(u.Path, "/") == "" {
+			return fmt.Errorf("invalid Service Bus queue or topic URL %q", target)
+		}
+	default:
+		return fmt.Errorf("unsupported notification provider %q", provider)
+	}
+	return nil
+}
+
+// encodeCloudEvent returns event in its JSON format, as sent in structured
+// content mode.
+func encodeCloudEvent(event CloudEvent) ([]byte, error) {
+	body, err := json.Marshal(withCloudEventDefaults(event))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode cloud event: %w", err)
+	}
+	return body, nil
+}
+
+// postNotification sends req and reports a non-2xx response as an error.
+func postNotification(client *http.Client, req *http.Request, service string) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", service, err)
+	}
+	defer resp.Body.Close()
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("failed to publish to %s: %s: %s", service, resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+type snsNotifier struct {
+	client      *http.Client
+	endpoint    string
+	region      string
+	topicARN    string
+	credentials aws.CredentialsProvider
+}
+
+func newSNSNotifier(ctx context.Context, client *http.Client, topicARN string) (*snsNotifier, error) {
+	region := strings.Split(topicARN, ":")[3]
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+	return &snsNotifier{
+		client:      client,
+		endpoint:    fmt.Sprintf("https://sns.%s.amazonaws.com/", region),
+		region:      region,
+		topicARN:    topicARN,
+		credentials: cfg.Credentials,
+	}, nil
+}
+
+// Notify publishes the event as the message, with its type as the ce-type
+// message attribute so subscriptions can filter on it.
+func (n *snsNotifier) Notify(ctx context.Context, event CloudEvent) error {
+	message, err := encodeCloudEvent(event)
+	if err != nil {
+		return err
+	}
+	form := url.Values{
+		"Action":                         {"Publish"},
+		"Version":                        {"2010-03-31"},
+		"TopicArn":                       {n.topicARN},
+		"Message":                        {string(message)},
+		"MessageAttributes.entry.1.Name": {"ce-type"},
+		"MessageAttributes.entry.1.Value.DataType":    {"String"},
+		"MessageAttributes.entry.1.Value.StringValue": {event.Type},
+	}
+	// FIFO topics order messages within a group
+	if strings.HasSuffix(n.topicARN, ".fifo") {
+		form.Set("MessageGroupId", event.Source)
+		form.Set("MessageDeduplicationId", event.ID)
+	}
+	body := form.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.endpoint, strings.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create SNS request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	creds, err := n.credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+	sum := sha256.Sum256([]byte(body))
+	if err := v4.NewSigner().SignHTTP(ctx, creds, req, hex.EncodeToString(sum[:]), "sns", n.region, time.Now()); err != nil {
+		return fmt.Errorf("failed to sign SNS request: %w", err)
+	}
+	return postNotification(n.client, req, "SNS")
+}
+
+type pubSubNotifier struct {
+	client   *http.Client
+	endpoint string
+	topic    string
+	tokens   oauth2.TokenSource
+}
+
+func newPubSubNotifier(ctx context.Context, client *http.Client, topic string) (*pubSubNotifier, error) {
+	tokens, err := google.DefaultTokenSource(ctx, "https://www.googleapis.com/auth/pubsub")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load GCP credentials: %w", err)
+	}
+	return &pubSubNotifier{client: client, endpoint: "https://pubsub.googleapis.com", topic: topic, tokens: tokens}, nil
+}
+
+type pubSubMessage struct {
+	Data       string            `json:"data"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// Notify publishes the event in structured content mode, with its type as
+// the ce-type attribute so subscriptions can filter on it.
+func (n *pubSubNotifier) Notify(ctx context.Context, event CloudEvent) error {
+	data, err := encodeCloudEvent(event)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string][]pubSubMessage{"messages": {{
+		Data: base64.StdEncoding.EncodeToString(data),
+		Attributes: map[string]string{
+			"content-type": "application/cloudevents+json",
+			"ce-type":      event.Type,
+		},
+	}}})
+	if err != nil {
+		return fmt.Errorf("failed to encode Pub/Sub request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.endpoint+"/v1/"+n.topic+":publish", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create Pub/Sub request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	token, err := n.tokens.Token()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve GCP token: %w", err)
+	}
+	token.SetAuthHeader(req)
+	return postNotification(n.client, req, "Pub/Sub")
+}
+
+type serviceBusNotifier struct {
+	client     *http.Client
+	entityURL  string
+	credential azcore.TokenCredential
+}
+
+func newServiceBusNotifier(client *http.Client, entityURL string) (*serviceBusNotifier, error) {
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Azure credentials: %w", err)
+	}
+	return &serviceBusNotifier{client: client, entityURL: strings.TrimSuffix(entityURL, "/"), credential: cred}, nil
+}
+
+// Notify sends the event in structured content mode as the message body.
+func (n *serviceBusNotifier) Notify(ctx context.Context, event CloudEvent) error {
+	body, err := encodeCloudEvent(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.entityURL+"/messages", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create Service Bus request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/cloudevents+json")
+	token, err := n.credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{"https://servicebus.azure.net/.default"}})
+	if err != nil {
+		return fmt.Errorf("failed to retrieve Azure token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+	return postNotification(n.client, req, "Service Bus")
+}
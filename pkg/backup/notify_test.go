@@ -0,0 +1,168 @@
+package backup
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"golang.org/x/oauth2"
+)
+
+var testNotification = CloudEvent{
+	ID:     "1",
+	Source: "/apis/backup.backup.io/v1alpha1/namespaces/default/clusterbackups/nightly",
+	Type:   CloudEventArchiveDeleted,
+	Time:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	Data:   map[string]string{"archive": "cluster-backup-20240101-000000.tar.gz"},
+}
+
+// notificationServer records the last request it received.
+func notificationServer(t *testing.T) (*httptest.Server, *http.Request, *[]byte) {
+	t.Helper()
+
+	received := &http.Request{}
+	body := new([]byte)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*received = *r.Clone(context.Background())
+		*body, _ = io.ReadAll(r.Body)
+	}))
+	t.Cleanup(server.Close)
+	return server, received, body
+}
+
+func decodeTestNotification(t *testing.T, data []byte) CloudEvent {
+	t.Helper()
+
+	var event CloudEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		t.Fatalf("message is not a cloud event: %v", err)
+	}
+	if event.SpecVersion != "1.0" || event.Type != testNotification.Type || event.Source != testNotification.Source {
+		t.Errorf("event = %+v", event)
+	}
+	return event
+}
+
+func TestSNSNotifier(t *testing.T) {
+	t.Parallel()
+
+	server, received, body := notificationServer(t)
+	n := &snsNotifier{
+		client:   server.Client(),
+		endpoint: server.URL + "/",
+		region:   "eu-west-1",
+		topicARN: "arn:aws:sns:eu-west-1:123456789012:backups.fifo",
+		credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}, nil
+		}),
+	}
+	if err := n.Notify(context.Background(), testNotification); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	if auth := received.Header.Get("Authorization"); !strings.Contains(auth, "Credential=AKID/") || !strings.Contains(auth, "/eu-west-1/sns/") {
+		t.Errorf("Authorization = %q, want a SigV4 signature for sns in eu-west-1", auth)
+	}
+	form, err := url.ParseQuery(string(*body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if form.Get("Action") != "Publish" || form.Get("TopicArn") != n.topicARN || form.Get("MessageGroupId") != testNotification.Source {
+		t.Errorf("form = %v", form)
+	}
+	if form.Get("MessageAttributes.entry.1.Value.StringValue") != CloudEventArchiveDeleted {
+		t.Errorf("ce-type attribute = %q", form.Get("MessageAttributes.entry.1.Value.StringValue"))
+	}
+	decodeTestNotification(t, []byte(form.Get("Message")))
+}
+
+func TestPubSubNotifier(t *testing.T) {
+	t.Parallel()
+
+	server, received, body := notificationServer(t)
+	n := &pubSubNotifier{
+		client:   server.Client(),
+		endpoint: server.URL,
+		topic:    "projects/acme/topics/backups",
+		tokens:   oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"}),
+	}
+	if err := n.Notify(context.Background(), testNotification); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	if received.URL.Path != "/v1/projects/acme/topics/backups:publish" || received.Header.Get("Authorization") != "Bearer token" {
+		t.Errorf("request = %s %v", received.URL.Path, received.Header)
+	}
+	var publish struct {
+		Messages []pubSubMessage `json:"messages"`
+	}
+	if err := json.Unmarshal(*body, &publish); err != nil || len(publish.Messages) != 1 {
+		t.Fatalf("publish request = %s", *body)
+	}
+	if publish.Messages[0].Attributes["ce-type"] != CloudEventArchiveDeleted {
+		t.Errorf("attributes = %v", publish.Messages[0].Attributes)
+	}
+	data, err := base64.StdEncoding.DecodeString(publish.Messages[0].Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decodeTestNotification(t, data)
+}
+
+type staticTokenCredential string
+
+func (c staticTokenCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: string(c), ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func TestServiceBusNotifier(t *testing.T) {
+	t.Parallel()
+
+	server, received, body := notificationServer(t)
+	n := &serviceBusNotifier{
+		client:     server.Client(),
+		entityURL:  server.URL + "/backups",
+		credential: staticTokenCredential("token"),
+	}
+	if err := n.Notify(context.Background(), testNotification); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	if received.URL.Path != "/backups/messages" || received.Header.Get("Authorization") != "Bearer token" ||
+		received.Header.Get("Content-Type") != "application/cloudevents+json" {
+		t.Errorf("request = %s %v", received.URL.Path, received.Header)
+	}
+	decodeTestNotification(t, *body)
+}
+
+func TestValidateNotificationTarget(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		provider, target string
+		valid            bool
+	}{
+		{NotificationProviderSNS, "arn:aws:sns:us-east-1:123456789012:backups", true},
+		{NotificationProviderSNS, "backups", false},
+		{NotificationProviderPubSub, "projects/acme/topics/backups", true},
+		{NotificationProviderPubSub, "acme/backups", false},
+		{NotificationProviderServiceBus, "https://acme.servicebus.windows.net/backups", true},
+		{NotificationProviderServiceBus, "https://acme.servicebus.windows.net", false},
+		{"slack", "https://hooks.slack.com/x", false},
+	}
+	for _, tt := range tests {
+		if err := ValidateNotificationTarget(tt.provider, tt.target); (err == nil) != tt.valid {
+			t.Errorf("ValidateNotificationTarget(%q, %q) = %v, want valid %v", tt.provider, tt.target, err, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrObjectTypeMismatch is reported for archived objects whose apiVersion
+// or kind does not match the resource type they are restored as.
+var ErrObjectTypeMismatch = errors.New("archived object does not match its resource type")
+
+// checkArchivedType compares the apiVersion of an archived object with the
+// group and version of the path it was archived under. Objects without an
+// apiVersion get the one of their path.
+func checkArchivedType(res archivedResource) error {
+	want := res.gvr.GroupVersion().String()
+	apiVersion := nestedString(res.object, "apiVersion")
+	if apiVersion == "" {
+		res.object["apiVersion"] = want
+		return nil
+	}
+	if apiVersion != want {
+		return fmt.Errorf("%w: apiVersion %s, archived as %s", ErrObjectTypeMismatch, apiVersion, typeName(res))
+	}
+	return nil
+}
+
+// checkKind compares the kind of an archived object with the kind the
+// target cluster serves for its resource type. Objects without a kind get
+// the served one.
+func (s servedAPIs) checkKind(res archivedResource) error {
+	want, ok := s[res.gvr]
+	if !ok || want == "" {
+		return nil
+	}
+	kind := nestedString(res.object, "kind")
+	if kind == "" {
+		res.object["kind"] = want
+		return nil
+	}
+	if kind != want {
+		return fmt.Errorf("%w: kind %s, the target cluster serves %s as %s", ErrObjectTypeMismatch, kind, typeName(res), want)
+	}
+	return nil
+}
+
+// typeName formats the resource type of res as in "deployments.apps/v1".
+func typeName(res archivedResource) string {
+	return res.gvr.GroupResource().String() + "/" + res.gvr.Version
+}
@@ -0,0 +1,86 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestRestoreFailsObjectsOfTheWrongType(t *testing.T) {
+	t.Parallel()
+
+	storageDir := t.TempDir()
+	archiveName := "cluster-backup-types.tar.gz"
+	file, err := os.Create(filepath.Join(storageDir, archiveName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gz)
+	writeJSONTarEntry(t, tarWriter, "namespaces/shop/v1/configmaps/settings.json", configMapObject("shop", "settings").Object)
+	writeJSONTarEntry(t, tarWriter, "namespaces/shop/v1/configmaps/bare.json", map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "bare", "namespace": "shop"},
+	})
+	writeJSONTarEntry(t, tarWriter, "namespaces/shop/v1/configmaps/secret.json", map[string]interface{}{
+		"apiVersion": "v1", "kind": "Secret",
+		"metadata": map[string]interface{}{"name": "secret", "namespace": "shop"},
+	})
+	writeJSONTarEntry(t, tarWriter, "namespaces/shop/apps/v1/deployments/web.json", map[string]interface{}{
+		"apiVersion": "apps/v1beta1", "kind": "Deployment",
+		"metadata": map[string]interface{}{"name": "web", "namespace": "shop"},
+	})
+	for _, closer := range []interface{ Close() error }{tarWriter, gz, file} {
+		if err := closer.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scheme := runtime.NewScheme()
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
+	bm := &BackupManager{
+		DynamicClient: fake.NewSimpleDynamicClient(scheme),
+		DiscoveryClient: &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{
+			{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "configmaps", Kind: "ConfigMap", Namespaced: true}}},
+			{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{{Name: "deployments", Kind: "Deployment", Namespaced: true}}},
+		}}},
+	}
+	result, err := bm.RestoreBackup(context.Background(), storageDir, archiveName, RestoreOptions{})
+	if err != nil {
+		t.Fatalf("RestoreBackup returned error: %v", err)
+	}
+	if result.Created != 2 || result.Failed != 2 {
+		t.Fatalf("restore counts = %+v, want 2 created and 2 failed", result.RestoreCounts)
+	}
+	failed := map[string]bool{}
+	for _, item := range result.FailedItems {
+		if !errors.Is(item.Err, ErrObjectTypeMismatch) {
+			t.Fatalf("failed item %v, want ErrObjectTypeMismatch", item)
+		}
+		failed[item.Name] = true
+	}
+	if !failed["secret"] || !failed["web"] {
+		t.Fatalf("failed items = %v, want secret and web", result.FailedItems)
+	}
+
+	// The object archived without a type is restored as its path says
+	bare, err := bm.DynamicClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).
+		Namespace("shop").Get(context.Background(), "bare", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bare.GetAPIVersion() != "v1" || bare.GetKind() != "ConfigMap" {
+		t.Fatalf("bare object restored as %s %s, want v1 ConfigMap", bare.GetAPIVersion(), bare.GetKind())
+	}
+}
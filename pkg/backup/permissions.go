@@ -0,0 +1,190 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// MissingPermissionPolicy decides what a backup does when the pre-flight
+// check finds resources the operator may not list.
+type MissingPermissionPolicy string
+
+const (
+	// MissingPermissionFail fails the backup before anything is collected.
+	MissingPermissionFail MissingPermissionPolicy = "Fail"
+	// MissingPermissionSkip leaves the resources out and reports them in
+	// BackupResult.SkippedResources.
+	MissingPermissionSkip MissingPermissionPolicy = "Skip"
+)
+
+// permissionCheckWorkers bounds the access reviews sent in parallel.
+const permissionCheckWorkers = 8
+
+var selfSubjectAccessReviews = schema.GroupVersionResource{
+	Group: "authorization.k8s.io", Version: "v1", Resource: "selfsubjectaccessreviews",
+}
+
+// PermissionGap is a resource type the operator may not list, in one
+// namespace or, when Namespace is empty, cluster-wide.
+type PermissionGap struct {
+	GVR       schema.GroupVersionResource
+	Namespace string
+}
+
+func (g PermissionGap) String() string {
+	if g.Namespace == "" {
+		return g.GVR.GroupResource().String()
+	}
+	return g.GVR.GroupResource().String() + " in " + g.Namespace
+}
+
+// MissingPermissionsError is returned under the Fail policy when the
+// operator may not list some of the selected resources.
+type MissingPermissionsError struct {
+	Gaps []PermissionGap
+}
+
+func (e *MissingPermissionsError) Error() string {
+	names := make([]string, len(e.Gaps))
+	for i, gap := range e.Gaps {
+		names[i] = gap.String()
+	}
+	return fmt.Sprintf("operator may not list %s", strings.Join(names, ", "))
+}
+
+// checkListPermissions runs the pre-flight check of opts.MissingPermissionPolicy.
+// It returns the resources the operator may not list, or an error under the
+// Fail policy. Only backups selecting resources by type and namespace are
+// checked; named items and applications are read individually.
+func (bm *BackupManager) checkListPermissions(ctx context.Context, opts BackupOptions) ([]PermissionGap, error) {
+	if opts.MissingPermissionPolicy == "" || opts.Application != nil || len(opts.Items) > 0 {
+		return nil, nil
+	}
+
+	targets := bm.discoverResources(ctx, opts)
+	var namespaces []string
+	for _, target := range targets {
+		if target.namespaced {
+			var err error
+			if namespaces, err = bm.getNamespacesToBackup(ctx, opts); err != nil {
+				return nil, fmt.Errorf("failed to get namespaces: %w", err)
+			}
+			break
+		}
+	}
+
+	var mu sync.Mutex
+	var gaps []PermissionGap
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(permissionCheckWorkers)
+	for _, target := range targets {
+		group.Go(func() error {
+			// A cluster-wide grant covers every namespace
+			allowed, err := bm.canList(groupCtx, target.gvr, "")
+			if err != nil || allowed {
+				return err
+			}
+			var denied []PermissionGap
+			if !target.namespaced {
+				denied = append(denied, PermissionGap{GVR: target.gvr})
+			}
+			for _, ns := range namespacesIf(target.namespaced, namespaces) {
+				allowed, err := bm.canList(groupCtx, target.gvr, ns)
+				if err != nil {
+					return err
+				}
+				if !allowed {
+					denied = append(denied, PermissionGap{GVR: target.gvr, Namespace: ns})
+				}
+			}
+			mu.Lock()
+			gaps = append(gaps, denied...)
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, fmt.Errorf("failed to check list permissions: %w", err)
+	}
+
+	sort.Slice(gaps, func(i, j int) bool {
+		return gaps[i].String() < gaps[j].String()
+	})
+	if len(gaps) > 0 && opts.MissingPermissionPolicy == MissingPermissionFail {
+		return nil, &MissingPermissionsError{Gaps: gaps}
+	}
+	if len(gaps) > 0 {
+		ctrl.LoggerFrom(ctx).Info("Skipping resources the operator may not list", "resources", gaps)
+	}
+	return gaps, nil
+}
+
+// namespacesIf returns namespaces for namespaced resources and none
+// otherwise.
+func namespacesIf(namespaced bool, namespaces []string) []string {
+	if namespaced {
+		return namespaces
+	}
+	return nil
+}
+
+// canList asks the apiserver whether the operator may list gvr in namespace,
+// or cluster-wide when namespace is empty.
+func (bm *BackupManager) canList(ctx context.Context, gvr schema.GroupVersionResource, namespace string) (bool, error) {
+	review := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "authorization.k8s.io/v1",
+		"kind":       "SelfSubjectAccessReview",
+		"spec": map[string]interface{}{
+			"resourceAttributes": map[string]interface{}{
+				"verb":      "list",
+				"group":     gvr.Group,
+				"version":   gvr.Version,
+				"resource":  gvr.Resource,
+				"namespace": namespace,
+			},
+		},
+	}}
+	result, err := bm.DynamicClient.Resource(selfSubjectAccessReviews).Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	allowed, _, _ := unstructured.NestedBool(result.Object, "status", "allowed")
+	return allowed, nil
+}
+
+// permissionGapSet indexes gaps for collectResources.
+func permissionGapSet(gaps []PermissionGap) map[PermissionGap]struct{} {
+	if len(gaps) == 0 {
+		return nil
+	}
+	set := make(map[PermissionGap]struct{}, len(gaps))
+	for _, gap := range gaps {
+		set[gap] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,99 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// newPermissionTestManager serves ConfigMaps, Secrets and Nodes, allowing
+// list everywhere except Secrets outside the "apps" namespace and Nodes.
+func newPermissionTestManager(t *testing.T) *BackupManager {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	dynamicClient := fake.NewSimpleDynamicClient(scheme,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "apps"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payments"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "apps"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "payments"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "apps"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "payments"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+	)
+	dynamicClient.PrependReactor("create", "selfsubjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		review := action.(clienttesting.CreateAction).GetObject().(*unstructured.Unstructured)
+		resource, _, _ := unstructured.NestedString(review.Object, "spec", "resourceAttributes", "resource")
+		namespace, _, _ := unstructured.NestedString(review.Object, "spec", "resourceAttributes", "namespace")
+		allowed := resource == "configmaps" || (resource == "secrets" && namespace == "apps")
+		review = review.DeepCopy()
+		review.Object["status"] = map[string]interface{}{"allowed": allowed}
+		return true, review, nil
+	})
+	return &BackupManager{
+		DynamicClient: dynamicClient,
+		DiscoveryClient: preferredResources{lists: []*metav1.APIResourceList{
+			{GroupVersion: "v1", APIResources: []metav1.APIResource{
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"list"}},
+				{Name: "secrets", Kind: "Secret", Namespaced: true, Verbs: []string{"list"}},
+				{Name: "nodes", Kind: "Node", Verbs: []string{"list"}},
+			}},
+		}},
+	}
+}
+
+func TestCheckListPermissions(t *testing.T) {
+	t.Parallel()
+
+	bm := newPermissionTestManager(t)
+	opts := BackupOptions{
+		IncludeClusterResources: true,
+		ResourceTypes:           []string{"ConfigMap", "Secret", "Node"},
+		MissingPermissionPolicy: MissingPermissionSkip,
+	}
+
+	gaps, err := bm.checkListPermissions(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("checkListPermissions: %v", err)
+	}
+	want := []PermissionGap{
+		{GVR: schema.GroupVersionResource{Version: "v1", Resource: "nodes"}},
+		{GVR: schema.GroupVersionResource{Version: "v1", Resource: "secrets"}, Namespace: "payments"},
+	}
+	if len(gaps) != len(want) || gaps[0] != want[0] || gaps[1] != want[1] {
+		t.Fatalf("gaps = %v, want %v", gaps, want)
+	}
+
+	// Only the permitted resources are estimated
+	estimate, err := bm.EstimateBackup(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("EstimateBackup: %v", err)
+	}
+	if estimate.Items != 3 || len(estimate.SkippedResources) != 2 {
+		t.Errorf("estimate = %d items skipping %v, want 3 items skipping 2", estimate.Items, estimate.SkippedResources)
+	}
+
+	opts.MissingPermissionPolicy = MissingPermissionFail
+	_, err = bm.checkListPermissions(context.Background(), opts)
+	var missing *MissingPermissionsError
+	if !errors.As(err, &missing) || missing.Error() != "operator may not list nodes, secrets in payments" {
+		t.Fatalf("err = %v, want a MissingPermissionsError", err)
+	}
+
+	// Without a policy nothing is checked
+	opts.MissingPermissionPolicy = ""
+	if gaps, err := bm.checkListPermissions(context.Background(), opts); err != nil || gaps != nil {
+		t.Fatalf("checkListPermissions without policy = %v, %v", gaps, err)
+	}
+}
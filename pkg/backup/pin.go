@@ -0,0 +1,95 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// keepSuffix names the sidecar marker that pins an archive. Pinned archives
+// are never pruned by retention and do not count towards maxArchives.
+const keepSuffix = ".keep"
+
+// isPinned reports whether archivePath has a keep marker.
+func isPinned(archivePath string) bool {
+	_, err := os.Stat(archivePath + keepSuffix)
+	return err == nil
+}
+
+// IsPinned reports whether the named archive in storagePath is pinned.
+func (bm *BackupManager) IsPinned(storagePath, archiveName string) bool {
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return false
+	}
+	return isPinned(filepath.Join(resolvedStoragePath, archiveName))
+}
+
+// SyncPinnedArchives makes the keep markers owned by owner in storagePath
+// match archives: markers are written for the listed archives present there
+// and removed from archives no longer listed. Markers written by hand or by
+// another owner are left alone.
+func (bm *BackupManager) SyncPinnedArchives(storagePath, owner string, archives []string) error {
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(resolvedStoragePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read storage directory: %w", err)
+	}
+
+	wanted := makeStringSet(archives, nil)
+	marker := []byte(owner + "\n")
+	for _, e := range entries {
+		name := e.Name()
+		switch {
+		case isArchiveName(name):
+			if _, ok := wanted[name]; !ok {
+				continue
+			}
+			markerPath := filepath.Join(resolvedStoragePath, name+keepSuffix)
+			if _, err := os.Stat(markerPath); err == nil {
+				continue
+			}
+			if err := os.WriteFile(markerPath, marker, 0644); err != nil {
+				return fmt.Errorf("failed to pin archive %q: %w", name, err)
+			}
+		case strings.HasSuffix(name, keepSuffix):
+			archive := strings.TrimSuffix(name, keepSuffix)
+			if _, ok := wanted[archive]; ok {
+				continue
+			}
+			markerPath := filepath.Join(resolvedStoragePath, name)
+			if data, err := os.ReadFile(markerPath); err != nil || !bytes.Equal(data, marker) {
+				continue
+			}
+			if err := os.Remove(markerPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to unpin archive %q: %w", archive, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCleanupArchivesSkipsPinnedArchives(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	old := time.Now().Add(-10 * 24 * time.Hour)
+	names := []string{
+		"cluster-backup-20250101-000000.tar.gz", // pinned by hand
+		"cluster-backup-20250102-000000.tar.gz",
+		"cluster-backup-20250103-000000.tar.gz",
+		"cluster-backup-20250104-000000.tar.gz",
+	}
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, names[0]+keepSuffix), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Pinned archives do not count towards maxArchives
+	maxArchives := 2
+	if _, err := (&BackupManager{}).CleanupArchives(dir, nil, &maxArchives); err != nil {
+		t.Fatalf("CleanupArchives: %v", err)
+	}
+	for i, name := range names {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if exists := err == nil; exists != (i != 1) {
+			t.Fatalf("%s exists = %v", name, exists)
+		}
+	}
+
+	retentionDays := 1
+	if _, err := (&BackupManager{}).CleanupArchives(dir, &retentionDays, nil); err != nil {
+		t.Fatalf("CleanupArchives: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, names[0])); err != nil {
+		t.Fatalf("pinned archive was removed: %v", err)
+	}
+}
+
+func TestSyncPinnedArchives(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	names := []string{
+		"cluster-backup-20250101-000000.tar.gz",
+		"cluster-backup-20250102-000000.tar.gz",
+		"cluster-backup-20250103-000000.tar.gz",
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Pinned by hand, so left alone when unlisted
+	if err := os.WriteFile(filepath.Join(dir, names[2]+keepSuffix), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	bm := &BackupManager{}
+	if err := bm.SyncPinnedArchives(dir, "owner", names[:2]); err != nil {
+		t.Fatalf("SyncPinnedArchives: %v", err)
+	}
+	for _, name := range names {
+		if !bm.IsPinned(dir, name) {
+			t.Fatalf("%s is not pinned", name)
+		}
+	}
+
+	if err := bm.SyncPinnedArchives(dir, "owner", names[1:2]); err != nil {
+		t.Fatalf("SyncPinnedArchives: %v", err)
+	}
+	if bm.IsPinned(dir, names[0]) {
+		t.Fatalf("%s is still pinned after being unlisted", names[0])
+	}
+	if !bm.IsPinned(dir, names[1]) || !bm.IsPinned(dir, names[2]) {
+		t.Fatal("unpinned an archive that is still listed or pinned by hand")
+	}
+}
+
+func TestTransferArchiveMoveKeepsPin(t *testing.T) {
+	t.Parallel()
+
+	source := t.TempDir()
+	destination := t.TempDir()
+	name := "cluster-backup-20250101-000000.tar.gz"
+	if err := os.WriteFile(filepath.Join(source, name), []byte(name), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	bm := &BackupManager{}
+	if err := bm.SyncPinnedArchives(source, "owner", []string{name}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := bm.TransferArchive(context.Background(), source, name, destination, true); err != nil {
+		t.Fatalf("TransferArchive: %v", err)
+	}
+	if !bm.IsPinned(destination, name) {
+		t.Fatal("moved archive lost its pin")
+	}
+	if _, err := os.Stat(filepath.Join(source, name+keepSuffix)); !os.IsNotExist(err) {
+		t.Fatalf("keep marker left in source: %v", err)
+	}
+}
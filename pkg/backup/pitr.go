@@ -0,0 +1,214 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// maxChangeRecordSize bounds one line of a change log.
+const maxChangeRecordSize = 16 << 20
+
+// ErrChangeLogGap is reported when the change log does not cover the time
+// between the base archive and the requested point in time.
+var ErrChangeLogGap = errors.New("change log does not cover the requested time")
+
+// pointInTimeArchive returns the newest archive in storagePath that was
+// complete at t.
+func (bm *BackupManager) pointInTimeArchive(storagePath string, t time.Time) (string, error) {
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return "", err
+	}
+	archives, err := bm.ListArchives(storagePath)
+	if err != nil {
+		return "", err
+	}
+	for i := len(archives) - 1; i >= 0; i-- {
+		info, err := os.Stat(filepath.Join(resolvedStoragePath, archives[i]))
+		if err != nil {
+			continue
+		}
+		if !info.ModTime().After(t) {
+			return archives[i], nil
+		}
+	}
+	return "", fmt.Errorf("no archive in %s was taken before %s", storagePath, t.UTC().Format(time.RFC3339))
+}
+
+// replayChangeLog applies the changes recorded in storagePath from the time
+// the base archive was started until t to the archived resources. Upserts
+// replace or add objects and tombstones remove them, so deleted objects are
+// not restored. Changes recorded while the archive was being written may
+// already be in it; replaying them again in order converges on the same
+// state.
+func (bm *BackupManager) replayChangeLog(ctx context.Context, storagePath string, base *archiveManifest, t time.Time,
+	clusterResources, namespacedResources []archivedResource, result *RestoreResult) ([]archivedResource, []archivedResource, error) {
+	if base == nil {
+		return nil, nil, fmt.Errorf("the archive has no manifest recording when it was taken")
+	}
+	if t.Before(base.CreatedAt) {
+		return nil, nil, fmt.Errorf("the archive was taken at %s, after the requested point in time",
+			base.CreatedAt.UTC().Format(time.RFC3339))
+	}
+
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	records, err := readChangeLog(ctx, filepath.Join(resolvedStoragePath, ChangeLogDir), base.CreatedAt, t)
+	if err != nil {
+		return nil, nil, err
+	}
+	clusterResources, namespacedResources = applyChanges(clusterResources, namespacedResources, records)
+	result.ReplayedChanges = len(records)
+	ctrl.LoggerFrom(ctx).Info("Replayed change log", "from", base.CreatedAt, "to", t, "changes", len(records))
+	return clusterResources, namespacedResources, nil
+}
+
+// readChangeLog returns the records in dir written from from until to, in
+// the order they were written. The first segment must have started by
+// from, so no change in between is missing.
+func readChangeLog(ctx context.Context, dir string, from, to time.Time) ([]ChangeRecord, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	segments, err := listChangeLogSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+	// Segment names have second precision
+	if len(segments) == 0 || segments[0].start.After(from.Truncate(time.Second)) {
+		return nil, fmt.Errorf("%w: no change log was being written at %s", ErrChangeLogGap, from.UTC().Format(time.RFC3339))
+	}
+
+	var records []ChangeRecord
+	for i, segment := range segments {
+		if segment.start.After(to) {
+			break
+		}
+		// Skip segments that ended before from
+		if i+1 < len(segments) && !segments[i+1].start.After(from.Truncate(time.Second)) {
+			continue
+		}
+		segmentRecords, err := readChangeLogSegment(segment.path, from, to)
+		if err != nil {
+			return nil, err
+		}
+		if len(segmentRecords.skipped) > 0 {
+			log.Info("Skipping unreadable change log lines", "segment", segment.path, "lines", segmentRecords.skipped)
+		}
+		records = append(records, segmentRecords.records...)
+	}
+	return records, nil
+}
+
+// changeLogSegmentRecords holds what was read from one segment.
+type changeLogSegmentRecords struct {
+	records []ChangeRecord
+	// skipped lists the line numbers that could not be decoded, such as a
+	// last line cut short when the operator stopped.
+	skipped []int
+}
+
+// readChangeLogSegment returns the records of one segment written from
+// from until to.
+func readChangeLogSegment(path string, from, to time.Time) (changeLogSegmentRecords, error) {
+	var out changeLogSegmentRecords
+	file, err := os.Open(path)
+	if err != nil {
+		return out, fmt.Errorf("failed to open change log segment: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxChangeRecordSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		var record ChangeRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			out.skipped = append(out.skipped, line)
+			continue
+		}
+		if record.Time.Before(from) || record.Time.After(to) {
+			continue
+		}
+		out.records = append(out.records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return out, fmt.Errorf("failed to read change log segment %s: %w", filepath.Base(path), err)
+	}
+	return out, nil
+}
+
+// changeKey identifies an object across API versions.
+type changeKey struct {
+	resource  schema.GroupResource
+	namespace string
+	name      string
+}
+
+// applyChanges replays records onto the archived resources, keeping the
+// archive order for objects it holds and appending new ones.
+func applyChanges(clusterResources, namespacedResources []archivedResource, records []ChangeRecord) ([]archivedResource, []archivedResource) {
+	resources := append(clusterResources, namespacedResources...)
+	index := make(map[changeKey]int, len(resources))
+	for i, res := range resources {
+		index[changeKey{res.gvr.GroupResource(), res.namespace, res.name}] = i
+	}
+
+	deleted := map[int]struct{}{}
+	for _, record := range records {
+		key := changeKey{record.GVR().GroupResource(), record.Namespace, record.Name}
+		i, ok := index[key]
+		switch {
+		case record.Type == ChangeDelete:
+			if ok {
+				deleted[i] = struct{}{}
+			}
+		case ok:
+			resources[i] = archivedResource{gvr: record.GVR(), namespace: record.Namespace, name: record.Name, object: record.Object}
+			delete(deleted, i)
+		default:
+			index[key] = len(resources)
+			resources = append(resources, archivedResource{gvr: record.GVR(), namespace: record.Namespace, name: record.Name, object: record.Object})
+		}
+	}
+
+	var cluster, namespaced []archivedResource
+	for i, res := range resources {
+		if _, ok := deleted[i]; ok {
+			continue
+		}
+		if res.namespace == "" {
+			cluster = append(cluster, res)
+		} else {
+			namespaced = append(namespaced, res)
+		}
+	}
+	return cluster, namespaced
+}
@@ -0,0 +1,127 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestApplyChanges(t *testing.T) {
+	t.Parallel()
+
+	namespaces := schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+	configMaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	cluster := []archivedResource{{gvr: namespaces, name: "app", object: map[string]interface{}{"kind": "Namespace"}}}
+	namespaced := []archivedResource{
+		{gvr: configMaps, namespace: "app", name: "changed", object: map[string]interface{}{"data": "old"}},
+		{gvr: configMaps, namespace: "app", name: "deleted"},
+		{gvr: configMaps, namespace: "app", name: "recreated"},
+	}
+	records := []ChangeRecord{
+		{Type: ChangeUpsert, Version: "v1", Resource: "configmaps", Namespace: "app", Name: "changed", Object: map[string]interface{}{"data": "new"}},
+		{Type: ChangeDelete, Version: "v1", Resource: "configmaps", Namespace: "app", Name: "deleted"},
+		{Type: ChangeDelete, Version: "v1", Resource: "configmaps", Namespace: "app", Name: "recreated"},
+		{Type: ChangeUpsert, Version: "v1", Resource: "configmaps", Namespace: "app", Name: "recreated", Object: map[string]interface{}{}},
+		{Type: ChangeUpsert, Version: "v1", Resource: "namespaces", Name: "added", Object: map[string]interface{}{}},
+		{Type: ChangeDelete, Version: "v1", Resource: "configmaps", Namespace: "app", Name: "never-archived"},
+	}
+
+	cluster, namespaced = applyChanges(cluster, namespaced, records)
+	if len(cluster) != 2 || cluster[0].name != "app" || cluster[1].name != "added" {
+		t.Fatalf("cluster resources = %+v, want app and added", cluster)
+	}
+	if len(namespaced) != 2 || namespaced[0].name != "changed" || namespaced[1].name != "recreated" {
+		t.Fatalf("namespaced resources = %+v, want changed and recreated", namespaced)
+	}
+	if namespaced[0].object["data"] != "new" {
+		t.Fatalf("changed object = %v, want the replayed version", namespaced[0].object)
+	}
+}
+
+func TestReadChangeLog(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	writeChangeLogSegment(t, dir, base, []ChangeRecord{
+		{Time: base.Add(time.Minute), Type: ChangeUpsert, Name: "before-archive"},
+		{Time: base.Add(20 * time.Minute), Type: ChangeUpsert, Name: "first"},
+	})
+	writeChangeLogSegment(t, dir, base.Add(time.Hour), []ChangeRecord{
+		{Time: base.Add(70 * time.Minute), Type: ChangeDelete, Name: "second"},
+		{Time: base.Add(90 * time.Minute), Type: ChangeUpsert, Name: "after-point-in-time"},
+	})
+	file, err := os.OpenFile(filepath.Join(dir, "changes-20260301-110000.jsonl"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteString(`{"time":"2026-03-01T11:`); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	records, err := readChangeLog(context.Background(), dir, base.Add(10*time.Minute), base.Add(80*time.Minute))
+	if err != nil {
+		t.Fatalf("readChangeLog returned error: %v", err)
+	}
+	if len(records) != 2 || records[0].Name != "first" || records[1].Name != "second" {
+		t.Fatalf("records = %+v, want first and second", records)
+	}
+
+	_, err = readChangeLog(context.Background(), dir, base.Add(-time.Minute), base.Add(80*time.Minute))
+	if !errors.Is(err, ErrChangeLogGap) {
+		t.Fatalf("error = %v, want ErrChangeLogGap for an archive older than the change log", err)
+	}
+}
+
+func TestPointInTimeArchive(t *testing.T) {
+	t.Parallel()
+
+	storageDir := t.TempDir()
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	for i, name := range []string{"cluster-backup-20260301-100000.tar.gz", "cluster-backup-20260301-110000.tar.gz"} {
+		path := filepath.Join(storageDir, name)
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		modTime := base.Add(time.Duration(i) * time.Hour)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bm := &BackupManager{}
+
+	name, err := bm.pointInTimeArchive(storageDir, base.Add(90*time.Minute))
+	if err != nil || name != "cluster-backup-20260301-110000.tar.gz" {
+		t.Fatalf("archive = %q, %v, want the newer archive", name, err)
+	}
+	name, err = bm.pointInTimeArchive(storageDir, base.Add(30*time.Minute))
+	if err != nil || name != "cluster-backup-20260301-100000.tar.gz" {
+		t.Fatalf("archive = %q, %v, want the older archive", name, err)
+	}
+	if _, err := bm.pointInTimeArchive(storageDir, base.Add(-time.Minute)); err == nil {
+		t.Fatal("expected an error for a time before every archive")
+	}
+}
+
+func writeChangeLogSegment(t *testing.T, dir string, start time.Time, records []ChangeRecord) {
+	t.Helper()
+
+	file, err := os.Create(filepath.Join(dir, changeLogPrefix+start.UTC().Format("20060102-150405")+changeLogSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	encoder := json.NewEncoder(file)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
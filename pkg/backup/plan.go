@@ -0,0 +1,240 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// PlanAction is what a restore would do with an archived item.
+type PlanAction string
+
+const (
+	// PlanActionCreate creates an item missing from the cluster.
+	PlanActionCreate PlanAction = "Create"
+	// PlanActionUpdate changes an existing item to match the archive.
+	PlanActionUpdate PlanAction = "Update"
+	// PlanActionUnchanged reapplies an item that already matches the archive.
+	PlanActionUnchanged PlanAction = "Unchanged"
+	// PlanActionSkip leaves an existing item untouched.
+	PlanActionSkip PlanAction = "Skip"
+	// PlanActionFail reports an item that cannot be restored.
+	PlanActionFail PlanAction = "Fail"
+)
+
+const (
+	// MaxPlannedItems caps how many items a RestorePlan lists.
+	MaxPlannedItems = 100
+	// maxPlannedChanges caps how many changed fields an item lists.
+	maxPlannedChanges = 10
+)
+
+// PlannedItem is what a restore would do with one archived item.
+type PlannedItem struct {
+	GVR       schema.GroupVersionResource
+	Namespace string
+	Name      string
+	Action    PlanAction
+	// Conflict is set for updates to objects another controller manages,
+	// which may revert the change.
+	Conflict bool
+	// Changes lists the fields set by the archive whose live value an
+	// update changes, such as "spec.replicas".
+	Changes []string
+	// Reason explains skipped, failed and conflicting items.
+	Reason string
+}
+
+func (i PlannedItem) String() string {
+	item := i.Name
+	if i.Namespace != "" {
+		item = i.Namespace + "/" + i.Name
+	}
+	return fmt.Sprintf("%s %s %s", i.Action, i.GVR.GroupResource(), item)
+}
+
+// RestorePlan previews a restore without applying anything.
+type RestorePlan struct {
+	Create    int
+	Update    int
+	Unchanged int
+	Skip      int
+	Fail      int
+	// Conflicts counts the updates with Conflict set.
+	Conflicts int
+	// Items lists the planned items in apply order, except unchanged ones,
+	// capped at MaxPlannedItems.
+	Items []PlannedItem
+	// Checks carries the outcome of the checks run before a restore applies
+	// anything, such as quota shortfalls and removed API versions.
+	Checks *RestoreResult
+}
+
+func (p *RestorePlan) add(item PlannedItem) {
+	switch item.Action {
+	case PlanActionCreate:
+		p.Create++
+	case PlanActionUpdate:
+		p.Update++
+		if item.Conflict {
+			p.Conflicts++
+		}
+	case PlanActionUnchanged:
+		p.Unchanged++
+		return
+	case PlanActionSkip:
+		p.Skip++
+	case PlanActionFail:
+		p.Fail++
+	}
+	if len(p.Items) < MaxPlannedItems {
+		p.Items = append(p.Items, item)
+	}
+}
+
+// String summarizes the plan in the style of "3 to create, 1 to update".
+func (p *RestorePlan) String() string {
+	return fmt.Sprintf("%d to create, %d to update (%d conflicting), %d unchanged, %d to skip, %d failing",
+		p.Create, p.Update, p.Conflicts, p.Unchanged, p.Skip, p.Fail)
+}
+
+// PlanRestore resolves what RestoreBackup would do with the archive at
+// storagePath/archiveName under opts, running the same checks and ordering
+// but applying nothing. Each item is compared with its live object.
+func (bm *BackupManager) PlanRestore(ctx context.Context, storagePath, archiveName string, opts RestoreOptions) (*RestorePlan, error) {
+	prepared, err := bm.prepareRestore(ctx, storagePath, archiveName, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer prepared.release()
+
+	// Items left out by the checks are only counted
+	plan := &RestorePlan{Skip: prepared.result.Skipped, Checks: prepared.result}
+	for _, list := range prepared.lists {
+		for _, res := range list {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			plan.add(bm.planItem(ctx, res, opts.existingPolicyFor(res)))
+		}
+	}
+	return plan, nil
+}
+
+// planItem compares res with its live object to decide what applying it
+// under policy would do.
+func (bm *BackupManager) planItem(ctx context.Context, res archivedResource, policy ExistingResourcePolicy) PlannedItem {
+	item := PlannedItem{GVR: res.gvr, Namespace: res.namespace, Name: res.name}
+	if res.err != nil {
+		item.Action = PlanActionFail
+		item.Reason = res.err.Error()
+		return item
+	}
+
+	archived := &unstructured.Unstructured{Object: res.object}
+	item.Name = archived.GetName()
+	live, err := bm.resourceClientFor(res).Get(ctx, item.Name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		item.Action = PlanActionCreate
+		return item
+	case err != nil:
+		item.Action = PlanActionFail
+		item.Reason = fmt.Sprintf("failed to fetch existing resource: %v", err)
+		return item
+	case policy == existingResourcePolicyKeep:
+		item.Action = PlanActionSkip
+		item.Reason = "existing object is kept"
+		return item
+	}
+
+	changes := objectChanges(archived.Object, live.Object)
+	if len(changes) == 0 {
+		item.Action = PlanActionUnchanged
+		return item
+	}
+	item.Action = PlanActionUpdate
+	if len(changes) > maxPlannedChanges {
+		changes = append(changes[:maxPlannedChanges], fmt.Sprintf("and %d more", len(changes)-maxPlannedChanges))
+	}
+	item.Changes = changes
+	if owner := metav1.GetControllerOf(live); owner != nil {
+		item.Conflict = true
+		item.Reason = fmt.Sprintf("controlled by %s %s", owner.Kind, owner.Name)
+	} else if isGitOpsManaged(live.Object) {
+		item.Conflict = true
+		item.Reason = "tracked by Argo CD or Flux"
+	}
+	return item
+}
+
+// objectChanges lists the fields set in archived whose value differs in
+// live. Only labels and annotations are compared from metadata, and status
+// is ignored.
+func objectChanges(archived, live map[string]interface{}) []string {
+	var changes []string
+	for _, key := range slices.Sorted(maps.Keys(archived)) {
+		switch key {
+		case "apiVersion", "kind", "status":
+		case "metadata":
+			archivedMeta, _ := archived[key].(map[string]interface{})
+			liveMeta, _ := live[key].(map[string]interface{})
+			for _, field := range []string{"labels", "annotations"} {
+				if value, ok := archivedMeta[field]; ok {
+					changes = diffValues(changes, "metadata."+field, value, liveMeta[field])
+				}
+			}
+		default:
+			changes = diffValues(changes, key, archived[key], live[key])
+		}
+	}
+	return changes
+}
+
+// diffValues appends path to changes for every field of archived that
+// differs in live, descending into objects.
+func diffValues(changes []string, path string, archived, live interface{}) []string {
+	archivedMap, ok := archived.(map[string]interface{})
+	liveMap, liveOK := live.(map[string]interface{})
+	if ok && liveOK {
+		for _, key := range slices.Sorted(maps.Keys(archivedMap)) {
+			changes = diffValues(changes, path+"."+key, archivedMap[key], liveMap[key])
+		}
+		return changes
+	}
+	// Archived numbers decode as float64 and live ones as int64, so values
+	// are compared in their JSON form
+	archivedJSON, err := json.Marshal(archived)
+	if err != nil {
+		return append(changes, path)
+	}
+	liveJSON, err := json.Marshal(live)
+	if err != nil || !bytes.Equal(archivedJSON, liveJSON) {
+		return append(changes, path)
+	}
+	return changes
+}
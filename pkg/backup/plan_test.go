@@ -0,0 +1,76 @@
+package backup
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+)
+
+func TestPlanRestore(t *testing.T) {
+	t.Parallel()
+
+	storageDir := t.TempDir()
+	archiveName := "cluster-backup-restore.tar.gz"
+	writeRestoreArchive(t, filepath.Join(storageDir, archiveName))
+
+	live := configMapObject("restore-ns", "sample-config")
+	live.Object["data"] = map[string]interface{}{"key": "drifted", "extra": "kept"}
+	live.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "v1", Kind: "Deployment", Name: "web", Controller: ptr.To(true)}})
+	bm := &BackupManager{DynamicClient: newQuotaTestClient(live)}
+
+	plan, err := bm.PlanRestore(context.Background(), storageDir, archiveName, RestoreOptions{})
+	if err != nil {
+		t.Fatalf("PlanRestore returned error: %v", err)
+	}
+	if plan.Create != 1 || plan.Update != 1 || plan.Conflicts != 1 || plan.Unchanged != 0 {
+		t.Fatalf("plan = %s, want 1 to create and 1 conflicting update", plan)
+	}
+	update := plan.Items[1]
+	if update.Action != PlanActionUpdate || !reflect.DeepEqual(update.Changes, []string{"data.key"}) || update.Reason != "controlled by Deployment web" {
+		t.Fatalf("update = %+v, want a conflicting change of data.key", update)
+	}
+
+	namespaceGVR := schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+	if _, err := bm.DynamicClient.Resource(namespaceGVR).Get(context.Background(), "restore-ns", metav1.GetOptions{}); err == nil {
+		t.Fatalf("expected planning to apply nothing")
+	}
+
+	// After a restore every item matches the archive
+	if _, err := bm.RestoreBackup(context.Background(), storageDir, archiveName, RestoreOptions{}); err != nil {
+		t.Fatalf("RestoreBackup returned error: %v", err)
+	}
+	plan, err = bm.PlanRestore(context.Background(), storageDir, archiveName, RestoreOptions{})
+	if err != nil {
+		t.Fatalf("PlanRestore returned error: %v", err)
+	}
+	if plan.Unchanged != 2 || len(plan.Items) != 0 {
+		t.Fatalf("plan = %s with items %v, want 2 unchanged", plan, plan.Items)
+	}
+}
+
+func TestObjectChanges(t *testing.T) {
+	t.Parallel()
+
+	archived := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"metadata":   map[string]interface{}{"name": "web", "labels": map[string]interface{}{"app": "web"}},
+		"spec":       map[string]interface{}{"replicas": float64(3), "paused": false},
+		"status":     map[string]interface{}{"replicas": float64(3)},
+	}
+	live := (&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"metadata":   map[string]interface{}{"name": "web", "uid": "1", "labels": map[string]interface{}{"app": "web", "team": "a"}},
+		"spec":       map[string]interface{}{"replicas": int64(3), "paused": true, "strategy": "RollingUpdate"},
+		"status":     map[string]interface{}{"replicas": int64(1)},
+	}}).Object
+
+	if got, want := objectChanges(archived, live), []string{"spec.paused"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("objectChanges = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// quiescedReplicasAnnotation records the replicas a workload had before a
+// restore quiesced it. Keeping it on the object lets a later restore scale
+// the workload back if the operator stopped before it could.
+const quiescedReplicasAnnotation = "backup.backup.io/quiesced-replicas"
+
+// defaultQuiesceTimeout bounds how long a restore waits for the pods of
+// quiesced workloads to stop.
+const defaultQuiesceTimeout = 2 * time.Minute
+
+const quiescePollInterval = 2 * time.Second
+
+// Workload names a Deployment or StatefulSet.
+type Workload struct {
+	// Kind is Deployment or StatefulSet. Empty means Deployment.
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func (w Workload) gvr() (schema.GroupVersionResource, error) {
+	switch w.Kind {
+	case "Deployment", "":
+		return schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, nil
+	case "StatefulSet":
+		return schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"}, nil
+	}
+	return schema.GroupVersionResource{}, fmt.Errorf("cannot quiesce workloads of kind %q", w.Kind)
+}
+
+// quiesce scales obj to zero replicas, recording its replicas in
+// quiescedReplicasAnnotation unless an earlier quiesce already did. It
+// reports whether obj changed.
+func quiesce(obj *unstructured.Unstructured) bool {
+	annotations := obj.GetAnnotations()
+	replicas := workloadReplicas(obj.Object)
+	if _, ok := annotations[quiescedReplicasAnnotation]; ok && replicas == 0 {
+		return false
+	}
+	if _, ok := annotations[quiescedReplicasAnnotation]; !ok {
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[quiescedReplicasAnnotation] = strconv.FormatInt(replicas, 10)
+		obj.SetAnnotations(annotations)
+	}
+	_ = unstructured.SetNestedField(obj.Object, int64(0), "spec", "replicas")
+	return true
+}
+
+// workloadReplicas returns the replicas of a Deployment or StatefulSet,
+// which Kubernetes defaults to one.
+func workloadReplicas(obj map[string]interface{}) int64 {
+	value, _, _ := unstructured.NestedFieldNoCopy(obj, "spec", "replicas")
+	switch v := value.(type) {
+	case int64:
+		return v
+	case float64:
+		// Objects decoded from plain JSON hold float64 numbers
+		return int64(v)
+	}
+	return 1
+}
+
+// quiesceArchived quiesces the archived copies of workloads among
+// resources, so restoring them does not start the controllers the restore
+// stopped. They are scaled back to their archived replicas afterwards.
+func quiesceArchived(resources []archivedResource, workloads []Workload) {
+	for _, res := range resources {
+		if res.err != nil || !isScalableWorkload(res) {
+			continue
+		}
+		for _, w := range workloads {
+			if gvr, err := w.gvr(); err == nil && gvr.Resource == res.gvr.Resource && w.Namespace == res.namespace && w.Name == res.name {
+				quiesce(&unstructured.Unstructured{Object: res.object})
+				break
+			}
+		}
+	}
+}
+
+// quiesceWorkloads scales workloads to zero and waits up to timeout for
+// their pods to stop. Workloads that do not exist are skipped. It returns
+// how many workloads were scaled down.
+func (bm *BackupManager) quiesceWorkloads(ctx context.Context, workloads []Workload, timeout time.Duration) (int, error) {
+	log := ctrl.LoggerFrom(ctx)
+	quiesced := 0
+	for _, w := range workloads {
+		changed, err := bm.updateWorkload(ctx, w, quiesce)
+		if err != nil {
+			return quiesced, err
+		}
+		if changed {
+			log.Info("Quiesced workload for the restore", "kind", w.Kind, "namespace", w.Namespace, "name", w.Name)
+			quiesced++
+		}
+	}
+	if quiesced == 0 {
+		return 0, nil
+	}
+
+	if timeout <= 0 {
+		timeout = defaultQuiesceTimeout
+	}
+	err := wait.PollUntilContextTimeout(ctx, quiescePollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		for _, w := range workloads {
+			gvr, _ := w.gvr()
+			obj, err := bm.DynamicClient.Resource(gvr).Namespace(w.Namespace).Get(ctx, w.Name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return false, err
+			}
+			if running, _, _ := unstructured.NestedInt64(obj.Object, "status", "replicas"); running > 0 {
+				log.V(1).Info("Waiting for quiesced workload to stop", "kind", w.Kind, "namespace", w.Namespace, "name", w.Name)
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		log.Error(err, "Quiesced workloads did not stop in time, restoring anyway")
+	}
+	return quiesced, nil
+}
+
+// resumeWorkloads scales every workload still carrying
+// quiescedReplicasAnnotation back to the recorded replicas.
+func (bm *BackupManager) resumeWorkloads(ctx context.Context, workloads []Workload) error {
+	for _, w := range workloads {
+		if _, err := bm.updateWorkload(ctx, w, resume); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resume scales obj back to the replicas recorded by quiesce and drops the
+// annotation.
+func resume(obj *unstructured.Unstructured) bool {
+	annotations := obj.GetAnnotations()
+	recorded, ok := annotations[quiescedReplicasAnnotation]
+	if !ok {
+		return false
+	}
+	delete(annotations, quiescedReplicasAnnotation)
+	obj.SetAnnotations(annotations)
+	if replicas, err := strconv.ParseInt(recorded, 10, 32); err == nil {
+		_ = unstructured.SetNestedField(obj.Object, replicas, "spec", "replicas")
+	}
+	return true
+}
+
+// updateWorkload applies mutate to w, retrying on conflicts, and reports
+// whether it was updated.
+func (bm *BackupManager) updateWorkload(ctx context.Context, w Workload, mutate func(*unstructured.Unstructured) bool) (bool, error) {
+	gvr, err := w.gvr()
+	if err != nil {
+		return false, err
+	}
+	changed := false
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		client := bm.DynamicClient.Resource(gvr).Namespace(w.Namespace)
+		obj, err := client.Get(ctx, w.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			changed = false
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if changed = mutate(obj); !changed {
+			return nil
+		}
+		_, err = client.Update(ctx, obj, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to scale %s %s/%s: %w", gvr.Resource, w.Namespace, w.Name, err)
+	}
+	return changed, nil
+}
@@ -0,0 +1,112 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestRestoreQuiescesWorkloads(t *testing.T) {
+	t.Parallel()
+
+	deployment := func(namespace, name string, replicas int64) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+			"spec":       map[string]interface{}{"replicas": replicas},
+		}}
+	}
+
+	storageDir := t.TempDir()
+	archiveName := "cluster-backup-quiesce.tar.gz"
+	file, err := os.Create(filepath.Join(storageDir, archiveName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gz)
+	writeJSONTarEntry(t, tarWriter, "namespaces/gitops/apps/v1/deployments/sync.json", deployment("gitops", "sync", 4).Object)
+	writeJSONTarEntry(t, tarWriter, "namespaces/shop/apps/v1/deployments/web.json", deployment("shop", "web", 3).Object)
+	for _, closer := range []interface{ Close() error }{tarWriter, gz, file} {
+		if err := closer.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scheme := runtime.NewScheme()
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "StatefulSet"})
+	operator := deployment("operators", "shop-operator", 2)
+	sync := deployment("gitops", "sync", 1)
+	bm := &BackupManager{DynamicClient: fake.NewSimpleDynamicClient(scheme, operator, sync)}
+	deployments := bm.DynamicClient.Resource(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"})
+	workloads := []Workload{
+		{Namespace: "operators", Name: "shop-operator"},
+		{Kind: "Deployment", Namespace: "gitops", Name: "sync"},
+		{Kind: "StatefulSet", Namespace: "operators", Name: "missing"},
+	}
+
+	quiesced, err := bm.quiesceWorkloads(context.Background(), workloads, 0)
+	if err != nil {
+		t.Fatalf("quiesceWorkloads returned error: %v", err)
+	}
+	if quiesced != 2 {
+		t.Fatalf("quiesced %d workloads, want 2", quiesced)
+	}
+	obj, err := deployments.Namespace("operators").Get(context.Background(), "shop-operator", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replicas, _, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas"); replicas != 0 {
+		t.Fatalf("quiesced operator has %d replicas, want 0", replicas)
+	}
+	if got := obj.GetAnnotations()[quiescedReplicasAnnotation]; got != "2" {
+		t.Fatalf("quiesced replicas annotation = %q, want 2", got)
+	}
+	// Quiescing twice keeps the recorded count
+	if quiesced, err := bm.quiesceWorkloads(context.Background(), workloads, 0); err != nil || quiesced != 0 {
+		t.Fatalf("second quiesceWorkloads = %d, %v, want 0, nil", quiesced, err)
+	}
+	if err := bm.resumeWorkloads(context.Background(), workloads); err != nil {
+		t.Fatalf("resumeWorkloads returned error: %v", err)
+	}
+
+	_, err = bm.RestoreBackup(context.Background(), storageDir, archiveName, RestoreOptions{
+		Quiesce:                workloads,
+		ExistingResourcePolicy: ExistingResourcePolicyUpdate,
+	})
+	if err != nil {
+		t.Fatalf("RestoreBackup returned error: %v", err)
+	}
+
+	for _, tc := range []struct {
+		namespace, name string
+		replicas        int64
+	}{
+		{"operators", "shop-operator", 2},
+		// The archived copy of a quiesced workload is resumed at its archived count
+		{"gitops", "sync", 4},
+		{"shop", "web", 3},
+	} {
+		obj, err := deployments.Namespace(tc.namespace).Get(context.Background(), tc.name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if replicas := workloadReplicas(obj.Object); replicas != tc.replicas {
+			t.Errorf("%s/%s has %d replicas after the restore, want %d", tc.namespace, tc.name, replicas, tc.replicas)
+		}
+		if _, ok := obj.GetAnnotations()[quiescedReplicasAnnotation]; ok {
+			t.Errorf("%s/%s still carries the quiesced replicas annotation", tc.namespace, tc.name)
+		}
+	}
+}
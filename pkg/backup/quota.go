@@ -0,0 +1,356 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// QuotaPolicy decides how a restore reacts when the archive does not fit in
+// the ResourceQuotas of its target namespaces.
+type QuotaPolicy string
+
+const (
+	// QuotaPolicyWarn reports quota shortfalls and restores anyway.
+	QuotaPolicyWarn QuotaPolicy = "Warn"
+	// QuotaPolicyFailFast aborts the restore before anything is applied.
+	QuotaPolicyFailFast QuotaPolicy = "FailFast"
+	// QuotaPolicyIgnore skips the quota check.
+	QuotaPolicyIgnore QuotaPolicy = "Ignore"
+)
+
+// ErrQuotaExceeded is returned by fail-fast restores whose archive does not
+// fit in the quotas of its target namespaces.
+var ErrQuotaExceeded = errors.New("restore would exceed namespace quota")
+
+// storageClassQuotaSuffix qualifies per-storage-class quota resource names.
+const storageClassQuotaSuffix = ".storageclass.storage.k8s.io/"
+
+var resourceQuotaGVR = schema.GroupVersionResource{Version: "v1", Resource: "resourcequotas"}
+
+// QuotaViolation describes a quota the archive would exceed in one namespace.
+type QuotaViolation struct {
+	Namespace string
+	Quota     string
+	Resource  corev1.ResourceName
+	// Required is what the restore would add; Available is what the quota
+	// has left.
+	Required  resource.Quantity
+	Available resource.Quantity
+}
+
+func (v QuotaViolation) String() string {
+	return fmt.Sprintf("namespace %s: quota %s has %s %s available, restore needs %s",
+		v.Namespace, v.Quota, v.Available.String(), v.Resource, v.Required.String())
+}
+
+// namespaceQuota is a quota's hard limits and current usage.
+type namespaceQuota struct {
+	name string
+	hard corev1.ResourceList
+	used corev1.ResourceList
+}
+
+// checkQuotas compares what restoring resources would add to each namespace
+// against the namespace's ResourceQuotas. Quotas that are only in the archive
+// are checked with no usage. Resources that already exist are updated in
+// place and add nothing. Scoped quotas are not evaluated.
+func (bm *BackupManager) checkQuotas(ctx context.Context, resources []archivedResource) ([]QuotaViolation, error) {
+	byNamespace := map[string][]archivedResource{}
+	archivedQuotas := map[string][]namespaceQuota{}
+	for _, res := range resources {
+		if res.err != nil || res.namespace == "" {
+			continue
+		}
+		byNamespace[res.namespace] = append(byNamespace[res.namespace], res)
+		if res.gvr.GroupResource() == resourceQuotaGVR.GroupResource() {
+			quota := &corev1.ResourceQuota{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(res.object, quota); err != nil {
+				return nil, fmt.Errorf("failed to decode ResourceQuota %s/%s: %w", res.namespace, res.name, err)
+			}
+			if unscoped(quota) {
+				archivedQuotas[res.namespace] = append(archivedQuotas[res.namespace],
+					namespaceQuota{name: quota.Name, hard: quota.Spec.Hard})
+			}
+		}
+	}
+
+	namespaces := make([]string, 0, len(byNamespace))
+	for namespace := range byNamespace {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	var violations []QuotaViolation
+	for _, namespace := range namespaces {
+		quotas, err := bm.namespaceQuotas(ctx, namespace, archivedQuotas[namespace])
+		if err != nil {
+			return nil, err
+		}
+		if len(quotas) == 0 {
+			continue
+		}
+
+		demand, err := bm.restoreDemand(ctx, namespace, byNamespace[namespace], quotas)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, evaluateQuotas(namespace, quotas, demand)...)
+	}
+	return violations, nil
+}
+
+// namespaceQuotas returns the unscoped quotas of namespace, adding archived
+// quotas that do not exist in the cluster yet.
+func (bm *BackupManager) namespaceQuotas(ctx context.Context, namespace string, archived []namespaceQuota) ([]namespaceQuota, error) {
+	list, err := bm.DynamicClient.Resource(resourceQuotaGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ResourceQuotas in namespace %s: %w", namespace, err)
+	}
+
+	var quotas []namespaceQuota
+	live := map[string]struct{}{}
+	for i := range list.Items {
+		quota := &corev1.ResourceQuota{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(list.Items[i].Object, quota); err != nil {
+			return nil, fmt.Errorf("failed to decode ResourceQuota %s/%s: %w", namespace, list.Items[i].GetName(), err)
+		}
+		live[quota.Name] = struct{}{}
+		if unscoped(quota) {
+			quotas = append(quotas, namespaceQuota{name: quota.Name, hard: quota.Spec.Hard, used: quota.Status.Used})
+		}
+	}
+	for _, quota := range archived {
+		if _, ok := live[quota.name]; !ok {
+			quotas = append(quotas, quota)
+		}
+	}
+	return quotas, nil
+}
+
+// restoreDemand sums the quota usage of the resources that restoring would
+// create in namespace, considering only resource names some quota limits.
+func (bm *BackupManager) restoreDemand(ctx context.Context, namespace string, resources []archivedResource, quotas []namespaceQuota) (corev1.ResourceList, error) {
+	limited := map[corev1.ResourceName]struct{}{}
+	for _, quota := range quotas {
+		for name := range quota.hard {
+			limited[name] = struct{}{}
+		}
+	}
+
+	demand := corev1.ResourceList{}
+	existing := map[schema.GroupVersionResource]map[string]struct{}{}
+	for _, res := range resources {
+		usage, err := quotaUsage(res)
+		if err != nil {
+			return nil, err
+		}
+		relevant := false
+		for name := range usage {
+			if _, ok := limited[name]; ok {
+				relevant = true
+				break
+			}
+		}
+		if !relevant {
+			continue
+		}
+
+		names, ok := existing[res.gvr]
+		if !ok {
+			if names, err = bm.existingNames(ctx, namespace, res.gvr); err != nil {
+				return nil, err
+			}
+			existing[res.gvr] = names
+		}
+		if _, found := names[res.name]; found {
+			continue
+		}
+
+		for name, quantity := range usage {
+			total := demand[name]
+			total.Add(quantity)
+			demand[name] = total
+		}
+	}
+	return demand, nil
+}
+
+// existingNames lists the names of gvr objects already in namespace.
+func (bm *BackupManager) existingNames(ctx context.Context, namespace string, gvr schema.GroupVersionResource) (map[string]struct{}, error) {
+	list, err := bm.DynamicClient.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s in namespace %s: %w", gvr.GroupResource(), namespace, err)
+	}
+	names := make(map[string]struct{}, len(list.Items))
+	for _, item := range list.Items {
+		names[item.GetName()] = struct{}{}
+	}
+	return names, nil
+}
+
+// evaluateQuotas reports every quota whose remaining capacity is below demand.
+func evaluateQuotas(namespace string, quotas []namespaceQuota, demand corev1.ResourceList) []QuotaViolation {
+	var violations []QuotaViolation
+	for _, quota := range quotas {
+		names := make([]string, 0, len(quota.hard))
+		for name := range quota.hard {
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			resourceName := corev1.ResourceName(name)
+			required, ok := demand[resourceName]
+			if !ok || required.IsZero() {
+				continue
+			}
+			available := quota.hard[resourceName].DeepCopy()
+			available.Sub(quota.used[resourceName])
+			if available.Sign() < 0 {
+				available = resource.Quantity{Format: available.Format}
+			}
+			if required.Cmp(available) > 0 {
+				violations = append(violations, QuotaViolation{
+					Namespace: namespace,
+					Quota:     quota.name,
+					Resource:  resourceName,
+					Required:  required,
+					Available: available,
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// quotaUsage returns what creating res charges against a namespace quota,
+// following the resource names of the Kubernetes quota evaluators.
+func quotaUsage(res archivedResource) (corev1.ResourceList, error) {
+	one := resource.MustParse("1")
+	countName := "count/" + res.gvr.Resource
+	if res.gvr.Group != "" {
+		countName += "." + res.gvr.Group
+	}
+	usage := corev1.ResourceList{corev1.ResourceName(countName): one}
+	if res.gvr.Group != "" {
+		return usage, nil
+	}
+
+	switch res.gvr.Resource {
+	case "configmaps", "secrets", "replicationcontrollers", "resourcequotas":
+		usage[corev1.ResourceName(res.gvr.Resource)] = one
+	case "pods":
+		pod := &corev1.Pod{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(res.object, pod); err != nil {
+			return nil, fmt.Errorf("failed to decode pod %s/%s: %w", res.namespace, res.name, err)
+		}
+		usage[corev1.ResourcePods] = one
+		addPodUsage(usage, pod)
+	case "services":
+		svc := &corev1.Service{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(res.object, svc); err != nil {
+			return nil, fmt.Errorf("failed to decode service %s/%s: %w", res.namespace, res.name, err)
+		}
+		usage[corev1.ResourceServices] = one
+		switch svc.Spec.Type {
+		case corev1.ServiceTypeLoadBalancer:
+			usage[corev1.ResourceServicesLoadBalancers] = one
+			usage[corev1.ResourceServicesNodePorts] = *resource.NewQuantity(int64(len(svc.Spec.Ports)), resource.DecimalSI)
+		case corev1.ServiceTypeNodePort:
+			usage[corev1.ResourceServicesNodePorts] = *resource.NewQuantity(int64(len(svc.Spec.Ports)), resource.DecimalSI)
+		}
+	case "persistentvolumeclaims":
+		pvc := &corev1.PersistentVolumeClaim{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(res.object, pvc); err != nil {
+			return nil, fmt.Errorf("failed to decode persistentvolumeclaim %s/%s: %w", res.namespace, res.name, err)
+		}
+		usage[corev1.ResourcePersistentVolumeClaims] = one
+		storage := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		usage[corev1.ResourceRequestsStorage] = storage
+		if class := pvc.Spec.StorageClassName; class != nil && *class != "" {
+			prefix := *class + storageClassQuotaSuffix
+			usage[corev1.ResourceName(prefix+string(corev1.ResourcePersistentVolumeClaims))] = one
+			usage[corev1.ResourceName(prefix+string(corev1.ResourceRequestsStorage))] = storage
+		}
+	}
+	return usage, nil
+}
+
+// addPodUsage charges the effective requests and limits of pod: the larger of
+// the sum over its containers and any single init container, plus overhead.
+func addPodUsage(usage corev1.ResourceList, pod *corev1.Pod) {
+	effective := func(get func(corev1.ResourceRequirements) corev1.ResourceList) corev1.ResourceList {
+		total := corev1.ResourceList{}
+		for _, container := range pod.Spec.Containers {
+			for name, quantity := range get(container.Resources) {
+				sum := total[name]
+				sum.Add(quantity)
+				total[name] = sum
+			}
+		}
+		for _, container := range pod.Spec.InitContainers {
+			for name, quantity := range get(container.Resources) {
+				if current, ok := total[name]; !ok || quantity.Cmp(current) > 0 {
+					total[name] = quantity
+				}
+			}
+		}
+		for name, quantity := range pod.Spec.Overhead {
+			sum := total[name]
+			sum.Add(quantity)
+			total[name] = sum
+		}
+		return total
+	}
+
+	requests := effective(func(r corev1.ResourceRequirements) corev1.ResourceList { return r.Requests })
+	limits := effective(func(r corev1.ResourceRequirements) corev1.ResourceList { return r.Limits })
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage} {
+		if quantity, ok := requests[name]; ok {
+			usage[name] = quantity
+			usage[corev1.ResourceName("requests."+string(name))] = quantity
+		}
+		if quantity, ok := limits[name]; ok {
+			usage[corev1.ResourceName("limits."+string(name))] = quantity
+		}
+	}
+}
+
+// unscoped reports whether quota applies to every object in its namespace.
+func unscoped(quota *corev1.ResourceQuota) bool {
+	return len(quota.Spec.Scopes) == 0 && quota.Spec.ScopeSelector == nil
+}
+
+// quotaViolationsError joins violations into a single ErrQuotaExceeded error.
+func quotaViolationsError(violations []QuotaViolation) error {
+	messages := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		messages = append(messages, violation.String())
+	}
+	return fmt.Errorf("%w: %s", ErrQuotaExceeded, strings.Join(messages, "; "))
+}
@@ -0,0 +1,149 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestCheckQuotas(t *testing.T) {
+	t.Parallel()
+
+	liveQuota := quotaObject("team", "compute",
+		map[string]interface{}{"pods": "2", "requests.cpu": "1", "requests.storage": "5Gi"},
+		map[string]interface{}{"pods": "1", "requests.cpu": "500m", "requests.storage": "0"})
+	livePod := podObject("team", "existing", "1", "")
+	bm := &BackupManager{DynamicClient: newQuotaTestClient(liveQuota, livePod)}
+
+	resources := []archivedResource{
+		archived("", "v1", "pods", livePod),
+		archived("", "v1", "pods", podObject("team", "web", "500m", "1")),
+		archived("", "v1", "persistentvolumeclaims", &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1", "kind": "PersistentVolumeClaim",
+			"metadata": map[string]interface{}{"name": "data", "namespace": "team"},
+			"spec": map[string]interface{}{
+				"storageClassName": "fast",
+				"resources":        map[string]interface{}{"requests": map[string]interface{}{"storage": "10Gi"}},
+			},
+		}}),
+		// fresh does not exist yet, its quota only comes from the archive
+		archived("", "v1", "resourcequotas", quotaObject("fresh", "objects",
+			map[string]interface{}{"configmaps": "1"}, nil)),
+		archived("", "v1", "configmaps", configMapObject("fresh", "one")),
+		archived("", "v1", "configmaps", configMapObject("fresh", "two")),
+	}
+
+	violations, err := bm.checkQuotas(context.Background(), resources)
+	if err != nil {
+		t.Fatalf("checkQuotas returned error: %v", err)
+	}
+
+	got := map[string]QuotaViolation{}
+	for _, violation := range violations {
+		got[violation.Namespace+"/"+string(violation.Resource)] = violation
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 violations, got %v", violations)
+	}
+	// The init container needs a whole CPU, more than the containers combined
+	if v, ok := got["team/requests.cpu"]; !ok || v.Required.String() != "1" || v.Available.String() != "500m" {
+		t.Fatalf("expected a requests.cpu violation of 1 against 500m, got %+v", v)
+	}
+	if v, ok := got["team/requests.storage"]; !ok || v.Required.String() != "10Gi" {
+		t.Fatalf("expected a requests.storage violation of 10Gi, got %+v", v)
+	}
+	if v, ok := got["fresh/configmaps"]; !ok || v.Quota != "objects" || v.Required.String() != "2" {
+		t.Fatalf("expected the archived quota to limit configmaps, got %+v", v)
+	}
+}
+
+func TestRestoreBackupQuotaPolicy(t *testing.T) {
+	t.Parallel()
+
+	storageDir := t.TempDir()
+	archiveName := "cluster-backup-restore.tar.gz"
+	writeRestoreArchive(t, filepath.Join(storageDir, archiveName))
+	full := quotaObject("restore-ns", "objects",
+		map[string]interface{}{"configmaps": "1"}, map[string]interface{}{"configmaps": "1"})
+
+	bm := &BackupManager{DynamicClient: newQuotaTestClient(full)}
+	_, err := bm.RestoreBackup(context.Background(), storageDir, archiveName, RestoreOptions{QuotaPolicy: QuotaPolicyFailFast})
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("expected ErrQuotaExceeded, got %v", err)
+	}
+	namespaceGVR := schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+	if _, err := bm.DynamicClient.Resource(namespaceGVR).Get(context.Background(), "restore-ns", metav1.GetOptions{}); err == nil {
+		t.Fatalf("expected nothing to be applied when the quota check fails")
+	}
+
+	result, err := bm.RestoreBackup(context.Background(), storageDir, archiveName, RestoreOptions{QuotaPolicy: QuotaPolicyWarn})
+	if err != nil {
+		t.Fatalf("RestoreBackup returned error: %v", err)
+	}
+	if len(result.QuotaViolations) != 1 || result.ResourcesApplied != 2 {
+		t.Fatalf("expected one quota warning and a full restore, got %+v", result)
+	}
+}
+
+func newQuotaTestClient(objects ...runtime.Object) *fake.FakeDynamicClient {
+	scheme := runtime.NewScheme()
+	for _, kind := range []string{"Namespace", "ConfigMap", "Pod", "PersistentVolumeClaim", "ResourceQuota"} {
+		registerUnstructuredType(scheme, schema.GroupVersionKind{Version: "v1", Kind: kind})
+	}
+	return fake.NewSimpleDynamicClient(scheme, objects...)
+}
+
+func archived(group, version, resource string, obj *unstructured.Unstructured) archivedResource {
+	return archivedResource{
+		gvr:       schema.GroupVersionResource{Group: group, Version: version, Resource: resource},
+		namespace: obj.GetNamespace(),
+		name:      obj.GetName(),
+		object:    obj.DeepCopy().Object,
+	}
+}
+
+func quotaObject(namespace, name string, hard, used map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1", "kind": "ResourceQuota",
+		"metadata": map[string]interface{}{"name": name, "namespace": namespace},
+		"spec":     map[string]interface{}{"hard": hard},
+	}}
+	if used != nil {
+		obj.Object["status"] = map[string]interface{}{"hard": hard, "used": used}
+	}
+	return obj
+}
+
+func podObject(namespace, name, cpu, initCPU string) *unstructured.Unstructured {
+	spec := map[string]interface{}{
+		"containers": []interface{}{map[string]interface{}{
+			"name":      "app",
+			"resources": map[string]interface{}{"requests": map[string]interface{}{"cpu": cpu}},
+		}},
+	}
+	if initCPU != "" {
+		spec["initContainers"] = []interface{}{map[string]interface{}{
+			"name":      "init",
+			"resources": map[string]interface{}{"requests": map[string]interface{}{"cpu": initCPU}},
+		}}
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1", "kind": "Pod",
+		"metadata": map[string]interface{}{"name": name, "namespace": namespace},
+		"spec":     spec,
+	}}
+}
+
+func configMapObject(namespace, name string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1", "kind": "ConfigMap",
+		"metadata": map[string]interface{}{"name": name, "namespace": namespace},
+	}}
+}
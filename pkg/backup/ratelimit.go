@@ -0,0 +1,102 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"sync/atomic"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// adjustableRateLimiter is a client-side rate limiter whose limits can be
+// changed while clients using it are running. Requests already waiting keep
+// the limiter they started with.
+type adjustableRateLimiter struct {
+	current atomic.Pointer[tokenBucket]
+	// defaultQPS and defaultBurst are restored by set(0, 0).
+	defaultQPS   float32
+	defaultBurst int
+}
+
+type tokenBucket struct {
+	flowcontrol.RateLimiter
+	burst int
+}
+
+var _ flowcontrol.RateLimiter = &adjustableRateLimiter{}
+
+// newAdjustableRateLimiter starts with the limits of config, falling back to
+// the client-go defaults when it does not set them.
+func newAdjustableRateLimiter(config *rest.Config) *adjustableRateLimiter {
+	qps, burst := config.QPS, config.Burst
+	if qps <= 0 {
+		qps = rest.DefaultQPS
+	}
+	if burst <= 0 {
+		burst = rest.DefaultBurst
+	}
+	l := &adjustableRateLimiter{defaultQPS: qps, defaultBurst: burst}
+	l.set(0, 0)
+	return l
+}
+
+// set switches to new limits. Zero values select the initial limits. It
+// reports whether the limits changed.
+func (l *adjustableRateLimiter) set(qps float32, burst int) bool {
+	if qps <= 0 {
+		qps = l.defaultQPS
+	}
+	if burst <= 0 {
+		burst = l.defaultBurst
+	}
+	if current := l.current.Load(); current != nil && current.QPS() == qps && current.burst == burst {
+		return false
+	}
+	previous := l.current.Swap(&tokenBucket{
+		RateLimiter: flowcontrol.NewTokenBucketRateLimiter(qps, burst),
+		burst:       burst,
+	})
+	if previous != nil {
+		previous.Stop()
+	}
+	return true
+}
+
+func (l *adjustableRateLimiter) TryAccept() bool { return l.current.Load().TryAccept() }
+
+func (l *adjustableRateLimiter) Accept() { l.current.Load().Accept() }
+
+func (l *adjustableRateLimiter) Stop() { l.current.Load().Stop() }
+
+func (l *adjustableRateLimiter) QPS() float32 { return l.current.Load().QPS() }
+
+func (l *adjustableRateLimiter) Wait(ctx context.Context) error {
+	return l.current.Load().Wait(ctx)
+}
+
+// SetClientRateLimits changes the QPS and burst of the clients used to
+// collect and restore resources without recreating them. Zero values restore
+// the limits the manager was created with. It reports whether the limits
+// changed, and does nothing for managers built without NewBackupManager.
+func (bm *BackupManager) SetClientRateLimits(qps float32, burst int) bool {
+	if bm.rateLimiter == nil {
+		return false
+	}
+	return bm.rateLimiter.set(qps, burst)
+}
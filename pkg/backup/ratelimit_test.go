@@ -0,0 +1,58 @@
+package backup
+
+import (
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestAdjustableRateLimiter(t *testing.T) {
+	t.Parallel()
+
+	limiter := newAdjustableRateLimiter(&rest.Config{})
+	if got := limiter.QPS(); got != rest.DefaultQPS {
+		t.Fatalf("initial QPS = %v, want %v", got, rest.DefaultQPS)
+	}
+
+	if !limiter.set(50, 100) {
+		t.Fatal("set(50, 100) reported no change")
+	}
+	if limiter.set(50, 100) {
+		t.Fatal("repeating set(50, 100) reported a change")
+	}
+	if got := limiter.QPS(); got != 50 {
+		t.Fatalf("QPS = %v, want 50", got)
+	}
+	if !limiter.TryAccept() {
+		t.Fatal("TryAccept() = false with a full bucket")
+	}
+
+	if !limiter.set(0, 0) {
+		t.Fatal("set(0, 0) reported no change")
+	}
+	if got := limiter.QPS(); got != rest.DefaultQPS {
+		t.Fatalf("QPS after reset = %v, want %v", got, rest.DefaultQPS)
+	}
+}
+
+func TestNewBackupManagerUsesAdjustableRateLimiter(t *testing.T) {
+	t.Parallel()
+
+	bm, err := NewBackupManager(&rest.Config{Host: "https://127.0.0.1:6443", QPS: 10, Burst: 20})
+	if err != nil {
+		t.Fatalf("NewBackupManager: %v", err)
+	}
+	if got := bm.rateLimiter.QPS(); got != 10 {
+		t.Fatalf("QPS = %v, want 10", got)
+	}
+	if !bm.SetClientRateLimits(25, 50) {
+		t.Fatal("SetClientRateLimits(25, 50) reported no change")
+	}
+	if got := bm.rateLimiter.QPS(); got != 25 {
+		t.Fatalf("QPS = %v, want 25", got)
+	}
+
+	if (&BackupManager{}).SetClientRateLimits(25, 50) {
+		t.Fatal("SetClientRateLimits on a manager without a limiter reported a change")
+	}
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var namespacesResource = schema.GroupResource{Resource: "namespaces"}
+
+// MinimalClusterRole returns the least-privilege ClusterRole that lets the
+// operator take a backup with opts in the current cluster. Only the reads of
+// the backup itself are covered; the operator's own resources, events and
+// restores still need the rules of its manager role. Resource types are
+// resolved through discovery, so CRDs installed later are not included.
+func (bm *BackupManager) MinimalClusterRole(ctx context.Context, name string, opts BackupOptions) (*rbacv1.ClusterRole, error) {
+	rules := roleRules{}
+
+	switch {
+	case opts.Application != nil:
+		if err := bm.allowApplication(ctx, rules, *opts.Application); err != nil {
+			return nil, err
+		}
+	case len(opts.Items) > 0:
+		if err := bm.allowItems(ctx, rules, opts.Items); err != nil {
+			return nil, err
+		}
+	default:
+		for _, target := range bm.discoverResources(ctx, opts) {
+			rules.allow(target.gvr.GroupResource(), "get", "list")
+		}
+		if len(opts.IncludeNamespaces) == 0 {
+			rules.allow(namespacesResource, "list")
+		}
+	}
+
+	// The archive manifest records the cluster it was taken from
+	rules.allowNamed(namespacesResource, kubeSystemNamespace)
+	rules.allow(nodesResource.GroupResource(), "list")
+
+	if opts.IncludeReferencedResources && opts.Application == nil {
+		rules.allowPodTemplateReferences()
+	}
+	if opts.SkipReissuableCertificateSecrets {
+		for _, gvr := range []schema.GroupVersionResource{certificateGVR, issuerGVR, clusterIssuerGVR} {
+			rules.allow(gvr.GroupResource(), "list")
+		}
+	}
+
+	return &rbacv1.ClusterRole{
+		TypeMeta:   metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Rules:      rules.policyRules(),
+	}, nil
+}
+
+// allowItems grants get on each named item.
+func (bm *BackupManager) allowItems(ctx context.Context, rules roleRules, items []BackupItem) error {
+	var apiResourceLists []*metav1.APIResourceList
+	resolved := false
+	for _, item := range items {
+		gvr := item.Resource
+		if gvr.Resource == "" {
+			if !resolved {
+				apiResourceLists = bm.apiResourceLists(ctx)
+				resolved = true
+			}
+			var err error
+			if gvr, err = resolveItemResource(apiResourceLists, item.Kind); err != nil {
+				return fmt.Errorf("failed to resolve item %s: %w", item, err)
+			}
+		}
+		rules.allowNamed(gvr.GroupResource(), item.Name)
+	}
+	return nil
+}
+
+// allowApplication grants get on the root, list on every type its owned
+// objects may have, and get on the objects its pod templates reference.
+func (bm *BackupManager) allowApplication(ctx context.Context, rules roleRules, root BackupItem) error {
+	gvr := root.Resource
+	if gvr.Resource == "" {
+		var err error
+		if gvr, err = resolveItemResource(bm.apiResourceLists(ctx), root.Kind); err != nil {
+			return fmt.Errorf("failed to resolve application %s: %w", root, err)
+		}
+	}
+	rules.allowNamed(gvr.GroupResource(), root.Name)
+
+	namespaced := root.Namespace != ""
+	for _, target := range bm.discoverResources(ctx, BackupOptions{IncludeClusterResources: !namespaced}) {
+		if target.namespaced == namespaced {
+			rules.allow(target.gvr.GroupResource(), "list")
+		}
+	}
+	rules.allowPodTemplateReferences()
+	return nil
+}
+
+// apiResourceLists returns the discovered resource types, logging partial
+// discovery failures like the backup itself does.
+func (bm *BackupManager) apiResourceLists(ctx context.Context) []*metav1.APIResourceList {
+	apiResourceLists, err := bm.DiscoveryClient.ServerPreferredResources()
+	if err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "Warning: Error discovering some API resources (continuing anyway)")
+	}
+	return apiResourceLists
+}
+
+// roleRules collects the verbs granted on each resource, and the names get
+// is limited to for resources that are only read by name.
+type roleRules map[schema.GroupResource]*resourceGrant
+
+type resourceGrant struct {
+	verbs map[string]struct{}
+	names map[string]struct{}
+}
+
+func (r roleRules) grant(gr schema.GroupResource) *resourceGrant {
+	g, ok := r[gr]
+	if !ok {
+		g = &resourceGrant{verbs: map[string]struct{}{}, names: map[string]struct{}{}}
+		r[gr] = g
+	}
+	return g
+}
+
+func (r roleRules) allow(gr schema.GroupResource, verbs ...string) {
+	g := r.grant(gr)
+	for _, verb := range verbs {
+		g.verbs[verb] = struct{}{}
+	}
+}
+
+func (r roleRules) allowNamed(gr schema.GroupResource, name string) {
+	r.grant(gr).names[name] = struct{}{}
+}
+
+func (r roleRules) allowPodTemplateReferences() {
+	for _, gvr := range []schema.GroupVersionResource{configMapsResource, secretsResource, pvcsResource} {
+		r.allow(gvr.GroupResource(), "get")
+	}
+}
+
+// policyRules merges resources of the same group and verbs into one rule,
+// sorted by group so the output is stable.
+func (r roleRules) policyRules() []rbacv1.PolicyRule {
+	merged := map[string]*rbacv1.PolicyRule{}
+	var keys []string
+	var named []rbacv1.PolicyRule
+	for gr, g := range r {
+		if len(g.names) > 0 {
+			if _, ok := g.verbs["get"]; !ok {
+				named = append(named, rbacv1.PolicyRule{
+					APIGroups:     []string{gr.Group},
+					Resources:     []string{gr.Resource},
+					ResourceNames: sortedKeys(g.names),
+					Verbs:         []string{"get"},
+				})
+			}
+		}
+		if len(g.verbs) == 0 {
+			continue
+		}
+		verbs := sortedKeys(g.verbs)
+		key := gr.Group + "\x00" + strings.Join(verbs, ",")
+		rule, ok := merged[key]
+		if !ok {
+			rule = &rbacv1.PolicyRule{APIGroups: []string{gr.Group}, Verbs: verbs}
+			merged[key] = rule
+			keys = append(keys, key)
+		}
+		rule.Resources = append(rule.Resources, gr.Resource)
+	}
+
+	sort.Strings(keys)
+	rules := make([]rbacv1.PolicyRule, 0, len(keys)+len(named))
+	for _, key := range keys {
+		rule := merged[key]
+		sort.Strings(rule.Resources)
+		rules = append(rules, *rule)
+	}
+	sort.Slice(named, func(i, j int) bool {
+		if named[i].APIGroups[0] != named[j].APIGroups[0] {
+			return named[i].APIGroups[0] < named[j].APIGroups[0]
+		}
+		return named[i].Resources[0] < named[j].Resources[0]
+	})
+	return append(rules, named...)
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,138 @@
+package backup
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newRBACTestManager() *BackupManager {
+	return &BackupManager{
+		DiscoveryClient: preferredResources{lists: []*metav1.APIResourceList{
+			{GroupVersion: "v1", APIResources: []metav1.APIResource{
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"get", "list"}},
+				{Name: "secrets", Kind: "Secret", Namespaced: true, Verbs: []string{"get", "list"}},
+				{Name: "nodes", Kind: "Node", Verbs: []string{"get", "list"}},
+			}},
+			{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{
+				{Name: "deployments", Kind: "Deployment", Namespaced: true, Verbs: []string{"get", "list"}},
+				{Name: "replicasets", Kind: "ReplicaSet", Namespaced: true, Verbs: []string{"get", "list"}},
+			}},
+		}},
+	}
+}
+
+func TestMinimalClusterRole(t *testing.T) {
+	t.Parallel()
+
+	bm := newRBACTestManager()
+	role, err := bm.MinimalClusterRole(context.Background(), "backup-reader", BackupOptions{
+		IncludeNamespaces: []string{"apps"},
+		ResourceTypes:     []string{"Deployment", "ConfigMap"},
+	})
+	if err != nil {
+		t.Fatalf("MinimalClusterRole: %v", err)
+	}
+	if role.Name != "backup-reader" || role.Kind != "ClusterRole" {
+		t.Fatalf("role = %s %q, want ClusterRole %q", role.Kind, role.Name, "backup-reader")
+	}
+	want := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get", "list"}},
+		{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: []string{"list"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"get", "list"}},
+		{APIGroups: []string{""}, Resources: []string{"namespaces"}, ResourceNames: []string{"kube-system"}, Verbs: []string{"get"}},
+	}
+	if !reflect.DeepEqual(role.Rules, want) {
+		t.Fatalf("rules = %+v, want %+v", role.Rules, want)
+	}
+
+	// Scanning every namespace also lists the namespaces, and cluster
+	// resources are only read when requested
+	role, err = bm.MinimalClusterRole(context.Background(), "backup-reader", BackupOptions{IncludeClusterResources: true})
+	if err != nil {
+		t.Fatalf("MinimalClusterRole: %v", err)
+	}
+	want = []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"configmaps", "nodes", "secrets"}, Verbs: []string{"get", "list"}},
+		{APIGroups: []string{""}, Resources: []string{"namespaces"}, Verbs: []string{"list"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments", "replicasets"}, Verbs: []string{"get", "list"}},
+		{APIGroups: []string{""}, Resources: []string{"namespaces"}, ResourceNames: []string{"kube-system"}, Verbs: []string{"get"}},
+	}
+	if !reflect.DeepEqual(role.Rules, want) {
+		t.Fatalf("rules = %+v, want %+v", role.Rules, want)
+	}
+}
+
+func TestMinimalClusterRoleItems(t *testing.T) {
+	t.Parallel()
+
+	bm := newRBACTestManager()
+	role, err := bm.MinimalClusterRole(context.Background(), "backup-reader", BackupOptions{
+		Items: []BackupItem{
+			{Kind: "deployment", Namespace: "apps", Name: "web"},
+			{Kind: "ConfigMap", Namespace: "apps", Name: "settings"},
+			{Kind: "ConfigMap", Namespace: "apps", Name: "flags"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("MinimalClusterRole: %v", err)
+	}
+	want := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: []string{"list"}},
+		{APIGroups: []string{""}, Resources: []string{"configmaps"}, ResourceNames: []string{"flags", "settings"}, Verbs: []string{"get"}},
+		{APIGroups: []string{""}, Resources: []string{"namespaces"}, ResourceNames: []string{"kube-system"}, Verbs: []string{"get"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, ResourceNames: []string{"web"}, Verbs: []string{"get"}},
+	}
+	if !reflect.DeepEqual(role.Rules, want) {
+		t.Fatalf("rules = %+v, want %+v", role.Rules, want)
+	}
+
+	// Referenced objects are read by names only known at backup time
+	role, err = bm.MinimalClusterRole(context.Background(), "backup-reader", BackupOptions{
+		Items:                      []BackupItem{{Kind: "ConfigMap", Namespace: "apps", Name: "settings"}},
+		IncludeReferencedResources: true,
+	})
+	if err != nil {
+		t.Fatalf("MinimalClusterRole: %v", err)
+	}
+	want = []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"configmaps", "persistentvolumeclaims", "secrets"}, Verbs: []string{"get"}},
+		{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: []string{"list"}},
+		{APIGroups: []string{""}, Resources: []string{"namespaces"}, ResourceNames: []string{"kube-system"}, Verbs: []string{"get"}},
+	}
+	if !reflect.DeepEqual(role.Rules, want) {
+		t.Fatalf("rules = %+v, want %+v", role.Rules, want)
+	}
+
+	if _, err := bm.MinimalClusterRole(context.Background(), "backup-reader", BackupOptions{
+		Items: []BackupItem{{Kind: "Widget", Namespace: "apps", Name: "w"}},
+	}); err == nil {
+		t.Fatal("MinimalClusterRole succeeded for an unknown kind")
+	}
+}
+
+func TestMinimalClusterRoleApplication(t *testing.T) {
+	t.Parallel()
+
+	bm := newRBACTestManager()
+	role, err := bm.MinimalClusterRole(context.Background(), "backup-reader", BackupOptions{
+		Application: &BackupItem{Kind: "Deployment", Namespace: "apps", Name: "web"},
+	})
+	if err != nil {
+		t.Fatalf("MinimalClusterRole: %v", err)
+	}
+	want := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"persistentvolumeclaims"}, Verbs: []string{"get"}},
+		{APIGroups: []string{""}, Resources: []string{"configmaps", "secrets"}, Verbs: []string{"get", "list"}},
+		{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: []string{"list"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments", "replicasets"}, Verbs: []string{"list"}},
+		{APIGroups: []string{""}, Resources: []string{"namespaces"}, ResourceNames: []string{"kube-system"}, Verbs: []string{"get"}},
+		{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, ResourceNames: []string{"web"}, Verbs: []string{"get"}},
+	}
+	if !reflect.DeepEqual(role.Rules, want) {
+		t.Fatalf("rules = %+v, want %+v", role.Rules, want)
+	}
+}
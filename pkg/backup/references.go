@@ -0,0 +1,86 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// namespacedReference is an objectReference in a given namespace.
+type namespacedReference struct {
+	namespace string
+	objectReference
+}
+
+// collectReferences writes the objects recorded in archive.references that
+// are not in the archive yet, returning how many were added. Missing objects
+// are skipped and failed reads logged, like failed List calls.
+func (bm *BackupManager) collectReferences(ctx context.Context, archive *archiveWriter) (int, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	refs := make([]namespacedReference, 0, len(archive.references))
+	for ref := range archive.references {
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		a, b := refs[i], refs[j]
+		if a.namespace != b.namespace {
+			return a.namespace < b.namespace
+		}
+		if a.gvr.Resource != b.gvr.Resource {
+			return a.gvr.Resource < b.gvr.Resource
+		}
+		return a.name < b.name
+	})
+	// Objects written from here on must not add references
+	archive.references = nil
+
+	count := 0
+	for _, ref := range refs {
+		name := path.Join(archiveDir(ref.gvr, ref.namespace), fmt.Sprintf("%s.json", ref.name))
+		if _, ok := archive.digests[name]; ok {
+			continue
+		}
+		obj, err := bm.getObject(ctx, ref.gvr, ref.namespace, ref.name)
+		if apierrors.IsNotFound(err) {
+			log.V(1).Info("Skipping missing referenced object", "resource", ref.gvr.Resource, "namespace", ref.namespace, "name", ref.name)
+			continue
+		}
+		if err != nil {
+			log.Error(err, "Failed to backup referenced object", "resource", ref.gvr.Resource, "namespace", ref.namespace, "name", ref.name)
+			continue
+		}
+		if archive.exclude != nil && archive.exclude(obj.Object) {
+			continue
+		}
+		cleanResource(obj)
+		if err := archive.writeObject(name, obj.Object); err != nil {
+			return count, fmt.Errorf("failed to write referenced object %q: %w", name, err)
+		}
+		count++
+	}
+	if count > 0 {
+		log.Info("Included referenced objects", "count", count)
+	}
+	return count, nil
+}
@@ -0,0 +1,70 @@
+package backup
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestCollectReferences(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	dynamicClient := fake.NewSimpleDynamicClient(scheme,
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "prod"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "pull", Namespace: "prod"}},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "prod"},
+			Type:       corev1.SecretTypeServiceAccountToken,
+		},
+	)
+	bm := &BackupManager{DynamicClient: dynamicClient}
+
+	archive := newArchiveWriter(io.Discard)
+	archive.exclude = isClusterGenerated
+	archive.references = map[namespacedReference]struct{}{}
+	deployment := map[string]interface{}{
+		"apiVersion": "apps/v1", "kind": "Deployment",
+		"metadata": map[string]interface{}{"name": "api", "namespace": "prod"},
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+			"imagePullSecrets": []interface{}{map[string]interface{}{"name": "pull"}},
+			"volumes": []interface{}{
+				map[string]interface{}{"name": "a", "configMap": map[string]interface{}{"name": "settings"}},
+				map[string]interface{}{"name": "b", "secret": map[string]interface{}{"secretName": "token"}},
+				map[string]interface{}{"name": "c", "persistentVolumeClaim": map[string]interface{}{"claimName": "missing"}},
+			},
+		}}},
+	}
+	if err := archive.writeObject("namespaces/prod/apps/v1/deployments/api.json", deployment); err != nil {
+		t.Fatal(err)
+	}
+	// Already selected, so not written twice
+	if err := archive.writeObject("namespaces/prod/v1/configmaps/settings.json", map[string]interface{}{
+		"apiVersion": "v1", "kind": "ConfigMap",
+		"metadata": map[string]interface{}{"name": "settings", "namespace": "prod"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := bm.collectReferences(context.Background(), archive)
+	if err != nil {
+		t.Fatalf("collectReferences: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("added %d referenced objects, want only the pull secret", count)
+	}
+	if _, ok := archive.digests["namespaces/prod/v1/secrets/pull.json"]; !ok {
+		t.Fatalf("pull secret missing from archive: %v", archive.digests)
+	}
+	if _, ok := archive.digests["namespaces/prod/v1/secrets/token.json"]; ok {
+		t.Fatal("expected the generated token secret to stay excluded")
+	}
+}
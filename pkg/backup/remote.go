@@ -0,0 +1,81 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// ForKubeconfig returns a BackupManager for the cluster kubeconfig points
+// at, through contextName or, when empty, its current context. The manager
+// starts with the client rate limits currently set on bm and shares its
+// HTTP client. Kubeconfigs that run plugins or read local files are
+// rejected, since they usually come from Secrets other users can write.
+func (bm *BackupManager) ForKubeconfig(kubeconfig []byte, contextName string) (*BackupManager, error) {
+	raw, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+	if err := validateKubeconfig(raw); err != nil {
+		return nil, err
+	}
+	config, err := clientcmd.NewNonInteractiveClientConfig(*raw, contextName, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	remote, err := NewBackupManager(config)
+	if err != nil {
+		return nil, err
+	}
+	remote.HTTPClient = bm.HTTPClient
+	remote.HostStorage = bm.HostStorage
+	remote.memoryBudget = bm.memoryBudget
+	remote.tempDirs = bm.tempDirs
+	remote.archiveRefs = bm.archiveRefs
+	if bm.rateLimiter != nil {
+		current := bm.rateLimiter.current.Load()
+		remote.rateLimiter.set(current.QPS(), current.burst)
+	}
+	return remote, nil
+}
+
+// validateKubeconfig rejects credentials that would run commands in, or
+// read files from, the operator's container.
+func validateKubeconfig(config *clientcmdapi.Config) error {
+	for name, cluster := range config.Clusters {
+		if cluster.CertificateAuthority != "" {
+			return fmt.Errorf("kubeconfig cluster %q reads a certificate authority file; embed certificate-authority-data instead", name)
+		}
+	}
+	for name, user := range config.AuthInfos {
+		switch {
+		case user.Exec != nil:
+			return fmt.Errorf("kubeconfig user %q runs an exec plugin, which is not supported", name)
+		case user.AuthProvider != nil:
+			return fmt.Errorf("kubeconfig user %q uses an auth provider plugin, which is not supported", name)
+		case user.ClientCertificate != "" || user.ClientKey != "":
+			return fmt.Errorf("kubeconfig user %q reads client certificate files; embed client-certificate-data and client-key-data instead", name)
+		case user.TokenFile != "":
+			return fmt.Errorf("kubeconfig user %q reads a token file; embed the token instead", name)
+		}
+	}
+	return nil
+}
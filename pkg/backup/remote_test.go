@@ -0,0 +1,112 @@
+package backup
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+func TestForKubeconfig(t *testing.T) {
+	t.Parallel()
+
+	var authorization string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"ConfigMapList","items":[]}`))
+	}))
+	defer server.Close()
+
+	kubeconfig := `apiVersion: v1
+kind: Config
+clusters:
+- name: edge
+  cluster:
+    server: ` + server.URL + `
+    insecure-skip-tls-verify: true
+- name: staging
+  cluster:
+    server: https://staging.invalid
+users:
+- name: edge
+  user:
+    token: edge-token
+- name: staging
+  user:
+    token: staging-token
+contexts:
+- name: edge
+  context: {cluster: edge, user: edge}
+- name: staging
+  context: {cluster: staging, user: staging}
+current-context: staging
+`
+
+	local, err := NewBackupManager(&rest.Config{Host: "https://local.invalid"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	local.SetClientRateLimits(5, 10)
+
+	remote, err := local.ForKubeconfig([]byte(kubeconfig), "edge")
+	if err != nil {
+		t.Fatalf("ForKubeconfig: %v", err)
+	}
+	if _, err := remote.DynamicClient.Resource(configMapsResource).Namespace("apps").List(context.Background(), metav1.ListOptions{}); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if authorization != "Bearer edge-token" {
+		t.Fatalf("Authorization = %q, want the edge token", authorization)
+	}
+	if remote.rateLimiter == local.rateLimiter || remote.rateLimiter.QPS() != 5 {
+		t.Fatalf("remote rate limiter QPS = %v, want its own limiter at 5", remote.rateLimiter.QPS())
+	}
+
+	// Without a context the current one is used
+	remote, err = local.ForKubeconfig([]byte(kubeconfig), "")
+	if err != nil {
+		t.Fatalf("ForKubeconfig: %v", err)
+	}
+	if remote.Config.Host != "https://staging.invalid" {
+		t.Fatalf("host = %q, want the current context's", remote.Config.Host)
+	}
+
+	if _, err := local.ForKubeconfig([]byte(kubeconfig), "missing"); err == nil {
+		t.Fatal("ForKubeconfig succeeded for a missing context")
+	}
+}
+
+func TestForKubeconfigRejectsLocalCredentials(t *testing.T) {
+	t.Parallel()
+
+	local := &BackupManager{}
+	for name, user := range map[string]string{
+		"exec":          "exec: {apiVersion: client.authentication.k8s.io/v1, command: /bin/sh}",
+		"auth provider": "auth-provider: {name: oidc}",
+		"token file":    "tokenFile: /var/run/secrets/kubernetes.io/serviceaccount/token",
+		"client cert":   "client-certificate: /etc/tls/tls.crt",
+	} {
+		kubeconfig := `apiVersion: v1
+kind: Config
+clusters:
+- name: edge
+  cluster: {server: https://edge.invalid}
+users:
+- name: edge
+  user: {` + user + `}
+contexts:
+- name: edge
+  context: {cluster: edge, user: edge}
+current-context: edge
+`
+		_, err := local.ForKubeconfig([]byte(kubeconfig), "")
+		if err == nil || !strings.Contains(err.Error(), `kubeconfig user "edge"`) {
+			t.Errorf("%s: err = %v, want the user rejected", name, err)
+		}
+	}
+}
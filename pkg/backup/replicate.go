@@ -0,0 +1,315 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// ReplicaResult records the copy of an archive to a replica storage location.
+type ReplicaResult struct {
+	StoragePath string
+	// FilePath is the stored archive, empty when Error is set.
+	FilePath string
+	Error    error
+}
+
+// replicateArchive copies a staged archive to every replica storage location
+// in parallel. A failed copy is returned with its location instead of
+// failing the others.
+func (bm *BackupManager) replicateArchive(ctx context.Context, stagingPath string, storagePaths []string) []ReplicaResult {
+	log := ctrl.LoggerFrom(ctx)
+
+	results := make([]ReplicaResult, len(storagePaths))
+	var wg sync.WaitGroup
+	for i, storagePath := range storagePaths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			archivePath, err := bm.copyArchive(stagingPath, storagePath)
+			if err != nil {
+				log.Error(err, "Failed to replicate archive", "storagePath", storagePath)
+			}
+			results[i] = ReplicaResult{StoragePath: storagePath, FilePath: archivePath, Error: err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// copyArchive copies an archive into the storage location, leaving the
+// original in place. The copy is written under a temporary name first so
+// a partially copied archive is never picked up by restore or retention.
+func (bm *BackupManager) copyArchive(stagingPath, storagePath string) (string, error) {
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(resolvedStoragePath, 0755); err != nil {
+		return "", fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	archivePath := filepath.Join(resolvedStoragePath, filepath.Base(stagingPath))
+	partialPath := archivePath + ".partial"
+	if err := copyFile(stagingPath, partialPath); err != nil {
+		_ = os.Remove(partialPath)
+		return "", err
+	}
+	if err := os.Rename(partialPath, archivePath); err != nil {
+		_ = os.Remove(partialPath)
+		return "", fmt.Errorf("failed to rename archive: %w", err)
+	}
+	return archivePath, nil
+}
+
+// ArchiveReplica is the state of one archive in one destination after
+// ReplicateArchives.
+type ArchiveReplica struct {
+	Archive     string
+	Destination string
+	// Copied is set when the archive was copied by this call rather than
+	// already present.
+	Copied bool
+	Error  error
+}
+
+// ListArchives returns the names of the archives in storagePath, oldest
+// first. A missing directory holds no archives.
+func (bm *BackupManager) ListArchives(storagePath string) ([]string, error) {
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(resolvedStoragePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read storage directory: %w", err)
+	}
+
+	var archives []string
+	for _, e := range entries {
+		if !e.IsDir() && isArchiveName(e.Name()) {
+			archives = append(archives, e.Name())
+		}
+	}
+	// The timestamp in the name gives chronological order
+	sort.Strings(archives)
+	return archives, nil
+}
+
+// StorageUsage is the number and total size of the archives in a storage
+// location.
+type StorageUsage struct {
+	Archives int
+	Bytes    int64
+}
+
+// StorageUsage counts the archives in storagePath and adds up their sizes.
+// Archives removed while it runs are left out.
+func (bm *BackupManager) StorageUsage(storagePath string) (StorageUsage, error) {
+	archives, err := bm.ListArchives(storagePath)
+	if err != nil {
+		return StorageUsage{}, err
+	}
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return StorageUsage{}, err
+	}
+
+	// Sizes come from the catalog where it lists the archive
+	catalog, err := bm.ReadCatalog(storagePath)
+	if err != nil {
+		return StorageUsage{}, err
+	}
+	var usage StorageUsage
+	for _, name := range archives {
+		if catalog != nil {
+			if entry, ok := catalog.Entry(name); ok {
+				usage.Archives++
+				usage.Bytes += entry.Size
+				continue
+			}
+		}
+		info, err := os.Stat(filepath.Join(resolvedStoragePath, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return StorageUsage{}, fmt.Errorf("failed to stat archive %s: %w", name, err)
+		}
+		usage.Archives++
+		usage.Bytes += info.Size()
+	}
+	return usage, nil
+}
+
+// ReplicateArchives copies every archive in source that a destination does
+// not hold yet, or holds with a different size. Destinations are handled in
+// parallel and archives oldest first within each. It returns the archives
+// found in source and the state of each of them in every destination; the
+// error is only set when source cannot be listed.
+func (bm *BackupManager) ReplicateArchives(ctx context.Context, source string, destinations []string) ([]string, []ArchiveReplica, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	archives, err := bm.ListArchives(source)
+	if err != nil {
+		return nil, nil, err
+	}
+	resolvedSource, err := bm.resolveStoragePath(source)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	results := make([][]ArchiveReplica, len(destinations))
+	var wg sync.WaitGroup
+	for i, destination := range destinations {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, archive := range archives {
+				replica := ArchiveReplica{Archive: archive, Destination: destination}
+				if err := ctx.Err(); err != nil {
+					replica.Error = err
+				} else {
+					replica.Copied, replica.Error = bm.replicateIfMissing(filepath.Join(resolvedSource, archive), destination)
+				}
+				if replica.Error != nil {
+					log.Error(replica.Error, "Failed to replicate archive", "archive", archive, "destination", destination)
+				} else if replica.Copied {
+					log.Info("Replicated archive", "archive", archive, "destination", destination)
+				}
+				results[i] = append(results[i], replica)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var replicas []ArchiveReplica
+	for _, r := range results {
+		replicas = append(replicas, r...)
+	}
+	return archives, replicas, nil
+}
+
+// replicateIfMissing copies archivePath into destination unless a file of
+// the same name and size is already there. It reports whether it copied.
+func (bm *BackupManager) replicateIfMissing(archivePath, destination string) (bool, error) {
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		return false, fmt.Errorf("failed to stat archive: %w", err)
+	}
+	resolvedDestination, err := bm.resolveStoragePath(destination)
+	if err != nil {
+		return false, err
+	}
+	existing, err := os.Stat(filepath.Join(resolvedDestination, filepath.Base(archivePath)))
+	if err == nil && existing.Size() == info.Size() {
+		return false, nil
+	}
+	copied, err := bm.copyArchive(archivePath, destination)
+	if err != nil {
+		return false, err
+	}
+	if err := catalogArchive(copied, catalogedManifestDigest(archivePath)); err != nil {
+		return true, fmt.Errorf("failed to update catalog: %w", err)
+	}
+	if err := copyRotationTag(archivePath, copied); err != nil {
+		return true, fmt.Errorf("failed to carry over rotation tag: %w", err)
+	}
+	if err := copyArchiveKeys(archivePath, copied); err != nil {
+		return true, fmt.Errorf("failed to carry over key list: %w", err)
+	}
+	return true, nil
+}
+
+// TransferArchive copies the named archive from source to destination,
+// removing it from source afterwards when move is set. The archive is
+// streamed through the operator and never overwrites an existing archive.
+// It returns the path of the archive in destination.
+func (bm *BackupManager) TransferArchive(ctx context.Context, source, archiveName, destination string, move bool) (string, error) {
+	if err := ValidateArchiveName(archiveName); err != nil {
+		return "", err
+	}
+	resolvedSource, err := bm.resolveStoragePath(source)
+	if err != nil {
+		return "", err
+	}
+	resolvedDestination, err := bm.resolveStoragePath(destination)
+	if err != nil {
+		return "", err
+	}
+	if filepath.Clean(resolvedSource) == filepath.Clean(resolvedDestination) {
+		return "", fmt.Errorf("source and destination are the same storage location")
+	}
+
+	archivePath := filepath.Join(resolvedSource, archiveName)
+	if _, err := os.Stat(archivePath); err != nil {
+		return "", fmt.Errorf("failed to find archive: %w", err)
+	}
+	if move {
+		if err := checkArchiveMutable(archivePath, time.Now()); err != nil {
+			return "", err
+		}
+		if bm.isReferenced(archivePath, time.Now()) {
+			return "", fmt.Errorf("%w: %s", ErrArchiveInUse, archiveName)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(resolvedDestination, archiveName)); err == nil {
+		return "", fmt.Errorf("archive %q already exists in %s", archiveName, destination)
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	transferred, err := bm.copyArchive(archivePath, destination)
+	if err != nil {
+		return "", err
+	}
+	if err := catalogArchive(transferred, catalogedManifestDigest(archivePath)); err != nil {
+		return transferred, fmt.Errorf("failed to update catalog: %w", err)
+	}
+	if err := copyRotationTag(archivePath, transferred); err != nil {
+		return transferred, fmt.Errorf("failed to carry over rotation tag: %w", err)
+	}
+	if err := copyArchiveKeys(archivePath, transferred); err != nil {
+		return transferred, fmt.Errorf("failed to carry over key list: %w", err)
+	}
+	if move {
+		// A moved archive stays pinned
+		if marker, err := os.ReadFile(archivePath + keepSuffix); err == nil {
+			if err := os.WriteFile(transferred+keepSuffix, marker, 0644); err != nil {
+				return transferred, fmt.Errorf("failed to carry over keep marker: %w", err)
+			}
+		}
+		if err := removeArchive(archivePath); err != nil {
+			return transferred, fmt.Errorf("failed to remove archive from source: %w", err)
+		}
+	}
+	ctrl.LoggerFrom(ctx).Info("Transferred archive", "archive", archiveName, "destination", destination, "move", move)
+	return transferred, nil
+}
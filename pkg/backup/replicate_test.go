@@ -0,0 +1,194 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReplicateArchive(t *testing.T) {
+	t.Parallel()
+
+	staging := filepath.Join(t.TempDir(), "cluster-backup-20250101-000000.tar.gz")
+	if err := os.WriteFile(staging, []byte("archive"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	blocked := filepath.Join(t.TempDir(), "blocked")
+	if err := os.WriteFile(blocked, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	replicaA := t.TempDir()
+	replicaB := filepath.Join(t.TempDir(), "nested")
+
+	results := (&BackupManager{}).replicateArchive(context.Background(), staging, []string{replicaA, blocked, replicaB})
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+
+	for _, i := range []int{0, 2} {
+		result := results[i]
+		if result.Error != nil {
+			t.Fatalf("replica %s failed: %v", result.StoragePath, result.Error)
+		}
+		data, err := os.ReadFile(result.FilePath)
+		if err != nil || string(data) != "archive" {
+			t.Fatalf("replica %s holds %q, %v", result.StoragePath, data, err)
+		}
+		if _, err := os.Stat(result.FilePath + ".partial"); !os.IsNotExist(err) {
+			t.Fatalf("partial copy left behind in %s", result.StoragePath)
+		}
+	}
+	if results[1].StoragePath != blocked || results[1].Error == nil || results[1].FilePath != "" {
+		t.Fatalf("unexpected result for unwritable replica: %+v", results[1])
+	}
+
+	// The staged archive stays in place for publishArchive
+	if _, err := os.Stat(staging); err != nil {
+		t.Fatalf("staged archive removed: %v", err)
+	}
+}
+
+func TestReplicateArchives(t *testing.T) {
+	t.Parallel()
+
+	source := t.TempDir()
+	for _, name := range []string{"cluster-backup-20250101-000000.tar.gz", "cluster-backup-20250102-000000.tar.zst", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(source, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// An in-flight copy is not a completed archive
+	if err := os.WriteFile(filepath.Join(source, "cluster-backup-20250103-000000.tar.gz.partial"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	present := t.TempDir()
+	existing := filepath.Join(present, "cluster-backup-20250101-000000.tar.gz")
+	if err := os.WriteFile(existing, []byte("cluster-backup-20250101-000000.tar.gz"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	blocked := filepath.Join(t.TempDir(), "blocked")
+	if err := os.WriteFile(blocked, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	bm := &BackupManager{}
+	archives, replicas, err := bm.ReplicateArchives(context.Background(), source, []string{present, blocked})
+	if err != nil {
+		t.Fatalf("ReplicateArchives: %v", err)
+	}
+	want := []string{"cluster-backup-20250101-000000.tar.gz", "cluster-backup-20250102-000000.tar.zst"}
+	if len(archives) != 2 || archives[0] != want[0] || archives[1] != want[1] {
+		t.Fatalf("archives = %v, want %v", archives, want)
+	}
+	if len(replicas) != 4 {
+		t.Fatalf("got %d replicas, want 4", len(replicas))
+	}
+
+	for _, replica := range replicas {
+		switch {
+		case replica.Destination == blocked:
+			if replica.Error == nil {
+				t.Fatalf("copy of %s to an unwritable destination succeeded", replica.Archive)
+			}
+		case replica.Archive == want[0]:
+			if replica.Error != nil || replica.Copied {
+				t.Fatalf("archive already present was copied again: %+v", replica)
+			}
+		default:
+			if replica.Error != nil || !replica.Copied {
+				t.Fatalf("missing archive was not copied: %+v", replica)
+			}
+		}
+	}
+	if _, err := os.Stat(filepath.Join(present, want[1])); err != nil {
+		t.Fatalf("replicated archive missing: %v", err)
+	}
+
+	// A second pass finds nothing to copy
+	_, replicas, err = bm.ReplicateArchives(context.Background(), source, []string{present})
+	if err != nil {
+		t.Fatalf("ReplicateArchives: %v", err)
+	}
+	for _, replica := range replicas {
+		if replica.Copied || replica.Error != nil {
+			t.Fatalf("unexpected copy on second pass: %+v", replica)
+		}
+	}
+}
+
+func TestTransferArchive(t *testing.T) {
+	t.Parallel()
+
+	const archive = "cluster-backup-20250101-000000.tar.gz"
+	source := t.TempDir()
+	if err := os.WriteFile(filepath.Join(source, archive), []byte("archive"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	bm := &BackupManager{}
+	ctx := context.Background()
+
+	copyDestination := t.TempDir()
+	copied, err := bm.TransferArchive(ctx, source, archive, copyDestination, false)
+	if err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	if copied != filepath.Join(copyDestination, archive) {
+		t.Fatalf("copied to %s", copied)
+	}
+	if _, err := os.Stat(filepath.Join(source, archive)); err != nil {
+		t.Fatalf("copy removed the source archive: %v", err)
+	}
+	if _, err := bm.TransferArchive(ctx, source, archive, copyDestination, false); err == nil {
+		t.Fatal("copy overwrote an existing archive")
+	}
+
+	moveDestination := t.TempDir()
+	if _, err := bm.TransferArchive(ctx, source, archive, moveDestination, true); err != nil {
+		t.Fatalf("move: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(source, archive)); !os.IsNotExist(err) {
+		t.Fatalf("move left the source archive: %v", err)
+	}
+
+	for _, name := range []string{"../" + archive, "notes.txt"} {
+		if _, err := bm.TransferArchive(ctx, moveDestination, name, t.TempDir(), false); err == nil {
+			t.Fatalf("accepted archive name %q", name)
+		}
+	}
+	if _, err := bm.TransferArchive(ctx, moveDestination, archive, moveDestination, false); err == nil {
+		t.Fatal("accepted identical source and destination")
+	}
+}
+
+func TestStorageUsage(t *testing.T) {
+	t.Parallel()
+
+	storagePath := t.TempDir()
+	for name, data := range map[string]string{
+		"cluster-backup-20250101-000000.tar.gz":         "first",
+		"cluster-backup-20250102-000000.tar.gz":         "second",
+		"cluster-backup-20250103-000000.tar.gz.partial": "partial",
+		".backup-operator-probe-1":                      "probe",
+	} {
+		if err := os.WriteFile(filepath.Join(storagePath, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bm := &BackupManager{}
+	usage, err := bm.StorageUsage(storagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage != (StorageUsage{Archives: 2, Bytes: int64(len("first") + len("second"))}) {
+		t.Fatalf("got %+v", usage)
+	}
+
+	usage, err = bm.StorageUsage(filepath.Join(storagePath, "missing"))
+	if err != nil || usage != (StorageUsage{}) {
+		t.Fatalf("missing location: got %+v, %v", usage, err)
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/restmapper"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// restMapper returns bm.RESTMapper or, when it is nil, a mapper over the
+// manager's discovery data built on first use, for one restore to resolve
+// its objects with.
+func (bm *BackupManager) restMapper() *refreshingMapper {
+	mapper := bm.RESTMapper
+	if mapper == nil {
+		bm.restMapperOnce.Do(func() {
+			bm.discoveredMapper = restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(bm.DiscoveryClient))
+		})
+		mapper = bm.discoveredMapper
+	}
+	return &refreshingMapper{RESTMapper: mapper}
+}
+
+// refreshingMapper resets a resettable mapper, at most once, when a kind
+// does not match or an object does not fit the mapping found for it, so a
+// restore sees types installed, and versions or scopes changed, since the
+// mapper last discovered them without rediscovering everything each time.
+type refreshingMapper struct {
+	meta.RESTMapper
+	refreshed bool
+}
+
+// refresh resets the mapper and reports whether it did.
+func (m *refreshingMapper) refresh() bool {
+	resettable, ok := m.RESTMapper.(meta.ResettableRESTMapper)
+	if !ok || m.refreshed {
+		return false
+	}
+	m.refreshed = true
+	resettable.Reset()
+	return true
+}
+
+// RESTMapping maps gk, refreshing the mapper and trying again when it does
+// not know the kind.
+func (m *refreshingMapper) RESTMapping(gk schema.GroupKind, versions ...string) (*meta.RESTMapping, error) {
+	mapping, err := m.RESTMapper.RESTMapping(gk, versions...)
+	if meta.IsNoMatchError(err) && m.refresh() {
+		return m.RESTMapper.RESTMapping(gk, versions...)
+	}
+	return mapping, err
+}
+
+// resolveResources sets the resource of every archived object from its
+// apiVersion and kind as mapper, the target cluster's, maps them, instead of
+// the resource named by its archive path, so archives restore whatever the
+// path layout they were written with. Objects the target cluster cannot
+// map keep the resource of their path, for checkRestoreAPIs to convert or
+// report. An object stored under a resource the target serves with another
+// kind, or whose namespace does not fit the scope of its kind, fails.
+func resolveResources(ctx context.Context, mapper *refreshingMapper, resources []archivedResource) {
+	log := ctrl.LoggerFrom(ctx)
+	for i := range resources {
+		res := &resources[i]
+		kind := nestedString(res.object, "kind")
+		if res.err != nil || kind == "" {
+			continue
+		}
+		gvk := res.gvr.GroupVersion().WithKind(kind)
+		if apiVersion := nestedString(res.object, "apiVersion"); apiVersion != "" {
+			gvk = schema.FromAPIVersionAndKind(apiVersion, kind)
+		}
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			if !meta.IsNoMatchError(err) {
+				log.Error(err, "Failed to map archived object, restoring it as its archive path says", "kind", kind, "name", res.name)
+			}
+			continue
+		}
+		err = checkMapping(mapper, *res, kind, mapping)
+		// The mapping may predate a change to the type
+		if err != nil && mapper.refresh() {
+			if mapping, err = mapper.RESTMapping(gvk.GroupKind(), gvk.Version); err == nil {
+				err = checkMapping(mapper, *res, kind, mapping)
+			}
+		}
+		if err != nil {
+			res.err = err
+			continue
+		}
+		if mapping.Resource != res.gvr {
+			log.V(1).Info("Restoring archived object as the resource its kind maps to", "archived", res.gvr, "resource", mapping.Resource, "name", res.name)
+			res.gvr = mapping.Resource
+		}
+	}
+}
+
+// checkMapping returns an ErrObjectTypeMismatch error when res, of kind, is
+// stored under a resource the target serves with another kind than mapping,
+// or when its namespace does not fit the scope of mapping.
+func checkMapping(mapper meta.RESTMapper, res archivedResource, kind string, mapping *meta.RESTMapping) error {
+	if mapping.Resource != res.gvr {
+		if served, err := mapper.KindFor(res.gvr); err == nil && served.Kind != kind {
+			return fmt.Errorf("%w: kind %s, the target cluster serves %s as %s", ErrObjectTypeMismatch, kind, typeName(res), served.Kind)
+		}
+	}
+	namespaced := mapping.Scope.Name() == meta.RESTScopeNameNamespace
+	switch {
+	case namespaced && res.namespace == "":
+		return fmt.Errorf("%w: %s is namespaced but archived without a namespace", ErrObjectTypeMismatch, kind)
+	case !namespaced && res.namespace != "":
+		return fmt.Errorf("%w: %s is cluster-scoped but archived in namespace %s", ErrObjectTypeMismatch, kind, res.namespace)
+	}
+	return nil
+}
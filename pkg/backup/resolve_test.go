@@ -0,0 +1,116 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestResolveResources(t *testing.T) {
+	t.Parallel()
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Secret"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+	bm := &BackupManager{RESTMapper: mapper}
+
+	object := func(kind, namespace, name string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": kind}}
+		obj.SetNamespace(namespace)
+		obj.SetName(name)
+		return obj
+	}
+	resources := []archivedResource{
+		// Archived under a path layout naming the kind instead of the resource
+		archived("", "v1", "configmap", object("ConfigMap", "shop", "renamed")),
+		archived("", "v1", "configmaps", object("ConfigMap", "shop", "settings")),
+		archived("", "v1", "configmaps", object("Secret", "shop", "misfiled")),
+		archived("", "v1", "namespaces", object("Namespace", "shop", "scoped")),
+		archived("example.com", "v1", "widgets", object("Widget", "shop", "unknown")),
+		// Archived under a path whose version differs from the object's
+		archived("apps", "v1beta1", "deployments", object("Deployment", "shop", "web")),
+	}
+	resources[4].object["apiVersion"] = "example.com/v1"
+	resources[5].object["apiVersion"] = "apps/v1"
+	resolveResources(context.Background(), bm.restMapper(), resources)
+
+	configMaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	for i, want := range []struct {
+		gvr      schema.GroupVersionResource
+		mismatch bool
+	}{
+		{configMaps, false},
+		{configMaps, false},
+		{configMaps, true},
+		{schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}, true},
+		{schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}, false},
+		{schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, false},
+	} {
+		res := resources[i]
+		if res.gvr != want.gvr || errors.Is(res.err, ErrObjectTypeMismatch) != want.mismatch {
+			t.Errorf("%s resolved to %s (%v), want %s and mismatch %t", res.name, res.gvr, res.err, want.gvr, want.mismatch)
+		}
+	}
+}
+
+func TestResolveResourcesRediscoversTypes(t *testing.T) {
+	t.Parallel()
+
+	fake := &clienttesting.Fake{Resources: []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{{Name: "configmaps", Kind: "ConfigMap", Namespaced: true}},
+	}}}
+	bm := &BackupManager{DiscoveryClient: &fakediscovery.FakeDiscovery{Fake: fake}}
+
+	widget := &unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "example.com/v1", "kind": "Widget"}}
+	widget.SetNamespace("shop")
+	widget.SetName("gadget")
+	resources := []archivedResource{archived("example.com", "v1", "widget", widget)}
+	resolveResources(context.Background(), bm.restMapper(), resources)
+	if want := (schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widget"}); resources[0].gvr != want || resources[0].err != nil {
+		t.Fatalf("expected the unknown kind to keep its archive path, got %s (%v)", resources[0].gvr, resources[0].err)
+	}
+
+	// The CRD is installed, and later switched to cluster scope, after the
+	// first restore looked the kind up
+	fake.Resources = append(fake.Resources, &metav1.APIResourceList{
+		GroupVersion: "example.com/v1",
+		APIResources: []metav1.APIResource{{Name: "widgets", Kind: "Widget", Namespaced: true}},
+	})
+	resources = []archivedResource{archived("example.com", "v1", "widget", widget)}
+	resolveResources(context.Background(), bm.restMapper(), resources)
+	if want := (schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}); resources[0].gvr != want || resources[0].err != nil {
+		t.Fatalf("expected the installed kind to be mapped, got %s (%v)", resources[0].gvr, resources[0].err)
+	}
+
+	// Kinds the mapper knows are not rediscovered
+	discoveries := len(fake.Actions())
+	resources = []archivedResource{archived("example.com", "v1", "widgets", widget)}
+	resolveResources(context.Background(), bm.restMapper(), resources)
+	if resources[0].err != nil || len(fake.Actions()) != discoveries {
+		t.Fatalf("expected the known kind to map without discovery, got %v and %d discovery calls", resources[0].err, len(fake.Actions())-discoveries)
+	}
+
+	fake.Resources[1].APIResources[0].Namespaced = false
+	clusterWidget := widget.DeepCopy()
+	clusterWidget.SetNamespace("")
+	resources = []archivedResource{archived("example.com", "v1", "widgets", clusterWidget)}
+	resolveResources(context.Background(), bm.restMapper(), resources)
+	if resources[0].err != nil {
+		t.Fatalf("expected the cluster-scoped widget to fit the new scope, got %v", resources[0].err)
+	}
+	resources = []archivedResource{archived("example.com", "v1", "widgets", widget)}
+	resolveResources(context.Background(), bm.restMapper(), resources)
+	if !errors.Is(resources[0].err, ErrObjectTypeMismatch) {
+		t.Fatalf("expected the namespaced widget to mismatch the new scope, got %v", resources[0].err)
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"strings"
+)
+
+// groupWildcardPrefix starts resource type entries such as "*.istio.io",
+// which select every kind of an API group and its subgroups.
+const groupWildcardPrefix = "*."
+
+// ValidateResourceType checks that resourceType is a kind or a group
+// wildcard such as "*.monitoring.coreos.com".
+func ValidateResourceType(resourceType string) error {
+	group, wildcard := strings.CutPrefix(strings.TrimSpace(resourceType), groupWildcardPrefix)
+	switch {
+	case wildcard && (group == "" || strings.Contains(group, "*")):
+		return fmt.Errorf("group wildcard %q must name a group, as in \"*.istio.io\"", resourceType)
+	case !wildcard && strings.Contains(resourceType, "*"):
+		return fmt.Errorf("wildcard %q is only supported as a group prefix, as in \"*.istio.io\"", resourceType)
+	}
+	return nil
+}
+
+// kindFilter matches discovered resource types against resource type
+// entries: kinds compare case-insensitively and group wildcards match the
+// group and every group ending in it.
+type kindFilter struct {
+	kinds  map[string]struct{}
+	groups []string
+}
+
+func newKindFilter(resourceTypes []string) kindFilter {
+	filter := kindFilter{kinds: map[string]struct{}{}}
+	for _, resourceType := range resourceTypes {
+		resourceType = strings.ToLower(strings.TrimSpace(resourceType))
+		if group, ok := strings.CutPrefix(resourceType, groupWildcardPrefix); ok {
+			filter.groups = append(filter.groups, group)
+			continue
+		}
+		filter.kinds[resourceType] = struct{}{}
+	}
+	return filter
+}
+
+// empty reports whether the filter has no entries, selecting everything.
+func (f kindFilter) empty() bool {
+	return len(f.kinds) == 0 && len(f.groups) == 0
+}
+
+// matches reports whether the kind in group is selected by an entry.
+func (f kindFilter) matches(group, kind string) bool {
+	if _, ok := f.kinds[strings.ToLower(kind)]; ok {
+		return true
+	}
+	for _, wildcard := range f.groups {
+		if group == wildcard || strings.HasSuffix(group, "."+wildcard) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package backup
+
+import "testing"
+
+func TestKindFilterMatchesGroupWildcards(t *testing.T) {
+	t.Parallel()
+
+	filter := newKindFilter([]string{"ConfigMap", " *.Istio.io ", "*.monitoring.coreos.com"})
+	for _, tc := range []struct {
+		group, kind string
+		want        bool
+	}{
+		{"", "configmap", true},
+		{"networking.istio.io", "VirtualService", true},
+		{"istio.io", "Gateway", true},
+		{"monitoring.coreos.com", "ServiceMonitor", true},
+		{"notistio.io", "Thing", false},
+		{"apps", "Deployment", false},
+	} {
+		if got := filter.matches(tc.group, tc.kind); got != tc.want {
+			t.Errorf("matches(%q, %q) = %v, want %v", tc.group, tc.kind, got, tc.want)
+		}
+	}
+	if !newKindFilter(nil).empty() || filter.empty() {
+		t.Error("empty() does not reflect the entries")
+	}
+}
+
+func TestValidateResourceType(t *testing.T) {
+	t.Parallel()
+
+	for _, resourceType := range []string{"Deployment", "*.istio.io"} {
+		if err := ValidateResourceType(resourceType); err != nil {
+			t.Errorf("ValidateResourceType(%q) error = %v", resourceType, err)
+		}
+	}
+	for _, resourceType := range []string{"*", "*.", "*.*.io", "Virtual*"} {
+		if err := ValidateResourceType(resourceType); err == nil {
+			t.Errorf("ValidateResourceType(%q) error = nil, want an error", resourceType)
+		}
+	}
+}
@@ -0,0 +1,954 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// RestoreFailurePolicy decides how a restore reacts to an item that cannot be applied.
+type RestoreFailurePolicy string
+
+const (
+	// RestoreFailurePolicyContinue records the failing item and keeps applying
+	// the remaining resources.
+	RestoreFailurePolicyContinue RestoreFailurePolicy = "Continue"
+	// RestoreFailurePolicyFailFast aborts the restore on the first failing
+	// item. The result of what was applied until then, including the
+	// failing item, is returned along with its RestoreItemError.
+	RestoreFailurePolicyFailFast RestoreFailurePolicy = "FailFast"
+)
+
+// ExistingResourcePolicy decides how a restore treats archived resources that
+// already exist in the cluster.
+type ExistingResourcePolicy string
+
+const (
+	// ExistingResourcePolicyUpdate replaces existing objects with the
+	// archived content.
+	ExistingResourcePolicyUpdate ExistingResourcePolicy = "Update"
+	// ExistingResourcePolicyPatch server-side applies the archived content,
+	// so only fields present in the archive are set and fields managed by
+	// other controllers are left alone.
+	ExistingResourcePolicyPatch ExistingResourcePolicy = "Patch"
+
+	// existingResourcePolicyKeep leaves existing objects untouched.
+	existingResourcePolicyKeep ExistingResourcePolicy = "Keep"
+)
+
+// restoreFieldManager owns the fields set by restores by default. Reusing it
+// across restores lets the apiserver drop fields that an earlier restore set
+// but the current archive no longer contains.
+const restoreFieldManager = "backup-operator-restore"
+
+// RestoreOptions contains configuration for a restore operation
+type RestoreOptions struct {
+	// FailurePolicy defaults to RestoreFailurePolicyContinue when empty.
+	FailurePolicy RestoreFailurePolicy
+
+	// Progress, when set, is called once the archive has been read and then
+	// after every processed item with the running and total item counts.
+	Progress func(processed, total int)
+
+	// ExistingResourcePolicy defaults to ExistingResourcePolicyUpdate when
+	// empty.
+	ExistingResourcePolicy ExistingResourcePolicy
+
+	// ExcludeGitOpsManaged skips archived objects tracked by Argo CD or Flux,
+	// leaving them for the GitOps controllers to recreate.
+	ExcludeGitOpsManaged bool
+
+	// IncludeGeneratedResources restores service account token Secrets and
+	// kube-root-ca.crt ConfigMaps, which are skipped by default because the
+	// target cluster generates its own.
+	IncludeGeneratedResources bool
+
+	// HelmReleasePolicy defaults to HelmReleasePolicyIntact when empty.
+	HelmReleasePolicy HelmReleasePolicy
+
+	// QuotaPolicy decides how the archive is checked against the
+	// ResourceQuotas of its target namespaces before anything is applied.
+	// Empty skips the check.
+	QuotaPolicy QuotaPolicy
+
+	// ClusterMismatchPolicy decides how a restore reacts when the archive
+	// was taken from another cluster. Empty defaults to
+	// ClusterMismatchPolicyWarn.
+	ClusterMismatchPolicy ClusterMismatchPolicy
+
+	// RemovedAPIPolicy decides what happens to archived resources whose API
+	// version the cluster no longer serves. Empty defaults to
+	// RemovedAPIPolicyConvert.
+	RemovedAPIPolicy RemovedAPIPolicy
+
+	// IgnoreWebhookFailures sets failurePolicy Ignore on the cluster's
+	// admission webhooks while the restore runs and puts the original
+	// policies back afterwards, so webhooks whose backends are not running
+	// yet cannot reject restored resources.
+	IgnoreWebhookFailures bool
+
+	// CertManagerWaitTimeout bounds how long cert-manager resources wait for
+	// their CRDs to be established. Zero waits up to two minutes.
+	CertManagerWaitTimeout time.Duration
+
+	// KeyWrappers are tried against every recipient of an encrypted archive.
+	// Recipients wrapped by a cloud KMS key are also tried with a wrapper for
+	// the key recorded in the archive, using the operator's cloud credentials.
+	KeyWrappers []KeyWrapper
+
+	// ArchiveSHA256 is the expected SHA-256 digest of the whole archive, as
+	// hex with an optional "sha256:" prefix. The archive is rejected before
+	// anything is applied when it does not match.
+	ArchiveSHA256 string
+
+	// PointInTime restores the state the cluster had at that instant by
+	// replaying the continuous backup change log of the storage path onto
+	// the archive. Without an archive name the newest archive completed by
+	// then is used. Objects deleted before that instant are not restored,
+	// but objects that exist in the cluster are never deleted.
+	PointInTime *time.Time
+
+	// ApplyRate limits how many resources are applied per second, with
+	// bursts of up to ApplyBurst, which defaults to one second's worth.
+	// Zero applies them as fast as the client rate limits allow.
+	ApplyRate  float64
+	ApplyBurst int
+
+	// FieldManager records the restored fields under this field manager.
+	// Empty uses backup-operator-restore.
+	FieldManager string
+
+	// KeepConflictingFields makes ExistingResourcePolicyPatch fail resources
+	// whose archived fields are owned by other field managers, instead of
+	// taking the fields over.
+	KeepConflictingFields bool
+
+	// Callouts are called in order for every object about to be applied and
+	// may change or skip it. Restore plans do not call them.
+	Callouts []RestoreCallout
+
+	// ScaleWorkloadsToZero restores Deployments and StatefulSets with zero
+	// replicas and records the archived count in OriginalReplicasAnnotation,
+	// so no pods start until they are scaled back up.
+	ScaleWorkloadsToZero bool
+
+	// Quiesce lists Deployments and StatefulSets, such as GitOps
+	// controllers or operators that would fight the restore, to scale to
+	// zero before applying anything and back up once the restore ends.
+	// Archived copies of them are restored scaled down and resumed too.
+	Quiesce []Workload
+
+	// QuiesceTimeout bounds how long to wait for the pods of quiesced
+	// workloads to stop. Zero means two minutes.
+	QuiesceTimeout time.Duration
+}
+
+// RestoreResult contains the details from a restore execution.
+type RestoreResult struct {
+	ResourcesApplied int
+	// RestoreCounts tallies the outcome of every archived item.
+	RestoreCounts
+	// ByResource breaks the counts down per group/resource
+	// (for example "deployments.apps").
+	ByResource map[string]*RestoreCounts
+	// FailedItems lists the items that could not be applied when the
+	// restore continued past failures, capped at MaxReportedRestoreFailures.
+	FailedItems []RestoreItemError
+	// QuotaViolations lists the quotas the archive was expected to exceed
+	// when the restore went ahead under QuotaPolicyWarn.
+	QuotaViolations []QuotaViolation
+	// HelmRollbackCommands lists the `helm rollback` commands returning each
+	// archived release to its archived revision, under HelmReleasePolicyRollback.
+	HelmRollbackCommands []string
+	// APIFindings lists the resources archived in removed or deprecated API
+	// versions and what the restore did with them, capped at
+	// MaxReportedAPIFindings.
+	APIFindings []APIFinding
+	// ClusterMismatch describes how the archive's source cluster differs
+	// from the target when the restore went ahead under
+	// ClusterMismatchPolicyWarn.
+	ClusterMismatch string
+	// BaseArchive is the archive a point-in-time restore started from.
+	BaseArchive string
+	// ReplayedChanges counts the change log records replayed onto the
+	// archive for a point-in-time restore.
+	ReplayedChanges int
+}
+
+// RestoreCounts tallies restore outcomes.
+type RestoreCounts struct {
+	Created int
+	Updated int
+	Skipped int
+	Failed  int
+}
+
+// MaxReportedRestoreFailures caps how many failed items a RestoreResult lists.
+const MaxReportedRestoreFailures = 20
+
+// restoreOutcome is the result of applying a single archived item
+type restoreOutcome int
+
+const (
+	outcomeCreated restoreOutcome = iota
+	outcomeUpdated
+	outcomeSkipped
+	outcomeFailed
+)
+
+// record adds the outcome of res to the totals and the per-resource breakdown
+func (r *RestoreResult) record(res archivedResource, outcome restoreOutcome) {
+	key := res.gvr.GroupResource().String()
+	if r.ByResource == nil {
+		r.ByResource = map[string]*RestoreCounts{}
+	}
+	perResource, ok := r.ByResource[key]
+	if !ok {
+		perResource = &RestoreCounts{}
+		r.ByResource[key] = perResource
+	}
+
+	for _, counts := range []*RestoreCounts{&r.RestoreCounts, perResource} {
+		switch outcome {
+		case outcomeCreated:
+			counts.Created++
+		case outcomeUpdated:
+			counts.Updated++
+		case outcomeSkipped:
+			counts.Skipped++
+		case outcomeFailed:
+			counts.Failed++
+		}
+	}
+
+	if outcome == outcomeCreated || outcome == outcomeUpdated {
+		r.ResourcesApplied++
+	}
+}
+
+// RestoreItemError describes a single archived resource that failed to restore.
+type RestoreItemError struct {
+	GVR       schema.GroupVersionResource
+	Namespace string
+	Name      string
+	Err       error
+}
+
+func (e RestoreItemError) Error() string {
+	if e.Namespace == "" {
+		return fmt.Sprintf("%s %s: %v", e.GVR.String(), e.Name, e.Err)
+	}
+	return fmt.Sprintf("%s %s/%s: %v", e.GVR.String(), e.Namespace, e.Name, e.Err)
+}
+
+func (e RestoreItemError) Unwrap() error {
+	return e.Err
+}
+
+type archivedResource struct {
+	gvr       schema.GroupVersionResource
+	namespace string
+	name      string
+	object    map[string]interface{}
+	// err is set when the entry failed verification and must not be applied
+	err error
+}
+
+// RestoreBackup reads an archived backup from storagePath/archiveName and reapplies the
+// resources to the cluster using the manager's dynamic client.
+func (bm *BackupManager) RestoreBackup(ctx context.Context, storagePath, archiveName string, opts RestoreOptions) (*RestoreResult, error) {
+	prepared, err := bm.prepareRestore(ctx, storagePath, archiveName, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer prepared.release()
+	return bm.applyRestore(ctx, prepared, opts)
+}
+
+// RestoreBackupFrom reads an archive streamed by r, reported as name, and
+// reapplies its resources like RestoreBackup. Incremental archives and
+// PointInTime need the storage location the archive was written to, so they
+// are rejected.
+func (bm *BackupManager) RestoreBackupFrom(ctx context.Context, r io.Reader, name string, opts RestoreOptions) (*RestoreResult, error) {
+	if opts.PointInTime != nil {
+		return nil, fmt.Errorf("point-in-time restores need the storage location holding the change log")
+	}
+	clusterResources, namespacedResources, manifest, err := readArchiveFrom(ctx, r, name, opts.keyWrappersFor(ctx), opts.ArchiveSHA256)
+	if err != nil {
+		return nil, err
+	}
+	if manifest != nil && manifest.Base != "" {
+		return nil, fmt.Errorf("archive %s is incremental, its base archive %s can only be read from storage", name, manifest.Base)
+	}
+	identities := ageIdentities(opts.KeyWrappers)
+	decryptSOPSResources(clusterResources, identities)
+	decryptSOPSResources(namespacedResources, identities)
+
+	prepared, err := bm.checkRestore(ctx, clusterResources, namespacedResources, manifest, &RestoreResult{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	return bm.applyRestore(ctx, prepared, opts)
+}
+
+// applyRestore applies the resources of a prepared restore in order.
+func (bm *BackupManager) applyRestore(ctx context.Context, prepared *preparedRestore, opts RestoreOptions) (*RestoreResult, error) {
+	log := ctrl.LoggerFrom(ctx)
+	result := prepared.result
+
+	if opts.IgnoreWebhookFailures {
+		release, relaxed, err := bm.relaxWebhooksForRestore(ctx)
+		// Put back whatever was relaxed, even when the restore is cancelled
+		defer release()
+		if err != nil {
+			return nil, fmt.Errorf("failed to relax webhook failure policies: %w", err)
+		}
+		log.Info("Relaxed webhook failure policies for the restore", "configurations", relaxed)
+	}
+
+	if len(opts.Quiesce) > 0 {
+		quiesced, err := bm.quiesceWorkloads(ctx, opts.Quiesce, opts.QuiesceTimeout)
+		// Resume whatever was scaled down, even when the restore is cancelled
+		defer func() {
+			if err := bm.resumeWorkloads(context.WithoutCancel(ctx), opts.Quiesce); err != nil {
+				log.Error(err, "Failed to resume quiesced workloads")
+			}
+		}()
+		if err != nil {
+			return nil, fmt.Errorf("failed to quiesce workloads: %w", err)
+		}
+		log.Info("Quiesced workloads for the restore", "workloads", quiesced)
+	}
+
+	total := 0
+	for _, list := range prepared.lists {
+		total += len(list)
+	}
+	processed := 0
+	if opts.Progress != nil {
+		opts.Progress(processed, total)
+	}
+
+	var throttle *rate.Limiter
+	if opts.ApplyRate > 0 {
+		burst := opts.ApplyBurst
+		if burst <= 0 {
+			burst = max(int(opts.ApplyRate), 1)
+		}
+		throttle = rate.NewLimiter(rate.Limit(opts.ApplyRate), burst)
+	}
+
+	for _, list := range prepared.lists {
+		if len(list) > 0 && isCertManagerResource(list[0].gvr) {
+			timeout := opts.CertManagerWaitTimeout
+			if timeout <= 0 {
+				timeout = defaultCertManagerWaitTimeout
+			}
+			if err := bm.waitForCRDs(ctx, list, timeout); err != nil {
+				log.Error(err, "cert-manager CRDs are not established, applying cert-manager resources anyway")
+			}
+		}
+		for _, res := range list {
+			outcome, err := outcomeFailed, res.err
+			if err == nil && throttle != nil {
+				if err := throttle.Wait(ctx); err != nil {
+					return nil, fmt.Errorf("restore interrupted after %d of %d resources: %w", processed, total, err)
+				}
+			}
+			skip := false
+			if err == nil && len(opts.Callouts) > 0 {
+				res, skip, err = bm.callOut(ctx, opts.Callouts, res)
+				if skip {
+					outcome = outcomeSkipped
+				}
+			}
+			if err == nil && !skip {
+				outcome, err = bm.applyResource(ctx, res, opts.existingPolicyFor(res), opts.applyOptions())
+			}
+			if err != nil {
+				itemErr := RestoreItemError{
+					GVR:       res.gvr,
+					Namespace: res.namespace,
+					Name:      res.name,
+					Err:       err,
+				}
+				log.Error(err, "Failed to restore resource", "gvr", res.gvr, "namespace", res.namespace, "name", itemErr.Name)
+				if len(result.FailedItems) < MaxReportedRestoreFailures {
+					result.FailedItems = append(result.FailedItems, itemErr)
+				}
+				if opts.FailurePolicy == RestoreFailurePolicyFailFast {
+					result.record(res, outcome)
+					return result, itemErr
+				}
+			}
+
+			result.record(res, outcome)
+
+			processed++
+			if opts.Progress != nil {
+				opts.Progress(processed, total)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// preparedRestore holds an archive that was read and checked for restore.
+type preparedRestore struct {
+	// lists holds the cluster-scoped resources, namespaced resources,
+	// cert-manager resources and webhook configurations, applied in that
+	// order.
+	lists [][]archivedResource
+	// result carries the outcome of the checks and the resources already
+	// skipped.
+	result *RestoreResult
+	// release ends the protection of the archive from retention.
+	release func()
+}
+
+// prepareRestore reads the archive and runs every check made before a
+// restore applies anything, returning its resources in apply order.
+func (bm *BackupManager) prepareRestore(ctx context.Context, storagePath, archiveName string, opts RestoreOptions) (_ *preparedRestore, err error) {
+	if archiveName == "" && opts.PointInTime != nil {
+		if archiveName, err = bm.pointInTimeArchive(storagePath, *opts.PointInTime); err != nil {
+			return nil, err
+		}
+	}
+	if archiveName == "" {
+		return nil, fmt.Errorf("archive name must be provided")
+	}
+
+	releaseArchive := bm.referenceArchive(storagePath, archiveName)
+	defer func() {
+		if err != nil {
+			releaseArchive()
+		}
+	}()
+
+	clusterResources, namespacedResources, manifest, err := bm.readStoredArchive(ctx, storagePath, archiveName, opts.keyWrappersFor(ctx), opts.ArchiveSHA256)
+	if err != nil {
+		return nil, err
+	}
+	identities := ageIdentities(opts.KeyWrappers)
+	decryptSOPSResources(clusterResources, identities)
+	decryptSOPSResources(namespacedResources, identities)
+
+	result := &RestoreResult{}
+	if opts.PointInTime != nil {
+		result.BaseArchive = archiveName
+		clusterResources, namespacedResources, err = bm.replayChangeLog(ctx, storagePath, manifest, *opts.PointInTime,
+			clusterResources, namespacedResources, result)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	prepared, err := bm.checkRestore(ctx, clusterResources, namespacedResources, manifest, result, opts)
+	if err != nil {
+		return nil, err
+	}
+	prepared.release = releaseArchive
+	return prepared, nil
+}
+
+// checkRestore runs every check made before a restore applies the resources
+// read from an archive, returning them in apply order with a no-op release.
+func (bm *BackupManager) checkRestore(ctx context.Context, clusterResources, namespacedResources []archivedResource, manifest *archiveManifest, result *RestoreResult, opts RestoreOptions) (*preparedRestore, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	var err error
+	if manifest != nil {
+		policy := opts.ClusterMismatchPolicy
+		if policy == "" {
+			policy = ClusterMismatchPolicyWarn
+		}
+		mismatch, err := bm.checkClusterIdentity(ctx, manifest.Cluster, policy)
+		if err != nil {
+			return nil, err
+		}
+		if mismatch != "" {
+			log.Info("Restoring into a different cluster than the archive was taken from", "mismatch", mismatch)
+			result.ClusterMismatch = mismatch
+		}
+	}
+
+	var exclude func(obj map[string]interface{}) bool
+	if !opts.IncludeGeneratedResources {
+		exclude = isClusterGenerated
+	}
+	if opts.ExcludeGitOpsManaged {
+		exclude = excludeEither(exclude, isGitOpsManaged)
+	}
+	if exclude != nil {
+		var excluded []archivedResource
+		clusterResources, excluded = excludeResources(clusterResources, exclude)
+		for _, res := range excluded {
+			result.record(res, outcomeSkipped)
+		}
+		namespacedResources, excluded = excludeResources(namespacedResources, exclude)
+		for _, res := range excluded {
+			result.record(res, outcomeSkipped)
+		}
+		log.V(1).Info("Skipping excluded resources", "count", result.Skipped)
+	}
+
+	if bm.RESTMapper != nil || bm.DiscoveryClient != nil {
+		mapper := bm.restMapper()
+		resolveResources(ctx, mapper, clusterResources)
+		resolveResources(ctx, mapper, namespacedResources)
+	}
+
+	// Managers without discovery cannot tell which versions are served
+	if bm.DiscoveryClient != nil {
+		clusterResources, namespacedResources, err = bm.checkRestoreAPIs(ctx, clusterResources, namespacedResources, opts.RemovedAPIPolicy, result)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Scaled after conversion so workloads of removed API versions count
+	if opts.ScaleWorkloadsToZero {
+		log.Info("Restoring workloads scaled to zero", "workloads", scaleToZero(namespacedResources))
+	}
+	if len(opts.Quiesce) > 0 {
+		quiesceArchived(namespacedResources, opts.Quiesce)
+	}
+
+	if opts.QuotaPolicy == QuotaPolicyWarn || opts.QuotaPolicy == QuotaPolicyFailFast {
+		violations, err := bm.checkQuotas(ctx, namespacedResources)
+		switch {
+		case err != nil && opts.QuotaPolicy == QuotaPolicyFailFast:
+			return nil, fmt.Errorf("failed to check namespace quotas: %w", err)
+		case err != nil:
+			log.Error(err, "Failed to check namespace quotas, restoring anyway")
+		case len(violations) > 0 && opts.QuotaPolicy == QuotaPolicyFailFast:
+			return nil, quotaViolationsError(violations)
+		case len(violations) > 0:
+			for _, violation := range violations {
+				log.Info("Restore is expected to exceed a namespace quota", "violation", violation.String())
+			}
+			result.QuotaViolations = violations
+		}
+	}
+
+	// Webhook configurations go last so their webhooks cannot intercept the
+	// resources the restore is still creating, including their own backends
+	clusterResources, webhookConfigurations := splitWebhookConfigurations(clusterResources)
+
+	// cert-manager resources follow the rest so their CRDs, cert-manager
+	// itself and the Secrets their issuers sign with are restored first
+	clusterResources, certManagerResources := splitCertManagerResources(clusterResources)
+	namespacedResources, namespacedCertManager := splitCertManagerResources(namespacedResources)
+	certManagerResources = append(certManagerResources, namespacedCertManager...)
+
+	if opts.HelmReleasePolicy == HelmReleasePolicyRollback {
+		var releases []helmRelease
+		for _, res := range namespacedResources {
+			if release, ok := helmReleaseFromSecret(res.object); ok && res.err == nil {
+				releases = append(releases, release)
+			}
+		}
+		result.HelmRollbackCommands = helmRollbackCommands(releases)
+	}
+
+	return &preparedRestore{
+		lists:   [][]archivedResource{clusterResources, namespacedResources, certManagerResources, webhookConfigurations},
+		result:  result,
+		release: func() {},
+	}, nil
+}
+
+// existingPolicyFor returns how res is treated when it already exists.
+func (o RestoreOptions) existingPolicyFor(res archivedResource) ExistingResourcePolicy {
+	if o.HelmReleasePolicy == HelmReleasePolicyRollback && isHelmReleaseSecret(res.object) {
+		// Keep the cluster's release history; rollbacks pick the archived
+		// revision from it
+		return existingResourcePolicyKeep
+	}
+	return o.ExistingResourcePolicy
+}
+
+// applyOptions returns the field manager and conflict handling of the writes
+// made by the restore.
+func (o RestoreOptions) applyOptions() metav1.ApplyOptions {
+	manager := o.FieldManager
+	if manager == "" {
+		manager = restoreFieldManager
+	}
+	return metav1.ApplyOptions{FieldManager: manager, Force: !o.KeepConflictingFields}
+}
+
+// keyWrappersFor returns the wrappers to try for a recipient of an encrypted
+// archive: the configured ones, followed by a KMS wrapper for the recorded key.
+func (o RestoreOptions) keyWrappersFor(ctx context.Context) func(WrappedKey) []KeyWrapper {
+	return func(recipient WrappedKey) []KeyWrapper {
+		wrappers := append([]KeyWrapper(nil), o.KeyWrappers...)
+		if isKMSProvider(recipient.Provider) {
+			wrapper, err := NewKMSKeyWrapper(ctx, recipient.Provider, recipient.KeyID)
+			if err != nil {
+				ctrl.LoggerFrom(ctx).Error(err, "Failed to create KMS key wrapper", "provider", recipient.Provider)
+			} else {
+				wrappers = append(wrappers, wrapper)
+			}
+		}
+		return wrappers
+	}
+}
+
+// readArchive loads every resource stored in the archive, split into
+// cluster-scoped and namespaced resources so they can be applied in order.
+// When the archive carries a manifest, each entry is checked against its
+// recorded digest; entries that fail verification or are missing are returned
+// with err set instead of aborting the whole restore.
+func readArchive(ctx context.Context, archivePath string, resolve func(WrappedKey) []KeyWrapper) ([]archivedResource, []archivedResource, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open archive %q: %w", filepath.Base(archivePath), err)
+	}
+	defer file.Close()
+
+	clusterResources, namespacedResources, _, err := readArchiveFrom(ctx, file, filepath.Base(archivePath), resolve, "")
+	return clusterResources, namespacedResources, err
+}
+
+// readArchiveFrom reads the archive streamed by r, reported as name, along
+// with its manifest, which is nil for archives written without one. When
+// wantDigest is set the whole stream is checked against it before any
+// resource is returned.
+func readArchiveFrom(ctx context.Context, r io.Reader, name string, resolve func(WrappedKey) []KeyWrapper, wantDigest string) ([]archivedResource, []archivedResource, *archiveManifest, error) {
+	hash := sha256.New()
+	if wantDigest != "" {
+		r = io.TeeReader(r, hash)
+	}
+
+	// The format is detected from the archive itself, so archives written
+	// with any compression or encryption restore without extra settings
+	raw := bufio.NewReader(r)
+	encryption := detectEncryption(raw)
+	compressed, err := decryptArchive(ctx, raw, resolve)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to decrypt archive: %w", err)
+	}
+
+	tarStream, compression, err := newDecompressor(bufio.NewReader(compressed))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to open archive %q: %w", name, err)
+	}
+	defer tarStream.Close()
+
+	tarReader := tar.NewReader(tarStream)
+
+	type rawEntry struct {
+		name string
+		data []byte
+	}
+	var (
+		entries  []rawEntry
+		manifest *archiveManifest
+	)
+
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if !strings.HasSuffix(header.Name, ".json") {
+			continue
+		}
+
+		data, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to read data for %q: %w", header.Name, err)
+		}
+
+		if header.Name == manifestName {
+			manifest = &archiveManifest{}
+			if err := json.Unmarshal(data, manifest); err != nil {
+				return nil, nil, nil, fmt.Errorf("failed to unmarshal manifest: %w", err)
+			}
+			continue
+		}
+		entries = append(entries, rawEntry{name: header.Name, data: data})
+	}
+
+	if wantDigest != "" {
+		// Hash whatever follows the tar stream too, such as padding
+		if _, err := io.Copy(io.Discard, raw); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		got := hex.EncodeToString(hash.Sum(nil))
+		if !strings.EqualFold(got, strings.TrimPrefix(wantDigest, digestPrefix)) {
+			return nil, nil, nil, fmt.Errorf("%w: %s has digest %s%s", ErrArchiveDigestMismatch, name, digestPrefix, got)
+		}
+	}
+
+	if manifest != nil && manifest.RunID != "" {
+		ctrl.LoggerFrom(ctx).Info("Archive was written by backup run", "archive", name, "backupRunID", manifest.RunID)
+	}
+	if manifest != nil && manifest.Partial {
+		ctrl.LoggerFrom(ctx).Info("Archive is partial, it holds only the resources collected before its backup run was interrupted",
+			"archive", name)
+	}
+
+	// Manifests written before formats were recorded leave Compression empty
+	if manifest != nil && manifest.Compression != "" &&
+		(manifest.Compression != compression || manifest.Encryption != encryption) {
+		ctrl.LoggerFrom(ctx).Info("Archive format differs from the one it was written with, it may have been repackaged",
+			"archive", name,
+			"compression", compression, "writtenCompression", manifest.Compression,
+			"encryption", encryption, "writtenEncryption", manifest.Encryption)
+	}
+
+	format, err := archiveFormatOf(manifest)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var (
+		clusterResources    []archivedResource
+		namespacedResources []archivedResource
+	)
+	add := func(resource archivedResource) {
+		if resource.namespace == "" {
+			clusterResources = append(clusterResources, resource)
+		} else {
+			namespacedResources = append(namespacedResources, resource)
+		}
+	}
+
+	seen := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		seen[entry.name] = struct{}{}
+
+		gvr, namespace, name, err := format.parseEntry(entry.name)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to parse archive entry %q: %w", entry.name, err)
+		}
+		resource := archivedResource{gvr: gvr, namespace: namespace, name: name}
+
+		if manifest != nil {
+			if expected, ok := manifest.Files[entry.name]; !ok || expected != digest(entry.data) {
+				resource.err = fmt.Errorf("%w: %s", ErrChecksumMismatch, entry.name)
+				add(resource)
+				continue
+			}
+		}
+
+		var obj map[string]interface{}
+		if err := json.Unmarshal(entry.data, &obj); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to unmarshal %q: %w", entry.name, err)
+		}
+
+		if err := ensureMetadata(obj, name, namespace); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to prepare metadata for %q: %w", entry.name, err)
+		}
+
+		resource.object = obj
+		resource.err = checkArchivedType(resource)
+		add(resource)
+	}
+
+	if manifest != nil {
+		var missing []string
+		for entryName := range manifest.Files {
+			if _, ok := seen[entryName]; !ok {
+				missing = append(missing, entryName)
+			}
+		}
+		sort.Strings(missing)
+		for _, entryName := range missing {
+			gvr, namespace, name, err := format.parseEntry(entryName)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("failed to parse manifest entry %q: %w", entryName, err)
+			}
+			add(archivedResource{
+				gvr:       gvr,
+				namespace: namespace,
+				name:      name,
+				err:       fmt.Errorf("%w: %s", ErrEntryMissing, entryName),
+			})
+		}
+	}
+
+	return clusterResources, namespacedResources, manifest, nil
+}
+
+// applyResource creates the archived resource. When it already exists it is
+// updated, patched or kept depending on policy. Every write is made as the
+// field manager of apply.
+func (bm *BackupManager) applyResource(ctx context.Context, res archivedResource, policy ExistingResourcePolicy, apply metav1.ApplyOptions) (restoreOutcome, error) {
+	resourceClient := bm.resourceClientFor(res)
+
+	obj := &unstructured.Unstructured{Object: res.object}
+
+	if res.namespace != "" {
+		obj.SetNamespace(res.namespace)
+	}
+
+	_, err := resourceClient.Create(ctx, obj, metav1.CreateOptions{FieldManager: apply.FieldManager})
+	if err == nil {
+		return outcomeCreated, nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return outcomeFailed, fmt.Errorf("failed to create resource: %w", err)
+	}
+
+	switch policy {
+	case existingResourcePolicyKeep:
+		return outcomeSkipped, nil
+	case ExistingResourcePolicyPatch:
+		// Force takes over fields the archive sets from their current
+		// managers, which is what repairs drift
+		if _, err := resourceClient.Apply(ctx, obj.GetName(), obj, apply); err != nil {
+			if apierrors.IsConflict(err) && !apply.Force {
+				return outcomeFailed, fmt.Errorf("archived fields are owned by other field managers: %w", err)
+			}
+			return outcomeFailed, fmt.Errorf("failed to patch resource: %w", err)
+		}
+		return outcomeUpdated, nil
+	}
+
+	existing, err := resourceClient.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return outcomeFailed, fmt.Errorf("failed to fetch existing resource: %w", err)
+	}
+
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	if _, err := resourceClient.Update(ctx, obj, metav1.UpdateOptions{FieldManager: apply.FieldManager}); err != nil {
+		return outcomeFailed, fmt.Errorf("failed to update resource: %w", err)
+	}
+
+	return outcomeUpdated, nil
+}
+
+// resourceClientFor returns the client for the archived resource's type and
+// namespace.
+func (bm *BackupManager) resourceClientFor(res archivedResource) dynamic.ResourceInterface {
+	namespaceable := bm.DynamicClient.Resource(res.gvr)
+	if res.namespace != "" {
+		return namespaceable.Namespace(res.namespace)
+	}
+	return namespaceable
+}
+
+func ensureMetadata(obj map[string]interface{}, name, namespace string) error {
+	metaObj, ok := obj["metadata"].(map[string]interface{})
+	if !ok || metaObj == nil {
+		metaObj = map[string]interface{}{}
+		obj["metadata"] = metaObj
+	}
+
+	if existingName, ok := metaObj["name"].(string); ok && existingName != "" {
+		name = existingName
+	}
+
+	if name == "" {
+		return fmt.Errorf("resource missing metadata.name")
+	}
+	metaObj["name"] = name
+
+	if namespace != "" {
+		metaObj["namespace"] = namespace
+	}
+
+	return nil
+}
+
+func parseArchiveEntry(path string) (schema.GroupVersionResource, string, string, error) {
+	clean := filepath.ToSlash(filepath.Clean(path))
+	parts := strings.Split(clean, "/")
+	if len(parts) < 2 {
+		return schema.GroupVersionResource{}, "", "", fmt.Errorf("archive path %q is malformed", path)
+	}
+
+	name := strings.TrimSuffix(parts[len(parts)-1], ".json")
+	if name == "" {
+		return schema.GroupVersionResource{}, "", "", fmt.Errorf("archive entry %q missing resource name", path)
+	}
+
+	dirParts := parts[:len(parts)-1]
+	switch dirParts[0] {
+	case "cluster":
+		remainder := dirParts[1:]
+		var group, version, resource string
+		switch len(remainder) {
+		case 2:
+			group = ""
+			version = remainder[0]
+			resource = remainder[1]
+		case 3:
+			group = remainder[0]
+			version = remainder[1]
+			resource = remainder[2]
+		default:
+			return schema.GroupVersionResource{}, "", "", fmt.Errorf("unexpected cluster path format: %q", path)
+		}
+		return schema.GroupVersionResource{Group: group, Version: version, Resource: resource}, "", name, nil
+	case "namespaces":
+		if len(dirParts) < 3 {
+			return schema.GroupVersionResource{}, "", "", fmt.Errorf("unexpected namespaced path format: %q", path)
+		}
+		namespace := dirParts[1]
+		remainder := dirParts[2:]
+		var group, version, resource string
+		switch len(remainder) {
+		case 2:
+			group = ""
+			version = remainder[0]
+			resource = remainder[1]
+		case 3:
+			group = remainder[0]
+			version = remainder[1]
+			resource = remainder[2]
+		default:
+			return schema.GroupVersionResource{}, "", "", fmt.Errorf("unexpected namespaced path format: %q", path)
+		}
+		return schema.GroupVersionResource{Group: group, Version: version, Resource: resource}, namespace, name, nil
+	default:
+		return schema.GroupVersionResource{}, "", "", fmt.Errorf("unrecognised archive prefix %q", dirParts[0])
+	}
+}
@@ -0,0 +1,451 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	jsonpatch "gopkg.in/evanphx/json-patch.v4"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestRestoreBackup(t *testing.T) {
+	t.Parallel()
+
+	storageDir := t.TempDir()
+	archiveName := "cluster-backup-restore.tar.gz"
+	writeRestoreArchive(t, filepath.Join(storageDir, archiveName))
+
+	scheme := runtime.NewScheme()
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Namespace"})
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Group: "", Version: "v1", Kind: "ConfigMap"})
+
+	dynamicClient := fake.NewSimpleDynamicClient(scheme)
+	bm := &BackupManager{DynamicClient: dynamicClient}
+
+	result, err := bm.RestoreBackup(context.Background(), storageDir, archiveName, RestoreOptions{})
+	if err != nil {
+		t.Fatalf("RestoreBackup returned error: %v", err)
+	}
+
+	if result.ResourcesApplied != 2 {
+		t.Fatalf("expected 2 resources applied, got %d", result.ResourcesApplied)
+	}
+
+	namespaceGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "namespaces"}
+	if _, err := dynamicClient.Resource(namespaceGVR).Get(context.Background(), "restore-ns", metav1.GetOptions{}); err != nil {
+		t.Fatalf("expected namespace to exist: %v", err)
+	}
+
+	configMapGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "configmaps"}
+	cm, err := dynamicClient.Resource(configMapGVR).Namespace("restore-ns").Get(context.Background(), "sample-config", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected configmap to exist: %v", err)
+	}
+
+	if cm.GetNamespace() != "restore-ns" {
+		t.Fatalf("expected configmap namespace restore-ns, got %s", cm.GetNamespace())
+	}
+
+	result, err = bm.RestoreBackup(context.Background(), storageDir, archiveName, RestoreOptions{})
+	if err != nil {
+		t.Fatalf("second RestoreBackup returned error: %v", err)
+	}
+	if want := (RestoreCounts{Updated: 2}); result.RestoreCounts != want {
+		t.Fatalf("expected existing resources to be updated %+v, got %+v", want, result.RestoreCounts)
+	}
+}
+
+func TestRestoreBackupContinuesPastFailures(t *testing.T) {
+	t.Parallel()
+
+	storageDir := t.TempDir()
+	archiveName := "cluster-backup-restore.tar.gz"
+	writeRestoreArchive(t, filepath.Join(storageDir, archiveName))
+
+	newClient := func() *fake.FakeDynamicClient {
+		scheme := runtime.NewScheme()
+		registerUnstructuredType(scheme, schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Namespace"})
+		registerUnstructuredType(scheme, schema.GroupVersionKind{Group: "", Version: "v1", Kind: "ConfigMap"})
+
+		dynamicClient := fake.NewSimpleDynamicClient(scheme)
+		dynamicClient.PrependReactor("create", "namespaces", func(clienttesting.Action) (bool, runtime.Object, error) {
+			return true, nil, fmt.Errorf("admission denied")
+		})
+		return dynamicClient
+	}
+
+	bm := &BackupManager{DynamicClient: newClient()}
+	result, err := bm.RestoreBackup(context.Background(), storageDir, archiveName, RestoreOptions{
+		FailurePolicy: RestoreFailurePolicyContinue,
+	})
+	if err != nil {
+		t.Fatalf("RestoreBackup returned error: %v", err)
+	}
+	if result.ResourcesApplied != 1 {
+		t.Fatalf("expected 1 resource applied, got %d", result.ResourcesApplied)
+	}
+	if len(result.FailedItems) != 1 || result.FailedItems[0].Name != "restore-ns" {
+		t.Fatalf("expected namespace restore-ns to be reported as failed, got %v", result.FailedItems)
+	}
+	if want := (RestoreCounts{Created: 1, Failed: 1}); result.RestoreCounts != want {
+		t.Fatalf("expected counts %+v, got %+v", want, result.RestoreCounts)
+	}
+	if got := result.ByResource["namespaces"]; got == nil || got.Failed != 1 {
+		t.Fatalf("expected one failed namespace in per-resource counts, got %+v", got)
+	}
+	if got := result.ByResource["configmaps"]; got == nil || got.Created != 1 {
+		t.Fatalf("expected one created configmap in per-resource counts, got %+v", got)
+	}
+
+	bm = &BackupManager{DynamicClient: newClient()}
+	result, err = bm.RestoreBackup(context.Background(), storageDir, archiveName, RestoreOptions{
+		FailurePolicy: RestoreFailurePolicyFailFast,
+	})
+	var itemErr RestoreItemError
+	if !errors.As(err, &itemErr) || itemErr.Name != "restore-ns" {
+		t.Fatalf("expected fail-fast restore to abort on restore-ns, got %v", err)
+	}
+	if result == nil || len(result.FailedItems) != 1 || result.RestoreCounts != (RestoreCounts{Failed: 1}) {
+		t.Fatalf("expected the partial result to record the failed namespace, got %+v", result)
+	}
+}
+
+func TestRestoreBackupThrottlesApplies(t *testing.T) {
+	t.Parallel()
+
+	storageDir := t.TempDir()
+	archiveName := "cluster-backup-restore.tar.gz"
+	writeRestoreArchive(t, filepath.Join(storageDir, archiveName))
+
+	newManager := func() *BackupManager {
+		scheme := runtime.NewScheme()
+		registerUnstructuredType(scheme, schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Namespace"})
+		registerUnstructuredType(scheme, schema.GroupVersionKind{Group: "", Version: "v1", Kind: "ConfigMap"})
+		return &BackupManager{DynamicClient: fake.NewSimpleDynamicClient(scheme)}
+	}
+
+	start := time.Now()
+	result, err := newManager().RestoreBackup(context.Background(), storageDir, archiveName, RestoreOptions{ApplyRate: 10, ApplyBurst: 1})
+	if err != nil {
+		t.Fatalf("RestoreBackup returned error: %v", err)
+	}
+	if result.ResourcesApplied != 2 {
+		t.Fatalf("expected 2 resources applied, got %d", result.ResourcesApplied)
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("expected the second resource to wait for the throttle, took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = newManager().RestoreBackup(ctx, storageDir, archiveName, RestoreOptions{ApplyRate: 0.1, ApplyBurst: 1})
+	if err == nil {
+		t.Fatal("expected a restore outlasting its context to be interrupted")
+	}
+}
+
+func TestRestoreBackupVerifiesManifestChecksums(t *testing.T) {
+	t.Parallel()
+
+	// Build a genuine archive, then corrupt one entry and drop another while
+	// keeping the manifest intact
+	var original bytes.Buffer
+	archive := newArchiveWriter(&original)
+	objects := map[string]map[string]interface{}{
+		"cluster/v1/namespaces/restore-ns.json": {
+			"apiVersion": "v1", "kind": "Namespace",
+			"metadata": map[string]interface{}{"name": "restore-ns"},
+		},
+		"namespaces/restore-ns/v1/configmaps/intact.json": {
+			"apiVersion": "v1", "kind": "ConfigMap",
+			"metadata": map[string]interface{}{"name": "intact"},
+			"data":     map[string]interface{}{"key": "value"},
+		},
+		"namespaces/restore-ns/v1/configmaps/rotten.json": {
+			"apiVersion": "v1", "kind": "ConfigMap",
+			"metadata": map[string]interface{}{"name": "rotten"},
+			"data":     map[string]interface{}{"key": "value"},
+		},
+		"namespaces/restore-ns/v1/configmaps/truncated.json": {
+			"apiVersion": "v1", "kind": "ConfigMap",
+			"metadata": map[string]interface{}{"name": "truncated"},
+		},
+	}
+	for name, obj := range objects {
+		if err := archive.writeObject(name, obj); err != nil {
+			t.Fatalf("writeObject returned error: %v", err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("failed closing archive: %v", err)
+	}
+
+	storageDir := t.TempDir()
+	archiveName := "cluster-backup-corrupt.tar.gz"
+	file, err := os.Create(filepath.Join(storageDir, archiveName))
+	if err != nil {
+		t.Fatalf("failed to create archive: %v", err)
+	}
+	gz := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gz)
+	tarReader := tar.NewReader(&original)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed reading archive: %v", err)
+		}
+		data, err := io.ReadAll(tarReader)
+		if err != nil {
+			t.Fatalf("failed reading %s: %v", header.Name, err)
+		}
+		switch header.Name {
+		case "namespaces/restore-ns/v1/configmaps/truncated.json":
+			continue
+		case "namespaces/restore-ns/v1/configmaps/rotten.json":
+			data = bytes.Replace(data, []byte("value"), []byte("valuf"), 1)
+		}
+		if err := tarWriter.WriteHeader(&tar.Header{Name: header.Name, Mode: 0o644, Size: int64(len(data))}); err != nil {
+			t.Fatalf("failed to write tar header: %v", err)
+		}
+		if _, err := tarWriter.Write(data); err != nil {
+			t.Fatalf("failed to write tar data: %v", err)
+		}
+	}
+	for _, c := range []io.Closer{tarWriter, gz, file} {
+		if err := c.Close(); err != nil {
+			t.Fatalf("failed to finalize archive: %v", err)
+		}
+	}
+
+	scheme := runtime.NewScheme()
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Namespace"})
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Group: "", Version: "v1", Kind: "ConfigMap"})
+	dynamicClient := fake.NewSimpleDynamicClient(scheme)
+	bm := &BackupManager{DynamicClient: dynamicClient}
+
+	result, err := bm.RestoreBackup(context.Background(), storageDir, archiveName, RestoreOptions{})
+	if err != nil {
+		t.Fatalf("RestoreBackup returned error: %v", err)
+	}
+	if want := (RestoreCounts{Created: 2, Failed: 2}); result.RestoreCounts != want {
+		t.Fatalf("expected counts %+v, got %+v", want, result.RestoreCounts)
+	}
+
+	failed := map[string]error{}
+	for _, item := range result.FailedItems {
+		failed[item.Name] = item.Err
+	}
+	if !errors.Is(failed["rotten"], ErrChecksumMismatch) {
+		t.Fatalf("expected rotten to fail with a checksum mismatch, got %v", failed["rotten"])
+	}
+	if !errors.Is(failed["truncated"], ErrEntryMissing) {
+		t.Fatalf("expected truncated to be reported missing, got %v", failed["truncated"])
+	}
+
+	configMapGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "configmaps"}
+	if _, err := dynamicClient.Resource(configMapGVR).Namespace("restore-ns").Get(context.Background(), "rotten", metav1.GetOptions{}); err == nil {
+		t.Fatalf("expected corrupted configmap not to be applied")
+	}
+}
+
+func TestRestoreBackupExistingResourcePolicy(t *testing.T) {
+	t.Parallel()
+
+	storageDir := t.TempDir()
+	archiveName := "cluster-backup-restore.tar.gz"
+	writeRestoreArchive(t, filepath.Join(storageDir, archiveName))
+
+	configMapGVR := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	restoreData := func(policy ExistingResourcePolicy) map[string]string {
+		scheme := runtime.NewScheme()
+		registerUnstructuredType(scheme, schema.GroupVersionKind{Version: "v1", Kind: "Namespace"})
+		registerUnstructuredType(scheme, schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+		drifted := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1", "kind": "ConfigMap",
+			"metadata": map[string]interface{}{"name": "sample-config", "namespace": "restore-ns"},
+			"data":     map[string]interface{}{"key": "drifted", "injected": "by-controller"},
+		}}
+		dynamicClient := fake.NewSimpleDynamicClient(scheme, drifted)
+		// The fake tracker cannot apply unstructured objects; a merge patch
+		// sets the same fields server-side apply would for this archive
+		dynamicClient.PrependReactor("patch", "configmaps", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			patch := action.(clienttesting.PatchAction)
+			if patch.GetPatchType() != types.ApplyPatchType {
+				return false, nil, nil
+			}
+			current, err := dynamicClient.Tracker().Get(configMapGVR, patch.GetNamespace(), patch.GetName())
+			if err != nil {
+				return true, nil, err
+			}
+			currentJSON, err := json.Marshal(current)
+			if err != nil {
+				return true, nil, err
+			}
+			merged, err := jsonpatch.MergePatch(currentJSON, patch.GetPatch())
+			if err != nil {
+				return true, nil, err
+			}
+			obj := &unstructured.Unstructured{}
+			if err := obj.UnmarshalJSON(merged); err != nil {
+				return true, nil, err
+			}
+			return true, obj, dynamicClient.Tracker().Update(configMapGVR, obj, patch.GetNamespace())
+		})
+
+		bm := &BackupManager{DynamicClient: dynamicClient}
+		result, err := bm.RestoreBackup(context.Background(), storageDir, archiveName, RestoreOptions{ExistingResourcePolicy: policy})
+		if err != nil {
+			t.Fatalf("RestoreBackup returned error: %v", err)
+		}
+		if want := (RestoreCounts{Created: 1, Updated: 1}); result.RestoreCounts != want {
+			t.Fatalf("expected counts %+v with policy %q, got %+v (%v)", want, policy, result.RestoreCounts, result.FailedItems)
+		}
+
+		cm, err := dynamicClient.Resource(configMapGVR).Namespace("restore-ns").Get(context.Background(), "sample-config", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("expected configmap to exist: %v", err)
+		}
+		data, _, _ := unstructured.NestedStringMap(cm.Object, "data")
+		return data
+	}
+
+	if data := restoreData(ExistingResourcePolicyUpdate); data["key"] != "value" || data["injected"] != "" {
+		t.Fatalf("expected Update to replace the configmap with the archived data, got %v", data)
+	}
+	if data := restoreData(ExistingResourcePolicyPatch); data["key"] != "value" || data["injected"] != "by-controller" {
+		t.Fatalf("expected Patch to repair key and keep fields missing from the archive, got %v", data)
+	}
+}
+
+func TestRestoreOptionsApplyOptions(t *testing.T) {
+	t.Parallel()
+
+	if got := (RestoreOptions{}).applyOptions(); got.FieldManager != restoreFieldManager || !got.Force {
+		t.Fatalf("expected forced writes as %s by default, got %+v", restoreFieldManager, got)
+	}
+	got := RestoreOptions{FieldManager: "dr-restore", KeepConflictingFields: true}.applyOptions()
+	if got.FieldManager != "dr-restore" || got.Force {
+		t.Fatalf("expected unforced writes as dr-restore, got %+v", got)
+	}
+}
+
+func TestRestoreBackupReportsApplyConflicts(t *testing.T) {
+	t.Parallel()
+
+	storageDir := t.TempDir()
+	archiveName := "cluster-backup-restore.tar.gz"
+	writeRestoreArchive(t, filepath.Join(storageDir, archiveName))
+
+	scheme := runtime.NewScheme()
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Version: "v1", Kind: "Namespace"})
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+	existing := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1", "kind": "ConfigMap",
+		"metadata": map[string]interface{}{"name": "sample-config", "namespace": "restore-ns"},
+		"data":     map[string]interface{}{"key": "owned-by-gitops"},
+	}}
+	dynamicClient := fake.NewSimpleDynamicClient(scheme, existing)
+	dynamicClient.PrependReactor("patch", "configmaps", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewApplyConflict(nil, `Apply failed with 1 conflict: conflict with "argocd-controller": .data.key`)
+	})
+
+	bm := &BackupManager{DynamicClient: dynamicClient}
+	result, err := bm.RestoreBackup(context.Background(), storageDir, archiveName, RestoreOptions{
+		ExistingResourcePolicy: ExistingResourcePolicyPatch,
+		FieldManager:           "dr-restore",
+		KeepConflictingFields:  true,
+	})
+	if err != nil {
+		t.Fatalf("RestoreBackup returned error: %v", err)
+	}
+	if want := (RestoreCounts{Created: 1, Failed: 1}); result.RestoreCounts != want {
+		t.Fatalf("expected the conflicting configmap to fail %+v, got %+v", want, result.RestoreCounts)
+	}
+	if len(result.FailedItems) != 1 || !apierrors.IsConflict(result.FailedItems[0].Err) ||
+		!strings.Contains(result.FailedItems[0].Error(), "owned by other field managers") {
+		t.Fatalf("expected the conflict to be reported, got %v", result.FailedItems)
+	}
+}
+
+func writeRestoreArchive(t *testing.T, archivePath string) {
+	t.Helper()
+
+	file, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatalf("failed to create archive: %v", err)
+	}
+	defer file.Close()
+
+	gz := gzip.NewWriter(file)
+	defer gz.Close()
+
+	tarWriter := tar.NewWriter(gz)
+	defer tarWriter.Close()
+
+	writeJSONTarEntry(t, tarWriter, "cluster/v1/namespaces/restore-ns.json", map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata": map[string]interface{}{
+			"name": "restore-ns",
+		},
+	})
+
+	writeJSONTarEntry(t, tarWriter, "namespaces/restore-ns/v1/configmaps/sample-config.json", map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name": "sample-config",
+		},
+		"data": map[string]string{
+			"key": "value",
+		},
+	})
+}
+
+func writeJSONTarEntry(t *testing.T, tw *tar.Writer, name string, obj interface{}) {
+	data, err := json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal test object %s: %v", name, err)
+	}
+
+	header := &tar.Header{
+		Name: name,
+		Mode: 0o644,
+		Size: int64(len(data)),
+	}
+
+	if err := tw.WriteHeader(header); err != nil {
+		t.Fatalf("failed to write tar header %s: %v", name, err)
+	}
+
+	if _, err := tw.Write(data); err != nil {
+		t.Fatalf("failed to write tar data %s: %v", name, err)
+	}
+}
+
+func registerUnstructuredType(scheme *runtime.Scheme, gvk schema.GroupVersionKind) {
+	scheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+	listGVK := schema.GroupVersionKind{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind + "List"}
+	scheme.AddKnownTypeWithName(listGVK, &unstructured.UnstructuredList{})
+}
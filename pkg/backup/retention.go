@@ -0,0 +1,83 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// EnforceMaxTotalSize removes the oldest unpinned archives of storagePath
+// until the archives left add up to at most maxBytes. Pinned archives, bases
+// of incremental archives, archives read by a restore and the newest archive
+// are never removed but count towards the total, so the location may stay
+// above the quota. Locked archives that would have been removed are kept and
+// reported through an ImmutableArchivesError. It returns the archives
+// removed, also when it fails.
+func (bm *BackupManager) EnforceMaxTotalSize(storagePath string, maxBytes int64) ([]string, error) {
+	archives, err := bm.ListArchives(storagePath)
+	if err != nil {
+		return nil, err
+	}
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	bases := referencedBases(resolvedStoragePath)
+
+	sizes := make(map[string]int64, len(archives))
+	var total int64
+	for _, archive := range archives {
+		info, err := os.Stat(filepath.Join(resolvedStoragePath, archive))
+		if err != nil {
+			continue
+		}
+		sizes[archive] = info.Size()
+		total += info.Size()
+	}
+
+	var locked, removed []string
+	// Oldest first; the newest archive is the one just written
+	for _, archive := range archives[:max(len(archives)-1, 0)] {
+		if total <= maxBytes {
+			break
+		}
+		archivePath := filepath.Join(resolvedStoragePath, archive)
+		if _, ok := bases[archive]; ok || isPinned(archivePath) || bm.isReferenced(archivePath, now) {
+			continue
+		}
+		if checkArchiveMutable(archivePath, now) != nil {
+			locked = append(locked, archive)
+			continue
+		}
+		if err := removeArchive(archivePath); err != nil {
+			return removed, fmt.Errorf("failed to remove archive %q over the size quota: %w", archive, err)
+		}
+		removed = append(removed, archive)
+		total -= sizes[archive]
+	}
+
+	if len(locked) > 0 {
+		slices.Sort(locked)
+		return removed, &ImmutableArchivesError{Archives: locked}
+	}
+	return removed, nil
+}
@@ -0,0 +1,59 @@
+package backup
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEnforceMaxTotalSize(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	names := []string{
+		"cluster-backup-20250101-000000.tar.gz", // pinned
+		"cluster-backup-20250102-000000.tar.gz", // legal hold
+		"cluster-backup-20250103-000000.tar.gz",
+		"cluster-backup-20250104-000000.tar.gz",
+		"cluster-backup-20250105-000000.tar.gz",
+		"cluster-backup-20250106-000000.tar.gz",
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(strings.Repeat("x", 100)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, names[0]+keepSuffix), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := lockArchive(filepath.Join(dir, names[1]), Immutability{LegalHold: true}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	bm := &BackupManager{}
+	removed, err := bm.EnforceMaxTotalSize(dir, 350)
+	var immutable *ImmutableArchivesError
+	if !errors.As(err, &immutable) || !slices.Equal(immutable.Archives, names[1:2]) {
+		t.Fatalf("expected the locked archive to be reported, got %v", err)
+	}
+	if !slices.Equal(removed, names[2:5]) {
+		t.Fatalf("removed %v, want %v", removed, names[2:5])
+	}
+
+	// The newest archive stays even when the quota cannot be met
+	removed, err = bm.EnforceMaxTotalSize(dir, 0)
+	if !errors.As(err, &immutable) || len(removed) != 0 {
+		t.Fatalf("removed %v, %v", removed, err)
+	}
+	left, err := bm.ListArchives(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{names[0], names[1], names[5]}; !slices.Equal(left, want) {
+		t.Fatalf("left %v, want %v", left, want)
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// rotationSuffix names the sidecar file holding the rotation tag of an
+// archive.
+const rotationSuffix = ".rotation"
+
+// RotationTag classifies a scheduled run for count-based rotation.
+type RotationTag string
+
+const (
+	RotationDaily   RotationTag = "daily"
+	RotationWeekly  RotationTag = "weekly"
+	RotationMonthly RotationTag = "monthly"
+)
+
+// RotationTagFor tags a run fired at now: monthly for the first run of a
+// calendar month, weekly for the first run of an ISO week and daily
+// otherwise. previous is when the last run fired, zero if there was none.
+func RotationTagFor(now, previous time.Time) RotationTag {
+	now = now.UTC()
+	previous = previous.UTC()
+	if previous.IsZero() || now.Year() != previous.Year() || now.Month() != previous.Month() {
+		return RotationMonthly
+	}
+	year, week := now.ISOWeek()
+	previousYear, previousWeek := previous.ISOWeek()
+	if year != previousYear || week != previousWeek {
+		return RotationWeekly
+	}
+	return RotationDaily
+}
+
+// tagArchive records the rotation tag of an archive.
+func tagArchive(archivePath string, tag RotationTag) error {
+	return os.WriteFile(archivePath+rotationSuffix, []byte(string(tag)+"\n"), 0644)
+}
+
+// archiveRotationTag returns the rotation tag of an archive, or "" when it
+// was written without one.
+func archiveRotationTag(archivePath string) RotationTag {
+	data, err := os.ReadFile(archivePath + rotationSuffix)
+	if err != nil {
+		return ""
+	}
+	return RotationTag(strings.TrimSpace(string(data)))
+}
+
+// copyRotationTag gives the copy of an archive at dst the rotation tag of
+// the archive at src, if it has one.
+func copyRotationTag(src, dst string) error {
+	if tag := archiveRotationTag(src); tag != "" {
+		return tagArchive(dst, tag)
+	}
+	return nil
+}
+
+// ArchiveRotationTag returns the rotation tag of the named archive in
+// storagePath, or "" when it has none.
+func (bm *BackupManager) ArchiveRotationTag(storagePath, archiveName string) RotationTag {
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return ""
+	}
+	return archiveRotationTag(filepath.Join(resolvedStoragePath, archiveName))
+}
+
+// RotateArchives keeps the newest keep[tag] unpinned archives of each
+// rotation tag in storagePath and removes the rest. Archives without a tag
+// count as daily, and tags missing from keep are not pruned. Archives read by
+// a restore are kept until a later run. Locked archives are kept and
+// reported through an ImmutableArchivesError. It returns the archives
+// removed, also when it fails.
+func (bm *BackupManager) RotateArchives(storagePath string, keep map[RotationTag]int) ([]string, error) {
+	archives, err := bm.ListArchives(storagePath)
+	if err != nil {
+		return nil, err
+	}
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	bases := referencedBases(resolvedStoragePath)
+
+	var locked, removed []string
+	seen := map[RotationTag]int{}
+	// Newest first, so the archives beyond each count are the oldest
+	for _, archive := range slices.Backward(archives) {
+		archivePath := filepath.Join(resolvedStoragePath, archive)
+		if _, ok := bases[archive]; ok || isPinned(archivePath) {
+			continue
+		}
+		tag := archiveRotationTag(archivePath)
+		if tag == "" {
+			tag = RotationDaily
+		}
+		limit, ok := keep[tag]
+		seen[tag]++
+		if !ok || seen[tag] <= limit || bm.isReferenced(archivePath, now) {
+			continue
+		}
+		if checkArchiveMutable(archivePath, now) != nil {
+			locked = append(locked, archive)
+			continue
+		}
+		if err := removeArchive(archivePath); err != nil {
+			return removed, fmt.Errorf("failed to rotate out archive %q: %w", archive, err)
+		}
+		removed = append(removed, archive)
+	}
+
+	if len(locked) > 0 {
+		slices.Sort(locked)
+		return removed, &ImmutableArchivesError{Archives: locked}
+	}
+	return removed, nil
+}
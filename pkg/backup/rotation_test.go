@@ -0,0 +1,89 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotationTagFor(t *testing.T) {
+	t.Parallel()
+
+	at := func(value string) time.Time {
+		parsed, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+	for _, tc := range []struct {
+		now, previous string
+		want          RotationTag
+	}{
+		{now: "2025-03-04", want: RotationMonthly},
+		{now: "2025-03-01", previous: "2025-02-28", want: RotationMonthly},
+		{now: "2025-03-10", previous: "2025-03-09", want: RotationWeekly}, // Monday after Sunday
+		{now: "2025-03-11", previous: "2025-03-10", want: RotationDaily},
+	} {
+		var previous time.Time
+		if tc.previous != "" {
+			previous = at(tc.previous)
+		}
+		if got := RotationTagFor(at(tc.now), previous); got != tc.want {
+			t.Errorf("RotationTagFor(%s, %q) = %s, want %s", tc.now, tc.previous, got, tc.want)
+		}
+	}
+}
+
+func TestRotateArchives(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	archives := []struct {
+		name string
+		tag  RotationTag
+	}{
+		{"cluster-backup-20250201-000000.tar.gz", RotationMonthly},
+		{"cluster-backup-20250301-000000.tar.gz", RotationMonthly},
+		{"cluster-backup-20250303-000000.tar.gz", RotationWeekly},
+		{"cluster-backup-20250304-000000.tar.gz", ""},
+		{"cluster-backup-20250305-000000.tar.gz", RotationDaily},
+		{"cluster-backup-20250306-000000.tar.gz", RotationDaily},
+	}
+	for _, archive := range archives {
+		path := filepath.Join(dir, archive.name)
+		if err := os.WriteFile(path, []byte(archive.name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if archive.tag != "" {
+			if err := tagArchive(path, archive.tag); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// Untagged archives count as daily; weekly has no count and is kept
+	bm := &BackupManager{}
+	if _, err := bm.RotateArchives(dir, map[RotationTag]int{RotationDaily: 2, RotationMonthly: 1}); err != nil {
+		t.Fatalf("RotateArchives: %v", err)
+	}
+	kept := map[string]bool{
+		"cluster-backup-20250301-000000.tar.gz": true,
+		"cluster-backup-20250303-000000.tar.gz": true,
+		"cluster-backup-20250305-000000.tar.gz": true,
+		"cluster-backup-20250306-000000.tar.gz": true,
+	}
+	for _, archive := range archives {
+		_, err := os.Stat(filepath.Join(dir, archive.name))
+		if exists := err == nil; exists != kept[archive.name] {
+			t.Errorf("%s exists = %v, want %v", archive.name, exists, kept[archive.name])
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, archives[0].name+rotationSuffix)); !os.IsNotExist(err) {
+		t.Errorf("rotation tag of a removed archive was left behind: %v", err)
+	}
+	if got := bm.ArchiveRotationTag(dir, archives[1].name); got != RotationMonthly {
+		t.Errorf("ArchiveRotationTag = %q, want monthly", got)
+	}
+}
@@ -0,0 +1,327 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// RunbookOptions configures GenerateRunbook.
+type RunbookOptions struct {
+	// KeyWrappers decrypt encrypted archives, as for RestoreOptions.
+	KeyWrappers []KeyWrapper
+}
+
+// Runbook documents how to recover a cluster from an archive: the steps a
+// restore applies in order, the CRDs and commands it depends on and the
+// tasks left to operators.
+type Runbook struct {
+	Archive   string           `json:"archive"`
+	CreatedAt *time.Time       `json:"createdAt,omitempty"`
+	RunID     string           `json:"runID,omitempty"`
+	Cluster   *ClusterIdentity `json:"cluster,omitempty"`
+	Steps     []RunbookStep    `json:"steps"`
+	// RequiredCRDs lists the CustomResourceDefinitions of the archived
+	// custom resources.
+	RequiredCRDs []RequiredCRD `json:"requiredCRDs,omitempty"`
+	// Commands are run once the resources are applied, such as the
+	// `helm rollback` commands of archived Helm releases.
+	Commands []string `json:"commands,omitempty"`
+	// ManualTasks are the follow-ups a restore cannot take care of, such as
+	// pointing DNS at new load balancers.
+	ManualTasks []string `json:"manualTasks,omitempty"`
+}
+
+// RunbookStep is a group of resources applied together.
+type RunbookStep struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// Namespaces lists the namespaces the step applies resources in.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Resources counts the objects of each resource type.
+	Resources []RunbookResource `json:"resources"`
+}
+
+// RunbookResource counts the archived objects of a resource type.
+type RunbookResource struct {
+	Resource string `json:"resource"`
+	Count    int    `json:"count"`
+}
+
+// RequiredCRD is a CustomResourceDefinition archived custom resources need.
+type RequiredCRD struct {
+	Name string `json:"name"`
+	// InArchive is set when the archive holds the definition itself, so a
+	// restore brings it back. Otherwise it must be installed beforehand.
+	InArchive bool `json:"inArchive"`
+}
+
+// GenerateRunbook reads storagePath/archiveName, or the archive URL
+// archiveName, and describes how a restore would bring it back, without
+// contacting the cluster. Steps follow the order RestoreBackup applies
+// resources in.
+func (bm *BackupManager) GenerateRunbook(ctx context.Context, storagePath, archiveName string, opts RunbookOptions) (*Runbook, error) {
+	if !IsArchiveURL(archiveName) {
+		if err := ValidateArchiveName(archiveName); err != nil {
+			return nil, err
+		}
+	}
+	clusterResources, namespacedResources, manifest, err := bm.readStoredArchive(ctx, storagePath, archiveName,
+		RestoreOptions{KeyWrappers: opts.KeyWrappers}.keyWrappersFor(ctx), "")
+	if err != nil {
+		return nil, err
+	}
+
+	runbook := &Runbook{Archive: RedactArchiveURL(archiveName)}
+	if manifest != nil {
+		runbook.CreatedAt = &manifest.CreatedAt
+		runbook.RunID = manifest.RunID
+		runbook.Cluster = manifest.Cluster
+	}
+
+	var failed, generated int
+	var usable []archivedResource
+	for _, res := range append(clusterResources, namespacedResources...) {
+		switch {
+		case res.err != nil:
+			failed++
+		case isClusterGenerated(res.object):
+			generated++
+		default:
+			usable = append(usable, res)
+		}
+	}
+	clusterResources, namespacedResources = nil, nil
+	for _, res := range usable {
+		if res.namespace == "" {
+			clusterResources = append(clusterResources, res)
+		} else {
+			namespacedResources = append(namespacedResources, res)
+		}
+	}
+	clusterResources, webhookConfigurations := splitWebhookConfigurations(clusterResources)
+	clusterResources, certManagerResources := splitCertManagerResources(clusterResources)
+	namespacedResources, namespacedCertManager := splitCertManagerResources(namespacedResources)
+	certManagerResources = append(certManagerResources, namespacedCertManager...)
+
+	runbook.addStep("Restore cluster-scoped resources",
+		"Apply the CustomResourceDefinitions, namespaces, RBAC and other cluster-scoped resources.", clusterResources)
+	runbook.addStep("Restore namespaced resources",
+		"Apply the resources of every namespace once the CRDs above are established.", namespacedResources)
+	runbook.addStep("Restore cert-manager resources",
+		"Wait for the cert-manager CRDs to be established and cert-manager to run, then apply its issuers and "+
+			"certificates. Certificates are reissued if their Secrets were not archived.", certManagerResources)
+	runbook.addStep("Restore webhook configurations",
+		"Apply the admission webhook configurations last so their webhooks cannot reject the resources restored "+
+			"above, including their own backends.", webhookConfigurations)
+
+	runbook.RequiredCRDs = requiredCRDs(usable)
+	var releases []helmRelease
+	for _, res := range usable {
+		if release, ok := helmReleaseFromSecret(res.object); ok {
+			releases = append(releases, release)
+		}
+	}
+	runbook.Commands = helmRollbackCommands(releases)
+	runbook.ManualTasks = manualTasks(usable, runbook.RequiredCRDs, failed, generated)
+	return runbook, nil
+}
+
+// addStep appends a step applying resources, unless there are none.
+func (r *Runbook) addStep(title, description string, resources []archivedResource) {
+	if len(resources) == 0 {
+		return
+	}
+	counts := map[string]int{}
+	namespaces := map[string]struct{}{}
+	for _, res := range resources {
+		counts[res.gvr.GroupResource().String()]++
+		if res.namespace != "" {
+			namespaces[res.namespace] = struct{}{}
+		}
+	}
+	step := RunbookStep{Title: title, Description: description, Namespaces: slices.Sorted(maps.Keys(namespaces))}
+	for _, resource := range slices.Sorted(maps.Keys(counts)) {
+		step.Resources = append(step.Resources, RunbookResource{Resource: resource, Count: counts[resource]})
+	}
+	r.Steps = append(r.Steps, step)
+}
+
+// requiredCRDs lists the definitions of the custom resources among
+// resources. Resources of groups without a dot and of *.k8s.io groups are
+// served by Kubernetes itself unless the archive holds their definition.
+func requiredCRDs(resources []archivedResource) []RequiredCRD {
+	archived := map[string]struct{}{}
+	for _, res := range resources {
+		if res.gvr.GroupResource() == crdGVR.GroupResource() {
+			archived[res.name] = struct{}{}
+		}
+	}
+	needed := map[string]bool{}
+	for _, res := range resources {
+		name := res.gvr.GroupResource().String()
+		_, inArchive := archived[name]
+		if inArchive || (strings.Contains(res.gvr.Group, ".") && !strings.HasSuffix(res.gvr.Group, ".k8s.io")) {
+			needed[name] = inArchive
+		}
+	}
+	crds := make([]RequiredCRD, 0, len(needed))
+	for _, name := range slices.Sorted(maps.Keys(needed)) {
+		crds = append(crds, RequiredCRD{Name: name, InArchive: needed[name]})
+	}
+	return crds
+}
+
+// runbookTaskObjects caps the objects named by a manual task.
+const runbookTaskObjects = 5
+
+var (
+	servicesResource  = schema.GroupResource{Resource: "services"}
+	ingressesResource = schema.GroupResource{Group: "networking.k8s.io", Resource: "ingresses"}
+	pvsResource       = schema.GroupResource{Resource: "persistentvolumes"}
+)
+
+// manualTasks lists what operators have to take care of around a restore
+// of resources.
+func manualTasks(resources []archivedResource, crds []RequiredCRD, failed, generated int) []string {
+	var tasks []string
+	for _, crd := range crds {
+		if !crd.InArchive {
+			tasks = append(tasks, fmt.Sprintf("Install the CustomResourceDefinition %s, e.g. with the chart of its operator, "+
+				"before restoring namespaced resources.", crd.Name))
+		}
+	}
+
+	var loadBalancers, hosts, claims, volumes []string
+	for _, res := range resources {
+		object := res.name
+		if res.namespace != "" {
+			object = res.namespace + "/" + res.name
+		}
+		switch res.gvr.GroupResource() {
+		case servicesResource:
+			if serviceType, _, _ := unstructured.NestedString(res.object, "spec", "type"); serviceType == "LoadBalancer" {
+				loadBalancers = append(loadBalancers, object)
+			}
+		case ingressesResource:
+			rules, _, _ := unstructured.NestedSlice(res.object, "spec", "rules")
+			for _, rule := range rules {
+				if host, ok := rule.(map[string]interface{})["host"].(string); ok && host != "" {
+					hosts = append(hosts, host)
+				}
+			}
+		case pvcsResource.GroupResource():
+			claims = append(claims, object)
+		case pvsResource:
+			volumes = append(volumes, object)
+		}
+	}
+	if len(loadBalancers) > 0 {
+		tasks = append(tasks, fmt.Sprintf("Point external DNS records at the new addresses of the LoadBalancer Services %s, "+
+			"unless external-dns manages them.", summarizeObjects(loadBalancers)))
+	}
+	if len(hosts) > 0 {
+		slices.Sort(hosts)
+		tasks = append(tasks, fmt.Sprintf("Check that the Ingress hosts %s resolve to the new ingress controller.",
+			summarizeObjects(slices.Compact(hosts))))
+	}
+	if len(claims) > 0 {
+		tasks = append(tasks, fmt.Sprintf("Restore the data of the PersistentVolumeClaims %s from volume snapshots or "+
+			"application backups; archives hold their definitions only.", summarizeObjects(claims)))
+	}
+	if len(volumes) > 0 {
+		tasks = append(tasks, fmt.Sprintf("Check that the storage behind the PersistentVolumes %s still exists, or remove them "+
+			"so their claims are provisioned again.", summarizeObjects(volumes)))
+	}
+	if generated > 0 {
+		tasks = append(tasks, fmt.Sprintf("%d service account tokens and root CA ConfigMaps are not restored; "+
+			"the cluster generates them again.", generated))
+	}
+	if failed > 0 {
+		tasks = append(tasks, fmt.Sprintf("%d archive entries failed verification and cannot be restored; "+
+			"restore them from another archive.", failed))
+	}
+	return tasks
+}
+
+// summarizeObjects joins the first few objects, counting the rest.
+func summarizeObjects(objects []string) string {
+	if len(objects) <= runbookTaskObjects {
+		return strings.Join(objects, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(objects[:runbookTaskObjects], ", "), len(objects)-runbookTaskObjects)
+}
+
+// Markdown renders the runbook as a Markdown document.
+func (r *Runbook) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Recovery runbook for %s\n", r.Archive)
+	if r.CreatedAt != nil || r.RunID != "" || r.Cluster != nil {
+		b.WriteString("\n")
+	}
+	if r.CreatedAt != nil {
+		fmt.Fprintf(&b, "- Taken at: %s\n", r.CreatedAt.UTC().Format(time.RFC3339))
+	}
+	if r.RunID != "" {
+		fmt.Fprintf(&b, "- Backup run: %s\n", r.RunID)
+	}
+	if r.Cluster != nil {
+		fmt.Fprintf(&b, "- Source cluster: %s\n", r.Cluster)
+	}
+
+	if len(r.RequiredCRDs) > 0 {
+		b.WriteString("\n## Required CRDs\n\n")
+		for _, crd := range r.RequiredCRDs {
+			source := "install before restoring"
+			if crd.InArchive {
+				source = "restored from the archive"
+			}
+			fmt.Fprintf(&b, "- `%s` (%s)\n", crd.Name, source)
+		}
+	}
+
+	b.WriteString("\n## Steps\n")
+	for i, step := range r.Steps {
+		fmt.Fprintf(&b, "\n### %d. %s\n\n%s\n\n", i+1, step.Title, step.Description)
+		if len(step.Namespaces) > 0 {
+			fmt.Fprintf(&b, "Namespaces: %s\n\n", strings.Join(step.Namespaces, ", "))
+		}
+		b.WriteString("| Resource | Objects |\n|---|---|\n")
+		for _, resource := range step.Resources {
+			fmt.Fprintf(&b, "| %s | %d |\n", resource.Resource, resource.Count)
+		}
+	}
+	if len(r.Commands) > 0 {
+		fmt.Fprintf(&b, "\n### %d. Run follow-up commands\n\n```sh\n%s\n```\n", len(r.Steps)+1, strings.Join(r.Commands, "\n"))
+	}
+
+	if len(r.ManualTasks) > 0 {
+		b.WriteString("\n## Manual tasks\n\n")
+		for _, task := range r.ManualTasks {
+			fmt.Fprintf(&b, "- [ ] %s\n", task)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,101 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateRunbook(t *testing.T) {
+	t.Parallel()
+
+	storageDir := t.TempDir()
+	archiveName := "cluster-backup-20260101-000000.tar.gz"
+	file, err := os.Create(filepath.Join(storageDir, archiveName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+	object := func(apiVersion, kind, namespace, name string, fields map[string]interface{}) map[string]interface{} {
+		metadata := map[string]interface{}{"name": name}
+		if namespace != "" {
+			metadata["namespace"] = namespace
+		}
+		obj := map[string]interface{}{"apiVersion": apiVersion, "kind": kind, "metadata": metadata}
+		for key, value := range fields {
+			obj[key] = value
+		}
+		return obj
+	}
+	writeJSONTarEntry(t, tw, "cluster/v1/namespaces/shop.json", object("v1", "Namespace", "", "shop", nil))
+	writeJSONTarEntry(t, tw, "cluster/apiextensions.k8s.io/v1/customresourcedefinitions/widgets.example.com.json",
+		object("apiextensions.k8s.io/v1", "CustomResourceDefinition", "", "widgets.example.com", nil))
+	writeJSONTarEntry(t, tw, "cluster/admissionregistration.k8s.io/v1/validatingwebhookconfigurations/shop.json",
+		object("admissionregistration.k8s.io/v1", "ValidatingWebhookConfiguration", "", "shop", nil))
+	writeJSONTarEntry(t, tw, "namespaces/shop/example.com/v1/widgets/blue.json",
+		object("example.com/v1", "Widget", "shop", "blue", nil))
+	writeJSONTarEntry(t, tw, "namespaces/shop/other.io/v1/gadgets/red.json",
+		object("other.io/v1", "Gadget", "shop", "red", nil))
+	writeJSONTarEntry(t, tw, "namespaces/shop/v1/services/front.json",
+		object("v1", "Service", "shop", "front", map[string]interface{}{"spec": map[string]interface{}{"type": "LoadBalancer"}}))
+	writeJSONTarEntry(t, tw, "namespaces/shop/v1/persistentvolumeclaims/data.json",
+		object("v1", "PersistentVolumeClaim", "shop", "data", nil))
+	writeJSONTarEntry(t, tw, "namespaces/shop/cert-manager.io/v1/certificates/front.json",
+		object("cert-manager.io/v1", "Certificate", "shop", "front", nil))
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	bm := &BackupManager{}
+	runbook, err := bm.GenerateRunbook(context.Background(), storageDir, archiveName, RunbookOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var titles []string
+	for _, step := range runbook.Steps {
+		titles = append(titles, step.Title)
+	}
+	want := []string{"Restore cluster-scoped resources", "Restore namespaced resources",
+		"Restore cert-manager resources", "Restore webhook configurations"}
+	if strings.Join(titles, "|") != strings.Join(want, "|") {
+		t.Fatalf("steps = %v, want %v", titles, want)
+	}
+	if namespaced := runbook.Steps[1]; len(namespaced.Resources) != 4 || strings.Join(namespaced.Namespaces, ",") != "shop" {
+		t.Fatalf("namespaced step = %+v", namespaced)
+	}
+
+	crds, _ := json.Marshal(runbook.RequiredCRDs)
+	if string(crds) != `[{"name":"certificates.cert-manager.io","inArchive":false},{"name":"gadgets.other.io","inArchive":false},{"name":"widgets.example.com","inArchive":true}]` {
+		t.Fatalf("required CRDs = %s", crds)
+	}
+
+	tasks := strings.Join(runbook.ManualTasks, "\n")
+	for _, task := range []string{"gadgets.other.io", "LoadBalancer Services shop/front", "PersistentVolumeClaims shop/data"} {
+		if !strings.Contains(tasks, task) {
+			t.Errorf("manual tasks do not mention %q:\n%s", task, tasks)
+		}
+	}
+	if strings.Contains(tasks, "widgets.example.com") {
+		t.Errorf("archived CRD listed as a manual task:\n%s", tasks)
+	}
+
+	markdown := runbook.Markdown()
+	for _, section := range []string{"# Recovery runbook for " + archiveName, "## Required CRDs", "### 4. Restore webhook configurations", "- [ ] "} {
+		if !strings.Contains(markdown, section) {
+			t.Errorf("markdown is missing %q:\n%s", section, markdown)
+		}
+	}
+}
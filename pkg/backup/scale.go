@@ -0,0 +1,63 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// OriginalReplicasAnnotation records on a workload restored with
+// ScaleWorkloadsToZero how many replicas it was archived with.
+const OriginalReplicasAnnotation = "backup.backup.io/original-replicas"
+
+// isScalableWorkload reports whether res is a Deployment or StatefulSet.
+func isScalableWorkload(res archivedResource) bool {
+	if res.gvr.Group != "apps" {
+		return false
+	}
+	return res.gvr.Resource == "deployments" || res.gvr.Resource == "statefulsets"
+}
+
+// scaleToZero sets the replicas of the Deployments and StatefulSets among
+// resources to zero, recording the archived count in
+// OriginalReplicasAnnotation. Workloads that already carry the annotation,
+// such as those archived after an earlier scaled-down restore, keep it.
+func scaleToZero(resources []archivedResource) int {
+	scaled := 0
+	for _, res := range resources {
+		if res.err != nil || !isScalableWorkload(res) {
+			continue
+		}
+		obj := &unstructured.Unstructured{Object: res.object}
+		annotations := obj.GetAnnotations()
+		if _, ok := annotations[OriginalReplicasAnnotation]; !ok {
+			replicas := workloadReplicas(res.object)
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations[OriginalReplicasAnnotation] = strconv.FormatInt(replicas, 10)
+			obj.SetAnnotations(annotations)
+		}
+		if err := unstructured.SetNestedField(res.object, int64(0), "spec", "replicas"); err != nil {
+			continue
+		}
+		scaled++
+	}
+	return scaled
+}
@@ -0,0 +1,84 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestRestoreScalesWorkloadsToZero(t *testing.T) {
+	t.Parallel()
+
+	workload := func(kind, name string, replicas interface{}, annotations map[string]interface{}) map[string]interface{} {
+		metadata := map[string]interface{}{"name": name, "namespace": "shop"}
+		if annotations != nil {
+			metadata["annotations"] = annotations
+		}
+		spec := map[string]interface{}{}
+		if replicas != nil {
+			spec["replicas"] = replicas
+		}
+		return map[string]interface{}{"apiVersion": "apps/v1", "kind": kind, "metadata": metadata, "spec": spec}
+	}
+
+	storageDir := t.TempDir()
+	archiveName := "cluster-backup-scaled.tar.gz"
+	file, err := os.Create(filepath.Join(storageDir, archiveName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gz)
+	writeJSONTarEntry(t, tarWriter, "namespaces/shop/apps/v1/deployments/web.json", workload("Deployment", "web", 3, nil))
+	writeJSONTarEntry(t, tarWriter, "namespaces/shop/apps/v1/deployments/worker.json", workload("Deployment", "worker", nil, nil))
+	writeJSONTarEntry(t, tarWriter, "namespaces/shop/apps/v1/statefulsets/db.json",
+		workload("StatefulSet", "db", 0, map[string]interface{}{OriginalReplicasAnnotation: "2"}))
+	writeJSONTarEntry(t, tarWriter, "namespaces/shop/v1/configmaps/settings.json", configMapObject("shop", "settings").Object)
+	for _, closer := range []interface{ Close() error }{tarWriter, gz, file} {
+		if err := closer.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scheme := runtime.NewScheme()
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "StatefulSet"})
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+	bm := &BackupManager{DynamicClient: fake.NewSimpleDynamicClient(scheme)}
+	result, err := bm.RestoreBackup(context.Background(), storageDir, archiveName, RestoreOptions{ScaleWorkloadsToZero: true})
+	if err != nil {
+		t.Fatalf("RestoreBackup returned error: %v", err)
+	}
+	if result.Created != 4 {
+		t.Fatalf("restore counts = %+v, want 4 created", result.RestoreCounts)
+	}
+
+	for _, tc := range []struct {
+		resource, name, original string
+	}{
+		{"deployments", "web", "3"},
+		{"deployments", "worker", "1"},
+		{"statefulsets", "db", "2"},
+	} {
+		obj, err := bm.DynamicClient.Resource(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: tc.resource}).
+			Namespace("shop").Get(context.Background(), tc.name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if replicas, _, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas"); replicas != 0 {
+			t.Errorf("%s/%s restored with %d replicas, want 0", tc.resource, tc.name, replicas)
+		}
+		if got := obj.GetAnnotations()[OriginalReplicasAnnotation]; got != tc.original {
+			t.Errorf("%s/%s original replicas = %q, want %q", tc.resource, tc.name, got, tc.original)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Schedule is a parsed ClusterBackup schedule.
+type Schedule interface {
+	// Next returns the first time after t the schedule runs, or the zero
+	// time when it never runs again.
+	Next(t time.Time) time.Time
+}
+
+// cronParser accepts five-field cron expressions and macros such as @daily.
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ParseSchedule parses a schedule: either a duration such as "24h", which
+// runs that long after the previous run, or a five-field cron expression or
+// macro such as "@daily", which runs at the times it matches in UTC.
+func ParseSchedule(schedule string) (Schedule, error) {
+	if d, err := time.ParseDuration(schedule); err == nil && d > 0 {
+		return IntervalSchedule(d), nil
+	}
+	if strings.Contains(schedule, "TZ=") || strings.HasPrefix(schedule, "@every") {
+		return nil, fmt.Errorf("invalid schedule %q: time zones and @every are not supported", schedule)
+	}
+	parsed, err := cronParser.Parse(schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", schedule, err)
+	}
+	return utcSchedule{parsed}, nil
+}
+
+// IntervalSchedule runs a fixed duration after the previous run.
+type IntervalSchedule time.Duration
+
+// Next returns t plus the interval.
+func (s IntervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(s))
+}
+
+// utcSchedule evaluates a cron schedule in UTC, whatever the local time
+// zone of the operator.
+type utcSchedule struct {
+	cron.Schedule
+}
+
+func (s utcSchedule) Next(t time.Time) time.Time {
+	return s.Schedule.Next(t.UTC())
+}
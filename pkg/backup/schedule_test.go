@@ -0,0 +1,44 @@
+package backup
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	t.Parallel()
+
+	last := time.Date(2025, time.January, 3, 1, 30, 0, 0, time.UTC)
+	for schedule, want := range map[string]time.Time{
+		"6h":                   last.Add(6 * time.Hour),
+		"0 2 * * *":            time.Date(2025, time.January, 3, 2, 0, 0, 0, time.UTC),
+		"*/15 0-6 * * MON-FRI": time.Date(2025, time.January, 3, 1, 45, 0, 0, time.UTC),
+		"0 3 * * SAT":          time.Date(2025, time.January, 4, 3, 0, 0, 0, time.UTC),
+		"@daily":               time.Date(2025, time.January, 4, 0, 0, 0, 0, time.UTC),
+		"@monthly":             time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		parsed, err := ParseSchedule(schedule)
+		if err != nil {
+			t.Fatalf("ParseSchedule(%q) returned error: %v", schedule, err)
+		}
+		if got := parsed.Next(last); !got.Equal(want) {
+			t.Fatalf("expected %q to run next at %s, got %s", schedule, want, got)
+		}
+	}
+
+	// Cron expressions are evaluated in UTC whatever the zone of the time
+	parsed, err := ParseSchedule("0 2 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := last.In(time.FixedZone("UTC+5", 5*60*60))
+	if got := parsed.Next(local); !got.Equal(time.Date(2025, time.January, 3, 2, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the next run at 02:00 UTC, got %s", got)
+	}
+
+	for _, schedule := range []string{"", "0", "-1h", "every night", "0 2 * *", "61 * * * *", "0 2 * * * *", "@fortnightly", "@every 1h", "CRON_TZ=Europe/Berlin 0 2 * * *"} {
+		if _, err := ParseSchedule(schedule); err == nil {
+			t.Fatalf("expected %q to be rejected", schedule)
+		}
+	}
+}
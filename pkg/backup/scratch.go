@@ -0,0 +1,100 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// ErrInsufficientScratchSpace is returned before a backup is staged when the
+// temp directory has less free space than the archive is expected to need.
+var ErrInsufficientScratchSpace = errors.New("insufficient scratch space")
+
+// SetMinScratchSpace makes backups fail before anything is staged unless the
+// temp directory has at least bytes free. Backups also require half again the
+// size of the largest archive in their storage location. It must be called
+// before the manager is used.
+func (bm *BackupManager) SetMinScratchSpace(bytes int64) {
+	if bm.tempDirs == nil {
+		bm.tempDirs = &tempDirs{active: map[string]struct{}{}}
+	}
+	bm.tempDirs.minFree = bytes
+}
+
+// checkScratchSpace fails with ErrInsufficientScratchSpace when dir has less
+// free space than a backup to storagePath is expected to stage
+func (bm *BackupManager) checkScratchSpace(ctx context.Context, dir, storagePath string) error {
+	free, ok := freeBytes(dir)
+	if !ok {
+		return nil
+	}
+
+	var required int64
+	if bm.tempDirs != nil {
+		required = bm.tempDirs.minFree
+	}
+	if largest := bm.largestArchiveSize(storagePath); largest+largest/2 > required {
+		required = largest + largest/2
+	}
+	ctrl.LoggerFrom(ctx).V(1).Info("Checked scratch space", "path", dir, "free", free, "required", required)
+	if free < required {
+		return fmt.Errorf("%w: %d bytes free in %s, about %d needed", ErrInsufficientScratchSpace, free, dir, required)
+	}
+	return nil
+}
+
+// scratchUsage returns the bytes used by the files below dir. Files removed
+// while it runs are left out.
+func scratchUsage(dir string) int64 {
+	var used int64
+	_ = filepath.WalkDir(dir, func(_ string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil && info.Mode().IsRegular() {
+			used += info.Size()
+		}
+		return nil
+	})
+	return used
+}
+
+// largestArchiveSize returns the size of the largest archive in
+// storagePath, or 0 when it holds none or cannot be read
+func (bm *BackupManager) largestArchiveSize(storagePath string) int64 {
+	archives, err := bm.ListArchives(storagePath)
+	if err != nil || len(archives) == 0 {
+		return 0
+	}
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return 0
+	}
+	var largest int64
+	for _, name := range archives {
+		if info, err := os.Stat(filepath.Join(resolvedStoragePath, name)); err == nil {
+			largest = max(largest, info.Size())
+		}
+	}
+	return largest
+}
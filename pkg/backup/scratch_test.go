@@ -0,0 +1,43 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckScratchSpace(t *testing.T) {
+	t.Parallel()
+
+	scratch := t.TempDir()
+	if _, ok := freeBytes(scratch); !ok {
+		t.Skip("free space cannot be determined on this platform")
+	}
+
+	storageDir := t.TempDir()
+	for name, size := range map[string]int{
+		"cluster-backup-20250101-000000.tar.gz": 100,
+		"cluster-backup-20250102-000000.tar.gz": 400,
+		"notes.txt":                             1000,
+	} {
+		if err := os.WriteFile(filepath.Join(storageDir, name), make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bm := &BackupManager{}
+	if got := bm.largestArchiveSize(storageDir); got != 400 {
+		t.Fatalf("expected the largest archive to be 400 bytes, got %d", got)
+	}
+	if err := bm.checkScratchSpace(context.Background(), scratch, storageDir); err != nil {
+		t.Fatalf("expected enough scratch space for a small archive, got %v", err)
+	}
+
+	bm.SetMinScratchSpace(1 << 62)
+	err := bm.checkScratchSpace(context.Background(), scratch, storageDir)
+	if !errors.Is(err, ErrInsufficientScratchSpace) {
+		t.Fatalf("expected ErrInsufficientScratchSpace, got %v", err)
+	}
+}
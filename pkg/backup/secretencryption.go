@@ -0,0 +1,268 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// SecretFormat selects how Secrets are written into archives.
+type SecretFormat string
+
+const (
+	// SecretFormatSealedSecrets writes every Secret as a Bitnami
+	// SealedSecret sealed to the cluster's sealing certificate. Restoring
+	// the archive creates the SealedSecrets, which the sealed-secrets
+	// controller unseals into Secrets.
+	SecretFormatSealedSecrets SecretFormat = "SealedSecrets"
+	// SecretFormatSOPS writes every Secret with its data encrypted in the
+	// SOPS format to age recipients. Restores decrypt them with the age
+	// identities of RestoreOptions.KeyWrappers.
+	SecretFormatSOPS SecretFormat = "SOPS"
+)
+
+// DefaultSealedSecretsNamespace is where the sealed-secrets controller keeps
+// its sealing keys by default.
+const DefaultSealedSecretsNamespace = "kube-system"
+
+// sealedSecretsKeyLabel marks the Secrets holding sealed-secrets keys.
+const sealedSecretsKeyLabel = "sealedsecrets.bitnami.com/sealed-secrets-key"
+
+var sealedSecretsResource = schema.GroupVersionResource{Group: "bitnami.com", Version: "v1alpha1", Resource: "sealedsecrets"}
+
+// SecretEncryption configures how Secrets are encrypted on their own, so
+// the archive never holds plaintext credentials even when the archive as a
+// whole is not encrypted.
+type SecretEncryption struct {
+	Format SecretFormat
+
+	// SealingCertificate is the PEM encoded certificate SealedSecrets are
+	// sealed to. When empty, the active key of the sealed-secrets
+	// controller in SealedSecretsNamespace is used.
+	SealingCertificate string
+	// SealedSecretsNamespace defaults to DefaultSealedSecretsNamespace.
+	SealedSecretsNamespace string
+
+	// AgeRecipients are the age public keys SOPS data keys are encrypted to.
+	AgeRecipients []string
+}
+
+// secretEncrypter rewrites a Secret before it is written into an archive.
+type secretEncrypter interface {
+	// encryptSecret returns the entry name and object to archive for the
+	// Secret obj archived as name.
+	encryptSecret(name string, obj map[string]interface{}) (string, map[string]interface{}, error)
+}
+
+// newSecretEncrypter returns the secretEncrypter for opts.
+func (bm *BackupManager) newSecretEncrypter(ctx context.Context, opts *SecretEncryption) (secretEncrypter, error) {
+	switch opts.Format {
+	case SecretFormatSealedSecrets:
+		certificate := opts.SealingCertificate
+		if certificate == "" {
+			var err error
+			if certificate, err = bm.sealingCertificate(ctx, opts.SealedSecretsNamespace); err != nil {
+				return nil, err
+			}
+		}
+		key, err := ParseSealingCertificate(certificate)
+		if err != nil {
+			return nil, err
+		}
+		return &secretSealer{key: key}, nil
+	case SecretFormatSOPS:
+		return newSOPSEncrypter(opts.AgeRecipients)
+	default:
+		return nil, fmt.Errorf("unsupported secret format %q", opts.Format)
+	}
+}
+
+// isSecret reports whether obj is a core Secret.
+func isSecret(obj map[string]interface{}) bool {
+	apiVersion, _ := obj["apiVersion"].(string)
+	kind, _ := obj["kind"].(string)
+	return apiVersion == "v1" && kind == "Secret"
+}
+
+// ParseSealingCertificate returns the RSA public key of a PEM encoded
+// sealed-secrets certificate.
+func ParseSealingCertificate(certificate string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(certificate))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("sealing certificate is not a PEM encoded certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sealing certificate: %w", err)
+	}
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("sealing certificate has a %T key, not RSA", cert.PublicKey)
+	}
+	return key, nil
+}
+
+// sealingCertificate returns the certificate of the newest active sealing
+// key of the sealed-secrets controller in namespace.
+func (bm *BackupManager) sealingCertificate(ctx context.Context, namespace string) (string, error) {
+	if namespace == "" {
+		namespace = DefaultSealedSecretsNamespace
+	}
+	list, err := bm.DynamicClient.Resource(secretsResource).Namespace(namespace).List(ctx,
+		metav1.ListOptions{LabelSelector: sealedSecretsKeyLabel + "=active"})
+	if err != nil {
+		return "", fmt.Errorf("failed to list sealed-secrets keys in %s: %w", namespace, err)
+	}
+	keys := list.Items
+	if len(keys) == 0 {
+		return "", fmt.Errorf("no active sealed-secrets key found in %s", namespace)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		newer, older := keys[i].GetCreationTimestamp(), keys[j].GetCreationTimestamp()
+		return older.Before(&newer)
+	})
+	encoded := nestedString(keys[0].Object, "data", "tls.crt")
+	certificate, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(certificate) == 0 {
+		return "", fmt.Errorf("sealed-secrets key %s/%s has no certificate", namespace, keys[0].GetName())
+	}
+	return string(certificate), nil
+}
+
+// secretSealer writes Secrets as SealedSecrets with the default strict
+// scope, so they can only be unsealed under their own name and namespace.
+type secretSealer struct {
+	key *rsa.PublicKey
+}
+
+func (s *secretSealer) encryptSecret(name string, obj map[string]interface{}) (string, map[string]interface{}, error) {
+	secretName := nestedString(obj, "metadata", "name")
+	namespace := nestedString(obj, "metadata", "namespace")
+	label := []byte(namespace + "/" + secretName)
+
+	encryptedData := map[string]interface{}{}
+	data, _ := obj["data"].(map[string]interface{})
+	for key, value := range data {
+		encoded, _ := value.(string)
+		plaintext, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to decode key %q of Secret %s: %w", key, secretName, err)
+		}
+		sealed, err := sealValue(s.key, plaintext, label)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to seal key %q of Secret %s: %w", key, secretName, err)
+		}
+		encryptedData[key] = base64.StdEncoding.EncodeToString(sealed)
+	}
+
+	templateMetadata := map[string]interface{}{"name": secretName, "namespace": namespace}
+	if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+		for _, field := range []string{"labels", "annotations"} {
+			if value, ok := metadata[field]; ok {
+				templateMetadata[field] = value
+			}
+		}
+	}
+	template := map[string]interface{}{"metadata": withoutLastApplied(templateMetadata)}
+	for _, field := range []string{"type", "immutable"} {
+		if value, ok := obj[field]; ok {
+			template[field] = value
+		}
+	}
+
+	sealed := map[string]interface{}{
+		"apiVersion": sealedSecretsResource.GroupVersion().String(),
+		"kind":       "SealedSecret",
+		"metadata":   map[string]interface{}{"name": secretName, "namespace": namespace},
+		"spec": map[string]interface{}{
+			"encryptedData": encryptedData,
+			"template":      template,
+		},
+	}
+	return path.Join(archiveDir(sealedSecretsResource, namespace), path.Base(name)), sealed, nil
+}
+
+// sealValue encrypts plaintext the way the sealed-secrets controller
+// expects: a fresh AES-256-GCM session key encrypted with RSA-OAEP under
+// label, prefixed with its length, followed by the sealed plaintext.
+func sealValue(key *rsa.PublicKey, plaintext, label []byte) ([]byte, error) {
+	sessionKey := make([]byte, 32)
+	if _, err := rand.Read(sessionKey); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	wrappedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, key, sessionKey, label)
+	if err != nil {
+		return nil, err
+	}
+
+	out := binary.BigEndian.AppendUint16(nil, uint16(len(wrappedKey)))
+	out = append(out, wrappedKey...)
+	// Every session key seals a single value, so a zero nonce is safe
+	return aead.Seal(out, make([]byte, aead.NonceSize()), plaintext, nil), nil
+}
+
+// withoutLastApplied drops the kubectl last-applied annotation from
+// metadata, which would otherwise carry the Secret data in plaintext.
+func withoutLastApplied(metadata map[string]interface{}) map[string]interface{} {
+	annotations, ok := metadata["annotations"].(map[string]interface{})
+	if !ok {
+		return metadata
+	}
+	if _, ok := annotations[lastAppliedAnnotation]; !ok {
+		return metadata
+	}
+	kept := make(map[string]interface{}, len(annotations))
+	for key, value := range annotations {
+		if key != lastAppliedAnnotation {
+			kept[key] = value
+		}
+	}
+	out := make(map[string]interface{}, len(metadata))
+	for key, value := range metadata {
+		out[key] = value
+	}
+	if len(kept) == 0 {
+		delete(out, "annotations")
+	} else {
+		out["annotations"] = kept
+	}
+	return out
+}
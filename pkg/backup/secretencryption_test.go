@@ -0,0 +1,229 @@
+package backup
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func testSecret() map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"name":      "db",
+			"namespace": "apps",
+			"labels":    map[string]interface{}{"app": "db"},
+			"annotations": map[string]interface{}{
+				lastAppliedAnnotation: `{"data":{"password":"aHVudGVyMg=="}}`,
+			},
+		},
+		"type": "Opaque",
+		"data": map[string]interface{}{
+			"password": base64.StdEncoding.EncodeToString([]byte("hunter2")),
+			"empty":    "",
+		},
+	}
+}
+
+// sealingKey returns an RSA key and a self-signed PEM certificate for it.
+func sealingKey(t *testing.T, notBefore time.Time) (*rsa.PrivateKey, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sealed-secret"},
+		NotBefore:    notBefore,
+		NotAfter:     notBefore.Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+// unseal reverses sealValue the way the sealed-secrets controller does.
+func unseal(t *testing.T, key *rsa.PrivateKey, sealed, label []byte) []byte {
+	t.Helper()
+	size := int(binary.BigEndian.Uint16(sealed))
+	sessionKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, key, sealed[2:2+size], label)
+	if err != nil {
+		t.Fatalf("failed to decrypt session key: %v", err)
+	}
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, err := aead.Open(nil, make([]byte, aead.NonceSize()), sealed[2+size:], nil)
+	if err != nil {
+		t.Fatalf("failed to open sealed value: %v", err)
+	}
+	return plaintext
+}
+
+func TestSealSecrets(t *testing.T) {
+	t.Parallel()
+
+	// The newest active key of the controller is sealed to
+	retiredKey, retiredCert := sealingKey(t, time.Now().Add(-time.Hour))
+	activeKey, activeCert := sealingKey(t, time.Now())
+	keySecret := func(name, certificate string, created time.Time) runtime.Object {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": DefaultSealedSecretsNamespace,
+				"labels":    map[string]interface{}{sealedSecretsKeyLabel: "active"},
+			},
+			"data": map[string]interface{}{"tls.crt": base64.StdEncoding.EncodeToString([]byte(certificate))},
+		}}
+		obj.SetCreationTimestamp(metav1.NewTime(created))
+		return obj
+	}
+	scheme := runtime.NewScheme()
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Version: "v1", Kind: "Secret"})
+	bm := &BackupManager{DynamicClient: fake.NewSimpleDynamicClient(scheme,
+		keySecret("sealed-secrets-key-old", retiredCert, time.Now().Add(-time.Hour)),
+		keySecret("sealed-secrets-key-new", activeCert, time.Now()),
+	)}
+	encrypter, err := bm.newSecretEncrypter(context.Background(), &SecretEncryption{Format: SecretFormatSealedSecrets})
+	if err != nil {
+		t.Fatalf("newSecretEncrypter returned error: %v", err)
+	}
+
+	name, sealed, err := encrypter.encryptSecret("namespaces/apps/v1/secrets/db.json", testSecret())
+	if err != nil {
+		t.Fatalf("encryptSecret returned error: %v", err)
+	}
+	if name != "namespaces/apps/bitnami.com/v1alpha1/sealedsecrets/db.json" {
+		t.Fatalf("unexpected entry name %q", name)
+	}
+	if kind := sealed["kind"]; kind != "SealedSecret" {
+		t.Fatalf("unexpected kind %v", kind)
+	}
+	if _, ok := sealed["data"]; ok {
+		t.Fatalf("sealed secret carries plaintext data: %v", sealed)
+	}
+	if annotations := nestedString(sealed, "spec", "template", "metadata", "annotations", lastAppliedAnnotation); annotations != "" {
+		t.Fatalf("last-applied annotation was kept: %v", sealed)
+	}
+	if secretType := nestedString(sealed, "spec", "template", "type"); secretType != "Opaque" {
+		t.Fatalf("unexpected template type %q", secretType)
+	}
+
+	encoded := nestedString(sealed, "spec", "encryptedData", "password")
+	ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := unseal(t, activeKey, ciphertext, []byte("apps/db")); string(got) != "hunter2" {
+		t.Fatalf("unsealed %q", got)
+	}
+	if _, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, retiredKey, ciphertext[2:258], []byte("apps/db")); err == nil {
+		t.Fatalf("expected the secret to be sealed to the newest key only")
+	}
+
+	// A configured certificate takes precedence over the controller's keys
+	_, err = (&BackupManager{}).newSecretEncrypter(context.Background(),
+		&SecretEncryption{Format: SecretFormatSealedSecrets, SealingCertificate: retiredCert})
+	if err != nil {
+		t.Fatalf("expected a configured certificate to be used without a client, got %v", err)
+	}
+	if _, err := ParseSealingCertificate("not a certificate"); err == nil {
+		t.Fatalf("expected an invalid certificate to be rejected")
+	}
+}
+
+func TestSOPSSecrets(t *testing.T) {
+	t.Parallel()
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypter, err := newSOPSEncrypter([]string{identity.Recipient().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name, encrypted, err := encrypter.encryptSecret("namespaces/apps/v1/secrets/db.json", testSecret())
+	if err != nil {
+		t.Fatalf("encryptSecret returned error: %v", err)
+	}
+	if name != "namespaces/apps/v1/secrets/db.json" {
+		t.Fatalf("unexpected entry name %q", name)
+	}
+	if value := nestedString(encrypted, "data", "password"); !strings.HasPrefix(value, "ENC[AES256_GCM,") {
+		t.Fatalf("expected the password to be encrypted, got %q", value)
+	}
+	if value := nestedString(encrypted, "metadata", "labels", "app"); value != "db" {
+		t.Fatalf("expected metadata to stay readable, got %q", value)
+	}
+
+	// The object is read back from its archived JSON
+	raw, err := json.Marshal(encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archived := func() map[string]interface{} {
+		var obj map[string]interface{}
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			t.Fatal(err)
+		}
+		return obj
+	}
+	resources := []archivedResource{{gvr: secretsResource, namespace: "apps", name: "db", object: archived()}}
+	decryptSOPSResources(resources, []age.Identity{identity})
+	if resources[0].err != nil {
+		t.Fatalf("failed to decrypt: %v", resources[0].err)
+	}
+	want := testSecret()
+	delete(want["metadata"].(map[string]interface{}), "annotations")
+	if !reflect.DeepEqual(resources[0].object, want) {
+		t.Fatalf("decrypted %v, want %v", resources[0].object, want)
+	}
+
+	if _, err := decryptSOPSObject(archived(), []age.Identity{other}); !errors.Is(err, ErrNoKeyWrapper) {
+		t.Fatalf("expected an unknown identity to fail with ErrNoKeyWrapper, got %v", err)
+	}
+	tampered := archived()
+	tampered["metadata"].(map[string]interface{})["labels"] = map[string]interface{}{"app": "other"}
+	if _, err := decryptSOPSObject(tampered, []age.Identity{identity}); err == nil || !strings.Contains(err.Error(), "authentication code") {
+		t.Fatalf("expected a modified object to fail its MAC, got %v", err)
+	}
+}
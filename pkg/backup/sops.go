@@ -0,0 +1,418 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+const (
+	// sopsMetadataKey holds the SOPS metadata of an encrypted object.
+	sopsMetadataKey = "sops"
+	// sopsVersion is the SOPS file format version written.
+	sopsVersion = "3.9.0"
+	// sopsIVSize is the GCM nonce size SOPS uses.
+	sopsIVSize = 32
+)
+
+// sopsEncryptedKeys selects the values SOPS encrypts: everything under the
+// data and stringData of a Secret, leaving its metadata readable.
+var sopsEncryptedKeys = regexp.MustCompile(`^(data|stringData)$`)
+
+var sopsValuePattern = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.*),iv:(.+),tag:(.+),type:(.+)\]$`)
+
+// sopsMetadata is the "sops" section of an encrypted object.
+type sopsMetadata struct {
+	Age            []sopsAgeKey `json:"age"`
+	LastModified   string       `json:"lastmodified"`
+	MAC            string       `json:"mac"`
+	EncryptedRegex string       `json:"encrypted_regex,omitempty"`
+	Version        string       `json:"version"`
+}
+
+// sopsAgeKey is the data key encrypted to one age recipient.
+type sopsAgeKey struct {
+	Recipient        string `json:"recipient"`
+	EncryptedDataKey string `json:"enc"`
+}
+
+// sopsEncrypter writes Secrets as SOPS encrypted objects, which sops and
+// GitOps tools such as Flux can decrypt as well as the operator.
+type sopsEncrypter struct {
+	recipients []*age.X25519Recipient
+}
+
+func newSOPSEncrypter(recipients []string) (*sopsEncrypter, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("SOPS secret encryption requires at least one age recipient")
+	}
+	encrypter := &sopsEncrypter{}
+	for _, value := range recipients {
+		recipient, err := age.ParseX25519Recipient(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid age recipient %q: %w", value, err)
+		}
+		encrypter.recipients = append(encrypter.recipients, recipient)
+	}
+	return encrypter, nil
+}
+
+func (e *sopsEncrypter) encryptSecret(name string, obj map[string]interface{}) (string, map[string]interface{}, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", nil, err
+	}
+	metadata := sopsMetadata{
+		LastModified:   time.Now().UTC().Format(time.RFC3339),
+		EncryptedRegex: sopsEncryptedKeys.String(),
+		Version:        sopsVersion,
+	}
+	for _, recipient := range e.recipients {
+		var enc bytes.Buffer
+		armored := armor.NewWriter(&enc)
+		w, err := age.Encrypt(armored, recipient)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to encrypt SOPS data key: %w", err)
+		}
+		if _, err := w.Write(dataKey); err != nil {
+			return "", nil, fmt.Errorf("failed to encrypt SOPS data key: %w", err)
+		}
+		if err := w.Close(); err != nil {
+			return "", nil, fmt.Errorf("failed to encrypt SOPS data key: %w", err)
+		}
+		if err := armored.Close(); err != nil {
+			return "", nil, fmt.Errorf("failed to encrypt SOPS data key: %w", err)
+		}
+		metadata.Age = append(metadata.Age, sopsAgeKey{Recipient: recipient.String(), EncryptedDataKey: enc.String()})
+	}
+
+	out := make(map[string]interface{}, len(obj)+1)
+	for key, value := range obj {
+		out[key] = value
+	}
+	if objectMetadata, ok := obj["metadata"].(map[string]interface{}); ok {
+		out["metadata"] = withoutLastApplied(objectMetadata)
+	}
+
+	mac := sha512.New()
+	encrypted, err := sopsWalk(out, nil, func(value interface{}, path []string, encrypt bool) (interface{}, error) {
+		writeSOPSMAC(mac, value)
+		if !encrypt {
+			return value, nil
+		}
+		return sopsEncryptValue(value, dataKey, sopsAdditionalData(path))
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encrypt Secret %s: %w", nestedString(obj, "metadata", "name"), err)
+	}
+	if metadata.MAC, err = sopsEncryptValue(fmt.Sprintf("%X", mac.Sum(nil)), dataKey, metadata.LastModified); err != nil {
+		return "", nil, err
+	}
+
+	result := encrypted.(map[string]interface{})
+	if result[sopsMetadataKey], err = toUnstructuredMap(metadata); err != nil {
+		return "", nil, err
+	}
+	return name, result, nil
+}
+
+// isSOPSEncrypted reports whether obj carries SOPS metadata.
+func isSOPSEncrypted(obj map[string]interface{}) bool {
+	_, ok := obj[sopsMetadataKey].(map[string]interface{})
+	return ok
+}
+
+// decryptSOPSResources decrypts the SOPS encrypted objects among resources
+// in place. Objects that cannot be decrypted keep the error, so they fail
+// instead of being applied.
+func decryptSOPSResources(resources []archivedResource, identities []age.Identity) {
+	for i := range resources {
+		res := &resources[i]
+		if res.err != nil || !isSOPSEncrypted(res.object) {
+			continue
+		}
+		obj, err := decryptSOPSObject(res.object, identities)
+		if err != nil {
+			res.err = err
+			continue
+		}
+		res.object = obj
+	}
+}
+
+// decryptSOPSObject decrypts a SOPS encrypted object with identities and
+// checks it against its message authentication code.
+func decryptSOPSObject(obj map[string]interface{}, identities []age.Identity) (map[string]interface{}, error) {
+	var metadata sopsMetadata
+	raw, err := json.Marshal(obj[sopsMetadataKey])
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &metadata); err != nil {
+		return nil, fmt.Errorf("invalid SOPS metadata: %w", err)
+	}
+	if len(identities) == 0 {
+		return nil, fmt.Errorf("%w: the object is SOPS encrypted and no age identity is configured", ErrNoKeyWrapper)
+	}
+	dataKey, err := sopsDataKey(metadata, identities)
+	if err != nil {
+		return nil, err
+	}
+	encryptedKeys := sopsEncryptedKeys
+	if metadata.EncryptedRegex != "" {
+		if encryptedKeys, err = regexp.Compile(metadata.EncryptedRegex); err != nil {
+			return nil, fmt.Errorf("invalid SOPS encrypted_regex: %w", err)
+		}
+	}
+
+	in := make(map[string]interface{}, len(obj))
+	for key, value := range obj {
+		if key != sopsMetadataKey {
+			in[key] = value
+		}
+	}
+	mac := sha512.New()
+	decrypted, err := sopsWalkMatching(in, nil, encryptedKeys, func(value interface{}, path []string, encrypted bool) (interface{}, error) {
+		if encrypted {
+			var err error
+			if value, err = sopsDecryptValue(value, dataKey, sopsAdditionalData(path)); err != nil {
+				return nil, err
+			}
+		}
+		writeSOPSMAC(mac, value)
+		return value, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt SOPS object: %w", err)
+	}
+	expected, err := sopsDecryptValue(metadata.MAC, dataKey, metadata.LastModified)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt SOPS message authentication code: %w", err)
+	}
+	if expected != fmt.Sprintf("%X", mac.Sum(nil)) {
+		return nil, errors.New("SOPS message authentication code does not match, the object was modified")
+	}
+	return decrypted.(map[string]interface{}), nil
+}
+
+// sopsDataKey decrypts the data key of metadata with any of identities.
+func sopsDataKey(metadata sopsMetadata, identities []age.Identity) ([]byte, error) {
+	for _, key := range metadata.Age {
+		r, err := age.Decrypt(armor.NewReader(strings.NewReader(key.EncryptedDataKey)), identities...)
+		if err != nil {
+			continue
+		}
+		return io.ReadAll(r)
+	}
+	recipients := make([]string, 0, len(metadata.Age))
+	for _, key := range metadata.Age {
+		recipients = append(recipients, key.Recipient)
+	}
+	return nil, fmt.Errorf("%w: no age identity matches the SOPS recipients %s", ErrNoKeyWrapper, strings.Join(recipients, ", "))
+}
+
+// sopsWalk walks value the way SOPS does with sopsEncryptedKeys.
+func sopsWalk(value interface{}, path []string, leaf func(value interface{}, path []string, encrypted bool) (interface{}, error)) (interface{}, error) {
+	return sopsWalkMatching(value, path, sopsEncryptedKeys, leaf)
+}
+
+// sopsWalkMatching calls leaf on every scalar in value, in the order SOPS
+// reads a JSON document written with sorted keys, and replaces it with the
+// result. A scalar is encrypted when any key on its path matches
+// encryptedKeys.
+func sopsWalkMatching(value interface{}, path []string, encryptedKeys *regexp.Regexp,
+	leaf func(value interface{}, path []string, encrypted bool) (interface{}, error)) (interface{}, error) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		out := make(map[string]interface{}, len(typed))
+		for _, key := range keys {
+			walked, err := sopsWalkMatching(typed[key], append(path[:len(path):len(path)], key), encryptedKeys, leaf)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = walked
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(typed))
+		for i, item := range typed {
+			walked, err := sopsWalkMatching(item, path, encryptedKeys, leaf)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = walked
+		}
+		return out, nil
+	default:
+		encrypted := false
+		for _, key := range path {
+			if encryptedKeys.MatchString(key) {
+				encrypted = true
+				break
+			}
+		}
+		return leaf(value, path, encrypted)
+	}
+}
+
+// sopsAdditionalData authenticates a value with its path, as SOPS does.
+func sopsAdditionalData(path []string) string {
+	return strings.Join(path, ":") + ":"
+}
+
+// writeSOPSMAC adds a plaintext value to the message authentication code.
+func writeSOPSMAC(mac hash.Hash, value interface{}) {
+	if value == nil {
+		return
+	}
+	plaintext, _ := sopsPlaintext(value)
+	mac.Write([]byte(plaintext))
+}
+
+// sopsPlaintext returns the plaintext and SOPS type name of a scalar.
+func sopsPlaintext(value interface{}) (string, string) {
+	switch typed := value.(type) {
+	case string:
+		return typed, "str"
+	case bool:
+		if typed {
+			return "True", "bool"
+		}
+		return "False", "bool"
+	case int64:
+		return strconv.FormatInt(typed, 10), "int"
+	case int:
+		return strconv.Itoa(typed), "int"
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64), "float"
+	default:
+		return fmt.Sprint(typed), "str"
+	}
+}
+
+// sopsEncryptValue encrypts a scalar into a SOPS "ENC[...]" string. Empty
+// strings are left as they are, as SOPS does.
+func sopsEncryptValue(value interface{}, dataKey []byte, additionalData string) (string, error) {
+	if value == nil || value == "" {
+		return "", nil
+	}
+	plaintext, valueType := sopsPlaintext(value)
+	if valueType == "bool" {
+		plaintext = strconv.FormatBool(value.(bool))
+	}
+	aead, err := sopsCipher(dataKey)
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, sopsIVSize)
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nil, iv, []byte(plaintext), []byte(additionalData))
+	tagStart := len(sealed) - aead.Overhead()
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s,type:%s]",
+		base64.StdEncoding.EncodeToString(sealed[:tagStart]),
+		base64.StdEncoding.EncodeToString(iv),
+		base64.StdEncoding.EncodeToString(sealed[tagStart:]),
+		valueType), nil
+}
+
+// sopsDecryptValue decrypts a SOPS "ENC[...]" string back into a scalar.
+func sopsDecryptValue(value interface{}, dataKey []byte, additionalData string) (interface{}, error) {
+	encoded, ok := value.(string)
+	if !ok || encoded == "" {
+		return value, nil
+	}
+	match := sopsValuePattern.FindStringSubmatch(encoded)
+	if match == nil {
+		return nil, errors.New("value is not SOPS encrypted")
+	}
+	var parts [3][]byte
+	for i := range parts {
+		var err error
+		if parts[i], err = base64.StdEncoding.DecodeString(match[i+1]); err != nil {
+			return nil, fmt.Errorf("invalid SOPS value: %w", err)
+		}
+	}
+	aead, err := sopsCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts[1]) != sopsIVSize {
+		return nil, errors.New("invalid SOPS value: unexpected IV size")
+	}
+	plaintext, err := aead.Open(nil, parts[1], append(parts[0], parts[2]...), []byte(additionalData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt SOPS value: %w", err)
+	}
+	switch valueType := match[4]; valueType {
+	case "str":
+		return string(plaintext), nil
+	case "int":
+		return strconv.ParseInt(string(plaintext), 10, 64)
+	case "float":
+		return strconv.ParseFloat(string(plaintext), 64)
+	case "bool":
+		return strconv.ParseBool(string(plaintext))
+	default:
+		return nil, fmt.Errorf("unsupported SOPS value type %q", valueType)
+	}
+}
+
+func sopsCipher(dataKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCMWithNonceSize(block, sopsIVSize)
+}
+
+// toUnstructuredMap converts v into the generic form of archived objects.
+func toUnstructuredMap(v interface{}) (map[string]interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
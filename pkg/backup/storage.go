@@ -0,0 +1,190 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// probeFilePattern names the throwaway files written by ProbeStorage. The
+// leading dot keeps them out of archive listings.
+const probeFilePattern = ".backup-operator-probe-*"
+
+// defaultHostRoot is where node directories are mounted when HostStorage
+// does not name a root.
+const defaultHostRoot = "/host"
+
+// HostStorage describes the node directories mounted into the operator
+// container for host:// storage locations. The node directory
+// /var/lib/backups is expected at <Root>/var/lib/backups.
+type HostStorage struct {
+	// Root is the container directory node directories are mounted below.
+	// Defaults to /host.
+	Root string
+
+	// Paths are the mounted node directories. host:// locations outside
+	// them are rejected instead of being written to the container.
+	Paths []string
+
+	// LegacyTmp is the container directory the node's /tmp is mounted at.
+	// When set, host:// locations outside Paths resolve below it the way
+	// they did before host storage paths existed, host:///tmp/prod to
+	// <LegacyTmp>/prod and host:///prod to <LegacyTmp>/prod, so archives
+	// written there stay reachable until they are moved.
+	LegacyTmp string
+}
+
+// resolveStoragePath returns the directory in the operator container that
+// backs storagePath.
+func (bm *BackupManager) resolveStoragePath(storagePath string) (string, error) {
+	hostPath, ok := strings.CutPrefix(storagePath, "host://")
+	if !ok {
+		return storagePath, nil
+	}
+
+	hostPath = filepath.Clean("/" + hostPath)
+	for _, mounted := range bm.HostStorage.Paths {
+		mounted = filepath.Clean("/" + mounted)
+		if hostPath == mounted || strings.HasPrefix(hostPath, strings.TrimSuffix(mounted, "/")+"/") {
+			root := bm.HostStorage.Root
+			if root == "" {
+				root = defaultHostRoot
+			}
+			return filepath.Join(root, hostPath), nil
+		}
+	}
+	if bm.HostStorage.LegacyTmp != "" {
+		return legacyHostPath(bm.HostStorage.LegacyTmp, hostPath), nil
+	}
+	return "", fmt.Errorf("host path %q is not within a mounted host storage path", hostPath)
+}
+
+// legacyHostPath maps a cleaned host path below the node's /tmp, mounted at
+// legacyTmp, exactly the way host:// locations were resolved before host
+// storage paths existed: a leading "/tmp" is dropped and the rest is kept.
+// Like then, the prefix is not matched on a path boundary, so /tmpfs/prod
+// maps to fs/prod.
+func legacyHostPath(legacyTmp, hostPath string) string {
+	return filepath.Join(legacyTmp, strings.TrimPrefix(hostPath, "/tmp"))
+}
+
+// ValidateStoragePath checks that storagePath names a location the operator
+// knows how to write to: a host:// URI or an absolute filesystem path.
+func ValidateStoragePath(storagePath string) error {
+	if storagePath == "" {
+		return fmt.Errorf("storage path must not be empty")
+	}
+	if strings.Contains(storagePath, "{{") {
+		if strings.Contains(storagePath, ".Date") {
+			return fmt.Errorf("{{.Date}} is only supported in gitExport.path; retention and the archive catalog need a fixed storage location")
+		}
+		if _, err := ExpandStoragePath(storagePath, StoragePathVars{ClusterName: "cluster", Namespace: "namespace", BackupName: "backup"}); err != nil {
+			return err
+		}
+	}
+	if scheme, _, ok := strings.Cut(storagePath, "://"); ok {
+		if scheme != "host" {
+			return fmt.Errorf("unsupported storage scheme %q", scheme)
+		}
+		return nil
+	}
+	if !filepath.IsAbs(storagePath) {
+		return fmt.Errorf("storage path %q must be absolute or a host:// URI", storagePath)
+	}
+	return nil
+}
+
+// StoragePathVars are the variables a storage path template can use, as in
+// host:///var/lib/backups/{{.ClusterName}}/{{.Namespace}}/{{.BackupName}}.
+type StoragePathVars struct {
+	ClusterName string
+	Namespace   string
+	BackupName  string
+
+	// Date is the UTC date of the run as YYYY-MM-DD. It is only set for
+	// paths written anew by every run, such as the GitOps export path.
+	Date string
+}
+
+// ExpandStoragePath substitutes vars into the template variables of
+// storagePath. Using a variable that is empty is an error.
+func ExpandStoragePath(storagePath string, vars StoragePathVars) (string, error) {
+	if !strings.Contains(storagePath, "{{") {
+		return storagePath, nil
+	}
+	tmpl, err := template.New("storagePath").Option("missingkey=error").Parse(storagePath)
+	if err != nil {
+		return "", fmt.Errorf("invalid storage path template %q: %w", storagePath, err)
+	}
+
+	values := map[string]string{}
+	for name, value := range map[string]string{
+		"ClusterName": vars.ClusterName,
+		"Namespace":   vars.Namespace,
+		"BackupName":  vars.BackupName,
+		"Date":        vars.Date,
+	} {
+		if value != "" {
+			values[name] = value
+		}
+	}
+	var expanded strings.Builder
+	if err := tmpl.Execute(&expanded, values); err != nil {
+		return "", fmt.Errorf("failed to expand storage path %q: %w", storagePath, err)
+	}
+	return expanded.String(), nil
+}
+
+// ProbeStorage verifies that storagePath is usable by writing and then
+// deleting a small probe object in it.
+func (bm *BackupManager) ProbeStorage(storagePath string) error {
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(resolvedStoragePath, 0755); err != nil {
+		return fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	probe, err := os.CreateTemp(resolvedStoragePath, probeFilePattern)
+	if err != nil {
+		return fmt.Errorf("failed to create probe object: %w", err)
+	}
+	probePath := probe.Name()
+
+	_, err = probe.WriteString("backup-operator storage probe\n")
+	if err == nil {
+		err = probe.Sync()
+	}
+	if closeErr := probe.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(probePath)
+		return fmt.Errorf("failed to write probe object: %w", err)
+	}
+
+	if err := os.Remove(probePath); err != nil {
+		return fmt.Errorf("failed to delete probe object: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"fmt"
+	"sync"
+)
+
+// StorageLockedError is returned when another run holds a storage location.
+type StorageLockedError struct {
+	StoragePath string
+	// Holder names the run holding the location.
+	Holder string
+}
+
+func (e *StorageLockedError) Error() string {
+	return fmt.Sprintf("storage location %s is in use by %s", e.StoragePath, e.Holder)
+}
+
+// storageLocks records which run holds each storage location, keyed by its
+// resolved path, so backups and retention sharing a location take turns.
+type storageLocks struct {
+	mu      sync.Mutex
+	holders map[string]string
+}
+
+func newStorageLocks() *storageLocks {
+	return &storageLocks{holders: map[string]string{}}
+}
+
+// LockStorage reserves storagePaths for holder until the returned function
+// is called. Either every location is reserved or, when another holder has
+// one of them, none is and a *StorageLockedError is returned.
+func (bm *BackupManager) LockStorage(holder string, storagePaths ...string) (func(), error) {
+	if bm.storageLocks == nil {
+		return func() {}, nil
+	}
+	locks := bm.storageLocks
+
+	keys := make([]string, 0, len(storagePaths))
+	for _, storagePath := range storagePaths {
+		key, err := bm.resolveStoragePath(storagePath)
+		if err != nil {
+			key = storagePath
+		}
+		keys = append(keys, key)
+	}
+
+	locks.mu.Lock()
+	defer locks.mu.Unlock()
+	for i, key := range keys {
+		if current, ok := locks.holders[key]; ok {
+			return nil, &StorageLockedError{StoragePath: storagePaths[i], Holder: current}
+		}
+	}
+	for _, key := range keys {
+		locks.holders[key] = holder
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			locks.mu.Lock()
+			defer locks.mu.Unlock()
+			for _, key := range keys {
+				delete(locks.holders, key)
+			}
+		})
+	}, nil
+}
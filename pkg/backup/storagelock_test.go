@@ -0,0 +1,36 @@
+package backup
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLockStorageQueuesSharedLocations(t *testing.T) {
+	t.Parallel()
+
+	bm := &BackupManager{storageLocks: newStorageLocks()}
+	release, err := bm.LockStorage("ClusterBackup default/nightly", "/backups", "/replica")
+	if err != nil {
+		t.Fatalf("LockStorage() error = %v", err)
+	}
+
+	_, err = bm.LockStorage("ClusterBackup default/manual", "/other", "/replica")
+	var locked *StorageLockedError
+	if !errors.As(err, &locked) || locked.StoragePath != "/replica" || locked.Holder != "ClusterBackup default/nightly" {
+		t.Fatalf("LockStorage() error = %v, want the replica held by the nightly run", err)
+	}
+	// A refused run reserves nothing
+	releaseOther, err := bm.LockStorage("CleanupPolicy default/retention", "/other")
+	if err != nil {
+		t.Fatalf("LockStorage() of a free location error = %v", err)
+	}
+	releaseOther()
+
+	release()
+	release()
+	releaseManual, err := bm.LockStorage("ClusterBackup default/manual", "/other", "/replica")
+	if err != nil {
+		t.Fatalf("LockStorage() after release error = %v", err)
+	}
+	releaseManual()
+}
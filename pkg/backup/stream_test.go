@@ -0,0 +1,75 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+// versionedResources also serves the server version recorded in manifests.
+type versionedResources struct {
+	preferredResources
+}
+
+func (versionedResources) ServerVersion() (*version.Info, error) {
+	return &version.Info{GitVersion: "v1.33.0"}, nil
+}
+
+func TestWriteBackupStreamsArchiveForRestoreBackupFrom(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	discovery := versionedResources{preferredResources{lists: []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{
+			{Name: "configmaps", Kind: "ConfigMap", Namespaced: true, Verbs: []string{"list", "get", "create"}},
+		}},
+	}}}
+	source := &BackupManager{
+		DynamicClient: fake.NewSimpleDynamicClient(scheme,
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "payments"},
+				Data: map[string]string{"mode": "live"}}),
+		DiscoveryClient: discovery,
+	}
+	opts := BackupOptions{IncludeNamespaces: []string{"payments"}, ResourceTypes: []string{"ConfigMap"}}
+
+	var stream bytes.Buffer
+	result, err := source.WriteBackup(context.Background(), &stream, opts)
+	if err != nil {
+		t.Fatalf("WriteBackup() error = %v", err)
+	}
+	if result.ResourceCount != 1 || result.ArchiveBytes != int64(stream.Len()) || result.FilePath != "" {
+		t.Fatalf("WriteBackup() = %+v, want 1 resource in the %d streamed bytes", result, stream.Len())
+	}
+
+	target := &BackupManager{DynamicClient: fake.NewSimpleDynamicClient(scheme)}
+	restored, err := target.RestoreBackupFrom(context.Background(), &stream, "stdin", RestoreOptions{})
+	if err != nil {
+		t.Fatalf("RestoreBackupFrom() error = %v", err)
+	}
+	if restored.ResourcesApplied != 1 {
+		t.Fatalf("RestoreBackupFrom() applied %d resources, want 1", restored.ResourcesApplied)
+	}
+	configMap, err := target.DynamicClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).
+		Namespace("payments").Get(context.Background(), "settings", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("restored configmap: %v", err)
+	}
+	if data := nestedString(configMap.Object, "data", "mode"); data != "live" {
+		t.Fatalf("restored configmap data = %q, want %q", data, "live")
+	}
+
+	opts.Incremental = &IncrementalOptions{}
+	if _, err := source.WriteBackup(context.Background(), &stream, opts); err == nil {
+		t.Fatal("WriteBackup() of an incremental backup error = nil, want an error")
+	}
+}
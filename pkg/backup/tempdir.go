@@ -0,0 +1,200 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// ErrUnknownScratchDir is returned for backups staging in a scratch directory
+// that was not added with AddScratchDir.
+var ErrUnknownScratchDir = errors.New("unknown scratch directory")
+
+// stagingDirName is the directory the operator creates in the temp and
+// scratch directories to hold the staging directories of its runs. Only
+// its contents are ever cleaned up, so nothing else in those directories,
+// such as a storage location below /tmp, can be mistaken for a leftover run.
+const stagingDirName = "backup-operator-staging"
+
+// tempDirPrefix starts the name of the directory every backup run stages its
+// archive and export in.
+const tempDirPrefix = "run-"
+
+// tempDirs tracks the staging directories of the runs in progress, so the
+// ones a crashed operator left behind can be told apart and removed.
+type tempDirs struct {
+	// root is the directory they are created in. Empty uses os.TempDir.
+	root string
+	// named maps the scratch directories backups may choose instead of
+	// root to their path.
+	named map[string]string
+	// minFree is the free space required before a run is staged.
+	minFree int64
+
+	mu     sync.Mutex
+	active map[string]struct{}
+}
+
+// SetTempDir makes the manager stage archives below dir instead of
+// os.TempDir. The directory is created if missing. It must be called before
+// the manager is used, and dir must not be shared with other operator
+// instances, whose runs CleanupTempDirs would remove.
+func (bm *BackupManager) SetTempDir(dir string) error {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return fmt.Errorf("failed to create temp directory %s: %w", dir, err)
+		}
+	}
+	if bm.tempDirs == nil {
+		bm.tempDirs = &tempDirs{active: map[string]struct{}{}}
+	}
+	bm.tempDirs.root = dir
+	return nil
+}
+
+// AddScratchDir registers dir as the scratch directory name, which backups
+// select through BackupOptions.ScratchDir to stage on another volume, such
+// as a memory-backed emptyDir or a PersistentVolumeClaim. The directory is
+// created if missing. Like SetTempDir, it must be called before the manager
+// is used and dir must not be shared with other operator instances.
+func (bm *BackupManager) AddScratchDir(name, dir string) error {
+	if name == "" || dir == "" {
+		return fmt.Errorf("scratch directories need a name and a path")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create scratch directory %s: %w", dir, err)
+	}
+	if bm.tempDirs == nil {
+		bm.tempDirs = &tempDirs{active: map[string]struct{}{}}
+	}
+	if bm.tempDirs.named == nil {
+		bm.tempDirs.named = map[string]string{}
+	}
+	bm.tempDirs.named[name] = dir
+	return nil
+}
+
+// stagingRoot returns the directory staging directories are created in
+// below root, or below os.TempDir when root is empty.
+func stagingRoot(root string) string {
+	if root == "" {
+		root = os.TempDir()
+	}
+	return filepath.Join(root, stagingDirName)
+}
+
+// makeStagingDir creates a staging directory below root.
+func makeStagingDir(root string) (string, error) {
+	parent := stagingRoot(root)
+	if err := os.MkdirAll(parent, 0700); err != nil {
+		return "", fmt.Errorf("failed to create staging directory %s: %w", parent, err)
+	}
+	return os.MkdirTemp(parent, tempDirPrefix+"*")
+}
+
+// makeTempDir creates a staging directory for a run in the scratch
+// directory scratchDir, or the temp directory when it is empty, and returns
+// a function removing it again.
+func (bm *BackupManager) makeTempDir(scratchDir string) (string, func(), error) {
+	if bm.tempDirs == nil {
+		if scratchDir != "" {
+			return "", nil, fmt.Errorf("%w %q", ErrUnknownScratchDir, scratchDir)
+		}
+		dir, err := makeStagingDir("")
+		if err != nil {
+			return "", nil, err
+		}
+		return dir, func() { os.RemoveAll(dir) }, nil
+	}
+
+	t := bm.tempDirs
+	root := t.root
+	if scratchDir != "" {
+		var ok bool
+		if root, ok = t.named[scratchDir]; !ok {
+			return "", nil, fmt.Errorf("%w %q", ErrUnknownScratchDir, scratchDir)
+		}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	dir, err := makeStagingDir(root)
+	if err != nil {
+		return "", nil, err
+	}
+	t.active[dir] = struct{}{}
+	return dir, func() {
+		os.RemoveAll(dir)
+		t.mu.Lock()
+		delete(t.active, dir)
+		t.mu.Unlock()
+	}, nil
+}
+
+// CleanupTempDirs removes the staging directories in the temp and scratch
+// directories that no run of this operator is using, such as those left
+// behind by a crash, and returns how many were removed. Only the
+// backup-operator-staging directory in each is looked at.
+func (bm *BackupManager) CleanupTempDirs(ctx context.Context) (int, error) {
+	if bm.tempDirs == nil {
+		return 0, nil
+	}
+	log := ctrl.LoggerFrom(ctx)
+
+	t := bm.tempDirs
+	roots := []string{stagingRoot(t.root)}
+	for _, dir := range t.named {
+		roots = append(roots, stagingRoot(dir))
+	}
+	// Holding the lock keeps runs from creating a directory between the
+	// listing and the removal
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	removed := 0
+	for _, root := range roots {
+		entries, err := os.ReadDir(root)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return removed, fmt.Errorf("failed to list temp directory %s: %w", root, err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() || !strings.HasPrefix(entry.Name(), tempDirPrefix) {
+				continue
+			}
+			dir := filepath.Join(root, entry.Name())
+			if _, ok := t.active[dir]; ok {
+				continue
+			}
+			if err := os.RemoveAll(dir); err != nil {
+				log.Error(err, "Failed to remove orphaned temp directory", "path", dir)
+				continue
+			}
+			log.Info("Removed orphaned temp directory", "path", dir)
+			removed++
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,104 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCleanupTempDirs(t *testing.T) {
+	t.Parallel()
+
+	root := filepath.Join(t.TempDir(), "staging")
+	bm := &BackupManager{}
+	if err := bm.SetTempDir(root); err != nil {
+		t.Fatalf("SetTempDir returned error: %v", err)
+	}
+
+	orphaned := filepath.Join(root, stagingDirName, tempDirPrefix+"123456")
+	if err := os.MkdirAll(filepath.Join(orphaned, "export"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	unrelated := filepath.Join(root, "other-tool-123")
+	if err := os.Mkdir(unrelated, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// A storage location below the temp directory, named like its archives
+	storage := filepath.Join(root, "cluster-backup-prod")
+	if err := os.Mkdir(storage, 0755); err != nil {
+		t.Fatal(err)
+	}
+	active, removeActive, err := bm.makeTempDir("")
+	if err != nil {
+		t.Fatalf("makeTempDir returned error: %v", err)
+	}
+	if filepath.Dir(active) != stagingRoot(root) {
+		t.Fatalf("expected the run directory below %s, got %s", stagingRoot(root), active)
+	}
+
+	removed, err := bm.CleanupTempDirs(context.Background())
+	if err != nil {
+		t.Fatalf("CleanupTempDirs returned error: %v", err)
+	}
+	if removed != 1 {
+		t.Fatalf("expected 1 directory removed, got %d", removed)
+	}
+	if _, err := os.Stat(orphaned); !os.IsNotExist(err) {
+		t.Fatalf("expected the orphaned directory to be removed, got %v", err)
+	}
+	for _, dir := range []string{active, unrelated, storage} {
+		if _, err := os.Stat(dir); err != nil {
+			t.Fatalf("expected %s to be kept: %v", dir, err)
+		}
+	}
+
+	removeActive()
+	if _, err := os.Stat(active); !os.IsNotExist(err) {
+		t.Fatalf("expected the finished run directory to be removed, got %v", err)
+	}
+	if removed, err := bm.CleanupTempDirs(context.Background()); err != nil || removed != 0 {
+		t.Fatalf("expected nothing left to clean up, got %d, %v", removed, err)
+	}
+}
+
+func TestNamedScratchDirs(t *testing.T) {
+	t.Parallel()
+
+	large := filepath.Join(t.TempDir(), "large")
+	bm := &BackupManager{}
+	if err := bm.AddScratchDir("large", large); err != nil {
+		t.Fatalf("AddScratchDir returned error: %v", err)
+	}
+
+	if _, _, err := bm.makeTempDir("missing"); !errors.Is(err, ErrUnknownScratchDir) {
+		t.Fatalf("expected ErrUnknownScratchDir, got %v", err)
+	}
+
+	dir, remove, err := bm.makeTempDir("large")
+	if err != nil {
+		t.Fatalf("makeTempDir returned error: %v", err)
+	}
+	defer remove()
+	if filepath.Dir(dir) != stagingRoot(large) {
+		t.Fatalf("expected the run directory below %s, got %s", stagingRoot(large), dir)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "archive.tar.gz"), make([]byte, 1024), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if used := scratchUsage(dir); used != 1024 {
+		t.Fatalf("expected 1024 bytes of scratch usage, got %d", used)
+	}
+
+	orphaned := filepath.Join(stagingRoot(large), tempDirPrefix+"123456")
+	if err := os.Mkdir(orphaned, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if removed, err := bm.CleanupTempDirs(context.Background()); err != nil || removed != 1 {
+		t.Fatalf("expected the orphaned directory to be removed, got %d, %v", removed, err)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatalf("expected the active run directory to be kept: %v", err)
+	}
+}
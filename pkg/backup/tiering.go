@@ -0,0 +1,91 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// TransitionArchives moves the unpinned archives in storagePath that were
+// last modified more than after ago to coldStoragePath. An archive that already
+// reached the cold location in an interrupted earlier run is only removed
+// from storagePath. It returns the archives moved before any error.
+func (bm *BackupManager) TransitionArchives(ctx context.Context, storagePath, coldStoragePath string, after time.Duration) ([]string, error) {
+	archives, err := bm.ListArchives(storagePath)
+	if err != nil {
+		return nil, err
+	}
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return nil, err
+	}
+	resolvedColdPath, err := bm.resolveStoragePath(coldStoragePath)
+	if err != nil {
+		return nil, err
+	}
+	cutoff := time.Now().Add(-after)
+	bases := referencedBases(resolvedStoragePath)
+
+	var moved []string
+	for _, archive := range archives {
+		archivePath := filepath.Join(resolvedStoragePath, archive)
+		info, err := os.Stat(archivePath)
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		// Locked archives stay where their lock was placed and pinned
+		// archives stay at hand. Incremental archives stay next to their
+		// base. Archives read by a restore are moved by a later run.
+		if checkArchiveMutable(archivePath, time.Now()) != nil || isPinned(archivePath) || isIncremental(archivePath) ||
+			bm.isReferenced(archivePath, time.Now()) {
+			continue
+		}
+		if _, ok := bases[archive]; ok {
+			continue
+		}
+
+		if cold, err := os.Stat(filepath.Join(resolvedColdPath, archive)); err == nil && cold.Size() == info.Size() {
+			if err := removeArchive(archivePath); err != nil {
+				return moved, fmt.Errorf("failed to remove transitioned archive %q: %w", archive, err)
+			}
+		} else if _, err := bm.TransferArchive(ctx, storagePath, archive, coldStoragePath, true); err != nil {
+			return moved, fmt.Errorf("failed to transition archive %q: %w", archive, err)
+		}
+		// Keep the original age so retention in the cold location counts
+		// from when the backup was taken
+		_ = os.Chtimes(filepath.Join(resolvedColdPath, archive), info.ModTime(), info.ModTime())
+		moved = append(moved, archive)
+	}
+	return moved, nil
+}
+
+// HasArchive reports whether storagePath holds the named archive.
+func (bm *BackupManager) HasArchive(storagePath, archiveName string) bool {
+	if filepath.Base(archiveName) != archiveName {
+		return false
+	}
+	resolvedStoragePath, err := bm.resolveStoragePath(storagePath)
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(resolvedStoragePath, archiveName))
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,56 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTransitionArchives(t *testing.T) {
+	t.Parallel()
+
+	hot := t.TempDir()
+	cold := filepath.Join(t.TempDir(), "cold")
+	old := time.Now().Add(-10 * 24 * time.Hour)
+
+	write := func(dir, name string, modTime time.Time) {
+		t.Helper()
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(hot, "cluster-backup-20250101-000000.tar.gz", old)
+	write(hot, "cluster-backup-20250102-000000.tar.gz", old)
+	write(hot, "cluster-backup-20250110-000000.tar.gz", time.Now())
+	// Left behind by an interrupted move
+	write(cold, "cluster-backup-20250102-000000.tar.gz", old)
+
+	moved, err := (&BackupManager{}).TransitionArchives(context.Background(), hot, cold, 7*24*time.Hour)
+	if err != nil {
+		t.Fatalf("TransitionArchives: %v", err)
+	}
+	if len(moved) != 2 {
+		t.Fatalf("moved %v, want the two old archives", moved)
+	}
+
+	remaining, _ := (&BackupManager{}).ListArchives(hot)
+	if len(remaining) != 1 || remaining[0] != "cluster-backup-20250110-000000.tar.gz" {
+		t.Fatalf("hot location holds %v", remaining)
+	}
+	info, err := os.Stat(filepath.Join(cold, "cluster-backup-20250101-000000.tar.gz"))
+	if err != nil {
+		t.Fatalf("archive not in cold location: %v", err)
+	}
+	if info.ModTime().Sub(old).Abs() > time.Second {
+		t.Fatalf("cold archive modification time %v, want %v", info.ModTime(), old)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/pager"
+)
+
+// MaxReportedSlowResources caps the slowest resource types recorded in the
+// archive manifest.
+const MaxReportedSlowResources = 5
+
+// ResourceTiming is the time a backup spent on one resource type, summed
+// over the namespaces it was listed in.
+type ResourceTiming struct {
+	GVR   schema.GroupVersionResource
+	Items int
+	// Duration is the time spent listing and archiving the objects, and
+	// List the part of it spent waiting for List calls.
+	Duration time.Duration
+	List     time.Duration
+}
+
+// manifestTiming is a ResourceTiming as recorded in the manifest.
+type manifestTiming struct {
+	Resource        string  `json:"resource"`
+	Items           int     `json:"items"`
+	DurationSeconds float64 `json:"durationSeconds"`
+	ListSeconds     float64 `json:"listSeconds"`
+}
+
+// timedListPage returns listPage adding the time each call takes to total
+func timedListPage(listPage pager.ListPageFunc, total *atomic.Int64) pager.ListPageFunc {
+	return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		start := time.Now()
+		defer func() { total.Add(int64(time.Since(start))) }()
+		return listPage(ctx, opts)
+	}
+}
+
+// recordTiming adds the time spent on items objects of gvr
+func (aw *archiveWriter) recordTiming(gvr schema.GroupVersionResource, items int, duration, list time.Duration) {
+	aw.mu.Lock()
+	defer aw.mu.Unlock()
+	timing, ok := aw.timings[gvr]
+	if !ok {
+		timing = &ResourceTiming{GVR: gvr}
+		aw.timings[gvr] = timing
+	}
+	timing.Items += items
+	timing.Duration += duration
+	timing.List += list
+}
+
+// slowestResources returns the recorded timings, slowest first
+func slowestResources(timings map[schema.GroupVersionResource]*ResourceTiming) []ResourceTiming {
+	sorted := make([]ResourceTiming, 0, len(timings))
+	for _, timing := range timings {
+		sorted = append(sorted, *timing)
+	}
+	slices.SortFunc(sorted, func(a, b ResourceTiming) int {
+		if c := cmp.Compare(b.Duration, a.Duration); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.GVR.String(), b.GVR.String())
+	})
+	return sorted
+}
+
+// manifestTimingsLocked returns the slowest resource types to record in the
+// manifest; aw.mu must be held
+func (aw *archiveWriter) manifestTimingsLocked() []manifestTiming {
+	var timings []manifestTiming
+	for _, timing := range slowestResources(aw.timings) {
+		if len(timings) == MaxReportedSlowResources {
+			break
+		}
+		timings = append(timings, manifestTiming{
+			Resource:        timing.GVR.GroupResource().String(),
+			Items:           timing.Items,
+			DurationSeconds: timing.Duration.Seconds(),
+			ListSeconds:     timing.List.Seconds(),
+		})
+	}
+	return timings
+}
@@ -0,0 +1,66 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestSlowestResourcesInManifest(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	archive := newArchiveWriter(&out)
+	for i := range MaxReportedSlowResources + 2 {
+		gvr := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: string(rune('a' + i))}
+		archive.recordTiming(gvr, 1, time.Duration(i)*time.Second, time.Duration(i)*time.Millisecond)
+	}
+	configMaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	archive.recordTiming(configMaps, 2, 5*time.Second, time.Second)
+	archive.recordTiming(configMaps, 3, 5*time.Second, time.Second)
+	if err := archive.Close(); err != nil {
+		t.Fatalf("failed closing archive: %v", err)
+	}
+
+	timings := slowestResources(archive.timings)
+	if len(timings) != MaxReportedSlowResources+3 {
+		t.Fatalf("expected %d timings, got %d", MaxReportedSlowResources+3, len(timings))
+	}
+	if got := timings[0]; got.GVR != configMaps || got.Items != 5 || got.Duration != 10*time.Second || got.List != 2*time.Second {
+		t.Fatalf("expected configmaps to be slowest with summed timings, got %+v", got)
+	}
+
+	manifest := readTestManifest(t, &out)
+	if len(manifest.SlowestResources) != MaxReportedSlowResources {
+		t.Fatalf("expected %d slowest resources in the manifest, got %d", MaxReportedSlowResources, len(manifest.SlowestResources))
+	}
+	if got := manifest.SlowestResources[0]; got.Resource != "configmaps" || got.Items != 5 || got.DurationSeconds != 10 || got.ListSeconds != 2 {
+		t.Fatalf("unexpected slowest resource in manifest: %+v", got)
+	}
+	if got := manifest.SlowestResources[1].Resource; got != "g.example.com" {
+		t.Fatalf("expected g.example.com second, got %q", got)
+	}
+}
+
+func TestTimedListPage(t *testing.T) {
+	t.Parallel()
+
+	var total atomic.Int64
+	listPage := timedListPage(func(context.Context, metav1.ListOptions) (runtime.Object, error) {
+		time.Sleep(5 * time.Millisecond)
+		return nil, errors.New("boom")
+	}, &total)
+	if _, err := listPage(context.Background(), metav1.ListOptions{}); err == nil {
+		t.Fatal("expected the List error to be returned")
+	}
+	if got := time.Duration(total.Load()); got < 5*time.Millisecond {
+		t.Fatalf("expected at least 5ms of List time, got %v", got)
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxReportedVerifyFailures caps the failed entries listed by
+// ArchiveVerification.
+const MaxReportedVerifyFailures = 10
+
+// ErrNoManifest is returned by VerifyArchive for archives written without a
+// manifest, whose entries have no checksums to be checked against.
+var ErrNoManifest = errors.New("archive has no manifest")
+
+// VerifyOptions configures VerifyArchive.
+type VerifyOptions struct {
+	// KeyWrappers decrypt encrypted archives, as for RestoreOptions.
+	KeyWrappers []KeyWrapper
+}
+
+// ArchiveVerification is the outcome of reading every entry of an archive
+// back from storage.
+type ArchiveVerification struct {
+	// Entries is the number of entries the manifest lists.
+	Entries int
+	// Failed counts the entries that are missing, do not match their
+	// checksum or are not JSON objects.
+	Failed int
+	// FailedEntries describes up to MaxReportedVerifyFailures of them.
+	FailedEntries []string
+}
+
+// Verified reports whether every entry passed.
+func (v *ArchiveVerification) Verified() bool {
+	return v.Failed == 0
+}
+
+func (v *ArchiveVerification) String() string {
+	if v.Verified() {
+		return fmt.Sprintf("all %d entries match their checksums", v.Entries)
+	}
+	return fmt.Sprintf("%d of %d entries failed verification: %s", v.Failed, v.Entries, strings.Join(v.FailedEntries, "; "))
+}
+
+// VerifyArchive reads storagePath/archiveName back from storage, decrypting
+// it if needed, and checks every entry listed in its manifest against its
+// checksum, including entries held by the base of an incremental archive.
+// It fails when the archive cannot be read as a whole; entries that fail are
+// reported in the result.
+func (bm *BackupManager) VerifyArchive(ctx context.Context, storagePath, archiveName string, opts VerifyOptions) (*ArchiveVerification, error) {
+	if err := ValidateArchiveName(archiveName); err != nil {
+		return nil, err
+	}
+	clusterResources, namespacedResources, manifest, err := bm.readStoredArchive(ctx, storagePath, archiveName,
+		RestoreOptions{KeyWrappers: opts.KeyWrappers}.keyWrappersFor(ctx), "")
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("%w: %s cannot be verified", ErrNoManifest, archiveName)
+	}
+
+	result := &ArchiveVerification{Entries: len(manifest.Files)}
+	for _, res := range append(clusterResources, namespacedResources...) {
+		if res.err == nil {
+			continue
+		}
+		result.Failed++
+		if len(result.FailedEntries) < MaxReportedVerifyFailures {
+			result.FailedEntries = append(result.FailedEntries, res.err.Error())
+		}
+	}
+	return result, nil
+}
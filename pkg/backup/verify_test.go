@@ -0,0 +1,112 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerifyArchive(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storageDir := t.TempDir()
+	bm := &BackupManager{}
+
+	goodName := "cluster-backup-20260101-000000.tar.gz"
+	writeConfigMapArchive(t, filepath.Join(storageDir, goodName), nil,
+		incrementalConfigMap("first", "1", "a"), incrementalConfigMap("second", "1", "b"))
+	result, err := bm.VerifyArchive(ctx, storageDir, goodName, VerifyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Verified() || result.Entries != 2 {
+		t.Fatalf("got %+v", result)
+	}
+
+	// Flip a byte in one entry, leaving the archive otherwise readable
+	rottenName := "cluster-backup-20260101-010000.tar.gz"
+	rewriteArchive(t, filepath.Join(storageDir, goodName), filepath.Join(storageDir, rottenName), func(name string, data []byte) []byte {
+		if strings.HasSuffix(name, "/second.json") {
+			return bytes.Replace(data, []byte(`"b"`), []byte(`"c"`), 1)
+		}
+		return data
+	})
+	result, err = bm.VerifyArchive(ctx, storageDir, rottenName, VerifyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Verified() || result.Failed != 1 || !strings.Contains(result.String(), "second.json") {
+		t.Fatalf("got %+v", result)
+	}
+
+	legacyName := "cluster-backup-20260101-020000.tar.gz"
+	writeRestoreArchive(t, filepath.Join(storageDir, legacyName))
+	if _, err := bm.VerifyArchive(ctx, storageDir, legacyName, VerifyOptions{}); !errors.Is(err, ErrNoManifest) {
+		t.Fatalf("expected ErrNoManifest, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(storageDir, "cluster-backup-20260101-030000.tar.gz"), []byte("garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bm.VerifyArchive(ctx, storageDir, "cluster-backup-20260101-030000.tar.gz", VerifyOptions{}); err == nil {
+		t.Fatal("expected an unreadable archive to fail")
+	}
+}
+
+// rewriteArchive copies the gzipped tar archive at src to dst, passing the
+// content of every entry through edit.
+func rewriteArchive(t *testing.T, src, dst string, edit func(name string, data []byte) []byte) {
+	t.Helper()
+
+	in, err := os.Open(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	gzReader, err := gzip.NewReader(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	gzWriter := gzip.NewWriter(&out)
+	tarWriter := tar.NewWriter(gzWriter)
+	tarReader := tar.NewReader(gzReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(tarReader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = edit(header.Name, data)
+		if err := tarWriter.WriteHeader(&tar.Header{Name: header.Name, Mode: 0o644, Size: int64(len(data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarWriter.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dst, out.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
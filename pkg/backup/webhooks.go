@@ -0,0 +1,291 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// originalFailurePoliciesAnnotation records, per webhook name, the failure
+// policy a webhook configuration had before a restore relaxed it. Keeping it
+// on the object lets a later restore put the policies back if the operator
+// stopped before it could.
+const originalFailurePoliciesAnnotation = "backup.backup.io/original-failure-policies"
+
+const (
+	failurePolicyIgnore = "Ignore"
+	// failurePolicyDefault applies to admissionregistration/v1 webhooks that
+	// do not set a failure policy.
+	failurePolicyDefault = "Fail"
+)
+
+var webhookConfigurationGVRs = []schema.GroupVersionResource{
+	{Group: "admissionregistration.k8s.io", Version: "v1", Resource: "validatingwebhookconfigurations"},
+	{Group: "admissionregistration.k8s.io", Version: "v1", Resource: "mutatingwebhookconfigurations"},
+}
+
+// isWebhookConfiguration reports whether gvr is an admission webhook
+// configuration, which is restored after every other resource so webhooks
+// whose backends are still being restored cannot reject the restore.
+func isWebhookConfiguration(gvr schema.GroupVersionResource) bool {
+	for _, webhookGVR := range webhookConfigurationGVRs {
+		if gvr.GroupResource() == webhookGVR.GroupResource() {
+			return true
+		}
+	}
+	return false
+}
+
+// splitWebhookConfigurations separates admission webhook configurations from
+// the other resources.
+func splitWebhookConfigurations(resources []archivedResource) ([]archivedResource, []archivedResource) {
+	var others, webhooks []archivedResource
+	for _, res := range resources {
+		if isWebhookConfiguration(res.gvr) {
+			webhooks = append(webhooks, res)
+		} else {
+			others = append(others, res)
+		}
+	}
+	return others, webhooks
+}
+
+// webhookRelaxations counts the restores that need webhook failure policies
+// relaxed, so the first one relaxes them and only the last one to finish
+// puts them back. mu also keeps relaxing and reverting from interleaving.
+type webhookRelaxations struct {
+	mu    sync.Mutex
+	count int
+}
+
+// relaxWebhooksForRestore relaxes the webhook failure policies for one
+// restore and returns the function that ends its relaxation. Policies are
+// put back once no restore through the manager needs them relaxed. Every
+// restore relaxes again, to cover configurations created by the restores
+// already running. release must be called even when err is set.
+func (bm *BackupManager) relaxWebhooksForRestore(ctx context.Context) (release func(), relaxed int, err error) {
+	relaxations := bm.webhookRelaxations
+	if relaxations == nil {
+		relaxations = &webhookRelaxations{}
+	}
+	relaxations.mu.Lock()
+	relaxations.count++
+	relaxed, err = bm.relaxWebhooks(ctx)
+	relaxations.mu.Unlock()
+
+	var once sync.Once
+	release = func() {
+		once.Do(func() {
+			relaxations.mu.Lock()
+			defer relaxations.mu.Unlock()
+			if relaxations.count--; relaxations.count > 0 {
+				return
+			}
+			if err := bm.restoreWebhookPolicies(context.WithoutCancel(ctx)); err != nil {
+				ctrl.LoggerFrom(ctx).Error(err, "Failed to restore webhook failure policies")
+			}
+		})
+	}
+	return release, relaxed, err
+}
+
+// RestoreWebhookPolicies puts back the failure policies that restores of an
+// operator which stopped before it could revert them left relaxed. It does
+// nothing while a restore through the manager has them relaxed.
+func (bm *BackupManager) RestoreWebhookPolicies(ctx context.Context) error {
+	if relaxations := bm.webhookRelaxations; relaxations != nil {
+		relaxations.mu.Lock()
+		defer relaxations.mu.Unlock()
+		if relaxations.count > 0 {
+			return nil
+		}
+	}
+	return bm.restoreWebhookPolicies(ctx)
+}
+
+// relaxWebhooks sets failurePolicy Ignore on every webhook in the cluster's
+// admission webhook configurations, recording the original policies in an
+// annotation. It returns how many configurations were changed.
+func (bm *BackupManager) relaxWebhooks(ctx context.Context) (int, error) {
+	relaxed := 0
+	for _, gvr := range webhookConfigurationGVRs {
+		list, err := bm.DynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return relaxed, fmt.Errorf("failed to list %s: %w", gvr.Resource, err)
+		}
+		for _, item := range list.Items {
+			changed, err := bm.updateWebhookPolicies(ctx, gvr, item.GetName(), relaxWebhookPolicies)
+			if err != nil {
+				return relaxed, err
+			}
+			if changed {
+				relaxed++
+			}
+		}
+	}
+	return relaxed, nil
+}
+
+// restoreWebhookPolicies puts back the failure policies recorded by
+// relaxWebhooks on every configuration still carrying the annotation.
+// Webhooks that were changed since, for example by the restore itself, are
+// left alone.
+func (bm *BackupManager) restoreWebhookPolicies(ctx context.Context) error {
+	for _, gvr := range webhookConfigurationGVRs {
+		list, err := bm.DynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %w", gvr.Resource, err)
+		}
+		for _, item := range list.Items {
+			if _, ok := item.GetAnnotations()[originalFailurePoliciesAnnotation]; !ok {
+				continue
+			}
+			if _, err := bm.updateWebhookPolicies(ctx, gvr, item.GetName(), revertWebhookPolicies); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// updateWebhookPolicies applies mutate to the named configuration, retrying
+// on conflicts, and reports whether it was updated.
+func (bm *BackupManager) updateWebhookPolicies(ctx context.Context, gvr schema.GroupVersionResource, name string, mutate func(*unstructured.Unstructured) (bool, error)) (bool, error) {
+	changed := false
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		client := bm.DynamicClient.Resource(gvr)
+		obj, err := client.Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			changed = false
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if changed, err = mutate(obj); err != nil || !changed {
+			return err
+		}
+		_, err = client.Update(ctx, obj, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to update failure policies of %s %s: %w", gvr.Resource, name, err)
+	}
+	return changed, nil
+}
+
+// relaxWebhookPolicies sets every webhook of obj to failurePolicy Ignore and
+// merges the policies it replaced into the annotation.
+func relaxWebhookPolicies(obj *unstructured.Unstructured) (bool, error) {
+	originals, err := originalFailurePolicies(obj)
+	if err != nil {
+		return false, err
+	}
+	webhooks, _, err := unstructured.NestedSlice(obj.Object, "webhooks")
+	if err != nil {
+		return false, err
+	}
+
+	changed := false
+	for _, entry := range webhooks {
+		webhook, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := webhook["name"].(string)
+		policy, _ := webhook["failurePolicy"].(string)
+		if policy == "" {
+			policy = failurePolicyDefault
+		}
+		if policy == failurePolicyIgnore {
+			continue
+		}
+		if _, recorded := originals[name]; !recorded {
+			originals[name] = policy
+		}
+		webhook["failurePolicy"] = failurePolicyIgnore
+		changed = true
+	}
+	if !changed {
+		return false, nil
+	}
+
+	encoded, err := json.Marshal(originals)
+	if err != nil {
+		return false, err
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[originalFailurePoliciesAnnotation] = string(encoded)
+	obj.SetAnnotations(annotations)
+	return true, unstructured.SetNestedSlice(obj.Object, webhooks, "webhooks")
+}
+
+// revertWebhookPolicies puts back the recorded policies of webhooks that are
+// still set to Ignore and drops the annotation.
+func revertWebhookPolicies(obj *unstructured.Unstructured) (bool, error) {
+	originals, err := originalFailurePolicies(obj)
+	if err != nil {
+		return false, err
+	}
+	webhooks, _, err := unstructured.NestedSlice(obj.Object, "webhooks")
+	if err != nil {
+		return false, err
+	}
+
+	for _, entry := range webhooks {
+		webhook, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := webhook["name"].(string)
+		original, recorded := originals[name]
+		if recorded && webhook["failurePolicy"] == failurePolicyIgnore {
+			webhook["failurePolicy"] = original
+		}
+	}
+
+	annotations := obj.GetAnnotations()
+	delete(annotations, originalFailurePoliciesAnnotation)
+	obj.SetAnnotations(annotations)
+	return true, unstructured.SetNestedSlice(obj.Object, webhooks, "webhooks")
+}
+
+// originalFailurePolicies decodes the annotation of obj, if any.
+func originalFailurePolicies(obj *unstructured.Unstructured) (map[string]string, error) {
+	originals := map[string]string{}
+	value, ok := obj.GetAnnotations()[originalFailurePoliciesAnnotation]
+	if !ok {
+		return originals, nil
+	}
+	if err := json.Unmarshal([]byte(value), &originals); err != nil {
+		return nil, fmt.Errorf("failed to decode %s annotation: %w", originalFailurePoliciesAnnotation, err)
+	}
+	return originals, nil
+}
@@ -0,0 +1,188 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestRestoreBackupRestoresWebhooksLast(t *testing.T) {
+	t.Parallel()
+
+	storageDir := t.TempDir()
+	archiveName := "cluster-backup-webhooks.tar.gz"
+	file, err := os.Create(filepath.Join(storageDir, archiveName))
+	if err != nil {
+		t.Fatalf("failed to create archive: %v", err)
+	}
+	gz := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gz)
+	writeJSONTarEntry(t, tarWriter, "cluster/admissionregistration.k8s.io/v1/validatingwebhookconfigurations/archived.json",
+		webhookConfiguration("archived", "Fail").Object)
+	writeJSONTarEntry(t, tarWriter, "cluster/v1/namespaces/restore-ns.json", map[string]interface{}{
+		"apiVersion": "v1", "kind": "Namespace", "metadata": map[string]interface{}{"name": "restore-ns"},
+	})
+	writeJSONTarEntry(t, tarWriter, "namespaces/restore-ns/v1/configmaps/sample-config.json", map[string]interface{}{
+		"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "sample-config"},
+	})
+	for _, closer := range []interface{ Close() error }{tarWriter, gz, file} {
+		if err := closer.Close(); err != nil {
+			t.Fatalf("failed to finalize archive: %v", err)
+		}
+	}
+
+	scheme := runtime.NewScheme()
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Version: "v1", Kind: "Namespace"})
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Group: "admissionregistration.k8s.io", Version: "v1", Kind: "ValidatingWebhookConfiguration"})
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Group: "admissionregistration.k8s.io", Version: "v1", Kind: "MutatingWebhookConfiguration"})
+	dynamicClient := fake.NewSimpleDynamicClient(scheme, webhookConfiguration("live", ""))
+
+	// Record every create and every webhook policy update in order
+	var events []string
+	record := func(action clienttesting.Action) (bool, runtime.Object, error) {
+		resource := action.GetResource().Resource
+		if action.GetVerb() == "update" {
+			obj := action.(clienttesting.UpdateAction).GetObject().(*unstructured.Unstructured)
+			events = append(events, "update "+obj.GetName()+" "+policyOf(obj))
+		} else {
+			events = append(events, "create "+resource)
+		}
+		return false, nil, nil
+	}
+	dynamicClient.PrependReactor("create", "*", record)
+	dynamicClient.PrependReactor("update", "validatingwebhookconfigurations", record)
+
+	bm := &BackupManager{DynamicClient: dynamicClient}
+	if _, err := bm.RestoreBackup(context.Background(), storageDir, archiveName, RestoreOptions{IgnoreWebhookFailures: true}); err != nil {
+		t.Fatalf("RestoreBackup returned error: %v", err)
+	}
+
+	want := []string{
+		"update live " + failurePolicyIgnore,
+		"create namespaces",
+		"create configmaps",
+		"create validatingwebhookconfigurations",
+		"update live " + failurePolicyDefault,
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected events %v, got %v", want, events)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Fatalf("expected events %v, got %v", want, events)
+		}
+	}
+
+	if got := webhookPolicy(t, dynamicClient, "live"); got != failurePolicyDefault {
+		t.Fatalf("expected live webhook policy to be put back to %q, got %q", failurePolicyDefault, got)
+	}
+	if got := webhookPolicy(t, dynamicClient, "archived"); got != "Fail" {
+		t.Fatalf("expected archived webhook to keep its policy, got %q", got)
+	}
+}
+
+func TestRelaxWebhooksForRestoreWaitsForTheLastRestore(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Group: "admissionregistration.k8s.io", Version: "v1", Kind: "ValidatingWebhookConfiguration"})
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Group: "admissionregistration.k8s.io", Version: "v1", Kind: "MutatingWebhookConfiguration"})
+	dynamicClient := fake.NewSimpleDynamicClient(scheme, webhookConfiguration("live", "Fail"))
+	bm := &BackupManager{DynamicClient: dynamicClient, webhookRelaxations: &webhookRelaxations{}}
+	ctx := context.Background()
+	relaxedPolicy := func() string {
+		obj, err := dynamicClient.Resource(webhookConfigurationGVRs[0]).Get(ctx, "live", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return policyOf(obj)
+	}
+
+	releaseFirst, _, err := bm.relaxWebhooksForRestore(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	releaseSecond, _, err := bm.relaxWebhooksForRestore(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	releaseFirst()
+	releaseFirst()
+	if policy := relaxedPolicy(); policy != failurePolicyIgnore {
+		t.Fatalf("policy after the first restore = %q, want it relaxed for the second", policy)
+	}
+	if err := bm.RestoreWebhookPolicies(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if policy := relaxedPolicy(); policy != failurePolicyIgnore {
+		t.Fatalf("startup revert changed the policy to %q during a restore", policy)
+	}
+	releaseSecond()
+	if policy := webhookPolicy(t, dynamicClient, "live"); policy != "Fail" {
+		t.Fatalf("policy after the last restore = %q, want Fail", policy)
+	}
+}
+
+func TestRestoreWebhookPoliciesRevertsLeftovers(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Group: "admissionregistration.k8s.io", Version: "v1", Kind: "ValidatingWebhookConfiguration"})
+	registerUnstructuredType(scheme, schema.GroupVersionKind{Group: "admissionregistration.k8s.io", Version: "v1", Kind: "MutatingWebhookConfiguration"})
+	dynamicClient := fake.NewSimpleDynamicClient(scheme, webhookConfiguration("live", ""))
+	// A restore of a previous operator process relaxed the policies
+	if _, err := (&BackupManager{DynamicClient: dynamicClient}).relaxWebhooks(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	bm := &BackupManager{DynamicClient: dynamicClient, webhookRelaxations: &webhookRelaxations{}}
+	if err := bm.RestoreWebhookPolicies(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if policy := webhookPolicy(t, dynamicClient, "live"); policy != failurePolicyDefault {
+		t.Fatalf("policy = %q, want %q", policy, failurePolicyDefault)
+	}
+}
+
+func webhookConfiguration(name, failurePolicy string) *unstructured.Unstructured {
+	webhook := map[string]interface{}{"name": name + ".example.com"}
+	if failurePolicy != "" {
+		webhook["failurePolicy"] = failurePolicy
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "admissionregistration.k8s.io/v1",
+		"kind":       "ValidatingWebhookConfiguration",
+		"metadata":   map[string]interface{}{"name": name},
+		"webhooks":   []interface{}{webhook},
+	}}
+}
+
+func webhookPolicy(t *testing.T, dynamicClient *fake.FakeDynamicClient, name string) string {
+	t.Helper()
+
+	obj, err := dynamicClient.Resource(webhookConfigurationGVRs[0]).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get webhook configuration %s: %v", name, err)
+	}
+	if _, ok := obj.GetAnnotations()[originalFailurePoliciesAnnotation]; ok {
+		t.Fatalf("expected no leftover annotation on %s", name)
+	}
+	return policyOf(obj)
+}
+
+func policyOf(obj *unstructured.Unstructured) string {
+	webhooks, _, _ := unstructured.NestedSlice(obj.Object, "webhooks")
+	policy, _ := webhooks[0].(map[string]interface{})["failurePolicy"].(string)
+	return policy
+}